@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/cache"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/jobs"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+)
+
+// newAdminCommand builds `weblayout admin`, operator tasks run directly against the database
+func newAdminCommand() *cobra.Command {
+	admin := &cobra.Command{
+		Use:   "admin",
+		Short: "Operator tasks run directly against the database",
+	}
+	admin.AddCommand(newAdminFlagsCommand(), newAdminCreateCommand(), newAdminResetPasswordCommand())
+	return admin
+}
+
+// passwordFlags reads the password of `admin create` and `admin reset-password`: --password, a line on stdin
+// with --password-stdin (kept out of the shell history), or else a generated one that is printed
+type passwordFlags struct {
+	password string
+	stdin    bool
+}
+
+func (p *passwordFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.password, "password", "", "the new password; generated and printed when neither this nor --password-stdin is given")
+	cmd.Flags().BoolVar(&p.stdin, "password-stdin", false, "read the new password from the first line of stdin")
+}
+
+// resolve returns the password and whether it was generated
+func (p *passwordFlags) resolve() (string, bool, error) {
+	switch {
+	case p.stdin:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", false, err
+		}
+		return strings.TrimRight(line, "\r\n"), false, nil
+	case p.password != "":
+		return p.password, false, nil
+	default:
+		password, err := passwords.Generate(20)
+		return password, true, err
+	}
+}
+
+// newAdminCreateCommand builds `weblayout admin create --email --role`, e.g. to bootstrap the first admin
+func newAdminCreateCommand() *cobra.Command {
+	account := services.NewAccount{}
+	var tenantID uint
+	var password passwordFlags
+	create := &cobra.Command{
+		Use:   "create --email <email> [--role admin]",
+		Short: "Create an account with the given role",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plain, generated, err := password.resolve()
+			if err != nil {
+				return err
+			}
+			account.Password = plain
+			withAccounts(tenantID, func(ctx context.Context, accounts services.AccountServiceInterface) error {
+				id, err := accounts.CreateAccount(ctx, &account)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Created %s %s with ID %d\n", account.Role, account.Email, id)
+				if generated {
+					fmt.Printf("Password: %s\n", plain)
+				}
+				return nil
+			})
+			return nil
+		},
+	}
+	create.Flags().StringVar(&account.Email, "email", "", "email address to log in with")
+	create.Flags().StringVar(&account.Role, "role", models.StrAdmin, "user, moderator or admin")
+	create.Flags().StringVar(&account.FirstName, "first-name", "", "first name")
+	create.Flags().StringVar(&account.LastName, "last-name", "", "last name")
+	create.Flags().UintVar(&tenantID, "tenant-id", 0, "tenant of the account with multi-tenancy; the default tenant otherwise")
+	create.MarkFlagRequired("email")
+	password.register(create)
+	return create
+}
+
+// newAdminResetPasswordCommand builds `weblayout admin reset-password --email`
+func newAdminResetPasswordCommand() *cobra.Command {
+	var email string
+	var tenantID uint
+	var password passwordFlags
+	reset := &cobra.Command{
+		Use:   "reset-password --email <email>",
+		Short: "Replace the password of an account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plain, generated, err := password.resolve()
+			if err != nil {
+				return err
+			}
+			withAccounts(tenantID, func(ctx context.Context, accounts services.AccountServiceInterface) error {
+				err := accounts.ResetPassword(ctx, email, plain)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Reset the password of %s\n", email)
+				if generated {
+					fmt.Printf("Password: %s\n", plain)
+				}
+				return nil
+			})
+			return nil
+		},
+	}
+	reset.Flags().StringVar(&email, "email", "", "email address of the account")
+	reset.Flags().UintVar(&tenantID, "tenant-id", 0, "tenant of the account with multi-tenancy")
+	reset.MarkFlagRequired("email")
+	password.register(reset)
+	return reset
+}
+
+// newAdminFlagsCommand builds `weblayout admin flags list|set|clear`, the CLI twin of /admin/flags
+func newAdminFlagsCommand() *cobra.Command {
+	flagsCommand := &cobra.Command{
+		Use:   "flags",
+		Short: "List and override feature flags",
+	}
+
+	var userID uint
+	set := &cobra.Command{
+		Use:   "set <feature> <true|false>",
+		Short: "Override a feature for everybody, or for one user with --user-id",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enabled, err := strconv.ParseBool(args[1])
+			if err != nil {
+				return fmt.Errorf("%q is not true or false", args[1])
+			}
+			withFeatureFlags(func(ctx context.Context, featureFlags services.FeatureFlagServiceInterface) error {
+				return featureFlags.SetOverride(ctx, &models.FeatureFlag{Name: args[0], UserID: userID, Enabled: enabled})
+			})
+			return nil
+		},
+	}
+	set.Flags().UintVar(&userID, "user-id", 0, "override the feature for this user only")
+
+	clearCommand := &cobra.Command{
+		Use:   "clear <feature>",
+		Short: "Remove the override for everybody, or for one user with --user-id",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			withFeatureFlags(func(ctx context.Context, featureFlags services.FeatureFlagServiceInterface) error {
+				return featureFlags.DeleteOverride(ctx, args[0], userID)
+			})
+		},
+	}
+	clearCommand.Flags().UintVar(&userID, "user-id", 0, "remove the override of this user")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Print every feature with its state and overrides",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			withFeatureFlags(func(ctx context.Context, featureFlags services.FeatureFlagServiceInterface) error {
+				states, err := featureFlags.ListFeatures(ctx)
+				if err != nil {
+					return err
+				}
+				out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(out, "FEATURE\tUSER\tENABLED")
+				for _, state := range states {
+					fmt.Fprintf(out, "%s\t*\t%t\n", state.Name, state.Enabled)
+					for _, override := range state.Overrides {
+						if override.UserID != 0 {
+							fmt.Fprintf(out, "%s\t%d\t%t\n", state.Name, override.UserID, override.Enabled)
+						}
+					}
+				}
+				return out.Flush()
+			})
+		},
+	}
+
+	flagsCommand.AddCommand(list, set, clearCommand)
+	return flagsCommand
+}
+
+// withAccounts runs fn with the account service of the configured database and exits on failure. The changes
+// are stored as events and, when the servers deliver webhooks on the job queue, queued for delivery.
+func withAccounts(tenantID uint, fn func(ctx context.Context, accounts services.AccountServiceInterface) error) {
+	ctx := context.Background()
+	a := app.New(ctx)
+	defer a.Close()
+
+	cfg, db, logger := a.Config, a.Database(), a.Logger
+	if tenantID != 0 {
+		ctx = tenancy.WithTenant(ctx, tenantID)
+	}
+
+	var sink events.Publisher = events.NewLogPublisher(logger)
+	if cfg.JobsEnabled && cfg.WebhookURL != "" {
+		queue := jobs.NewQueue(repositories.NewJobRepo(db, logger), cfg.JobWorkers, cfg.JobPollInterval, cfg.JobTimeout, cfg.JobMaxAttempts, logger)
+		sink = jobs.NewQueuedPublisher(queue, jobs.MaxAttempts(cfg.WebhookMaxAttempts))
+	}
+	eventService := services.NewEventService(repositories.NewEventRepo(db, logger), logger)
+	emitter := events.NewEmitter(cfg.EventSource, events.NewPersistingPublisher(eventService, sink), logger)
+
+	// Through the cache, so the servers do not keep serving the account as it was
+	var userRepo repositories.UserRepoInterface = repositories.NewUserRepo(db, logger)
+	if cfg.UserCacheEnabled {
+		userRepo = repositories.NewCachedUserRepo(userRepo, cache.NewRedisClient(cfg.RedisURL), cfg.UserCacheTTL, logger)
+	}
+	userService := services.NewUserService(userRepo, repositories.NewVoteRepo(db, logger), repositories.NewRememberTokenRepo(db, logger), repositories.NewTxManager(db, logger), emitter, logger)
+	accounts := services.NewAccountService(userService, repositories.NewRoleRepo(db, logger), logger)
+
+	err := fn(ctx, accounts)
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		for _, violation := range appErr.Fields {
+			fmt.Fprintf(os.Stderr, "--%s %s\n", strings.ReplaceAll(violation.Field, "_", "-"), violation.Message)
+		}
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// withFeatureFlags runs fn with the feature flag service of the configured database and exits on failure
+func withFeatureFlags(fn func(ctx context.Context, featureFlags services.FeatureFlagServiceInterface) error) {
+	ctx := context.Background()
+	a := app.New(ctx)
+	defer a.Close()
+
+	flagRepo := repositories.NewFeatureFlagRepo(a.Database(), a.Logger)
+	featureFlags := services.NewFeatureFlagService(flagRepo, a.Config.FeatureFlags, a.Config.FeatureFlagRefreshInterval, a.Logger)
+	err := fn(ctx, featureFlags)
+	if err != nil {
+		a.Logger.Fatal(err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/seed"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+)
+
+// newFixturesCommand builds `weblayout fixtures --users N --votes M`, load-test data for pagination, search and
+// ratings; like seed it is refused by the production profile
+func newFixturesCommand() *cobra.Command {
+	opts := seed.FixtureOptions{}
+	var tenantID uint
+	fixtures := &cobra.Command{
+		Use:   "fixtures --users N [--votes M]",
+		Short: "Generate fake users and votes for load testing; run seed first so the roles exist",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			a := app.New(ctx)
+			defer a.Close()
+
+			profile := a.Config.Profile()
+			if !profile.Seed {
+				a.Logger.Fatalf("fixtures is not available with the %s profile (APP_ENV)", profile.Name)
+			}
+			if opts.Users < 0 || opts.Votes < 0 || opts.LikeRatio < 0 || opts.LikeRatio > 1 {
+				a.Logger.Fatal("--users and --votes must not be negative and --like-ratio must be between 0 and 1")
+			}
+			if tenantID != 0 {
+				ctx = tenancy.WithTenant(ctx, tenantID)
+			}
+
+			db, logger := a.Database(), a.Logger
+			generator := seed.NewFixtureGenerator(
+				repositories.NewUserRepo(db, logger),
+				repositories.NewVoteRepo(db, logger),
+				repositories.NewRoleRepo(db, logger),
+				repositories.NewTxManager(db, logger),
+				logger,
+			)
+			started := time.Now()
+			report, err := generator.Run(ctx, opts)
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+			a.Logger.Infof("Generated %d users and %d votes in %s", report.Users, report.Votes, time.Since(started).Round(time.Millisecond))
+		},
+	}
+	fixtures.Flags().IntVar(&opts.Users, "users", 0, "number of users to create")
+	fixtures.Flags().IntVar(&opts.Votes, "votes", 0, "number of votes to cast among the new users")
+	fixtures.Flags().Float64Var(&opts.LikeRatio, "like-ratio", 0.7, "share of the votes that are likes")
+	fixtures.Flags().DurationVar(&opts.Span, "span", 90*24*time.Hour, "sign-ups and votes are spread over this long before now")
+	fixtures.Flags().Int64Var(&opts.RandSeed, "seed", 1, "random seed; the same seed draws the same names and distribution")
+	fixtures.Flags().UintVar(&tenantID, "tenant-id", 0, "tenant of the data with multi-tenancy")
+	fixtures.MarkFlagRequired("users")
+	return fixtures
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+)
+
+// newHealthcheckCommand builds `weblayout healthcheck`, which probes the local server's /readyz so container
+// HEALTHCHECK directives need no curl in the image. It only reads the configuration: no logging, secrets or
+// connections of its own.
+func newHealthcheckCommand() *cobra.Command {
+	var url string
+	var timeout time.Duration
+	healthcheck := &cobra.Command{
+		Use:   "healthcheck [--url http://127.0.0.1:<APP_PORT>/readyz]",
+		Short: "Exit non-zero unless the local server reports ready",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client := &http.Client{Timeout: timeout}
+			if url == "" {
+				cfg, err := config.NewConfig()
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				url = fmt.Sprintf("http://127.0.0.1:%s/readyz", cfg.AppPort)
+				if cfg.Profile().Autocert && len(cfg.AutocertDomains) > 0 {
+					// The certificate names the public domains, not the loopback address being probed
+					url = fmt.Sprintf("https://127.0.0.1:%s/readyz", cfg.AppPort)
+					client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+				}
+			}
+
+			resp, err := client.Get(url)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			fmt.Printf("%s %s\n", resp.Status, strings.TrimSpace(string(body)))
+			if resp.StatusCode != http.StatusOK {
+				os.Exit(1)
+			}
+		},
+	}
+	healthcheck.Flags().StringVar(&url, "url", "", "endpoint to probe; defaults to /readyz on APP_PORT of this host")
+	healthcheck.Flags().DurationVar(&timeout, "timeout", 3*time.Second, "give up after this long")
+	return healthcheck
+}
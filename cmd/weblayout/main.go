@@ -1,9 +1,9 @@
 package main
 
-import (
-	"gitlab.com/jkozhemiaka/web-layout/internal/server"
-)
+import "os"
 
 func main() {
-	server.Run()
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
 }
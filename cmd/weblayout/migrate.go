@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/database"
+)
+
+// newMigrateCommand builds `weblayout migrate up|down [steps]|status`
+func newMigrateCommand() *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, roll back or inspect the Postgres schema migrations",
+	}
+
+	migrate.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			withMigrator(func(migrator *database.Migrator) error {
+				return migrator.Up()
+			})
+		},
+	}, &cobra.Command{
+		Use:   "down [steps]",
+		Short: "Roll back migrations (default 1 step)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps := 1
+			if len(args) > 0 {
+				var err error
+				steps, err = strconv.Atoi(args[0])
+				if err != nil || steps < 1 {
+					return fmt.Errorf("steps must be a positive number, got %q", args[0])
+				}
+			}
+			withMigrator(func(migrator *database.Migrator) error {
+				return migrator.Down(steps)
+			})
+			return nil
+		},
+	}, &cobra.Command{
+		Use:   "status",
+		Short: "Print the current version and dirty flag",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			withMigrator(func(migrator *database.Migrator) error {
+				version, dirty, err := migrator.Status()
+				if err != nil {
+					return err
+				}
+				fmt.Printf("version: %d, dirty: %t\n", version, dirty)
+				return nil
+			})
+		},
+	})
+	return migrate
+}
+
+// withMigrator runs fn against the configured Postgres database and exits on failure
+func withMigrator(fn func(migrator *database.Migrator) error) {
+	// Short-lived commands only need the secrets at startup; leases are not renewed
+	a := app.New(context.Background())
+	defer a.Close()
+
+	if a.Config.DBDriver != config.DriverPostgres {
+		a.Logger.Fatal("migrations target Postgres; other drivers get their schema from AutoMigrate on startup")
+	}
+
+	migrator, err := database.NewMigrator(a.Config)
+	if err != nil {
+		a.Logger.Fatal(err)
+	}
+	defer migrator.Close()
+
+	err = fn(migrator)
+	if err != nil {
+		a.Logger.Fatal(err)
+	}
+}
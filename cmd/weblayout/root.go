@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+)
+
+// newRootCommand builds `weblayout`; the configuration flags are shared by every subcommand
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "weblayout",
+		Short: "User management REST API",
+	}
+	config.RegisterFlags(root.PersistentFlags())
+
+	root.AddCommand(newServeCommand(), newHealthcheckCommand(), newMigrateCommand(), newSeedCommand(), newFixturesCommand(), newAdminCommand(), newTokenCommand())
+	return root
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/seed"
+)
+
+// newSeedCommand builds `weblayout seed [--fake-users N]`; the profile decides whether it, and fake users, are allowed
+func newSeedCommand() *cobra.Command {
+	var fakeUsers int
+	seedCommand := &cobra.Command{
+		Use:   "seed",
+		Short: "Create the default roles, the seed admin and optionally fake demo users",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			a := app.New(context.Background())
+			defer a.Close()
+
+			profile := a.Config.Profile()
+			if !profile.Seed {
+				a.Logger.Fatalf("seed is not available with the %s profile (APP_ENV)", profile.Name)
+			}
+			if fakeUsers > 0 && !profile.SeedFakeUsers {
+				a.Logger.Fatalf("--fake-users is not available with the %s profile (APP_ENV)", profile.Name)
+			}
+
+			db := a.Database()
+			userRepo := repositories.NewUserRepo(db, a.Logger)
+			seeder := seed.NewSeeder(db, userRepo, a.Logger)
+			err := seeder.Run(context.Background(), seed.Options{
+				AdminEmail:    a.Config.SeedAdminEmail,
+				AdminPassword: a.Config.SeedAdminPassword,
+				FakeUsers:     fakeUsers,
+			})
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+		},
+	}
+	seedCommand.Flags().IntVar(&fakeUsers, "fake-users", 0, "number of fake demo users to create")
+	return seedCommand
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/server"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the API",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			a := app.New(context.Background())
+			defer a.Close()
+
+			server.Run(a)
+		},
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/spf13/cobra"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+)
+
+// newTokenCommand builds `weblayout token mint|inspect`, for debugging integrations and smoke tests
+func newTokenCommand() *cobra.Command {
+	token := &cobra.Command{
+		Use:   "token",
+		Short: "Mint and inspect JWTs signed with the configured key",
+	}
+	token.AddCommand(newTokenMintCommand(), newTokenInspectCommand())
+	return token
+}
+
+// newTokenMintCommand builds `weblayout token mint`, printing a token the API accepts like one from /login
+func newTokenMintCommand() *cobra.Command {
+	var email, role string
+	var userID, tenantID uint
+	var ttl time.Duration
+	var extra []string
+	mint := &cobra.Command{
+		Use:   "mint --email <email> [--role user] [--user-id N] [--tenant-id 1] [--ttl 1h] [--claim key=value ...]",
+		Short: "Print a signed token with the given claims",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ttl <= 0 {
+				return errors.New("--ttl must be positive")
+			}
+			minted := auth.NewClaims(email, role, userID, ttl)
+			minted.TenantID = tenantID
+			claims, err := mapClaims(minted)
+			if err != nil {
+				return err
+			}
+			for _, claim := range extra {
+				name, value, ok := strings.Cut(claim, "=")
+				if !ok || name == "" {
+					return fmt.Errorf("--claim %q is not key=value", claim)
+				}
+				claims[name] = claimValue(value)
+			}
+
+			a := app.New(context.Background())
+			defer a.Close()
+
+			keys, err := auth.ParseKeys(a.Config.JwtAlgorithm, a.Config.JwtKey)
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+			token, err := keys.Sign(claims)
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+			fmt.Println(token)
+			return nil
+		},
+	}
+	mint.Flags().StringVar(&email, "email", "", "email claim; the API needs one")
+	mint.Flags().StringVar(&role, "role", models.StrUser, "role claim: user, moderator or admin")
+	mint.Flags().UintVar(&userID, "user-id", 0, "user_id claim")
+	mint.Flags().UintVar(&tenantID, "tenant-id", tenancy.DefaultTenantID, "tenant_id claim; the API refuses tokens of other tenants")
+	mint.Flags().DurationVar(&ttl, "ttl", time.Hour, "lifetime of the token")
+	mint.Flags().StringArrayVar(&extra, "claim", nil, "additional claim as key=value; JSON values such as 42 or true keep their type")
+	mint.MarkFlagRequired("email")
+	return mint
+}
+
+// mapClaims turns claims into a map that further claims can be added to
+func mapClaims(claims jwt.Claims) (jwt.MapClaims, error) {
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	mapped := jwt.MapClaims{}
+	return mapped, json.Unmarshal(body, &mapped)
+}
+
+// claimValue is value decoded as JSON when it is valid JSON and the plain string otherwise
+func claimValue(value string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// TokenInspection is what `weblayout token inspect` prints
+type TokenInspection struct {
+	Header    map[string]interface{} `json:"header"`
+	Claims    jwt.MapClaims          `json:"claims"`
+	ExpiresAt *time.Time             `json:"expires_at,omitempty"`
+	Valid     bool                   `json:"valid"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// newTokenInspectCommand builds `weblayout token inspect`, failing for tokens the API would refuse
+func newTokenInspectCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect [<token>|-]",
+		Short: "Decode a token and verify it against the configured key; reads stdin without an argument or with -",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			raw, err := tokenArgument(args)
+			if err != nil {
+				return err
+			}
+
+			claims := jwt.MapClaims{}
+			token, _, err := new(jwt.Parser).ParseUnverified(raw, claims)
+			if err != nil {
+				return fmt.Errorf("not a JWT: %w", err)
+			}
+			inspection := &TokenInspection{Header: token.Header, Claims: claims, Valid: true}
+			if exp, ok := claims["exp"].(float64); ok {
+				expiresAt := time.Unix(int64(exp), 0).UTC()
+				inspection.ExpiresAt = &expiresAt
+			}
+
+			a := app.New(context.Background())
+			defer a.Close()
+
+			keys, err := auth.ParseKeys(a.Config.JwtAlgorithm, a.Config.JwtKey)
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+			verified, err := keys.Parse(raw)
+			if err == nil && (verified.Role == "" || verified.Email == "") {
+				err = errors.New("the API needs the email and role claims")
+			}
+			if err != nil {
+				inspection.Valid = false
+				inspection.Error = err.Error()
+			}
+
+			out := json.NewEncoder(os.Stdout)
+			out.SetIndent("", "  ")
+			if err := out.Encode(inspection); err != nil {
+				return err
+			}
+			if !inspection.Valid {
+				a.Close()
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
+
+// tokenArgument is the token given as the argument, or the first line of stdin; a Bearer prefix is dropped
+func tokenArgument(args []string) (string, error) {
+	raw := ""
+	if len(args) == 1 && args[0] != "-" {
+		raw = args[0]
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading the token from stdin: %w", err)
+		}
+		raw = line
+	}
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "Bearer ")
+	if raw == "" {
+		return "", errors.New("no token given")
+	}
+	return raw, nil
+}
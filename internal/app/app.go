@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/buildinfo"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/database"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/logging"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/secrets"
+	"gitlab.com/jkozhemiaka/web-layout/internal/sms"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// App is the wiring every command shares: the validated configuration with its secrets resolved, the
+// profile's logger and the secret backends
+type App struct {
+	Config *config.Config
+	// Configured is the configuration as loaded, before secret references were resolved; reloads compare with it
+	Configured config.Config
+	Logger     *zap.SugaredLogger
+	LogLevel   zap.AtomicLevel
+	Secrets    *secrets.Secrets
+
+	logger *zap.Logger
+}
+
+// New loads and validates the configuration and builds the logger. Configuration errors are fatal and go to
+// the standard logger, since the logger depends on the profile.
+func New(ctx context.Context) *App {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, logLevel, err := logging.New(cfg)
+	if err != nil {
+		log.Fatal(apperrors.LoggerInitError.AppendMessage(err))
+	}
+	sugar := logger.Sugar()
+	sugar.Infof("Starting %s with the %s profile", buildinfo.Read(), cfg.Profile().Name)
+	sugar.Infof("Effective configuration:\n%s", cfg.Masked())
+	apperrors.CaptureStacks(cfg.Profile().StackTraces)
+	if err := i18n.SetFallback(cfg.DefaultLanguage); err != nil {
+		sugar.Fatal(err)
+	}
+	configured := *cfg
+
+	resolved, err := secrets.Setup(ctx, cfg, sugar)
+	if err != nil {
+		sugar.Fatal(err)
+	}
+	err = cfg.Validate()
+	if err != nil {
+		sugar.Fatal(err)
+	}
+	if source := resolved.CredentialSource(); source != nil {
+		database.UseCredentials(source)
+	}
+
+	return &App{
+		Config:     cfg,
+		Configured: configured,
+		Logger:     sugar,
+		LogLevel:   logLevel,
+		Secrets:    resolved,
+		logger:     logger,
+	}
+}
+
+// Database opens the configured database, exiting when it cannot be reached
+func (a *App) Database() *gorm.DB {
+	db, err := database.SetupDatabase(a.Config, a.Logger)
+	if err != nil {
+		a.Logger.Fatal(err)
+	}
+	return db
+}
+
+// Mailer builds the configured mailer, which sends right away; the server queues messages instead
+func (a *App) Mailer() mail.Mailer {
+	mailer, err := mail.NewMailer(a.Config, a.Secrets.AWS, a.Logger)
+	if err != nil {
+		a.Logger.Fatal(err)
+	}
+	return mailer
+}
+
+// SMSSender builds the configured SMS sender
+func (a *App) SMSSender() sms.Sender {
+	sender, err := sms.NewSender(a.Config, a.Logger)
+	if err != nil {
+		a.Logger.Fatal(err)
+	}
+	return sender
+}
+
+// Close flushes the logger
+func (a *App) Close() {
+	a.logger.Sync()
+}
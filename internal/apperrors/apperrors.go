@@ -9,8 +9,27 @@ type AppError struct {
 	Message  string
 	Code     string
 	HTTPCode int
+	// Fields lists every invalid field of a request, see WithFields
+	Fields []FieldViolation
+
+	cause error
+	stack []uintptr
+}
+
+// FieldViolation is one problem with one field of a request. Rule is the failed check (required, email, min, ...)
+// and stays the same in every language, like the codes.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// MessageID and MessageArgs name Message in the i18n catalog, so responses can have it in the request's language
+	MessageID   string        `json:"-"`
+	MessageArgs []interface{} `json:"-"`
 }
 
+// StatusClientClosedRequest is the non-standard status for requests the client gave up on before the answer
+const StatusClientClosedRequest = 499
+
 var (
 	EnvConfigLoadError = AppError{
 		Message: "Failed to load env file",
@@ -49,6 +68,12 @@ var (
 		HTTPCode: 500,
 	}
 
+	QueryFailedErr = AppError{
+		Message:  "Failed to read the record",
+		Code:     "QUERY_FAILED_ERR",
+		HTTPCode: http.StatusInternalServerError,
+	}
+
 	NoRecordFoundErr = AppError{
 		Message:  "No record found",
 		Code:     "NO_RECORD_FOUND",
@@ -74,22 +99,274 @@ var (
 		HTTPCode: 409, // HTTP 409 Conflict, as the action cannot be performed due to existing state
 	}
 
+	DuplicateEmailErr = AppError{
+		Message:  "A user with this email already exists",
+		Code:     "DUPLICATE_EMAIL_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	IdentityLinkedErr = AppError{
+		Message:  "The account is already linked to a user",
+		Code:     "IDENTITY_LINKED_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	LastIdentityErr = AppError{
+		Message:  "The only way to sign in cannot be unlinked",
+		Code:     "LAST_IDENTITY_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	InvitationPendingErr = AppError{
+		Message:  "This email already has a pending invitation",
+		Code:     "INVITATION_PENDING_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	InvitationInvalidErr = AppError{
+		Message:  "The invitation has expired, been revoked or already been accepted",
+		Code:     "INVITATION_INVALID_ERR",
+		HTTPCode: http.StatusGone,
+	}
+
+	AccountLockedErr = AppError{
+		Message:  "The account is locked, an admin can unlock it",
+		Code:     "ACCOUNT_LOCKED_ERR",
+		HTTPCode: http.StatusForbidden,
+	}
+
+	UserBannedErr = AppError{
+		Message:  "The account is banned",
+		Code:     "USER_BANNED_ERR",
+		HTTPCode: http.StatusForbidden,
+	}
+
+	ServiceAccountLoginErr = AppError{
+		Message:  "Service accounts can not sign in, they call the API with their API keys",
+		Code:     "SERVICE_ACCOUNT_LOGIN_ERR",
+		HTTPCode: http.StatusForbidden,
+	}
+
+	LoginAlertInvalidErr = AppError{
+		Message:  "The login alert has expired or was already used",
+		Code:     "LOGIN_ALERT_INVALID_ERR",
+		HTTPCode: http.StatusGone,
+	}
+
+	PhoneCodeInvalidErr = AppError{
+		Message:  "The code is wrong, has expired or was already used",
+		Code:     "PHONE_CODE_INVALID_ERR",
+		HTTPCode: http.StatusBadRequest,
+	}
+
+	PhoneNotVerifiedErr = AppError{
+		Message:  "The phone number is not verified",
+		Code:     "PHONE_NOT_VERIFIED_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	PhoneTakenErr = AppError{
+		Message:  "The phone number is verified by another account",
+		Code:     "PHONE_TAKEN_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	RememberTokenInvalidErr = AppError{
+		Message:  "The remember-me token is unknown, expired or revoked",
+		Code:     "REMEMBER_TOKEN_INVALID_ERR",
+		HTTPCode: http.StatusUnauthorized,
+	}
+
+	ReportPendingErr = AppError{
+		Message:  "You already reported this user, the report is pending",
+		Code:     "REPORT_PENDING_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	ReportResolvedErr = AppError{
+		Message:  "The report was already resolved",
+		Code:     "REPORT_RESOLVED_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	AlreadyMemberErr = AppError{
+		Message:  "The user is already a member of the organization",
+		Code:     "ALREADY_MEMBER_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	LastOrganizationAdminErr = AppError{
+		Message:  "An organization keeps at least one admin",
+		Code:     "LAST_ORGANIZATION_ADMIN_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	TermsNotAcceptedErr = AppError{
+		Message:  "The current terms of service and privacy policy have not been accepted",
+		Code:     "TERMS_NOT_ACCEPTED_ERR",
+		HTTPCode: http.StatusForbidden,
+	}
+
+	QuotaExceededErr = AppError{
+		Message:  "The tenant has used up its quota",
+		Code:     "QUOTA_EXCEEDED_ERR",
+		HTTPCode: http.StatusTooManyRequests,
+	}
+
+	TransactionConflictErr = AppError{
+		Message:  "The request conflicted with a concurrent update, please retry",
+		Code:     "TRANSACTION_CONFLICT_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	DuplicateRecordErr = AppError{
+		Message:  "A record with the same unique key already exists",
+		Code:     "DUPLICATE_RECORD_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	ReferenceViolationErr = AppError{
+		Message:  "The record references a missing record or is still referenced",
+		Code:     "REFERENCE_VIOLATION_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	RequestCanceledErr = AppError{
+		Message:  "The request was canceled",
+		Code:     "REQUEST_CANCELED_ERR",
+		HTTPCode: StatusClientClosedRequest,
+	}
+
+	TimeoutErr = AppError{
+		Message:  "The operation timed out",
+		Code:     "TIMEOUT_ERR",
+		HTTPCode: http.StatusGatewayTimeout,
+	}
+
+	FeatureDisabledErr = AppError{
+		Message:  "This feature is currently disabled",
+		Code:     "FEATURE_DISABLED_ERR",
+		HTTPCode: http.StatusForbidden,
+	}
+
 	UnauthorizedErr = AppError{
 		Message:  "Unauthorized action",
 		Code:     "UNAUTHORIZED_ERR",
 		HTTPCode: http.StatusUnauthorized,
 	}
+
+	OAuthInvalidClientErr = AppError{
+		Message:  "Unknown OAuth client or wrong client secret",
+		Code:     "OAUTH_INVALID_CLIENT_ERR",
+		HTTPCode: http.StatusUnauthorized,
+	}
+
+	OAuthInvalidGrantErr = AppError{
+		Message:  "The authorization code is invalid, expired or was issued to another client",
+		Code:     "OAUTH_INVALID_GRANT_ERR",
+		HTTPCode: http.StatusBadRequest,
+	}
+
+	OAuthUnauthorizedClientErr = AppError{
+		Message:  "The OAuth client is not registered for this grant type",
+		Code:     "OAUTH_UNAUTHORIZED_CLIENT_ERR",
+		HTTPCode: http.StatusBadRequest,
+	}
+
+	OAuthInvalidScopeErr = AppError{
+		Message:  "The OAuth client is not registered for the requested scope",
+		Code:     "OAUTH_INVALID_SCOPE_ERR",
+		HTTPCode: http.StatusBadRequest,
+	}
+
+	ValidationFailedErr = AppError{
+		Message:  "The request has invalid fields",
+		Code:     "VALIDATION_ERR",
+		HTTPCode: http.StatusBadRequest,
+	}
+
+	// Generic errors, used for responses whose error does not carry a code of its own
+	BadRequestErr = AppError{
+		Message:  "The request is invalid",
+		Code:     "BAD_REQUEST_ERR",
+		HTTPCode: http.StatusBadRequest,
+	}
+
+	ForbiddenErr = AppError{
+		Message:  "Permission denied",
+		Code:     "FORBIDDEN_ERR",
+		HTTPCode: http.StatusForbidden,
+	}
+
+	ConflictErr = AppError{
+		Message:  "The request conflicts with the current state",
+		Code:     "CONFLICT_ERR",
+		HTTPCode: http.StatusConflict,
+	}
+
+	TooManyRequestsErr = AppError{
+		Message:  "Too many requests",
+		Code:     "TOO_MANY_REQUESTS_ERR",
+		HTTPCode: http.StatusTooManyRequests,
+	}
+
+	InternalErr = AppError{
+		Message:  "Internal server error",
+		Code:     "INTERNAL_ERR",
+		HTTPCode: http.StatusInternalServerError,
+	}
+
+	BadGatewayErr = AppError{
+		Message:  "An upstream service failed",
+		Code:     "BAD_GATEWAY_ERR",
+		HTTPCode: http.StatusBadGateway,
+	}
 )
 
+//go:generate go run gen/main.go
+
 func (appError *AppError) Error() string {
 	return appError.Code + ": " + appError.Message
 }
 
+// AppendMessage returns a copy of the error with details appended to its message. The first detail that is an
+// error becomes the cause, see Unwrap.
 func (appError *AppError) AppendMessage(anyErrs ...interface{}) *AppError {
+	appended := &AppError{
+		Message:  fmt.Sprintf("%v : %v", appError.Message, anyErrs),
+		Code:     appError.Code,
+		HTTPCode: appError.HTTPCode,
+		Fields:   appError.Fields,
+		stack:    callers(2),
+	}
+	for _, anyErr := range anyErrs {
+		if err, ok := anyErr.(error); ok {
+			appended.cause = err
+			break
+		}
+	}
+	return appended
+}
+
+// WithFields returns a copy of the error listing the field violations, so one response reports all of them
+func (appError *AppError) WithFields(fields ...FieldViolation) *AppError {
 	return &AppError{
-		Message: fmt.Sprintf("%v : %v", appError.Message, anyErrs),
-		Code:    appError.Code,
+		Message:  appError.Message,
+		Code:     appError.Code,
+		HTTPCode: appError.HTTPCode,
+		Fields:   append(append([]FieldViolation(nil), appError.Fields...), fields...),
+		cause:    appError.cause,
+		stack:    callers(2),
+	}
+}
+
+// Is reports whether target is an AppError with the same code, so errors.Is matches copies made by AppendMessage
+func (appError *AppError) Is(target error) bool {
+	other, ok := target.(*AppError)
+	if !ok {
+		return false
 	}
+	return appError.Code == other.Code
 }
 
 func Is(err1 error, err2 *AppError) bool {
@@ -0,0 +1,52 @@
+package apperrors
+
+import (
+	"net/http"
+	"sort"
+)
+
+// CatalogEntry documents one error code clients may receive
+type CatalogEntry struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	HTTPStatus int    `json:"http_status"`
+}
+
+// Catalog lists the errors that reach API responses, sorted by code. Errors without an HTTP status are
+// startup errors and are left out.
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for _, appError := range catalog {
+		if appError.HTTPCode == 0 {
+			continue
+		}
+		entries = append(entries, CatalogEntry{Code: appError.Code, Message: appError.Message, HTTPStatus: appError.HTTPCode})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
+
+// ForStatus returns the generic error for an HTTP status, for responses whose error has no code
+func ForStatus(status int) *AppError {
+	switch status {
+	case http.StatusBadRequest:
+		return &BadRequestErr
+	case http.StatusUnauthorized:
+		return &UnauthorizedErr
+	case http.StatusForbidden:
+		return &ForbiddenErr
+	case http.StatusNotFound:
+		return &NoRecordFoundErr
+	case http.StatusConflict:
+		return &ConflictErr
+	case http.StatusTooManyRequests:
+		return &TooManyRequestsErr
+	case http.StatusBadGateway:
+		return &BadGatewayErr
+	case http.StatusGatewayTimeout:
+		return &TimeoutErr
+	}
+	return &InternalErr
+}
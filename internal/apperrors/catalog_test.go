@@ -0,0 +1,54 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestCatalog_CodesAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, appError := range catalog {
+		assert.False(t, seen[appError.Code], "duplicate code %s", appError.Code)
+		seen[appError.Code] = true
+	}
+}
+
+func TestCatalog_ListsResponseErrors(t *testing.T) {
+	entries := Catalog()
+
+	assert.Contains(t, entries, CatalogEntry{Code: VoteCooldownErr.Code, Message: VoteCooldownErr.Message, HTTPStatus: http.StatusTooManyRequests})
+	for _, entry := range entries {
+		assert.NotEqual(t, EnvConfigLoadError.Code, entry.Code, "startup errors are not listed")
+		assert.NotZero(t, entry.HTTPStatus)
+	}
+}
+
+func TestAppendMessage_KeepsCodeAndStatus(t *testing.T) {
+	appended := NoRecordFoundErr.AppendMessage("User not found.")
+
+	assert.Equal(t, NoRecordFoundErr.Code, appended.Code)
+	assert.Equal(t, http.StatusNotFound, appended.HTTPCode)
+	assert.Equal(t, &InternalErr, ForStatus(http.StatusServiceUnavailable))
+}
+
+func TestLocalize_EveryCodeIsTranslated(t *testing.T) {
+	for lang, messages := range bundles {
+		for _, appError := range catalog {
+			assert.NotEmpty(t, messages[appError.Code], "%s has no %s message", lang, appError.Code)
+		}
+		assert.Len(t, messages, len(catalog), "%s has messages for unknown codes", lang)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	assert.Equal(t, "Запис не знайдено", Localize(&NoRecordFoundErr, language.Ukrainian))
+	assert.Equal(t, "No record found", Localize(&NoRecordFoundErr, language.English))
+	assert.Equal(t, "Запис не знайдено : [User not found.]",
+		Localize(NoRecordFoundErr.AppendMessage("User not found."), language.Ukrainian))
+	assert.Equal(t, "No record found", Localize(&NoRecordFoundErr, language.German), "unknown languages fall back to English")
+	assert.Equal(t, "plain", Localize(errors.New("plain"), language.Ukrainian))
+}
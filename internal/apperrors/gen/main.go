@@ -0,0 +1,109 @@
+//go:build ignore
+
+// gen writes catalog_gen.go, listing every AppError value declared in apperrors.go, and the English message
+// bundle locales/en.json the translations start from. Run `go generate ./...`.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "apperrors.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var names []string
+	messages := make(map[string]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if i >= len(value.Values) || !name.IsExported() {
+					continue
+				}
+				literal, ok := value.Values[i].(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				if ident, ok := literal.Type.(*ast.Ident); ok && ident.Name == "AppError" {
+					names = append(names, name.Name)
+					code, message := fields(literal)
+					messages[code] = message
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by gen/main.go; DO NOT EDIT.\n\npackage apperrors\n\nvar catalog = []*AppError{\n")
+	for _, name := range names {
+		fmt.Fprintf(&out, "\t&%s,\n", name)
+	}
+	out.WriteString("}\n")
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = os.WriteFile("catalog_gen.go", source, 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bundle, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = os.WriteFile("locales/en.json", append(bundle, '\n'), 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fields returns the Code and Message string literals of an AppError literal
+func fields(literal *ast.CompositeLit) (string, string) {
+	var code, message string
+	for _, element := range literal.Elts {
+		pair, ok := element.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := pair.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		value, ok := pair.Value.(*ast.BasicLit)
+		if !ok || value.Kind != token.STRING {
+			continue
+		}
+		text, err := strconv.Unquote(value.Value)
+		if err != nil {
+			log.Fatal(err)
+		}
+		switch key.Name {
+		case "Code":
+			code = text
+		case "Message":
+			message = text
+		}
+	}
+	return code, message
+}
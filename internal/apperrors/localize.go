@@ -0,0 +1,59 @@
+package apperrors
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// bundles maps a language to the messages of each code; locales/en.json is generated from apperrors.go
+var bundles = loadBundles()
+
+func loadBundles() map[string]map[string]string {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		err = json.Unmarshal(data, &messages)
+		if err != nil {
+			panic("apperrors: " + file.Name() + ": " + err.Error())
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// Localize returns the message of err in lang. The code stays the same in every language, only the text
+// changes; details added by AppendMessage are kept as they are. Codes missing from the bundle, and errors
+// that are not AppErrors, fall back to the English text.
+func Localize(err error, lang language.Tag) string {
+	var appError *AppError
+	if !errors.As(err, &appError) {
+		return err.Error()
+	}
+
+	base, _ := lang.Base()
+	translated, ok := bundles[base.String()][appError.Code]
+	if !ok {
+		return appError.Message
+	}
+	// AppendMessage keeps the default message as a prefix; the rest are details that are not translated
+	if original := bundles["en"][appError.Code]; strings.HasPrefix(appError.Message, original) {
+		return translated + strings.TrimPrefix(appError.Message, original)
+	}
+	return appError.Message
+}
@@ -0,0 +1,94 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// maxStackDepth bounds the frames recorded for one error
+const maxStackDepth = 32
+
+var captureStacks atomic.Bool
+
+// CaptureStacks makes AppendMessage record where each error is created, and error responses carry the
+// stack and cause chain. The development profile turns it on; elsewhere the cause chain is only logged.
+func CaptureStacks(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// CapturingStacks reports whether CaptureStacks is on
+func CapturingStacks() bool {
+	return captureStacks.Load()
+}
+
+// Unwrap returns the error passed to AppendMessage, if any, so the cause chain can be followed
+func (appError *AppError) Unwrap() error {
+	return appError.cause
+}
+
+// StackTrace returns the frames recorded when the error was created, empty unless CaptureStacks is on
+func (appError *AppError) StackTrace() []string {
+	return formatFrames(appError.stack)
+}
+
+func callers(skip int) []uintptr {
+	if !captureStacks.Load() {
+		return nil
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(skip+1, pcs)]
+}
+
+func formatFrames(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(pcs)
+	var lines []string
+	for {
+		frame, more := frames.Next()
+		lines = append(lines, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		if !more {
+			return lines
+		}
+	}
+}
+
+// Causes lists the messages of the errors err wraps, outermost first, not including err itself
+func Causes(err error) []string {
+	var causes []string
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+	}
+	return causes
+}
+
+// Stack returns the deepest stack recorded in the chain of err, by AppendMessage or by the
+// github.com/pkg/errors constructors, which record theirs whether or not CaptureStacks is on
+func Stack(err error) []string {
+	return formatFrames(Callers(err))
+}
+
+// Callers returns the program counters of the stack Stack formats, innermost call first
+func Callers(err error) []uintptr {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch traced := err.(type) {
+		case *AppError:
+			if len(traced.stack) > 0 {
+				pcs = traced.stack
+			}
+		case interface{ StackTrace() pkgerrors.StackTrace }:
+			pcs = make([]uintptr, 0, len(traced.StackTrace()))
+			for _, frame := range traced.StackTrace() {
+				// A Frame holds the return address, as runtime.Callers reports it
+				pcs = append(pcs, uintptr(frame))
+			}
+		}
+	}
+	return pcs
+}
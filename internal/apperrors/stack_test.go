@@ -0,0 +1,45 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendMessage_RecordsStackWhenCapturing(t *testing.T) {
+	CaptureStacks(true)
+	t.Cleanup(func() { CaptureStacks(false) })
+
+	appended := QueryFailedErr.AppendMessage("details")
+
+	stack := appended.StackTrace()
+	if assert.NotEmpty(t, stack) {
+		assert.Contains(t, stack[0], "TestAppendMessage_RecordsStackWhenCapturing", "the first frame is the caller")
+	}
+}
+
+func TestAppendMessage_NoStackByDefault(t *testing.T) {
+	assert.Empty(t, QueryFailedErr.AppendMessage("details").StackTrace())
+}
+
+func TestCauses(t *testing.T) {
+	root := errors.New("connection refused")
+	appended := QueryFailedErr.AppendMessage(fmt.Errorf("select users: %w", root))
+
+	assert.True(t, errors.Is(appended, root))
+	assert.Equal(t, []string{"select users: connection refused", "connection refused"}, Causes(appended))
+	assert.Empty(t, Causes(&QueryFailedErr))
+	assert.Empty(t, Causes(QueryFailedErr.AppendMessage("no error among the details")))
+}
+
+func TestStack_FindsPkgErrorsStack(t *testing.T) {
+	err := InsertionFailedErr.AppendMessage(pkgerrors.New("disk full"))
+
+	stack := Stack(err)
+	if assert.NotEmpty(t, stack) {
+		assert.Contains(t, stack[0], "TestStack_FindsPkgErrorsStack")
+	}
+}
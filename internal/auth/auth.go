@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -13,26 +15,113 @@ type Claims struct {
 	Email string `json:"email"`
 	Role  string `json:"role"`
 	ID    uint   `json:"user_id"`
+	// ClientID is set on tokens issued to an OAuth client, which may only do what Scope, space separated, allows.
+	// Its client credentials tokens name no user.
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	// TenantID and Permissions are embedded in login tokens as JWT_CLAIMS asks, see Embed
+	TenantID    uint     `json:"tenant_id,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 	jwt.StandardClaims
 }
 
+// Custom claims JWT_CLAIMS can embed in login tokens
+const (
+	// ClaimTenant binds the token to the tenant it was issued in
+	ClaimTenant = "tenant"
+	// ClaimPermissions lists the permissions of the user's role as an array
+	ClaimPermissions = "permissions"
+	// ClaimScope lists them space separated in scope, for resource servers that read OAuth scopes
+	ClaimScope = "scope"
+)
+
+// HasScope reports whether scope is one of the scopes of the token
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Embed adds the claims kinds name to the token: the tenant and the permissions of the user's role
+func (c *Claims) Embed(kinds []string, tenantID uint, permissions []string) {
+	for _, kind := range kinds {
+		switch kind {
+		case ClaimTenant:
+			c.TenantID = tenantID
+		case ClaimPermissions:
+			c.Permissions = append([]string{}, permissions...)
+		case ClaimScope:
+			c.Scope = strings.Join(permissions, " ")
+		}
+	}
+}
+
+// CarriesPermissions reports whether the permissions of the user were embedded, so they can be checked without a
+// lookup
+func (c *Claims) CarriesPermissions() bool {
+	return c.ClientID == "" && (c.Permissions != nil || c.Scope != "")
+}
+
+// HasPermission reports whether the embedded permissions include permission
+func (c *Claims) HasPermission(permission string) bool {
+	for _, granted := range c.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return c.ClientID == "" && c.HasScope(permission)
+}
+
+type claimsContextKey struct{}
+
+// WithClaims returns a context carrying the claims of the request's token
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims set by WithClaims, nil without any
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims
+}
+
+// LoginTokenTTL is how long the tokens handed out by /login are valid
+const LoginTokenTTL = 24 * time.Hour
+
 func GenerateTokenHandler(email, role string, ID uint, JwtKey []byte) []byte {
-	claims := &Claims{
+	tokenString, err := Sign(NewClaims(email, role, ID, LoginTokenTTL), JwtKey)
+	if err != nil {
+		return nil
+	}
+
+	return []byte(tokenString)
+}
+
+// NewClaims returns the claims of a token that identifies the user for ttl from now
+func NewClaims(email, role string, ID uint, ttl time.Duration) *Claims {
+	now := time.Now()
+	return &Claims{
 		Email: email,
 		Role:  role,
 		ID:    ID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
 		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(JwtKey)
-	if err != nil {
-		return nil
-	}
+// Sign returns the HS256 token of claims, which may carry claims beyond Claims (e.g. jwt.MapClaims)
+func Sign(claims jwt.Claims, key []byte) (string, error) {
+	return NewHMACKeys(key).Sign(claims)
+}
 
-	return []byte(tokenString)
+// Parse verifies the HS256 signature and expiry of tokenString against key and returns its claims
+func Parse(tokenString string, key []byte) (*Claims, error) {
+	return NewHMACKeys(key).Parse(tokenString)
 }
 
 func Access(username, password string, user *models.User) error {
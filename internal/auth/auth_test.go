@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte("test-key")
+
+func TestSignAndParse(t *testing.T) {
+	token, err := Sign(NewClaims("ops@example.com", "admin", 7, time.Hour), testKey)
+	require.NoError(t, err)
+
+	claims, err := Parse(token, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, "ops@example.com", claims.Email)
+	assert.Equal(t, "admin", claims.Role)
+	assert.Equal(t, uint(7), claims.ID)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), claims.ExpiresAt, 2)
+}
+
+func TestParseRejectsForeignAndExpiredTokens(t *testing.T) {
+	token, err := Sign(NewClaims("ops@example.com", "admin", 7, time.Hour), []byte("other-key"))
+	require.NoError(t, err)
+	_, err = Parse(token, testKey)
+	assert.Error(t, err, "signed with another key")
+
+	token, err = Sign(NewClaims("ops@example.com", "admin", 7, -time.Minute), testKey)
+	require.NoError(t, err)
+	_, err = Parse(token, testKey)
+	assert.Error(t, err, "expired")
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, NewClaims("ops@example.com", "admin", 7, time.Hour)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = Parse(unsigned, testKey)
+	assert.Error(t, err, "alg none")
+}
+
+func TestEmbedCustomClaims(t *testing.T) {
+	claims := NewClaims("ops@example.com", "user", 7, time.Hour)
+	claims.Embed([]string{ClaimTenant, ClaimPermissions}, 3, []string{"audit.read", "stats.read"})
+	token, err := Sign(claims, testKey)
+	require.NoError(t, err)
+
+	parsed, err := Parse(token, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), parsed.TenantID)
+	assert.True(t, parsed.CarriesPermissions())
+	assert.True(t, parsed.HasPermission("stats.read"))
+	assert.False(t, parsed.HasPermission("users.delete"))
+	assert.Empty(t, parsed.Scope)
+
+	scoped := NewClaims("ops@example.com", "user", 7, time.Hour)
+	scoped.Embed([]string{ClaimScope}, 3, []string{"audit.read", "stats.read"})
+	assert.Equal(t, "audit.read stats.read", scoped.Scope)
+	assert.True(t, scoped.HasPermission("audit.read"))
+	assert.Zero(t, scoped.TenantID)
+
+	oauth := &Claims{ClientID: "app", Scope: "stats.read"}
+	assert.False(t, oauth.CarriesPermissions(), "the scope of an OAuth token is what the user let the client do")
+	assert.False(t, oauth.HasPermission("stats.read"))
+}
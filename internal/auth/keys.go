@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Signing algorithms JWT_ALGORITHM accepts
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// Keys sign and verify tokens with one algorithm. With RS256 and ES256 only the private key signs; resource
+// servers verify tokens with the public key from JWKS and never hold a secret. Rotate replaces the key; the one
+// it replaced still verifies the tokens it signed.
+type Keys struct {
+	mu       sync.RWMutex
+	current  *keyPair
+	previous *keyPair // nil until the first rotation
+}
+
+type keyPair struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	// keyID names the public key in the kid header of tokens and in JWKS; empty for HMAC
+	keyID string
+}
+
+// JWK is a public key as RFC 7517 describes it
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	// N and E are set for RSA keys, Curve, X and Y for ECDSA ones
+	N     string `json:"n,omitempty"`
+	E     string `json:"e,omitempty"`
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeys signs and verifies HS256 tokens with secret
+func NewHMACKeys(secret []byte) *Keys {
+	return &Keys{current: &keyPair{method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}}
+}
+
+// ParseKeys returns the keys of algorithm from key: the secret for HS256, a PEM private key for RS256 (PKCS #1 or
+// PKCS #8) and ES256 (SEC 1 or PKCS #8, on P-256)
+func ParseKeys(algorithm, key string) (*Keys, error) {
+	switch algorithm {
+	case AlgorithmHS256, "":
+		if key == "" {
+			return nil, errors.New("the HS256 secret is empty")
+		}
+		return NewHMACKeys([]byte(key)), nil
+	case AlgorithmRS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("RS256 needs a PEM RSA private key: %w", err)
+		}
+		if private.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("the RSA key has %d bits, at least 2048 are needed", private.N.BitLen())
+		}
+		return newAsymmetricKeys(jwt.SigningMethodRS256, private, &private.PublicKey)
+	case AlgorithmES256:
+		private, err := jwt.ParseECPrivateKeyFromPEM([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("ES256 needs a PEM ECDSA private key: %w", err)
+		}
+		if private.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 needs a key on the P-256 curve")
+		}
+		return newAsymmetricKeys(jwt.SigningMethodES256, private, &private.PublicKey)
+	}
+	return nil, fmt.Errorf("unknown signing algorithm %q", algorithm)
+}
+
+func newAsymmetricKeys(method jwt.SigningMethod, private, public interface{}) (*Keys, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return &Keys{current: &keyPair{method: method, signKey: private, verifyKey: public, keyID: base64.RawURLEncoding.EncodeToString(sum[:12])}}, nil
+}
+
+// Rotate makes the key of next sign from now on and keeps the current one, only, to verify the tokens it signed
+// until they expire. The algorithm cannot change; rotating to the current key changes nothing.
+func (k *Keys) Rotate(next *Keys) error {
+	next.mu.RLock()
+	pair := next.current
+	next.mu.RUnlock()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if pair.method.Alg() != k.current.method.Alg() {
+		return fmt.Errorf("cannot rotate %s keys to %s", k.current.method.Alg(), pair.method.Alg())
+	}
+	if pair.same(k.current) {
+		return nil
+	}
+	k.previous, k.current = k.current, pair
+	return nil
+}
+
+func (k *Keys) pairs() (*keyPair, *keyPair) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current, k.previous
+}
+
+func (k *Keys) Algorithm() string {
+	current, _ := k.pairs()
+	return current.method.Alg()
+}
+
+// Sign returns the token of claims, which may carry claims beyond Claims (e.g. jwt.MapClaims)
+func (k *Keys) Sign(claims jwt.Claims) (string, error) {
+	current, _ := k.pairs()
+	token := jwt.NewWithClaims(current.method, claims)
+	if current.keyID != "" {
+		token.Header["kid"] = current.keyID
+	}
+	return token.SignedString(current.signKey)
+}
+
+// Parse verifies the signature and expiry of tokenString and returns its claims, with the current key or the one
+// it replaced. Tokens signed with any other algorithm are refused, so a public key can never be used as an HMAC
+// secret.
+func (k *Keys) Parse(tokenString string) (*Claims, error) {
+	current, previous := k.pairs()
+	claims, err := current.parse(tokenString)
+	var validation *jwt.ValidationError
+	if err != nil && previous != nil && errors.As(err, &validation) && validation.Errors&jwt.ValidationErrorSignatureInvalid != 0 {
+		return previous.parse(tokenString)
+	}
+	return claims, err
+}
+
+func (pair *keyPair) parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != pair.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Header["alg"])
+		}
+		return pair.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// same reports whether other is the same key
+func (pair *keyPair) same(other *keyPair) bool {
+	if pair.keyID != "" || other.keyID != "" {
+		return pair.keyID == other.keyID
+	}
+	secret, _ := pair.signKey.([]byte)
+	otherSecret, _ := other.signKey.([]byte)
+	return string(secret) == string(otherSecret)
+}
+
+// JWKS returns the public keys for resource servers to verify tokens with, the current one first and then the one
+// it replaced; it has none for HMAC, whose secret is never published
+func (k *Keys) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	current, previous := k.pairs()
+	for _, pair := range []*keyPair{current, previous} {
+		if pair == nil {
+			continue
+		}
+		switch public := pair.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType: "RSA", Use: "sig", Algorithm: pair.method.Alg(), KeyID: pair.keyID,
+				N: base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			// Coordinates are padded to the size of the curve
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType: "EC", Use: "sig", Algorithm: pair.method.Alg(), KeyID: pair.keyID, Curve: public.Curve.Params().Name,
+				X: base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size))),
+				Y: base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size))),
+			})
+		}
+	}
+	return jwks
+}
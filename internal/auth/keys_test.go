@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaPEM(t *testing.T, bits int) (string, *rsa.PrivateKey) {
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})), private
+}
+
+func ecPEM(t *testing.T, curve elliptic.Curve) string {
+	private, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(private)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestKeys_SignAndParse(t *testing.T) {
+	rsaKey, _ := rsaPEM(t, 2048)
+	for algorithm, key := range map[string]string{
+		AlgorithmHS256: "hmac-secret",
+		AlgorithmRS256: rsaKey,
+		AlgorithmES256: ecPEM(t, elliptic.P256()),
+	} {
+		keys, err := ParseKeys(algorithm, key)
+		require.NoError(t, err, algorithm)
+		token, err := keys.Sign(NewClaims("ops@example.com", "admin", 7, time.Hour))
+		require.NoError(t, err, algorithm)
+
+		claims, err := keys.Parse(token)
+		require.NoError(t, err, algorithm)
+		assert.Equal(t, uint(7), claims.ID, algorithm)
+		assert.Equal(t, algorithm, keys.Algorithm())
+	}
+}
+
+func TestParseKeys_RefusesUnfitKeys(t *testing.T) {
+	weak, _ := rsaPEM(t, 1024)
+	for name, parse := range map[string]func() (*Keys, error){
+		"empty secret":     func() (*Keys, error) { return ParseKeys(AlgorithmHS256, "") },
+		"secret for RS256": func() (*Keys, error) { return ParseKeys(AlgorithmRS256, "hmac-secret") },
+		"short RSA key":    func() (*Keys, error) { return ParseKeys(AlgorithmRS256, weak) },
+		"P-384 for ES256":  func() (*Keys, error) { return ParseKeys(AlgorithmES256, ecPEM(t, elliptic.P384())) },
+		"unknown":          func() (*Keys, error) { return ParseKeys("none", "") },
+	} {
+		_, err := parse()
+		assert.Error(t, err, name)
+	}
+}
+
+func TestKeys_RefuseOtherAlgorithms(t *testing.T) {
+	key, private := rsaPEM(t, 2048)
+	keys, err := ParseKeys(AlgorithmRS256, key)
+	require.NoError(t, err)
+
+	// The public key, which anyone can fetch, used as an HMAC secret
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	forged, err := Sign(NewClaims("ops@example.com", "admin", 7, time.Hour), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+	require.NoError(t, err)
+	_, err = keys.Parse(forged)
+	assert.Error(t, err)
+
+	hmacToken, err := Sign(NewClaims("ops@example.com", "admin", 7, time.Hour), []byte("hmac-secret"))
+	require.NoError(t, err)
+	_, err = keys.Parse(hmacToken)
+	assert.Error(t, err)
+}
+
+func TestKeys_JWKSVerifiesTokens(t *testing.T) {
+	key, _ := rsaPEM(t, 2048)
+	keys, err := ParseKeys(AlgorithmRS256, key)
+	require.NoError(t, err)
+	token, err := keys.Sign(NewClaims("ops@example.com", "admin", 7, time.Hour))
+	require.NoError(t, err)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	jwk := jwks.Keys[0]
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	require.NoError(t, err)
+	public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	// A resource server holding only the published key
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, jwk.KeyID, token.Header["kid"])
+		return public, nil
+	})
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+
+	assert.Empty(t, NewHMACKeys([]byte("hmac-secret")).JWKS().Keys, "the secret is never published")
+}
+
+func TestKeys_RotateKeepsThePreviousKeyForVerification(t *testing.T) {
+	keys := NewHMACKeys([]byte("old-secret"))
+	oldToken, err := keys.Sign(NewClaims("ops@example.com", "admin", 7, time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, keys.Rotate(NewHMACKeys([]byte("new-secret"))))
+	newToken, err := keys.Sign(NewClaims("ops@example.com", "admin", 7, time.Hour))
+	require.NoError(t, err)
+	_, err = NewHMACKeys([]byte("new-secret")).Parse(newToken)
+	assert.NoError(t, err, "the new key signs")
+	_, err = keys.Parse(oldToken)
+	assert.NoError(t, err, "tokens of the previous key still verify")
+
+	// Only one previous key is kept
+	require.NoError(t, keys.Rotate(NewHMACKeys([]byte("newest-secret"))))
+	_, err = keys.Parse(newToken)
+	assert.NoError(t, err)
+	_, err = keys.Parse(oldToken)
+	assert.Error(t, err)
+
+	rsaKey, _ := rsaPEM(t, 2048)
+	rsaKeys, err := ParseKeys(AlgorithmRS256, rsaKey)
+	require.NoError(t, err)
+	assert.Error(t, keys.Rotate(rsaKeys), "the algorithm cannot change")
+}
+
+func TestKeys_JWKSPublishesThePreviousKey(t *testing.T) {
+	first, err := ParseKeys(AlgorithmES256, ecPEM(t, elliptic.P256()))
+	require.NoError(t, err)
+	second, err := ParseKeys(AlgorithmES256, ecPEM(t, elliptic.P256()))
+	require.NoError(t, err)
+	firstID := first.JWKS().Keys[0].KeyID
+
+	require.NoError(t, first.Rotate(second))
+	require.NoError(t, first.Rotate(second), "rotating to the current key changes nothing")
+	jwks := first.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, second.JWKS().Keys[0].KeyID, jwks.Keys[0].KeyID)
+	assert.Equal(t, firstID, jwks.Keys[1].KeyID)
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers of a request signed by a partner
+const (
+	PartnerHeader   = "X-Partner-ID"
+	TimestampHeader = "X-Signature-Timestamp"
+	SignatureHeader = "X-Signature"
+)
+
+var (
+	ErrSignatureExpired  = errors.New("the signature timestamp is too far from the server clock")
+	ErrSignatureMismatch = errors.New("the signature does not match the request")
+)
+
+// SignRequest returns the hex HMAC-SHA256, keyed by secret, of the timestamp (Unix seconds), the method, the
+// request URI (path and query) and the hex SHA-256 of the body, each on a line of its own
+func SignRequest(secret []byte, timestamp, method, requestURI string, body []byte) string {
+	bodySum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI + "\n" + hex.EncodeToString(bodySum[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks that signature is SignRequest of the request and that timestamp is within skew of now
+func VerifySignature(secret []byte, timestamp, method, requestURI string, body []byte, signature string, now time.Time, skew time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureExpired
+	}
+	if offset := now.Sub(time.Unix(seconds, 0)); offset > skew || offset < -skew {
+		return ErrSignatureExpired
+	}
+	expected := SignRequest(secret, timestamp, method, requestURI, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("partner-secret")
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	timestamp := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+	body := []byte(`{"email":"ann@example.com"}`)
+	signature := SignRequest(secret, timestamp, "POST", "/users?notify=1", body)
+
+	assert.NoError(t, VerifySignature(secret, timestamp, "POST", "/users?notify=1", body, signature, now, 5*time.Minute))
+	assert.ErrorIs(t, VerifySignature(secret, timestamp, "POST", "/users?notify=1", body, signature, now, 30*time.Second), ErrSignatureExpired)
+	assert.ErrorIs(t, VerifySignature(secret, timestamp, "POST", "/users?notify=1", body, signature, now.Add(-7*time.Minute), 5*time.Minute), ErrSignatureExpired,
+		"timestamps ahead of the server clock are refused too")
+	assert.ErrorIs(t, VerifySignature(secret, "yesterday", "POST", "/users?notify=1", body, signature, now, 5*time.Minute), ErrSignatureExpired)
+
+	assert.ErrorIs(t, VerifySignature(secret, timestamp, "POST", "/users?notify=1", []byte(`{"email":"eve@example.com"}`), signature, now, 5*time.Minute), ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySignature(secret, timestamp, "PUT", "/users?notify=1", body, signature, now, 5*time.Minute), ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySignature(secret, timestamp, "POST", "/users", body, signature, now, 5*time.Minute), ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySignature([]byte("other-secret"), timestamp, "POST", "/users?notify=1", body, signature, now, 5*time.Minute), ErrSignatureMismatch)
+}
@@ -0,0 +1,93 @@
+package buildinfo
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Stamped at build time and preferred over what the toolchain records, e.g. in images built without .git:
+//
+//	go build -ldflags "-X gitlab.com/jkozhemiaka/web-layout/internal/buildinfo.version=v1.4.0
+//	  -X gitlab.com/jkozhemiaka/web-layout/internal/buildinfo.commit=$(git rev-parse HEAD)
+//	  -X gitlab.com/jkozhemiaka/web-layout/internal/buildinfo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
+// Info describes the running binary, as recorded by the Go toolchain
+type Info struct {
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// Read returns the version, commit and build time stamped with -ldflags, falling back to the module version and
+// VCS stamp of the binary; fields are empty under `go test` or `go run` without either
+func Read() Info {
+	info := Info{}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Module = build.Main.Path
+		info.Version = build.Main.Version
+		info.GoVersion = build.GoVersion
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Revision = setting.Value
+			case "vcs.time":
+				info.Time = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if version != "" {
+		info.Version = version
+	}
+	if commit != "" {
+		info.Revision = commit
+	}
+	if buildTime != "" {
+		info.Time = buildTime
+	}
+	return info
+}
+
+// String is a one-line summary for logs, e.g. "v1.4.0 (3f2a9c1, built 2024-05-01T10:00:00Z)"
+func (info Info) String() string {
+	summary := info.Version
+	if summary == "" {
+		summary = "unknown version"
+	}
+	details := []string{}
+	if revision := info.Revision; revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if info.Modified {
+			revision += "+dirty"
+		}
+		details = append(details, revision)
+	}
+	if info.Time != "" {
+		details = append(details, "built "+info.Time)
+	}
+	if len(details) > 0 {
+		summary = fmt.Sprintf("%s (%s)", summary, strings.Join(details, ", "))
+	}
+	return summary
+}
+
+// Release names the build in error reports: the module version, or the VCS revision for development builds
+func (info Info) Release() string {
+	if info.Version != "" && info.Version != "(devel)" {
+		return info.Version
+	}
+	return info.Revision
+}
@@ -0,0 +1,23 @@
+package buildinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead_PrefersStampedValues(t *testing.T) {
+	version, commit, buildTime = "v1.4.0", "3f2a9c1d5e7b", "2024-05-01T10:00:00Z"
+	defer func() { version, commit, buildTime = "", "", "" }()
+
+	info := Read()
+	assert.Equal(t, "v1.4.0", info.Version)
+	assert.Equal(t, "3f2a9c1d5e7b", info.Revision)
+	assert.Equal(t, "2024-05-01T10:00:00Z", info.Time)
+}
+
+func TestInfo_String(t *testing.T) {
+	assert.Equal(t, "unknown version", Info{}.String())
+	assert.Equal(t, "v1.4.0 (built 2024-05-01T10:00:00Z)", Info{Version: "v1.4.0", Time: "2024-05-01T10:00:00Z"}.String())
+	assert.Equal(t, "(devel) (3f2a9c1d5e7b+dirty)", Info{Version: "(devel)", Revision: "3f2a9c1d5e7b0a1b2c3d", Modified: true}.String())
+}
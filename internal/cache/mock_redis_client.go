@@ -1,7 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: internal/cache/redis_client.go
 
-// Package mocks is a generated GoMock package.
+// Package cache is a generated GoMock package.
 package cache
 
 import (
@@ -35,6 +35,25 @@ func (m *MockCacheInterface) EXPECT() *MockCacheInterfaceMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockCacheInterface) Delete(ctx context.Context, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCacheInterfaceMockRecorder) Delete(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCacheInterface)(nil).Delete), varargs...)
+}
+
 // Get mocks base method.
 func (m *MockCacheInterface) Get(ctx context.Context, key string, cacheTTL time.Duration) (string, error) {
 	m.ctrl.T.Helper()
@@ -14,6 +14,7 @@ var ctx = context.Background()
 type CacheInterface interface {
 	Get(ctx context.Context, key string, cacheTTL time.Duration) (string, error)
 	Set(ctx context.Context, key string, value string, cacheTTL time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
 }
 
 type RedisClient struct {
@@ -60,3 +61,11 @@ func (r *RedisClient) Set(ctx context.Context, key string, value string, cacheTT
 	}
 	return nil
 }
+
+// Delete видаляє ключі з Redis
+func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.Client.Del(ctx, keys...).Err()
+}
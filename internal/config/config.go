@@ -2,28 +2,407 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 )
 
+// Values accepted by APP_ENV, which also takes the aliases dev, stage and prod. Each selects a Profile.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Values accepted by DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Values accepted by USER_REPO_DRIVER
+const (
+	RepoDriverGorm   = "gorm"
+	RepoDriverPgx    = "pgx"
+	RepoDriverMemory = "memory"
+)
+
+// Mail drivers
+const (
+	MailDriverLog      = "log"
+	MailDriverSMTP     = "smtp"
+	MailDriverSES      = "ses"
+	MailDriverSendGrid = "sendgrid"
+)
+
+// SMS drivers
+const (
+	SMSDriverLog    = "log"
+	SMSDriverTwilio = "twilio"
+)
+
+// Values accepted by SCHEDULER_LOCK
+const (
+	SchedulerLockPostgres = "postgres"
+	SchedulerLockRedis    = "redis"
+	SchedulerLockNone     = "none"
+)
+
+// Config is assembled from, in increasing precedence: the defaults below, the YAML file, the env file,
+// environment variables and command-line flags. Fields tagged secret are hidden by Masked, validate tags
+// are checked by Validate.
 type Config struct {
-	AppPort     string `required:"true" split_words:"true"`
-	PostgresURI string `required:"true" split_words:"true"`
-	RedisURL    string `required:"true" split_words:"true"`
-	JwtKey      string `required:"true" split_words:"true"`
+	AppEnv   string `default:"development" split_words:"true" validate:"oneof=development staging production"`
+	AppPort  string `split_words:"true" validate:"required,port"`
+	RedisURL string `split_words:"true" secret:"url" validate:"required,url"`
+	// JwtKey is required unless VaultJWTKeyPath names the secret holding it: the HMAC secret, or the PEM private key
+	// for JwtAlgorithm RS256 and ES256
+	JwtKey       string `split_words:"true" secret:"true"`
+	JwtAlgorithm string `default:"HS256" split_words:"true" validate:"oneof=HS256 RS256 ES256"`
+	// IntrospectionClients maps the ID of each service allowed to call POST /auth/introspect to its secret, e.g.
+	// "billing:s3cret"; without any the endpoint is not served
+	IntrospectionClients map[string]string `split_words:"true" secret:"true"`
+	// Partners who cannot run OAuth flows sign their requests instead (see auth.SignRequest). PartnerSecrets maps
+	// the ID of each partner to its secret, PartnerAccounts to the email of the service account it acts as and
+	// PartnerScopes to the space-separated permissions it has; PartnerSignatureSkew is how far a signed timestamp
+	// may be from the server clock.
+	PartnerSecrets       map[string]string `split_words:"true" secret:"true"`
+	PartnerAccounts      map[string]string `split_words:"true"`
+	PartnerScopes        map[string]string `split_words:"true"`
+	PartnerSignatureSkew time.Duration     `default:"5m" split_words:"true" validate:"gt=0"`
+	// JwtClaims are the custom claims embedded in login tokens: permissions and scope carry the permissions of the
+	// user's role so routes can check them without a lookup. Every token is bound to its tenant, so tenant is kept
+	// for configurations that still name it.
+	JwtClaims []string `split_words:"true" validate:"dive,oneof=tenant permissions scope"`
+
+	DBDriver    string `default:"postgres" split_words:"true" validate:"oneof=postgres sqlite"`
+	PostgresURI string `split_words:"true" secret:"url" validate:"omitempty,url"`
+	// DBPassword, when set, replaces the password of PostgresURI and the replica URIs
+	DBPassword string `split_words:"true" secret:"true"`
+	// SqliteDSN is used when DB_DRIVER=sqlite, e.g. "file:dev.db" or the default in-memory database
+	SqliteDSN string `default:"file::memory:?cache=shared" split_words:"true"`
+
+	// PostgresReplicaURIs is a comma-separated list of read replicas; reads stay on the primary when empty
+	PostgresReplicaURIs []string `envconfig:"POSTGRES_REPLICA_URIS" secret:"url" validate:"dive,url"`
+	// Connection pool limits, applied to the primary and to every replica
+	DBMaxOpenConns    int           `default:"25" split_words:"true" validate:"gte=0"`
+	DBMaxIdleConns    int           `default:"10" split_words:"true" validate:"gte=0"`
+	DBConnMaxLifetime time.Duration `default:"30m" split_words:"true" validate:"gte=0"`
+	DBConnMaxIdleTime time.Duration `default:"5m" split_words:"true" validate:"gte=0"`
+	// Startup connection retries: delays double from the initial to the max backoff until the timeout
+	DBConnectTimeout        time.Duration `default:"60s" split_words:"true" validate:"gt=0"`
+	DBConnectInitialBackoff time.Duration `default:"500ms" split_words:"true" validate:"gt=0"`
+	DBConnectMaxBackoff     time.Duration `default:"5s" split_words:"true" validate:"gtefield=DBConnectInitialBackoff"`
+	// UserRepoDriver selects the user repository: "gorm", "pgx" for hand-written SQL on the hot paths (Postgres only)
+	// or "memory" to keep users and votes in the process, for demos (development profile only)
+	UserRepoDriver string `default:"gorm" split_words:"true" validate:"oneof=gorm pgx memory"`
+	// AutoMigrate applies pending schema migrations when the server starts
+	AutoMigrate bool `default:"true" split_words:"true"`
+
+	// With TenancyEnabled each request is scoped to the tenant named in TenantHeader or served on the request host
+	TenancyEnabled bool   `default:"false" split_words:"true"`
+	TenantHeader   string `default:"X-Tenant" split_words:"true"`
+
+	// Personal data encryption: base64 encoded 32 byte keys. Without PIIEncryptionKey values are stored in
+	// plaintext; PIIIndexKey keys the email blind index, is required with PIIEncryptionKey and must differ from it,
+	// and must not change once users exist.
+	PIIEncryptionKey string `envconfig:"PII_ENCRYPTION_KEY" secret:"true"`
+	PIIIndexKey      string `envconfig:"PII_INDEX_KEY" secret:"true"`
+
+	// LogLevel is the minimum zap level logged by the server: debug, info, warn or error; the profile's when empty
+	LogLevel string `split_words:"true" reload:"true" validate:"omitempty,oneof=debug info warn error"`
+	// LogFormat is console or json; the profile's when empty. LogOutputPaths are files, stdout or stderr.
+	LogFormat      string   `split_words:"true" validate:"omitempty,oneof=console json"`
+	LogOutputPaths []string `default:"stderr" split_words:"true"`
+	// Profiles that sample log LogSamplingInitial entries with the same message per second, then every
+	// LogSamplingThereafter-th; 0 turns sampling off
+	LogSamplingInitial    int `default:"100" split_words:"true" validate:"gte=0"`
+	LogSamplingThereafter int `default:"100" split_words:"true" validate:"gte=0"`
+	// LogCaller adds the file and line of the logging call; LogStacktraceLevel is the lowest level logged with
+	// a stack trace, the profile's when empty
+	LogCaller          bool   `default:"true" split_words:"true"`
+	LogStacktraceLevel string `split_words:"true" validate:"omitempty,oneof=debug info warn error fatal"`
+	// DBLogLevel is the GORM logger level: silent, error, warn or info; the profile's when empty
+	DBLogLevel string `split_words:"true" validate:"omitempty,oneof=silent error warn info"`
+	// VoteCooldown is how long a user waits between votes
+	VoteCooldown time.Duration `default:"1h" split_words:"true" reload:"true" validate:"gte=0"`
+	// Settings tagged reload are re-read on SIGHUP and when a config file changes, polled every ConfigWatchInterval (0 disables polling)
+	ConfigWatchInterval time.Duration `default:"10s" split_words:"true" validate:"gte=0"`
+
+	// With VaultAddr set the JWT key is read from the KV secret at VaultJWTKeyPath (e.g. "secret/data/usermanagement")
+	// and, with VaultDBRole, database credentials are issued by the database secrets engine and renewed in the background.
+	// Vault is authenticated with VaultToken or an AppRole login.
+	VaultAddr         string `split_words:"true" validate:"omitempty,url"`
+	VaultToken        string `split_words:"true" secret:"true"`
+	VaultRoleID       string `envconfig:"VAULT_ROLE_ID"`
+	VaultSecretID     string `envconfig:"VAULT_SECRET_ID" secret:"true" validate:"required_with=VaultRoleID"`
+	VaultAppRoleMount string `default:"approle" envconfig:"VAULT_APPROLE_MOUNT"`
+	VaultJWTKeyPath   string `envconfig:"VAULT_JWT_KEY_PATH"`
+	VaultJWTKeyField  string `default:"jwt_key" envconfig:"VAULT_JWT_KEY_FIELD"`
+	VaultDBRole       string `envconfig:"VAULT_DB_ROLE"`
+	VaultDBMount      string `default:"database" envconfig:"VAULT_DB_MOUNT"`
+	// The JWT key is read again every VaultJWTKeyRefresh, so a new version signs without a restart; 0 reads it once
+	VaultJWTKeyRefresh time.Duration `default:"5m" envconfig:"VAULT_JWT_KEY_REFRESH_INTERVAL" validate:"gte=0"`
+
+	// Any string setting may instead hold a reference, resolved at startup: "aws-sm:<secret id or ARN>[#field]",
+	// "aws-ssm:<parameter name>" or "vault:<path>#<field>". Values are cached for SecretsCacheTTL.
+	SecretsCacheTTL    time.Duration `default:"5m" split_words:"true" validate:"gte=0"`
+	AWSRegion          string        `envconfig:"AWS_REGION"`
+	AWSAccessKeyID     string        `envconfig:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey string        `envconfig:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	AWSSessionToken    string        `envconfig:"AWS_SESSION_TOKEN" secret:"true"`
+
+	// FeatureFlags switches features on or off, e.g. "voting:false,registration:true"; overrides stored in the
+	// database win and are re-read every FeatureFlagRefreshInterval
+	FeatureFlags               map[string]bool `envconfig:"FEATURE_FLAGS" reload:"true"`
+	FeatureFlagRefreshInterval time.Duration   `default:"30s" split_words:"true" validate:"gt=0"`
+
+	// PermissionRefreshInterval bounds how long other instances keep serving the role permissions they cached
+	// before a change
+	PermissionRefreshInterval time.Duration `default:"30s" split_words:"true" validate:"gt=0"`
+
+	// PprofEnabled serves the net/http/pprof profiles under /debug/pprof/ to admins
+	PprofEnabled bool `default:"false" split_words:"true"`
+	// MetricsEnabled times every database statement and serves the counters to Prometheus at /metrics on
+	// MetricsPort, a listener of its own that is kept off the public ingress
+	MetricsEnabled bool   `default:"false" split_words:"true"`
+	MetricsPort    string `default:"9090" split_words:"true" validate:"port"`
+
+	// SentryDSN reports 5xx errors and panics to Sentry. SentryEnvironment defaults to APP_ENV and SentryRelease
+	// to the module version or VCS revision of the binary.
+	SentryDSN         string `envconfig:"SENTRY_DSN" secret:"true" validate:"omitempty,url"`
+	SentryEnvironment string `envconfig:"SENTRY_ENVIRONMENT"`
+	SentryRelease     string `envconfig:"SENTRY_RELEASE"`
+
+	// MailDriver sends email through SMTP, SES (with the AWS settings above) or SendGrid, or only logs it;
+	// only the development profile defaults to log. Messages are queued as jobs when the job queue is enabled.
+	// SMTPStartTLS refuses relays that do not offer STARTTLS; turning it off sends in the clear to such relays.
+	MailDriver     string `split_words:"true" validate:"omitempty,oneof=log smtp ses sendgrid"`
+	MailFrom       string `split_words:"true" validate:"omitempty,email"`
+	SMTPHost       string `envconfig:"SMTP_HOST"`
+	SMTPPort       string `default:"587" envconfig:"SMTP_PORT" validate:"port"`
+	SMTPUsername   string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword   string `envconfig:"SMTP_PASSWORD" secret:"true"`
+	SMTPStartTLS   bool   `default:"true" envconfig:"SMTP_STARTTLS"`
+	SendGridAPIKey string `envconfig:"SENDGRID_API_KEY" secret:"true"`
+	// MailTemplatesDir holds <locale>/<name>.tmpl files replacing the embedded email templates, or adding locales
+	MailTemplatesDir string `split_words:"true"`
+
+	// SMSDriver sends text messages through Twilio, from TwilioFrom, or only logs them
+	SMSDriver        string `default:"log" envconfig:"SMS_DRIVER" validate:"oneof=log twilio"`
+	TwilioAccountSID string `envconfig:"TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken  string `envconfig:"TWILIO_AUTH_TOKEN" secret:"true"`
+	TwilioFrom       string `envconfig:"TWILIO_FROM"`
+	// A phone code is valid for PhoneCodeTTL and PhoneCodeAttempts wrong guesses. One user is sent at most
+	// PhoneCodeSendLimit codes per PhoneCodeSendWindow; 0 turns the limit off.
+	PhoneCodeTTL        time.Duration `default:"10m" split_words:"true" validate:"gt=0"`
+	PhoneCodeAttempts   int           `default:"5" split_words:"true" validate:"gt=0"`
+	PhoneCodeSendLimit  int           `default:"5" split_words:"true" validate:"gte=0"`
+	PhoneCodeSendWindow time.Duration `default:"1h" split_words:"true" validate:"gt=0"`
+
+	// Invitation emails link to InvitationURL with the token appended as ?token=; the page asks for a password and
+	// posts it to /invitations/accept. An invitation, or its latest resend, is valid for InvitationTTL.
+	InvitationURL string        `default:"http://localhost:3000/invitations/accept" split_words:"true" validate:"url"`
+	InvitationTTL time.Duration `default:"72h" split_words:"true" validate:"gt=0"`
+	// InvitationRetention is how long an expired invitation is kept for resending before the cleanup deletes it
+	InvitationRetention time.Duration `default:"720h" split_words:"true" validate:"gt=0"`
+
+	// OAuth clients exchange an authorization code within OAuthCodeTTL; their tokens are valid for OAuthTokenTTL
+	OAuthCodeTTL  time.Duration `envconfig:"OAUTH_CODE_TTL" default:"1m" validate:"gt=0"`
+	OAuthTokenTTL time.Duration `envconfig:"OAUTH_TOKEN_TTL" default:"1h" validate:"gt=0"`
+
+	// TermsVersion is the current version of the terms of service and privacy policy. Once it changes, signed-in
+	// users are refused with TERMS_NOT_ACCEPTED_ERR until they accept it at /me/terms; empty turns the check off.
+	TermsVersion string `split_words:"true" reload:"true" validate:"max=64"`
+
+	// SignupRateLimit is how many accounts one client address may create with POST /users per SignupRateWindow;
+	// 0 turns the limit off. X-Forwarded-For names the client only on connections from TrustedProxies,
+	// addresses or CIDR ranges.
+	SignupRateLimit  int           `default:"5" split_words:"true" validate:"gte=0"`
+	SignupRateWindow time.Duration `default:"1h" split_words:"true" validate:"gt=0"`
+	TrustedProxies   []string      `split_words:"true" validate:"dive,cidr|ip"`
+
+	// Once an account has LoginThrottleAttempts failed logins within LoginThrottleWindow, from any address, each
+	// further login has to wait after the latest failure: LoginThrottleDelay, doubling with every failure up to
+	// LoginThrottleMaxDelay. 0 attempts turns the throttle off.
+	LoginThrottleAttempts int           `default:"5" split_words:"true" validate:"gte=0"`
+	LoginThrottleWindow   time.Duration `default:"15m" split_words:"true" validate:"gt=0"`
+	LoginThrottleDelay    time.Duration `default:"1s" split_words:"true" validate:"gt=0"`
+	LoginThrottleMaxDelay time.Duration `default:"15m" split_words:"true" validate:"gtefield=LoginThrottleDelay"`
+
+	// A remember-me token signs its device in again within RememberMeTTL, and the token handed out then is valid
+	// for another RememberMeTTL
+	RememberMeTTL time.Duration `default:"720h" envconfig:"REMEMBER_ME_TTL" validate:"gt=0"`
+
+	// Failed logins are also counted per client address, account and user agent in windows of BruteForceWindow.
+	// The failure that brings one of them to its threshold raises a security incident: it is logged, kept for
+	// SecurityIncidentRetention, emitted to the webhook and mailed to SecurityAlertEmails. 0 stops counting a signal.
+	BruteForceIPThreshold        int           `default:"20" envconfig:"BRUTE_FORCE_IP_THRESHOLD" validate:"gte=0"`
+	BruteForceAccountThreshold   int           `default:"10" split_words:"true" validate:"gte=0"`
+	BruteForceUserAgentThreshold int           `default:"50" split_words:"true" validate:"gte=0"`
+	BruteForceWindow             time.Duration `default:"15m" split_words:"true" validate:"gt=0"`
+	SecurityAlertEmails          []string      `split_words:"true" validate:"dive,email"`
+	SecurityIncidentRetention    time.Duration `default:"2160h" split_words:"true" validate:"gt=0"`
+
+	// SecurityEventRetention is how long the logins, password changes and revocations users see under
+	// /me/security-events are kept
+	SecurityEventRetention time.Duration `default:"2160h" split_words:"true" validate:"gt=0"`
+
+	// With LoginAlertsEnabled a login from a device or country the user has not signed in from before mails them a
+	// link to LoginAlertURL with ?token= appended, valid for LoginAlertTTL; the page posts it to
+	// /login-alerts/deny, which locks the account. GeoIPDatabase is a CSV file of address ranges and their
+	// countries; without it logins are only told apart by device.
+	LoginAlertsEnabled bool          `default:"true" split_words:"true"`
+	LoginAlertURL      string        `default:"http://localhost:3000/login-alerts/deny" split_words:"true" validate:"url"`
+	LoginAlertTTL      time.Duration `default:"168h" split_words:"true" validate:"gt=0"`
+	GeoIPDatabase      string        `envconfig:"GEOIP_DATABASE"`
+
+	// APIKeyTiers maps each rate-limit tier of API keys to the requests a key may make per APIKeyRateWindow, 0 for
+	// no limit; keys are created in APIKeyDefaultTier unless given one. Their daily usage is kept for
+	// APIKeyUsageRetention.
+	APIKeyTiers          map[string]int `default:"free:60,standard:600,unlimited:0" envconfig:"API_KEY_TIERS" validate:"min=1,dive,gte=0"`
+	APIKeyDefaultTier    string         `default:"free" envconfig:"API_KEY_DEFAULT_TIER" validate:"required"`
+	APIKeyRateWindow     time.Duration  `default:"1m" envconfig:"API_KEY_RATE_WINDOW" validate:"gt=0"`
+	APIKeyUsageRetention time.Duration  `default:"8784h" envconfig:"API_KEY_USAGE_RETENTION" validate:"gt=0"`
+
+	// DefaultRole names the role of users who sign up; SignupRoles overrides it per signup source, e.g.
+	// "sso:moderator" for the sources in models.SignupSources. Both are checked against the roles table at startup.
+	DefaultRole string            `default:"user" split_words:"true" validate:"required"`
+	SignupRoles map[string]string `split_words:"true"`
+
+	// With QuotasEnabled every tenant is limited to the QuotaMax settings unless it has limits of its own in the
+	// tenant_quotas table; 0 is unlimited. API requests are counted per tenant and UTC day.
+	QuotasEnabled               bool `default:"false" split_words:"true"`
+	QuotaMaxUsers               int  `default:"0" split_words:"true" validate:"gte=0"`
+	QuotaMaxVotesPerDay         int  `default:"0" split_words:"true" validate:"gte=0"`
+	QuotaMaxRequestsPerDay      int  `default:"0" split_words:"true" validate:"gte=0"`
+	QuotaMaxOrganizationMembers int  `default:"0" split_words:"true" validate:"gte=0"`
+
+	// DefaultLanguage answers requests whose Accept-Language matches no supported language, and is used for
+	// notifications and emails, which are sent outside any request
+	DefaultLanguage string `default:"en" split_words:"true" validate:"oneof=en uk"`
+
+	EventSource string `default:"urn:usermanagement" split_words:"true"`
+	WebhookURL  string `split_words:"true" secret:"url" validate:"omitempty,url"`
+	// A webhook delivery is attempted WebhookMaxAttempts times. After WebhookBreakerThreshold failures in a row
+	// deliveries wait WebhookBreakerCooldown before the endpoint is tried again. Attempts are logged in
+	// webhook_deliveries for WebhookDeliveryRetention.
+	WebhookMaxAttempts       int           `default:"10" split_words:"true" validate:"gt=0"`
+	WebhookBreakerThreshold  int           `default:"5" split_words:"true" validate:"gt=0"`
+	WebhookBreakerCooldown   time.Duration `default:"1m" split_words:"true" validate:"gt=0"`
+	WebhookDeliveryRetention time.Duration `default:"720h" split_words:"true" validate:"gt=0"`
+
+	UserCacheEnabled bool          `default:"false" split_words:"true"`
+	UserCacheTTL     time.Duration `default:"5m" split_words:"true" validate:"gt=0"`
+
+	// Users soft-deleted more than ArchiveAfterDays ago are moved to users_archive (or dropped with ArchivePurge)
+	ArchiveEnabled   bool          `default:"false" split_words:"true"`
+	ArchiveAfterDays int           `default:"90" split_words:"true" validate:"gt=0"`
+	ArchiveInterval  time.Duration `default:"1h" split_words:"true" validate:"gt=0"`
+	ArchiveBatchSize int           `default:"500" split_words:"true" validate:"gt=0"`
+	ArchivePurge     bool          `default:"false" split_words:"true"`
+
+	// Users who have not signed in for InactivityAfterDays are anonymized, after a warning email sent
+	// InactivityWarningDays before; admins are never anonymized
+	InactivityEnabled     bool          `default:"false" split_words:"true"`
+	InactivityAfterDays   int           `default:"365" split_words:"true" validate:"gtfield=InactivityWarningDays"`
+	InactivityWarningDays int           `default:"30" split_words:"true" validate:"gt=0"`
+	InactivityInterval    time.Duration `default:"24h" split_words:"true" validate:"gt=0"`
+	InactivityBatchSize   int           `default:"500" split_words:"true" validate:"gt=0"`
+
+	// The job queue runs background work stored in the jobs table on JobWorkers workers, polling every
+	// JobPollInterval. Each attempt may take JobTimeout and a failing job is tried JobMaxAttempts times.
+	JobsEnabled     bool          `default:"true" split_words:"true"`
+	JobWorkers      int           `default:"4" split_words:"true" validate:"gt=0"`
+	JobPollInterval time.Duration `default:"1s" split_words:"true" validate:"gt=0"`
+	JobTimeout      time.Duration `default:"5m" split_words:"true" validate:"gt=0"`
+	JobMaxAttempts  int           `default:"5" split_words:"true" validate:"gt=0"`
+	// JobRetention keeps succeeded jobs that long before the cleanup drops them
+	JobRetention time.Duration `default:"168h" split_words:"true" validate:"gt=0"`
+
+	// Every ChangeFeedRelayInterval the scheduler moves up to ChangeFeedRelayBatchSize changes per transaction
+	// from the outbox into the change feed, so the feed lags the writes by about that long
+	ChangeFeedRelayInterval  time.Duration `default:"1s" split_words:"true" validate:"gt=0"`
+	ChangeFeedRelayBatchSize int           `default:"500" split_words:"true" validate:"gt=0"`
+
+	// Every CleanupInterval expired rows are deleted, CleanupBatchSize per statement
+	CleanupEnabled   bool          `default:"true" split_words:"true"`
+	CleanupInterval  time.Duration `default:"1h" split_words:"true" validate:"gt=0"`
+	CleanupBatchSize int           `default:"1000" split_words:"true" validate:"gt=0"`
+
+	// Periodic tasks run on the replica holding SchedulerLockName: a Postgres advisory lock, a Redis lease or,
+	// with none, no lock at all. SchedulerLockInterval paces the attempts and the Redis lease is three times it.
+	SchedulerLock         string        `split_words:"true" validate:"omitempty,oneof=postgres redis none"`
+	SchedulerLockName     string        `default:"usermanagement:scheduler" split_words:"true" validate:"required"`
+	SchedulerLockInterval time.Duration `default:"10s" split_words:"true" validate:"gt=0"`
+
+	// Outside development, HTTPS certificates for AutocertDomains are obtained from Let's Encrypt (its staging
+	// directory with APP_ENV=staging) and cached in AutocertCacheDir. AutocertHTTPPort answers the HTTP-01
+	// challenges and redirects other requests to HTTPS (port 443, so APP_PORT should be 443).
+	AutocertDomains  []string `split_words:"true"`
+	AutocertEmail    string   `split_words:"true"`
+	AutocertCacheDir string   `default:"certs" split_words:"true"`
+	AutocertHTTPPort string   `default:"80" envconfig:"AUTOCERT_HTTP_PORT" validate:"omitempty,port"`
+
+	// With MTLSPort set the API is also served there, over TLS with MTLSCertFile and MTLSKeyFile, to callers whose
+	// client certificate is signed by the CA bundle MTLSClientCA. MTLSIdentities maps the subject common name or a
+	// DNS name of the certificate to the email of the service account the caller acts as, e.g.
+	// billing.internal:billing@svc.example.com; certificates of other identities are refused. MTLSScopes maps the
+	// same names to the space-separated permissions the service account has with the certificate.
+	MTLSPort       string            `envconfig:"MTLS_PORT" validate:"omitempty,port"`
+	MTLSCertFile   string            `envconfig:"MTLS_CERT_FILE" validate:"required_with=MTLSPort"`
+	MTLSKeyFile    string            `envconfig:"MTLS_KEY_FILE" validate:"required_with=MTLSPort"`
+	MTLSClientCA   string            `envconfig:"MTLS_CLIENT_CA" validate:"required_with=MTLSPort"`
+	MTLSIdentities map[string]string `envconfig:"MTLS_IDENTITIES" validate:"required_with=MTLSPort"`
+	MTLSScopes     map[string]string `envconfig:"MTLS_SCOPES"`
+
+	// Initial admin account created by the seed command
+	SeedAdminEmail    string `split_words:"true"`
+	SeedAdminPassword string `split_words:"true" secret:"true"`
 }
 
+// NewConfig reads the env file named by CONFIG_PATH and the YAML file named by File (or CONFIG_FILE).
+// At least one of them is required; environment variables and flags override both.
 func NewConfig() (*Config, error) {
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		return nil, apperrors.EnvConfigVarError.AppendMessage("please set CONFIG_PATH")
+	captureEnv()
+	return load()
+}
+
+// sourceFiles returns the env file and YAML file paths in use; either may be empty
+func sourceFiles() (string, string) {
+	configFile := File
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
 	}
+	return os.Getenv("CONFIG_PATH"), configFile
+}
 
-	err := godotenv.Load(configPath)
+func load() (*Config, error) {
+	// Applied first: the files never replace variables that are already set
+	err := applyFlags()
 	if err != nil {
-		return nil, apperrors.EnvConfigLoadError.AppendMessage(err)
+		return nil, apperrors.EnvConfigParseError.AppendMessage(err)
+	}
+
+	configPath, configFile := sourceFiles()
+	if configPath == "" && configFile == "" {
+		return nil, apperrors.EnvConfigVarError.AppendMessage("please set CONFIG_PATH or CONFIG_FILE")
+	}
+
+	if configPath != "" {
+		err := godotenv.Load(configPath)
+		if err != nil {
+			return nil, apperrors.EnvConfigLoadError.AppendMessage(err)
+		}
+	}
+
+	if configFile != "" {
+		err := loadFile(configFile)
+		if err != nil {
+			return nil, apperrors.EnvConfigLoadError.AppendMessage(err)
+		}
 	}
 
 	config := &Config{}
@@ -31,6 +410,18 @@ func NewConfig() (*Config, error) {
 	if err != nil {
 		return nil, apperrors.EnvConfigParseError.AppendMessage(err)
 	}
+	config.AppEnv = canonicalEnv(config.AppEnv)
 
 	return config, nil
 }
+
+// EffectiveSchedulerLock is SCHEDULER_LOCK, or postgres with DB_DRIVER=postgres and none otherwise
+func (c *Config) EffectiveSchedulerLock() string {
+	if c.SchedulerLock != "" {
+		return c.SchedulerLock
+	}
+	if c.DBDriver == DriverPostgres {
+		return SchedulerLockPostgres
+	}
+	return SchedulerLockNone
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the structured config file read by NewConfig; it falls back to the CONFIG_FILE environment variable.
+// RegisterFlags binds it to the --config flag.
+var File string
+
+// fileKeys maps "section.key" in the config file to the environment variable it supplies
+var fileKeys = map[string]string{
+	"server.env":                   "APP_ENV",
+	"server.port":                  "APP_PORT",
+	"server.default_language":      "DEFAULT_LANGUAGE",
+	"server.event_source":          "EVENT_SOURCE",
+	"server.webhook_url":           "WEBHOOK_URL",
+	"webhooks.max_attempts":        "WEBHOOK_MAX_ATTEMPTS",
+	"webhooks.breaker_threshold":   "WEBHOOK_BREAKER_THRESHOLD",
+	"webhooks.breaker_cooldown":    "WEBHOOK_BREAKER_COOLDOWN",
+	"webhooks.delivery_retention":  "WEBHOOK_DELIVERY_RETENTION",
+	"server.tenancy_enabled":       "TENANCY_ENABLED",
+	"server.tenant_header":         "TENANT_HEADER",
+	"server.vote_cooldown":         "VOTE_COOLDOWN",
+	"server.config_watch_interval": "CONFIG_WATCH_INTERVAL",
+	"server.autocert_domains":      "AUTOCERT_DOMAINS",
+	"server.autocert_email":        "AUTOCERT_EMAIL",
+	"server.autocert_cache_dir":    "AUTOCERT_CACHE_DIR",
+	"server.autocert_http_port":    "AUTOCERT_HTTP_PORT",
+	"server.mtls_port":             "MTLS_PORT",
+	"server.mtls_cert_file":        "MTLS_CERT_FILE",
+	"server.mtls_key_file":         "MTLS_KEY_FILE",
+	"server.mtls_client_ca":        "MTLS_CLIENT_CA",
+	"server.mtls_identities":       "MTLS_IDENTITIES",
+	"server.mtls_scopes":           "MTLS_SCOPES",
+	"database.driver":              "DB_DRIVER",
+	"database.postgres_uri":        "POSTGRES_URI",
+	"database.sqlite_dsn":          "SQLITE_DSN",
+	"database.replica_uris":        "POSTGRES_REPLICA_URIS",
+	"database.max_open_conns":      "DB_MAX_OPEN_CONNS",
+	"database.max_idle_conns":      "DB_MAX_IDLE_CONNS",
+	"database.conn_max_lifetime":   "DB_CONN_MAX_LIFETIME",
+	"database.conn_max_idle_time":  "DB_CONN_MAX_IDLE_TIME",
+	"database.connect_timeout":     "DB_CONNECT_TIMEOUT",
+	"database.connect_backoff":     "DB_CONNECT_INITIAL_BACKOFF",
+	"database.connect_max_backoff": "DB_CONNECT_MAX_BACKOFF",
+	"database.user_repo_driver":    "USER_REPO_DRIVER",
+	"database.auto_migrate":        "AUTO_MIGRATE",
+	"database.redis_url":           "REDIS_URL",
+	"database.log_level":           "DB_LOG_LEVEL",
+	"auth.jwt_key":                 "JWT_KEY",
+	"auth.jwt_algorithm":           "JWT_ALGORITHM",
+	"auth.introspection_clients":   "INTROSPECTION_CLIENTS",
+	"auth.partner_secrets":         "PARTNER_SECRETS",
+	"auth.partner_accounts":        "PARTNER_ACCOUNTS",
+	"auth.partner_scopes":          "PARTNER_SCOPES",
+	"auth.partner_signature_skew":  "PARTNER_SIGNATURE_SKEW",
+	"auth.jwt_claims":              "JWT_CLAIMS",
+	"auth.pii_encryption_key":      "PII_ENCRYPTION_KEY",
+	"auth.pii_index_key":           "PII_INDEX_KEY",
+	"logging.level":                "LOG_LEVEL",
+	"logging.format":               "LOG_FORMAT",
+	"logging.output_paths":         "LOG_OUTPUT_PATHS",
+	"logging.sampling_initial":     "LOG_SAMPLING_INITIAL",
+	"logging.sampling_thereafter":  "LOG_SAMPLING_THEREAFTER",
+	"logging.caller":               "LOG_CALLER",
+	"logging.stacktrace_level":     "LOG_STACKTRACE_LEVEL",
+	"debug.pprof":                  "PPROF_ENABLED",
+	"metrics.enabled":              "METRICS_ENABLED",
+	"metrics.port":                 "METRICS_PORT",
+	"jobs.enabled":                 "JOBS_ENABLED",
+	"jobs.workers":                 "JOB_WORKERS",
+	"jobs.poll_interval":           "JOB_POLL_INTERVAL",
+	"jobs.timeout":                 "JOB_TIMEOUT",
+	"jobs.max_attempts":            "JOB_MAX_ATTEMPTS",
+	"jobs.retention":               "JOB_RETENTION",
+	"change_feed.relay_interval":   "CHANGE_FEED_RELAY_INTERVAL",
+	"change_feed.relay_batch_size": "CHANGE_FEED_RELAY_BATCH_SIZE",
+	"cleanup.enabled":              "CLEANUP_ENABLED",
+	"cleanup.interval":             "CLEANUP_INTERVAL",
+	"cleanup.batch_size":           "CLEANUP_BATCH_SIZE",
+	"scheduler.lock":               "SCHEDULER_LOCK",
+	"scheduler.lock_name":          "SCHEDULER_LOCK_NAME",
+	"scheduler.lock_interval":      "SCHEDULER_LOCK_INTERVAL",
+	"mail.driver":                  "MAIL_DRIVER",
+	"mail.from":                    "MAIL_FROM",
+	"mail.smtp_host":               "SMTP_HOST",
+	"mail.smtp_port":               "SMTP_PORT",
+	"mail.smtp_username":           "SMTP_USERNAME",
+	"mail.smtp_password":           "SMTP_PASSWORD",
+	"mail.smtp_starttls":           "SMTP_STARTTLS",
+	"mail.sendgrid_api_key":        "SENDGRID_API_KEY",
+	"mail.templates_dir":           "MAIL_TEMPLATES_DIR",
+	"sms.driver":                   "SMS_DRIVER",
+	"sms.twilio_account_sid":       "TWILIO_ACCOUNT_SID",
+	"sms.twilio_auth_token":        "TWILIO_AUTH_TOKEN",
+	"sms.twilio_from":              "TWILIO_FROM",
+	"sms.code_ttl":                 "PHONE_CODE_TTL",
+	"sms.code_attempts":            "PHONE_CODE_ATTEMPTS",
+	"sms.code_send_limit":          "PHONE_CODE_SEND_LIMIT",
+	"sms.code_send_window":         "PHONE_CODE_SEND_WINDOW",
+	"invitations.url":              "INVITATION_URL",
+	"invitations.ttl":              "INVITATION_TTL",
+	"invitations.retention":        "INVITATION_RETENTION",
+	"oauth.code_ttl":               "OAUTH_CODE_TTL",
+	"oauth.token_ttl":              "OAUTH_TOKEN_TTL",
+	"terms.version":                "TERMS_VERSION",
+	"server.trusted_proxies":       "TRUSTED_PROXIES",
+	"signup.rate_limit":            "SIGNUP_RATE_LIMIT",
+	"signup.rate_window":           "SIGNUP_RATE_WINDOW",
+	"signup.default_role":          "DEFAULT_ROLE",
+	"login.throttle_attempts":      "LOGIN_THROTTLE_ATTEMPTS",
+	"login.throttle_window":        "LOGIN_THROTTLE_WINDOW",
+	"login.throttle_delay":         "LOGIN_THROTTLE_DELAY",
+	"login.throttle_max_delay":     "LOGIN_THROTTLE_MAX_DELAY",
+	"login.remember_me_ttl":        "REMEMBER_ME_TTL",
+	"brute_force.ip":               "BRUTE_FORCE_IP_THRESHOLD",
+	"brute_force.account":          "BRUTE_FORCE_ACCOUNT_THRESHOLD",
+	"brute_force.user_agent":       "BRUTE_FORCE_USER_AGENT_THRESHOLD",
+	"brute_force.window":           "BRUTE_FORCE_WINDOW",
+	"brute_force.alert_emails":     "SECURITY_ALERT_EMAILS",
+	"brute_force.retention":        "SECURITY_INCIDENT_RETENTION",
+	"security_events.retention":    "SECURITY_EVENT_RETENTION",
+	"login_alerts.enabled":         "LOGIN_ALERTS_ENABLED",
+	"login_alerts.url":             "LOGIN_ALERT_URL",
+	"login_alerts.ttl":             "LOGIN_ALERT_TTL",
+	"geoip.database":               "GEOIP_DATABASE",
+	"api_keys.tiers":               "API_KEY_TIERS",
+	"api_keys.default_tier":        "API_KEY_DEFAULT_TIER",
+	"api_keys.rate_window":         "API_KEY_RATE_WINDOW",
+	"api_keys.usage_retention":     "API_KEY_USAGE_RETENTION",
+	"signup.roles":                 "SIGNUP_ROLES",
+	"quotas.enabled":               "QUOTAS_ENABLED",
+	"quotas.max_users":             "QUOTA_MAX_USERS",
+	"quotas.max_votes_per_day":     "QUOTA_MAX_VOTES_PER_DAY",
+	"quotas.max_requests_per_day":  "QUOTA_MAX_REQUESTS_PER_DAY",
+	"quotas.max_members":           "QUOTA_MAX_ORGANIZATION_MEMBERS",
+	"sentry.dsn":                   "SENTRY_DSN",
+	"sentry.environment":           "SENTRY_ENVIRONMENT",
+	"sentry.release":               "SENTRY_RELEASE",
+	"features.flags":               "FEATURE_FLAGS",
+	"features.refresh_interval":    "FEATURE_FLAG_REFRESH_INTERVAL",
+}
+
+// loadFile reads a YAML config file and exports its values as environment variables, leaving variables that
+// are already set alone so the environment overrides the file. Unknown keys are rejected to catch typos.
+func loadFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		return errors.Errorf("config file %s: TOML is not supported, use YAML", path)
+	default:
+		return errors.Errorf("config file %s: unknown format, expected .yaml or .yml", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var sections map[string]map[string]interface{}
+	err = yaml.Unmarshal(data, &sections)
+	if err != nil {
+		return errors.Wrapf(err, "config file %s", path)
+	}
+
+	values, err := flattenFile(sections)
+	if err != nil {
+		return errors.Wrapf(err, "config file %s", path)
+	}
+
+	for name, value := range values {
+		if _, set := os.LookupEnv(name); set {
+			continue
+		}
+		err = os.Setenv(name, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenFile converts the file sections into environment variable values; lists become comma-separated
+func flattenFile(sections map[string]map[string]interface{}) (map[string]string, error) {
+	var unknown []string
+	values := make(map[string]string)
+	for section, keys := range sections {
+		for key, value := range keys {
+			name, ok := fileKeys[section+"."+key]
+			if !ok {
+				unknown = append(unknown, section+"."+key)
+				continue
+			}
+			if value == nil {
+				continue
+			}
+
+			if list, isList := value.([]interface{}); isList {
+				items := make([]string, len(list))
+				for i, item := range list {
+					items[i] = fmt.Sprint(item)
+				}
+				values[name] = strings.Join(items, ",")
+				continue
+			}
+			if mapping, isMap := value.(map[string]interface{}); isMap {
+				items := make([]string, 0, len(mapping))
+				for key, item := range mapping {
+					items = append(items, fmt.Sprintf("%s:%v", key, item))
+				}
+				sort.Strings(items)
+				values[name] = strings.Join(items, ",")
+				continue
+			}
+			values[name] = fmt.Sprint(value)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.Errorf("unknown keys %s", strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
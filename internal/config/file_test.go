@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewConfig_ReadsYAMLFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  port: "8080"
+database:
+  driver: sqlite
+  replica_uris: [postgres://a, postgres://b]
+  conn_max_lifetime: 10m
+  auto_migrate: false
+  redis_url: redis://localhost:6379
+auth:
+  jwt_key: from-file
+logging:
+  level: warn
+features:
+  flags:
+    voting: false
+`)
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("CONFIG_FILE", path)
+	// Environment variables win over the file
+	t.Setenv("JWT_KEY", "from-env")
+	for _, name := range []string{"APP_PORT", "DB_DRIVER", "POSTGRES_REPLICA_URIS", "DB_CONN_MAX_LIFETIME", "AUTO_MIGRATE", "REDIS_URL", "LOG_LEVEL", "FEATURE_FLAGS"} {
+		unsetEnv(t, name)
+	}
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", cfg.AppPort)
+	assert.Equal(t, DriverSQLite, cfg.DBDriver)
+	assert.Equal(t, []string{"postgres://a", "postgres://b"}, cfg.PostgresReplicaURIs)
+	assert.Equal(t, 10*time.Minute, cfg.DBConnMaxLifetime)
+	assert.False(t, cfg.AutoMigrate)
+	assert.Equal(t, "from-env", cfg.JwtKey)
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.Equal(t, map[string]bool{"voting": false}, cfg.FeatureFlags)
+}
+
+func TestNewConfig_RejectsBadFiles(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "")
+
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "config.yaml", "database:\n  drivr: sqlite\n"))
+	_, err := NewConfig()
+	assert.ErrorContains(t, err, "database.drivr")
+
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "config.toml", "[database]\n"))
+	_, err = NewConfig()
+	assert.ErrorContains(t, err, "TOML is not supported")
+
+	t.Setenv("CONFIG_FILE", "")
+	_, err = NewConfig()
+	assert.ErrorContains(t, err, "CONFIG_PATH or CONFIG_FILE")
+}
+
+// unsetEnv removes name for the duration of the test, restoring its previous value afterwards
+func unsetEnv(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv(name, "")
+	require.NoError(t, os.Unsetenv(name))
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/pflag"
+)
+
+// setting is one Config field together with the environment variable envconfig reads it from
+type setting struct {
+	Key   string
+	Field string
+}
+
+var settingsTemplate = template.Must(template.New("settings").Parse("{{range .}}{{.Key}} {{.Name}}\n{{end}}"))
+
+// settings lists the Config fields in declaration order, named exactly as envconfig names them
+func settings() []setting {
+	var out bytes.Buffer
+	err := envconfig.Usaget("", &Config{}, &out, settingsTemplate)
+	if err != nil {
+		panic(err)
+	}
+
+	var list []setting
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		list = append(list, setting{Key: parts[0], Field: parts[1]})
+	}
+	return list
+}
+
+// flagSet holds the flags registered by RegisterFlags; NewConfig applies those given on the command line
+var flagSet *pflag.FlagSet
+
+// RegisterFlags adds --config and one flag per setting to fs, e.g. --app-port for APP_PORT.
+// Flags given on the command line override the environment and both config files.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&File, "config", "", "YAML config file (defaults to CONFIG_FILE)")
+	for _, s := range settings() {
+		fs.String(flagName(s.Key), "", "overrides "+s.Key)
+	}
+	flagSet = fs
+}
+
+// applyFlags exports the flags set on the command line as environment variables, replacing existing values
+func applyFlags() error {
+	if flagSet == nil {
+		return nil
+	}
+
+	keys := make(map[string]string)
+	for _, s := range settings() {
+		keys[flagName(s.Key)] = s.Key
+	}
+
+	var err error
+	flagSet.Visit(func(f *pflag.Flag) {
+		key, ok := keys[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		err = os.Setenv(key, f.Value.String())
+	})
+	return err
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Values of the secret struct tag
+const (
+	secretFull = "true" // the whole value is hidden
+	secretURL  = "url"  // only the password of a URL is hidden
+)
+
+const maskedValue = "******"
+
+// Masked renders the effective configuration as KEY=value lines in declaration order, with secrets hidden
+func (c *Config) Masked() string {
+	var b strings.Builder
+	c.eachMasked(func(key, value string) {
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	})
+	return b.String()
+}
+
+// MaskedValues is Masked as a map from variable name to value
+func (c *Config) MaskedValues() map[string]string {
+	values := make(map[string]string)
+	c.eachMasked(func(key, value string) {
+		values[key] = value
+	})
+	return values
+}
+
+func (c *Config) eachMasked(fn func(key, value string)) {
+	value := reflect.ValueOf(c).Elem()
+	configType := value.Type()
+	for _, s := range settings() {
+		field, _ := configType.FieldByName(s.Field)
+		fn(s.Key, maskValue(value.FieldByName(s.Field), field.Tag.Get("secret")))
+	}
+}
+
+func maskValue(v reflect.Value, secret string) string {
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = maskValue(v.Index(i), secret)
+		}
+		return strings.Join(items, ",")
+	}
+	if v.Kind() == reflect.Map {
+		items := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			items = append(items, fmt.Sprintf("%v:%s", key.Interface(), maskValue(v.MapIndex(key), secret)))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+
+	text := fmt.Sprint(v.Interface())
+	if text == "" {
+		return ""
+	}
+	switch secret {
+	case secretFull:
+		return maskedValue
+	case secretURL:
+		parsed, err := url.Parse(text)
+		if err != nil {
+			return maskedValue
+		}
+		return parsed.Redacted()
+	}
+	return text
+}
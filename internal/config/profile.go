@@ -0,0 +1,136 @@
+package config
+
+import "strings"
+
+// Profile groups the behaviour that differs between environments, selected by APP_ENV
+type Profile struct {
+	Name string
+	// DevelopmentLogging selects zap's human-readable console logger; otherwise logs are JSON
+	DevelopmentLogging bool
+	// LogLevel and StacktraceLevel apply when LOG_LEVEL and LOG_STACKTRACE_LEVEL are not set
+	LogLevel        string
+	StacktraceLevel string
+	// LogSampling drops repeated log entries as configured by LOG_SAMPLING_INITIAL and LOG_SAMPLING_THEREAFTER
+	LogSampling bool
+	// DBLogLevel is the GORM logger level (silent, error, warn or info) when DB_LOG_LEVEL is not set
+	DBLogLevel string
+	// Autocert serves HTTPS with certificates from ACMEDirectoryURL when AUTOCERT_DOMAINS is set
+	Autocert         bool
+	ACMEDirectoryURL string
+	// Seed allows the seed command; SeedFakeUsers also allows its -fake-users option
+	Seed          bool
+	SeedFakeUsers bool
+	// StackTraces records where errors are created and returns the stack and cause chain in error responses
+	StackTraces bool
+	// MemoryRepos allows USER_REPO_DRIVER=memory, which loses every user and vote on restart
+	MemoryRepos bool
+	// LogMail makes MAIL_DRIVER default to log, which sends nothing; other profiles require a driver
+	LogMail bool
+}
+
+// Let's Encrypt directories; staging certificates are untrusted but not rate limited
+const (
+	letsEncryptURL        = "https://acme-v02.api.letsencrypt.org/directory"
+	letsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+var profiles = map[string]Profile{
+	EnvDevelopment: {
+		Name:               EnvDevelopment,
+		DevelopmentLogging: true,
+		LogLevel:           "debug",
+		StacktraceLevel:    "warn",
+		DBLogLevel:         "info",
+		Seed:               true,
+		SeedFakeUsers:      true,
+		StackTraces:        true,
+		MemoryRepos:        true,
+		LogMail:            true,
+	},
+	EnvStaging: {
+		Name:             EnvStaging,
+		LogLevel:         "info",
+		StacktraceLevel:  "error",
+		LogSampling:      true,
+		DBLogLevel:       "warn",
+		Autocert:         true,
+		ACMEDirectoryURL: letsEncryptStagingURL,
+		Seed:             true,
+	},
+	EnvProduction: {
+		Name:             EnvProduction,
+		LogLevel:         "info",
+		StacktraceLevel:  "error",
+		LogSampling:      true,
+		DBLogLevel:       "error",
+		Autocert:         true,
+		ACMEDirectoryURL: letsEncryptURL,
+	},
+}
+
+// envAliases lets APP_ENV use the short profile names
+var envAliases = map[string]string{
+	"dev":   EnvDevelopment,
+	"stage": EnvStaging,
+	"prod":  EnvProduction,
+}
+
+// canonicalEnv maps an APP_ENV alias to the profile name, leaving other values for Validate to reject
+func canonicalEnv(env string) string {
+	env = strings.ToLower(env)
+	if name, ok := envAliases[env]; ok {
+		return name
+	}
+	return env
+}
+
+// Profile returns the profile named by APP_ENV, development when it is unknown
+func (c *Config) Profile() Profile {
+	if profile, ok := profiles[c.AppEnv]; ok {
+		return profile
+	}
+	return profiles[EnvDevelopment]
+}
+
+// EffectiveLogLevel is LOG_LEVEL, or the profile's level when it is not set
+func (c *Config) EffectiveLogLevel() string {
+	if c.LogLevel != "" {
+		return c.LogLevel
+	}
+	return c.Profile().LogLevel
+}
+
+// EffectiveDBLogLevel is DB_LOG_LEVEL, or the profile's level when it is not set
+func (c *Config) EffectiveDBLogLevel() string {
+	if c.DBLogLevel != "" {
+		return c.DBLogLevel
+	}
+	return c.Profile().DBLogLevel
+}
+
+// EffectiveMailDriver is MAIL_DRIVER, or log when it is not set and the profile allows it
+func (c *Config) EffectiveMailDriver() string {
+	if c.MailDriver == "" && c.Profile().LogMail {
+		return MailDriverLog
+	}
+	return c.MailDriver
+}
+
+// EffectiveLogFormat is LOG_FORMAT, or console for profiles with development logging and json otherwise
+func (c *Config) EffectiveLogFormat() string {
+	if c.LogFormat != "" {
+		return c.LogFormat
+	}
+	if c.Profile().DevelopmentLogging {
+		return "console"
+	}
+	return "json"
+}
+
+// EffectiveStacktraceLevel is LOG_STACKTRACE_LEVEL, or the profile's level when it is not set
+func (c *Config) EffectiveStacktraceLevel() string {
+	if c.LogStacktraceLevel != "" {
+		return c.LogStacktraceLevel
+	}
+	return c.Profile().StacktraceLevel
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig_SelectsProfileFromAlias(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "server:\n  port: \"7000\"\ndatabase:\n  redis_url: redis://localhost:6379\n")
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("APP_ENV", "stage")
+	for _, name := range []string{"LOG_LEVEL", "DB_LOG_LEVEL"} {
+		unsetEnv(t, name)
+	}
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, EnvStaging, cfg.AppEnv)
+	profile := cfg.Profile()
+	assert.False(t, profile.DevelopmentLogging)
+	assert.Equal(t, letsEncryptStagingURL, profile.ACMEDirectoryURL)
+	assert.True(t, profile.Seed)
+	assert.False(t, profile.SeedFakeUsers)
+	assert.False(t, profile.StackTraces)
+	assert.Equal(t, "info", cfg.EffectiveLogLevel())
+	assert.Equal(t, "warn", cfg.EffectiveDBLogLevel())
+}
+
+func TestConfig_ExplicitLevelsBeatProfile(t *testing.T) {
+	cfg := &Config{AppEnv: EnvProduction, LogLevel: "debug", DBLogLevel: "info"}
+
+	assert.False(t, cfg.Profile().Seed)
+	assert.Equal(t, "debug", cfg.EffectiveLogLevel())
+	assert.Equal(t, "info", cfg.EffectiveDBLogLevel())
+	assert.Equal(t, EnvDevelopment, (&Config{}).Profile().Name)
+}
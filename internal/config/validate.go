@@ -0,0 +1,193 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// ValidationError lists every problem found in a Config, each prefixed with the variable that sets it
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Validate checks the settings once secret references are resolved and reports all problems together.
+// Simple rules live in the validate struct tags; rules spanning several settings are checked below.
+func (c *Config) Validate() error {
+	keys := settingKeys()
+	problems, err := tagProblems(c, keys)
+	if err != nil {
+		return err
+	}
+	add := func(field, problem string) {
+		problems = append(problems, keys[field]+": "+problem)
+	}
+
+	if c.JwtKey == "" {
+		add("JwtKey", "is required unless VAULT_JWT_KEY_PATH is set")
+	}
+	if _, ok := c.APIKeyTiers[c.APIKeyDefaultTier]; !ok {
+		add("APIKeyDefaultTier", "is not one of API_KEY_TIERS")
+	}
+	for partner := range c.PartnerSecrets {
+		if c.PartnerAccounts[partner] == "" {
+			add("PartnerAccounts", "has no service account for partner "+partner)
+		}
+	}
+	for partner := range c.PartnerScopes {
+		if _, ok := c.PartnerSecrets[partner]; !ok {
+			add("PartnerScopes", "names partner "+partner+", which has no secret")
+		}
+	}
+	for identity := range c.MTLSScopes {
+		if _, ok := c.MTLSIdentities[identity]; !ok {
+			add("MTLSScopes", "names "+identity+", which is not one of MTLS_IDENTITIES")
+		}
+	}
+	if c.DBDriver == DriverPostgres && c.PostgresURI == "" {
+		add("PostgresURI", "is required with DB_DRIVER="+DriverPostgres)
+	}
+	if c.DBDriver != DriverPostgres && len(c.PostgresReplicaURIs) > 0 {
+		add("PostgresReplicaURIs", "read replicas require DB_DRIVER="+DriverPostgres)
+	}
+	if c.UserRepoDriver == RepoDriverPgx && c.DBDriver != DriverPostgres {
+		add("UserRepoDriver", RepoDriverPgx+" requires DB_DRIVER="+DriverPostgres)
+	}
+	if c.UserRepoDriver == RepoDriverMemory && !c.Profile().MemoryRepos {
+		add("UserRepoDriver", RepoDriverMemory+" is not available with the "+c.Profile().Name+" profile (APP_ENV)")
+	}
+	if c.TenancyEnabled && c.TenantHeader == "" {
+		add("TenantHeader", "is required with TENANCY_ENABLED=true")
+	}
+	if c.PIIEncryptionKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.PIIEncryptionKey); err != nil || len(key) != 32 {
+			add("PIIEncryptionKey", "must be 32 bytes, base64 encoded")
+		}
+	}
+	// An empty index key would make the blind index a plain hash of the email, so it is required with encryption
+	if c.PIIIndexKey != "" || c.PIIEncryptionKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.PIIIndexKey); err != nil || len(key) != 32 {
+			add("PIIIndexKey", "must be 32 bytes, base64 encoded")
+		} else if c.PIIIndexKey == c.PIIEncryptionKey {
+			add("PIIIndexKey", "must differ from PII_ENCRYPTION_KEY")
+		}
+	}
+	if c.SchedulerLock == SchedulerLockPostgres && c.DBDriver != DriverPostgres {
+		add("SchedulerLock", SchedulerLockPostgres+" requires DB_DRIVER="+DriverPostgres)
+	}
+	if c.EffectiveMailDriver() == "" {
+		add("MailDriver", "is required with the "+c.Profile().Name+" profile (APP_ENV)")
+	}
+	if c.MailDriver != MailDriverLog && c.MailDriver != "" && c.MailFrom == "" {
+		add("MailFrom", "is required with MAIL_DRIVER="+c.MailDriver)
+	}
+	if c.MailDriver == MailDriverSMTP && c.SMTPHost == "" {
+		add("SMTPHost", "is required with MAIL_DRIVER="+MailDriverSMTP)
+	}
+	if c.MailDriver == MailDriverSES && c.AWSRegion == "" {
+		add("AWSRegion", "is required with MAIL_DRIVER="+MailDriverSES)
+	}
+	if c.MailDriver == MailDriverSendGrid && c.SendGridAPIKey == "" {
+		add("SendGridAPIKey", "is required with MAIL_DRIVER="+MailDriverSendGrid)
+	}
+	if c.SMSDriver == SMSDriverTwilio && (c.TwilioAccountSID == "" || c.TwilioAuthToken == "" || c.TwilioFrom == "") {
+		add("SMSDriver", "needs TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM with SMS_DRIVER="+SMSDriverTwilio)
+	}
+	if c.VaultAddr != "" && c.VaultToken == "" && c.VaultRoleID == "" {
+		add("VaultAddr", "needs VAULT_TOKEN or VAULT_ROLE_ID")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateFields checks the validate tags of the named fields only
+func (c *Config) validateFields(fields ...string) error {
+	problems, err := tagProblems(c, settingKeys(), fields...)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// tagProblems checks the validate tags of fields, or of every field when none are named
+func tagProblems(c *Config, keys map[string]string, fields ...string) ([]string, error) {
+	validate := validator.New()
+	validate.RegisterValidation("port", isPort)
+
+	var err error
+	if len(fields) > 0 {
+		err = validate.StructPartial(c, fields...)
+	} else {
+		err = validate.Struct(c)
+	}
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+
+	problems := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		problems = append(problems, keys[fieldErr.StructField()]+": "+describe(fieldErr, keys))
+	}
+	return problems, nil
+}
+
+// settingKeys maps Config field names to their environment variables
+func settingKeys() map[string]string {
+	keys := make(map[string]string)
+	for _, s := range settings() {
+		keys[s.Field] = s.Key
+	}
+	return keys
+}
+
+func isPort(fl validator.FieldLevel) bool {
+	port, err := strconv.Atoi(fl.Field().String())
+	return err == nil && port >= 1 && port <= 65535
+}
+
+// describe turns a failed validate tag into a short sentence
+func describe(fieldErr validator.FieldError, keys map[string]string) string {
+	param := fieldErr.Param()
+	if fieldErr.Type() == reflect.TypeOf(time.Duration(0)) && param == "0" {
+		param = "0s"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "url":
+		// The value is not echoed, URLs may carry credentials
+		return "is not a valid URL"
+	case "oneof":
+		return fmt.Sprintf("%q must be one of %s", fieldErr.Value(), strings.ReplaceAll(param, " ", ", "))
+	case "port":
+		return fmt.Sprintf("%q must be a port between 1 and 65535", fieldErr.Value())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "gte":
+		return fmt.Sprintf("must be at least %s", param)
+	case "gtfield":
+		return fmt.Sprintf("must be greater than %s", keys[fieldErr.Param()])
+	case "gtefield":
+		return fmt.Sprintf("must not be less than %s", keys[fieldErr.Param()])
+	case "required_with":
+		return fmt.Sprintf("is required with %s", keys[fieldErr.Param()])
+	}
+	return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Watcher reloads the configuration on SIGHUP or when a config file changes. Only fields tagged
+// reload:"true" take effect; changes to other settings are logged and wait for a restart.
+// Readers get an immutable snapshot from Current, replaced atomically on every reload.
+type Watcher struct {
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	modTimes    map[string]time.Time
+	logger      *zap.SugaredLogger
+}
+
+func NewWatcher(cfg *Config, logger *zap.SugaredLogger) *Watcher {
+	watcher := &Watcher{
+		logger:   logger,
+		modTimes: make(map[string]time.Time),
+	}
+	watcher.current.Store(cfg)
+	configPath, configFile := sourceFiles()
+	for _, path := range []string{configPath, configFile} {
+		if path != "" {
+			watcher.modTimes[path] = modTime(path)
+		}
+	}
+	return watcher
+}
+
+// Current returns the latest snapshot; callers must not modify it
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// OnReload registers fn to be called with the new snapshot after every successful reload
+func (w *Watcher) OnReload(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reads the configuration sources again and publishes a snapshot with the reloadable fields updated
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	restoreEnv()
+	fresh, err := load()
+	if err != nil {
+		return err
+	}
+
+	previous := w.Current()
+	next := *previous
+	current := reflect.ValueOf(&next).Elem()
+	loaded := reflect.ValueOf(fresh).Elem()
+	var reloaded []string
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		if reflect.DeepEqual(current.Field(i).Interface(), loaded.Field(i).Interface()) {
+			continue
+		}
+		if field.Tag.Get("reload") != "true" {
+			w.logger.Warnf("Config %s changed, restart to apply it", field.Name)
+			continue
+		}
+		current.Field(i).Set(loaded.Field(i))
+		reloaded = append(reloaded, field.Name)
+	}
+	if len(reloaded) > 0 {
+		// Only the reloaded fields are checked, the others may still hold unresolved secret references
+		err = next.validateFields(reloaded...)
+		if err != nil {
+			return err
+		}
+		w.logger.Infof("Config reloaded: %v", reloaded)
+	}
+
+	w.current.Store(&next)
+	for _, fn := range w.subscribers {
+		fn(&next)
+	}
+	return nil
+}
+
+// WithReloaded returns a copy of base with the reloadable fields taken from reloaded, e.g. to combine the
+// resolved startup configuration with a snapshot published by the Watcher
+func WithReloaded(base, reloaded *Config) *Config {
+	merged := *base
+	target := reflect.ValueOf(&merged).Elem()
+	source := reflect.ValueOf(reloaded).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		if target.Type().Field(i).Tag.Get("reload") == "true" {
+			target.Field(i).Set(source.Field(i))
+		}
+	}
+	return &merged
+}
+
+// Run reloads on SIGHUP, and on config file changes polled every interval (0 disables polling), until ctx is done
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var poll <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			w.reload("SIGHUP")
+		case <-poll:
+			if w.filesChanged() {
+				w.reload("config file change")
+			}
+		}
+	}
+}
+
+func (w *Watcher) reload(reason string) {
+	w.logger.Infof("Reloading configuration after %s", reason)
+	err := w.Reload()
+	if err != nil {
+		w.logger.Errorf("Config reload failed, keeping the current settings: %v", err)
+	}
+}
+
+func (w *Watcher) filesChanged() bool {
+	changed := false
+	for path, seen := range w.modTimes {
+		current := modTime(path)
+		if !current.Equal(seen) {
+			w.modTimes[path] = current
+			changed = true
+		}
+	}
+	return changed
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// baseEnv holds the process environment for every setting as it was before NewConfig loaded the files,
+// so a reload can tell values set by the operator from values the previous load exported
+var baseEnv map[string]string
+
+func captureEnv() {
+	baseEnv = make(map[string]string)
+	for _, s := range settings() {
+		if value, ok := os.LookupEnv(s.Key); ok {
+			baseEnv[s.Key] = value
+		}
+	}
+}
+
+func restoreEnv() {
+	for _, s := range settings() {
+		if value, ok := baseEnv[s.Key]; ok {
+			os.Setenv(s.Key, value)
+			continue
+		}
+		os.Unsetenv(s.Key)
+	}
+}
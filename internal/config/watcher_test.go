@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWatcher_ReloadAppliesOnlyReloadableSettings(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  port: "7000"
+  vote_cooldown: 1h
+database:
+  redis_url: redis://localhost:6379
+auth:
+  jwt_key: secret
+logging:
+  level: info
+`)
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("CONFIG_FILE", path)
+	for _, name := range []string{"APP_PORT", "VOTE_COOLDOWN", "REDIS_URL", "JWT_KEY", "LOG_LEVEL"} {
+		unsetEnv(t, name)
+	}
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+	watcher := NewWatcher(cfg, zaptest.NewLogger(t).Sugar())
+
+	var notified *Config
+	watcher.OnReload(func(reloaded *Config) { notified = reloaded })
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  port: "7001"
+  vote_cooldown: 5m
+database:
+  redis_url: redis://localhost:6379
+auth:
+  jwt_key: secret
+logging:
+  level: warn
+`), 0o600))
+	require.NoError(t, watcher.Reload())
+
+	current := watcher.Current()
+	assert.Equal(t, "warn", current.LogLevel)
+	assert.Equal(t, 5*time.Minute, current.VoteCooldown)
+	assert.Equal(t, "7000", current.AppPort, "APP_PORT needs a restart")
+	assert.Same(t, current, notified)
+	assert.Equal(t, "info", cfg.LogLevel, "earlier snapshots are not modified")
+}
+
+func TestWatcher_FailedReloadKeepsSnapshot(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "database:\n  redis_url: redis://localhost:6379\nserver:\n  port: \"7000\"\nauth:\n  jwt_key: secret\n")
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("CONFIG_FILE", path)
+	for _, name := range []string{"APP_PORT", "REDIS_URL", "JWT_KEY"} {
+		unsetEnv(t, name)
+	}
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+	watcher := NewWatcher(cfg, zaptest.NewLogger(t).Sugar())
+
+	require.NoError(t, os.WriteFile(path, []byte("logging:\n  levle: warn\n"), 0o600))
+	assert.Error(t, watcher.Reload())
+	assert.Same(t, cfg, watcher.Current())
+}
+
+func TestWatcher_ReloadRejectsInvalidSettings(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "database:\n  redis_url: redis://localhost:6379\nserver:\n  port: \"7000\"\nauth:\n  jwt_key: secret\n")
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("CONFIG_FILE", path)
+	for _, name := range []string{"APP_PORT", "REDIS_URL", "JWT_KEY", "LOG_LEVEL"} {
+		unsetEnv(t, name)
+	}
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+	watcher := NewWatcher(cfg, zaptest.NewLogger(t).Sugar())
+
+	require.NoError(t, os.WriteFile(path, []byte("database:\n  redis_url: redis://localhost:6379\nserver:\n  port: \"7000\"\nauth:\n  jwt_key: secret\nlogging:\n  level: loud\n"), 0o600))
+	err = watcher.Reload()
+	require.IsType(t, &ValidationError{}, err)
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+	assert.Same(t, cfg, watcher.Current())
+}
+
+func TestWithReloaded(t *testing.T) {
+	base := &Config{AppPort: "7000", JwtKey: "resolved", LogLevel: "info"}
+	reloaded := &Config{AppPort: "7001", JwtKey: "aws-sm:jwt", LogLevel: "warn"}
+
+	merged := WithReloaded(base, reloaded)
+	assert.Equal(t, "warn", merged.LogLevel)
+	assert.Equal(t, "7000", merged.AppPort)
+	assert.Equal(t, "resolved", merged.JwtKey)
+	assert.Equal(t, "info", base.LogLevel, "base is not modified")
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// credentialSource, when set, supplies the Postgres username and password for every new connection,
+// so credentials rotated by a secrets backend apply without reopening the pools
+var credentialSource func() (username, password string)
+
+// UseCredentials installs source for the connections opened by SetupDatabase and SetupPgxPool afterwards
+func UseCredentials(source func() (username, password string)) {
+	credentialSource = source
+}
+
+func applyCredentials(_ context.Context, connConfig *pgx.ConnConfig) error {
+	connConfig.User, connConfig.Password = credentialSource()
+	return nil
+}
+
+// postgresDialector opens dsn directly, or through a pgx connector that asks credentialSource before each connection
+func postgresDialector(dsn string) (gorm.Dialector, error) {
+	if credentialSource == nil {
+		return postgres.Open(dsn), nil
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(applyCredentials))
+	return postgres.New(postgres.Config{Conn: sqlDB}), nil
+}
@@ -3,26 +3,176 @@ package database
 import (
 	"fmt"
 	"net/url"
-	"os"
 	"strings"
 
+	"github.com/glebarez/sqlite"
 	"github.com/pkg/errors"
 	"gitlab.com/jkozhemiaka/web-layout/internal/config"
-	"gorm.io/driver/postgres"
+	"gitlab.com/jkozhemiaka/web-layout/internal/metrics"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
-func SetupDatabase(cfg *config.Config) (*gorm.DB, error) {
-	postgresURI := os.Getenv("POSTGRES_URI")
-	if postgresURI == "" {
+func SetupDatabase(cfg *config.Config, logger *zap.SugaredLogger) (*gorm.DB, error) {
+	// The pii serializer reads these keys for every encrypted column
+	keyring, err := pii.NewKeyringFromConfig(cfg.PIIEncryptionKey, cfg.PIIIndexKey)
+	if err != nil {
+		return nil, err
+	}
+	pii.Use(keyring)
+
+	var db *gorm.DB
+	switch cfg.DBDriver {
+	case config.DriverPostgres:
+		db, err = setupPostgres(cfg, logger)
+	case config.DriverSQLite:
+		db, err = setupSQLite(cfg, logger)
+	default:
+		return nil, errors.Errorf("unsupported DB_DRIVER %q", cfg.DBDriver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Scopes queries to the tenant in the context; without one (tenancy off) everything is in the default tenant
+	err = db.Use(tenancy.Plugin{})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MetricsEnabled {
+		err = db.Use(metrics.GormPlugin{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+func setupPostgres(cfg *config.Config, logger *zap.SugaredLogger) (*gorm.DB, error) {
+	if cfg.PostgresURI == "" {
 		return nil, errors.New("can't finde POSTGRES_URI")
 	}
 
+	dsn, err := buildDSN(cfg.PostgresURI)
+	if err != nil {
+		return nil, err
+	}
+
+	dialector, err := postgresDialector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Postgres often comes up after the API in containers; gorm.Open pings, so retry it
+	var db *gorm.DB
+	backoff := Backoff{
+		Initial:  cfg.DBConnectInitialBackoff,
+		Max:      cfg.DBConnectMaxBackoff,
+		Deadline: cfg.DBConnectTimeout,
+	}
+	err = retry(backoff, logger, func() error {
+		var openErr error
+		db, openErr = gorm.Open(dialector, &gorm.Config{
+			Logger: newGormLogger(cfg, logger),
+		})
+		return openErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = configurePool(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = registerReplicas(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// setupSQLite opens an in-memory or file database for development and tests.
+// Replicas are not supported and the schema comes from AutoMigrate rather than the SQL migrations.
+func setupSQLite(cfg *config.Config, logger *zap.SugaredLogger) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(cfg.SqliteDSN), &gorm.Config{
+		Logger: newGormLogger(cfg, logger),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = configurePool(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// configurePool bounds the primary's connection pool; database/sql leaves open connections unlimited by default
+func configurePool(db *gorm.DB, cfg *config.Config) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+	return nil
+}
+
+// registerReplicas routes read queries to the replicas while writes and transactions stay on the primary
+func registerReplicas(db *gorm.DB, cfg *config.Config) error {
+	if len(cfg.PostgresReplicaURIs) == 0 {
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(cfg.PostgresReplicaURIs))
+	for _, uri := range cfg.PostgresReplicaURIs {
+		dsn, err := buildDSN(uri)
+		if err != nil {
+			return errors.Wrap(err, "invalid replica URI")
+		}
+		dialector, err := postgresDialector(dsn)
+		if err != nil {
+			return errors.Wrap(err, "invalid replica URI")
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})
+	err := db.Use(resolver)
+	if err != nil {
+		return err
+	}
+
+	// Each replica gets a pool of the same size as the primary
+	resolver.
+		SetMaxOpenConns(cfg.DBMaxOpenConns).
+		SetMaxIdleConns(cfg.DBMaxIdleConns).
+		SetConnMaxLifetime(cfg.DBConnMaxLifetime).
+		SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+	return nil
+}
+
+// buildDSN converts a postgres:// URI into a key/value DSN
+func buildDSN(postgresURI string) (string, error) {
 	// Parse the PostgreSQL URI
 	parsedURL, err := url.Parse(postgresURI)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	// Extract components from URI
@@ -36,12 +186,8 @@ func SetupDatabase(cfg *config.Config) (*gorm.DB, error) {
 		port = hostPort[1]
 	}
 
-	dsn := fmt.Sprintf(
+	return fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
-		host, user, password, parsedURL.Path[1:], port,
-	)
-
-	return gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
+		host, user, password, strings.TrimPrefix(parsedURL.Path, "/"), port,
+	), nil
 }
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+var dbLogLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// slowQueryThreshold is how long a statement runs before it is logged at warn
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger writes GORM's logs to the application logger, so statements share its format, outputs and sampling
+type gormLogger struct {
+	logger *zap.SugaredLogger
+	level  logger.LogLevel
+}
+
+// newGormLogger logs at the effective DB_LOG_LEVEL: every statement at info, slow ones from warn
+func newGormLogger(cfg *config.Config, log *zap.SugaredLogger) logger.Interface {
+	level, ok := dbLogLevels[cfg.EffectiveDBLogLevel()]
+	if !ok {
+		level = logger.Silent
+	}
+	return &gormLogger{logger: log.Named("gorm"), level: level}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{logger: l.logger, level: level}
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.Infow(msg, "data", data, "source", utils.FileWithLineNum())
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.Warnw(msg, "data", data, "source", utils.FileWithLineNum())
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.Errorw(msg, "data", data, "source", utils.FileWithLineNum())
+	}
+}
+
+// Trace logs a statement once it ran; a missing record is an answer, not a failure, so it is not logged
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger.Errorw("Query failed", "error", err, "elapsed", elapsed, "rows", rows, "sql", sql, "source", utils.FileWithLineNum())
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.Warnw("Slow query", "elapsed", elapsed, "threshold", slowQueryThreshold, "rows", rows, "sql", sql, "source", utils.FileWithLineNum())
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger.Infow("Query", "elapsed", elapsed, "rows", rows, "sql", sql, "source", utils.FileWithLineNum())
+	}
+}
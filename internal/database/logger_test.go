@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+func TestGormLogger_WritesToZap(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	gormLog := newGormLogger(&config.Config{DBLogLevel: "warn"}, zap.New(core).Sugar())
+	statement := func() (string, int64) { return "SELECT 1", 1 }
+	ctx := context.Background()
+
+	gormLog.Trace(ctx, time.Now(), statement, nil)
+	gormLog.Trace(ctx, time.Now(), statement, gorm.ErrRecordNotFound)
+	assert.Zero(t, logs.Len(), "fast statements and missing records are not logged at warn")
+
+	gormLog.Trace(ctx, time.Now().Add(-time.Second), statement, nil)
+	gormLog.Trace(ctx, time.Now(), statement, errors.New("connection reset"))
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, "Slow query", entries[0].Message)
+	assert.Equal(t, "gorm", entries[0].LoggerName)
+	assert.Equal(t, "SELECT 1", entries[0].ContextMap()["sql"])
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "connection reset", entries[1].ContextMap()["error"])
+}
+
+func TestGormLogger_SilentLogsNothing(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	gormLog := newGormLogger(&config.Config{DBLogLevel: "silent"}, zap.New(core).Sugar())
+
+	gormLog.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, errors.New("boom"))
+	gormLog.Error(context.Background(), "failed")
+	assert.Zero(t, logs.Len())
+}
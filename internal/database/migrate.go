@@ -0,0 +1,125 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migrator applies the versioned SQL migrations embedded in the binary
+type Migrator struct {
+	migrate *migrate.Migrate
+}
+
+func NewMigrator(cfg *config.Config) (*Migrator, error) {
+	databaseURL, err := migrationURL(cfg.PostgresURI)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{migrate: m}, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	err := m.migrate.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Down rolls back the given number of applied migrations
+func (m *Migrator) Down(steps int) error {
+	err := m.migrate.Steps(-steps)
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Status reports the current schema version and whether the last migration failed halfway
+func (m *Migrator) Status() (version uint, dirty bool, err error) {
+	version, dirty, err = m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return dbErr
+}
+
+// migrationURL points the pgx migrate driver at the configured database
+func migrationURL(postgresURI string) (string, error) {
+	parsedURL, err := url.Parse(postgresURI)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(parsedURL.Scheme, "postgres") {
+		return "", errors.New("POSTGRES_URI must use the postgres:// scheme")
+	}
+
+	parsedURL.Scheme = "pgx"
+	query := parsedURL.Query()
+	if query.Get("sslmode") == "" {
+		query.Set("sslmode", "disable")
+	}
+	parsedURL.RawQuery = query.Encode()
+
+	return parsedURL.String(), nil
+}
+
+// AutoMigrate builds the schema from the models for databases the SQL migrations do not target (SQLite)
+// and inserts the default roles and tenant the migrations would otherwise provide.
+func AutoMigrate(db *gorm.DB) error {
+	err := db.AutoMigrate(&models.Tenant{}, &models.Role{}, &models.User{}, &models.Vote{}, &models.Event{}, &models.UserHistory{}, &models.UserArchive{}, &models.FeatureFlag{}, &models.Job{}, &models.ScheduledRun{}, &models.Notification{}, &models.NotificationPreference{}, &models.WebhookDelivery{}, &models.Identity{}, &models.Invitation{}, &models.Organization{}, &models.Membership{}, &models.TermsAcceptance{}, &models.TenantQuota{}, &models.TenantUsage{}, &models.Change{}, &models.ChangeOutbox{}, &models.UserActivity{}, &models.Permission{}, &models.RolePermission{}, &models.PermissionAudit{}, &models.SecurityIncident{}, &models.AdminAudit{}, &models.OAuthClient{}, &models.OAuthCode{}, &models.OAuthConsent{}, &models.APIKey{}, &models.APIKeyUsage{}, &models.SecurityEvent{}, &models.KnownDevice{}, &models.LoginAlert{}, &models.AccountLock{}, &models.Follow{}, &models.Report{}, &models.Ban{}, &models.ShadowBan{}, &models.Phone{}, &models.PhoneCode{}, &models.RememberToken{})
+	if err != nil {
+		return err
+	}
+
+	defaultTenant := models.Tenant{ID: tenancy.DefaultTenantID, Name: "Default", Slug: "default"}
+	err = db.Where(models.Tenant{ID: tenancy.DefaultTenantID}).FirstOrCreate(&defaultTenant).Error
+	if err != nil {
+		return err
+	}
+
+	// Each role inherits from the one before it, like 000021_role_parents sets up
+	var parentID *uint
+	for _, name := range []string{models.StrUser, models.StrModerator, models.StrAdmin} {
+		role := models.Role{}
+		err = db.Where(models.Role{Name: name}).Attrs(models.Role{ParentID: parentID}).FirstOrCreate(&role).Error
+		if err != nil {
+			return err
+		}
+		parentID = &role.ID
+	}
+	return nil
+}
@@ -1,5 +1,3 @@
-\connect postgres;
-
 -- Create roles table
 CREATE TABLE IF NOT EXISTS roles (
     id SERIAL PRIMARY KEY,
@@ -45,4 +43,4 @@ VALUES (
     'Super',
     '$2a$14$4Cxw5/NK2ARnNMcE8/jnSuo6vATld5cO1yxSuWXwniqgIJIa39I7a',  -- It's best to hash passwords before inserting them in a real application
     (SELECT id FROM roles WHERE name = 'admin')
-);
+) ON CONFLICT (email) DO NOTHING;
@@ -0,0 +1,13 @@
+-- Create events table (append-only log of emitted CloudEvents, ordered by seq for replays)
+CREATE TABLE IF NOT EXISTS events (
+    seq BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(36) UNIQUE NOT NULL,
+    source VARCHAR(255) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    subject VARCHAR(255),
+    time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    data_content_type VARCHAR(100),
+    data JSONB
+);
+
+CREATE INDEX IF NOT EXISTS events_time_idx ON events (time);
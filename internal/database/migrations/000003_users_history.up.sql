@@ -0,0 +1,17 @@
+-- Create users_history table (append-only prior versions of users, written by the GORM hooks on User)
+CREATE TABLE IF NOT EXISTS users_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    operation VARCHAR(20) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    role_id INT,
+    rating INT NOT NULL DEFAULT 0,
+    vote_updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    valid_from TIMESTAMP WITH TIME ZONE,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS users_history_user_id_idx ON users_history (user_id);
@@ -0,0 +1,4 @@
+DELETE FROM users_history WHERE user_id NOT IN (SELECT id FROM users);
+ALTER TABLE users_history ADD CONSTRAINT users_history_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+DROP TABLE IF EXISTS users_archive;
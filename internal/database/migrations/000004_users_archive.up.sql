@@ -0,0 +1,19 @@
+-- Create users_archive table (users moved out of users by the archival job)
+CREATE TABLE IF NOT EXISTS users_archive (
+    id INTEGER PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    role_id INT,
+    rating INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    vote_updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS users_archive_email_idx ON users_archive (email);
+
+-- The change history is kept after the user row itself is archived or purged
+ALTER TABLE users_history DROP CONSTRAINT IF EXISTS users_history_user_id_fkey;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS users_tenant_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE users_archive DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users_history DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE votes DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,23 @@
+-- Create tenants table; every existing row moves to the default tenant
+CREATE TABLE IF NOT EXISTS tenants (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    host VARCHAR(255) UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tenants (id, name, slug) VALUES (1, 'Default', 'default') ON CONFLICT DO NOTHING;
+SELECT setval('tenants_id_seq', (SELECT MAX(id) FROM tenants));
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE users_history ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1;
+ALTER TABLE users_archive ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1;
+
+-- Emails are unique per tenant
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_email_key ON users (tenant_id, email);
+
+CREATE INDEX IF NOT EXISTS votes_tenant_id_idx ON votes (tenant_id);
+CREATE INDEX IF NOT EXISTS users_history_tenant_id_idx ON users_history (tenant_id);
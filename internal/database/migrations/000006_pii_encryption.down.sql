@@ -0,0 +1,15 @@
+-- Only valid once the data has been decrypted again; ciphertext does not fit the old column sizes
+DROP INDEX IF EXISTS users_tenant_email_key;
+ALTER TABLE users DROP COLUMN IF EXISTS email_index;
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_email_key ON users (tenant_id, email);
+CREATE INDEX IF NOT EXISTS users_archive_email_idx ON users_archive (email);
+
+ALTER TABLE users_archive ALTER COLUMN last_name TYPE VARCHAR(255);
+ALTER TABLE users_archive ALTER COLUMN first_name TYPE VARCHAR(255);
+ALTER TABLE users_archive ALTER COLUMN email TYPE VARCHAR(255);
+ALTER TABLE users_history ALTER COLUMN last_name TYPE VARCHAR(255);
+ALTER TABLE users_history ALTER COLUMN first_name TYPE VARCHAR(255);
+ALTER TABLE users_history ALTER COLUMN email TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN last_name TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN first_name TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
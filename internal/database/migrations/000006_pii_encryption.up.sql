@@ -0,0 +1,17 @@
+-- Personal data is stored encrypted (see internal/pii); ciphertext outgrows the old VARCHAR limits
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users ALTER COLUMN first_name TYPE TEXT;
+ALTER TABLE users ALTER COLUMN last_name TYPE TEXT;
+ALTER TABLE users_history ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users_history ALTER COLUMN first_name TYPE TEXT;
+ALTER TABLE users_history ALTER COLUMN last_name TYPE TEXT;
+ALTER TABLE users_archive ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users_archive ALTER COLUMN first_name TYPE TEXT;
+ALTER TABLE users_archive ALTER COLUMN last_name TYPE TEXT;
+
+-- Lookups and uniqueness move to the blind index; existing rows are filled in on startup
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_index VARCHAR(64);
+DROP INDEX IF EXISTS users_tenant_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_email_key ON users (tenant_id, email_index);
+
+DROP INDEX IF EXISTS users_archive_email_idx;
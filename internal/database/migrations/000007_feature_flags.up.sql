@@ -0,0 +1,10 @@
+-- Runtime overrides of feature flags; user_id 0 applies to every user
+CREATE TABLE IF NOT EXISTS feature_flags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    user_id INT NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS feature_flags_name_user_id_key ON feature_flags (name, user_id);
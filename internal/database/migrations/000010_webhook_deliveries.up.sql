@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL,
+    url TEXT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    succeeded BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_event_id_idx ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_created_at_idx ON webhook_deliveries (created_at);
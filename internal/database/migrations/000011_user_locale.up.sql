@@ -0,0 +1,3 @@
+-- Users choose how times in their emails are shown; empty means UTC and the server's default language
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- Create identities table: the accounts at identity providers linked to each user
+CREATE TABLE IF NOT EXISTS identities (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS identities_tenant_provider_subject_key ON identities (tenant_id, provider, subject);
+CREATE UNIQUE INDEX IF NOT EXISTS identities_user_id_provider_key ON identities (user_id, provider);
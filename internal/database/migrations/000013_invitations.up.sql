@@ -0,0 +1,17 @@
+-- Create invitations table: sign-ups by email with a role picked by an admin, accepted with the emailed token
+CREATE TABLE IF NOT EXISTS invitations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    email TEXT NOT NULL,
+    email_index VARCHAR(64) NOT NULL,
+    role_id INT NOT NULL REFERENCES roles(id),
+    invited_by INT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_token_hash ON invitations (token_hash);
+CREATE INDEX IF NOT EXISTS invitations_tenant_email_idx ON invitations (tenant_id, email_index);
@@ -0,0 +1,4 @@
+ALTER TABLE invitations DROP COLUMN IF EXISTS organization_role;
+ALTER TABLE invitations DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
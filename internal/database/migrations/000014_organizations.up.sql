@@ -0,0 +1,22 @@
+-- Create organizations and their members; a user can belong to several organizations, with a role in each
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    created_by INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    organization_id INT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memberships_user_id ON memberships (user_id);
+
+-- Invitations can make the invitee a member of an organization
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS organization_id INT REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS organization_role VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Create terms_acceptances table: the versions of the terms of service and privacy policy each user accepted
+CREATE TABLE IF NOT EXISTS terms_acceptances (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version VARCHAR(64) NOT NULL,
+    accepted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_terms_acceptances_user_version ON terms_acceptances (user_id, version);
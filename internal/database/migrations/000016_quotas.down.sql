@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_votes_tenant_created_at;
+DROP TABLE IF EXISTS tenant_usage;
+DROP TABLE IF EXISTS tenant_quotas;
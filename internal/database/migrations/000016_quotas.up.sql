@@ -0,0 +1,17 @@
+-- Create tenant_quotas, the limits that differ from the configured ones, and tenant_usage, the daily request counters
+CREATE TABLE IF NOT EXISTS tenant_quotas (
+    tenant_id INT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    quota_limit INT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_usage (
+    tenant_id INT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    day VARCHAR(10) NOT NULL,
+    requests INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_votes_tenant_created_at ON votes (tenant_id, created_at);
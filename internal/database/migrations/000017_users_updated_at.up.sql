@@ -0,0 +1,2 @@
+-- Index users for the sync API, which reads the changes of a tenant in updated_at order
+CREATE INDEX IF NOT EXISTS idx_users_tenant_updated_at ON users (tenant_id, updated_at, id);
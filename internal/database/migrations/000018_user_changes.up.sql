@@ -0,0 +1,18 @@
+-- Create user_changes, the change feed of users numbered in commit order, written by the GORM hooks on User
+CREATE TABLE IF NOT EXISTS user_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    operation VARCHAR(10) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_changes_tenant_seq ON user_changes (tenant_id, seq);
+
+-- Start the feed with the existing users, so replicating it from the beginning copies every user
+INSERT INTO user_changes (tenant_id, user_id, operation, changed_at)
+SELECT tenant_id, id, 'create', created_at FROM users ORDER BY id;
+
+INSERT INTO user_changes (tenant_id, user_id, operation, changed_at)
+SELECT tenant_id, id, 'delete', deleted_at FROM users
+WHERE deleted_at IS NOT NULL AND deleted_at > '0001-01-01 00:00:00+00' ORDER BY deleted_at, id;
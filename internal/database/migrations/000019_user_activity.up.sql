@@ -0,0 +1,14 @@
+-- Create user_activity, the last sign-in of every user and the inactivity warning sent to them
+CREATE TABLE IF NOT EXISTS user_activity (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE CASCADE,
+    last_login_at TIMESTAMPTZ NOT NULL,
+    warned_at TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00'
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_activity_tenant_id ON user_activity (tenant_id);
+
+-- Sign-ins were not recorded before, so existing users count as active from now on
+INSERT INTO user_activity (user_id, tenant_id, last_login_at)
+SELECT id, tenant_id, NOW() FROM users
+ON CONFLICT (user_id) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS permission_audit;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
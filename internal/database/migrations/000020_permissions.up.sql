@@ -0,0 +1,28 @@
+-- Create permissions, their grants to roles and the audit of changes to both
+CREATE TABLE IF NOT EXISTS permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions (permission_id);
+
+-- Audit entries outlive the permissions and roles they name, so they have no foreign keys
+CREATE TABLE IF NOT EXISTS permission_audit (
+    id SERIAL PRIMARY KEY,
+    actor_id INT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    permission_id INT NOT NULL,
+    permission_name VARCHAR(100) NOT NULL,
+    role_id INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
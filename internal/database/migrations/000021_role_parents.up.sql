@@ -0,0 +1,5 @@
+-- Roles inherit the permissions of their parent: admin from moderator, moderator from user
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES roles(id) ON DELETE SET NULL;
+
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'user') WHERE name = 'moderator' AND parent_id IS NULL;
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'moderator') WHERE name = 'admin' AND parent_id IS NULL;
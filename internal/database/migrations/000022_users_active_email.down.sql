@@ -0,0 +1,3 @@
+-- Fails while a deleted user and an active one share an email
+DROP INDEX IF EXISTS users_tenant_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_email_key ON users (tenant_id, email_index);
@@ -0,0 +1,5 @@
+-- Emails are unique among the users that are not deleted, so the email of a deleted user can sign up again.
+-- The predicate matches the zero time GORM writes for users that were never deleted.
+DROP INDEX IF EXISTS users_tenant_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_email_key ON users (tenant_id, email_index)
+    WHERE deleted_at IS NULL OR deleted_at = '0001-01-01 00:00:00+00:00';
@@ -0,0 +1,2 @@
+-- Emails stay lowercased; they are re-indexed as stored on startup
+UPDATE users SET email_index = NULL;
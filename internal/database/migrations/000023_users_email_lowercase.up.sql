@@ -0,0 +1,3 @@
+-- Emails are stored and indexed lowercased (models.NormalizeEmail). They may be encrypted, so they can not be lowered
+-- here: without an index every user is lowercased and re-indexed by database.EncryptPII on startup.
+UPDATE users SET email_index = NULL;
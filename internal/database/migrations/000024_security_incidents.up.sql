@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS security_incidents (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    signal VARCHAR(20) NOT NULL,
+    subject TEXT NOT NULL,
+    failures INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS security_incidents_tenant_id_idx ON security_incidents (tenant_id);
+CREATE INDEX IF NOT EXISTS security_incidents_created_at_idx ON security_incidents (created_at);
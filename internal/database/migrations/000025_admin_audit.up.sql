@@ -0,0 +1,16 @@
+-- No foreign keys: entries outlive the users they name, archived and purged ones included
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    actor_id INT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_id INT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_tenant_id_idx ON admin_audit (tenant_id);
+CREATE INDEX IF NOT EXISTS admin_audit_actor_id_idx ON admin_audit (actor_id);
+CREATE INDEX IF NOT EXISTS admin_audit_target_id_idx ON admin_audit (target_id);
+CREATE INDEX IF NOT EXISTS admin_audit_created_at_idx ON admin_audit (created_at);
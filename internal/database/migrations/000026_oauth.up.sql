@@ -0,0 +1,47 @@
+-- Third-party clients of the OAuth2 authorization server, the codes users approve for them and their consents
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    client_id VARCHAR(64) NOT NULL,
+    secret_hash TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    redirect_uris TEXT NOT NULL DEFAULT '[]',
+    grant_types TEXT NOT NULL DEFAULT '[]',
+    scopes TEXT NOT NULL DEFAULT '[]',
+    created_by INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_clients_client_id ON oauth_clients (client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_clients_tenant_id ON oauth_clients (tenant_id);
+
+CREATE TABLE IF NOT EXISTS oauth_codes (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    code_hash VARCHAR(64) NOT NULL,
+    client_id INT NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL DEFAULT '',
+    code_challenge VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_codes_code_hash ON oauth_codes (code_hash);
+CREATE INDEX IF NOT EXISTS idx_oauth_codes_tenant_id ON oauth_codes (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_codes_client_id ON oauth_codes (client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_codes_expires_at ON oauth_codes (expires_at);
+
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id INT NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, client_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_consents_tenant_id ON oauth_consents (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_consents_client_id ON oauth_consents (client_id);
@@ -0,0 +1,26 @@
+-- API keys of programs calling the API as a user, and their daily request counters
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    name TEXT NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tier VARCHAR(50) NOT NULL,
+    created_by INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id INT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day VARCHAR(10) NOT NULL,
+    requests INT NOT NULL DEFAULT 0,
+    limited INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_usage_day ON api_key_usage (day);
@@ -0,0 +1,15 @@
+-- No foreign key on user_id: events are kept for incident response after the user is gone
+CREATE TABLE IF NOT EXISTS security_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS security_events_tenant_id_idx ON security_events (tenant_id);
+CREATE INDEX IF NOT EXISTS security_events_user_id_idx ON security_events (user_id);
+CREATE INDEX IF NOT EXISTS security_events_created_at_idx ON security_events (created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_locks;
+DROP TABLE IF EXISTS login_alerts;
+DROP TABLE IF EXISTS known_devices;
@@ -0,0 +1,36 @@
+-- Devices and countries users signed in from, the "this wasn't me" links mailed for new ones, and the accounts
+-- locked through them
+CREATE TABLE IF NOT EXISTS known_devices (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint TEXT NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS known_devices_tenant_id_idx ON known_devices (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS known_devices_user_device_key ON known_devices (user_id, fingerprint, country);
+
+CREATE TABLE IF NOT EXISTS login_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS login_alerts_tenant_id_idx ON login_alerts (tenant_id);
+CREATE INDEX IF NOT EXISTS login_alerts_user_id_idx ON login_alerts (user_id);
+CREATE INDEX IF NOT EXISTS login_alerts_expires_at_idx ON login_alerts (expires_at);
+
+CREATE TABLE IF NOT EXISTS account_locks (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    reason VARCHAR(50) NOT NULL,
+    locked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS account_locks_tenant_id_idx ON account_locks (tenant_id);
@@ -0,0 +1,12 @@
+-- Users following the profiles of other users
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followed_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followed_id),
+    CHECK (follower_id <> followed_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followed_id_idx ON follows (followed_id);
+CREATE INDEX IF NOT EXISTS follows_tenant_id_idx ON follows (tenant_id);
@@ -0,0 +1,23 @@
+-- Users reported to the moderators. The reporter is only kept to refuse a second pending report on the same user
+-- and is never shown.
+CREATE TABLE IF NOT EXISTS reports (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    reporter_id INT REFERENCES users(id) ON DELETE SET NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    action VARCHAR(20) NOT NULL DEFAULT '',
+    resolved_by INT,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (reporter_id IS NULL OR reporter_id <> user_id)
+);
+
+CREATE INDEX IF NOT EXISTS reports_tenant_id_idx ON reports (tenant_id);
+CREATE INDEX IF NOT EXISTS reports_reporter_id_idx ON reports (reporter_id);
+CREATE INDEX IF NOT EXISTS reports_user_id_idx ON reports (user_id);
+CREATE INDEX IF NOT EXISTS reports_status_idx ON reports (status);
+-- One pending report per reporter and user
+CREATE UNIQUE INDEX IF NOT EXISTS reports_pending_key ON reports (reporter_id, user_id) WHERE status = 'pending';
@@ -0,0 +1,12 @@
+-- Users kept from signing in until a ban expires
+CREATE TABLE IF NOT EXISTS bans (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    banned_by INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS bans_tenant_id_idx ON bans (tenant_id);
+CREATE INDEX IF NOT EXISTS bans_expires_at_idx ON bans (expires_at);
@@ -0,0 +1,9 @@
+-- Users hidden from everyone else without being told
+CREATE TABLE IF NOT EXISTS shadow_bans (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    created_by INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS shadow_bans_tenant_id_idx ON shadow_bans (tenant_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS phone_codes;
+DROP TABLE IF EXISTS phones;
@@ -0,0 +1,31 @@
+-- Phone numbers users verify with a texted code, optionally as a second factor for password logins
+CREATE TABLE IF NOT EXISTS phones (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    number TEXT NOT NULL,
+    number_index TEXT NOT NULL,
+    verified_at TIMESTAMPTZ,
+    second_factor BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A number is verified by one user per tenant at most
+CREATE UNIQUE INDEX IF NOT EXISTS phones_tenant_number_key ON phones (tenant_id, number_index) WHERE verified_at IS NOT NULL;
+
+-- One-time codes texted to the phones, one per user and purpose
+CREATE TABLE IF NOT EXISTS phone_codes (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    challenge_hash TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT phone_codes_user_purpose_key UNIQUE (user_id, purpose)
+);
+
+CREATE INDEX IF NOT EXISTS phone_codes_tenant_id_idx ON phone_codes (tenant_id);
+CREATE INDEX IF NOT EXISTS phone_codes_challenge_hash_idx ON phone_codes (challenge_hash);
+CREATE INDEX IF NOT EXISTS phone_codes_expires_at_idx ON phone_codes (expires_at);
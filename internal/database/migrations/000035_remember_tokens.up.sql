@@ -0,0 +1,17 @@
+-- Remember-me token series; a family is the tokens handed out one after another to one device
+CREATE TABLE IF NOT EXISTS remember_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    fingerprint TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS remember_tokens_tenant_id_idx ON remember_tokens (tenant_id);
+CREATE INDEX IF NOT EXISTS remember_tokens_user_id_idx ON remember_tokens (user_id);
+CREATE INDEX IF NOT EXISTS remember_tokens_family_id_idx ON remember_tokens (family_id);
+CREATE INDEX IF NOT EXISTS remember_tokens_expires_at_idx ON remember_tokens (expires_at);
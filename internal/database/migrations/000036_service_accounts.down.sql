@@ -0,0 +1,3 @@
+ALTER TABLE admin_audit DROP COLUMN IF EXISTS actor_type;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
+ALTER TABLE users DROP COLUMN IF EXISTS service_account;
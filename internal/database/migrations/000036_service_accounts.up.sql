@@ -0,0 +1,6 @@
+-- Service accounts are users programs call the API as with API keys, scoped to the permissions their keys list
+ALTER TABLE users ADD COLUMN IF NOT EXISTS service_account BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT NOT NULL DEFAULT '';
+
+-- The audit tells the actions of service accounts from those of people
+ALTER TABLE admin_audit ADD COLUMN IF NOT EXISTS actor_type VARCHAR(32) NOT NULL DEFAULT 'user';
@@ -0,0 +1,4 @@
+-- Jobs run in, and are listed to, the tenant that queued them
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+
+CREATE INDEX IF NOT EXISTS jobs_tenant_id_idx ON jobs (tenant_id);
@@ -0,0 +1,4 @@
+-- Deliveries are listed to the tenant whose event they carried
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_tenant_id_idx ON webhook_deliveries (tenant_id);
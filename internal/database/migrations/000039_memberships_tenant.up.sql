@@ -0,0 +1,6 @@
+-- Memberships are scoped to the tenant of their organization
+ALTER TABLE memberships ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+
+UPDATE memberships SET tenant_id = organizations.tenant_id FROM organizations WHERE organizations.id = memberships.organization_id;
+
+CREATE INDEX IF NOT EXISTS memberships_tenant_id_idx ON memberships (tenant_id);
@@ -0,0 +1,9 @@
+-- Create user_change_outbox, where writers of users append to the change feed without waiting for each other;
+-- the relay moves the committed entries into user_changes, one relay at a time
+CREATE TABLE IF NOT EXISTS user_change_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    operation VARCHAR(10) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
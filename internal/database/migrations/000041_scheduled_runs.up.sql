@@ -0,0 +1,5 @@
+-- Create scheduled_runs, when the scheduler last started each periodic task, so a new leader keeps the cadence
+CREATE TABLE IF NOT EXISTS scheduled_runs (
+    task VARCHAR(100) PRIMARY KEY,
+    last_run_at TIMESTAMPTZ NOT NULL
+);
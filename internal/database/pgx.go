@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+)
+
+// SetupPgxPool opens the native pgx pool used when USER_REPO_DRIVER=pgx. It shares the pool limits of
+// the GORM connection and always targets the primary.
+func SetupPgxPool(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	if cfg.DBDriver != config.DriverPostgres {
+		return nil, errors.Errorf("USER_REPO_DRIVER=%s requires DB_DRIVER=%s", config.RepoDriverPgx, config.DriverPostgres)
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.PostgresURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid POSTGRES_URI")
+	}
+
+	poolConfig.MaxConns = int32(cfg.DBMaxOpenConns)
+	poolConfig.MaxConnLifetime = cfg.DBConnMaxLifetime
+	poolConfig.MaxConnIdleTime = cfg.DBConnMaxIdleTime
+	if credentialSource != nil {
+		poolConfig.BeforeConnect = applyCredentials
+	}
+
+	return pgxpool.ConnectConfig(ctx, poolConfig)
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gorm.io/gorm"
+)
+
+const piiBackfillBatchSize = 500
+
+// EncryptPII brings rows written before encryption was configured, or inserted by the SQL migrations,
+// up to date: it lowercases emails without a blind index and fills the index in, and re-writes plaintext
+// columns so the pii serializer encrypts them. It returns the number of rows rewritten and the users whose
+// email is an older user's but for case; those keep their email as stored, and so can not be found by it,
+// until it is changed.
+func EncryptPII(db *gorm.DB) (int, []uint, error) {
+	pending := "email_index IS NULL OR email_index = ''"
+	if pii.Enabled() {
+		pending += " OR email NOT LIKE '" + pii.CiphertextPrefix + "%'"
+	}
+
+	total := 0
+	var conflicts []uint
+	var lastID uint
+	for {
+		var users []models.User
+		err := db.Where("id > ? AND ("+pending+")", lastID).Order("id").Limit(piiBackfillBatchSize).Find(&users).Error
+		if err != nil {
+			return total, conflicts, err
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		for i := range users {
+			user := &users[i]
+			// Users are visited oldest first, so the oldest of those sharing an email but for case keeps it
+			email := models.NormalizeEmail(user.Email)
+			var taken int64
+			err = db.Model(&models.User{}).
+				Where("tenant_id = ? AND email_index = ? AND id <> ?", user.TenantID, pii.BlindIndex(email), user.ID).
+				Where("deleted_at IS NULL OR deleted_at = ?", time.Time{}).
+				Count(&taken).Error
+			if err != nil {
+				return total, conflicts, err
+			}
+			if taken > 0 && user.DeletedAt.IsZero() {
+				conflicts = append(conflicts, user.ID)
+			} else {
+				user.Email = email
+			}
+			user.EmailIndex = pii.BlindIndex(user.Email)
+			// UpdateColumns skips the hooks, so this is not recorded as a change in users_history
+			err = db.Model(user).Select("email", "email_index", "first_name", "last_name").UpdateColumns(user).Error
+			if err != nil {
+				return total, conflicts, err
+			}
+			lastID = user.ID
+		}
+		total += len(users)
+	}
+
+	if !pii.Enabled() {
+		return total, conflicts, nil
+	}
+
+	// History and archive rows have no index, they only need encrypting
+	plaintext := "email NOT LIKE '" + pii.CiphertextPrefix + "%'"
+	lastID = 0
+	for {
+		var history []models.UserHistory
+		err := db.Where("id > ? AND "+plaintext, lastID).Order("id").Limit(piiBackfillBatchSize).Find(&history).Error
+		if err != nil {
+			return total, conflicts, err
+		}
+		if len(history) == 0 {
+			break
+		}
+
+		for i := range history {
+			err = db.Model(&history[i]).Select("email", "first_name", "last_name").UpdateColumns(&history[i]).Error
+			if err != nil {
+				return total, conflicts, err
+			}
+			lastID = history[i].ID
+		}
+		total += len(history)
+	}
+
+	lastID = 0
+	for {
+		var archive []models.UserArchive
+		err := db.Where("id > ? AND "+plaintext, lastID).Order("id").Limit(piiBackfillBatchSize).Find(&archive).Error
+		if err != nil {
+			return total, conflicts, err
+		}
+		if len(archive) == 0 {
+			break
+		}
+
+		for i := range archive {
+			err = db.Model(&archive[i]).Select("email", "first_name", "last_name").UpdateColumns(&archive[i]).Error
+			if err != nil {
+				return total, conflicts, err
+			}
+			lastID = archive[i].ID
+		}
+		total += len(archive)
+	}
+
+	return total, conflicts, nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEncryptPII_BackfillsLegacyRows(t *testing.T) {
+	cfg := &config.Config{
+		DBDriver:         config.DriverSQLite,
+		SqliteDSN:        "file:" + t.Name() + "?mode=memory&cache=shared",
+		DBMaxOpenConns:   1,
+		DBMaxIdleConns:   1,
+		DBLogLevel:       "silent",
+		PIIEncryptionKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+		PIIIndexKey:      base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)),
+	}
+	db, err := SetupDatabase(cfg, zaptest.NewLogger(t).Sugar())
+	require.NoError(t, err)
+	t.Cleanup(func() { pii.Use(pii.NewKeyring(nil, nil)) })
+	require.NoError(t, AutoMigrate(db))
+
+	// Written the way the SQL migrations insert the admin: plaintext and no blind index
+	require.NoError(t, db.Exec(
+		"INSERT INTO users (tenant_id, email, first_name, last_name, password, role_id) VALUES (1, 'admin@example.com', 'Admin', 'Super', 'hash', 3)",
+	).Error)
+
+	rewritten, conflicts, err := EncryptPII(db)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rewritten)
+	assert.Empty(t, conflicts)
+
+	var stored struct{ Email, EmailIndex string }
+	require.NoError(t, db.Raw("SELECT email, email_index FROM users").Scan(&stored).Error)
+	assert.True(t, strings.HasPrefix(stored.Email, pii.CiphertextPrefix))
+	assert.Equal(t, pii.BlindIndex("admin@example.com"), stored.EmailIndex)
+
+	var user models.User
+	require.NoError(t, db.Where("email_index = ?", pii.BlindIndex("admin@example.com")).First(&user).Error)
+	assert.Equal(t, "Super", user.LastName)
+
+	rewritten, _, err = EncryptPII(db)
+	require.NoError(t, err)
+	assert.Zero(t, rewritten)
+}
+
+func TestEncryptPII_LowercasesEmails(t *testing.T) {
+	cfg := &config.Config{
+		DBDriver:       config.DriverSQLite,
+		SqliteDSN:      "file:" + t.Name() + "?mode=memory&cache=shared",
+		DBMaxOpenConns: 1,
+		DBMaxIdleConns: 1,
+		DBLogLevel:     "silent",
+	}
+	db, err := SetupDatabase(cfg, zaptest.NewLogger(t).Sugar())
+	require.NoError(t, err)
+	require.NoError(t, AutoMigrate(db))
+
+	// Written before emails were lowercased, and without an index as 000023_users_email_lowercase leaves them
+	require.NoError(t, db.Exec(`INSERT INTO users (tenant_id, email, password, role_id) VALUES
+		(1, 'Ann@Example.com', 'hash', 1), (1, 'ANN@example.com', 'hash', 1), (1, 'Bob@Example.com', 'hash', 1)`).Error)
+
+	rewritten, conflicts, err := EncryptPII(db)
+	require.NoError(t, err)
+	assert.Equal(t, 3, rewritten)
+	assert.Equal(t, []uint{2}, conflicts, "the older user keeps the email")
+
+	var emails []string
+	require.NoError(t, db.Raw("SELECT email FROM users ORDER BY id").Scan(&emails).Error)
+	assert.Equal(t, []string{"ann@example.com", "ANN@example.com", "bob@example.com"}, emails)
+
+	var user models.User
+	require.NoError(t, db.Where("email_index = ?", models.EmailBlindIndex("BOB@example.com")).First(&user).Error)
+	assert.Equal(t, uint(3), user.ID)
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Backoff describes exponential retry delays bounded by a total deadline
+type Backoff struct {
+	Initial  time.Duration
+	Max      time.Duration
+	Deadline time.Duration
+}
+
+// retry calls fn until it succeeds or the next attempt would start after the deadline,
+// doubling the delay between attempts up to Max and logging each failure
+func retry(backoff Backoff, logger *zap.SugaredLogger, fn func() error) error {
+	start := time.Now()
+	delay := backoff.Initial
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if time.Since(start)+delay > backoff.Deadline {
+			return errors.Wrapf(err, "giving up after %d attempts in %s", attempt, time.Since(start).Round(time.Millisecond))
+		}
+
+		logger.Warnf("Database not ready (attempt %d), retrying in %s: %v", attempt, delay, err)
+		time.Sleep(delay)
+
+		delay *= 2
+		if delay > backoff.Max {
+			delay = backoff.Max
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	attempts := 0
+	err := retry(Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond, Deadline: time.Second}, zaptest.NewLogger(t).Sugar(), func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetry_GivesUpAtDeadline(t *testing.T) {
+	attempts := 0
+	start := time.Now()
+	err := retry(Backoff{Initial: 10 * time.Millisecond, Max: 20 * time.Millisecond, Deadline: 50 * time.Millisecond}, zaptest.NewLogger(t).Sugar(), func() error {
+		attempts++
+		return errors.New("connection refused")
+	})
+
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.GreaterOrEqual(t, attempts, 2)
+}
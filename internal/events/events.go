@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// SpecVersion is the CloudEvents specification version used for every envelope
+	SpecVersion = "1.0"
+	// ContentType is the media type of a CloudEvent in structured content mode
+	ContentType = "application/cloudevents+json"
+	// DataContentType is the media type of the data carried inside the envelope
+	DataContentType = "application/json"
+)
+
+// Event types emitted by the service
+const (
+	UserCreated = "com.usermanagement.user.created"
+	UserUpdated = "com.usermanagement.user.updated"
+	UserDeleted = "com.usermanagement.user.deleted"
+	VoteCast    = "com.usermanagement.vote.cast"
+	VoteRevoked = "com.usermanagement.vote.revoked"
+	// UserFollowed and UserUnfollowed carry the follower as user_id and the followed profile as profile_id
+	UserFollowed   = "com.usermanagement.user.followed"
+	UserUnfollowed = "com.usermanagement.user.unfollowed"
+	// SecurityIncidentRaised is emitted when failed logins reach a brute-force threshold
+	SecurityIncidentRaised = "com.usermanagement.security.incident"
+)
+
+// Event is a CloudEvents 1.0 envelope in structured content mode
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	// Sequence is the CloudEvents "sequence" extension, set once the event has been persisted
+	Sequence string `json:"sequence,omitempty"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// Store persists emitted events and returns the assigned sequence number
+type Store interface {
+	SaveEvent(ctx context.Context, event *Event) (uint64, error)
+}
+
+// New builds an envelope with a fresh ID and the current time, marshaling data as JSON
+func New(source, eventType, subject string, data interface{}) (*Event, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	event := &Event{
+		SpecVersion: SpecVersion,
+		ID:          id,
+		Source:      source,
+		Type:        eventType,
+		Subject:     subject,
+		Time:        time.Now().UTC(),
+	}
+
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		event.Data = raw
+		event.DataContentType = DataContentType
+	}
+
+	return event, nil
+}
+
+// newID generates a random (version 4) UUID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
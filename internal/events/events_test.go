@@ -0,0 +1,90 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	event, err := New("urn:test", UserCreated, "42", map[string]uint{"user_id": 42})
+	assert.NoError(t, err)
+
+	assert.Equal(t, SpecVersion, event.SpecVersion)
+	assert.Equal(t, "urn:test", event.Source)
+	assert.Equal(t, UserCreated, event.Type)
+	assert.Equal(t, "42", event.Subject)
+	assert.Equal(t, DataContentType, event.DataContentType)
+	assert.Len(t, event.ID, 36)
+	assert.False(t, event.Time.IsZero())
+	assert.JSONEq(t, `{"user_id":42}`, string(event.Data))
+
+	other, err := New("urn:test", UserCreated, "42", nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, event.ID, other.ID)
+	assert.Empty(t, other.DataContentType)
+}
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	var received Event
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	event, _ := New("urn:test", VoteCast, "7", nil)
+	err := NewWebhookPublisher(srv.URL).Publish(context.Background(), event)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentType, contentType)
+	assert.Equal(t, event.ID, received.ID)
+	assert.Equal(t, VoteCast, received.Type)
+}
+
+func TestWebhookPublisher_PublishError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	event, _ := New("urn:test", VoteCast, "7", nil)
+	err := NewWebhookPublisher(srv.URL).Publish(context.Background(), event)
+
+	var webhookErr *WebhookError
+	require.ErrorAs(t, err, &webhookErr)
+	assert.Equal(t, http.StatusInternalServerError, webhookErr.StatusCode)
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(ctx context.Context, event *Event) error {
+	return errors.New("sink down")
+}
+
+func TestFanoutPublisher_PublishesToEveryoneDespiteFailures(t *testing.T) {
+	var got []string
+	recording := publisherFunc(func(ctx context.Context, event *Event) error {
+		got = append(got, event.ID)
+		return nil
+	})
+	event, err := New("urn:test", UserCreated, "1", nil)
+	assert.NoError(t, err)
+
+	err = NewFanoutPublisher(failingPublisher{}, recording).Publish(context.Background(), event)
+	assert.EqualError(t, err, "sink down")
+	assert.Equal(t, []string{event.ID}, got)
+}
+
+type publisherFunc func(ctx context.Context, event *Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
@@ -0,0 +1,188 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type LogPublisher struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogPublisher(logger *zap.SugaredLogger) *LogPublisher {
+	return &LogPublisher{
+		logger: logger,
+	}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event *Event) error {
+	p.logger.Infow("event emitted", "type", event.Type, "id", event.ID, "subject", event.Subject)
+	return nil
+}
+
+// WebhookPublisher posts every event to a single URL in structured content mode
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string) *WebhookPublisher {
+	return &WebhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// URL is where the events are posted
+func (p *WebhookPublisher) URL() string {
+	return p.url
+}
+
+// WebhookError is returned for a response outside 2xx
+type WebhookError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook %s responded with status %d", e.URL, e.StatusCode)
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event *Event) error {
+	_, err := p.Deliver(ctx, event)
+	return err
+}
+
+// Deliver posts the event and returns the status of the response, 0 when there was none
+func (p *WebhookPublisher) Deliver(ctx context.Context, event *Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, &WebhookError{URL: p.url, StatusCode: res.StatusCode}
+	}
+	return res.StatusCode, nil
+}
+
+// PersistingPublisher stores every event before handing it to the next publisher, so it can be replayed later
+type PersistingPublisher struct {
+	store Store
+	next  Publisher
+}
+
+func NewPersistingPublisher(store Store, next Publisher) *PersistingPublisher {
+	return &PersistingPublisher{
+		store: store,
+		next:  next,
+	}
+}
+
+func (p *PersistingPublisher) Publish(ctx context.Context, event *Event) error {
+	seq, err := p.store.SaveEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	event.Sequence = strconv.FormatUint(seq, 10)
+
+	return p.next.Publish(ctx, event)
+}
+
+// FanoutPublisher hands every event to several publishers; one failing does not keep the others from receiving it
+type FanoutPublisher struct {
+	publishers []Publisher
+}
+
+func NewFanoutPublisher(publishers ...Publisher) *FanoutPublisher {
+	return &FanoutPublisher{
+		publishers: publishers,
+	}
+}
+
+func (p *FanoutPublisher) Publish(ctx context.Context, event *Event) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sink kinds accepted by NewSink
+const (
+	SinkWebhook = "webhook"
+	SinkLog     = "log"
+)
+
+// NewSink builds a publisher for an explicitly chosen destination, e.g. the target of a replay
+func NewSink(kind, url string, logger *zap.SugaredLogger) (Publisher, error) {
+	switch kind {
+	case SinkWebhook:
+		if url == "" {
+			return nil, fmt.Errorf("%s sink requires a url", kind)
+		}
+		return NewWebhookPublisher(url), nil
+	case SinkLog:
+		return NewLogPublisher(logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported sink type %q", kind)
+	}
+}
+
+// NewPublisher returns a webhook publisher when a URL is configured and falls back to logging otherwise
+func NewPublisher(webhookURL string, logger *zap.SugaredLogger) Publisher {
+	if webhookURL == "" {
+		return NewLogPublisher(logger)
+	}
+	return NewWebhookPublisher(webhookURL)
+}
+
+// Emitter stamps events with the service source and publishes them without failing the caller
+type Emitter struct {
+	source    string
+	publisher Publisher
+	logger    *zap.SugaredLogger
+}
+
+func NewEmitter(source string, publisher Publisher, logger *zap.SugaredLogger) *Emitter {
+	return &Emitter{
+		source:    source,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+func (e *Emitter) Emit(ctx context.Context, eventType, subject string, data interface{}) {
+	event, err := New(e.source, eventType, subject, data)
+	if err != nil {
+		e.logger.Errorw("failed to build event", "type", eventType, "error", err)
+		return
+	}
+
+	err = e.publisher.Publish(ctx, event)
+	if err != nil {
+		e.logger.Errorw("failed to publish event", "type", eventType, "id", event.ID, "error", err)
+	}
+}
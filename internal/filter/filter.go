@@ -0,0 +1,95 @@
+// Package filter reads structured list filters from the query string, e.g.
+// ?filter[role]=admin&filter[created_at][gte]=2024-01-01. It only parses the syntax: which fields and operators
+// a list accepts, and what they mean, is up to the repository that applies them.
+package filter
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+)
+
+type Operator string
+
+const (
+	Eq       Operator = "eq"
+	Ne       Operator = "ne"
+	Gt       Operator = "gt"
+	Gte      Operator = "gte"
+	Lt       Operator = "lt"
+	Lte      Operator = "lte"
+	Contains Operator = "contains"
+	// In takes a comma separated list
+	In Operator = "in"
+)
+
+var operators = map[Operator]bool{Eq: true, Ne: true, Gt: true, Gte: true, Lt: true, Lte: true, Contains: true, In: true}
+
+// Condition is one filter[field][operator]=value parameter; filter[field]=value is Eq
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    string
+}
+
+// Values returns the items of an In condition
+func (c Condition) Values() []string {
+	values := strings.Split(c.Value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// String is the parameter the condition was read from, for error messages
+func (c Condition) String() string {
+	return "filter[" + c.Field + "][" + string(c.Operator) + "]"
+}
+
+var parameter = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse returns the conditions of every filter parameter in query, sorted by field and operator so equal filters
+// give equal conditions; any malformed filter parameter is a BadRequestErr
+func Parse(query url.Values) ([]Condition, error) {
+	var conditions []Condition
+	for key, values := range query {
+		if !strings.HasPrefix(key, "filter") {
+			continue
+		}
+		match := parameter.FindStringSubmatch(key)
+		if match == nil {
+			return nil, apperrors.BadRequestErr.AppendMessage("malformed filter " + key + ", expected filter[field] or filter[field][operator]")
+		}
+		operator := Eq
+		if match[2] != "" {
+			operator = Operator(match[2])
+		}
+		if !operators[operator] {
+			return nil, apperrors.BadRequestErr.AppendMessage("unknown filter operator " + string(operator))
+		}
+		if len(values) > 1 {
+			return nil, apperrors.BadRequestErr.AppendMessage(key + " is given more than once")
+		}
+		conditions = append(conditions, Condition{Field: match[1], Operator: operator, Value: values[0]})
+	}
+
+	sort.Slice(conditions, func(i, j int) bool {
+		if conditions[i].Field != conditions[j].Field {
+			return conditions[i].Field < conditions[j].Field
+		}
+		return conditions[i].Operator < conditions[j].Operator
+	})
+	return conditions, nil
+}
+
+// Encode is the canonical query string of conditions, e.g. for cache keys
+func Encode(conditions []Condition) string {
+	query := url.Values{}
+	for _, condition := range conditions {
+		query.Set(condition.String(), condition.Value)
+	}
+	return query.Encode()
+}
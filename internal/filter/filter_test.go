@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+)
+
+func TestParse(t *testing.T) {
+	query, err := url.ParseQuery("filter[role]=admin&filter[created_at][gte]=2024-01-01&filter[email][contains]=@corp.com&page=2")
+	require.NoError(t, err)
+
+	conditions, err := Parse(query)
+	require.NoError(t, err)
+	assert.Equal(t, []Condition{
+		{Field: "created_at", Operator: Gte, Value: "2024-01-01"},
+		{Field: "email", Operator: Contains, Value: "@corp.com"},
+		{Field: "role", Operator: Eq, Value: "admin"},
+	}, conditions)
+	assert.Equal(t, "filter%5Bcreated_at%5D%5Bgte%5D=2024-01-01&filter%5Bemail%5D%5Bcontains%5D=%40corp.com&filter%5Brole%5D%5Beq%5D=admin", Encode(conditions))
+}
+
+func TestParse_RejectsMalformedFilters(t *testing.T) {
+	for _, raw := range []string{
+		"filter=admin",
+		"filter[role]]=admin",
+		"filter[Role]=admin",
+		"filter[role][like]=adm",
+		"filter[role]=admin&filter[role]=user",
+	} {
+		query, err := url.ParseQuery(raw)
+		require.NoError(t, err)
+		_, err = Parse(query)
+		assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), raw)
+	}
+}
+
+func TestCondition_Values(t *testing.T) {
+	assert.Equal(t, []string{"admin", "moderator"}, Condition{Operator: In, Value: "admin, moderator"}.Values())
+}
@@ -0,0 +1,125 @@
+// Package geoip finds the country of client addresses in a table of address ranges loaded from CSV
+package geoip
+
+import (
+	"encoding/csv"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ipRange is the addresses from start to end, both included, of one country
+type ipRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// Database maps addresses to ISO 3166 country codes
+type Database struct {
+	// ranges are sorted by start and do not overlap
+	ranges []ipRange
+}
+
+// Open loads the database from the CSV file at path, see Load
+func Open(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "geoip")
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// Load reads a CSV of ranges, each "first address,last address,country" like the free country database of db-ip.com,
+// or "CIDR,country". IPv4 and IPv6 ranges may be mixed.
+func Load(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &Database{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "geoip")
+		}
+		entry, err := parseRange(record)
+		if err != nil {
+			return nil, errors.Wrapf(err, "geoip: line %d", line)
+		}
+		db.ranges = append(db.ranges, entry)
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	for i := 1; i < len(db.ranges); i++ {
+		if !db.ranges[i-1].end.Less(db.ranges[i].start) {
+			return nil, errors.Errorf("geoip: %s overlaps %s", db.ranges[i].start, db.ranges[i-1].start)
+		}
+	}
+	return db, nil
+}
+
+func parseRange(record []string) (ipRange, error) {
+	switch len(record) {
+	case 2:
+		prefix, err := netip.ParsePrefix(record[0])
+		if err != nil {
+			return ipRange{}, err
+		}
+		prefix = prefix.Masked()
+		return ipRange{start: prefix.Addr(), end: lastAddr(prefix), country: strings.ToUpper(record[1])}, nil
+	case 3:
+		start, err := netip.ParseAddr(record[0])
+		if err != nil {
+			return ipRange{}, err
+		}
+		end, err := netip.ParseAddr(record[1])
+		if err != nil {
+			return ipRange{}, err
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return ipRange{}, errors.Errorf("invalid range %s-%s", start, end)
+		}
+		return ipRange{start: start, end: end, country: strings.ToUpper(record[2])}, nil
+	}
+	return ipRange{}, errors.Errorf("expected 2 or 3 fields, got %d", len(record))
+}
+
+// lastAddr is the highest address of prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// Country returns the country code of ip, or "" when the address is invalid or in no range
+func (db *Database) Country(ip string) string {
+	if db == nil {
+		return ""
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	// The last range starting at or before addr is the only one that can hold it
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].start)
+	}) - 1
+	if i < 0 || db.ranges[i].end.Less(addr) {
+		return ""
+	}
+	return db.ranges[i].country
+}
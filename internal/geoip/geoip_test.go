@@ -0,0 +1,41 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_Country(t *testing.T) {
+	db, err := Load(strings.NewReader(`203.0.113.0,203.0.113.255,ua
+198.51.100.0/24,DE
+2001:db8::,2001:db8::ffff,PL
+192.0.2.0,192.0.2.127,US
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "UA", db.Country("203.0.113.7"))
+	assert.Equal(t, "DE", db.Country("198.51.100.255"))
+	assert.Equal(t, "US", db.Country("192.0.2.0"))
+	assert.Equal(t, "", db.Country("192.0.2.128"), "between ranges")
+	assert.Equal(t, "PL", db.Country("2001:db8::1"))
+	assert.Equal(t, "UA", db.Country("::ffff:203.0.113.7"), "IPv4-mapped addresses are looked up as IPv4")
+	assert.Equal(t, "", db.Country("10.0.0.1"))
+	assert.Equal(t, "", db.Country("not an address"))
+	assert.Equal(t, "", (*Database)(nil).Country("203.0.113.7"), "without a database nothing is located")
+}
+
+func TestLoad_RejectsInvalidRanges(t *testing.T) {
+	for _, csv := range []string{
+		"203.0.113.0,UA,extra,field\n",
+		"203.0.113.255,203.0.113.0,UA\n",
+		"203.0.113.0,2001:db8::,UA\n",
+		"203.0.113.0/33,UA\n",
+		"203.0.113.0/24,UA\n203.0.113.128/25,DE\n",
+	} {
+		_, err := Load(strings.NewReader(csv))
+		assert.Error(t, err, csv)
+	}
+}
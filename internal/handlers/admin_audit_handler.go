@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+const (
+	// auditReasonHeader carries the reason an admin gives for an action, recorded with it in the admin audit
+	auditReasonHeader = "X-Audit-Reason"
+	maxAuditReason    = 500
+	// exportAuditBatch is how many entries an export reads at a time
+	exportAuditBatch = 500
+)
+
+type adminAuditHandler struct {
+	*BaseHandler
+	audit  services.AdminAuditServiceInterface
+	logger *zap.SugaredLogger
+}
+
+func NewAdminAuditHandler(audit services.AdminAuditServiceInterface, logger *zap.SugaredLogger) *adminAuditHandler {
+	return &adminAuditHandler{
+		BaseHandler: NewBaseHandler(logger),
+		audit:       audit,
+		logger:      logger,
+	}
+}
+
+// AdminAuditResponse is a page of the admin audit; NextBeforeID is passed as ?before_id= for the next, older page
+type AdminAuditResponse struct {
+	Data         []models.AdminAudit `json:"data"`
+	NextBeforeID uint64              `json:"next_before_id,omitempty"`
+}
+
+// ListAudit returns the latest admin actions, filtered by ?actor_id=, ?actor_type=, ?target_id=, ?action=, ?since= and
+// ?until=. The route requires the audit.read permission, like the export.
+func (h *adminAuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAdminAuditQuery(r.URL.Query())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	query.Limit = defaultAuditLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		query.Limit, err = strconv.Atoi(value)
+		if err != nil || query.Limit <= 0 || query.Limit > maxAuditLimit {
+			h.sendError(w, r, errors.New("limit should be in the range from 1 to "+strconv.Itoa(maxAuditLimit)), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := r.URL.Query().Get("before_id"); value != "" {
+		query.BeforeID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.audit.ListAudit(r.Context(), query)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response := &AdminAuditResponse{Data: entries}
+	if len(entries) == query.Limit {
+		response.NextBeforeID = entries[len(entries)-1].ID
+	}
+	h.respond(w, response, http.StatusOK)
+}
+
+// ExportAudit streams every admin action matching the filters of ListAudit as CSV, newest first
+func (h *adminAuditHandler) ExportAudit(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAdminAuditQuery(r.URL.Query())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	query.Limit = exportAuditBatch
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="admin-audit.csv"`)
+	out := csv.NewWriter(w)
+	out.Write([]string{"id", "created_at", "actor_id", "actor_type", "action", "target_id", "reason", "details"})
+	err = h.audit.ExportAudit(r.Context(), query, func(entries []models.AdminAudit) error {
+		for _, entry := range entries {
+			out.Write([]string{
+				strconv.FormatUint(entry.ID, 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatUint(uint64(entry.ActorID), 10),
+				entry.ActorType,
+				entry.Action,
+				strconv.FormatUint(uint64(entry.TargetID), 10),
+				csvText(entry.Reason),
+				csvText(entry.Details),
+			})
+		}
+		out.Flush()
+		return out.Error()
+	})
+	out.Flush()
+	// The status is sent with the first row, so a failure midway can only cut the file short
+	if err != nil {
+		h.logger.Errorw("Exporting the admin audit failed", "error", err)
+	}
+}
+
+// csvText keeps free text from being read as a formula by spreadsheets
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// parseAdminAuditQuery reads the filters shared by the audit list and export
+func parseAdminAuditQuery(values url.Values) (*models.AdminAuditQuery, error) {
+	query := &models.AdminAuditQuery{Action: values.Get("action"), ActorType: values.Get("actor_type")}
+	if query.ActorType != "" && query.ActorType != models.ActorUser && query.ActorType != models.ActorServiceAccount {
+		return nil, apperrors.BadRequestErr.AppendMessage("actor_type should be " + models.ActorUser + " or " + models.ActorServiceAccount)
+	}
+	for name, target := range map[string]*uint{"actor_id": &query.ActorID, "target_id": &query.TargetID} {
+		if value := values.Get(name); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, apperrors.BadRequestErr.AppendMessage(name + " should be a user ID")
+			}
+			*target = uint(id)
+		}
+	}
+	for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := values.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, apperrors.BadRequestErr.AppendMessage(name + " should be an RFC 3339 time")
+			}
+			*target = parsed
+		}
+	}
+	return query, nil
+}
+
+// adminAction is the admin making the request and the reason given in X-Audit-Reason
+func adminAction(r *http.Request, actorID uint) (*models.AdminAction, error) {
+	reason := strings.TrimSpace(r.Header.Get(auditReasonHeader))
+	if len(reason) > maxAuditReason {
+		return nil, apperrors.BadRequestErr.AppendMessage(auditReasonHeader + " should be at most " + strconv.Itoa(maxAuditReason) + " bytes")
+	}
+	return &models.AdminAction{ActorID: actorID, Reason: reason}, nil
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestAdminAuditHandler_ListAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	audit := services.NewMockAdminAuditServiceInterface(ctrl)
+	handler := NewAdminAuditHandler(audit, zap.NewNop().Sugar())
+	changedAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	handlertest.NewRequest(t, http.MethodGet, "/admin/audit?since=yesterday").As(handlertest.Admin).
+		Serve(handler.ListAudit).
+		AssertStatus(http.StatusBadRequest).
+		AssertErrorCode("BAD_REQUEST_ERR")
+	handlertest.NewRequest(t, http.MethodGet, "/admin/audit?actor_type=robot").As(handlertest.Admin).
+		Serve(handler.ListAudit).
+		AssertStatus(http.StatusBadRequest).
+		AssertErrorCode("BAD_REQUEST_ERR")
+
+	audit.EXPECT().ListAudit(gomock.Any(), &models.AdminAuditQuery{
+		ActorID: 3, Action: models.AdminAuditRoleChanged, Since: changedAt.Add(-24 * time.Hour), BeforeID: 40, Limit: 2,
+	}).Return([]models.AdminAudit{
+		{ID: 31, ActorID: 3, ActorType: models.ActorUser, Action: models.AdminAuditRoleChanged, TargetID: 12, Reason: "promoted", Details: "role_id 1 -> 2", CreatedAt: changedAt},
+		{ID: 30, ActorID: 3, ActorType: models.ActorUser, Action: models.AdminAuditRoleChanged, TargetID: 14, Details: "role_id 2 -> 1", CreatedAt: changedAt.Add(-time.Hour)},
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/audit?actor_id=3&action=user.role_changed&since=2024-02-29T12:00:00Z&before_id=40&limit=2").As(handlertest.Admin).
+		Serve(handler.ListAudit).
+		AssertStatus(http.StatusOK).
+		AssertGolden("admin_audit_handler/list")
+}
+
+func TestAdminAuditHandler_ExportAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	audit := services.NewMockAdminAuditServiceInterface(ctrl)
+	handler := NewAdminAuditHandler(audit, zap.NewNop().Sugar())
+	deletedAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	audit.EXPECT().ExportAudit(gomock.Any(), &models.AdminAuditQuery{ActorType: models.ActorServiceAccount, TargetID: 12, Limit: exportAuditBatch}, gomock.Any()).
+		DoAndReturn(func(_ interface{}, _ *models.AdminAuditQuery, fn func([]models.AdminAudit) error) error {
+			return fn([]models.AdminAudit{
+				{ID: 5, ActorID: 9, ActorType: models.ActorServiceAccount, Action: models.AdminAuditUserDeleted, TargetID: 12, Reason: "=HYPERLINK(\"x\")", CreatedAt: deletedAt},
+			})
+		})
+	response := handlertest.NewRequest(t, http.MethodGet, "/admin/audit/export?target_id=12&actor_type=service_account").As(handlertest.Admin).
+		Serve(handler.ExportAudit).
+		AssertStatus(http.StatusOK)
+
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Equal(t, "id,created_at,actor_id,actor_type,action,target_id,reason,details\n"+
+		"5,2024-03-01T12:00:00Z,9,service_account,user.deleted,12,\"'=HYPERLINK(\"\"x\"\")\",\n", response.Body.String())
+}
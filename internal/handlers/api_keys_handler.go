@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+type apiKeysHandler struct {
+	*BaseHandler
+	apiKeys   services.APIKeyServiceInterface
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+}
+
+func NewAPIKeysHandler(apiKeys services.APIKeyServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *apiKeysHandler {
+	return &apiKeysHandler{
+		BaseHandler: NewBaseHandler(logger),
+		apiKeys:     apiKeys,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	UserID uint   `json:"user_id" validate:"required"`
+	// Tier is one of API_KEY_TIERS, or empty for API_KEY_DEFAULT_TIER
+	Tier string `json:"tier" validate:"max=50"`
+	// Scopes, space separated, are the only permissions a key of a service account has
+	Scopes string `json:"scopes" validate:"max=1000"`
+}
+
+// CreateAPIKeyResponse is the created key with its value, which is only ever shown here
+type CreateAPIKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+type SetAPIKeyTierRequest struct {
+	Tier string `json:"tier" validate:"required,max=50"`
+}
+
+// Create creates an API key for the user in the body
+func (h *apiKeysHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+
+	createRequest := &CreateAPIKeyRequest{}
+	if err := h.decode(r, createRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, createRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	key, value, err := h.apiKeys.Create(r.Context(), &models.APIKey{
+		Name:      createRequest.Name,
+		UserID:    createRequest.UserID,
+		Tier:      createRequest.Tier,
+		Scopes:    createRequest.Scopes,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, CreateAPIKeyResponse{APIKey: key, Key: value}, http.StatusCreated)
+}
+
+func (h *apiKeysHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	keys, err := h.apiKeys.List(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, keys, http.StatusOK)
+}
+
+// SetTier moves the key in the path to the tier in the body
+func (h *apiKeysHandler) SetTier(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.keyID(w, r)
+	if !ok {
+		return
+	}
+	tierRequest := &SetAPIKeyTierRequest{}
+	if err := h.decode(r, tierRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, tierRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.apiKeys.SetTier(r.Context(), id, tierRequest.Tier); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+func (h *apiKeysHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.keyID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeys.Delete(r.Context(), id); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// Usage returns the requests made with the key in the path over the last ?days= (30 by default), today included
+func (h *apiKeysHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.keyID(w, r)
+	if !ok {
+		return
+	}
+	days := defaultUsageDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days <= 0 || days > maxUsageDays {
+			h.sendError(w, r, errors.New("days should be in the range from 1 to "+strconv.Itoa(maxUsageDays)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.apiKeys.Usage(r.Context(), id, days)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, report, http.StatusOK)
+}
+
+// keyID returns the key ID in the path of a request by an admin
+func (h *apiKeysHandler) keyID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	if !h.requireAdmin(w, r) {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
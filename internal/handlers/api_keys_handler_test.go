@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestAPIKeysHandler(t *testing.T) {
+	admin, user := handlertest.Admin, handlertest.User
+	key := &models.APIKey{ID: 7, Name: "Billing", Prefix: "uk_Zm9vYmFy", UserID: user.ID, Tier: "standard", CreatedBy: admin.ID, CreatedAt: goldenTime}
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *apiKeysHandler) http.HandlerFunc
+		expect     func(apiKeys *services.MockAPIKeyServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "create",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/api-keys").JSON(map[string]interface{}{"name": "Billing", "user_id": user.ID, "tier": "standard"}).As(admin),
+			serve:   func(h *apiKeysHandler) http.HandlerFunc { return h.Create },
+			expect: func(apiKeys *services.MockAPIKeyServiceInterface) {
+				apiKeys.EXPECT().Create(gomock.Any(), &models.APIKey{Name: "Billing", UserID: user.ID, Tier: "standard", CreatedBy: admin.ID}).Return(key, "uk_Zm9vYmFyYmF6cXV4", nil)
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "api_keys_handler/create",
+		},
+		{
+			name:       "create as a user",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/api-keys").JSON(map[string]interface{}{"name": "Billing", "user_id": user.ID}).As(user),
+			serve:      func(h *apiKeysHandler) http.HandlerFunc { return h.Create },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "create without a user",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/api-keys").JSON(map[string]interface{}{"name": "Billing"}).As(admin),
+			serve:      func(h *apiKeysHandler) http.HandlerFunc { return h.Create },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:    "set an unknown tier",
+			request: handlertest.NewRequest(t, http.MethodPut, "/admin/api-keys/7/tier").Vars(map[string]string{"id": "7"}).JSON(map[string]string{"tier": "gold"}).As(admin),
+			serve:   func(h *apiKeysHandler) http.HandlerFunc { return h.SetTier },
+			expect: func(apiKeys *services.MockAPIKeyServiceInterface) {
+				apiKeys.EXPECT().SetTier(gomock.Any(), uint(7), "gold").Return(apperrors.BadRequestErr.AppendMessage("unknown tier gold"))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.BadRequestErr.Code,
+		},
+		{
+			name:    "usage",
+			request: handlertest.NewRequest(t, http.MethodGet, "/admin/api-keys/7/usage?days=7").Vars(map[string]string{"id": "7"}).As(admin),
+			serve:   func(h *apiKeysHandler) http.HandlerFunc { return h.Usage },
+			expect: func(apiKeys *services.MockAPIKeyServiceInterface) {
+				apiKeys.EXPECT().Usage(gomock.Any(), uint(7), 7).Return(&models.APIKeyUsageReport{
+					APIKeyID: 7, Tier: "standard", Limit: 600, Window: "1m0s", Requests: 15, Limited: 2,
+					Days: []models.APIKeyUsage{{Day: "2024-02-28", Requests: 10}, {Day: "2024-03-01", Requests: 5, Limited: 2}},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "api_keys_handler/usage",
+		},
+		{
+			name:       "usage of too many days",
+			request:    handlertest.NewRequest(t, http.MethodGet, "/admin/api-keys/7/usage?days=1000").Vars(map[string]string{"id": "7"}).As(admin),
+			serve:      func(h *apiKeysHandler) http.HandlerFunc { return h.Usage },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "delete",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/api-keys/7").Vars(map[string]string{"id": "7"}).As(admin),
+			serve:   func(h *apiKeysHandler) http.HandlerFunc { return h.Delete },
+			expect: func(apiKeys *services.MockAPIKeyServiceInterface) {
+				apiKeys.EXPECT().Delete(gomock.Any(), uint(7)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			apiKeys := services.NewMockAPIKeyServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(apiKeys)
+			}
+			handler := NewAPIKeysHandler(apiKeys, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+const maxBansPageSize = 100
+
+type bansHandler struct {
+	*BaseHandler
+	bans      services.BanServiceInterface
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+}
+
+func NewBansHandler(bans services.BanServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *bansHandler {
+	return &bansHandler{
+		BaseHandler: NewBaseHandler(logger),
+		bans:        bans,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+type BanUserRequest struct {
+	// Reason is shown to the user when they try to sign in
+	Reason    string    `json:"reason" validate:"required,max=500"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
+
+// Ban bans the user in the path until the time in the body, replacing their current ban
+func (h *bansHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	by, userID, ok := h.banAction(w, r)
+	if !ok {
+		return
+	}
+	banRequest := &BanUserRequest{}
+	if err := h.decode(r, banRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, banRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	ban, err := h.bans.Ban(r.Context(), by, &models.Ban{
+		UserID:    userID,
+		Reason:    banRequest.Reason,
+		ExpiresAt: banRequest.ExpiresAt,
+	})
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewBanResponse(ban), http.StatusCreated)
+}
+
+// Lift ends the ban of the user in the path
+func (h *bansHandler) Lift(w http.ResponseWriter, r *http.Request) {
+	by, userID, ok := h.banAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.bans.Lift(r.Context(), by, userID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// ListBans returns a page of the bans in force with the banned users, soonest to expire first
+func (h *bansHandler) ListBans(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	page, pageSize := defaultPage, defaultPageSize
+	var err error
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			h.sendError(w, r, errors.New("incorrect page number"), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize <= 0 || pageSize > maxBansPageSize {
+			h.sendError(w, r, errors.New("the number of objects on the page should be in the range from 1 to "+strconv.Itoa(maxBansPageSize)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	bans, err := h.bans.ListBans(r.Context(), page, pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewBanPageResponse(bans), http.StatusOK)
+}
+
+// banAction returns the admin action of a request by an admin and the user in its path
+func (h *bansHandler) banAction(w http.ResponseWriter, r *http.Request) (*models.AdminAction, uint, bool) {
+	if !h.requireAdmin(w, r) {
+		return nil, 0, false
+	}
+	actorID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return nil, 0, false
+	}
+	userID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return nil, 0, false
+	}
+	by, err := adminAction(r, actorID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return by, uint(userID), true
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestBansHandler(t *testing.T) {
+	admin, user := handlertest.Admin, handlertest.User
+	banned := goldenUser(4, "banned@example.com")
+	expiresAt := goldenTime.Add(72 * time.Hour)
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *bansHandler) http.HandlerFunc
+		expect     func(bans *services.MockBanServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name: "ban",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/users/4/ban").Vars(map[string]string{"id": "4"}).
+				JSON(map[string]interface{}{"reason": "Spam", "expires_at": expiresAt}).Header("X-Audit-Reason", "ticket 42").As(admin),
+			serve: func(h *bansHandler) http.HandlerFunc { return h.Ban },
+			expect: func(bans *services.MockBanServiceInterface) {
+				by := &models.AdminAction{ActorID: admin.ID, Reason: "ticket 42"}
+				bans.EXPECT().Ban(gomock.Any(), by, &models.Ban{UserID: 4, Reason: "Spam", ExpiresAt: expiresAt}).
+					DoAndReturn(func(_ interface{}, _ *models.AdminAction, ban *models.Ban) (*models.Ban, error) {
+						ban.BannedBy, ban.CreatedAt = admin.ID, goldenTime
+						return ban, nil
+					})
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "bans_handler/ban",
+		},
+		{
+			name: "ban without a reason",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/users/4/ban").Vars(map[string]string{"id": "4"}).
+				JSON(map[string]interface{}{"expires_at": expiresAt}).As(admin),
+			serve:      func(h *bansHandler) http.HandlerFunc { return h.Ban },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name: "ban by a user",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/users/4/ban").Vars(map[string]string{"id": "4"}).
+				JSON(map[string]interface{}{"reason": "Spam", "expires_at": expiresAt}).As(user),
+			serve:      func(h *bansHandler) http.HandlerFunc { return h.Ban },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "bans",
+			request: handlertest.NewRequest(t, http.MethodGet, "/admin/bans?page_size=1").As(admin),
+			serve:   func(h *bansHandler) http.HandlerFunc { return h.ListBans },
+			expect: func(bans *services.MockBanServiceInterface) {
+				bans.EXPECT().ListBans(gomock.Any(), 1, 1).Return(&models.BanPage{
+					Data:       []models.Ban{{UserID: 4, Reason: "Spam", ExpiresAt: expiresAt, BannedBy: admin.ID, CreatedAt: goldenTime, User: &banned}},
+					Page:       1,
+					PageSize:   1,
+					Total:      2,
+					TotalPages: 2,
+					HasNext:    true,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "bans_handler/bans",
+		},
+		{
+			name:    "lift",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/users/4/ban").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *bansHandler) http.HandlerFunc { return h.Lift },
+			expect: func(bans *services.MockBanServiceInterface) {
+				bans.EXPECT().Lift(gomock.Any(), &models.AdminAction{ActorID: admin.ID}, uint(4)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "lift a user not banned",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/users/4/ban").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *bansHandler) http.HandlerFunc { return h.Lift },
+			expect: func(bans *services.MockBanServiceInterface) {
+				bans.EXPECT().Lift(gomock.Any(), gomock.Any(), uint(4)).Return(repositories.ErrNotFound.AppendMessage("Ban not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			bans := services.NewMockBanServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(bans)
+			}
+			handler := NewBansHandler(bans, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
@@ -3,10 +3,19 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/sentry"
 	"go.uber.org/zap"
+	"golang.org/x/text/language"
 )
 
 type BaseHandler struct {
@@ -19,13 +28,112 @@ func NewBaseHandler(logger *zap.SugaredLogger) *BaseHandler {
 	}
 }
 
-func (h *BaseHandler) sendError(w http.ResponseWriter, err error, httpStatus int) {
-	h.logger.Error(err.Error())
-	h.respond(w, &ErrorResponse{Message: err.Error()}, httpStatus)
+// sendError answers with the status of err when it is an AppError that has one, and httpStatus otherwise
+func (h *BaseHandler) sendError(w http.ResponseWriter, r *http.Request, err error, httpStatus int) {
+	var appError *apperrors.AppError
+	if errors.As(err, &appError) && appError.HTTPCode != 0 {
+		httpStatus = appError.HTTPCode
+	}
+	causes, stack := apperrors.Causes(err), apperrors.Stack(err)
+	if len(causes) == 0 && len(stack) == 0 {
+		h.logger.Error(err.Error())
+	} else {
+		h.logger.Errorw(err.Error(), "causes", causes, "stack", stack)
+	}
+	if httpStatus >= http.StatusInternalServerError {
+		sentry.FromContext(r.Context()).CaptureRequest(r, sentry.LevelError, err, nil)
+	}
+	h.respond(w, NewErrorResponse(err, httpStatus, i18n.FromContext(r.Context())), httpStatus)
 }
 
+// NewErrorResponse builds the body for err, coded with the error's own code or else the generic one for httpStatus.
+// The message of an AppError is translated to lang; the code never is.
+func NewErrorResponse(err error, httpStatus int, lang language.Tag) *ErrorResponse {
+	code := apperrors.ForStatus(httpStatus).Code
+	message := err.Error()
+	var appError *apperrors.AppError
+	if errors.As(err, &appError) {
+		code = appError.Code
+		message = strings.Replace(message, appError.Message, apperrors.Localize(appError, lang), 1)
+	}
+	response := &ErrorResponse{Code: code, Message: message}
+	if appError != nil && appError.Fields != nil {
+		response.Fields = make([]apperrors.FieldViolation, len(appError.Fields))
+		for i, field := range appError.Fields {
+			if field.MessageID != "" {
+				field.Message = i18n.Message(lang, field.MessageID, field.MessageArgs...)
+			}
+			response.Fields[i] = field
+		}
+	}
+	if apperrors.CapturingStacks() {
+		response.Causes, response.Stack = apperrors.Causes(err), apperrors.Stack(err)
+	}
+	return response
+}
+
+// decode reads the JSON body into v. Fields v does not have, values of the wrong type and anything after the
+// value are rejected rather than ignored, so a misspelled field never goes unnoticed.
 func (h *BaseHandler) decode(r *http.Request, v interface{}) error {
-	return json.NewDecoder(r.Body).Decode(v)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		return decodeError(err)
+	}
+	if decoder.Decode(&json.RawMessage{}) != io.EOF {
+		return apperrors.BadRequestErr.AppendMessage("the body has more than one JSON value")
+	}
+	return nil
+}
+
+// decodeError explains why a body did not decode: wrong and unknown fields become field violations, anything
+// else a BadRequestErr
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperrors.BadRequestErr.AppendMessage("the body is empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.BadRequestErr.AppendMessage("the body is not valid JSON")
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		id := "validation.type." + jsonKind(typeErr.Type)
+		return apperrors.ValidationFailedErr.WithFields(apperrors.FieldViolation{
+			Field:     typeErr.Field,
+			Rule:      "type",
+			Message:   i18n.Message(language.English, id),
+			MessageID: id,
+		})
+	case errors.As(err, &typeErr):
+		return apperrors.BadRequestErr.AppendMessage("the body should be a JSON " + jsonKind(typeErr.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return apperrors.ValidationFailedErr.WithFields(apperrors.FieldViolation{
+			Field:     field,
+			Rule:      "unknown",
+			Message:   i18n.Message(language.English, "validation.unknown"),
+			MessageID: "validation.unknown",
+		})
+	}
+	return apperrors.BadRequestErr.AppendMessage(err)
+}
+
+// jsonKind names the JSON type Go values of t decode from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
 }
 
 func (h *BaseHandler) respond(w http.ResponseWriter, data interface{}, httpStatus int) {
@@ -45,3 +153,30 @@ func (h *BaseHandler) GetAuthenticatedRole(ctx context.Context) string {
 	role, _ := ctx.Value(models.RoleContextKey).(string)
 	return role
 }
+
+// requireAdmin answers 403 and returns false unless the request is made by an admin
+func (h *BaseHandler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.GetAuthenticatedRole(r.Context()) != models.StrAdmin {
+		h.sendError(w, r, apperrors.ForbiddenErr.AppendMessage("the admin role is required"), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// adminUser answers 403 unless an admin makes the request and returns the admin's ID, for the audit
+func (h *BaseHandler) adminUser(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	if !h.requireAdmin(w, r) {
+		return 0, false
+	}
+	return h.authenticatedUser(w, r)
+}
+
+// authenticatedUser answers 401 and returns false when the request carries no user
+func (h *BaseHandler) authenticatedUser(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := strconv.ParseUint(h.GetAuthenticatedUserID(r.Context()), 10, 32)
+	if err != nil || userID == 0 {
+		h.sendError(w, r, errors.New("authentication required"), http.StatusUnauthorized)
+		return 0, false
+	}
+	return uint(userID), true
+}
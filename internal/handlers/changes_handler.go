@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type changesHandler struct {
+	*BaseHandler
+	changes services.ChangeServiceInterface
+	logger  *zap.SugaredLogger
+}
+
+func NewChangesHandler(changes services.ChangeServiceInterface, logger *zap.SugaredLogger) *changesHandler {
+	return &changesHandler{
+		BaseHandler: NewBaseHandler(logger),
+		changes:     changes,
+		logger:      logger,
+	}
+}
+
+// ListChanges serves GET /changes?since_seq=<seq>&page_size=<n>, the user changes after since_seq in commit order.
+// Followers store next_seq and pass it as since_seq of their next request.
+func (h *changesHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	queryParams := r.URL.Query()
+	var sinceSeq uint64
+	if queryParams.Get("since_seq") != "" {
+		var err error
+		sinceSeq, err = strconv.ParseUint(queryParams.Get("since_seq"), 10, 64)
+		if err != nil {
+			h.sendError(w, r, errors.New("since_seq should be a sequence number"), http.StatusBadRequest)
+			return
+		}
+	}
+	pageSize := defaultPageSize
+	if queryParams.Get("page_size") != "" {
+		var err error
+		pageSize, err = strconv.Atoi(queryParams.Get("page_size"))
+		if err != nil || pageSize <= 0 || pageSize > maxPageSize {
+			h.sendError(w, r, errors.New("the number of objects on the page should be in the range from 1 to "+strconv.Itoa(maxPageSize)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.changes.ListChanges(r.Context(), sinceSeq, pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewChangesPageResponse(page), http.StatusOK)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestChangesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	changes := services.NewMockChangeServiceInterface(ctrl)
+	handler := NewChangesHandler(changes, zap.NewNop().Sugar())
+
+	handlertest.NewRequest(t, http.MethodGet, "/changes").As(handlertest.User).
+		Serve(handler.ListChanges).
+		AssertStatus(http.StatusForbidden)
+	handlertest.NewRequest(t, http.MethodGet, "/changes?since_seq=-1").As(handlertest.Admin).
+		Serve(handler.ListChanges).
+		AssertStatus(http.StatusBadRequest)
+
+	user := goldenUser(3, "c@example.com")
+	changes.EXPECT().ListChanges(gomock.Any(), uint64(40), 2).Return(&models.ChangesPage{
+		Data: []models.Change{
+			{Seq: 41, UserID: 3, Operation: models.ChangeOperationUpdate, ChangedAt: goldenTime, User: &user},
+			{Seq: 43, UserID: 4, Operation: models.ChangeOperationDelete, ChangedAt: goldenTime.Add(time.Minute)},
+		},
+		HasMore: true,
+		NextSeq: 43,
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/changes?since_seq=40&page_size=2").As(handlertest.Admin).
+		Serve(handler.ListChanges).
+		AssertStatus(http.StatusOK).
+		AssertGolden("changes_handler/list")
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/buildinfo"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+)
+
+type debugHandler struct {
+	*BaseHandler
+	// effectiveConfig returns the configuration in use, including settings changed by reloads
+	effectiveConfig func() *config.Config
+	logger          *zap.SugaredLogger
+}
+
+func NewDebugHandler(effectiveConfig func() *config.Config, logger *zap.SugaredLogger) *debugHandler {
+	return &debugHandler{
+		BaseHandler:     NewBaseHandler(logger),
+		effectiveConfig: effectiveConfig,
+		logger:          logger,
+	}
+}
+
+type DebugConfigResponse struct {
+	Profile string            `json:"profile"`
+	Config  map[string]string `json:"config"`
+	Build   buildinfo.Info    `json:"build"`
+}
+
+// Config returns the effective configuration keyed by variable name, with secrets redacted as in the startup log
+func (h *debugHandler) Config(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	cfg := h.effectiveConfig()
+	h.respond(w, &DebugConfigResponse{
+		Profile: cfg.Profile().Name,
+		Config:  cfg.MaskedValues(),
+		Build:   buildinfo.Read(),
+	}, http.StatusOK)
+}
+
+// Pprof serves the runtime profiles of net/http/pprof: the index at /debug/pprof/ and each profile at
+// /debug/pprof/{profile}, e.g. heap, goroutine or profile?seconds=30 for CPU
+func (h *debugHandler) Pprof(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	switch mux.Vars(r)["profile"] {
+	case "":
+		pprof.Index(w, r)
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		// Index serves the named profiles (heap, goroutine, block, ...) and answers 404 for unknown ones
+		pprof.Index(w, r)
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestDebugConfig(t *testing.T) {
+	cfg := &config.Config{
+		AppEnv:      config.EnvStaging,
+		AppPort:     "50052",
+		JwtKey:      "jwt-secret",
+		PostgresURI: "postgres://app:db-secret@db:5432/app",
+	}
+	handler := NewDebugHandler(func() *config.Config { return cfg }, zap.NewExample().Sugar())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	w := httptest.NewRecorder()
+	handler.Config(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req = req.WithContext(context.WithValue(req.Context(), models.RoleContextKey, models.StrAdmin))
+	w = httptest.NewRecorder()
+	handler.Config(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	var response DebugConfigResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, config.EnvStaging, response.Profile)
+	assert.Equal(t, "50052", response.Config["APP_PORT"])
+	assert.Equal(t, "postgres://app:xxxxx@db:5432/app", response.Config["POSTGRES_URI"])
+	assert.NotEmpty(t, response.Build.GoVersion)
+}
+
+func TestDebugPprof(t *testing.T) {
+	handler := NewDebugHandler(func() *config.Config { return &config.Config{} }, zap.NewExample().Sugar())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil)
+	req = mux.SetURLVars(req, map[string]string{"profile": "goroutine"})
+	w := httptest.NewRecorder()
+	handler.Pprof(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req = req.WithContext(context.WithValue(req.Context(), models.RoleContextKey, models.StrAdmin))
+	w = httptest.NewRecorder()
+	handler.Pprof(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine profile:")
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"go.uber.org/zap"
+)
+
+type errorsHandler struct {
+	*BaseHandler
+	logger *zap.SugaredLogger
+}
+
+func NewErrorsHandler(logger *zap.SugaredLogger) *errorsHandler {
+	return &errorsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		logger:      logger,
+	}
+}
+
+// ListErrors returns the catalog of error codes responses may carry, with the messages in the request language
+func (h *errorsHandler) ListErrors(w http.ResponseWriter, r *http.Request) {
+	lang := i18n.FromContext(r.Context())
+	entries := apperrors.Catalog()
+	for i, entry := range entries {
+		entries[i].Message = apperrors.Localize(&apperrors.AppError{Code: entry.Code, Message: entry.Message}, lang)
+	}
+	h.respond(w, entries, http.StatusOK)
+}
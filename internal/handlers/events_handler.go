@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type eventsHandler struct {
+	*BaseHandler
+	eventService services.EventServiceInterface
+	logger       *zap.SugaredLogger
+	cfg          *config.Config
+}
+
+func NewEventsHandler(eventService services.EventServiceInterface, logger *zap.SugaredLogger, cfg *config.Config) *eventsHandler {
+	return &eventsHandler{
+		BaseHandler:  NewBaseHandler(logger),
+		eventService: eventService,
+		logger:       logger,
+		cfg:          cfg,
+	}
+}
+
+type ReplayEventsRequest struct {
+	FromSeq  uint64    `json:"from_seq"`
+	ToSeq    uint64    `json:"to_seq"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+	Sink     struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"sink"`
+}
+
+func (h *eventsHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	type ReplayEventsResponse struct {
+		Replayed int    `json:"replayed"`
+		Message  string `json:"message,omitempty"`
+	}
+
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	replayRequest := &ReplayEventsRequest{}
+	err := h.decode(r, replayRequest)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if replayRequest.ToSeq > 0 && replayRequest.FromSeq > replayRequest.ToSeq {
+		h.sendError(w, r, errors.New("from_seq must not be greater than to_seq"), http.StatusBadRequest)
+		return
+	}
+
+	sink, err := events.NewSink(replayRequest.Sink.Type, replayRequest.Sink.URL, h.logger)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	filter := models.EventFilter{
+		FromSeq:  replayRequest.FromSeq,
+		ToSeq:    replayRequest.ToSeq,
+		FromTime: replayRequest.FromTime,
+		ToTime:   replayRequest.ToTime,
+	}
+
+	replayed, err := h.eventService.Replay(r.Context(), filter, sink)
+	if err != nil {
+		h.logger.Error(err)
+		h.respond(w, &ReplayEventsResponse{Replayed: replayed, Message: err.Error()}, http.StatusBadGateway)
+		return
+	}
+
+	h.respond(w, &ReplayEventsResponse{Replayed: replayed}, http.StatusOK)
+}
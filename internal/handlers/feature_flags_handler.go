@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type featureFlagsHandler struct {
+	*BaseHandler
+	featureFlags services.FeatureFlagServiceInterface
+	logger       *zap.SugaredLogger
+	cfg          *config.Config
+}
+
+func NewFeatureFlagsHandler(featureFlags services.FeatureFlagServiceInterface, logger *zap.SugaredLogger, cfg *config.Config) *featureFlagsHandler {
+	return &featureFlagsHandler{
+		BaseHandler:  NewBaseHandler(logger),
+		featureFlags: featureFlags,
+		logger:       logger,
+		cfg:          cfg,
+	}
+}
+
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+	UserID  uint  `json:"user_id"` // 0 overrides the feature for everybody
+}
+
+func (h *featureFlagsHandler) ListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	states, err := h.featureFlags.ListFeatures(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, states, http.StatusOK)
+}
+
+func (h *featureFlagsHandler) SetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	setRequest := &SetFeatureFlagRequest{}
+	err := h.decode(r, setRequest)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if setRequest.Enabled == nil {
+		h.sendError(w, r, errors.New("enabled is required"), http.StatusBadRequest)
+		return
+	}
+
+	flag := &models.FeatureFlag{Name: mux.Vars(r)["name"], UserID: setRequest.UserID, Enabled: *setRequest.Enabled}
+	err = h.featureFlags.SetOverride(r.Context(), flag)
+	if errors.Is(err, &apperrors.NoRecordFoundErr) {
+		h.sendError(w, r, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, flag, http.StatusOK)
+}
+
+// DeleteFeatureFlag removes the override for everybody, or for the user given as ?user_id=
+func (h *featureFlagsHandler) DeleteFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var userID uint64
+	if value := r.URL.Query().Get("user_id"); value != "" {
+		var err error
+		userID, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := h.featureFlags.DeleteOverride(r.Context(), mux.Vars(r)["name"], uint(userID))
+	if errors.Is(err, &apperrors.NoRecordFoundErr) {
+		h.sendError(w, r, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// requireFeature answers 403 and returns false when the feature is off for the authenticated user
+func (h *BaseHandler) requireFeature(w http.ResponseWriter, r *http.Request, featureFlags services.FeatureFlagServiceInterface, name string) bool {
+	userID, _ := strconv.Atoi(h.GetAuthenticatedUserID(r.Context()))
+	if featureFlags.Enabled(r.Context(), name, uint(userID)) {
+		return true
+	}
+	h.sendError(w, r, &apperrors.FeatureDisabledErr, http.StatusForbidden)
+	return false
+}
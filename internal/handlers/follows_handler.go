@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+const maxFollowsPageSize = 100
+
+type followsHandler struct {
+	*BaseHandler
+	follows services.FollowServiceInterface
+	logger  *zap.SugaredLogger
+}
+
+func NewFollowsHandler(follows services.FollowServiceInterface, logger *zap.SugaredLogger) *followsHandler {
+	return &followsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		follows:     follows,
+		logger:      logger,
+	}
+}
+
+// Follow subscribes the caller to the profile in the path and answers 204, also when they already follow it
+func (h *followsHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	followerID, followedID, ok := h.followIDs(w, r)
+	if !ok {
+		return
+	}
+	if err := h.follows.Follow(r.Context(), followerID, followedID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+func (h *followsHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	followerID, followedID, ok := h.followIDs(w, r)
+	if !ok {
+		return
+	}
+	if err := h.follows.Unfollow(r.Context(), followerID, followedID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// ListFollowers returns a page of the users following the user in the path, latest first
+func (h *followsHandler) ListFollowers(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.follows.ListFollowers, false)
+}
+
+// ListFollowing returns a page of the users the user in the path follows, latest first
+func (h *followsHandler) ListFollowing(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.follows.ListFollowing, true)
+}
+
+func (h *followsHandler) list(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID uint, page, pageSize int) (*models.FollowPage, error), following bool) {
+	userID, err := parseProfileID(mux.Vars(r)["id"])
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	page, pageSize := defaultPage, defaultPageSize
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			h.sendError(w, r, errors.New("incorrect page number"), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize <= 0 || pageSize > maxFollowsPageSize {
+			h.sendError(w, r, errors.New("the number of objects on the page should be in the range from 1 to "+strconv.Itoa(maxFollowsPageSize)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	follows, err := list(r.Context(), uint(userID), page, pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewFollowPageResponse(follows, following), http.StatusOK)
+}
+
+// Counts returns how many users follow the user in the path and how many they follow
+func (h *followsHandler) Counts(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseProfileID(mux.Vars(r)["id"])
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	counts, err := h.follows.Counts(r.Context(), uint(userID))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, counts, http.StatusOK)
+}
+
+// followIDs returns the caller and the profile in the path
+func (h *followsHandler) followIDs(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	followerID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	followedID, err := parseProfileID(mux.Vars(r)["id"])
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return followerID, uint(followedID), true
+}
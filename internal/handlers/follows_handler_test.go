@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestFollowsHandler(t *testing.T) {
+	user := handlertest.User
+	follower := goldenUser(4, "follower@example.com")
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *followsHandler) http.HandlerFunc
+		expect     func(follows *services.MockFollowServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "follow",
+			request: handlertest.NewRequest(t, http.MethodPost, "/users/7/follow").Vars(map[string]string{"id": "7"}).As(user),
+			serve:   func(h *followsHandler) http.HandlerFunc { return h.Follow },
+			expect: func(follows *services.MockFollowServiceInterface) {
+				follows.EXPECT().Follow(gomock.Any(), user.ID, uint(7)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "follow yourself",
+			request: handlertest.NewRequest(t, http.MethodPost, "/users/1/follow").Vars(map[string]string{"id": "1"}).As(user),
+			serve:   func(h *followsHandler) http.HandlerFunc { return h.Follow },
+			expect: func(follows *services.MockFollowServiceInterface) {
+				follows.EXPECT().Follow(gomock.Any(), user.ID, user.ID).Return(apperrors.BadRequestErr.AppendMessage("you cannot follow yourself"))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.BadRequestErr.Code,
+		},
+		{
+			name:    "unfollow a profile not followed",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/users/7/follow").Vars(map[string]string{"id": "7"}).As(user),
+			serve:   func(h *followsHandler) http.HandlerFunc { return h.Unfollow },
+			expect: func(follows *services.MockFollowServiceInterface) {
+				follows.EXPECT().Unfollow(gomock.Any(), user.ID, uint(7)).Return(repositories.ErrNotFound.AppendMessage("Follow not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
+		{
+			name:    "followers",
+			request: handlertest.NewRequest(t, http.MethodGet, "/users/1/followers?page=1&page_size=1").Vars(map[string]string{"id": "1"}).As(user),
+			serve:   func(h *followsHandler) http.HandlerFunc { return h.ListFollowers },
+			expect: func(follows *services.MockFollowServiceInterface) {
+				follows.EXPECT().ListFollowers(gomock.Any(), user.ID, 1, 1).Return(&models.FollowPage{
+					Data:       []models.Follow{{FollowerID: 4, FollowedID: user.ID, CreatedAt: goldenTime, Follower: &follower}},
+					Page:       1,
+					PageSize:   1,
+					Total:      2,
+					TotalPages: 2,
+					HasNext:    true,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "follows_handler/followers",
+		},
+		{
+			name:       "followers with a page too large",
+			request:    handlertest.NewRequest(t, http.MethodGet, "/users/1/following?page_size=1000").Vars(map[string]string{"id": "1"}).As(user),
+			serve:      func(h *followsHandler) http.HandlerFunc { return h.ListFollowing },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "counts",
+			request: handlertest.NewRequest(t, http.MethodGet, "/users/1/follow-counts").Vars(map[string]string{"id": "1"}).As(user),
+			serve:   func(h *followsHandler) http.HandlerFunc { return h.Counts },
+			expect: func(follows *services.MockFollowServiceInterface) {
+				follows.EXPECT().Counts(gomock.Any(), user.ID).Return(&models.FollowCounts{UserID: user.ID, Followers: 2, Following: 5}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "follows_handler/counts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			follows := services.NewMockFollowServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(follows)
+			}
+			handler := NewFollowsHandler(follows, zap.NewNop().Sugar())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
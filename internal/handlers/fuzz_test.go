@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+// The fuzz targets run their seed corpus with `go test`; fuzz one with e.g.
+// `go test ./internal/handlers -run '^$' -fuzz FuzzCreateUserHandler -fuzztime 1m`
+
+func newFuzzValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
+	return validate
+}
+
+// assertJSONResponse fails unless the handler answered with one of statuses and, if any, a JSON body
+func assertJSONResponse(t *testing.T, response *handlertest.Response, statuses ...int) {
+	t.Helper()
+	allowed := false
+	for _, status := range statuses {
+		allowed = allowed || response.Code == status
+	}
+	if !allowed {
+		t.Fatalf("unexpected status %d, body: %s", response.Code, response.Body.String())
+	}
+	if response.Body.Len() > 0 && !json.Valid(response.Body.Bytes()) {
+		t.Fatalf("the body is not JSON: %q", response.Body.String())
+	}
+}
+
+func FuzzCreateUserHandler(f *testing.F) {
+	f.Add([]byte(`{"email":"test@example.com","first_name":"John","last_name":"Doe","password":"password@123"}`))
+	f.Add([]byte(`{"email":"test@example.com","first_name":"John","last_name":"Doe","password":"password@123","role_id":3}`))
+	f.Add([]byte(`{"email":"not-an-email","password":"short"}`))
+	f.Add([]byte(`{"email":1,"role_id":-1}`))
+	f.Add([]byte(`{"role_id":99999999999999999999}`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"email":"a@b.c"`))
+	f.Add([]byte("{\"first_name\":\"\xff\xfe\"}"))
+	validate := newFuzzValidator()
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		ctrl := gomock.NewController(t)
+		userService := services.NewMockUserServiceInterface(ctrl)
+		userService.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Return(nil, &apperrors.NoRecordFoundErr).AnyTimes()
+		userService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, user *models.User) (uint, error) {
+			if user.RoleID != 1 {
+				t.Errorf("registration created a user with role %d", user.RoleID)
+			}
+			return 1, nil
+		}).AnyTimes()
+		featureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+		featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+		handler := NewUserHandler(userService, featureFlags, nil, nil, nil, testSignupRoles, zap.NewNop().Sugar(), validate, &config.Config{})
+
+		response := handlertest.NewRequest(t, http.MethodPost, "/users").JSON(body).Serve(handler.CreateUserHandler)
+		assertJSONResponse(t, response, http.StatusCreated, http.StatusBadRequest)
+
+		if response.Code == http.StatusCreated {
+			// Whatever was accepted must have been a valid request
+			request := &CreateUserRequest{}
+			if err := json.Unmarshal(body, request); err != nil {
+				t.Fatalf("accepted a body that does not decode: %v", err)
+			}
+			if err := validate.Struct(request); err != nil {
+				t.Fatalf("accepted an invalid request: %v", err)
+			}
+		}
+	})
+}
+
+func FuzzUpdateUserHandler(f *testing.F) {
+	f.Add("12", []byte(`{"email":"test@example.com","first_name":"John","last_name":"Doe","password":"password@123"}`), false)
+	f.Add("12", []byte(`{"email":"test@example.com","first_name":"John","last_name":"Doe","password":"password@123","role_id":3}`), true)
+	f.Add("abc", []byte(`{"role_id":"3"}`), true)
+	f.Add("", []byte(`{}`), false)
+	f.Add("-1", []byte(`{"password":null}`), false)
+	f.Add("12", []byte(`{"email":["x"]}`), true)
+	validate := newFuzzValidator()
+
+	f.Fuzz(func(t *testing.T, id string, body []byte, admin bool) {
+		identity := handlertest.User
+		if admin {
+			identity = handlertest.Admin
+		}
+		ctrl := gomock.NewController(t)
+		userService := services.NewMockUserServiceInterface(ctrl)
+		userService.EXPECT().UpdateUser(gomock.Any(), id, gomock.Any()).DoAndReturn(func(_ interface{}, _ string, user *models.User) (*models.User, error) {
+			if !admin && user.RoleID != 0 {
+				t.Errorf("a user changed the role to %d", user.RoleID)
+			}
+			return user, nil
+		}).AnyTimes()
+		audit := services.NewMockAdminAuditServiceInterface(ctrl)
+		audit.EXPECT().UpdateUser(gomock.Any(), gomock.Any(), id, gomock.Any()).DoAndReturn(func(_ interface{}, _ *models.AdminAction, _ string, user *models.User) (*models.User, error) {
+			if !admin {
+				t.Errorf("a user went through the admin audit")
+			}
+			return user, nil
+		}).AnyTimes()
+		securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+		securityEvents.EXPECT().Record(gomock.Any(), gomock.Any(), models.SecurityEventPasswordChanged, gomock.Any()).AnyTimes()
+		handler := NewUserHandler(userService, services.NewMockFeatureFlagServiceInterface(ctrl), audit, securityEvents, nil, testSignupRoles, zap.NewNop().Sugar(), validate, &config.Config{})
+
+		response := handlertest.NewRequest(t, http.MethodPut, "/users/12").
+			Vars(map[string]string{"id": id}).
+			As(identity).
+			JSON(body).
+			Serve(handler.UpdateUser)
+		assertJSONResponse(t, response, http.StatusCreated, http.StatusBadRequest, http.StatusForbidden)
+	})
+}
+
+func FuzzVoteHandler(f *testing.F) {
+	for _, id := range []string{"7", "1", "0", "-7", "abc", "", "007", "+7", "99999999999999999999", "4294967303"} {
+		f.Add(id, true)
+	}
+	f.Add("7", false)
+
+	f.Fuzz(func(t *testing.T, id string, like bool) {
+		voter := handlertest.User
+		ctrl := gomock.NewController(t)
+		userService := services.NewMockUserServiceInterface(ctrl)
+		userService.EXPECT().Vote(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, vote *models.Vote) (uint, error) {
+			if vote.UserID == vote.ProfileID {
+				t.Errorf("voted for %q, the voter's own profile", id)
+			}
+			if parsed, err := strconv.ParseUint(id, 10, 32); err != nil || uint(parsed) != vote.ProfileID {
+				t.Errorf("voted for profile %d from %q", vote.ProfileID, id)
+			}
+			if (like && vote.Value != 1) || (!like && vote.Value != -1) {
+				t.Errorf("unexpected vote value %d", vote.Value)
+			}
+			return 1, nil
+		}).AnyTimes()
+		featureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+		featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureVoting, voter.ID).Return(true)
+		handler := NewVotesHandler(userService, featureFlags, zap.NewNop().Sugar(), &config.Config{})
+
+		serve := handler.Dislike
+		if like {
+			serve = handler.Like
+		}
+		response := handlertest.NewRequest(t, http.MethodPost, "/like/7").
+			Vars(map[string]string{"id": id}).
+			As(voter).
+			Serve(serve)
+		assertJSONResponse(t, response, http.StatusCreated, http.StatusBadRequest, http.StatusForbidden)
+	})
+}
+
+func FuzzValidateListUsersParam(f *testing.F) {
+	for _, params := range [][2]string{
+		{"", ""}, {"1", "10"}, {"0", "10"}, {"-1", "-1"}, {"2", "1000"}, {"2", "1001"},
+		{"abc", "10"}, {"1", "1e3"}, {"9223372036854775807", "1"}, {"99999999999999999999", "5"}, {" 1", "10 "},
+	} {
+		f.Add(params[0], params[1])
+	}
+	handler := NewUserHandler(nil, nil, nil, nil, nil, testSignupRoles, zap.NewNop().Sugar(), nil, &config.Config{})
+
+	f.Fuzz(func(t *testing.T, page, pageSize string) {
+		validPage, validPageSize, err := handler.validateListUsersParam(page, pageSize)
+		if err != nil {
+			return
+		}
+		if validPage < 1 {
+			t.Errorf("accepted page %d from %q", validPage, page)
+		}
+		if validPageSize < 1 || validPageSize > maxPageSize {
+			t.Errorf("accepted page size %d from %q", validPageSize, pageSize)
+		}
+	})
+}
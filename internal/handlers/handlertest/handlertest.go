@@ -0,0 +1,226 @@
+// Package handlertest builds requests for the HTTP handlers and checks their responses. Requests can carry the
+// identity of a logged-in user the way jwtMiddleware leaves it, and response bodies can be compared with golden
+// JSON files in the testdata directory of the test's package; `go test ./internal/handlers -update` rewrites them.
+// Every response to a request for an operation of the OpenAPI document is checked against it.
+package handlertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/openapi"
+	"golang.org/x/text/language"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the responses the tests get")
+
+// JwtKey signs the tokens of requests made with As
+const JwtKey = "handlertest-secret"
+
+// Identity is the logged-in user a request is made as
+type Identity struct {
+	ID    uint
+	Email string
+	Role  string
+}
+
+// User, Moderator and Admin are identities with each of the roles the migrations create
+var (
+	User      = Identity{ID: 1, Email: "user@example.com", Role: models.StrUser}
+	Moderator = Identity{ID: 2, Email: "moderator@example.com", Role: models.StrModerator}
+	Admin     = Identity{ID: 3, Email: "admin@example.com", Role: models.StrAdmin}
+)
+
+// Request builds an *http.Request for calling a handler directly
+type Request struct {
+	t        *testing.T
+	method   string
+	target   string
+	body     io.Reader
+	header   http.Header
+	vars     map[string]string
+	identity *Identity
+	lang     *language.Tag
+}
+
+// NewRequest starts a request for method and target, e.g. "/users?page=2"
+func NewRequest(t *testing.T, method, target string) *Request {
+	return &Request{t: t, method: method, target: target, header: http.Header{}}
+}
+
+// JSON sends v encoded as JSON; a string or []byte is sent as it is
+func (r *Request) JSON(v interface{}) *Request {
+	switch body := v.(type) {
+	case string:
+		r.body = strings.NewReader(body)
+	case []byte:
+		r.body = bytes.NewReader(body)
+	default:
+		encoded, err := json.Marshal(v)
+		require.NoError(r.t, err)
+		r.body = bytes.NewReader(encoded)
+	}
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// Form sends values as an urlencoded form, like the login page does
+func (r *Request) Form(values url.Values) *Request {
+	r.body = strings.NewReader(values.Encode())
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// Vars sets the route variables mux would take from the path, e.g. {"id": "12"}
+func (r *Request) Vars(vars map[string]string) *Request {
+	r.vars = vars
+	return r
+}
+
+// As makes the request as identity: with a token signed with JwtKey, and with the identity in the context as
+// jwtMiddleware puts it there
+func (r *Request) As(identity Identity) *Request {
+	r.identity = &identity
+	return r
+}
+
+// Language negotiates tag for the request, as the language middleware does with Accept-Language
+func (r *Request) Language(tag language.Tag) *Request {
+	r.lang = &tag
+	return r
+}
+
+// Header sets a request header
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Build returns the request
+func (r *Request) Build() *http.Request {
+	req := httptest.NewRequest(r.method, r.target, r.body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	if r.vars != nil {
+		req = mux.SetURLVars(req, r.vars)
+	}
+
+	ctx := req.Context()
+	if r.identity != nil {
+		req.Header.Set("Authorization", "Bearer "+string(auth.GenerateTokenHandler(r.identity.Email, r.identity.Role, r.identity.ID, []byte(JwtKey))))
+		ctx = context.WithValue(ctx, models.RoleContextKey, r.identity.Role)
+		ctx = context.WithValue(ctx, models.EmailContextKey, r.identity.Email)
+		ctx = context.WithValue(ctx, models.IDContextKey, strconv.FormatUint(uint64(r.identity.ID), 10))
+	}
+	if r.lang != nil {
+		ctx = i18n.WithLanguage(ctx, *r.lang)
+	}
+	return req.WithContext(ctx)
+}
+
+// Serve calls handler with the request and returns what it answered, failing the test when the operation is in
+// the OpenAPI document and the answer does not match it
+func (r *Request) Serve(handler http.HandlerFunc) *Response {
+	w := httptest.NewRecorder()
+	handler(w, r.Build())
+	assertContract(r.t, r.method, r.target, w)
+	return &Response{t: r.t, ResponseRecorder: w}
+}
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Spec
+	specErr  error
+)
+
+// assertContract checks the response against the OpenAPI document; a request to an operation it does not describe
+// fails the test, so a new route cannot go without its entry
+func assertContract(t *testing.T, method, target string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	specOnce.Do(func() { spec, specErr = openapi.Load() })
+	require.NoError(t, specErr)
+
+	err := spec.ValidateResponse(method, target, w.Code, w.Body.Bytes())
+	if errors.Is(err, openapi.ErrUndocumented) {
+		assert.Fail(t, "the operation is missing from internal/openapi/openapi.yaml", "%s %s", method, target)
+		return
+	}
+	assert.NoError(t, err, "the response differs from internal/openapi/openapi.yaml; body: %s", w.Body.String())
+}
+
+// Response is what a handler answered
+type Response struct {
+	t *testing.T
+	*httptest.ResponseRecorder
+}
+
+// AssertStatus checks the status code, showing the body when it differs
+func (r *Response) AssertStatus(want int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, want, r.Code, "body: %s", r.Body.String())
+	return r
+}
+
+// Decode decodes the JSON body into v, failing the test when it is not JSON
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Body.Bytes(), v), "body: %s", r.Body.String())
+}
+
+// AssertErrorCode checks that the body is an error response with code, e.g. apperrors.FeatureDisabledErr.Code
+func (r *Response) AssertErrorCode(code string) *Response {
+	r.t.Helper()
+	var response struct {
+		Code string `json:"code"`
+	}
+	r.Decode(&response)
+	assert.Equal(r.t, code, response.Code)
+	return r
+}
+
+// AssertGolden compares the JSON body with testdata/<name>.golden.json, ignoring formatting and key order
+func (r *Response) AssertGolden(name string) *Response {
+	r.t.Helper()
+	AssertGoldenJSON(r.t, name, r.Body.Bytes())
+	return r
+}
+
+// AssertGoldenJSON compares got with testdata/<name>.golden.json, ignoring formatting and key order. With -update
+// the file is written with got instead.
+func AssertGoldenJSON(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden.json")
+
+	if *update {
+		var indented bytes.Buffer
+		require.NoError(t, json.Indent(&indented, bytes.TrimSpace(got), "", "  "), "got: %s", got)
+		indented.WriteByte('\n')
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, indented.Bytes(), 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run the test with -update to create %s", path)
+	assert.JSONEq(t, string(want), string(got), "compared with %s; run the test with -update to accept the new output", path)
+}
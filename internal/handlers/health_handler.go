@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// readinessTimeout bounds all dependency checks of one /readyz request
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency can be used
+type HealthCheck func(ctx context.Context) error
+
+// HealthResponse is the body of /livez and /readyz; checks are "ok" or "unavailable"
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type healthHandler struct {
+	*BaseHandler
+	checks map[string]HealthCheck
+	logger *zap.SugaredLogger
+}
+
+func NewHealthHandler(checks map[string]HealthCheck, logger *zap.SugaredLogger) *healthHandler {
+	return &healthHandler{
+		BaseHandler: NewBaseHandler(logger),
+		checks:      checks,
+		logger:      logger,
+	}
+}
+
+// Livez answers as long as the process serves requests
+func (h *healthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, HealthResponse{Status: "ok"}, http.StatusOK)
+}
+
+// Readyz answers 503 while a dependency is unavailable. The probes are unauthenticated, so the errors
+// are only logged.
+func (h *healthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response := HealthResponse{Status: "ok", Checks: make(map[string]string, len(names))}
+	for _, name := range names {
+		if err := h.checks[name](ctx); err != nil {
+			h.logger.Warnw("Readiness check failed", "check", name, "error", err)
+			response.Checks[name] = "unavailable"
+			response.Status = "unavailable"
+			continue
+		}
+		response.Checks[name] = "ok"
+	}
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	h.respond(w, response, status)
+}
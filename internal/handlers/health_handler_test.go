@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReadyz(t *testing.T) {
+	healthy := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: connection refused") }
+
+	handler := NewHealthHandler(map[string]HealthCheck{"database": healthy, "redis": healthy}, zap.NewExample().Sugar())
+	w := httptest.NewRecorder()
+	handler.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response HealthResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, HealthResponse{Status: "ok", Checks: map[string]string{"database": "ok", "redis": "ok"}}, response)
+
+	handler = NewHealthHandler(map[string]HealthCheck{"database": down, "redis": healthy}, zap.NewExample().Sugar())
+	w = httptest.NewRecorder()
+	handler.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotContains(t, w.Body.String(), "10.0.0.5", "errors are not exposed")
+
+	response = HealthResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "unavailable", response.Status)
+	assert.Equal(t, map[string]string{"database": "unavailable", "redis": "ok"}, response.Checks)
+}
+
+func TestLivez(t *testing.T) {
+	handler := NewHealthHandler(nil, zap.NewExample().Sugar())
+	w := httptest.NewRecorder()
+	handler.Livez(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
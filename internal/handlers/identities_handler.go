@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type identitiesHandler struct {
+	*BaseHandler
+	identityService services.IdentityServiceInterface
+	securityEvents  services.SecurityEventServiceInterface
+	logger          *zap.SugaredLogger
+}
+
+func NewIdentitiesHandler(identityService services.IdentityServiceInterface, securityEvents services.SecurityEventServiceInterface, logger *zap.SugaredLogger) *identitiesHandler {
+	return &identitiesHandler{
+		BaseHandler:     NewBaseHandler(logger),
+		identityService: identityService,
+		securityEvents:  securityEvents,
+		logger:          logger,
+	}
+}
+
+// ListIdentities returns the ways the authenticated user can sign in, e.g. their password and a GitHub account
+func (h *identitiesHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	identities, err := h.identityService.ListIdentities(r.Context(), userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, identities, http.StatusOK)
+}
+
+// UnlinkIdentity removes the authenticated user's identity at the provider in the path; the last one is kept
+func (h *identitiesHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	provider := mux.Vars(r)["provider"]
+	err := h.identityService.UnlinkIdentity(r.Context(), userID, provider)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.securityEvents.Record(r.Context(), userID, models.SecurityEventIdentityUnlinked, provider)
+	h.respond(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestIdentitiesHandler(t *testing.T) {
+	user := handlertest.User
+
+	tests := []struct {
+		name    string
+		request *handlertest.Request
+		serve   func(h *identitiesHandler) http.HandlerFunc
+		expect  func(identities *services.MockIdentityServiceInterface)
+		// event is the security event the case records for the user
+		event      string
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "list",
+			request: handlertest.NewRequest(t, http.MethodGet, "/me/identities").As(user),
+			serve:   func(h *identitiesHandler) http.HandlerFunc { return h.ListIdentities },
+			expect: func(identities *services.MockIdentityServiceInterface) {
+				identities.EXPECT().ListIdentities(gomock.Any(), user.ID).Return([]models.Identity{
+					{ID: 1, UserID: user.ID, Provider: models.ProviderPassword, Subject: "1", CreatedAt: goldenTime},
+					{ID: 2, UserID: user.ID, Provider: models.ProviderGitHub, Subject: "octocat", CreatedAt: goldenTime},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "identities_handler/list",
+		},
+		{
+			name:       "list anonymously",
+			request:    handlertest.NewRequest(t, http.MethodGet, "/me/identities"),
+			serve:      func(h *identitiesHandler) http.HandlerFunc { return h.ListIdentities },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "unlink",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/me/identities/github").Vars(map[string]string{"provider": "github"}).As(user),
+			serve:   func(h *identitiesHandler) http.HandlerFunc { return h.UnlinkIdentity },
+			expect: func(identities *services.MockIdentityServiceInterface) {
+				identities.EXPECT().UnlinkIdentity(gomock.Any(), user.ID, models.ProviderGitHub).Return(nil)
+			},
+			event:      models.SecurityEventIdentityUnlinked,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "unlink the last identity",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/me/identities/password").Vars(map[string]string{"provider": "password"}).As(user),
+			serve:   func(h *identitiesHandler) http.HandlerFunc { return h.UnlinkIdentity },
+			expect: func(identities *services.MockIdentityServiceInterface) {
+				identities.EXPECT().UnlinkIdentity(gomock.Any(), user.ID, models.ProviderPassword).Return(apperrors.LastIdentityErr.AppendMessage("password"))
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.LastIdentityErr.Code,
+		},
+		{
+			name:    "unlink an identity the user does not have",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/me/identities/saml").Vars(map[string]string{"provider": "saml"}).As(user),
+			serve:   func(h *identitiesHandler) http.HandlerFunc { return h.UnlinkIdentity },
+			expect: func(identities *services.MockIdentityServiceInterface) {
+				identities.EXPECT().UnlinkIdentity(gomock.Any(), user.ID, models.ProviderSAML).Return(apperrors.NoRecordFoundErr.AppendMessage("Identity not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			identities := services.NewMockIdentityServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(identities)
+			}
+			securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+			if tt.event != "" {
+				securityEvents.EXPECT().Record(gomock.Any(), user.ID, tt.event, gomock.Any())
+			}
+			handler := NewIdentitiesHandler(identities, securityEvents, zap.NewNop().Sugar())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type introspectionHandler struct {
+	*BaseHandler
+	introspection services.IntrospectionServiceInterface
+	logger        *zap.SugaredLogger
+}
+
+func NewIntrospectionHandler(introspection services.IntrospectionServiceInterface, logger *zap.SugaredLogger) *introspectionHandler {
+	return &introspectionHandler{
+		BaseHandler:   NewBaseHandler(logger),
+		introspection: introspection,
+		logger:        logger,
+	}
+}
+
+// Introspect answers a client, authenticated with HTTP Basic or client_id and client_secret in the form, that
+// posts a token: {"active": false}, or every claim of the token with active, token_type, sub and username added
+func (h *introspectionHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if !h.introspection.AuthenticateClient(clientID, secret) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+		h.sendError(w, r, apperrors.UnauthorizedErr.AppendMessage("unknown client or wrong secret"), http.StatusUnauthorized)
+		return
+	}
+	token := r.PostFormValue("token")
+	if token == "" {
+		h.sendError(w, r, apperrors.BadRequestErr.AppendMessage("token is required"), http.StatusBadRequest)
+		return
+	}
+
+	claims, err := h.introspection.Introspect(r.Context(), token)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if claims == nil {
+		h.respond(w, map[string]interface{}{"active": false}, http.StatusOK)
+		return
+	}
+
+	response := make(map[string]interface{}, len(claims)+4)
+	for name, value := range claims {
+		response[name] = value
+	}
+	response["active"] = true
+	response["token_type"] = "Bearer"
+	// Client credentials tokens keep the client as sub and have no user
+	if userID, ok := claims["user_id"].(float64); ok && userID > 0 {
+		response["sub"] = strconv.FormatFloat(userID, 'f', -1, 64)
+	}
+	if email, ok := claims["email"].(string); ok && email != "" {
+		response["username"] = email
+	}
+	h.respond(w, response, http.StatusOK)
+}
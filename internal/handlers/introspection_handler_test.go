@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestIntrospectionHandler_Introspect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	introspection := services.NewMockIntrospectionServiceInterface(ctrl)
+	handler := NewIntrospectionHandler(introspection, zap.NewNop().Sugar())
+	basic := func(clientID, secret string) string {
+		req, _ := http.NewRequest(http.MethodPost, "/", nil)
+		req.SetBasicAuth(clientID, secret)
+		return req.Header.Get("Authorization")
+	}
+
+	introspection.EXPECT().AuthenticateClient("billing", "wrong").Return(false)
+	response := handlertest.NewRequest(t, http.MethodPost, "/auth/introspect").
+		Header("Authorization", basic("billing", "wrong")).
+		Form(url.Values{"token": {"a.b.c"}}).
+		Serve(handler.Introspect).
+		AssertStatus(http.StatusUnauthorized).
+		AssertErrorCode("UNAUTHORIZED_ERR")
+	assert.Equal(t, `Basic realm="introspect"`, response.Header().Get("WWW-Authenticate"))
+
+	introspection.EXPECT().AuthenticateClient("billing", "s3cret").Return(true).Times(3)
+	handlertest.NewRequest(t, http.MethodPost, "/auth/introspect").
+		Form(url.Values{"client_id": {"billing"}, "client_secret": {"s3cret"}}).
+		Serve(handler.Introspect).
+		AssertStatus(http.StatusBadRequest)
+
+	introspection.EXPECT().Introspect(gomock.Any(), "expired").Return(nil, nil)
+	handlertest.NewRequest(t, http.MethodPost, "/auth/introspect").
+		Header("Authorization", basic("billing", "s3cret")).
+		Form(url.Values{"token": {"expired"}}).
+		Serve(handler.Introspect).
+		AssertStatus(http.StatusOK).
+		AssertGolden("introspection_handler/inactive")
+
+	introspection.EXPECT().Introspect(gomock.Any(), "a.b.c").Return(map[string]interface{}{
+		"email": "alice@example.com", "role": "user", "user_id": float64(12), "iat": float64(1709294400), "exp": float64(1709380800),
+	}, nil)
+	response = handlertest.NewRequest(t, http.MethodPost, "/auth/introspect").
+		Header("Authorization", basic("billing", "s3cret")).
+		Form(url.Values{"token": {"a.b.c"}}).
+		Serve(handler.Introspect).
+		AssertStatus(http.StatusOK).
+		AssertGolden("introspection_handler/active")
+	assert.Equal(t, "no-store", response.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type invitationsHandler struct {
+	*BaseHandler
+	invitations services.InvitationServiceInterface
+	logger      *zap.SugaredLogger
+	validator   *validator.Validate
+}
+
+func NewInvitationsHandler(invitations services.InvitationServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *invitationsHandler {
+	return &invitationsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		invitations: invitations,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+type InviteRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// AcceptInvitationRequest is the profile of the invited user; the email and role come from the invitation
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" validate:"required"`
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name" validate:"required"`
+	Password  string `json:"password" validate:"required,min=8,password"`
+	Timezone  string `json:"timezone" validate:"omitempty,timezone"`
+	Locale    string `json:"locale" validate:"omitempty,locale"`
+}
+
+// Invite mails an invitation to sign up with the given role
+func (h *invitationsHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+
+	inviteRequest := &InviteRequest{}
+	if err := h.decode(r, inviteRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, inviteRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := h.invitations.Invite(r.Context(), inviteRequest.Email, inviteRequest.Role, userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, invitation, http.StatusCreated)
+}
+
+// ListInvitations returns the pending invitations, expired ones included so they can be resent
+func (h *invitationsHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	invitations, err := h.invitations.ListPendingInvitations(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, invitations, http.StatusOK)
+}
+
+// ResendInvitation mails a fresh token for the invitation in the path; the previous one stops working
+func (h *invitationsHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := h.invitations.ResendInvitation(r.Context(), uint(id))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, invitation, http.StatusOK)
+}
+
+// RevokeInvitation deletes the pending invitation in the path
+func (h *invitationsHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.invitations.RevokeInvitation(r.Context(), uint(id)); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// AcceptInvitation creates the invited user with the password of the request; it needs no Bearer token, the
+// invitation token stands in for it
+func (h *invitationsHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	type AcceptInvitationResponse struct {
+		UserID string `json:"user_id"`
+	}
+
+	acceptRequest := &AcceptInvitationRequest{}
+	if err := h.decode(r, acceptRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, acceptRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	hash, err := passwords.HashPassword(acceptRequest.Password)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	userID, err := h.invitations.AcceptInvitation(r.Context(), acceptRequest.Token, &models.User{
+		FirstName: acceptRequest.FirstName,
+		LastName:  acceptRequest.LastName,
+		Password:  hash,
+		Timezone:  acceptRequest.Timezone,
+		Locale:    acceptRequest.Locale,
+	})
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, &AcceptInvitationResponse{UserID: strconv.Itoa(int(userID))}, http.StatusCreated)
+}
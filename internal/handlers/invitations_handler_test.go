@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestInvitationsHandler(t *testing.T) {
+	admin := handlertest.Admin
+	invitation := &models.Invitation{
+		ID:        4,
+		Email:     "ann@example.com",
+		Role:      models.Role{ID: 2, Name: models.StrModerator},
+		InvitedBy: admin.ID,
+		ExpiresAt: goldenTime.AddDate(0, 0, 3),
+		SentAt:    goldenTime,
+		CreatedAt: goldenTime,
+	}
+	acceptance := map[string]string{"token": "t0ken", "first_name": "Ann", "last_name": "Lee", "password": "Password@123"}
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *invitationsHandler) http.HandlerFunc
+		expect     func(invitations *services.MockInvitationServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "invite",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/invitations").JSON(map[string]string{"email": "ann@example.com", "role": "moderator"}).As(admin),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.Invite },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().Invite(gomock.Any(), "ann@example.com", models.StrModerator, admin.ID).Return(invitation, nil)
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "invitations_handler/invite",
+		},
+		{
+			name:       "invite with an unknown role",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/invitations").JSON(map[string]string{"email": "ann@example.com", "role": "owner"}).As(admin),
+			serve:      func(h *invitationsHandler) http.HandlerFunc { return h.Invite },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:       "invite as a user",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/invitations").JSON(map[string]string{"email": "ann@example.com", "role": "admin"}).As(handlertest.User),
+			serve:      func(h *invitationsHandler) http.HandlerFunc { return h.Invite },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "invite a pending email",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/invitations").JSON(map[string]string{"email": "ann@example.com", "role": "user"}).As(admin),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.Invite },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().Invite(gomock.Any(), "ann@example.com", models.StrUser, admin.ID).Return(nil, apperrors.InvitationPendingErr.AppendMessage("ann@example.com"))
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.InvitationPendingErr.Code,
+		},
+		{
+			name:    "list",
+			request: handlertest.NewRequest(t, http.MethodGet, "/admin/invitations").As(admin),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.ListInvitations },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().ListPendingInvitations(gomock.Any()).Return([]models.Invitation{*invitation}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "resend",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/invitations/4/resend").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.ResendInvitation },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().ResendInvitation(gomock.Any(), uint(4)).Return(invitation, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "revoke an accepted invitation",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/invitations/4").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.RevokeInvitation },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().RevokeInvitation(gomock.Any(), uint(4)).Return(apperrors.NoRecordFoundErr.AppendMessage("Invitation not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
+		{
+			name:    "revoke",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/invitations/4").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.RevokeInvitation },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().RevokeInvitation(gomock.Any(), uint(4)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "accept",
+			request: handlertest.NewRequest(t, http.MethodPost, "/invitations/accept").JSON(acceptance),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.AcceptInvitation },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().AcceptInvitation(gomock.Any(), "t0ken", gomock.Any()).Return(uint(9), nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:    "accept an expired invitation",
+			request: handlertest.NewRequest(t, http.MethodPost, "/invitations/accept").JSON(acceptance),
+			serve:   func(h *invitationsHandler) http.HandlerFunc { return h.AcceptInvitation },
+			expect: func(invitations *services.MockInvitationServiceInterface) {
+				invitations.EXPECT().AcceptInvitation(gomock.Any(), "t0ken", gomock.Any()).Return(uint(0), &apperrors.InvitationInvalidErr)
+			},
+			wantStatus: http.StatusGone,
+			wantCode:   apperrors.InvitationInvalidErr.Code,
+		},
+		{
+			name:       "accept with a weak password",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/invitations/accept").JSON(map[string]string{"token": "t0ken", "first_name": "Ann", "last_name": "Lee", "password": "short"}),
+			serve:      func(h *invitationsHandler) http.HandlerFunc { return h.AcceptInvitation },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			invitations := services.NewMockInvitationServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(invitations)
+			}
+			handler := NewInvitationsHandler(invitations, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
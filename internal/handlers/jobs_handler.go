@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/jobs"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultJobsLimit = 50
+	maxJobsLimit     = 500
+)
+
+type jobsHandler struct {
+	*BaseHandler
+	dashboard jobs.Dashboard
+	logger    *zap.SugaredLogger
+	cfg       *config.Config
+}
+
+func NewJobsHandler(dashboard jobs.Dashboard, logger *zap.SugaredLogger, cfg *config.Config) *jobsHandler {
+	return &jobsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		dashboard:   dashboard,
+		logger:      logger,
+		cfg:         cfg,
+	}
+}
+
+type ListJobsResponse struct {
+	Stats models.JobStats `json:"stats"`
+	Jobs  []models.Job    `json:"jobs"`
+}
+
+// ListJobs returns the number of jobs in each state and the latest jobs, filtered by ?state= and capped by ?limit=
+func (h *jobsHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state != "" && !isJobState(state) {
+		h.sendError(w, r, errors.New("unknown job state "+strconv.Quote(state)), http.StatusBadRequest)
+		return
+	}
+	limit := defaultJobsLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxJobsLimit {
+			h.sendError(w, r, errors.New("limit should be in the range from 1 to "+strconv.Itoa(maxJobsLimit)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := h.dashboard.Stats(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	list, err := h.dashboard.ListJobs(r.Context(), state, limit)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, &ListJobsResponse{Stats: stats, Jobs: list}, http.StatusOK)
+}
+
+// RetryJob puts a dead job back in the queue with a fresh set of attempts
+func (h *jobsHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	err = h.dashboard.RetryJob(r.Context(), id)
+	if errors.Is(err, &apperrors.NoRecordFoundErr) {
+		h.sendError(w, r, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+func isJobState(state string) bool {
+	for _, known := range models.JobStates {
+		if state == known {
+			return true
+		}
+	}
+	return false
+}
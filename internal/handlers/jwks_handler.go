@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"go.uber.org/zap"
+)
+
+type jwksHandler struct {
+	*BaseHandler
+	keys   *auth.Keys
+	logger *zap.SugaredLogger
+}
+
+func NewJWKSHandler(keys *auth.Keys, logger *zap.SugaredLogger) *jwksHandler {
+	return &jwksHandler{
+		BaseHandler: NewBaseHandler(logger),
+		keys:        keys,
+		logger:      logger,
+	}
+}
+
+// JWKS returns the public key tokens are signed with, for resource servers to verify them; with HS256 the set is
+// empty
+func (h *jwksHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	h.respond(w, h.keys.JWKS(), http.StatusOK)
+}
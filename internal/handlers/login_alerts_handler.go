@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type loginAlertsHandler struct {
+	*BaseHandler
+	loginAlerts services.LoginAlertServiceInterface
+	logger      *zap.SugaredLogger
+	validator   *validator.Validate
+}
+
+func NewLoginAlertsHandler(loginAlerts services.LoginAlertServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *loginAlertsHandler {
+	return &loginAlertsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		loginAlerts: loginAlerts,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+type DenyLoginRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Deny locks the account a login alert was mailed for; the token in the body is all it needs, as the user may
+// not be able to sign in any more
+func (h *loginAlertsHandler) Deny(w http.ResponseWriter, r *http.Request) {
+	denyRequest := &DenyLoginRequest{}
+	if err := h.decode(r, denyRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, denyRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.loginAlerts.Deny(r.Context(), denyRequest.Token); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// Unlock lets the user in the path sign in again, with the reason in X-Audit-Reason recorded in the admin audit
+func (h *loginAlertsHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	actorID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	by, err := adminAction(r, actorID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.loginAlerts.Unlock(r.Context(), by, uint(userID)); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestLoginAlertsHandler(t *testing.T) {
+	admin, user := handlertest.Admin, handlertest.User
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *loginAlertsHandler) http.HandlerFunc
+		expect     func(loginAlerts *services.MockLoginAlertServiceInterface)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:    "deny",
+			request: handlertest.NewRequest(t, http.MethodPost, "/login-alerts/deny").JSON(map[string]string{"token": "abc"}),
+			serve:   func(h *loginAlertsHandler) http.HandlerFunc { return h.Deny },
+			expect: func(loginAlerts *services.MockLoginAlertServiceInterface) {
+				loginAlerts.EXPECT().Deny(gomock.Any(), "abc").Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "deny with a used token",
+			request: handlertest.NewRequest(t, http.MethodPost, "/login-alerts/deny").JSON(map[string]string{"token": "abc"}),
+			serve:   func(h *loginAlertsHandler) http.HandlerFunc { return h.Deny },
+			expect: func(loginAlerts *services.MockLoginAlertServiceInterface) {
+				loginAlerts.EXPECT().Deny(gomock.Any(), "abc").Return(&apperrors.LoginAlertInvalidErr)
+			},
+			wantStatus: http.StatusGone,
+			wantCode:   apperrors.LoginAlertInvalidErr.Code,
+		},
+		{
+			name:       "deny without a token",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/login-alerts/deny").JSON(map[string]string{}),
+			serve:      func(h *loginAlertsHandler) http.HandlerFunc { return h.Deny },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:    "unlock",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/users/1/lock").Vars(map[string]string{"id": "1"}).Header("X-Audit-Reason", "confirmed by phone").As(admin),
+			serve:   func(h *loginAlertsHandler) http.HandlerFunc { return h.Unlock },
+			expect: func(loginAlerts *services.MockLoginAlertServiceInterface) {
+				loginAlerts.EXPECT().Unlock(gomock.Any(), &models.AdminAction{ActorID: admin.ID, Reason: "confirmed by phone"}, uint(1)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "unlock a user who is not locked",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/users/2/lock").Vars(map[string]string{"id": "2"}).As(admin),
+			serve:   func(h *loginAlertsHandler) http.HandlerFunc { return h.Unlock },
+			expect: func(loginAlerts *services.MockLoginAlertServiceInterface) {
+				loginAlerts.EXPECT().Unlock(gomock.Any(), &models.AdminAction{ActorID: admin.ID}, uint(2)).Return(repositories.ErrNotFound.AppendMessage("Account lock not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
+		{
+			name:       "unlock as a user",
+			request:    handlertest.NewRequest(t, http.MethodDelete, "/admin/users/1/lock").Vars(map[string]string{"id": "1"}).As(user),
+			serve:      func(h *loginAlertsHandler) http.HandlerFunc { return h.Unlock },
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(loginAlerts)
+			}
+			handler := NewLoginAlertsHandler(loginAlerts, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+		})
+	}
+}
@@ -1,27 +1,65 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
 	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
 	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
 	"go.uber.org/zap"
 )
 
 type loginHandler struct {
 	*BaseHandler
 	userService services.UserServiceInterface
-	logger      *zap.SugaredLogger
-	cfg         *config.Config
+	identities  services.IdentityServiceInterface
+	activity    services.ActivityServiceInterface
+	security    services.SecurityServiceInterface
+	// securityEvents records the logins a user sees under /me/security-events
+	securityEvents services.SecurityEventServiceInterface
+	// loginAlerts keeps locked users out and alerts users of logins from new devices with LOGIN_ALERTS_ENABLED
+	loginAlerts services.LoginAlertServiceInterface
+	// bans keeps banned users out until their ban expires
+	bans services.BanServiceInterface
+	// phones asks users with an SMS second factor for the code texted to them, and signs users in by phone
+	phones services.PhoneServiceInterface
+	// remember keeps users who asked to be remembered signed in on their device for REMEMBER_ME_TTL
+	remember services.RememberServiceInterface
+	tokens   services.TokenServiceInterface
+	// throttle is nil with LOGIN_THROTTLE_ATTEMPTS=0
+	throttle ratelimit.Throttle
+	// trustedProxies are the peers whose X-Forwarded-For names the client
+	trustedProxies []*net.IPNet
+	logger         *zap.SugaredLogger
+	cfg            *config.Config
 }
 
-func NewLoginHandler(userService services.UserServiceInterface, logger *zap.SugaredLogger, cfg *config.Config) *loginHandler {
+func NewLoginHandler(userService services.UserServiceInterface, identities services.IdentityServiceInterface, activity services.ActivityServiceInterface, security services.SecurityServiceInterface, securityEvents services.SecurityEventServiceInterface, loginAlerts services.LoginAlertServiceInterface, bans services.BanServiceInterface, phones services.PhoneServiceInterface, remember services.RememberServiceInterface, tokens services.TokenServiceInterface, throttle ratelimit.Throttle, trustedProxies []*net.IPNet, logger *zap.SugaredLogger, cfg *config.Config) *loginHandler {
 	return &loginHandler{
-		BaseHandler: NewBaseHandler(logger),
-		userService: userService,
-		logger:      logger,
-		cfg:         cfg,
+		BaseHandler:    NewBaseHandler(logger),
+		userService:    userService,
+		identities:     identities,
+		activity:       activity,
+		security:       security,
+		securityEvents: securityEvents,
+		loginAlerts:    loginAlerts,
+		bans:           bans,
+		phones:         phones,
+		remember:       remember,
+		tokens:         tokens,
+		throttle:       throttle,
+		trustedProxies: trustedProxies,
+		logger:         logger,
+		cfg:            cfg,
 	}
 }
 
@@ -29,16 +67,307 @@ func (h *loginHandler) Login(w http.ResponseWriter, r *http.Request) {
 	email := r.FormValue("email")
 	password := r.FormValue("password")
 
+	throttleKey := loginThrottleKey(r.Context(), email)
+	if h.throttled(w, r, throttleKey) {
+		return
+	}
+
 	user, err := h.userService.GetUserByEmail(r.Context(), email)
-	if err != nil {
-		h.sendError(w, err, http.StatusInternalServerError)
+	if err != nil && !errors.Is(err, &apperrors.NoRecordFoundErr) {
+		h.sendError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
 	err = auth.Access(email, password, user)
 	if err != nil {
+		h.recordAttempt(r.Context(), throttleKey, false)
+		h.recordFailure(r, email)
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
-	w.Write(auth.GenerateTokenHandler(email, user.Role.Name, user.ID, []byte(h.cfg.JwtKey)))
+	h.recordAttempt(r.Context(), throttleKey, true)
+
+	// A user who unlinked their password signs in with the providers they kept
+	allowed, err := h.identities.PasswordLogin(r.Context(), user)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "password sign-in is unlinked from this account", http.StatusUnauthorized)
+		return
+	}
+	if !h.admit(w, r, user) {
+		return
+	}
+
+	challenge, required, err := h.phones.StartSecondFactor(r.Context(), user)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if required {
+		h.respond(w, &SecondFactorResponse{SecondFactor: "sms", Challenge: challenge}, http.StatusAccepted)
+		return
+	}
+	h.signIn(w, r, user, "")
+}
+
+// SecondFactorResponse answers a password login that needs the code texted to the user; the code goes to
+// POST /login/second-factor with the challenge
+type SecondFactorResponse struct {
+	SecondFactor string `json:"second_factor"`
+	Challenge    string `json:"challenge"`
+}
+
+// SecondFactor completes a password login with the challenge it answered with and the code texted to the user
+func (h *loginHandler) SecondFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.phones.CompleteSecondFactor(r.Context(), r.FormValue("challenge"), r.FormValue("code"))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	user, err := h.userService.GetUser(r.Context(), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	// The user may have been locked or banned since the password was checked
+	if !h.admit(w, r, user) {
+		return
+	}
+	h.signIn(w, r, user, "")
+}
+
+// RequestPhoneCode texts a login code to the verified phone with the number in the form, if any. The answer is 202
+// whatever happened, over the send limits and on failures too, so it does not tell which numbers have accounts.
+func (h *loginHandler) RequestPhoneCode(w http.ResponseWriter, r *http.Request) {
+	number := r.FormValue("phone")
+	if number == "" {
+		h.sendError(w, r, errors.New("phone is required"), http.StatusBadRequest)
+		return
+	}
+
+	h.phones.StartLogin(r.Context(), number)
+	h.respond(w, nil, http.StatusAccepted)
+}
+
+// PhoneLogin signs in the user who verified the phone in the form with the login code texted to it. Wrong codes
+// count towards the login throttle like wrong passwords.
+func (h *loginHandler) PhoneLogin(w http.ResponseWriter, r *http.Request) {
+	number := r.FormValue("phone")
+
+	throttleKey := phoneLoginThrottleKey(r.Context(), number)
+	if h.throttled(w, r, throttleKey) {
+		return
+	}
+
+	userID, err := h.phones.CompleteLogin(r.Context(), number, r.FormValue("code"))
+	if err != nil {
+		if apperrors.Is(err, &apperrors.PhoneCodeInvalidErr) {
+			h.recordAttempt(r.Context(), throttleKey, false)
+		}
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.recordAttempt(r.Context(), throttleKey, true)
+
+	user, err := h.userService.GetUser(r.Context(), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if !h.admit(w, r, user) {
+		return
+	}
+	h.signIn(w, r, user, "phone")
+}
+
+// rememberCookie holds the remember-me token. Only the remember-me endpoints get it, and never from other sites.
+const rememberCookie = "remember_me"
+
+// RememberLogin signs in the user remembered on this device with the remember-me cookie, which it replaces with the
+// next token of the series. A used token, or one from another device, revokes its series as stolen.
+func (h *loginHandler) RememberLogin(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(rememberCookie)
+	if err != nil {
+		h.sendError(w, r, &apperrors.RememberTokenInvalidErr, http.StatusUnauthorized)
+		return
+	}
+
+	userID, next, err := h.remember.Use(r.Context(), cookie.Value)
+	if err != nil {
+		if apperrors.Is(err, &apperrors.RememberTokenInvalidErr) {
+			h.clearRememberCookie(w)
+		}
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	// The used token is no good any more, whatever happens next
+	h.setRememberCookie(w, next)
+
+	user, err := h.userService.GetUser(r.Context(), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if !h.admit(w, r, user) {
+		return
+	}
+	h.signIn(w, r, user, "remember_me")
+}
+
+// Forget stops remembering the user on this device
+func (h *loginHandler) Forget(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(rememberCookie); err == nil {
+		if err := h.remember.Forget(r.Context(), cookie.Value); err != nil {
+			h.sendError(w, r, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	h.clearRememberCookie(w)
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// ForgetDevices stops remembering the caller on every device
+func (h *loginHandler) ForgetDevices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.remember.ForgetAll(r.Context(), userID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+func (h *loginHandler) setRememberCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     rememberCookie,
+		Value:    token,
+		Path:     "/login/remember",
+		MaxAge:   int(h.cfg.RememberMeTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func (h *loginHandler) clearRememberCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     rememberCookie,
+		Path:     "/login/remember",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// admit answers 403 for users who may not sign in at all, whatever their credentials
+func (h *loginHandler) admit(w http.ResponseWriter, r *http.Request, user *models.User) bool {
+	if user.ServiceAccount {
+		h.sendError(w, r, &apperrors.ServiceAccountLoginErr, http.StatusForbidden)
+		return false
+	}
+	// A user who answered a login alert with "this wasn't me" or was suspended stays out until an admin unlocks
+	// them, a banned user until the ban ends
+	if err := services.CheckStanding(r.Context(), h.loginAlerts, h.bans, user.ID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// signIn answers with a token for the user, whose credentials are all checked, and remembers the user on their
+// device when the form has remember_me=true. The login security event gets details, which name the credential unless
+// it was the password.
+func (h *loginHandler) signIn(w http.ResponseWriter, r *http.Request, user *models.User, details string) {
+	// A missed login only brings the inactivity warning closer, it never blocks signing in
+	err := h.activity.RecordLogin(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Errorw("Failed to record login", "user_id", user.ID, "error", err)
+	}
+	token, err := h.tokens.Issue(r.Context(), user)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	// A user who asked to be remembered still gets the token, remembered or not
+	if r.FormValue("remember_me") == "true" {
+		remembered, err := h.remember.Issue(r.Context(), user.ID)
+		if err != nil {
+			h.logger.Errorw("Failed to remember the user", "user_id", user.ID, "error", err)
+		} else {
+			h.setRememberCookie(w, remembered)
+		}
+	}
+	h.securityEvents.Record(r.Context(), user.ID, models.SecurityEventLogin, details)
+	// The user signed in either way, an alert that could not be sent is only logged
+	if h.cfg.LoginAlertsEnabled {
+		if err := h.loginAlerts.CheckLogin(r.Context(), user); err != nil {
+			h.logger.Errorw("Failed to check the login for a new device", "user_id", user.ID, "error", err)
+		}
+	}
+	w.Write([]byte(token))
+}
+
+// loginThrottleKey names the account a login is for, whether or not it exists, so the throttle does not tell
+// which emails have accounts. The blind index keeps the email out of Redis.
+func loginThrottleKey(ctx context.Context, email string) string {
+	return tenancy.KeyPrefix(ctx) + models.EmailBlindIndex(email)
+}
+
+// phoneLoginThrottleKey names the phone a login is for, like loginThrottleKey the email
+func phoneLoginThrottleKey(ctx context.Context, number string) string {
+	return tenancy.KeyPrefix(ctx) + "phone:" + models.PhoneNumberBlindIndex(number)
+}
+
+// throttled answers 429 with Retry-After while the account has to wait after failed logins. A failing throttle
+// is logged and the login served, so Redis can not lock everybody out.
+func (h *loginHandler) throttled(w http.ResponseWriter, r *http.Request, key string) bool {
+	if h.throttle == nil {
+		return false
+	}
+	wait, err := h.throttle.Wait(r.Context(), key)
+	if err != nil {
+		h.logger.Errorw("Failed to check the login throttle", "error", err)
+		return false
+	}
+	if wait <= 0 {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	h.sendError(w, r, apperrors.TooManyRequestsErr.AppendMessage("too many failed logins for this account"), http.StatusTooManyRequests)
+	return true
+}
+
+// recordAttempt counts a failed login towards the throttle, or clears the failures once the password is right
+func (h *loginHandler) recordAttempt(ctx context.Context, key string, succeeded bool) {
+	if h.throttle == nil {
+		return
+	}
+	var err error
+	if succeeded {
+		err = h.throttle.Reset(ctx, key)
+	} else {
+		err = h.throttle.Fail(ctx, key)
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to record a login attempt", "error", err)
+	}
+}
+
+// recordFailure hands a failed login to brute-force detection; failing to count it does not change the answer
+func (h *loginHandler) recordFailure(r *http.Request, email string) {
+	err := h.security.RecordFailedLogin(r.Context(), &models.FailedLogin{
+		IP:        ratelimit.ClientIP(r, h.trustedProxies),
+		Email:     email,
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		h.logger.Errorw("Failed to record a failed login", "error", err)
+	}
 }
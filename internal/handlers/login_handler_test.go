@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestLoginHandler(t *testing.T) {
+	hash, err := passwords.HashPassword("password@123")
+	require.NoError(t, err)
+	user := &models.User{ID: 5, Email: "john@example.com", Password: hash, Role: models.Role{Name: models.StrModerator}}
+
+	tests := []struct {
+		name     string
+		password string
+		found    *models.User
+		err      error
+		// unlinked is whether the user unlinked their password identity
+		unlinked bool
+		// locked is whether the user denied a login alert
+		locked bool
+		// ban is the user's ban in force, if any
+		ban *models.Ban
+		// secondFactor is whether the user's logins need a code texted to them
+		secondFactor bool
+		wantStatus   int
+		wantCode     string
+	}{
+		{name: "valid credentials", password: "password@123", found: user, wantStatus: http.StatusOK},
+		{name: "wrong password", password: "password@124", found: user, wantStatus: http.StatusUnauthorized},
+		{name: "password unlinked", password: "password@123", found: user, unlinked: true, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "account locked",
+			password:   "password@123",
+			found:      user,
+			locked:     true,
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperrors.AccountLockedErr.Code,
+		},
+		{
+			name:       "banned",
+			password:   "password@123",
+			found:      user,
+			ban:        &models.Ban{UserID: user.ID, Reason: "Spam", ExpiresAt: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)},
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperrors.UserBannedErr.Code,
+		},
+		{name: "second factor", password: "password@123", found: user, secondFactor: true, wantStatus: http.StatusAccepted},
+		{name: "unknown email", password: "password@123", err: &apperrors.NoRecordFoundErr, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "lookup fails",
+			password:   "password@123",
+			err:        apperrors.QueryFailedErr.AppendMessage(errors.New("connection refused")),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   apperrors.QueryFailedErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			userService := services.NewMockUserServiceInterface(ctrl)
+			userService.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(tt.found, tt.err)
+			identities := services.NewMockIdentityServiceInterface(ctrl)
+			identities.EXPECT().PasswordLogin(gomock.Any(), user).Return(!tt.unlinked, nil).AnyTimes()
+			activity := services.NewMockActivityServiceInterface(ctrl)
+			if tt.wantStatus == http.StatusOK {
+				activity.EXPECT().RecordLogin(gomock.Any(), user.ID).Return(nil)
+			}
+			security := services.NewMockSecurityServiceInterface(ctrl)
+			if tt.wantStatus == http.StatusUnauthorized && !tt.unlinked {
+				security.EXPECT().RecordFailedLogin(gomock.Any(), &models.FailedLogin{IP: "192.0.2.1", Email: user.Email, UserAgent: "login-test"}).Return(nil)
+			}
+			securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+			if tt.wantStatus == http.StatusOK {
+				securityEvents.EXPECT().Record(gomock.Any(), user.ID, models.SecurityEventLogin, "")
+			}
+			loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+			loginAlerts.EXPECT().Locked(gomock.Any(), user.ID).Return(tt.locked, nil).AnyTimes()
+			if tt.wantStatus == http.StatusOK {
+				loginAlerts.EXPECT().CheckLogin(gomock.Any(), user).Return(nil)
+			}
+			bans := services.NewMockBanServiceInterface(ctrl)
+			bans.EXPECT().ActiveBan(gomock.Any(), user.ID).Return(tt.ban, nil).AnyTimes()
+			phones := services.NewMockPhoneServiceInterface(ctrl)
+			if tt.secondFactor {
+				phones.EXPECT().StartSecondFactor(gomock.Any(), user).Return("challenge", true, nil)
+			} else {
+				phones.EXPECT().StartSecondFactor(gomock.Any(), user).Return("", false, nil).AnyTimes()
+			}
+			handler := NewLoginHandler(userService, identities, activity, security, securityEvents, loginAlerts, bans, phones, nil, services.NewTokenService(nil, nil, auth.NewHMACKeys([]byte(handlertest.JwtKey)), zap.NewNop().Sugar()), nil, nil, zap.NewNop().Sugar(), &config.Config{JwtKey: handlertest.JwtKey, LoginAlertsEnabled: true})
+
+			response := handlertest.NewRequest(t, http.MethodPost, "/login").
+				Form(url.Values{"email": {user.Email}, "password": {tt.password}}).
+				Header("User-Agent", "login-test").
+				Serve(handler.Login).
+				AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.ban != nil {
+				assert.Contains(t, response.Body.String(), "Spam, until 2030-01-01T00:00:00Z", "banned users are told why and until when")
+			}
+			if tt.secondFactor {
+				assert.JSONEq(t, `{"second_factor":"sms","challenge":"challenge"}`, response.Body.String(), "no token before the code")
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			claims, err := auth.Parse(response.Body.String(), []byte(handlertest.JwtKey))
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, claims.ID)
+			assert.Equal(t, user.Email, claims.Email)
+			assert.Equal(t, models.StrModerator, claims.Role)
+		})
+	}
+}
+
+// fakeThrottle makes every key wait once it has failed attempts times
+type fakeThrottle struct {
+	attempts int
+	failures map[string]int
+}
+
+func (f *fakeThrottle) Wait(_ context.Context, key string) (time.Duration, error) {
+	if f.failures[key] >= f.attempts {
+		return 90 * time.Second, nil
+	}
+	return 0, nil
+}
+
+func (f *fakeThrottle) Fail(_ context.Context, key string) error {
+	f.failures[key]++
+	return nil
+}
+
+func (f *fakeThrottle) Reset(_ context.Context, key string) error {
+	delete(f.failures, key)
+	return nil
+}
+
+func TestLoginHandler_ThrottlesFailedLoginsPerAccount(t *testing.T) {
+	hash, err := passwords.HashPassword("password@123")
+	require.NoError(t, err)
+	user := &models.User{ID: 5, Email: "john@example.com", Password: hash, Role: models.Role{Name: models.StrUser}}
+
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	userService.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Return(user, nil).AnyTimes()
+	identities := services.NewMockIdentityServiceInterface(ctrl)
+	identities.EXPECT().PasswordLogin(gomock.Any(), user).Return(true, nil).AnyTimes()
+	activity := services.NewMockActivityServiceInterface(ctrl)
+	activity.EXPECT().RecordLogin(gomock.Any(), user.ID).Return(nil).AnyTimes()
+	security := services.NewMockSecurityServiceInterface(ctrl)
+	security.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+	securityEvents.EXPECT().Record(gomock.Any(), user.ID, models.SecurityEventLogin, "").AnyTimes()
+	loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+	loginAlerts.EXPECT().Locked(gomock.Any(), user.ID).Return(false, nil).AnyTimes()
+	bans := services.NewMockBanServiceInterface(ctrl)
+	bans.EXPECT().ActiveBan(gomock.Any(), user.ID).Return(nil, nil).AnyTimes()
+	phones := services.NewMockPhoneServiceInterface(ctrl)
+	phones.EXPECT().StartSecondFactor(gomock.Any(), user).Return("", false, nil).AnyTimes()
+	throttle := &fakeThrottle{attempts: 2, failures: map[string]int{}}
+	handler := NewLoginHandler(userService, identities, activity, security, securityEvents, loginAlerts, bans, phones, nil, services.NewTokenService(nil, nil, auth.NewHMACKeys([]byte(handlertest.JwtKey)), zap.NewNop().Sugar()), throttle, nil, zap.NewNop().Sugar(), &config.Config{JwtKey: handlertest.JwtKey})
+
+	login := func(email, password string) *handlertest.Response {
+		return handlertest.NewRequest(t, http.MethodPost, "/login").
+			Form(url.Values{"email": {email}, "password": {password}}).
+			Serve(handler.Login)
+	}
+
+	login("john@example.com", "password@124").AssertStatus(http.StatusUnauthorized)
+	// The same account in another case
+	login("John@Example.com", "password@124").AssertStatus(http.StatusUnauthorized)
+
+	response := login("john@example.com", "password@123").
+		AssertStatus(http.StatusTooManyRequests).
+		AssertErrorCode(apperrors.TooManyRequestsErr.Code)
+	assert.Equal(t, "90", response.Header().Get("Retry-After"), "even the right password waits")
+
+	throttle.failures = map[string]int{}
+	login("john@example.com", "password@124").AssertStatus(http.StatusUnauthorized)
+	login("john@example.com", "password@123").AssertStatus(http.StatusOK)
+	assert.Empty(t, throttle.failures, "a login clears the failures")
+}
+
+func TestLoginHandler_SecondFactor(t *testing.T) {
+	user := &models.User{ID: 5, Email: "john@example.com", Role: models.Role{Name: models.StrUser}}
+
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	activity := services.NewMockActivityServiceInterface(ctrl)
+	securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+	loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+	loginAlerts.EXPECT().Locked(gomock.Any(), user.ID).Return(false, nil).AnyTimes()
+	bans := services.NewMockBanServiceInterface(ctrl)
+	bans.EXPECT().ActiveBan(gomock.Any(), user.ID).Return(nil, nil).AnyTimes()
+	phones := services.NewMockPhoneServiceInterface(ctrl)
+	handler := NewLoginHandler(userService, nil, activity, nil, securityEvents, loginAlerts, bans, phones, nil, services.NewTokenService(nil, nil, auth.NewHMACKeys([]byte(handlertest.JwtKey)), zap.NewNop().Sugar()), nil, nil, zap.NewNop().Sugar(), &config.Config{JwtKey: handlertest.JwtKey})
+
+	complete := func(code string) *handlertest.Response {
+		return handlertest.NewRequest(t, http.MethodPost, "/login/second-factor").
+			Form(url.Values{"challenge": {"challenge"}, "code": {code}}).
+			Serve(handler.SecondFactor)
+	}
+
+	phones.EXPECT().CompleteSecondFactor(gomock.Any(), "challenge", "654321").Return(uint(0), &apperrors.PhoneCodeInvalidErr)
+	complete("654321").AssertStatus(http.StatusBadRequest).AssertErrorCode(apperrors.PhoneCodeInvalidErr.Code)
+
+	phones.EXPECT().CompleteSecondFactor(gomock.Any(), "challenge", "123456").Return(user.ID, nil)
+	userService.EXPECT().GetUser(gomock.Any(), "5").Return(user, nil)
+	activity.EXPECT().RecordLogin(gomock.Any(), user.ID).Return(nil)
+	securityEvents.EXPECT().Record(gomock.Any(), user.ID, models.SecurityEventLogin, "")
+	response := complete("123456").AssertStatus(http.StatusOK)
+
+	claims, err := auth.Parse(response.Body.String(), []byte(handlertest.JwtKey))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.ID)
+}
+
+func TestLoginHandler_Phone(t *testing.T) {
+	user := &models.User{ID: 5, Email: "john@example.com", Role: models.Role{Name: models.StrUser}}
+
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	activity := services.NewMockActivityServiceInterface(ctrl)
+	securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+	loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+	loginAlerts.EXPECT().Locked(gomock.Any(), user.ID).Return(false, nil).AnyTimes()
+	bans := services.NewMockBanServiceInterface(ctrl)
+	bans.EXPECT().ActiveBan(gomock.Any(), user.ID).Return(nil, nil).AnyTimes()
+	phones := services.NewMockPhoneServiceInterface(ctrl)
+	throttle := &fakeThrottle{attempts: 2, failures: map[string]int{}}
+	handler := NewLoginHandler(userService, nil, activity, nil, securityEvents, loginAlerts, bans, phones, nil, services.NewTokenService(nil, nil, auth.NewHMACKeys([]byte(handlertest.JwtKey)), zap.NewNop().Sugar()), throttle, nil, zap.NewNop().Sugar(), &config.Config{JwtKey: handlertest.JwtKey})
+
+	login := func(code string) *handlertest.Response {
+		return handlertest.NewRequest(t, http.MethodPost, "/login/phone").
+			Form(url.Values{"phone": {"+380501234567"}, "code": {code}}).
+			Serve(handler.PhoneLogin)
+	}
+
+	handlertest.NewRequest(t, http.MethodPost, "/login/phone/code").
+		Serve(handler.RequestPhoneCode).
+		AssertStatus(http.StatusBadRequest)
+	phones.EXPECT().StartLogin(gomock.Any(), "+380501234567")
+	handlertest.NewRequest(t, http.MethodPost, "/login/phone/code").
+		Form(url.Values{"phone": {"+380501234567"}}).
+		Serve(handler.RequestPhoneCode).
+		AssertStatus(http.StatusAccepted)
+
+	phones.EXPECT().CompleteLogin(gomock.Any(), "+380501234567", "654321").Return(uint(0), &apperrors.PhoneCodeInvalidErr).Times(2)
+	login("654321").AssertStatus(http.StatusBadRequest).AssertErrorCode(apperrors.PhoneCodeInvalidErr.Code)
+	login("654321").AssertStatus(http.StatusBadRequest)
+	login("123456").AssertStatus(http.StatusTooManyRequests)
+
+	throttle.failures = map[string]int{}
+	phones.EXPECT().CompleteLogin(gomock.Any(), "+380501234567", "123456").Return(user.ID, nil)
+	userService.EXPECT().GetUser(gomock.Any(), "5").Return(user, nil)
+	activity.EXPECT().RecordLogin(gomock.Any(), user.ID).Return(nil)
+	securityEvents.EXPECT().Record(gomock.Any(), user.ID, models.SecurityEventLogin, "phone")
+	response := login("123456").AssertStatus(http.StatusOK)
+
+	claims, err := auth.Parse(response.Body.String(), []byte(handlertest.JwtKey))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.ID)
+	assert.Equal(t, user.Email, claims.Email, "the token is the one a password login gets")
+
+	// A service account that verified a phone still can not sign in with it
+	phones.EXPECT().CompleteLogin(gomock.Any(), "+380501234567", "123456").Return(uint(40), nil)
+	userService.EXPECT().GetUser(gomock.Any(), "40").Return(&models.User{ID: 40, Email: "billing@example.com", ServiceAccount: true}, nil)
+	login("123456").AssertStatus(http.StatusForbidden).AssertErrorCode(apperrors.ServiceAccountLoginErr.Code)
+}
+
+func TestLoginHandler_Remember(t *testing.T) {
+	user := &models.User{ID: 5, Email: "john@example.com", Role: models.Role{Name: models.StrUser}}
+
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	activity := services.NewMockActivityServiceInterface(ctrl)
+	activity.EXPECT().RecordLogin(gomock.Any(), user.ID).Return(nil).AnyTimes()
+	securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+	loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+	loginAlerts.EXPECT().Locked(gomock.Any(), user.ID).Return(false, nil).AnyTimes()
+	bans := services.NewMockBanServiceInterface(ctrl)
+	bans.EXPECT().ActiveBan(gomock.Any(), user.ID).Return(nil, nil).AnyTimes()
+	phones := services.NewMockPhoneServiceInterface(ctrl)
+	remember := services.NewMockRememberServiceInterface(ctrl)
+	handler := NewLoginHandler(userService, nil, activity, nil, securityEvents, loginAlerts, bans, phones, remember, services.NewTokenService(nil, nil, auth.NewHMACKeys([]byte(handlertest.JwtKey)), zap.NewNop().Sugar()), nil, nil, zap.NewNop().Sugar(), &config.Config{JwtKey: handlertest.JwtKey, RememberMeTTL: 720 * time.Hour})
+
+	cookie := func(response *handlertest.Response) *http.Cookie {
+		for _, cookie := range response.Result().Cookies() {
+			if cookie.Name == rememberCookie {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	// Completing a second factor with remember_me remembers the device
+	phones.EXPECT().CompleteSecondFactor(gomock.Any(), "challenge", "123456").Return(user.ID, nil)
+	userService.EXPECT().GetUser(gomock.Any(), "5").Return(user, nil).Times(2)
+	remember.EXPECT().Issue(gomock.Any(), user.ID).Return("first", nil)
+	securityEvents.EXPECT().Record(gomock.Any(), user.ID, models.SecurityEventLogin, "")
+	response := handlertest.NewRequest(t, http.MethodPost, "/login/second-factor").
+		Form(url.Values{"challenge": {"challenge"}, "code": {"123456"}, "remember_me": {"true"}}).
+		Serve(handler.SecondFactor).
+		AssertStatus(http.StatusOK)
+	issued := cookie(response)
+	require.NotNil(t, issued)
+	assert.Equal(t, "first", issued.Value)
+	assert.Equal(t, "/login/remember", issued.Path)
+	assert.Equal(t, int((720 * time.Hour).Seconds()), issued.MaxAge)
+	assert.True(t, issued.HttpOnly)
+
+	// The cookie signs in and is replaced with the next token
+	remember.EXPECT().Use(gomock.Any(), "first").Return(user.ID, "second", nil)
+	securityEvents.EXPECT().Record(gomock.Any(), user.ID, models.SecurityEventLogin, "remember_me")
+	response = handlertest.NewRequest(t, http.MethodPost, "/login/remember").
+		Header("Cookie", "remember_me=first").
+		Serve(handler.RememberLogin).
+		AssertStatus(http.StatusOK)
+	assert.Equal(t, "second", cookie(response).Value)
+	claims, err := auth.Parse(response.Body.String(), []byte(handlertest.JwtKey))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.ID)
+
+	// A stolen token is refused and its cookie cleared
+	remember.EXPECT().Use(gomock.Any(), "first").Return(uint(0), "", &apperrors.RememberTokenInvalidErr)
+	response = handlertest.NewRequest(t, http.MethodPost, "/login/remember").
+		Header("Cookie", "remember_me=first").
+		Serve(handler.RememberLogin).
+		AssertStatus(http.StatusUnauthorized).
+		AssertErrorCode(apperrors.RememberTokenInvalidErr.Code)
+	assert.Equal(t, -1, cookie(response).MaxAge)
+
+	handlertest.NewRequest(t, http.MethodPost, "/login/remember").
+		Serve(handler.RememberLogin).
+		AssertStatus(http.StatusUnauthorized)
+
+	remember.EXPECT().Forget(gomock.Any(), "second").Return(nil)
+	handlertest.NewRequest(t, http.MethodDelete, "/login/remember").
+		Header("Cookie", "remember_me=second").
+		Serve(handler.Forget).
+		AssertStatus(http.StatusNoContent)
+
+	remember.EXPECT().ForgetAll(gomock.Any(), handlertest.User.ID).Return(nil)
+	handlertest.NewRequest(t, http.MethodDelete, "/me/remember-tokens").
+		As(handlertest.User).
+		Serve(handler.ForgetDevices).
+		AssertStatus(http.StatusNoContent)
+}
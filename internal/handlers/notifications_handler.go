@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+const maxNotificationsPageSize = 100
+
+type notificationsHandler struct {
+	*BaseHandler
+	notificationService services.NotificationServiceInterface
+	logger              *zap.SugaredLogger
+	cfg                 *config.Config
+}
+
+func NewNotificationsHandler(notificationService services.NotificationServiceInterface, logger *zap.SugaredLogger, cfg *config.Config) *notificationsHandler {
+	return &notificationsHandler{
+		BaseHandler:         NewBaseHandler(logger),
+		notificationService: notificationService,
+		logger:              logger,
+		cfg:                 cfg,
+	}
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []uint64 `json:"ids"` // empty marks every notification read
+}
+
+// ListNotifications returns the authenticated user's notifications, newest first; ?unread=true keeps the unread ones
+func (h *notificationsHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, pageSize := defaultPage, defaultPageSize
+	var err error
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			h.sendError(w, r, errors.New("incorrect page number"), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize <= 0 || pageSize > maxNotificationsPageSize {
+			h.sendError(w, r, errors.New("the number of objects on the page should be in the range from 1 to "+strconv.Itoa(maxNotificationsPageSize)), http.StatusBadRequest)
+			return
+		}
+	}
+	unreadOnly, _ := strconv.ParseBool(query.Get("unread"))
+
+	notifications, err := h.notificationService.ListNotifications(r.Context(), userID, unreadOnly, page, pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, notifications, http.StatusOK)
+}
+
+func (h *notificationsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	type MarkNotificationsReadResponse struct {
+		Marked int `json:"marked"`
+	}
+
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	// An empty body marks everything read
+	markRequest := &MarkNotificationsReadRequest{}
+	err := h.decode(r, markRequest)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	marked, err := h.notificationService.MarkRead(r.Context(), userID, markRequest.IDs)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, &MarkNotificationsReadResponse{Marked: marked}, http.StatusOK)
+}
+
+// GetPreferences returns whether each channel (in_app, email, sms) is on for the authenticated user
+func (h *notificationsHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.Preferences(r.Context(), userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, preferences, http.StatusOK)
+}
+
+// SetPreferences switches the channels in the body, e.g. {"email": false}, and leaves the others alone
+func (h *notificationsHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	preferences := map[string]bool{}
+	err := h.decode(r, &preferences)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.notificationService.SetPreferences(r.Context(), userID, preferences)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, updated, http.StatusOK)
+}
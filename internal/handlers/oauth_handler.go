@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type oauthHandler struct {
+	*BaseHandler
+	oauth          services.OAuthServiceInterface
+	securityEvents services.SecurityEventServiceInterface
+	logger         *zap.SugaredLogger
+	validator      *validator.Validate
+}
+
+func NewOAuthHandler(oauth services.OAuthServiceInterface, securityEvents services.SecurityEventServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *oauthHandler {
+	return &oauthHandler{
+		BaseHandler:    NewBaseHandler(logger),
+		oauth:          oauth,
+		securityEvents: securityEvents,
+		logger:         logger,
+		validator:      validator,
+	}
+}
+
+type RegisterClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" validate:"dive,required"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code client_credentials"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,dive,oneof=profile notifications"`
+	// Confidential clients get a secret; public ones, e.g. mobile apps, cannot keep one
+	Confidential bool `json:"confidential"`
+}
+
+// RegisterClientResponse is the registered client with its secret, which is only ever shown here
+type RegisterClientResponse struct {
+	*models.OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizationResponse describes what the client in the query asks the user to approve
+type AuthorizationResponse struct {
+	Client *models.OAuthClient `json:"client"`
+	Scope  string              `json:"scope"`
+	// Consented is true when the user already approved every scope asked for
+	Consented bool `json:"consented"`
+}
+
+type AuthorizeRequest struct {
+	Approve bool `json:"approve"`
+}
+
+// AuthorizeResponse is where to send the user back to the client, with the code or the refusal
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// TokenErrorResponse is the error body of the token endpoint, as clients expect it from RFC 6749
+type TokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// tokenErrors maps the codes of the errors the token endpoint answers with to the RFC 6749 ones
+var tokenErrors = map[string]string{
+	apperrors.OAuthInvalidClientErr.Code:      "invalid_client",
+	apperrors.OAuthInvalidGrantErr.Code:       "invalid_grant",
+	apperrors.OAuthUnauthorizedClientErr.Code: "unauthorized_client",
+	apperrors.OAuthInvalidScopeErr.Code:       "invalid_scope",
+	apperrors.BadRequestErr.Code:              "invalid_request",
+}
+
+// RegisterClient registers a third-party application
+func (h *oauthHandler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+
+	registerRequest := &RegisterClientRequest{}
+	if err := h.decode(r, registerRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, registerRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	client, secret, err := h.oauth.RegisterClient(r.Context(), &models.OAuthClient{
+		Name:         registerRequest.Name,
+		RedirectURIs: registerRequest.RedirectURIs,
+		GrantTypes:   registerRequest.GrantTypes,
+		Scopes:       registerRequest.Scopes,
+		CreatedBy:    userID,
+	}, registerRequest.Confidential)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, RegisterClientResponse{OAuthClient: client, ClientSecret: secret}, http.StatusCreated)
+}
+
+func (h *oauthHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	clients, err := h.oauth.ListClients(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, clients, http.StatusOK)
+}
+
+// DeleteClient deletes the client in the path with the consents users gave it
+func (h *oauthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.oauth.DeleteClient(r.Context(), uint(id)); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// Authorization checks the authorization request in the query for the consent screen of the authenticated user
+func (h *oauthHandler) Authorization(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	request := authorizationRequest(r.URL.Query())
+	client, consent, err := h.oauth.Authorization(r.Context(), userID, request)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, AuthorizationResponse{
+		Client:    client,
+		Scope:     models.JoinScopes(request.Scope),
+		Consented: consent != nil && consent.Covers(request.Scope),
+	}, http.StatusOK)
+}
+
+// Authorize answers the authorization request in the query with the authenticated user's decision and returns
+// the redirect URI of the client with a code, or with error=access_denied when the user refused
+func (h *oauthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	authorizeRequest := &AuthorizeRequest{}
+	if err := h.decode(r, authorizeRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	request := authorizationRequest(r.URL.Query())
+	params := url.Values{}
+	if authorizeRequest.Approve {
+		code, err := h.oauth.Authorize(r.Context(), userID, request)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusInternalServerError)
+			return
+		}
+		params.Set("code", code)
+	} else {
+		// Only a valid request is sent back, so the redirect URI is one the client registered
+		if _, _, err := h.oauth.Authorization(r.Context(), userID, request); err != nil {
+			h.sendError(w, r, err, http.StatusInternalServerError)
+			return
+		}
+		params.Set("error", "access_denied")
+	}
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+
+	redirect, err := url.Parse(request.RedirectURI)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	query := redirect.Query()
+	for name := range params {
+		query.Set(name, params.Get(name))
+	}
+	redirect.RawQuery = query.Encode()
+	h.respond(w, AuthorizeResponse{RedirectTo: redirect.String()}, http.StatusOK)
+}
+
+// Token is the token endpoint of clients: grant_type authorization_code or client_credentials in the form, the
+// client authenticated with HTTP Basic or client_id and client_secret in the form
+func (h *oauthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	clientID, secret, basic := r.BasicAuth()
+	if !basic {
+		clientID, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+
+	var token *models.OAuthToken
+	var err error
+	switch grantType := r.PostFormValue("grant_type"); grantType {
+	case models.GrantAuthorizationCode:
+		token, err = h.oauth.ExchangeCode(r.Context(), clientID, secret, r.PostFormValue("code"),
+			r.PostFormValue("redirect_uri"), r.PostFormValue("code_verifier"))
+	case models.GrantClientCredentials:
+		token, err = h.oauth.ClientCredentials(r.Context(), clientID, secret, r.PostFormValue("scope"))
+	default:
+		h.respond(w, TokenErrorResponse{Error: "unsupported_grant_type", ErrorDescription: "grant_type should be authorization_code or client_credentials"}, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.tokenError(w, err, basic)
+		return
+	}
+	h.respond(w, token, http.StatusOK)
+}
+
+// ListConsents returns the clients the authenticated user approved
+func (h *oauthHandler) ListConsents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	consents, err := h.oauth.ListConsents(r.Context(), userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, consents, http.StatusOK)
+}
+
+// RevokeConsent withdraws the authenticated user's consent to the client in the path; the tokens the client
+// holds for the user stop being active at introspection
+func (h *oauthHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	clientID := mux.Vars(r)["client_id"]
+	if err := h.oauth.RevokeConsent(r.Context(), userID, clientID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.securityEvents.Record(r.Context(), userID, models.SecurityEventTokenRevoked, clientID)
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// tokenError answers the token endpoint with the RFC 6749 error for err
+func (h *oauthHandler) tokenError(w http.ResponseWriter, err error, basic bool) {
+	var appError *apperrors.AppError
+	code, ok := "", false
+	if errors.As(err, &appError) {
+		code, ok = tokenErrors[appError.Code]
+	}
+	if !ok {
+		h.logger.Error(err.Error())
+		h.respond(w, TokenErrorResponse{Error: "server_error"}, http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusBadRequest
+	if code == "invalid_client" {
+		status = http.StatusUnauthorized
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+	h.respond(w, TokenErrorResponse{Error: code, ErrorDescription: appError.Message}, status)
+}
+
+func authorizationRequest(query url.Values) *models.AuthorizationRequest {
+	return &models.AuthorizationRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+}
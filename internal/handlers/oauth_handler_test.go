@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestOAuthHandler(t *testing.T) {
+	admin, user := handlertest.Admin, handlertest.User
+	client := &models.OAuthClient{
+		ID:           4,
+		ClientID:     "Zm9vYmFyYmF6cXV4",
+		Name:         "Calendar",
+		RedirectURIs: []string{"https://calendar.example.com/callback"},
+		GrantTypes:   []string{models.GrantAuthorizationCode},
+		Scopes:       []string{models.ScopeProfile, models.ScopeNotifications},
+		CreatedBy:    admin.ID,
+		CreatedAt:    goldenTime,
+	}
+	registration := map[string]interface{}{
+		"name":          "Calendar",
+		"redirect_uris": []string{"https://calendar.example.com/callback"},
+		"grant_types":   []string{"authorization_code"},
+		"scopes":        []string{"profile", "notifications"},
+		"confidential":  true,
+	}
+	authorization := "/oauth/authorize?response_type=code&client_id=Zm9vYmFyYmF6cXV4&redirect_uri=https%3A%2F%2Fcalendar.example.com%2Fcallback" +
+		"&scope=profile&state=xyz&code_challenge=E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM&code_challenge_method=S256"
+	request := &models.AuthorizationRequest{
+		ResponseType: "code", ClientID: client.ClientID, RedirectURI: "https://calendar.example.com/callback", Scope: "profile",
+		State: "xyz", CodeChallenge: "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallengeMethod: "S256",
+	}
+	exchange := url.Values{
+		"grant_type": {"authorization_code"}, "client_id": {client.ClientID}, "code": {"c0de"},
+		"redirect_uri": {"https://calendar.example.com/callback"}, "code_verifier": {"v3rifier"},
+	}
+
+	tests := []struct {
+		name    string
+		request *handlertest.Request
+		serve   func(h *oauthHandler) http.HandlerFunc
+		expect  func(oauth *services.MockOAuthServiceInterface)
+		// event is the security event the case records for the user
+		event      string
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "register",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/oauth/clients").JSON(registration).As(admin),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.RegisterClient },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().RegisterClient(gomock.Any(), gomock.Any(), true).Return(client, "s3cret", nil)
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "oauth_handler/register",
+		},
+		{
+			name:       "register as a user",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/oauth/clients").JSON(registration).As(user),
+			serve:      func(h *oauthHandler) http.HandlerFunc { return h.RegisterClient },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "register an unknown scope",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/oauth/clients").JSON(map[string]interface{}{
+				"name": "Calendar", "grant_types": []string{"client_credentials"}, "scopes": []string{"admin"}, "confidential": true,
+			}).As(admin),
+			serve:      func(h *oauthHandler) http.HandlerFunc { return h.RegisterClient },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:    "delete",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/oauth/clients/4").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.DeleteClient },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().DeleteClient(gomock.Any(), uint(4)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "authorization",
+			request: handlertest.NewRequest(t, http.MethodGet, authorization).As(user),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Authorization },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().Authorization(gomock.Any(), user.ID, request).Return(client, &models.OAuthConsent{Scope: "notifications"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "oauth_handler/authorization",
+		},
+		{
+			name:    "authorization with another redirect URI",
+			request: handlertest.NewRequest(t, http.MethodGet, "/oauth/authorize?response_type=code&client_id=Zm9vYmFyYmF6cXV4&redirect_uri=https%3A%2F%2Fevil.example.com").As(user),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Authorization },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().Authorization(gomock.Any(), user.ID, gomock.Any()).Return(nil, nil, apperrors.BadRequestErr.AppendMessage("redirect_uri is not registered for the client"))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.BadRequestErr.Code,
+		},
+		{
+			name:    "approve",
+			request: handlertest.NewRequest(t, http.MethodPost, authorization).JSON(map[string]bool{"approve": true}).As(user),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Authorize },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().Authorize(gomock.Any(), user.ID, request).Return("c0de", nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "oauth_handler/approve",
+		},
+		{
+			name:    "deny",
+			request: handlertest.NewRequest(t, http.MethodPost, authorization).JSON(map[string]bool{"approve": false}).As(user),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Authorize },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().Authorization(gomock.Any(), user.ID, request).Return(client, nil, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "oauth_handler/deny",
+		},
+		{
+			name:    "exchange a code",
+			request: handlertest.NewRequest(t, http.MethodPost, "/oauth/token").Form(exchange),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Token },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().ExchangeCode(gomock.Any(), client.ClientID, "", "c0de", "https://calendar.example.com/callback", "v3rifier").
+					Return(&models.OAuthToken{AccessToken: "a.b.c", TokenType: "Bearer", ExpiresIn: 3600, Scope: "profile"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "oauth_handler/token",
+		},
+		{
+			name:    "exchange a used code",
+			request: handlertest.NewRequest(t, http.MethodPost, "/oauth/token").Form(exchange),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Token },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().ExchangeCode(gomock.Any(), client.ClientID, "", "c0de", gomock.Any(), gomock.Any()).Return(nil, &apperrors.OAuthInvalidGrantErr)
+			},
+			wantStatus: http.StatusBadRequest,
+			golden:     "oauth_handler/invalid_grant",
+		},
+		{
+			name:    "client credentials with a wrong secret",
+			request: handlertest.NewRequest(t, http.MethodPost, "/oauth/token").Form(url.Values{"grant_type": {"client_credentials"}, "client_id": {"cron"}, "client_secret": {"wrong"}}),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.Token },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().ClientCredentials(gomock.Any(), "cron", "wrong", "").Return(nil, &apperrors.OAuthInvalidClientErr)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "password grant",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/oauth/token").Form(url.Values{"grant_type": {"password"}}),
+			serve:      func(h *oauthHandler) http.HandlerFunc { return h.Token },
+			wantStatus: http.StatusBadRequest,
+			golden:     "oauth_handler/unsupported_grant_type",
+		},
+		{
+			name:    "list consents",
+			request: handlertest.NewRequest(t, http.MethodGet, "/me/oauth/consents").As(user),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.ListConsents },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().ListConsents(gomock.Any(), user.ID).Return([]models.OAuthConsent{
+					{UserID: user.ID, ClientID: client.ID, Scope: "profile", Client: client, CreatedAt: goldenTime, UpdatedAt: goldenTime},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "oauth_handler/consents",
+		},
+		{
+			name:    "revoke a consent",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/me/oauth/consents/Zm9vYmFyYmF6cXV4").Vars(map[string]string{"client_id": client.ClientID}).As(user),
+			serve:   func(h *oauthHandler) http.HandlerFunc { return h.RevokeConsent },
+			expect: func(oauth *services.MockOAuthServiceInterface) {
+				oauth.EXPECT().RevokeConsent(gomock.Any(), user.ID, client.ClientID).Return(nil)
+			},
+			event:      models.SecurityEventTokenRevoked,
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			oauth := services.NewMockOAuthServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(oauth)
+			}
+			securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+			if tt.event != "" {
+				securityEvents.EXPECT().Record(gomock.Any(), user.ID, tt.event, client.ClientID)
+			}
+			handler := NewOAuthHandler(oauth, securityEvents, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
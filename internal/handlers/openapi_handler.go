@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/openapi"
+	"go.uber.org/zap"
+)
+
+type openAPIHandler struct {
+	*BaseHandler
+	logger *zap.SugaredLogger
+}
+
+func NewOpenAPIHandler(logger *zap.SugaredLogger) *openAPIHandler {
+	return &openAPIHandler{
+		BaseHandler: NewBaseHandler(logger),
+		logger:      logger,
+	}
+}
+
+// Document serves the OpenAPI document the handler tests check responses against
+func (h *openAPIHandler) Document(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openapi.Document())
+}
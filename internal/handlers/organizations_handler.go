@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type organizationsHandler struct {
+	*BaseHandler
+	organizations services.OrganizationServiceInterface
+	logger        *zap.SugaredLogger
+	validator     *validator.Validate
+}
+
+func NewOrganizationsHandler(organizations services.OrganizationServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *organizationsHandler {
+	return &organizationsHandler{
+		BaseHandler:   NewBaseHandler(logger),
+		organizations: organizations,
+		logger:        logger,
+		validator:     validator,
+	}
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+type SetMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// CreateOrganization creates an organization with the caller as its admin
+func (h *organizationsHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	createRequest := &CreateOrganizationRequest{}
+	if err := h.decode(r, createRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, createRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	organization, err := h.organizations.CreateOrganization(r.Context(), createRequest.Name, userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, organization, http.StatusCreated)
+}
+
+// ListOrganizations returns the organizations of the caller with the caller's role in each
+func (h *organizationsHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	memberships, err := h.organizations.ListMemberships(r.Context(), userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewMembershipResponses(memberships), http.StatusOK)
+}
+
+// GetOrganization returns the organization in the path to its members
+func (h *organizationsHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.authorize(w, r, models.StrUser)
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizations.GetOrganization(r.Context(), organizationID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, organization, http.StatusOK)
+}
+
+// ListMembers returns the members of the organization in the path to its members
+func (h *organizationsHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.authorize(w, r, models.StrUser)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizations.ListMembers(r.Context(), organizationID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewMembershipResponses(members), http.StatusOK)
+}
+
+// AddMember adds the user with the email to the organization, answering 201 with the membership, or, for global
+// admins, invites the email when nobody has it yet, answering 202 with the invitation
+func (h *organizationsHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.authorize(w, r, models.StrAdmin)
+	if !ok {
+		return
+	}
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	addRequest := &AddMemberRequest{}
+	if err := h.decode(r, addRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, addRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	membership, invitation, err := h.organizations.AddMember(r.Context(), organizationID, addRequest.Email, addRequest.Role, userID, h.GetAuthenticatedRole(r.Context()))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if invitation != nil {
+		h.respond(w, invitation, http.StatusAccepted)
+		return
+	}
+	h.respond(w, NewMembershipResponse(membership), http.StatusCreated)
+}
+
+// SetMemberRole changes the role of the member in the path
+func (h *organizationsHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.authorize(w, r, models.StrAdmin)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseUint(mux.Vars(r)["user_id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	roleRequest := &SetMemberRoleRequest{}
+	if err := h.decode(r, roleRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, roleRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	membership, err := h.organizations.SetMemberRole(r.Context(), organizationID, uint(memberID), roleRequest.Role)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewMembershipResponse(membership), http.StatusOK)
+}
+
+// RemoveMember removes the member in the path; organization admins remove anybody, other members only themselves
+func (h *organizationsHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseUint(mux.Vars(r)["user_id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	minimum := models.StrAdmin
+	if uint(memberID) == userID {
+		minimum = models.StrUser
+	}
+	organizationID, ok := h.authorize(w, r, minimum)
+	if !ok {
+		return
+	}
+
+	if err := h.organizations.RemoveMember(r.Context(), organizationID, uint(memberID)); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// authorize answers and returns false unless the caller has at least the role minimum in the organization in the
+// path, whose ID it returns
+func (h *organizationsHandler) authorize(w http.ResponseWriter, r *http.Request, minimum string) (uint, bool) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return 0, false
+	}
+	organizationID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return 0, false
+	}
+
+	err = h.organizations.Authorize(r.Context(), uint(organizationID), userID, h.GetAuthenticatedRole(r.Context()), minimum)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusForbidden)
+		return 0, false
+	}
+	return uint(organizationID), true
+}
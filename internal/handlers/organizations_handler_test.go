@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestOrganizationsHandler(t *testing.T) {
+	user := handlertest.User
+	organization := &models.Organization{ID: 7, Name: "Acme", CreatedBy: user.ID, CreatedAt: goldenTime}
+	orgVars := map[string]string{"id": "7"}
+	memberVars := map[string]string{"id": "7", "user_id": "5"}
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *organizationsHandler) http.HandlerFunc
+		expect     func(organizations *services.MockOrganizationServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "create",
+			request: handlertest.NewRequest(t, http.MethodPost, "/organizations").JSON(map[string]string{"name": "Acme"}).As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.CreateOrganization },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().CreateOrganization(gomock.Any(), "Acme", user.ID).Return(organization, nil)
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "organizations_handler/create",
+		},
+		{
+			name:       "create without a name",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/organizations").JSON(map[string]string{}).As(user),
+			serve:      func(h *organizationsHandler) http.HandlerFunc { return h.CreateOrganization },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:    "list",
+			request: handlertest.NewRequest(t, http.MethodGet, "/organizations").As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.ListOrganizations },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().ListMemberships(gomock.Any(), user.ID).Return([]models.Membership{{OrganizationID: 7, UserID: user.ID, Role: models.StrAdmin, Organization: organization}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "get as a non-member",
+			request: handlertest.NewRequest(t, http.MethodGet, "/organizations/7").Vars(orgVars).As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.GetOrganization },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().Authorize(gomock.Any(), uint(7), user.ID, user.Role, models.StrUser).Return(apperrors.ForbiddenErr.AppendMessage("not a member of the organization"))
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperrors.ForbiddenErr.Code,
+		},
+		{
+			name:    "add an existing user",
+			request: handlertest.NewRequest(t, http.MethodPost, "/organizations/7/members").JSON(map[string]string{"email": "bob@example.com", "role": "moderator"}).Vars(orgVars).As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.AddMember },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().Authorize(gomock.Any(), uint(7), user.ID, user.Role, models.StrAdmin).Return(nil)
+				organizations.EXPECT().AddMember(gomock.Any(), uint(7), "bob@example.com", models.StrModerator, user.ID, user.Role).
+					Return(&models.Membership{OrganizationID: 7, UserID: 5, Role: models.StrModerator}, nil, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:    "add an unknown email",
+			request: handlertest.NewRequest(t, http.MethodPost, "/organizations/7/members").JSON(map[string]string{"email": "ann@example.com", "role": "user"}).Vars(orgVars).As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.AddMember },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().Authorize(gomock.Any(), uint(7), user.ID, user.Role, models.StrAdmin).Return(nil)
+				organizations.EXPECT().AddMember(gomock.Any(), uint(7), "ann@example.com", models.StrUser, user.ID, user.Role).Return(nil, &models.Invitation{ID: 4}, nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:    "add as a member",
+			request: handlertest.NewRequest(t, http.MethodPost, "/organizations/7/members").JSON(map[string]string{"email": "ann@example.com", "role": "user"}).Vars(orgVars).As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.AddMember },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().Authorize(gomock.Any(), uint(7), user.ID, user.Role, models.StrAdmin).Return(apperrors.ForbiddenErr.AppendMessage("needs the admin role in the organization"))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "demote the last admin",
+			request: handlertest.NewRequest(t, http.MethodPut, "/organizations/7/members/5").JSON(map[string]string{"role": "user"}).Vars(memberVars).As(user),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.SetMemberRole },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().Authorize(gomock.Any(), uint(7), user.ID, user.Role, models.StrAdmin).Return(nil)
+				organizations.EXPECT().SetMemberRole(gomock.Any(), uint(7), uint(5), models.StrUser).Return(nil, &apperrors.LastOrganizationAdminErr)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.LastOrganizationAdminErr.Code,
+		},
+		{
+			name:    "leave",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/organizations/7/members/5").Vars(map[string]string{"id": "7", "user_id": "5"}).As(handlertest.Identity{ID: 5, Email: "bob@example.com", Role: models.StrUser}),
+			serve:   func(h *organizationsHandler) http.HandlerFunc { return h.RemoveMember },
+			expect: func(organizations *services.MockOrganizationServiceInterface) {
+				organizations.EXPECT().Authorize(gomock.Any(), uint(7), uint(5), models.StrUser, models.StrUser).Return(nil)
+				organizations.EXPECT().RemoveMember(gomock.Any(), uint(7), uint(5)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			organizations := services.NewMockOrganizationServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(organizations)
+			}
+			handler := NewOrganizationsHandler(organizations, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
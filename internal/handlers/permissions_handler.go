@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+type permissionsHandler struct {
+	*BaseHandler
+	permissions services.PermissionServiceInterface
+	logger      *zap.SugaredLogger
+	validator   *validator.Validate
+}
+
+func NewPermissionsHandler(permissions services.PermissionServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *permissionsHandler {
+	return &permissionsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		permissions: permissions,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+type PermissionRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// PermissionAuditResponse is a page of the audit; NextBeforeID is passed as ?before_id= for the next, older page
+type PermissionAuditResponse struct {
+	Data         []models.PermissionAudit `json:"data"`
+	NextBeforeID uint                     `json:"next_before_id,omitempty"`
+}
+
+func (h *permissionsHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	permissions, err := h.permissions.ListPermissions(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, permissions, http.StatusOK)
+}
+
+func (h *permissionsHandler) CreatePermission(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+	permission, ok := h.decodePermission(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.permissions.CreatePermission(r.Context(), permission, actorID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, permission, http.StatusCreated)
+}
+
+func (h *permissionsHandler) UpdatePermission(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	permission, ok := h.decodePermission(w, r)
+	if !ok {
+		return
+	}
+	permission.ID = id
+
+	if err := h.permissions.UpdatePermission(r.Context(), permission, actorID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, permission, http.StatusOK)
+}
+
+// DeletePermission deletes the permission and takes it from every role that has it
+func (h *permissionsHandler) DeletePermission(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.permissions.DeletePermission(r.Context(), id, actorID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// ListRoles returns the roles with the parent each inherits permissions from
+func (h *permissionsHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	roles, err := h.permissions.ListRoles(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, roles, http.StatusOK)
+}
+
+// ListRolePermissions returns the permissions of the role, inherited ones included, each with the role it was
+// granted to
+func (h *permissionsHandler) ListRolePermissions(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	roleID, ok := h.pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	permissions, err := h.permissions.RolePermissions(r.Context(), roleID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, permissions, http.StatusOK)
+}
+
+// GrantPermission grants the permission in the path to the role; granting it again changes nothing
+func (h *permissionsHandler) GrantPermission(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+	roleID, ok := h.pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	permissionID, ok := h.pathID(w, r, "permission_id")
+	if !ok {
+		return
+	}
+
+	if err := h.permissions.GrantPermission(r.Context(), roleID, permissionID, actorID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+func (h *permissionsHandler) RevokePermission(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+	roleID, ok := h.pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	permissionID, ok := h.pathID(w, r, "permission_id")
+	if !ok {
+		return
+	}
+
+	if err := h.permissions.RevokePermission(r.Context(), roleID, permissionID, actorID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// ListAudit returns the changes to permissions and grants, newest first, capped by ?limit= and paged with
+// ?before_id=
+func (h *permissionsHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	limit := defaultAuditLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			h.sendError(w, r, errors.New("limit should be in the range from 1 to "+strconv.Itoa(maxAuditLimit)), http.StatusBadRequest)
+			return
+		}
+	}
+	var beforeID uint64
+	if value := query.Get("before_id"); value != "" {
+		var err error
+		beforeID, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.permissions.ListAudit(r.Context(), uint(beforeID), limit)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response := &PermissionAuditResponse{Data: entries}
+	if len(entries) == limit {
+		response.NextBeforeID = entries[len(entries)-1].ID
+	}
+	h.respond(w, response, http.StatusOK)
+}
+
+func (h *permissionsHandler) pathID(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *permissionsHandler) decodePermission(w http.ResponseWriter, r *http.Request) (*models.Permission, bool) {
+	permissionRequest := &PermissionRequest{}
+	if err := h.decode(r, permissionRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return nil, false
+	}
+	if err := myValidate.ValidationError(h.validator, permissionRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return nil, false
+	}
+	return &models.Permission{Name: permissionRequest.Name, Description: permissionRequest.Description}, true
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestPermissionsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permissions := services.NewMockPermissionServiceInterface(ctrl)
+	handler := NewPermissionsHandler(permissions, zap.NewNop().Sugar(), validator.New())
+	createdAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	handlertest.NewRequest(t, http.MethodPost, "/permissions").As(handlertest.User).JSON(`{"name":"users.delete"}`).
+		Serve(handler.CreatePermission).
+		AssertStatus(http.StatusForbidden)
+
+	handlertest.NewRequest(t, http.MethodPost, "/permissions").As(handlertest.Admin).JSON(`{"description":"Delete users"}`).
+		Serve(handler.CreatePermission).
+		AssertStatus(http.StatusBadRequest)
+
+	permissions.EXPECT().CreatePermission(gomock.Any(), &models.Permission{Name: "users.delete", Description: "Delete users"}, handlertest.Admin.ID).
+		DoAndReturn(func(_ interface{}, permission *models.Permission, _ uint) error {
+			permission.ID, permission.CreatedAt, permission.UpdatedAt = 5, createdAt, createdAt
+			return nil
+		})
+	handlertest.NewRequest(t, http.MethodPost, "/permissions").As(handlertest.Admin).JSON(`{"name":"users.delete","description":"Delete users"}`).
+		Serve(handler.CreatePermission).
+		AssertStatus(http.StatusCreated).
+		AssertGolden("permissions_handler/create")
+
+	permissions.EXPECT().GrantPermission(gomock.Any(), uint(2), uint(5), handlertest.Admin.ID).Return(nil)
+	handlertest.NewRequest(t, http.MethodPut, "/roles/2/permissions/5").As(handlertest.Admin).
+		Vars(map[string]string{"id": "2", "permission_id": "5"}).
+		Serve(handler.GrantPermission).
+		AssertStatus(http.StatusNoContent)
+
+	permissions.EXPECT().RevokePermission(gomock.Any(), uint(2), uint(6), handlertest.Admin.ID).
+		Return(repositories.ErrNotFound.AppendMessage("Permission not found."))
+	handlertest.NewRequest(t, http.MethodDelete, "/roles/2/permissions/6").As(handlertest.Admin).
+		Vars(map[string]string{"id": "2", "permission_id": "6"}).
+		Serve(handler.RevokePermission).
+		AssertStatus(http.StatusNotFound)
+
+	permissions.EXPECT().ListAudit(gomock.Any(), uint(0), 2).Return([]models.PermissionAudit{
+		{ID: 4, ActorID: handlertest.Admin.ID, Action: models.PermissionAuditGranted, PermissionID: 5, PermissionName: "users.delete", RoleID: 2, CreatedAt: createdAt},
+		{ID: 3, ActorID: handlertest.Admin.ID, Action: models.PermissionAuditCreated, PermissionID: 5, PermissionName: "users.delete", CreatedAt: createdAt},
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/permissions/audit?limit=2").As(handlertest.Admin).
+		Serve(handler.ListAudit).
+		AssertStatus(http.StatusOK).
+		AssertGolden("permissions_handler/audit")
+}
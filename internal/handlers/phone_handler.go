@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type phoneHandler struct {
+	*BaseHandler
+	phones    services.PhoneServiceInterface
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+}
+
+func NewPhoneHandler(phones services.PhoneServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *phoneHandler {
+	return &phoneHandler{
+		BaseHandler: NewBaseHandler(logger),
+		phones:      phones,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+// SetPhoneRequest has the number in E.164 form, e.g. +380501234567
+type SetPhoneRequest struct {
+	Number string `json:"number" validate:"required,e164"`
+}
+
+// VerifyPhoneRequest has the code texted to the phone
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,max=16"`
+}
+
+// SetSecondFactorRequest turns the SMS second factor on or off
+type SetSecondFactorRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetPhone returns the caller's phone
+func (h *phoneHandler) GetPhone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	phone, err := h.phones.GetPhone(r.Context(), userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, phone, http.StatusOK)
+}
+
+// SetPhone replaces the caller's phone and texts it a code to verify it with
+func (h *phoneHandler) SetPhone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	setRequest := &SetPhoneRequest{}
+	if err := h.decode(r, setRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, setRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	phone, err := h.phones.SetPhone(r.Context(), userID, setRequest.Number)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, phone, http.StatusOK)
+}
+
+// ResendCode texts the caller's unverified phone a new code
+func (h *phoneHandler) ResendCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.phones.ResendCode(r.Context(), userID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// Verify verifies the caller's phone with the code texted to it
+func (h *phoneHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	verifyRequest := &VerifyPhoneRequest{}
+	if err := h.decode(r, verifyRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, verifyRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	phone, err := h.phones.Verify(r.Context(), userID, verifyRequest.Code)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, phone, http.StatusOK)
+}
+
+// SetSecondFactor turns the SMS second factor of the caller's password logins on or off
+func (h *phoneHandler) SetSecondFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	setRequest := &SetSecondFactorRequest{}
+	if err := h.decode(r, setRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	phone, err := h.phones.SetSecondFactor(r.Context(), userID, setRequest.Enabled)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, phone, http.StatusOK)
+}
+
+// RemovePhone removes the caller's phone, and its second factor with it
+func (h *phoneHandler) RemovePhone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.phones.Remove(r.Context(), userID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestPhoneHandler(t *testing.T) {
+	user := handlertest.User
+	verified := &models.Phone{UserID: user.ID, Number: "+380501234567", VerifiedAt: &goldenTime, UpdatedAt: goldenTime}
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *phoneHandler) http.HandlerFunc
+		expect     func(phones *services.MockPhoneServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "set",
+			request: handlertest.NewRequest(t, http.MethodPut, "/me/phone").JSON(map[string]string{"number": "+380501234567"}).As(user),
+			serve:   func(h *phoneHandler) http.HandlerFunc { return h.SetPhone },
+			expect: func(phones *services.MockPhoneServiceInterface) {
+				phones.EXPECT().SetPhone(gomock.Any(), user.ID, "+380501234567").Return(&models.Phone{UserID: user.ID, Number: "+380501234567", UpdatedAt: goldenTime}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "set a number in local form",
+			request:    handlertest.NewRequest(t, http.MethodPut, "/me/phone").JSON(map[string]string{"number": "0501234567"}).As(user),
+			serve:      func(h *phoneHandler) http.HandlerFunc { return h.SetPhone },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:    "resend over the limit",
+			request: handlertest.NewRequest(t, http.MethodPost, "/me/phone/code").As(user),
+			serve:   func(h *phoneHandler) http.HandlerFunc { return h.ResendCode },
+			expect: func(phones *services.MockPhoneServiceInterface) {
+				phones.EXPECT().ResendCode(gomock.Any(), user.ID).Return(apperrors.TooManyRequestsErr.AppendMessage("too many codes sent, try again in 60 seconds"))
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   apperrors.TooManyRequestsErr.Code,
+		},
+		{
+			name:    "verify",
+			request: handlertest.NewRequest(t, http.MethodPost, "/me/phone/verify").JSON(map[string]string{"code": "123456"}).As(user),
+			serve:   func(h *phoneHandler) http.HandlerFunc { return h.Verify },
+			expect: func(phones *services.MockPhoneServiceInterface) {
+				phones.EXPECT().Verify(gomock.Any(), user.ID, "123456").Return(verified, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "phone_handler/verify",
+		},
+		{
+			name:    "verify with a wrong code",
+			request: handlertest.NewRequest(t, http.MethodPost, "/me/phone/verify").JSON(map[string]string{"code": "654321"}).As(user),
+			serve:   func(h *phoneHandler) http.HandlerFunc { return h.Verify },
+			expect: func(phones *services.MockPhoneServiceInterface) {
+				phones.EXPECT().Verify(gomock.Any(), user.ID, "654321").Return(nil, &apperrors.PhoneCodeInvalidErr)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.PhoneCodeInvalidErr.Code,
+		},
+		{
+			name:    "second factor on an unverified phone",
+			request: handlertest.NewRequest(t, http.MethodPut, "/me/phone/second-factor").JSON(map[string]bool{"enabled": true}).As(user),
+			serve:   func(h *phoneHandler) http.HandlerFunc { return h.SetSecondFactor },
+			expect: func(phones *services.MockPhoneServiceInterface) {
+				phones.EXPECT().SetSecondFactor(gomock.Any(), user.ID, true).Return(nil, &apperrors.PhoneNotVerifiedErr)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.PhoneNotVerifiedErr.Code,
+		},
+		{
+			name:    "remove",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/me/phone").As(user),
+			serve:   func(h *phoneHandler) http.HandlerFunc { return h.RemovePhone },
+			expect: func(phones *services.MockPhoneServiceInterface) {
+				phones.EXPECT().Remove(gomock.Any(), user.ID).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			phones := services.NewMockPhoneServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(phones)
+			}
+			handler := NewPhoneHandler(phones, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type quotasHandler struct {
+	*BaseHandler
+	quotas services.QuotaServiceInterface
+	logger *zap.SugaredLogger
+}
+
+func NewQuotasHandler(quotas services.QuotaServiceInterface, logger *zap.SugaredLogger) *quotasHandler {
+	return &quotasHandler{
+		BaseHandler: NewBaseHandler(logger),
+		quotas:      quotas,
+		logger:      logger,
+	}
+}
+
+// Usage returns the limit and use of every quota of the tenant the request is made for
+func (h *quotasHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	usage, err := h.quotas.Usage(r.Context())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, usage, http.StatusOK)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestQuotasHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	quotas := services.NewMockQuotaServiceInterface(ctrl)
+	handler := NewQuotasHandler(quotas, zap.NewNop().Sugar())
+
+	handlertest.NewRequest(t, http.MethodGet, "/admin/quotas").As(handlertest.User).
+		Serve(handler.Usage).
+		AssertStatus(http.StatusForbidden)
+
+	quotas.EXPECT().Usage(gomock.Any()).Return([]models.QuotaUsage{
+		{Name: models.QuotaUsers, Limit: 100, Used: 12},
+		{Name: models.QuotaVotesPerDay, Used: 3},
+		{Name: models.QuotaRequestsPerDay, Limit: 10000, Used: 40},
+		{Name: models.QuotaOrganizationMembers, Limit: 25, Used: 5},
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/quotas").As(handlertest.Admin).
+		Serve(handler.Usage).
+		AssertStatus(http.StatusOK).
+		AssertGolden("quotas_handler/usage")
+}
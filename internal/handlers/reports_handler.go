@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+const maxReportsPageSize = 100
+
+type reportsHandler struct {
+	*BaseHandler
+	reports   services.ReportServiceInterface
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+}
+
+func NewReportsHandler(reports services.ReportServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *reportsHandler {
+	return &reportsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		reports:     reports,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+type ReportUserRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=spam harassment impersonation inappropriate_content other"`
+	Details string `json:"details" validate:"max=1000"`
+}
+
+type ResolveReportRequest struct {
+	Action string `json:"action" validate:"required,oneof=dismiss suspend delete"`
+}
+
+// Report files a report by the caller on the user in the path
+func (h *reportsHandler) Report(w http.ResponseWriter, r *http.Request) {
+	reporterID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	userID, err := parseProfileID(mux.Vars(r)["id"])
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	reportRequest := &ReportUserRequest{}
+	if err := h.decode(r, reportRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, reportRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.reports.Report(r.Context(), &models.Report{
+		ReporterID: reporterID,
+		UserID:     uint(userID),
+		Reason:     reportRequest.Reason,
+		Details:    reportRequest.Details,
+	})
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewReportResponse(report), http.StatusCreated)
+}
+
+// ListReports returns a page of the moderation queue, oldest first: the pending reports unless ?status= asks for the
+// dismissed or actioned ones, filtered by ?reason= and ?user_id=. The route requires the reports.moderate permission.
+func (h *reportsHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	query, err := parseReportQuery(r.URL.Query())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	page, pageSize := defaultPage, defaultPageSize
+	if value := r.URL.Query().Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			h.sendError(w, r, errors.New("incorrect page number"), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := r.URL.Query().Get("page_size"); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize <= 0 || pageSize > maxReportsPageSize {
+			h.sendError(w, r, errors.New("the number of objects on the page should be in the range from 1 to "+strconv.Itoa(maxReportsPageSize)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	reports, err := h.reports.ListReports(r.Context(), query, page, pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewReportPageResponse(reports), http.StatusOK)
+}
+
+// Resolve dismisses the report in the path or acts on the reported user, with the X-Audit-Reason header recorded in
+// the admin audit. The route requires the reports.moderate permission.
+func (h *reportsHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	resolveRequest := &ResolveReportRequest{}
+	if err := h.decode(r, resolveRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, resolveRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	by, err := adminAction(r, actorID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.reports.Resolve(r.Context(), by, id, resolveRequest.Action)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewReportResponse(report), http.StatusOK)
+}
+
+func parseReportQuery(values url.Values) (*models.ReportQuery, error) {
+	query := &models.ReportQuery{Status: models.ReportStatusPending, Reason: values.Get("reason")}
+	switch status := values.Get("status"); status {
+	case "":
+	case models.ReportStatusPending, models.ReportStatusDismissed, models.ReportStatusActioned:
+		query.Status = status
+	default:
+		return nil, errors.New("status should be one of pending, dismissed, actioned")
+	}
+	if value := values.Get("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		query.UserID = uint(userID)
+	}
+	return query, nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestReportsHandler(t *testing.T) {
+	admin, user := handlertest.Admin, handlertest.User
+	reported := goldenUser(4, "reported@example.com")
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *reportsHandler) http.HandlerFunc
+		expect     func(reports *services.MockReportServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name: "report",
+			request: handlertest.NewRequest(t, http.MethodPost, "/users/4/report").Vars(map[string]string{"id": "4"}).
+				JSON(map[string]string{"reason": models.ReportReasonSpam, "details": "Sells followers"}).As(user),
+			serve: func(h *reportsHandler) http.HandlerFunc { return h.Report },
+			expect: func(reports *services.MockReportServiceInterface) {
+				reports.EXPECT().Report(gomock.Any(), &models.Report{ReporterID: user.ID, UserID: 4, Reason: models.ReportReasonSpam, Details: "Sells followers"}).
+					DoAndReturn(func(_ interface{}, report *models.Report) (*models.Report, error) {
+						report.ID, report.Status, report.CreatedAt = 12, models.ReportStatusPending, goldenTime
+						return report, nil
+					})
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "reports_handler/report",
+		},
+		{
+			name: "report with an unknown reason",
+			request: handlertest.NewRequest(t, http.MethodPost, "/users/4/report").Vars(map[string]string{"id": "4"}).
+				JSON(map[string]string{"reason": "dislike"}).As(user),
+			serve:      func(h *reportsHandler) http.HandlerFunc { return h.Report },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name: "report again",
+			request: handlertest.NewRequest(t, http.MethodPost, "/users/4/report").Vars(map[string]string{"id": "4"}).
+				JSON(map[string]string{"reason": models.ReportReasonSpam}).As(user),
+			serve: func(h *reportsHandler) http.HandlerFunc { return h.Report },
+			expect: func(reports *services.MockReportServiceInterface) {
+				reports.EXPECT().Report(gomock.Any(), gomock.Any()).Return(nil, &apperrors.ReportPendingErr)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.ReportPendingErr.Code,
+		},
+		{
+			name:    "queue",
+			request: handlertest.NewRequest(t, http.MethodGet, "/admin/reports?reason=spam&page_size=1").As(admin),
+			serve:   func(h *reportsHandler) http.HandlerFunc { return h.ListReports },
+			expect: func(reports *services.MockReportServiceInterface) {
+				query := &models.ReportQuery{Status: models.ReportStatusPending, Reason: models.ReportReasonSpam}
+				reports.EXPECT().ListReports(gomock.Any(), query, 1, 1).Return(&models.ReportPage{
+					Data: []models.Report{{
+						ID:         12,
+						ReporterID: user.ID,
+						UserID:     4,
+						Reason:     models.ReportReasonSpam,
+						Details:    "Sells followers",
+						Status:     models.ReportStatusPending,
+						CreatedAt:  goldenTime,
+						User:       &reported,
+					}},
+					Page:       1,
+					PageSize:   1,
+					Total:      3,
+					TotalPages: 3,
+					HasNext:    true,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "reports_handler/queue",
+		},
+		{
+			name:       "queue with an unknown status",
+			request:    handlertest.NewRequest(t, http.MethodGet, "/admin/reports?status=closed").As(admin),
+			serve:      func(h *reportsHandler) http.HandlerFunc { return h.ListReports },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "suspend",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/reports/12/resolve").Vars(map[string]string{"id": "12"}).
+				JSON(map[string]string{"action": models.ReportActionSuspend}).Header("X-Audit-Reason", "spam campaign").As(admin),
+			serve: func(h *reportsHandler) http.HandlerFunc { return h.Resolve },
+			expect: func(reports *services.MockReportServiceInterface) {
+				by := &models.AdminAction{ActorID: admin.ID, Reason: "spam campaign"}
+				reports.EXPECT().Resolve(gomock.Any(), by, uint64(12), models.ReportActionSuspend).
+					Return(&models.Report{ID: 12, UserID: 4, Reason: models.ReportReasonSpam, Status: models.ReportStatusActioned, Action: models.ReportActionSuspend}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "resolve a resolved report",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/reports/12/resolve").Vars(map[string]string{"id": "12"}).
+				JSON(map[string]string{"action": models.ReportActionDismiss}).As(admin),
+			serve: func(h *reportsHandler) http.HandlerFunc { return h.Resolve },
+			expect: func(reports *services.MockReportServiceInterface) {
+				reports.EXPECT().Resolve(gomock.Any(), gomock.Any(), uint64(12), models.ReportActionDismiss).Return(nil, &apperrors.ReportResolvedErr)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.ReportResolvedErr.Code,
+		},
+		{
+			name: "resolve with an unknown action",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/reports/12/resolve").Vars(map[string]string{"id": "12"}).
+				JSON(map[string]string{"action": "ban"}).As(admin),
+			serve:      func(h *reportsHandler) http.HandlerFunc { return h.Resolve },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			reports := services.NewMockReportServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(reports)
+			}
+			handler := NewReportsHandler(reports, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
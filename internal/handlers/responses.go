@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+)
+
+// UserResponse is a user as the API shows it. It is copied field by field from models.User, so the password hash,
+// the tenant and any column added to the model later stay out of responses until they are added here on purpose.
+type UserResponse struct {
+	ID            uint         `json:"user_id"`
+	Email         string       `json:"email"`
+	FirstName     string       `json:"first_name"`
+	LastName      string       `json:"last_name"`
+	Role          RoleResponse `json:"role"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	VoteUpdatedAt time.Time    `json:"vote_updated_at"`
+	Rating        int          `json:"rating"`
+	Timezone      string       `json:"timezone"`
+	Locale        string       `json:"locale"`
+	// ServiceAccount is only shown for service accounts
+	ServiceAccount bool `json:"service_account,omitempty"`
+}
+
+type RoleResponse struct {
+	ID   uint   `json:"role_id"`
+	Name string `json:"name"`
+}
+
+// NewUserResponse maps user, nil staying nil
+func NewUserResponse(user *models.User) *UserResponse {
+	if user == nil {
+		return nil
+	}
+	return &UserResponse{
+		ID:             user.ID,
+		Email:          user.Email,
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		Role:           RoleResponse{ID: user.Role.ID, Name: user.Role.Name},
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		VoteUpdatedAt:  user.VoteUpdatedAt,
+		Rating:         user.Rating,
+		Timezone:       user.Timezone,
+		Locale:         user.Locale,
+		ServiceAccount: user.ServiceAccount,
+	}
+}
+
+func NewUserResponses(users []models.User) []UserResponse {
+	responses := make([]UserResponse, len(users))
+	for i := range users {
+		responses[i] = *NewUserResponse(&users[i])
+	}
+	return responses
+}
+
+// UserPageResponse is models.UserPage with the users mapped
+type UserPageResponse struct {
+	Data       []UserResponse `json:"data"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	Total      int            `json:"total"`
+	TotalPages int            `json:"total_pages"`
+	HasNext    bool           `json:"has_next"`
+}
+
+func NewUserPageResponse(page *models.UserPage) *UserPageResponse {
+	return &UserPageResponse{
+		Data:       NewUserResponses(page.Data),
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+		HasNext:    page.HasNext,
+	}
+}
+
+// FollowResponse is one entry of a follower or following list: the user on the other side and when the follow began
+type FollowResponse struct {
+	User       *UserResponse `json:"user"`
+	FollowedAt time.Time     `json:"followed_at"`
+}
+
+// FollowPageResponse is models.FollowPage with the followers, or the followed users when following is set, mapped
+type FollowPageResponse struct {
+	Data       []FollowResponse `json:"data"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	Total      int              `json:"total"`
+	TotalPages int              `json:"total_pages"`
+	HasNext    bool             `json:"has_next"`
+}
+
+func NewFollowPageResponse(page *models.FollowPage, following bool) *FollowPageResponse {
+	data := make([]FollowResponse, len(page.Data))
+	for i := range page.Data {
+		follow := &page.Data[i]
+		user := follow.Follower
+		if following {
+			user = follow.Followed
+		}
+		data[i] = FollowResponse{User: NewUserResponse(user), FollowedAt: follow.CreatedAt}
+	}
+	return &FollowPageResponse{
+		Data:       data,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+		HasNext:    page.HasNext,
+	}
+}
+
+// ReportResponse is models.Report with the reported user mapped, when it is loaded
+type ReportResponse struct {
+	*models.Report
+	User *UserResponse `json:"user,omitempty"`
+}
+
+func NewReportResponse(report *models.Report) *ReportResponse {
+	return &ReportResponse{Report: report, User: NewUserResponse(report.User)}
+}
+
+type ReportPageResponse struct {
+	Data       []ReportResponse `json:"data"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	Total      int              `json:"total"`
+	TotalPages int              `json:"total_pages"`
+	HasNext    bool             `json:"has_next"`
+}
+
+func NewReportPageResponse(page *models.ReportPage) *ReportPageResponse {
+	data := make([]ReportResponse, len(page.Data))
+	for i := range page.Data {
+		data[i] = *NewReportResponse(&page.Data[i])
+	}
+	return &ReportPageResponse{
+		Data:       data,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+		HasNext:    page.HasNext,
+	}
+}
+
+// BanResponse is models.Ban with the banned user mapped, when it is loaded
+type BanResponse struct {
+	*models.Ban
+	User *UserResponse `json:"user,omitempty"`
+}
+
+func NewBanResponse(ban *models.Ban) *BanResponse {
+	return &BanResponse{Ban: ban, User: NewUserResponse(ban.User)}
+}
+
+type BanPageResponse struct {
+	Data       []BanResponse `json:"data"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	Total      int           `json:"total"`
+	TotalPages int           `json:"total_pages"`
+	HasNext    bool          `json:"has_next"`
+}
+
+func NewBanPageResponse(page *models.BanPage) *BanPageResponse {
+	data := make([]BanResponse, len(page.Data))
+	for i := range page.Data {
+		data[i] = *NewBanResponse(&page.Data[i])
+	}
+	return &BanPageResponse{
+		Data:       data,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+		HasNext:    page.HasNext,
+	}
+}
+
+// ShadowBanResponse is models.ShadowBan with the shadow-banned user mapped, when it is loaded
+type ShadowBanResponse struct {
+	*models.ShadowBan
+	User *UserResponse `json:"user,omitempty"`
+}
+
+type ShadowBanPageResponse struct {
+	Data       []ShadowBanResponse `json:"data"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	Total      int                 `json:"total"`
+	TotalPages int                 `json:"total_pages"`
+	HasNext    bool                `json:"has_next"`
+}
+
+func NewShadowBanPageResponse(page *models.ShadowBanPage) *ShadowBanPageResponse {
+	data := make([]ShadowBanResponse, len(page.Data))
+	for i := range page.Data {
+		data[i] = ShadowBanResponse{ShadowBan: &page.Data[i], User: NewUserResponse(page.Data[i].User)}
+	}
+	return &ShadowBanPageResponse{
+		Data:       data,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+		HasNext:    page.HasNext,
+	}
+}
+
+// UserChangeResponse is models.UserChange with the user mapped
+type UserChangeResponse struct {
+	UserID    uint          `json:"user_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Deleted   bool          `json:"deleted"`
+	User      *UserResponse `json:"user,omitempty"`
+}
+
+type UserChangesPageResponse struct {
+	Data         []UserChangeResponse `json:"data"`
+	HasMore      bool                 `json:"has_more"`
+	UpdatedSince time.Time            `json:"updated_since"`
+	AfterID      uint                 `json:"after_id"`
+}
+
+func NewUserChangesPageResponse(page *models.UserChangesPage) *UserChangesPageResponse {
+	response := &UserChangesPageResponse{
+		Data:         make([]UserChangeResponse, len(page.Data)),
+		HasMore:      page.HasMore,
+		UpdatedSince: page.UpdatedSince,
+		AfterID:      page.AfterID,
+	}
+	for i, change := range page.Data {
+		response.Data[i] = UserChangeResponse{
+			UserID:    change.UserID,
+			UpdatedAt: change.UpdatedAt,
+			Deleted:   change.Deleted,
+			User:      NewUserResponse(change.User),
+		}
+	}
+	return response
+}
+
+// ChangeResponse is models.Change with the user mapped
+type ChangeResponse struct {
+	Seq       uint64        `json:"seq"`
+	UserID    uint          `json:"user_id"`
+	Operation string        `json:"operation"`
+	ChangedAt time.Time     `json:"changed_at"`
+	User      *UserResponse `json:"user,omitempty"`
+}
+
+type ChangesPageResponse struct {
+	Data    []ChangeResponse `json:"data"`
+	HasMore bool             `json:"has_more"`
+	NextSeq uint64           `json:"next_seq"`
+}
+
+func NewChangesPageResponse(page *models.ChangesPage) *ChangesPageResponse {
+	response := &ChangesPageResponse{
+		Data:    make([]ChangeResponse, len(page.Data)),
+		HasMore: page.HasMore,
+		NextSeq: page.NextSeq,
+	}
+	for i, change := range page.Data {
+		response.Data[i] = ChangeResponse{
+			Seq:       change.Seq,
+			UserID:    change.UserID,
+			Operation: change.Operation,
+			ChangedAt: change.ChangedAt,
+			User:      NewUserResponse(change.User),
+		}
+	}
+	return response
+}
+
+// MembershipResponse is models.Membership with the member mapped
+type MembershipResponse struct {
+	OrganizationID uint                 `json:"organization_id"`
+	UserID         uint                 `json:"user_id"`
+	Role           string               `json:"role"`
+	CreatedAt      time.Time            `json:"created_at"`
+	Organization   *models.Organization `json:"organization,omitempty"`
+	User           *UserResponse        `json:"user,omitempty"`
+}
+
+func NewMembershipResponse(membership *models.Membership) *MembershipResponse {
+	return &MembershipResponse{
+		OrganizationID: membership.OrganizationID,
+		UserID:         membership.UserID,
+		Role:           membership.Role,
+		CreatedAt:      membership.CreatedAt,
+		Organization:   membership.Organization,
+		User:           NewUserResponse(membership.User),
+	}
+}
+
+func NewMembershipResponses(memberships []models.Membership) []MembershipResponse {
+	responses := make([]MembershipResponse, len(memberships))
+	for i := range memberships {
+		responses[i] = *NewMembershipResponse(&memberships[i])
+	}
+	return responses
+}
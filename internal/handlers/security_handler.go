@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+type securityHandler struct {
+	*BaseHandler
+	security       services.SecurityServiceInterface
+	securityEvents services.SecurityEventServiceInterface
+	logger         *zap.SugaredLogger
+}
+
+func NewSecurityHandler(security services.SecurityServiceInterface, securityEvents services.SecurityEventServiceInterface, logger *zap.SugaredLogger) *securityHandler {
+	return &securityHandler{
+		BaseHandler:    NewBaseHandler(logger),
+		security:       security,
+		securityEvents: securityEvents,
+		logger:         logger,
+	}
+}
+
+type ListSecurityIncidentsResponse struct {
+	Incidents []models.SecurityIncident `json:"incidents"`
+}
+
+// SecurityEventsResponse is a page of security events; NextBeforeID is passed as ?before_id= for the next, older page
+type SecurityEventsResponse struct {
+	Data         []models.SecurityEvent `json:"data"`
+	NextBeforeID uint64                 `json:"next_before_id,omitempty"`
+}
+
+// ListIncidents returns the latest brute-force incidents, of one signal with ?signal= and capped by ?limit=. The
+// route requires the security.incidents.read permission.
+func (h *securityHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	signal := query.Get("signal")
+	switch signal {
+	case "", models.SignalIP, models.SignalAccount, models.SignalUserAgent:
+	default:
+		h.sendError(w, r, errors.New("signal should be one of ip, account, user_agent"), http.StatusBadRequest)
+		return
+	}
+	limit := defaultJobsLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxJobsLimit {
+			h.sendError(w, r, errors.New("limit should be in the range from 1 to "+strconv.Itoa(maxJobsLimit)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	incidents, err := h.security.ListIncidents(r.Context(), signal, limit)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, &ListSecurityIncidentsResponse{Incidents: incidents}, http.StatusOK)
+}
+
+// ListMyEvents returns the latest security events of the authenticated user, of one ?type= and paged like ListEvents
+func (h *securityHandler) ListMyEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	h.listEvents(w, r, userID)
+}
+
+// ListEvents returns the latest security events of every user, or of ?user_id=, for incident response. The route
+// requires the security.events.read permission.
+func (h *securityHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	var userID uint
+	if value := r.URL.Query().Get("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			h.sendError(w, r, errors.New("user_id should be a user ID"), http.StatusBadRequest)
+			return
+		}
+		userID = uint(id)
+	}
+	h.listEvents(w, r, userID)
+}
+
+// listEvents answers with a page of the events of userID, or of every user when it is 0
+func (h *securityHandler) listEvents(w http.ResponseWriter, r *http.Request, userID uint) {
+	values := r.URL.Query()
+	query := &models.SecurityEventQuery{UserID: userID, Type: values.Get("type"), Limit: defaultAuditLimit}
+	switch query.Type {
+	case "", models.SecurityEventLogin, models.SecurityEventPasswordChanged, models.SecurityEventIdentityUnlinked, models.SecurityEventTokenRevoked:
+	default:
+		h.sendError(w, r, errors.New("type should be one of login, password_changed, identity_unlinked, token_revoked"), http.StatusBadRequest)
+		return
+	}
+	if value := values.Get("limit"); value != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(value)
+		if err != nil || query.Limit <= 0 || query.Limit > maxAuditLimit {
+			h.sendError(w, r, errors.New("limit should be in the range from 1 to "+strconv.Itoa(maxAuditLimit)), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := values.Get("before_id"); value != "" {
+		var err error
+		query.BeforeID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := h.securityEvents.ListEvents(r.Context(), query)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response := &SecurityEventsResponse{Data: events}
+	if len(events) == query.Limit {
+		response.NextBeforeID = events[len(events)-1].ID
+	}
+	h.respond(w, response, http.StatusOK)
+}
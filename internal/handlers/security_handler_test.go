@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestSecurityHandler_ListIncidents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	security := services.NewMockSecurityServiceInterface(ctrl)
+	handler := NewSecurityHandler(security, nil, zap.NewNop().Sugar())
+	raisedAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	handlertest.NewRequest(t, http.MethodGet, "/admin/security/incidents?signal=password").As(handlertest.Admin).
+		Serve(handler.ListIncidents).
+		AssertStatus(http.StatusBadRequest)
+
+	security.EXPECT().ListIncidents(gomock.Any(), models.SignalIP, 2).Return([]models.SecurityIncident{
+		{ID: 9, Signal: models.SignalIP, Subject: "203.0.113.7", Failures: 20, CreatedAt: raisedAt},
+		{ID: 4, Signal: models.SignalIP, Subject: "198.51.100.4", Failures: 20, CreatedAt: raisedAt.Add(-time.Hour)},
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/security/incidents?signal=ip&limit=2").As(handlertest.Admin).
+		Serve(handler.ListIncidents).
+		AssertStatus(http.StatusOK).
+		AssertGolden("security_handler/incidents")
+}
+
+func TestSecurityHandler_ListEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	securityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+	handler := NewSecurityHandler(nil, securityEvents, zap.NewNop().Sugar())
+	user := handlertest.User
+
+	securityEvents.EXPECT().ListEvents(gomock.Any(), &models.SecurityEventQuery{UserID: user.ID, Limit: 2}).Return([]models.SecurityEvent{
+		{ID: 12, UserID: user.ID, Type: models.SecurityEventPasswordChanged, IP: "203.0.113.7", UserAgent: "Mozilla/5.0", CreatedAt: goldenTime},
+		{ID: 9, UserID: user.ID, Type: models.SecurityEventLogin, IP: "203.0.113.7", UserAgent: "Mozilla/5.0", CreatedAt: goldenTime.Add(-time.Hour)},
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/me/security-events?limit=2").As(user).
+		Serve(handler.ListMyEvents).
+		AssertStatus(http.StatusOK).
+		AssertGolden("security_handler/my_events")
+
+	handlertest.NewRequest(t, http.MethodGet, "/me/security-events").
+		Serve(handler.ListMyEvents).
+		AssertStatus(http.StatusUnauthorized)
+	handlertest.NewRequest(t, http.MethodGet, "/me/security-events?type=vote").As(user).
+		Serve(handler.ListMyEvents).
+		AssertStatus(http.StatusBadRequest)
+
+	securityEvents.EXPECT().ListEvents(gomock.Any(), &models.SecurityEventQuery{UserID: 5, Type: models.SecurityEventTokenRevoked, BeforeID: 40, Limit: defaultAuditLimit}).Return(nil, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/security/events?user_id=5&type=token_revoked&before_id=40").As(handlertest.Admin).
+		Serve(handler.ListEvents).
+		AssertStatus(http.StatusOK)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/security/events?user_id=ann").As(handlertest.Admin).
+		Serve(handler.ListEvents).
+		AssertStatus(http.StatusBadRequest)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type serviceAccountsHandler struct {
+	*BaseHandler
+	audit     services.AdminAuditServiceInterface
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+}
+
+func NewServiceAccountsHandler(audit services.AdminAuditServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *serviceAccountsHandler {
+	return &serviceAccountsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		audit:       audit,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+// CreateServiceAccountRequest names a service account. Mutual TLS identities and partner accounts map to it by
+// Email; its role is user or moderator, never admin.
+type CreateServiceAccountRequest struct {
+	Email  string `json:"email" validate:"required,email,max=254"`
+	Name   string `json:"name" validate:"required,max=100"`
+	RoleID uint   `json:"role_id" validate:"required,oneof=1 2"`
+}
+
+// Create creates a service account, which gets its credentials from POST /admin/api-keys
+func (h *serviceAccountsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	actorID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	by, err := adminAction(r, actorID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	createRequest := &CreateServiceAccountRequest{}
+	if err := h.decode(r, createRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, createRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.audit.CreateServiceAccount(r.Context(), by, &models.User{
+		Email:     createRequest.Email,
+		FirstName: createRequest.Name,
+		RoleID:    createRequest.RoleID,
+	})
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewUserResponse(account), http.StatusCreated)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestServiceAccountsHandler(t *testing.T) {
+	admin := handlertest.Admin
+	body := map[string]interface{}{"email": "billing@svc.example.com", "name": "Billing", "role_id": 1}
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		expect     func(audit *services.MockAdminAuditServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "create",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/service-accounts").JSON(body).Header(auditReasonHeader, "billing export").As(admin),
+			expect: func(audit *services.MockAdminAuditServiceInterface) {
+				audit.EXPECT().CreateServiceAccount(gomock.Any(), &models.AdminAction{ActorID: admin.ID, Reason: "billing export"}, &models.User{Email: "billing@svc.example.com", FirstName: "Billing", RoleID: 1}).
+					Return(&models.User{ID: 40, Email: "billing@svc.example.com", FirstName: "Billing", Role: models.Role{ID: 1, Name: models.StrUser}, RoleID: 1, ServiceAccount: true, CreatedAt: goldenTime, UpdatedAt: goldenTime}, nil)
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "service_accounts_handler/create",
+		},
+		{
+			name:       "create as a user",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/service-accounts").JSON(body).As(handlertest.User),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "create an admin",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/admin/service-accounts").JSON(map[string]interface{}{"email": "ops@svc.example.com", "name": "Ops", "role_id": 3}).As(admin),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+		{
+			name:    "create with a taken email",
+			request: handlertest.NewRequest(t, http.MethodPost, "/admin/service-accounts").JSON(body).As(admin),
+			expect: func(audit *services.MockAdminAuditServiceInterface) {
+				audit.EXPECT().CreateServiceAccount(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, apperrors.DuplicateEmailErr.AppendMessage("billing@svc.example.com"))
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.DuplicateEmailErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			audit := services.NewMockAdminAuditServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(audit)
+			}
+			handler := NewServiceAccountsHandler(audit, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(handler.Create).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+const maxShadowBansPageSize = 100
+
+type shadowBansHandler struct {
+	*BaseHandler
+	shadowBans services.ShadowBanServiceInterface
+	logger     *zap.SugaredLogger
+}
+
+func NewShadowBansHandler(shadowBans services.ShadowBanServiceInterface, logger *zap.SugaredLogger) *shadowBansHandler {
+	return &shadowBansHandler{
+		BaseHandler: NewBaseHandler(logger),
+		shadowBans:  shadowBans,
+		logger:      logger,
+	}
+}
+
+// ShadowBan shadow-bans the user in the path
+func (h *shadowBansHandler) ShadowBan(w http.ResponseWriter, r *http.Request) {
+	by, userID, ok := h.shadowBanAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.shadowBans.ShadowBan(r.Context(), by, userID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// Lift lifts the shadow ban of the user in the path
+func (h *shadowBansHandler) Lift(w http.ResponseWriter, r *http.Request) {
+	by, userID, ok := h.shadowBanAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.shadowBans.Lift(r.Context(), by, userID); err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, nil, http.StatusNoContent)
+}
+
+// ListShadowBans returns a page of the shadow bans with the shadow-banned users, latest first
+func (h *shadowBansHandler) ListShadowBans(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	page, pageSize := defaultPage, defaultPageSize
+	var err error
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			h.sendError(w, r, errors.New("incorrect page number"), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize <= 0 || pageSize > maxShadowBansPageSize {
+			h.sendError(w, r, errors.New("the number of objects on the page should be in the range from 1 to "+strconv.Itoa(maxShadowBansPageSize)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	shadowBans, err := h.shadowBans.ListShadowBans(r.Context(), page, pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewShadowBanPageResponse(shadowBans), http.StatusOK)
+}
+
+// shadowBanAction returns the admin action of a request by an admin and the user in its path
+func (h *shadowBansHandler) shadowBanAction(w http.ResponseWriter, r *http.Request) (*models.AdminAction, uint, bool) {
+	if !h.requireAdmin(w, r) {
+		return nil, 0, false
+	}
+	actorID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return nil, 0, false
+	}
+	userID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return nil, 0, false
+	}
+	by, err := adminAction(r, actorID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return by, uint(userID), true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestShadowBansHandler(t *testing.T) {
+	admin, user := handlertest.Admin, handlertest.User
+	shadowBanned := goldenUser(4, "shadow@example.com")
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *shadowBansHandler) http.HandlerFunc
+		expect     func(shadowBans *services.MockShadowBanServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name: "shadow-ban",
+			request: handlertest.NewRequest(t, http.MethodPut, "/admin/users/4/shadow-ban").Vars(map[string]string{"id": "4"}).
+				Header("X-Audit-Reason", "vote ring").As(admin),
+			serve: func(h *shadowBansHandler) http.HandlerFunc { return h.ShadowBan },
+			expect: func(shadowBans *services.MockShadowBanServiceInterface) {
+				shadowBans.EXPECT().ShadowBan(gomock.Any(), &models.AdminAction{ActorID: admin.ID, Reason: "vote ring"}, uint(4)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "shadow-ban by a user",
+			request:    handlertest.NewRequest(t, http.MethodPut, "/admin/users/4/shadow-ban").Vars(map[string]string{"id": "4"}).As(user),
+			serve:      func(h *shadowBansHandler) http.HandlerFunc { return h.ShadowBan },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "shadow bans",
+			request: handlertest.NewRequest(t, http.MethodGet, "/admin/shadow-bans?page_size=1").As(admin),
+			serve:   func(h *shadowBansHandler) http.HandlerFunc { return h.ListShadowBans },
+			expect: func(shadowBans *services.MockShadowBanServiceInterface) {
+				shadowBans.EXPECT().ListShadowBans(gomock.Any(), 1, 1).Return(&models.ShadowBanPage{
+					Data:       []models.ShadowBan{{UserID: 4, CreatedBy: admin.ID, CreatedAt: goldenTime, User: &shadowBanned}},
+					Page:       1,
+					PageSize:   1,
+					Total:      2,
+					TotalPages: 2,
+					HasNext:    true,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "shadow_bans_handler/shadow_bans",
+		},
+		{
+			name:       "shadow bans with a bad page size",
+			request:    handlertest.NewRequest(t, http.MethodGet, "/admin/shadow-bans?page_size=1000").As(admin),
+			serve:      func(h *shadowBansHandler) http.HandlerFunc { return h.ListShadowBans },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "lift",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/users/4/shadow-ban").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *shadowBansHandler) http.HandlerFunc { return h.Lift },
+			expect: func(shadowBans *services.MockShadowBanServiceInterface) {
+				shadowBans.EXPECT().Lift(gomock.Any(), &models.AdminAction{ActorID: admin.ID}, uint(4)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "lift a user not shadow-banned",
+			request: handlertest.NewRequest(t, http.MethodDelete, "/admin/users/4/shadow-ban").Vars(map[string]string{"id": "4"}).As(admin),
+			serve:   func(h *shadowBansHandler) http.HandlerFunc { return h.Lift },
+			expect: func(shadowBans *services.MockShadowBanServiceInterface) {
+				shadowBans.EXPECT().Lift(gomock.Any(), gomock.Any(), uint(4)).Return(repositories.ErrNotFound.AppendMessage("Shadow ban not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			shadowBans := services.NewMockShadowBanServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(shadowBans)
+			}
+			handler := NewShadowBansHandler(shadowBans, zap.NewNop().Sugar())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultActiveDays = 30
+	maxActiveDays     = 365
+)
+
+type statsHandler struct {
+	*BaseHandler
+	stats  services.StatsServiceInterface
+	logger *zap.SugaredLogger
+}
+
+func NewStatsHandler(stats services.StatsServiceInterface, logger *zap.SugaredLogger) *statsHandler {
+	return &statsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		stats:       stats,
+		logger:      logger,
+	}
+}
+
+// Stats returns the dashboard counters of the tenant; users who signed in within ?active_days= (30 by default)
+// count as active. The route requires the stats.read permission.
+func (h *statsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	activeDays := defaultActiveDays
+	if value := r.URL.Query().Get("active_days"); value != "" {
+		var err error
+		activeDays, err = strconv.Atoi(value)
+		if err != nil || activeDays <= 0 || activeDays > maxActiveDays {
+			h.sendError(w, r, errors.New("active_days should be in the range from 1 to "+strconv.Itoa(maxActiveDays)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := h.stats.Stats(r.Context(), time.Duration(activeDays)*24*time.Hour)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, stats, http.StatusOK)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestStatsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stats := services.NewMockStatsServiceInterface(ctrl)
+	handler := NewStatsHandler(stats, zap.NewNop().Sugar())
+	generatedAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	handlertest.NewRequest(t, http.MethodGet, "/admin/stats?active_days=0").As(handlertest.Admin).
+		Serve(handler.Stats).
+		AssertStatus(http.StatusBadRequest)
+
+	stats.EXPECT().Stats(gomock.Any(), 7*24*time.Hour).Return(&models.Stats{
+		TotalUsers:   120,
+		ActiveUsers:  45,
+		DeletedUsers: 6,
+		Signups:      models.SignupStats{Day: 2, Week: 9, Month: 31},
+		VotesCast:    870,
+		ActiveSince:  generatedAt.Add(-7 * 24 * time.Hour),
+		GeneratedAt:  generatedAt,
+	}, nil)
+	handlertest.NewRequest(t, http.MethodGet, "/admin/stats?active_days=7").As(handlertest.Admin).
+		Serve(handler.Stats).
+		AssertStatus(http.StatusOK).
+		AssertGolden("stats_handler/stats")
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+type termsHandler struct {
+	*BaseHandler
+	terms     services.TermsServiceInterface
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+}
+
+func NewTermsHandler(terms services.TermsServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate) *termsHandler {
+	return &termsHandler{
+		BaseHandler: NewBaseHandler(logger),
+		terms:       terms,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+// AcceptTermsRequest names the version the user read, so a stale page cannot accept a newer one
+type AcceptTermsRequest struct {
+	Version string `json:"version" validate:"required,max=64"`
+}
+
+// GetTerms returns the current terms version and the versions the caller accepted
+func (h *termsHandler) GetTerms(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.terms.Status(r.Context(), userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, status, http.StatusOK)
+}
+
+// AcceptTerms records that the caller accepted the current terms version
+func (h *termsHandler) AcceptTerms(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	acceptRequest := &AcceptTermsRequest{}
+	if err := h.decode(r, acceptRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := myValidate.ValidationError(h.validator, acceptRequest); err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	acceptance, err := h.terms.Accept(r.Context(), userID, acceptRequest.Version)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, acceptance, http.StatusOK)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestTermsHandler(t *testing.T) {
+	user := handlertest.User
+	acceptance := models.TermsAcceptance{UserID: user.ID, Version: "2024-01", AcceptedAt: goldenTime}
+
+	tests := []struct {
+		name       string
+		request    *handlertest.Request
+		serve      func(h *termsHandler) http.HandlerFunc
+		expect     func(terms *services.MockTermsServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:    "status",
+			request: handlertest.NewRequest(t, http.MethodGet, "/me/terms").As(user),
+			serve:   func(h *termsHandler) http.HandlerFunc { return h.GetTerms },
+			expect: func(terms *services.MockTermsServiceInterface) {
+				terms.EXPECT().Status(gomock.Any(), user.ID).Return(&models.TermsStatus{CurrentVersion: "2024-05", Acceptances: []models.TermsAcceptance{acceptance}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			golden:     "terms_handler/status",
+		},
+		{
+			name:    "accept",
+			request: handlertest.NewRequest(t, http.MethodPost, "/me/terms").JSON(map[string]string{"version": "2024-01"}).As(user),
+			serve:   func(h *termsHandler) http.HandlerFunc { return h.AcceptTerms },
+			expect: func(terms *services.MockTermsServiceInterface) {
+				terms.EXPECT().Accept(gomock.Any(), user.ID, "2024-01").Return(&acceptance, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "accept an outdated version",
+			request: handlertest.NewRequest(t, http.MethodPost, "/me/terms").JSON(map[string]string{"version": "2023-12"}).As(user),
+			serve:   func(h *termsHandler) http.HandlerFunc { return h.AcceptTerms },
+			expect: func(terms *services.MockTermsServiceInterface) {
+				terms.EXPECT().Accept(gomock.Any(), user.ID, "2023-12").Return(nil, apperrors.ConflictErr.AppendMessage("the current terms version is 2024-01"))
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   apperrors.ConflictErr.Code,
+		},
+		{
+			name:       "accept without a version",
+			request:    handlertest.NewRequest(t, http.MethodPost, "/me/terms").JSON(map[string]string{}).As(user),
+			serve:      func(h *termsHandler) http.HandlerFunc { return h.AcceptTerms },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ValidationFailedErr.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			terms := services.NewMockTermsServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(terms)
+			}
+			handler := NewTermsHandler(terms, zap.NewNop().Sugar(), newFuzzValidator())
+
+			response := tt.request.Serve(tt.serve(handler)).AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
+	}
+}
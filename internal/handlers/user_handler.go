@@ -9,28 +9,45 @@ import (
 
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
 	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
 	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
 	"go.uber.org/zap"
+	"golang.org/x/text/language"
 )
 
 type userHandler struct {
 	*BaseHandler
-	userService services.UserServiceInterface
+	userService  services.UserServiceInterface
+	featureFlags services.FeatureFlagServiceInterface
+	audit        services.AdminAuditServiceInterface
+	// securityEvents records the password changes a user sees under /me/security-events
+	securityEvents services.SecurityEventServiceInterface
+	// shadowBans hides shadow-banned users from the public profile
+	shadowBans  services.ShadowBanServiceInterface
+	signupRoles services.SignupRoles
 	logger      *zap.SugaredLogger
 	validator   *validator.Validate
 	cfg         *config.Config
 }
 
-func NewUserHandler(userService services.UserServiceInterface, logger *zap.SugaredLogger, validator *validator.Validate, cfg *config.Config) *userHandler {
+func NewUserHandler(userService services.UserServiceInterface, featureFlags services.FeatureFlagServiceInterface, audit services.AdminAuditServiceInterface, securityEvents services.SecurityEventServiceInterface, shadowBans services.ShadowBanServiceInterface, signupRoles services.SignupRoles, logger *zap.SugaredLogger, validator *validator.Validate, cfg *config.Config) *userHandler {
 	return &userHandler{
-		BaseHandler: NewBaseHandler(logger),
-		userService: userService,
-		logger:      logger,
-		validator:   validator,
-		cfg:         cfg,
+		BaseHandler:    NewBaseHandler(logger),
+		userService:    userService,
+		featureFlags:   featureFlags,
+		audit:          audit,
+		securityEvents: securityEvents,
+		shadowBans:     shadowBans,
+		signupRoles:    signupRoles,
+		logger:         logger,
+		validator:      validator,
+		cfg:            cfg,
 	}
 }
 
@@ -40,15 +57,36 @@ const (
 	maxPageSize     = 1000
 )
 
+// ErrorResponse is the body of every error; Code is stable and listed by GET /errors, Message is for humans
 type ErrorResponse struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
+	// Fields lists every invalid field of a VALIDATION_ERR
+	Fields []apperrors.FieldViolation `json:"fields,omitempty"`
+	// Causes and Stack are only sent in the development profile
+	Causes []string `json:"causes,omitempty"`
+	Stack  []string `json:"stack,omitempty"`
 }
+
+// CreateUserRequest is the body of a signup; new users always get the user role
 type CreateUserRequest struct {
-	Email     string `json:"email" validate:"required,email"`
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
+	Email     string `json:"email" validate:"required,email,max=254"`
+	FirstName string `json:"first_name" validate:"required,max=100"`
+	LastName  string `json:"last_name" validate:"required,max=100"`
 	Password  string `json:"password" validate:"required,min=8,password"`
+	Timezone  string `json:"timezone" validate:"omitempty,timezone"`
+	Locale    string `json:"locale" validate:"omitempty,locale"`
+}
+
+// UpdateUserRequest changes the fields it has and leaves the others; only admins may change the role
+type UpdateUserRequest struct {
+	Email     string `json:"email" validate:"omitempty,email,max=254"`
+	FirstName string `json:"first_name" validate:"omitempty,max=100"`
+	LastName  string `json:"last_name" validate:"omitempty,max=100"`
+	Password  string `json:"password" validate:"omitempty,min=8,password"`
 	RoleID    uint   `json:"role_id" validate:"omitempty,oneof=1 2 3"`
+	Timezone  string `json:"timezone" validate:"omitempty,timezone"`
+	Locale    string `json:"locale" validate:"omitempty,locale"`
 }
 
 func (h *userHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -56,23 +94,27 @@ func (h *userHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request)
 		UserId string `json:"user_id"`
 	}
 
+	if !h.requireFeature(w, r, h.featureFlags, models.FeatureRegistration) {
+		return
+	}
+
 	createUserRequest := &CreateUserRequest{}
 	err := h.decode(r, createUserRequest)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
 	err = h.ValidateUserStruct(r.Context(), createUserRequest)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 
 	}
 
 	hash, err := passwords.HashPassword(createUserRequest.Password)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
@@ -81,12 +123,14 @@ func (h *userHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request)
 		FirstName: createUserRequest.FirstName,
 		LastName:  createUserRequest.LastName,
 		Password:  hash,
-		RoleID:    1, // bad approach
+		RoleID:    h.signupRoles.RoleID(models.SignupSelf),
+		Timezone:  createUserRequest.Timezone,
+		Locale:    createUserRequest.Locale,
 	}
 
 	userId, err := h.userService.CreateUser(r.Context(), user)
 	if err != nil {
-		h.sendError(w, err, http.StatusInternalServerError)
+		h.sendError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -103,17 +147,19 @@ func (h *userHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["id"]
 
-	ctx := r.Context()
-	role := h.GetAuthenticatedRole(ctx)
-
-	if role != models.StrAdmin {
-		h.sendError(w, errors.New("premission is denided"), http.StatusBadRequest)
+	actorID, ok := h.adminUser(w, r)
+	if !ok {
+		return
+	}
+	by, err := adminAction(r, actorID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
-	user, err := h.userService.DeleteUser(r.Context(), userID)
+	user, err := h.audit.DeleteUser(r.Context(), by, userID)
 	if err != nil {
-		h.sendError(w, err, http.StatusInternalServerError)
+		h.sendError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 	res := &CreateUserResponse{
@@ -129,46 +175,78 @@ func (h *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	role := h.GetAuthenticatedRole(ctx)
 
-	if role != models.StrAdmin && userID != vars["id"] {
-		h.sendError(w, errors.New("premission is denided"), http.StatusBadRequest)
+	if role != models.StrAdmin && userID != h.GetAuthenticatedUserID(ctx) {
+		h.sendError(w, r, apperrors.ForbiddenErr.AppendMessage("only admins update other users"), http.StatusForbidden)
 		return
 	}
 
-	createUserRequest := &CreateUserRequest{}
-	err := h.decode(r, createUserRequest)
+	updateUserRequest := &UpdateUserRequest{}
+	err := h.decode(r, updateUserRequest)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
-	// Validate the User struct
-	err = h.validator.Struct(createUserRequest)
+	err = myValidate.ValidationError(h.validator, updateUserRequest)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
-
-	hash, err := passwords.HashPassword(createUserRequest.Password)
-	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+	if *updateUserRequest == (UpdateUserRequest{}) {
+		h.sendError(w, r, apperrors.BadRequestErr.AppendMessage("the request changes nothing"), http.StatusBadRequest)
+		return
+	}
+	if updateUserRequest.RoleID > 0 && role != models.StrAdmin {
+		h.sendError(w, r, errors.New("only admins can change the role"), http.StatusForbidden)
 		return
 	}
 
 	updatedData := &models.User{
-		Email:     createUserRequest.Email,
-		FirstName: createUserRequest.FirstName,
-		LastName:  createUserRequest.LastName,
-		Password:  hash,
+		Email:     updateUserRequest.Email,
+		FirstName: updateUserRequest.FirstName,
+		LastName:  updateUserRequest.LastName,
+		RoleID:    updateUserRequest.RoleID,
+		Timezone:  updateUserRequest.Timezone,
+		Locale:    updateUserRequest.Locale,
+	}
+	if updateUserRequest.Password != "" {
+		updatedData.Password, err = passwords.HashPassword(updateUserRequest.Password)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusBadRequest)
+			return
+		}
 	}
 
-	if role == models.StrAdmin && createUserRequest.RoleID > 0 {
-		updatedData.RoleID = createUserRequest.RoleID
+	// Admins changing another user or a role go through the admin audit
+	if role == models.StrAdmin && (userID != h.GetAuthenticatedUserID(ctx) || updatedData.RoleID > 0) {
+		actorID, ok := h.authenticatedUser(w, r)
+		if !ok {
+			return
+		}
+		by, err := adminAction(r, actorID)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		user, err := h.audit.UpdateUser(ctx, by, userID, updatedData)
+		if err != nil {
+			h.sendError(w, r, err, http.StatusNotFound)
+			return
+		}
+		if updatedData.Password != "" {
+			h.securityEvents.Record(ctx, user.ID, models.SecurityEventPasswordChanged, "by an admin")
+		}
+		h.respond(w, nil, http.StatusCreated)
+		return
 	}
 
-	_, err = h.userService.UpdateUser(ctx, userID, updatedData)
+	user, err := h.userService.UpdateUser(ctx, userID, updatedData)
 	if err != nil {
-		h.sendError(w, err, http.StatusNotFound)
+		h.sendError(w, r, err, http.StatusNotFound)
 		return
 	}
+	if updatedData.Password != "" {
+		h.securityEvents.Record(ctx, user.ID, models.SecurityEventPasswordChanged, "")
+	}
 
 	h.respond(w, nil, http.StatusCreated)
 }
@@ -180,11 +258,57 @@ func (h *userHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userService.GetUser(ctx, userID)
 	if err != nil {
-		h.sendError(w, err, http.StatusNotFound)
+		h.sendError(w, r, err, http.StatusNotFound)
+		return
+	}
+	// A shadow-banned user is not found by others; they still see themselves, here as under /me
+	if h.GetAuthenticatedUserID(ctx) == strconv.FormatUint(uint64(user.ID), 10) {
+		h.respond(w, NewUserResponse(user), http.StatusCreated)
+		return
+	}
+	hidden, err := h.shadowBans.IsShadowBanned(ctx, user.ID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if hidden {
+		h.sendError(w, r, apperrors.NoRecordFoundErr.AppendMessage("User not found."), http.StatusNotFound)
 		return
 	}
 
-	h.respond(w, user, http.StatusCreated)
+	h.respond(w, NewUserResponse(user), http.StatusCreated)
+}
+
+// Me returns the logged-in user, including the timezone and locale their emails are written in
+func (h *userHandler) Me(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, err := h.userService.GetUser(ctx, h.GetAuthenticatedUserID(ctx))
+	if err != nil {
+		h.sendError(w, r, err, http.StatusNotFound)
+		return
+	}
+
+	h.respond(w, NewUserResponse(user), http.StatusOK)
+}
+
+// GetUserHistory lists the prior versions of a user recorded on every update and deletion (admin only)
+func (h *userHandler) GetUserHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	ctx := r.Context()
+
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	history, err := h.userService.GetUserHistory(ctx, userID)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respond(w, history, http.StatusOK)
 }
 
 func (h *userHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
@@ -196,26 +320,67 @@ func (h *userHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 	intPage, intPageSize, err := h.validateListUsersParam(page, pageSize)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if queryParams.Has("updated_since") {
+		h.listUserChanges(w, r, intPageSize)
+		return
+	}
+	filters, err := filter.Parse(queryParams)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	usersPage, err := h.userService.ListUsers(ctx, intPage, intPageSize, filters)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
-	users, err := h.userService.ListUsers(ctx, intPage, intPageSize)
+
+	h.respond(w, NewUserPageResponse(usersPage), http.StatusOK)
+}
+
+// listUserChanges serves GET /users?updated_since=<RFC 3339 time>[&after_id=<id>], the users changed after the
+// cursor with tombstones for deleted ones; clients continue from the cursor returned with each page
+func (h *userHandler) listUserChanges(w http.ResponseWriter, r *http.Request, pageSize int) {
+	queryParams := r.URL.Query()
+	since, err := time.Parse(time.RFC3339Nano, queryParams.Get("updated_since"))
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, errors.New("updated_since should be an RFC 3339 time"), http.StatusBadRequest)
 		return
 	}
+	var afterID uint64
+	if queryParams.Get("after_id") != "" {
+		afterID, err = strconv.ParseUint(queryParams.Get("after_id"), 10, 32)
+		if err != nil {
+			h.sendError(w, r, errors.New("after_id should be a user ID"), http.StatusBadRequest)
+			return
+		}
+	}
 
-	h.respond(w, users, http.StatusOK)
+	changes, err := h.userService.ListUserChanges(r.Context(), since, uint(afterID), pageSize)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, NewUserChangesPageResponse(changes), http.StatusOK)
 }
 
+// CountUsers counts the users matching the filter[...] parameters of ListUsers, all users without any
 func (h *userHandler) CountUsers(w http.ResponseWriter, r *http.Request) {
 	type CreateUserResponse struct {
 		Count uint `json:"count"`
 	}
 	ctx := r.Context()
-	count, err := h.userService.CountUsers(ctx)
+	filters, err := filter.Parse(r.URL.Query())
+	if err != nil {
+		h.sendError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	count, err := h.userService.CountUsers(ctx, filters)
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 	res := &CreateUserResponse{
@@ -251,18 +416,41 @@ func (h *userHandler) validateListUsersParam(page, pageSize string) (validPage,
 	return validPage, validPageSize, nil
 }
 
+// ValidateUserStruct reports every invalid field of the request at once, including an email already in use
 func (h *userHandler) ValidateUserStruct(ctx context.Context, createUserRequest *CreateUserRequest) error {
 	// Validate the User struct
 	err := h.validator.Struct(createUserRequest)
-	if err != nil {
+	violations := myValidate.Violations(createUserRequest, err)
+	if err != nil && violations == nil {
 		return err
 	}
 
-	// Check if email is unique
-	existingUser, _ := h.userService.GetUserByEmail(ctx, createUserRequest.Email)
-	if existingUser != nil {
-		err := errors.New("email already in use")
-		return err
+	// Check if email is unique, unless it is invalid anyway
+	if !hasViolation(violations, "email") {
+		_, err = h.userService.GetUserByEmail(ctx, createUserRequest.Email)
+		if err == nil {
+			violations = append(violations, apperrors.FieldViolation{
+				Field:     "email",
+				Rule:      "unique",
+				Message:   i18n.Message(language.English, "validation.unique"),
+				MessageID: "validation.unique",
+			})
+		} else if !errors.Is(err, &apperrors.NoRecordFoundErr) {
+			return err
+		}
+	}
+
+	if len(violations) > 0 {
+		return apperrors.ValidationFailedErr.WithFields(violations...)
 	}
 	return nil
 }
+
+func hasViolation(violations []apperrors.FieldViolation, field string) bool {
+	for _, violation := range violations {
+		if violation.Field == field {
+			return true
+		}
+	}
+	return false
+}
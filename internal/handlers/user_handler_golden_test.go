@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/golang/mock/gomock"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+	"golang.org/x/text/language"
+)
+
+// goldenTime keeps the timestamps in the golden files fixed
+var goldenTime = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// goldenUser has a password hash and a tenant, which the golden files show never leave the server
+func goldenUser(id uint, email string) models.User {
+	return models.User{
+		ID:        id,
+		TenantID:  7,
+		Email:     email,
+		Password:  "$2a$10$goldenhash",
+		FirstName: "John",
+		LastName:  "Doe",
+		Role:      models.Role{ID: 1, Name: models.StrUser},
+		CreatedAt: goldenTime,
+		UpdatedAt: goldenTime,
+		Rating:    3,
+	}
+}
+
+func TestUserHandler_Responses(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func(t *testing.T) *handlertest.Request
+		serve   func(h *userHandler) http.HandlerFunc
+		// expect sets up the service calls the case makes
+		expect     func(userService *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface)
+		wantStatus int
+	}{
+		{
+			name: "get user",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users/12").Vars(map[string]string{"id": "12"}).As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.GetUser },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				user := goldenUser(12, "john@example.com")
+				userService.EXPECT().GetUser(gomock.Any(), "12").Return(&user, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "get missing user",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users/12").Vars(map[string]string{"id": "12"}).As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.GetUser },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				userService.EXPECT().GetUser(gomock.Any(), "12").Return(nil, apperrors.NoRecordFoundErr.AppendMessage("No user found with the given ID."))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "me",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/me").As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.Me },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				user := goldenUser(handlertest.User.ID, handlertest.User.Email)
+				user.Timezone, user.Locale = "Europe/Kyiv", "uk-UA"
+				userService.EXPECT().GetUser(gomock.Any(), "1").Return(&user, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "list users",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users?page=2&page_size=2").As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.ListUsers },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				userService.EXPECT().ListUsers(gomock.Any(), 2, 2, gomock.Nil()).Return(&models.UserPage{
+					Data:       []models.User{goldenUser(3, "c@example.com"), goldenUser(4, "d@example.com")},
+					Page:       2,
+					PageSize:   2,
+					Total:      5,
+					TotalPages: 3,
+					HasNext:    true,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "list users with filters",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users?filter[role]=admin&filter[created_at][gte]=2024-01-01").As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.ListUsers },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				userService.EXPECT().ListUsers(gomock.Any(), 1, 10, []filter.Condition{
+					{Field: "created_at", Operator: filter.Gte, Value: "2024-01-01"},
+					{Field: "role", Operator: filter.Eq, Value: "admin"},
+				}).Return(&models.UserPage{
+					Data:       []models.User{goldenUser(3, "c@example.com")},
+					Page:       1,
+					PageSize:   10,
+					Total:      1,
+					TotalPages: 1,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "list users with a malformed filter",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users?filter[role][like]=adm").As(handlertest.User)
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.ListUsers },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "count users with filters",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users/count?filter[role]=admin&filter[created_at][lt]=2024-01-01").As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CountUsers },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				userService.EXPECT().CountUsers(gomock.Any(), []filter.Condition{
+					{Field: "created_at", Operator: filter.Lt, Value: "2024-01-01"},
+					{Field: "role", Operator: filter.Eq, Value: "admin"},
+				}).Return(4, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "count users with an unknown field",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users/count?filter[password]=secret").As(handlertest.User)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CountUsers },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				userService.EXPECT().CountUsers(gomock.Any(), gomock.Any()).
+					Return(0, apperrors.BadRequestErr.AppendMessage("users can not be filtered by password"))
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "list users with a bad page size",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users?page_size=0").As(handlertest.User)
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.ListUsers },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "list user changes",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users?updated_since=2024-03-01T12:00:00Z&after_id=2&page_size=2")
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.ListUsers },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				user := goldenUser(3, "c@example.com")
+				userService.EXPECT().ListUserChanges(gomock.Any(), goldenTime, uint(2), 2).Return(&models.UserChangesPage{
+					Data: []models.UserChange{
+						{UserID: 3, UpdatedAt: goldenTime, User: &user},
+						{UserID: 4, UpdatedAt: goldenTime.Add(time.Minute), Deleted: true},
+					},
+					HasMore:      true,
+					UpdatedSince: goldenTime.Add(time.Minute),
+					AfterID:      4,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "list user changes with a bad timestamp",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users?updated_since=yesterday")
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.ListUsers },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "history as user",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users/12/history").Vars(map[string]string{"id": "12"}).As(handlertest.User)
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.GetUserHistory },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "history as admin",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodGet, "/users/12/history").Vars(map[string]string{"id": "12"}).As(handlertest.Admin)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.GetUserHistory },
+			expect: func(userService *services.MockUserServiceInterface, _ *services.MockFeatureFlagServiceInterface) {
+				userService.EXPECT().GetUserHistory(gomock.Any(), "12").Return([]models.UserHistory{{
+					ID:        1,
+					UserID:    12,
+					Operation: models.HistoryOperationUpdate,
+					Email:     "john@example.com",
+					FirstName: "Johnny",
+					LastName:  "Doe",
+					RoleID:    1,
+					ValidFrom: goldenTime,
+					ChangedAt: goldenTime.Add(time.Hour),
+				}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "register with invalid fields",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").JSON(`{"email":"not-an-email","first_name":"John","password":"short"}`)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(_ *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with an unknown timezone and locale",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").
+					JSON(`{"email":"john@example.com","first_name":"John","last_name":"Doe","password":"password@123","timezone":"Mars/Olympus","locale":"not a tag"}`)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(userService *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+				userService.EXPECT().GetUserByEmail(gomock.Any(), "john@example.com").Return(nil, &apperrors.NoRecordFoundErr)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with invalid fields in Ukrainian",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").
+					JSON(`{"email":"not-an-email","first_name":"John","password":"short"}`).
+					Language(language.Ukrainian)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(_ *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with an unknown field",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").
+					JSON(`{"email":"john@example.com","first_name":"John","last_name":"Doe","password":"password@123","role_id":3}`)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(_ *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with a wrong type",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").
+					JSON(`{"email":"john@example.com","first_name":42,"last_name":"Doe","password":"password@123"}`)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(_ *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with trailing data",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").
+					JSON(`{"email":"john@example.com","first_name":"John","last_name":"Doe","password":"password@123"} {}`)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(_ *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with invalid JSON",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPost, "/users").JSON(`{"email":`)
+			},
+			serve: func(h *userHandler) http.HandlerFunc { return h.CreateUserHandler },
+			expect: func(_ *services.MockUserServiceInterface, featureFlags *services.MockFeatureFlagServiceInterface) {
+				featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update with an empty body",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPut, "/users/1").Vars(map[string]string{"id": "1"}).As(handlertest.User).JSON(`{}`)
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.UpdateUser },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update role as user",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPut, "/users/1").Vars(map[string]string{"id": "1"}).As(handlertest.User).JSON(`{"role_id":3}`)
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.UpdateUser },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "update another user as user",
+			request: func(t *testing.T) *handlertest.Request {
+				return handlertest.NewRequest(t, http.MethodPut, "/users/2").Vars(map[string]string{"id": "2"}).As(handlertest.User).JSON(`{"password":"Secret123!"}`)
+			},
+			serve:      func(h *userHandler) http.HandlerFunc { return h.UpdateUser },
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	validate := validator.New()
+	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			userService := services.NewMockUserServiceInterface(ctrl)
+			featureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+			if tt.expect != nil {
+				tt.expect(userService, featureFlags)
+			}
+			shadowBans := services.NewMockShadowBanServiceInterface(ctrl)
+			shadowBans.EXPECT().IsShadowBanned(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+			handler := NewUserHandler(userService, featureFlags, nil, nil, shadowBans, testSignupRoles, zap.NewNop().Sugar(), validate, &config.Config{})
+
+			tt.request(t).
+				Serve(tt.serve(handler)).
+				AssertStatus(tt.wantStatus).
+				AssertGolden("user_handler/" + strings.ReplaceAll(tt.name, " ", "_"))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,14 +14,20 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 
 	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
 	"gitlab.com/jkozhemiaka/web-layout/internal/services"
 	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
 	"go.uber.org/zap"
+	"golang.org/x/text/language"
 )
 
+// testSignupRoles gives self-registered users the user role, like the default DEFAULT_ROLE
+var testSignupRoles = services.SignupRoles{models.SignupSelf: 1, models.SignupSSO: 1, models.SignupOAuth: 1, models.SignupPhone: 1}
+
 func TestCreateUserHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -31,17 +38,20 @@ func TestCreateUserHandler(t *testing.T) {
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	cfg := &config.Config{}
 
-	handler := NewUserHandler(mockUserService, logger, validate, cfg)
+	mockFeatureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+	mockFeatureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+	handler := NewUserHandler(mockUserService, mockFeatureFlags, nil, nil, nil, testSignupRoles, logger, validate, cfg)
 
 	reqBody := &CreateUserRequest{
 		Email:     "test@example.com",
 		FirstName: "John",
 		LastName:  "Doe",
 		Password:  "password@123",
-		RoleID:    1,
 	}
 
 	reqBodyBytes, _ := json.Marshal(reqBody)
@@ -51,7 +61,7 @@ func TestCreateUserHandler(t *testing.T) {
 
 	// Mock the service response
 	mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(uint(12345), nil)
-	mockUserService.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockUserService.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Return(nil, &apperrors.NoRecordFoundErr)
 
 	handler.CreateUserHandler(w, req)
 
@@ -69,6 +79,98 @@ func TestCreateUserHandler(t *testing.T) {
 	assert.Equal(t, "12345", response.UserId)
 }
 
+func TestCreateUserHandler_ReportsEveryInvalidField(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validate := validator.New()
+	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
+
+	mockUserService := services.NewMockUserServiceInterface(ctrl)
+	mockFeatureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+	mockFeatureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(true)
+	handler := NewUserHandler(mockUserService, mockFeatureFlags, nil, nil, nil, testSignupRoles, zap.NewExample().Sugar(), validate, &config.Config{})
+
+	// The email is valid but taken, so it is reported next to the other fields
+	mockUserService.EXPECT().GetUserByEmail(gomock.Any(), "taken@example.com").Return(&models.User{ID: 1}, nil)
+
+	body := `{"email":"taken@example.com","first_name":"John","password":"short"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(body)))
+	w := httptest.NewRecorder()
+
+	handler.CreateUserHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	var response ErrorResponse
+	_ = json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, apperrors.ValidationFailedErr.Code, response.Code)
+	assert.Equal(t, []apperrors.FieldViolation{
+		{Field: "last_name", Rule: "required", Message: "is required"},
+		{Field: "password", Rule: "min", Message: "must be at least 8 characters long"},
+		{Field: "email", Rule: "unique", Message: "is already in use"},
+	}, response.Fields)
+}
+
+func TestCreateUserHandler_RegistrationDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := services.NewMockUserServiceInterface(ctrl)
+	mockFeatureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+	mockFeatureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(false)
+
+	handler := NewUserHandler(mockUserService, mockFeatureFlags, nil, nil, nil, testSignupRoles, zap.NewExample().Sugar(), validator.New(), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(`{"email":"test@example.com"}`)))
+	w := httptest.NewRecorder()
+
+	handler.CreateUserHandler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+	var response ErrorResponse
+	_ = json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, apperrors.FeatureDisabledErr.Code, response.Code)
+}
+
+func TestCreateUserHandler_LocalizedError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFeatureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+	mockFeatureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureRegistration, uint(0)).Return(false)
+
+	handler := NewUserHandler(services.NewMockUserServiceInterface(ctrl), mockFeatureFlags, nil, nil, nil, testSignupRoles, zap.NewExample().Sugar(), validator.New(), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(`{"email":"test@example.com"}`)))
+	req = req.WithContext(i18n.WithLanguage(req.Context(), language.Ukrainian))
+	w := httptest.NewRecorder()
+
+	handler.CreateUserHandler(w, req)
+
+	var response ErrorResponse
+	_ = json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, apperrors.FeatureDisabledErr.Code, response.Code, "the code is not translated")
+	assert.Equal(t, apperrors.FeatureDisabledErr.Code+": Ця функція зараз вимкнена", response.Message)
+}
+
+func TestNewErrorResponse_StackInDevelopment(t *testing.T) {
+	err := apperrors.QueryFailedErr.AppendMessage(errors.New("connection refused"))
+
+	response := NewErrorResponse(err, http.StatusInternalServerError, language.English)
+	assert.Empty(t, response.Causes, "details are left out unless stacks are captured")
+	assert.Empty(t, response.Stack)
+
+	apperrors.CaptureStacks(true)
+	t.Cleanup(func() { apperrors.CaptureStacks(false) })
+	err = apperrors.QueryFailedErr.AppendMessage(errors.New("connection refused"))
+
+	response = NewErrorResponse(err, http.StatusInternalServerError, language.English)
+	assert.Equal(t, []string{"connection refused"}, response.Causes)
+	assert.NotEmpty(t, response.Stack)
+}
+
 func TestDeleteUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -79,22 +181,27 @@ func TestDeleteUser(t *testing.T) {
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	cfg := &config.Config{}
 
-	handler := NewUserHandler(mockUserService, logger, validate, cfg)
+	mockAudit := services.NewMockAdminAuditServiceInterface(ctrl)
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), mockAudit, nil, nil, testSignupRoles, logger, validate, cfg)
 
 	req := httptest.NewRequest(http.MethodDelete, "/users/123", nil)
+	req.Header.Set("X-Audit-Reason", "spam account")
 	req = mux.SetURLVars(req, map[string]string{"id": "123"})
 	w := httptest.NewRecorder()
 
-	// Mock the role
+	// Mock the admin
 	ctx := context.WithValue(req.Context(), models.RoleContextKey, models.StrAdmin)
+	ctx = context.WithValue(ctx, models.IDContextKey, "7")
 	req = req.WithContext(ctx)
 
 	// Mock the service response
 	deletedUser := &models.User{ID: 123, DeletedAt: time.Now()}
-	mockUserService.EXPECT().DeleteUser(gomock.Any(), "123").Return(deletedUser, nil)
+	mockAudit.EXPECT().DeleteUser(gomock.Any(), &models.AdminAction{ActorID: 7, Reason: "spam account"}, "123").Return(deletedUser, nil)
 
 	handler.DeleteUser(w, req)
 
@@ -114,10 +221,14 @@ func TestGetUser(t *testing.T) {
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	cfg := &config.Config{}
 
-	handler := NewUserHandler(mockUserService, logger, validate, cfg)
+	mockShadowBans := services.NewMockShadowBanServiceInterface(ctrl)
+
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), nil, nil, mockShadowBans, testSignupRoles, logger, validate, cfg)
 
 	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
 	req = mux.SetURLVars(req, map[string]string{"id": "123"})
@@ -126,6 +237,7 @@ func TestGetUser(t *testing.T) {
 	// Mock the service response
 	expectedUser := &models.User{ID: 123, Email: "test@example.com"}
 	mockUserService.EXPECT().GetUser(gomock.Any(), "123").Return(expectedUser, nil)
+	mockShadowBans.EXPECT().IsShadowBanned(gomock.Any(), uint(123)).Return(false, nil)
 
 	handler.GetUser(w, req)
 
@@ -140,6 +252,74 @@ func TestGetUser(t *testing.T) {
 	assert.Equal(t, "test@example.com", user.Email)
 }
 
+func TestGetUser_HidesShadowBannedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := services.NewMockUserServiceInterface(ctrl)
+	mockUserService.EXPECT().GetUser(gomock.Any(), "123").Return(&models.User{ID: 123, Email: "test@example.com"}, nil)
+	mockShadowBans := services.NewMockShadowBanServiceInterface(ctrl)
+	mockShadowBans.EXPECT().IsShadowBanned(gomock.Any(), uint(123)).Return(true, nil)
+
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), nil, nil, mockShadowBans, testSignupRoles, zap.NewExample().Sugar(), validator.New(), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "123"})
+	w := httptest.NewRecorder()
+
+	handler.GetUser(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	var response ErrorResponse
+	_ = json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, apperrors.NoRecordFoundErr.Code, response.Code)
+}
+
+func TestGetUser_ShowsShadowBannedUsersThemselves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := services.NewMockUserServiceInterface(ctrl)
+	mockUserService.EXPECT().GetUser(gomock.Any(), "123").Return(&models.User{ID: 123, Email: "test@example.com"}, nil)
+	// No IsShadowBanned call is expected: their own profile is never hidden from them
+	mockShadowBans := services.NewMockShadowBanServiceInterface(ctrl)
+
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), nil, nil, mockShadowBans, testSignupRoles, zap.NewExample().Sugar(), validator.New(), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "123"})
+	req = req.WithContext(context.WithValue(req.Context(), models.IDContextKey, "123"))
+	w := httptest.NewRecorder()
+
+	handler.GetUser(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	var user models.User
+	_ = json.NewDecoder(w.Body).Decode(&user)
+	assert.Equal(t, uint(123), user.ID)
+}
+
+func TestGetUser_StatusFromAppError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := services.NewMockUserServiceInterface(ctrl)
+	mockUserService.EXPECT().GetUser(gomock.Any(), "123").Return(nil, apperrors.TimeoutErr.AppendMessage(context.DeadlineExceeded))
+
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), nil, nil, nil, testSignupRoles, zap.NewExample().Sugar(), validator.New(), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "123"})
+	w := httptest.NewRecorder()
+
+	handler.GetUser(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Result().StatusCode, "the error's status wins over the handler's")
+	var response ErrorResponse
+	_ = json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, apperrors.TimeoutErr.Code, response.Code)
+}
+
 func TestListUsers(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -150,10 +330,12 @@ func TestListUsers(t *testing.T) {
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	cfg := &config.Config{}
 
-	handler := NewUserHandler(mockUserService, logger, validate, cfg)
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), nil, nil, nil, testSignupRoles, logger, validate, cfg)
 
 	req := httptest.NewRequest(http.MethodGet, "/users", nil)
 	w := httptest.NewRecorder()
@@ -163,7 +345,13 @@ func TestListUsers(t *testing.T) {
 		{ID: 1, Email: "test1@example.com"},
 		{ID: 2, Email: "test2@example.com"},
 	}
-	mockUserService.EXPECT().ListUsers(gomock.Any(), defaultPage, defaultPageSize).Return(users, nil)
+	mockUserService.EXPECT().ListUsers(gomock.Any(), defaultPage, defaultPageSize, gomock.Nil()).Return(&models.UserPage{
+		Data:       users,
+		Page:       defaultPage,
+		PageSize:   defaultPageSize,
+		Total:      2,
+		TotalPages: 1,
+	}, nil)
 
 	handler.ListUsers(w, req)
 
@@ -172,9 +360,11 @@ func TestListUsers(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 
-	var returnedUsers []*models.User
-	_ = json.NewDecoder(res.Body).Decode(&returnedUsers)
-	assert.Len(t, returnedUsers, 2)
+	var returnedPage models.UserPage
+	_ = json.NewDecoder(res.Body).Decode(&returnedPage)
+	assert.Len(t, returnedPage.Data, 2)
+	assert.Equal(t, 2, returnedPage.Total)
+	assert.False(t, returnedPage.HasNext)
 }
 
 func TestCountUsers(t *testing.T) {
@@ -187,16 +377,18 @@ func TestCountUsers(t *testing.T) {
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	cfg := &config.Config{}
 
-	handler := NewUserHandler(mockUserService, logger, validate, cfg)
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), nil, nil, nil, testSignupRoles, logger, validate, cfg)
 
 	req := httptest.NewRequest(http.MethodGet, "/users/count", nil)
 	w := httptest.NewRecorder()
 
 	// Mock the service response
-	mockUserService.EXPECT().CountUsers(gomock.Any()).Return(123, nil)
+	mockUserService.EXPECT().CountUsers(gomock.Any(), gomock.Nil()).Return(123, nil)
 
 	handler.CountUsers(w, req)
 
@@ -224,12 +416,16 @@ func TestUpdateUser(t *testing.T) {
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	cfg := &config.Config{}
 
-	handler := NewUserHandler(mockUserService, logger, validate, cfg)
+	mockAudit := services.NewMockAdminAuditServiceInterface(ctrl)
+	mockSecurityEvents := services.NewMockSecurityEventServiceInterface(ctrl)
+	handler := NewUserHandler(mockUserService, services.NewMockFeatureFlagServiceInterface(ctrl), mockAudit, mockSecurityEvents, nil, testSignupRoles, logger, validate, cfg)
 
-	reqBody := &CreateUserRequest{
+	reqBody := &UpdateUserRequest{
 		Email:     "test@example.com",
 		FirstName: "John",
 		LastName:  "Doe",
@@ -245,10 +441,12 @@ func TestUpdateUser(t *testing.T) {
 
 	// Mock the administrator role
 	ctx := context.WithValue(req.Context(), models.RoleContextKey, models.StrAdmin)
+	ctx = context.WithValue(ctx, models.IDContextKey, "7")
 	req = req.WithContext(ctx)
 
 	// Mock the service response
-	mockUserService.EXPECT().UpdateUser(gomock.Any(), "123", gomock.Any()).Return(nil, nil)
+	mockAudit.EXPECT().UpdateUser(gomock.Any(), &models.AdminAction{ActorID: 7}, "123", gomock.Any()).Return(&models.User{ID: 123}, nil)
+	mockSecurityEvents.EXPECT().Record(gomock.Any(), uint(123), models.SecurityEventPasswordChanged, "by an admin")
 
 	handler.UpdateUser(w, req)
 
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/buildinfo"
+	"go.uber.org/zap"
+)
+
+type versionHandler struct {
+	*BaseHandler
+	logger *zap.SugaredLogger
+}
+
+func NewVersionHandler(logger *zap.SugaredLogger) *versionHandler {
+	return &versionHandler{
+		BaseHandler: NewBaseHandler(logger),
+		logger:      logger,
+	}
+}
+
+// Version returns the version, commit and build time of the running binary
+func (h *versionHandler) Version(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, buildinfo.Read(), http.StatusOK)
+}
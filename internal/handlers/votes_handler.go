@@ -15,20 +15,28 @@ import (
 
 type votesHandler struct {
 	*BaseHandler
-	userService services.UserServiceInterface
-	logger      *zap.SugaredLogger
-	cfg         *config.Config
+	userService  services.UserServiceInterface
+	featureFlags services.FeatureFlagServiceInterface
+	logger       *zap.SugaredLogger
+	cfg          *config.Config
 }
 
-func NewVotesHandler(userService services.UserServiceInterface, logger *zap.SugaredLogger, cfg *config.Config) *votesHandler {
+func NewVotesHandler(userService services.UserServiceInterface, featureFlags services.FeatureFlagServiceInterface, logger *zap.SugaredLogger, cfg *config.Config) *votesHandler {
 	return &votesHandler{
-		BaseHandler: NewBaseHandler(logger),
-		userService: userService,
-		logger:      logger,
-		cfg:         cfg,
+		BaseHandler:  NewBaseHandler(logger),
+		userService:  userService,
+		featureFlags: featureFlags,
+		logger:       logger,
+		cfg:          cfg,
 	}
 }
 
+// parseProfileID parses the profile of a vote route; IDs are 32-bit, so larger numbers cannot name a profile
+func parseProfileID(id string) (int, error) {
+	profileID, err := strconv.ParseUint(id, 10, 32)
+	return int(profileID), err
+}
+
 func (h *votesHandler) Like(w http.ResponseWriter, r *http.Request) {
 	h.vote(w, r, 1)
 }
@@ -42,23 +50,27 @@ func (h *votesHandler) vote(w http.ResponseWriter, r *http.Request, value int) {
 		VoteId string `json:"vote_id"`
 	}
 
+	if !h.requireFeature(w, r, h.featureFlags, models.FeatureVoting) {
+		return
+	}
+
 	vars := mux.Vars(r)
-	profileID, err := strconv.Atoi(vars["id"])
+	profileID, err := parseProfileID(vars["id"])
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
 	// Getting the ID of the voting user (let's say it is in the context or token)
 	userID, err := strconv.Atoi(h.GetAuthenticatedUserID(r.Context()))
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
 	if userID == profileID {
 		err := errors.New("you cannot vote for yourself")
-		h.sendError(w, err, http.StatusForbidden)
+		h.sendError(w, r, err, http.StatusForbidden)
 		return
 	}
 
@@ -73,7 +85,7 @@ func (h *votesHandler) vote(w http.ResponseWriter, r *http.Request, value int) {
 	// Attempting to create or update a voice
 	voteId, err := h.userService.Vote(ctx, vote)
 	if err != nil {
-		h.sendError(w, err, http.StatusInternalServerError)
+		h.sendError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -86,23 +98,27 @@ func (h *votesHandler) RevokeVote(w http.ResponseWriter, r *http.Request) {
 		VoteId string `json:"vote_id"`
 	}
 
+	if !h.requireFeature(w, r, h.featureFlags, models.FeatureVoting) {
+		return
+	}
+
 	vars := mux.Vars(r)
-	profileID, err := strconv.Atoi(vars["id"])
+	profileID, err := parseProfileID(vars["id"])
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
 	// Getting the ID of the voting user (let's say it is in the context or token)
 	userID, err := strconv.Atoi(h.GetAuthenticatedUserID(r.Context()))
 	if err != nil {
-		h.sendError(w, err, http.StatusBadRequest)
+		h.sendError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
 	if userID == profileID {
 		err := errors.New("you cannot vote for yourself")
-		h.sendError(w, err, http.StatusForbidden)
+		h.sendError(w, r, err, http.StatusForbidden)
 		return
 	}
 
@@ -111,7 +127,7 @@ func (h *votesHandler) RevokeVote(w http.ResponseWriter, r *http.Request) {
 	// Attempting to create or update a voice
 	err = h.userService.RevokeVote(ctx, uint(userID), uint(profileID))
 	if err != nil {
-		h.sendError(w, err, http.StatusInternalServerError)
+		h.sendError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
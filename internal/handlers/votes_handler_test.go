@@ -1,131 +1,140 @@
 package handlers
 
-/*import (
-	"context"
-	"errors"
-	"log"
+import (
 	"net/http"
-	"net/http/httptest"
-	"os"
-	"strconv"
 	"testing"
 
 	"github.com/golang/mock/gomock"
-	"github.com/gorilla/mux"
-	"github.com/stretchr/testify/assert"
-	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 	"gitlab.com/jkozhemiaka/web-layout/internal/config"
-	"gitlab.com/jkozhemiaka/web-layout/internal/database"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers/handlertest"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
-	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
 	"gitlab.com/jkozhemiaka/web-layout/internal/services"
 	"go.uber.org/zap"
 )
 
-func InitializeMockVotes(t *testing.T) (*services.MockUserServiceInterface, *votesHandler) {
-	os.Setenv("CONFIG_PATH", "../../configs/.sample.env")
-	defer os.Unsetenv("CONFIG_PATH")
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockUserService := services.NewMockUserServiceInterface(ctrl)
-	logger, err := zap.NewDevelopment()
-	if err != nil {
-		log.Fatal(err)
+func TestVotesHandler(t *testing.T) {
+	voter := handlertest.User
+
+	tests := []struct {
+		name      string
+		serve     func(h *votesHandler) http.HandlerFunc
+		profileID string
+		disabled  bool
+		// expect sets up the service calls the case makes
+		expect     func(userService *services.MockUserServiceInterface)
+		wantStatus int
+		wantCode   string
+		golden     string
+	}{
+		{
+			name:      "like",
+			serve:     func(h *votesHandler) http.HandlerFunc { return h.Like },
+			profileID: "7",
+			expect: func(userService *services.MockUserServiceInterface) {
+				userService.EXPECT().Vote(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, vote *models.Vote) (uint, error) {
+						if vote.UserID != voter.ID || vote.ProfileID != 7 || vote.Value != 1 {
+							t.Errorf("unexpected vote %+v", vote)
+						}
+						return 42, nil
+					})
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "votes_handler/vote_created",
+		},
+		{
+			name:      "dislike",
+			serve:     func(h *votesHandler) http.HandlerFunc { return h.Dislike },
+			profileID: "7",
+			expect: func(userService *services.MockUserServiceInterface) {
+				userService.EXPECT().Vote(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, vote *models.Vote) (uint, error) {
+						if vote.Value != -1 {
+							t.Errorf("unexpected vote %+v", vote)
+						}
+						return 42, nil
+					})
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "votes_handler/vote_created",
+		},
+		{
+			name:       "own profile",
+			serve:      func(h *votesHandler) http.HandlerFunc { return h.Like },
+			profileID:  "1",
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperrors.ForStatus(http.StatusForbidden).Code,
+		},
+		{
+			name:       "invalid profile ID",
+			serve:      func(h *votesHandler) http.HandlerFunc { return h.Like },
+			profileID:  "abc",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.ForStatus(http.StatusBadRequest).Code,
+		},
+		{
+			name:      "cooldown",
+			serve:     func(h *votesHandler) http.HandlerFunc { return h.Dislike },
+			profileID: "7",
+			expect: func(userService *services.MockUserServiceInterface) {
+				userService.EXPECT().Vote(gomock.Any(), gomock.Any()).Return(uint(0), &apperrors.VoteCooldownErr)
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   apperrors.VoteCooldownErr.Code,
+		},
+		{
+			name:       "voting disabled",
+			serve:      func(h *votesHandler) http.HandlerFunc { return h.Like },
+			profileID:  "7",
+			disabled:   true,
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperrors.FeatureDisabledErr.Code,
+		},
+		{
+			name:      "revoke",
+			serve:     func(h *votesHandler) http.HandlerFunc { return h.RevokeVote },
+			profileID: "7",
+			expect: func(userService *services.MockUserServiceInterface) {
+				userService.EXPECT().RevokeVote(gomock.Any(), voter.ID, uint(7)).Return(nil)
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "votes_handler/vote_revoked",
+		},
+		{
+			name:      "revoke missing vote",
+			serve:     func(h *votesHandler) http.HandlerFunc { return h.RevokeVote },
+			profileID: "7",
+			expect: func(userService *services.MockUserServiceInterface) {
+				userService.EXPECT().RevokeVote(gomock.Any(), voter.ID, uint(7)).Return(apperrors.NoRecordFoundErr.AppendMessage("Vote not found."))
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperrors.NoRecordFoundErr.Code,
+		},
 	}
-	defer logger.Sync()
 
-	cfg, err := config.NewConfig()
-	if err != nil {
-		logger.Sugar().Fatal(err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			userService := services.NewMockUserServiceInterface(ctrl)
+			featureFlags := services.NewMockFeatureFlagServiceInterface(ctrl)
+			featureFlags.EXPECT().Enabled(gomock.Any(), models.FeatureVoting, voter.ID).Return(!tt.disabled)
+			if tt.expect != nil {
+				tt.expect(userService)
+			}
+			handler := NewVotesHandler(userService, featureFlags, zap.NewNop().Sugar(), &config.Config{})
+
+			response := handlertest.NewRequest(t, http.MethodPost, "/like/"+tt.profileID).
+				Vars(map[string]string{"id": tt.profileID}).
+				As(voter).
+				Serve(tt.serve(handler)).
+				AssertStatus(tt.wantStatus)
+			if tt.wantCode != "" {
+				response.AssertErrorCode(tt.wantCode)
+			}
+			if tt.golden != "" {
+				response.AssertGolden(tt.golden)
+			}
+		})
 	}
-	db, err := database.SetupDatabase(cfg)
-	if err != nil {
-		logger.Sugar().Fatal(err)
-	}
-	userService := services.NewUserService(repositories.NewUserRepo(db, logger.Sugar()), logger.Sugar())
-	votesHandler := NewVotesHandler(userService, logger.Sugar(), cfg)
-
-	return mockUserService, votesHandler
-}
-
-func TestVotes(t *testing.T) {
-	mockUserService, handler := InitializeMockVotes(t)
-
-	t.Run("Like success", func(t *testing.T) {
-		token := auth.GenerateTokenHandler("admin@example.com", "admin", 2, []byte(handler.cfg.JwtKey))
-		mockUserService.EXPECT().Vote(gomock.Any(), gomock.Any()).Return("vote_id_123", nil)
-
-		req := httptest.NewRequest(http.MethodPost, "/votes/like/1", nil)
-		req.Header.Set("Authorization", "Bearer "+string(token))
-		req = mux.SetURLVars(req, map[string]string{"id": "1"})
-		req = req.WithContext(contextWithUserID(req.Context(), 2)) // Mock user ID 2 voting
-
-		w := httptest.NewRecorder()
-		handler.Like(w, req)
-
-		assert.Equal(t, http.StatusCreated, w.Code)
-	})
-
-	t.Run("Dislike success", func(t *testing.T) {
-		token := auth.GenerateTokenHandler("admin@example.com", "admin", 2, []byte(handler.cfg.JwtKey))
-		mockUserService.EXPECT().Vote(gomock.Any(), gomock.Any()).Return("vote_id_123", nil)
-
-		req := httptest.NewRequest(http.MethodPost, "/votes/dislike/3", nil)
-		req.Header.Set("Authorization", "Bearer "+string(token))
-		req = mux.SetURLVars(req, map[string]string{"id": "3"})
-		req = req.WithContext(contextWithUserID(req.Context(), 2)) // Mock user ID 2 voting
-
-		w := httptest.NewRecorder()
-		handler.Dislike(w, req)
-
-		assert.Equal(t, http.StatusCreated, w.Code)
-	})
-
-	t.Run("Cannot vote for self", func(t *testing.T) {
-		token := auth.GenerateTokenHandler("admin@example.com", "admin", 2, []byte(handler.cfg.JwtKey))
-		req := httptest.NewRequest(http.MethodPost, "/votes/like/2", nil)
-		req.Header.Set("Authorization", "Bearer "+string(token))
-		req = mux.SetURLVars(req, map[string]string{"id": "2"})
-		req = req.WithContext(contextWithUserID(req.Context(), 2)) // Mock user ID 2 voting for profile 2
-
-		w := httptest.NewRecorder()
-		handler.Like(w, req)
-
-		assert.Equal(t, http.StatusForbidden, w.Code)
-	})
-
-	t.Run("Invalid profile ID", func(t *testing.T) {
-		token := auth.GenerateTokenHandler("admin@example.com", "admin", 2, []byte(handler.cfg.JwtKey))
-		req := httptest.NewRequest(http.MethodPost, "/votes/like/abc", nil)
-		req.Header.Set("Authorization", "Bearer "+string(token))
-		req = mux.SetURLVars(req, map[string]string{"id": "abc"})
-		req = req.WithContext(contextWithUserID(req.Context(), 2)) // Mock user ID 2 voting
-
-		w := httptest.NewRecorder()
-		handler.Like(w, req)
-
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-	})
-
-	t.Run("UserService error", func(t *testing.T) {
-		mockUserService.EXPECT().Vote(gomock.Any(), gomock.Any()).Return("", errors.New("service error"))
-		token := auth.GenerateTokenHandler("admin@example.com", "admin", 2, []byte(handler.cfg.JwtKey))
-		req := httptest.NewRequest(http.MethodPost, "/votes/like/1", nil)
-		req.Header.Set("Authorization", "Bearer "+string(token))
-		req = mux.SetURLVars(req, map[string]string{"id": "1"})
-		req = req.WithContext(contextWithUserID(req.Context(), 2)) // Mock user ID 2 voting
-
-		w := httptest.NewRecorder()
-		handler.Like(w, req)
-
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
-	})
-}
-
-func contextWithUserID(ctx context.Context, userID int) context.Context {
-	ctx = context.WithValue(ctx, models.IDContextKey, strconv.Itoa(userID))
-	return ctx
 }
-*/
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/jobs"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+)
+
+type webhooksHandler struct {
+	*BaseHandler
+	deliveries jobs.DeliveryLog
+	logger     *zap.SugaredLogger
+}
+
+func NewWebhooksHandler(deliveries jobs.DeliveryLog, logger *zap.SugaredLogger) *webhooksHandler {
+	return &webhooksHandler{
+		BaseHandler: NewBaseHandler(logger),
+		deliveries:  deliveries,
+		logger:      logger,
+	}
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// ListDeliveries returns the latest webhook attempts, of one event with ?event_id= and capped by ?limit=
+func (h *webhooksHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	limit := defaultJobsLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxJobsLimit {
+			h.sendError(w, r, errors.New("limit should be in the range from 1 to "+strconv.Itoa(maxJobsLimit)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveries, err := h.deliveries.ListWebhookDeliveries(r.Context(), query.Get("event_id"), limit)
+	if err != nil {
+		h.sendError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, &ListWebhookDeliveriesResponse{Deliveries: deliveries}, http.StatusOK)
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalog maps a language to its messages by ID, e.g. "validation.required"; locales/en.json has every ID
+var catalog = loadCatalog()
+
+func loadCatalog() map[string]map[string]string {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("i18n: " + file.Name() + ": " + err.Error())
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// Has reports whether the catalog has a message with the id
+func Has(id string) bool {
+	_, ok := catalog["en"][id]
+	return ok
+}
+
+// Message returns the message with the id in lang, formatted with args as by fmt.Sprintf. Messages missing in lang
+// come in the fallback language, then in English; an unknown id is returned as it is.
+func Message(lang language.Tag, id string, args ...interface{}) string {
+	format, ok := lookup(lang, id)
+	if !ok {
+		return id
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func lookup(lang language.Tag, id string) (string, bool) {
+	for _, tag := range []language.Tag{lang, Fallback(), language.English} {
+		base, _ := tag.Base()
+		if message, ok := catalog[base.String()][id]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// FormatTime shows t in location with the date and time layout of lang, e.g. "14.10.2026 09:30 EEST" in Ukrainian
+func FormatTime(t time.Time, location *time.Location, lang language.Tag) string {
+	return t.In(location).Format(Message(lang, "format.datetime"))
+}
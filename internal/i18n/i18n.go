@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+)
+
+// Languages the API answers in
+var Supported = []language.Tag{language.English, language.Ukrainian}
+
+var matcher = language.NewMatcher(Supported)
+
+// fallback indexes Supported; it is English unless SetFallback changes it
+var fallback atomic.Int32
+
+type contextKey struct{}
+
+// SetFallback makes lang the language used when a request asks for none of the supported ones, and for messages
+// sent outside a request. DEFAULT_LANGUAGE sets it at startup.
+func SetFallback(lang string) error {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return err
+	}
+	for i, supported := range Supported {
+		if supported == tag {
+			fallback.Store(int32(i))
+			return nil
+		}
+	}
+	return fmt.Errorf("language %q is not supported", lang)
+}
+
+// Fallback returns the language set by SetFallback
+func Fallback() language.Tag {
+	return Supported[fallback.Load()]
+}
+
+// Negotiate picks the supported language that best matches an Accept-Language header, or the fallback
+func Negotiate(acceptLanguage string) language.Tag {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Fallback()
+	}
+	return Supported[index]
+}
+
+// WithLanguage returns a copy of ctx carrying the language of the request
+func WithLanguage(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, contextKey{}, tag)
+}
+
+// FromContext returns the language stored by WithLanguage, or the fallback
+func FromContext(ctx context.Context) language.Tag {
+	if tag, ok := ctx.Value(contextKey{}).(language.Tag); ok {
+		return tag
+	}
+	return Fallback()
+}
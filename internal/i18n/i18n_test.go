@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, language.Ukrainian, Negotiate("uk-UA,uk;q=0.9,en;q=0.8"))
+	assert.Equal(t, language.English, Negotiate("de-DE,en;q=0.5"))
+	assert.Equal(t, language.English, Negotiate("fr"), "unsupported languages fall back to English")
+	assert.Equal(t, language.English, Negotiate(""))
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, language.English, FromContext(context.Background()))
+	assert.Equal(t, language.Ukrainian, FromContext(WithLanguage(context.Background(), language.Ukrainian)))
+}
+
+func TestSetFallback(t *testing.T) {
+	t.Cleanup(func() { SetFallback("en") })
+
+	assert.Error(t, SetFallback("fr"))
+	assert.Equal(t, language.English, Fallback())
+
+	assert.NoError(t, SetFallback("uk"))
+	assert.Equal(t, language.Ukrainian, Negotiate("fr"), "unmatched languages get the fallback")
+	assert.Equal(t, language.English, Negotiate("en-GB"))
+	assert.Equal(t, language.Ukrainian, FromContext(context.Background()))
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "is required", Message(language.English, "validation.required"))
+	assert.Equal(t, "має містити щонайменше 8 символів", Message(language.Ukrainian, "validation.min", "8"))
+	assert.Equal(t, "must be at least 8 characters long", Message(language.German, "validation.min", "8"), "unsupported languages get the fallback")
+	assert.Equal(t, "missing.id", Message(language.English, "missing.id"))
+	assert.True(t, Has("validation.email"))
+	assert.False(t, Has("missing.id"))
+}
+
+func TestCatalog_EveryLanguageTranslatesEveryMessage(t *testing.T) {
+	for _, tag := range Supported {
+		base, _ := tag.Base()
+		messages, ok := catalog[base.String()]
+		if !assert.True(t, ok, "no locales/%s.json", base) {
+			continue
+		}
+		for id := range catalog["en"] {
+			assert.Contains(t, messages, id, "locales/%s.json", base)
+		}
+		for id := range messages {
+			assert.Contains(t, catalog["en"], id, "locales/%s.json has a message English does not", base)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	require.NoError(t, err)
+	at := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "May 1, 2024 12:00 UTC", FormatTime(at, time.UTC, language.English))
+	assert.Equal(t, "01.05.2024 15:00 EEST", FormatTime(at, kyiv, language.Ukrainian))
+}
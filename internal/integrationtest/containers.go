@@ -0,0 +1,149 @@
+//go:build integration
+
+// Package integrationtest starts the Postgres and Redis containers the integration tests run against, with
+// `go test -tags integration ./...`. Tests are skipped without a Docker daemon. A package's tests share one
+// container of each, removed by the testcontainers reaper when the test binary exits, and every test gets a
+// database of its own with the SQL migrations applied.
+package integrationtest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/database"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresImage = "postgres:16-alpine"
+	redisImage    = "redis:7-alpine"
+)
+
+var (
+	postgresOnce sync.Once
+	// postgresURI connects to the container's maintenance database, from which the test databases are created
+	postgresURI string
+	postgresErr error
+
+	redisOnce sync.Once
+	redisURL  string
+	redisErr  error
+
+	databases      atomic.Int64
+	redisDatabases atomic.Int64
+)
+
+// skipWithoutDocker is testcontainers.SkipIfProviderIsNotHealthy for benchmarks as well as tests
+func skipWithoutDocker(t testing.TB) {
+	t.Helper()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err == nil {
+		err = provider.Health(context.Background())
+	}
+	if err != nil {
+		t.Skipf("Docker is not running: %s", err)
+	}
+}
+
+// PostgresURI returns the URI of a new database with every migration applied
+func PostgresURI(t testing.TB) string {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	postgresOnce.Do(func() {
+		ctx := context.Background()
+		var container *postgres.PostgresContainer
+		container, postgresErr = postgres.RunContainer(ctx,
+			testcontainers.WithImage(postgresImage),
+			testcontainers.WithWaitStrategy(
+				// The server restarts once after the init scripts, so wait for it to be ready the second time
+				wait.ForLog("database system is ready to accept connections").WithOccurrence(2).WithStartupTimeout(time.Minute),
+			),
+		)
+		if postgresErr != nil {
+			return
+		}
+		postgresURI, postgresErr = container.ConnectionString(ctx, "sslmode=disable")
+	})
+	require.NoError(t, postgresErr, "starting Postgres")
+
+	maintenance := Database(t, Config(postgresURI))
+	name := fmt.Sprintf("test_%d", databases.Add(1))
+	require.NoError(t, maintenance.Exec("CREATE DATABASE "+name).Error)
+
+	parsed, err := url.Parse(postgresURI)
+	require.NoError(t, err)
+	parsed.Path = "/" + name
+	uri := parsed.String()
+
+	migrator, err := database.NewMigrator(Config(uri))
+	require.NoError(t, err)
+	defer migrator.Close()
+	require.NoError(t, migrator.Up())
+	return uri
+}
+
+// RedisURL returns the URL of an empty logical database of the shared Redis. Redis has 16 of them, so at most
+// that many tests of a package can use Redis without seeing each other's keys.
+func RedisURL(t testing.TB) string {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	redisOnce.Do(func() {
+		ctx := context.Background()
+		var container *redis.RedisContainer
+		container, redisErr = redis.RunContainer(ctx, testcontainers.WithImage(redisImage))
+		if redisErr != nil {
+			return
+		}
+		redisURL, redisErr = container.ConnectionString(ctx)
+	})
+	require.NoError(t, redisErr, "starting Redis")
+
+	uri := fmt.Sprintf("%s/%d", redisURL, redisDatabases.Add(1)%16)
+	options, err := goredis.ParseURL(uri)
+	require.NoError(t, err)
+	client := goredis.NewClient(options)
+	defer client.Close()
+	require.NoError(t, client.FlushDB(context.Background()).Err())
+	return uri
+}
+
+// Config is the database configuration of the server for uri, without replicas
+func Config(uri string) *config.Config {
+	return &config.Config{
+		DBDriver:                config.DriverPostgres,
+		PostgresURI:             uri,
+		DBMaxOpenConns:          5,
+		DBMaxIdleConns:          2,
+		DBConnectTimeout:        10 * time.Second,
+		DBConnectInitialBackoff: 100 * time.Millisecond,
+		DBConnectMaxBackoff:     time.Second,
+		DBLogLevel:              "silent",
+	}
+}
+
+// Database opens cfg like the server does and closes it when the test ends
+func Database(t testing.TB, cfg *config.Config) *gorm.DB {
+	t.Helper()
+	db, err := database.SetupDatabase(cfg, zaptest.NewLogger(t).Sugar())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
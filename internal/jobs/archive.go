@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// KindArchiveUsers runs one archival pass on the queue; its payload is ignored
+const KindArchiveUsers = "users.archive"
+
+// UserArchiver periodically moves users soft-deleted longer than the retention period out of the users table
+type UserArchiver struct {
+	repo      repositories.UserArchiveRepoInterface
+	retention time.Duration
+	batchSize int
+	purge     bool
+	logger    *zap.SugaredLogger
+}
+
+func NewUserArchiver(repo repositories.UserArchiveRepoInterface, retention time.Duration, batchSize int, purge bool, logger *zap.SugaredLogger) *UserArchiver {
+	return &UserArchiver{
+		repo:      repo,
+		retention: retention,
+		batchSize: batchSize,
+		purge:     purge,
+		logger:    logger,
+	}
+}
+
+// RunOnce processes batches until no user is left past the retention period and returns the total.
+// Each batch is its own transaction, so a long backlog never holds locks on the whole table.
+func (archiver *UserArchiver) RunOnce(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().Add(-archiver.retention)
+
+	total := 0
+	for ctx.Err() == nil {
+		archived, err := archiver.repo.ArchiveDeletedUsers(ctx, deletedBefore, archiver.batchSize, archiver.purge)
+		if err != nil {
+			return total, err
+		}
+
+		total += archived
+		if archived < archiver.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		archiver.logger.Infow("Archived deleted users", "count", total, "purge", archiver.purge)
+	}
+	return total, ctx.Err()
+}
+
+// Perform is the handler of KindArchiveUsers
+func (archiver *UserArchiver) Perform(ctx context.Context, payload []byte) error {
+	_, err := archiver.RunOnce(ctx)
+	return err
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeArchiveRepo struct {
+	batches []int
+	calls   int
+	err     error
+}
+
+func (repo *fakeArchiveRepo) ArchiveDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int, purge bool) (int, error) {
+	if repo.calls == len(repo.batches) {
+		return 0, repo.err
+	}
+	archived := repo.batches[repo.calls]
+	repo.calls++
+	return archived, nil
+}
+
+func TestUserArchiver_RunOnceDrainsBatches(t *testing.T) {
+	repo := &fakeArchiveRepo{batches: []int{10, 10, 3}}
+	archiver := NewUserArchiver(repo, 30*24*time.Hour, 10, false, zaptest.NewLogger(t).Sugar())
+
+	total, err := archiver.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 23, total)
+	assert.Equal(t, 3, repo.calls)
+}
+
+func TestUserArchiver_RunOnceReportsErrors(t *testing.T) {
+	repo := &fakeArchiveRepo{batches: []int{10}, err: errors.New("db down")}
+	archiver := NewUserArchiver(repo, 30*24*time.Hour, 10, false, zaptest.NewLogger(t).Sugar())
+
+	total, err := archiver.RunOnce(context.Background())
+	assert.EqualError(t, err, "db down")
+	assert.Equal(t, 10, total)
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// KindRelayChanges names the task relaying the outbox into the change feed
+const KindRelayChanges = "changes.relay"
+
+// ChangeRelay moves the user mutations writers append to the outbox into the change feed, where they get their
+// sequence numbers
+type ChangeRelay struct {
+	repo      repositories.ChangeRepoInterface
+	batchSize int
+	logger    *zap.SugaredLogger
+}
+
+func NewChangeRelay(repo repositories.ChangeRepoInterface, batchSize int, logger *zap.SugaredLogger) *ChangeRelay {
+	return &ChangeRelay{
+		repo:      repo,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// RunOnce relays batches until the outbox is empty and returns the total
+func (relay *ChangeRelay) RunOnce(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		relayed, err := relay.repo.RelayChanges(ctx, relay.batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		total += relayed
+		if relayed < relay.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		relay.logger.Debugw("Relayed changes", "count", total)
+	}
+	return total, ctx.Err()
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeChangeRepo struct {
+	repositories.ChangeRepoInterface
+	batches []int
+	calls   int
+}
+
+func (repo *fakeChangeRepo) RelayChanges(ctx context.Context, limit int) (int, error) {
+	if repo.calls == len(repo.batches) {
+		return 0, nil
+	}
+	relayed := repo.batches[repo.calls]
+	repo.calls++
+	return relayed, nil
+}
+
+func TestChangeRelay_RunOnceDrainsTheOutbox(t *testing.T) {
+	repo := &fakeChangeRepo{batches: []int{5, 5, 2}}
+	relay := NewChangeRelay(repo, 5, zaptest.NewLogger(t).Sugar())
+
+	total, err := relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 12, total)
+	assert.Equal(t, 3, repo.calls)
+}
@@ -0,0 +1,271 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// KindCleanup runs one cleanup pass on the queue; its payload is ignored
+const KindCleanup = "maintenance.cleanup"
+
+// Sweeper deletes up to limit expired rows of one kind, such as tokens past their expiry, and returns how many
+type Sweeper interface {
+	Name() string
+	DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Cleaner periodically runs every sweeper until it has nothing left to delete
+type Cleaner struct {
+	sweepers  []Sweeper
+	batchSize int
+	logger    *zap.SugaredLogger
+}
+
+func NewCleaner(batchSize int, logger *zap.SugaredLogger, sweepers ...Sweeper) *Cleaner {
+	return &Cleaner{
+		sweepers:  sweepers,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// RunOnce deletes expired rows batch by batch and returns the count per sweeper. A failing sweeper does not
+// keep the others from running; the first error is returned.
+func (cleaner *Cleaner) RunOnce(ctx context.Context) (map[string]int, error) {
+	now := time.Now()
+	deleted := make(map[string]int, len(cleaner.sweepers))
+
+	var firstErr error
+	for _, sweeper := range cleaner.sweepers {
+		total, err := cleaner.sweep(ctx, sweeper, now)
+		deleted[sweeper.Name()] = total
+		if err != nil {
+			cleaner.logger.Errorw("Cleanup failed", "sweeper", sweeper.Name(), "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if total > 0 {
+			cleaner.logger.Infow("Deleted expired rows", "sweeper", sweeper.Name(), "count", total)
+		}
+	}
+	return deleted, firstErr
+}
+
+// sweep deletes in batches, each its own statement, so a large backlog never locks the whole table
+func (cleaner *Cleaner) sweep(ctx context.Context, sweeper Sweeper, now time.Time) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		deleted, err := sweeper.DeleteExpired(ctx, now, cleaner.batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+		if deleted < cleaner.batchSize {
+			break
+		}
+	}
+	return total, ctx.Err()
+}
+
+// Perform is the handler of KindCleanup
+func (cleaner *Cleaner) Perform(ctx context.Context, payload []byte) error {
+	_, err := cleaner.RunOnce(ctx)
+	return err
+}
+
+// SucceededJobsSweeper drops jobs that succeeded more than retention ago; failed ones are kept for the dashboard
+type SucceededJobsSweeper struct {
+	repo      repositories.JobRepoInterface
+	retention time.Duration
+}
+
+func NewSucceededJobsSweeper(repo repositories.JobRepoInterface, retention time.Duration) *SucceededJobsSweeper {
+	return &SucceededJobsSweeper{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+func (sweeper *SucceededJobsSweeper) Name() string {
+	return "succeeded_jobs"
+}
+
+func (sweeper *SucceededJobsSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteSucceededJobs(ctx, now.Add(-sweeper.retention), limit)
+}
+
+// SecurityIncidentsSweeper drops brute-force incidents raised more than retention ago
+type SecurityIncidentsSweeper struct {
+	repo      repositories.SecurityIncidentRepoInterface
+	retention time.Duration
+}
+
+func NewSecurityIncidentsSweeper(repo repositories.SecurityIncidentRepoInterface, retention time.Duration) *SecurityIncidentsSweeper {
+	return &SecurityIncidentsSweeper{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+func (sweeper *SecurityIncidentsSweeper) Name() string {
+	return "security_incidents"
+}
+
+func (sweeper *SecurityIncidentsSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteSecurityIncidents(ctx, now.Add(-sweeper.retention), limit)
+}
+
+// SecurityEventsSweeper drops the security events of users recorded more than retention ago
+type SecurityEventsSweeper struct {
+	repo      repositories.SecurityEventRepoInterface
+	retention time.Duration
+}
+
+func NewSecurityEventsSweeper(repo repositories.SecurityEventRepoInterface, retention time.Duration) *SecurityEventsSweeper {
+	return &SecurityEventsSweeper{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+func (sweeper *SecurityEventsSweeper) Name() string {
+	return "security_events"
+}
+
+func (sweeper *SecurityEventsSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteSecurityEvents(ctx, now.Add(-sweeper.retention), limit)
+}
+
+// OAuthCodesSweeper drops the authorization codes that expired without being exchanged
+type OAuthCodesSweeper struct {
+	repo repositories.OAuthRepoInterface
+}
+
+func NewOAuthCodesSweeper(repo repositories.OAuthRepoInterface) *OAuthCodesSweeper {
+	return &OAuthCodesSweeper{repo: repo}
+}
+
+func (sweeper *OAuthCodesSweeper) Name() string {
+	return "oauth_codes"
+}
+
+func (sweeper *OAuthCodesSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteExpiredCodes(ctx, now, limit)
+}
+
+// LoginAlertsSweeper drops the "this wasn't me" links of login alerts that expired unused
+type LoginAlertsSweeper struct {
+	repo repositories.LoginAlertRepoInterface
+}
+
+func NewLoginAlertsSweeper(repo repositories.LoginAlertRepoInterface) *LoginAlertsSweeper {
+	return &LoginAlertsSweeper{repo: repo}
+}
+
+func (sweeper *LoginAlertsSweeper) Name() string {
+	return "login_alerts"
+}
+
+func (sweeper *LoginAlertsSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteExpiredAlerts(ctx, now, limit)
+}
+
+// BansSweeper deletes expired bans; they stopped applying at login when they expired
+type BansSweeper struct {
+	repo repositories.BanRepoInterface
+}
+
+func NewBansSweeper(repo repositories.BanRepoInterface) *BansSweeper {
+	return &BansSweeper{repo: repo}
+}
+
+func (sweeper *BansSweeper) Name() string {
+	return "bans"
+}
+
+func (sweeper *BansSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteExpiredBans(ctx, now, limit)
+}
+
+// PhoneCodesSweeper drops the codes texted to phones that expired unused
+type PhoneCodesSweeper struct {
+	repo repositories.PhoneRepoInterface
+}
+
+func NewPhoneCodesSweeper(repo repositories.PhoneRepoInterface) *PhoneCodesSweeper {
+	return &PhoneCodesSweeper{repo: repo}
+}
+
+func (sweeper *PhoneCodesSweeper) Name() string {
+	return "phone_codes"
+}
+
+func (sweeper *PhoneCodesSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteExpiredCodes(ctx, now, limit)
+}
+
+// RememberTokensSweeper drops the remember-me tokens that expired, used or not
+type RememberTokensSweeper struct {
+	repo repositories.RememberTokenRepoInterface
+}
+
+func NewRememberTokensSweeper(repo repositories.RememberTokenRepoInterface) *RememberTokensSweeper {
+	return &RememberTokensSweeper{repo: repo}
+}
+
+func (sweeper *RememberTokensSweeper) Name() string {
+	return "remember_tokens"
+}
+
+func (sweeper *RememberTokensSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteExpiredTokens(ctx, now, limit)
+}
+
+// InvitationsSweeper drops the invitations that expired more than retention ago without being accepted; until
+// then an admin can still resend them
+type InvitationsSweeper struct {
+	repo      repositories.InvitationRepoInterface
+	retention time.Duration
+}
+
+func NewInvitationsSweeper(repo repositories.InvitationRepoInterface, retention time.Duration) *InvitationsSweeper {
+	return &InvitationsSweeper{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+func (sweeper *InvitationsSweeper) Name() string {
+	return "invitations"
+}
+
+func (sweeper *InvitationsSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteExpiredInvitations(ctx, now.Add(-sweeper.retention), limit)
+}
+
+// APIKeyUsageSweeper drops the daily usage counters of API keys older than retention
+type APIKeyUsageSweeper struct {
+	repo      repositories.APIKeyRepoInterface
+	retention time.Duration
+}
+
+func NewAPIKeyUsageSweeper(repo repositories.APIKeyRepoInterface, retention time.Duration) *APIKeyUsageSweeper {
+	return &APIKeyUsageSweeper{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+func (sweeper *APIKeyUsageSweeper) Name() string {
+	return "api_key_usage"
+}
+
+func (sweeper *APIKeyUsageSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteUsageBefore(ctx, now.Add(-sweeper.retention).UTC().Format("2006-01-02"), limit)
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeSweeper struct {
+	name    string
+	batches []int
+	calls   int
+	err     error
+}
+
+func (sweeper *fakeSweeper) Name() string {
+	return sweeper.name
+}
+
+func (sweeper *fakeSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	if sweeper.calls == len(sweeper.batches) {
+		return 0, sweeper.err
+	}
+	deleted := sweeper.batches[sweeper.calls]
+	sweeper.calls++
+	return deleted, nil
+}
+
+func TestCleaner_RunOnceDrainsEverySweeper(t *testing.T) {
+	tokens := &fakeSweeper{name: "tokens", batches: []int{100, 100, 7}}
+	sessions := &fakeSweeper{name: "sessions", batches: []int{3}}
+	cleaner := NewCleaner(100, zaptest.NewLogger(t).Sugar(), tokens, sessions)
+
+	deleted, err := cleaner.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"tokens": 207, "sessions": 3}, deleted)
+	assert.Equal(t, 3, tokens.calls)
+}
+
+func TestCleaner_RunOnceKeepsGoingAfterAFailure(t *testing.T) {
+	broken := &fakeSweeper{name: "broken", err: errors.New("db down")}
+	sessions := &fakeSweeper{name: "sessions", batches: []int{3}}
+	cleaner := NewCleaner(100, zaptest.NewLogger(t).Sugar(), broken, sessions)
+
+	deleted, err := cleaner.RunOnce(context.Background())
+	assert.EqualError(t, err, "db down")
+	assert.Equal(t, 3, deleted["sessions"])
+}
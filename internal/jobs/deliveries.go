@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+)
+
+// KindDeliverEvent publishes one event to the configured sink
+const KindDeliverEvent = "events.deliver"
+
+// QueuedPublisher hands events to the queue instead of publishing them in the request, so a slow or failing
+// webhook neither delays the response nor loses the event
+type QueuedPublisher struct {
+	queue *Queue
+	opts  []EnqueueOption
+}
+
+// NewQueuedPublisher enqueues every event with opts, e.g. a MaxAttempts of its own
+func NewQueuedPublisher(queue *Queue, opts ...EnqueueOption) *QueuedPublisher {
+	return &QueuedPublisher{queue: queue, opts: opts}
+}
+
+func (p *QueuedPublisher) Publish(ctx context.Context, event *events.Event) error {
+	return p.queue.Enqueue(ctx, KindDeliverEvent, event, p.opts...)
+}
+
+// DeliverEvent is the handler of KindDeliverEvent, publishing to next
+func DeliverEvent(next events.Publisher) Handler {
+	return func(ctx context.Context, payload []byte) error {
+		event := &events.Event{}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return fmt.Errorf("%w: %v", ErrSkipRetry, err)
+		}
+		return next.Publish(ctx, event)
+	}
+}
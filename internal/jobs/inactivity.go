@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// KindAnonymizeInactive runs one inactivity pass on the queue; its payload is ignored
+const KindAnonymizeInactive = "users.anonymize_inactive"
+
+// InactivityAnonymizer warns users who have not signed in for period minus warning, and anonymizes them once
+// they stay away for the whole period and the warning is at least warning old
+type InactivityAnonymizer struct {
+	repo      repositories.InactivityRepoInterface
+	mailer    mail.Mailer
+	templates *mail.Templates
+	period    time.Duration
+	warning   time.Duration
+	batchSize int
+	logger    *zap.SugaredLogger
+	now       func() time.Time
+}
+
+type inactivityWarningEmail struct {
+	FirstName   string
+	AnonymizeAt string
+}
+
+func NewInactivityAnonymizer(repo repositories.InactivityRepoInterface, mailer mail.Mailer, templates *mail.Templates, period, warning time.Duration, batchSize int, logger *zap.SugaredLogger) *InactivityAnonymizer {
+	return &InactivityAnonymizer{
+		repo:      repo,
+		mailer:    mailer,
+		templates: templates,
+		period:    period,
+		warning:   warning,
+		batchSize: batchSize,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// RunOnce sends the due warnings, then anonymizes the users warned long enough ago, and returns how many of
+// each it handled
+func (anonymizer *InactivityAnonymizer) RunOnce(ctx context.Context) (warned, anonymized int, err error) {
+	now := anonymizer.now()
+
+	warned, err = anonymizer.warn(ctx, now)
+	if err != nil {
+		return warned, 0, err
+	}
+	anonymized, err = anonymizer.anonymize(ctx, now)
+
+	if warned > 0 || anonymized > 0 {
+		anonymizer.logger.Infow("Processed inactive users", "warned", warned, "anonymized", anonymized)
+	}
+	return warned, anonymized, err
+}
+
+func (anonymizer *InactivityAnonymizer) warn(ctx context.Context, now time.Time) (int, error) {
+	inactiveBefore := now.Add(-(anonymizer.period - anonymizer.warning))
+	anonymizeAt := now.Add(anonymizer.warning)
+
+	total := 0
+	for ctx.Err() == nil {
+		users, err := anonymizer.repo.ListToWarn(ctx, inactiveBefore, anonymizer.batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for i := range users {
+			user := &users[i]
+			lang := user.Language(i18n.Fallback())
+			email := &inactivityWarningEmail{
+				FirstName:   user.FirstName,
+				AnonymizeAt: i18n.FormatTime(anonymizeAt, user.Location(), lang),
+			}
+			msg, err := anonymizer.templates.Render(mail.TemplateInactivityWarning, lang, email, user.Email)
+			if err != nil {
+				return total, err
+			}
+			// Only a user who was actually told is marked, the others are tried again on the next pass
+			err = anonymizer.mailer.Send(ctx, msg)
+			if err != nil {
+				return total, err
+			}
+			err = anonymizer.repo.MarkWarned(ctx, user.ID, now)
+			if err != nil {
+				return total, err
+			}
+			total++
+		}
+		if len(users) < anonymizer.batchSize {
+			break
+		}
+	}
+	return total, ctx.Err()
+}
+
+func (anonymizer *InactivityAnonymizer) anonymize(ctx context.Context, now time.Time) (int, error) {
+	inactiveBefore := now.Add(-anonymizer.period)
+	warnedBefore := now.Add(-anonymizer.warning)
+
+	total := 0
+	for ctx.Err() == nil {
+		ids, err := anonymizer.repo.ListToAnonymize(ctx, inactiveBefore, warnedBefore, anonymizer.batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for _, id := range ids {
+			done, err := anonymizer.repo.Anonymize(ctx, id, inactiveBefore)
+			if err != nil {
+				return total, err
+			}
+			if done {
+				total++
+			}
+		}
+		if len(ids) < anonymizer.batchSize {
+			break
+		}
+	}
+	return total, ctx.Err()
+}
+
+// Perform is the handler of KindAnonymizeInactive
+func (anonymizer *InactivityAnonymizer) Perform(ctx context.Context, payload []byte) error {
+	_, _, err := anonymizer.RunOnce(ctx)
+	return err
+}
@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeInactivityRepo struct {
+	toWarn      []models.User
+	toAnonymize []uint
+	warned      []uint
+	anonymized  []uint
+}
+
+func (repo *fakeInactivityRepo) RecordLogin(ctx context.Context, userID uint, at time.Time) error {
+	return nil
+}
+
+func (repo *fakeInactivityRepo) ListToWarn(ctx context.Context, inactiveBefore time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	for _, user := range repo.toWarn {
+		if !containsID(repo.warned, user.ID) && len(users) < limit {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (repo *fakeInactivityRepo) MarkWarned(ctx context.Context, userID uint, at time.Time) error {
+	repo.warned = append(repo.warned, userID)
+	return nil
+}
+
+func (repo *fakeInactivityRepo) ListToAnonymize(ctx context.Context, inactiveBefore, warnedBefore time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	for _, id := range repo.toAnonymize {
+		if !containsID(repo.anonymized, id) && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (repo *fakeInactivityRepo) Anonymize(ctx context.Context, userID uint, inactiveBefore time.Time) (bool, error) {
+	repo.anonymized = append(repo.anonymized, userID)
+	return true, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+type failingMailer struct {
+	err error
+}
+
+func (m *failingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	return m.err
+}
+
+func newTestAnonymizer(t *testing.T, repo *fakeInactivityRepo, mailer mail.Mailer) *InactivityAnonymizer {
+	templates, err := mail.LoadTemplates("")
+	require.NoError(t, err)
+	anonymizer := NewInactivityAnonymizer(repo, mailer, templates, 365*24*time.Hour, 30*24*time.Hour, 2, zaptest.NewLogger(t).Sugar())
+	anonymizer.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return anonymizer
+}
+
+func TestInactivityAnonymizer_WarnsThenAnonymizes(t *testing.T) {
+	repo := &fakeInactivityRepo{
+		toWarn: []models.User{
+			{ID: 1, Email: "ann@example.com", FirstName: "Ann"},
+			{ID: 2, Email: "bob@example.com", FirstName: "Bob", Locale: "uk", Timezone: "Europe/Kyiv"},
+			{ID: 3, Email: "eve@example.com", FirstName: "Eve"},
+		},
+		toAnonymize: []uint{7, 8},
+	}
+	mailer := &recordingMailer{}
+
+	warned, anonymized, err := newTestAnonymizer(t, repo, mailer).RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, warned)
+	assert.Equal(t, 2, anonymized)
+	assert.Equal(t, []uint{1, 2, 3}, repo.warned)
+	assert.Equal(t, []uint{7, 8}, repo.anonymized)
+
+	require.Len(t, mailer.messages, 3)
+	assert.Equal(t, []string{"ann@example.com"}, mailer.messages[0].To)
+	assert.Contains(t, mailer.messages[0].Text, "May 31, 2024")
+	// In the user's language and time zone
+	assert.Contains(t, mailer.messages[1].Text, "31.05.2024 15:00")
+}
+
+func TestInactivityAnonymizer_UnsentWarningsAreNotMarked(t *testing.T) {
+	repo := &fakeInactivityRepo{toWarn: []models.User{{ID: 1, Email: "ann@example.com"}}, toAnonymize: []uint{7}}
+	mailer := &failingMailer{err: errors.New("smtp down")}
+
+	warned, anonymized, err := newTestAnonymizer(t, repo, mailer).RunOnce(context.Background())
+	assert.EqualError(t, err, "smtp down")
+	assert.Zero(t, warned)
+	assert.Zero(t, anonymized)
+	assert.Empty(t, repo.warned)
+	assert.Empty(t, repo.anonymized)
+}
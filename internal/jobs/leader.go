@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"hash/fnv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// PostgresElector leads while it holds a session-level advisory lock. The lock lives on one dedicated
+// connection and is released by Postgres itself when that connection or the instance dies.
+type PostgresElector struct {
+	db       *sql.DB
+	key      int64
+	interval time.Duration
+	logger   *zap.SugaredLogger
+}
+
+// NewPostgresElector competes for the advisory lock named name, retrying and checking the connection every interval
+func NewPostgresElector(db *sql.DB, name string, interval time.Duration, logger *zap.SugaredLogger) *PostgresElector {
+	return &PostgresElector{db: db, key: lockKey(name), interval: interval, logger: logger}
+}
+
+// lockKey maps a lock name on the bigint key space of the advisory locks
+func lockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
+
+func (e *PostgresElector) Lead(ctx context.Context) (context.Context, error) {
+	for {
+		conn, err := e.tryLock(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warnw("Advisory lock unavailable", "error", err)
+		}
+		if conn != nil {
+			leading, cancel := context.WithCancel(ctx)
+			go e.hold(leading, cancel, conn)
+			return leading, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// tryLock returns the connection holding the lock, or nil while another session holds it
+func (e *PostgresElector) tryLock(ctx context.Context) (*sql.Conn, error) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&locked)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// hold pings the lock's connection until ctx ends, then unlocks; losing the connection ends the leadership
+func (e *PostgresElector) hold(ctx context.Context, cancel context.CancelFunc, conn *sql.Conn) {
+	defer cancel()
+	defer conn.Close()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The connection goes back to the pool, so the lock must not stay with it
+			unlockCtx, cancelUnlock := context.WithTimeout(context.Background(), e.interval)
+			defer cancelUnlock()
+			if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", e.key); err != nil {
+				e.logger.Warnw("Releasing the advisory lock failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+				e.logger.Errorw("Advisory lock connection lost", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// Only the holder of the lease may extend or release it
+var (
+	renewLease = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLease = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisElector leads while it holds a lease: a key set with NX and a TTL, extended every third of the TTL.
+// A leader that stops renewing loses the key once the TTL passes.
+type RedisElector struct {
+	client *redis.Client
+	key    string
+	ttl    time.Duration
+	logger *zap.SugaredLogger
+}
+
+func NewRedisElector(client *redis.Client, key string, ttl time.Duration, logger *zap.SugaredLogger) *RedisElector {
+	return &RedisElector{client: client, key: key, ttl: ttl, logger: logger}
+}
+
+func (e *RedisElector) Lead(ctx context.Context) (context.Context, error) {
+	token, err := leaseToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		acquired, err := e.client.SetNX(ctx, e.key, token, e.ttl).Result()
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warnw("Scheduler lease unavailable", "error", err)
+		}
+		if acquired {
+			leading, cancel := context.WithCancel(ctx)
+			go e.hold(leading, cancel, token)
+			return leading, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(e.ttl / 3):
+		}
+	}
+}
+
+// hold renews the lease until ctx ends, then releases it; a lease found taken over or expired ends the leadership
+func (e *RedisElector) hold(ctx context.Context, cancel context.CancelFunc, token string) {
+	defer cancel()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancelRelease := context.WithTimeout(context.Background(), e.ttl/3)
+			defer cancelRelease()
+			if err := releaseLease.Run(releaseCtx, e.client, []string{e.key}, token).Err(); err != nil {
+				e.logger.Warnw("Releasing the scheduler lease failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+			renewed, err := renewLease.Run(ctx, e.client, []string{e.key}, token, e.ttl.Milliseconds()).Int()
+			if ctx.Err() != nil {
+				continue
+			}
+			if err != nil {
+				// A failed renewal is retried on the next tick until the lease may have expired
+				e.logger.Warnw("Renewing the scheduler lease failed", "error", err)
+				if time.Since(renewedAt) < e.ttl {
+					continue
+				}
+				renewed = 0
+			}
+			if renewed == 0 {
+				e.logger.Errorw("Scheduler lease lost")
+				return
+			}
+			renewedAt = time.Now()
+		}
+	}
+}
+
+func leaseToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+)
+
+// KindSendMail sends one email with the configured mailer
+const KindSendMail = "mail.send"
+
+// QueuedMailer hands messages to the queue, so a slow or unavailable mail provider never holds up a request
+type QueuedMailer struct {
+	queue *Queue
+}
+
+func NewQueuedMailer(queue *Queue) *QueuedMailer {
+	return &QueuedMailer{queue: queue}
+}
+
+func (m *QueuedMailer) Send(ctx context.Context, msg *mail.Message) error {
+	return m.queue.Enqueue(ctx, KindSendMail, msg)
+}
+
+// SendMail is the handler of KindSendMail, sending with mailer
+func SendMail(mailer mail.Mailer) Handler {
+	return func(ctx context.Context, payload []byte) error {
+		msg := &mail.Message{}
+		if err := json.Unmarshal(payload, msg); err != nil {
+			return fmt.Errorf("%w: %v", ErrSkipRetry, err)
+		}
+		return mailer.Send(ctx, msg)
+	}
+}
@@ -0,0 +1,292 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/metrics"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// Handler performs one job of a kind, given the JSON payload it was enqueued with.
+// Returning an error retries the job with backoff; wrap ErrSkipRetry to fail it for good.
+type Handler func(ctx context.Context, payload []byte) error
+
+// ErrSkipRetry marks an error that another attempt cannot fix, such as a malformed payload
+var ErrSkipRetry = errors.New("skip retry")
+
+var jobsProcessed = metrics.Default.NewCounterVec("jobs_processed_total",
+	"Background job attempts by kind and result (succeeded, retried, postponed or dead).", "kind", "result")
+
+// Postpone wraps a handler's error to put the job back until at without using up an attempt, e.g. while the
+// service the job talks to is known to be down
+func Postpone(err error, at time.Time) error {
+	return &postponedError{err: err, at: at}
+}
+
+type postponedError struct {
+	err error
+	at  time.Time
+}
+
+func (e *postponedError) Error() string { return e.err.Error() }
+func (e *postponedError) Unwrap() error { return e.err }
+
+type attemptKey struct{}
+
+// Attempt is the number of the attempt in progress, starting at 1, in the context of a handler
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// Backoff between attempts doubles from retryBaseDelay up to retryMaxDelay
+const (
+	retryBaseDelay = 5 * time.Second
+	retryMaxDelay  = time.Hour
+)
+
+// Queue runs jobs stored in the database on a pool of workers. Jobs survive restarts, are retried with
+// exponential backoff up to their MaxAttempts and then kept as dead for the dashboard to inspect and retry.
+type Queue struct {
+	repo         repositories.JobRepoInterface
+	workers      int
+	pollInterval time.Duration
+	timeout      time.Duration
+	maxAttempts  int
+	logger       *zap.SugaredLogger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	// systemKinds run without a tenant, see RegisterSystem
+	systemKinds map[string]bool
+}
+
+// NewQueue builds a queue whose workers give every job up to timeout and attempt it maxAttempts times by default
+func NewQueue(repo repositories.JobRepoInterface, workers int, pollInterval, timeout time.Duration, maxAttempts int, logger *zap.SugaredLogger) *Queue {
+	return &Queue{
+		repo:         repo,
+		workers:      workers,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+		maxAttempts:  maxAttempts,
+		logger:       logger,
+		handlers:     make(map[string]Handler),
+		systemKinds:  make(map[string]bool),
+	}
+}
+
+// Register makes the workers run handler for jobs of kind; register every kind before Run
+func (q *Queue) Register(kind string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = handler
+}
+
+// RegisterSystem is Register for jobs that span every tenant, such as the scheduled sweeps: their handler runs
+// without a tenant, while the jobs of other kinds run in the tenant that queued them
+func (q *Queue) RegisterSystem(kind string, handler Handler) {
+	q.Register(kind, handler)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.systemKinds[kind] = true
+}
+
+// EnqueueOption adjusts a job before it is stored
+type EnqueueOption func(job *models.Job)
+
+// RunAt delays the job until at
+func RunAt(at time.Time) EnqueueOption {
+	return func(job *models.Job) {
+		job.RunAt = at
+	}
+}
+
+// MaxAttempts overrides the configured number of attempts for the job
+func MaxAttempts(attempts int) EnqueueOption {
+	return func(job *models.Job) {
+		job.MaxAttempts = attempts
+	}
+}
+
+// Enqueue stores a job of kind with payload marshalled to JSON, in the tenant of ctx. Inside a transaction from
+// the TxManager the job is only visible to workers once the transaction commits.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload interface{}, opts ...EnqueueOption) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	job := &models.Job{
+		TenantID:    tenancy.Current(ctx),
+		Kind:        kind,
+		Payload:     string(body),
+		MaxAttempts: q.maxAttempts,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return q.repo.EnqueueJob(ctx, job)
+}
+
+// Run polls for due jobs every pollInterval and processes them on the workers until ctx is cancelled,
+// then waits for the jobs in progress
+func (q *Queue) Run(ctx context.Context) {
+	slots := make(chan struct{}, q.workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		free := q.workers - len(slots)
+		if free > 0 {
+			claimed, err := q.claim(ctx, free)
+			if err != nil {
+				q.logger.Errorw("Claiming jobs failed", "error", err)
+			}
+			for i := range claimed {
+				job := claimed[i]
+				slots <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+					q.process(ctx, &job)
+				}()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims the jobs that are due, up to the number of workers, and processes them one by one
+func (q *Queue) RunOnce(ctx context.Context) (int, error) {
+	claimed, err := q.claim(ctx, q.workers)
+	if err != nil {
+		return 0, err
+	}
+	for i := range claimed {
+		q.process(ctx, &claimed[i])
+	}
+	return len(claimed), nil
+}
+
+func (q *Queue) claim(ctx context.Context, limit int) ([]models.Job, error) {
+	q.mu.RLock()
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	q.mu.RUnlock()
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+	sort.Strings(kinds)
+
+	// A job is only reclaimed well after its timeout, so a slow worker is not raced by another
+	now := time.Now()
+	return q.repo.ClaimJobs(ctx, kinds, limit, now, now.Add(-2*q.timeout))
+}
+
+func (q *Queue) process(ctx context.Context, job *models.Job) {
+	q.mu.RLock()
+	handler, system := q.handlers[job.Kind], q.systemKinds[job.Kind]
+	q.mu.RUnlock()
+
+	jobCtx := context.WithValue(ctx, attemptKey{}, job.Attempts)
+	if !system {
+		jobCtx = tenancy.WithTenant(jobCtx, job.TenantID)
+	}
+	jobCtx, cancel := context.WithTimeout(jobCtx, q.timeout)
+	err := safely(jobCtx, handler, []byte(job.Payload))
+	cancel()
+
+	if err == nil {
+		jobsProcessed.Inc(job.Kind, "succeeded")
+		if err := q.repo.CompleteJob(ctx, job.ID); err != nil {
+			q.logger.Errorw("Completing job failed", "job_id", job.ID, "kind", job.Kind, "error", err)
+		}
+		return
+	}
+
+	var postponed *postponedError
+	if errors.As(err, &postponed) {
+		jobsProcessed.Inc(job.Kind, "postponed")
+		q.logger.Infow("Job postponed", "job_id", job.ID, "kind", job.Kind, "run_at", postponed.at, "error", err)
+		if err := q.repo.PostponeJob(ctx, job.ID, err.Error(), postponed.at); err != nil {
+			q.logger.Errorw("Postponing job failed", "job_id", job.ID, "kind", job.Kind, "error", err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts && !errors.Is(err, ErrSkipRetry) {
+		at := time.Now().Add(backoff(job.Attempts))
+		retryAt = &at
+		jobsProcessed.Inc(job.Kind, "retried")
+		q.logger.Warnw("Job failed, retrying", "job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts, "retry_at", at, "error", err)
+	} else {
+		jobsProcessed.Inc(job.Kind, "dead")
+		q.logger.Errorw("Job failed permanently", "job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts, "error", err)
+	}
+
+	if err := q.repo.FailJob(ctx, job.ID, err.Error(), retryAt); err != nil {
+		q.logger.Errorw("Recording job failure failed", "job_id", job.ID, "kind", job.Kind, "error", err)
+	}
+}
+
+// safely turns a panicking handler into a failed attempt instead of a crashed worker
+func safely(ctx context.Context, handler Handler, payload []byte) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+// backoff is the delay before the attempt following the given one
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// Dashboard is the read and repair side of the queue served to admins, who see the jobs of their tenant
+type Dashboard interface {
+	Stats(ctx context.Context) (models.JobStats, error)
+	ListJobs(ctx context.Context, state string, limit int) ([]models.Job, error)
+	RetryJob(ctx context.Context, id uint64) error
+}
+
+func (q *Queue) Stats(ctx context.Context) (models.JobStats, error) {
+	return q.repo.CountJobs(ctx)
+}
+
+func (q *Queue) ListJobs(ctx context.Context, state string, limit int) ([]models.Job, error) {
+	return q.repo.ListJobs(ctx, state, limit)
+}
+
+func (q *Queue) RetryJob(ctx context.Context, id uint64) error {
+	return q.repo.RetryJob(ctx, id)
+}
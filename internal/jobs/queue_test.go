@@ -0,0 +1,220 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeJobRepo keeps jobs in memory and claims every pending one regardless of RunAt
+type fakeJobRepo struct {
+	jobs []*models.Job
+}
+
+func (repo *fakeJobRepo) EnqueueJob(ctx context.Context, job *models.Job) error {
+	job.ID = uint64(len(repo.jobs) + 1)
+	job.State = models.JobPending
+	repo.jobs = append(repo.jobs, job)
+	return nil
+}
+
+func (repo *fakeJobRepo) ClaimJobs(ctx context.Context, kinds []string, limit int, now, staleBefore time.Time) ([]models.Job, error) {
+	var claimed []models.Job
+	for _, job := range repo.jobs {
+		if job.State == models.JobPending && len(claimed) < limit {
+			job.State = models.JobRunning
+			job.Attempts++
+			claimed = append(claimed, *job)
+		}
+	}
+	return claimed, nil
+}
+
+func (repo *fakeJobRepo) CompleteJob(ctx context.Context, id uint64) error {
+	repo.jobs[id-1].State = models.JobSucceeded
+	return nil
+}
+
+func (repo *fakeJobRepo) FailJob(ctx context.Context, id uint64, lastError string, retryAt *time.Time) error {
+	job := repo.jobs[id-1]
+	job.LastError = lastError
+	if retryAt == nil {
+		job.State = models.JobDead
+		return nil
+	}
+	job.State = models.JobPending
+	job.RunAt = *retryAt
+	return nil
+}
+
+func (repo *fakeJobRepo) PostponeJob(ctx context.Context, id uint64, lastError string, runAt time.Time) error {
+	job := repo.jobs[id-1]
+	job.State = models.JobPending
+	job.LastError = lastError
+	job.RunAt = runAt
+	job.Attempts--
+	return nil
+}
+
+func (repo *fakeJobRepo) CountJobs(ctx context.Context) (models.JobStats, error) {
+	stats := models.JobStats{}
+	for _, job := range repo.jobs {
+		stats[job.State]++
+	}
+	return stats, nil
+}
+
+func (repo *fakeJobRepo) ListJobs(ctx context.Context, state string, limit int) ([]models.Job, error) {
+	return nil, nil
+}
+
+func (repo *fakeJobRepo) RetryJob(ctx context.Context, id uint64) error {
+	return nil
+}
+
+func (repo *fakeJobRepo) DeleteSucceededJobs(ctx context.Context, finishedBefore time.Time, limit int) (int, error) {
+	return 0, nil
+}
+
+func newTestQueue(t *testing.T, repo *fakeJobRepo) *Queue {
+	return NewQueue(repo, 4, time.Millisecond, time.Second, 3, zaptest.NewLogger(t).Sugar())
+}
+
+func TestQueue_RetriesWithBackoffThenGivesUp(t *testing.T) {
+	repo := &fakeJobRepo{}
+	queue := newTestQueue(t, repo)
+	calls := 0
+	queue.Register("flaky", func(ctx context.Context, payload []byte) error {
+		calls++
+		assert.JSONEq(t, `{"to":"a@example.com"}`, string(payload))
+		return errors.New("smtp unavailable")
+	})
+	require.NoError(t, queue.Enqueue(context.Background(), "flaky", map[string]string{"to": "a@example.com"}))
+
+	before := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := queue.RunOnce(context.Background())
+		require.NoError(t, err)
+	}
+
+	job := repo.jobs[0]
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, models.JobDead, job.State)
+	assert.Equal(t, "smtp unavailable", job.LastError)
+	assert.True(t, job.RunAt.After(before.Add(retryBaseDelay*2-time.Second)), "the second retry waits twice as long")
+}
+
+func TestQueue_SkipRetryAndPanicsFailTheAttempt(t *testing.T) {
+	repo := &fakeJobRepo{}
+	queue := newTestQueue(t, repo)
+	queue.Register("malformed", func(ctx context.Context, payload []byte) error {
+		return fmt.Errorf("%w: bad payload", ErrSkipRetry)
+	})
+	queue.Register("panics", func(ctx context.Context, payload []byte) error {
+		panic("boom")
+	})
+	require.NoError(t, queue.Enqueue(context.Background(), "malformed", nil))
+	require.NoError(t, queue.Enqueue(context.Background(), "panics", nil))
+
+	processed, err := queue.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, models.JobDead, repo.jobs[0].State)
+	assert.Equal(t, models.JobPending, repo.jobs[1].State)
+	assert.Equal(t, "job panicked: boom", repo.jobs[1].LastError)
+}
+
+func TestQueue_RunsJobsInTheirTenant(t *testing.T) {
+	repo := &fakeJobRepo{}
+	queue := newTestQueue(t, repo)
+	tenants := map[string]interface{}{}
+	record := func(kind string) Handler {
+		return func(ctx context.Context, payload []byte) error {
+			tenantID, ok := tenancy.FromContext(ctx)
+			if ok {
+				tenants[kind] = tenantID
+			} else {
+				tenants[kind] = "none"
+			}
+			return nil
+		}
+	}
+	queue.Register("tenant", record("tenant"))
+	queue.RegisterSystem("system", record("system"))
+	require.NoError(t, queue.Enqueue(tenancy.WithTenant(context.Background(), 2), "tenant", nil))
+	require.NoError(t, queue.Enqueue(context.Background(), "system", nil))
+
+	_, err := queue.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), repo.jobs[0].TenantID)
+	assert.Equal(t, tenancy.DefaultTenantID, repo.jobs[1].TenantID, "jobs queued without a tenant belong to the default one")
+	assert.Equal(t, map[string]interface{}{"tenant": uint(2), "system": "none"}, tenants)
+}
+
+func TestQueue_DeliversQueuedEvents(t *testing.T) {
+	repo := &fakeJobRepo{}
+	queue := newTestQueue(t, repo)
+	sink := &recordingPublisher{}
+	queue.Register(KindDeliverEvent, DeliverEvent(sink))
+
+	event, err := events.New("urn:test", events.UserCreated, "users/1", map[string]int{"user_id": 1})
+	require.NoError(t, err)
+	require.NoError(t, NewQueuedPublisher(queue).Publish(context.Background(), event))
+	assert.Empty(t, sink.events, "publishing only enqueues")
+
+	_, err = queue.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, event.ID, sink.events[0].ID)
+	assert.JSONEq(t, `{"user_id":1}`, string(sink.events[0].Data))
+	assert.Equal(t, models.JobSucceeded, repo.jobs[0].State)
+}
+
+func TestQueue_SendsQueuedMail(t *testing.T) {
+	repo := &fakeJobRepo{}
+	queue := newTestQueue(t, repo)
+	mailer := &recordingMailer{}
+	queue.Register(KindSendMail, SendMail(mailer))
+
+	msg := &mail.Message{To: []string{"ann@example.com"}, Subject: "Hi", Text: "plain"}
+	require.NoError(t, NewQueuedMailer(queue).Send(context.Background(), msg))
+	assert.Empty(t, mailer.messages, "sending only enqueues")
+
+	_, err := queue.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*mail.Message{msg}, mailer.messages)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, retryBaseDelay, backoff(1))
+	assert.Equal(t, 4*retryBaseDelay, backoff(3))
+	assert.Equal(t, retryMaxDelay, backoff(50))
+}
+
+type recordingPublisher struct {
+	events []*events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+type recordingMailer struct {
+	messages []*mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/metrics"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+var scheduledRuns = metrics.Default.NewCounterVec("scheduler_runs_total",
+	"Periodic task runs on the leading instance by task and result (succeeded or failed).", "task", "result")
+
+// LeaderElector decides which of the replicas runs the periodic tasks
+type LeaderElector interface {
+	// Lead blocks until this instance leads and returns a context that ends once it no longer does.
+	// It only fails when ctx ends first; leadership is given up when ctx ends.
+	Lead(ctx context.Context) (context.Context, error)
+}
+
+// LocalElector always leads; it suits a single instance, e.g. with SQLite
+type LocalElector struct{}
+
+func (LocalElector) Lead(ctx context.Context) (context.Context, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// Task is a periodic piece of work, such as a cleanup or enqueuing one
+type Task func(ctx context.Context) error
+
+type scheduledTask struct {
+	name     string
+	interval time.Duration
+	run      Task
+}
+
+// persistRunsFrom is the shortest interval whose runs are recorded; running a more frequent task early on a
+// failover costs nothing, while recording it would write to the database every few seconds
+const persistRunsFrom = time.Minute
+
+// Scheduler runs tasks at fixed intervals on the leading instance only, so a deployment with several
+// replicas runs each of them once per interval. The start of every run is recorded in runs, and a new leader
+// runs a task once its interval has passed since then, or right away when it never ran.
+type Scheduler struct {
+	elector LeaderElector
+	runs    repositories.ScheduledRunRepoInterface
+	logger  *zap.SugaredLogger
+	tasks   []scheduledTask
+}
+
+// NewScheduler records the runs of tasks in runs; with nil runs a new leader runs every task right away
+func NewScheduler(elector LeaderElector, runs repositories.ScheduledRunRepoInterface, logger *zap.SugaredLogger) *Scheduler {
+	return &Scheduler{elector: elector, runs: runs, logger: logger}
+}
+
+// Every adds a task run every interval; add every task before Run
+func (s *Scheduler) Every(name string, interval time.Duration, task Task) {
+	s.tasks = append(s.tasks, scheduledTask{name: name, interval: interval, run: task})
+}
+
+// Enqueue returns a task that puts a job of kind on the queue, leaving the work itself to any worker
+func Enqueue(queue *Queue, kind string, payload interface{}) Task {
+	return func(ctx context.Context) error {
+		return queue.Enqueue(ctx, kind, payload)
+	}
+}
+
+// Run campaigns for leadership and runs the tasks while leading, until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		leading, err := s.elector.Lead(ctx)
+		if err != nil {
+			return
+		}
+		s.logger.Infow("Leading the scheduler", "tasks", len(s.tasks))
+		s.runTasks(leading)
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warnw("Lost the scheduler leadership")
+	}
+}
+
+// runTasks runs every task one interval after its last start until ctx ends
+func (s *Scheduler) runTasks(ctx context.Context) {
+	lastRuns := s.lastRuns(ctx)
+
+	var wg sync.WaitGroup
+	for _, task := range s.tasks {
+		wg.Add(1)
+		go func(task scheduledTask, lastRun time.Time) {
+			defer wg.Done()
+
+			next := lastRun.Add(task.interval)
+			for {
+				if wait := time.Until(next); wait > 0 {
+					timer := time.NewTimer(wait)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-timer.C:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+
+				started := time.Now()
+				s.runTask(ctx, task)
+				s.saveRun(ctx, task, started)
+				next = started.Add(task.interval)
+			}
+		}(task, lastRuns[task.name])
+	}
+	wg.Wait()
+}
+
+// lastRuns loads when the tasks last started; when that fails they run right away, as they would the first time
+func (s *Scheduler) lastRuns(ctx context.Context) map[string]time.Time {
+	if s.runs == nil {
+		return nil
+	}
+	lastRuns, err := s.runs.LastRuns(ctx)
+	if err != nil {
+		s.logger.Errorw("Loading the last runs of the scheduled tasks failed", "error", err)
+		return nil
+	}
+	return lastRuns
+}
+
+func (s *Scheduler) saveRun(ctx context.Context, task scheduledTask, started time.Time) {
+	if s.runs == nil || task.interval < persistRunsFrom || ctx.Err() != nil {
+		return
+	}
+	if err := s.runs.SaveRun(ctx, task.name, started); err != nil {
+		s.logger.Errorw("Recording the run of a scheduled task failed", "task", task.name, "error", err)
+	}
+}
+
+func (s *Scheduler) runTask(ctx context.Context, task scheduledTask) {
+	if err := task.run(ctx); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		scheduledRuns.Inc(task.name, "failed")
+		s.logger.Errorw("Scheduled task failed", "task", task.name, "error", err)
+		return
+	}
+	scheduledRuns.Inc(task.name, "succeeded")
+}
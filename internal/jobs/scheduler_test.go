@@ -0,0 +1,153 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+// termElector grants one leadership per context sent on grants, which lasts until the test cancels it
+type termElector struct {
+	grants chan context.Context
+}
+
+func (e *termElector) Lead(ctx context.Context) (context.Context, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case term := <-e.grants:
+		return term, nil
+	}
+}
+
+func TestScheduler_RunsTasksOnlyWhileLeading(t *testing.T) {
+	elector := &termElector{grants: make(chan context.Context)}
+	scheduler := NewScheduler(elector, nil, zaptest.NewLogger(t).Sugar())
+
+	var runs int32
+	scheduler.Every("count", time.Hour, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs), "a follower runs nothing")
+
+	// Every term starts with one run of each task
+	for term := 1; term <= 2; term++ {
+		leading, lose := context.WithCancel(ctx)
+		elector.grants <- leading
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == int32(term) }, time.Second, 5*time.Millisecond)
+		lose()
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
+
+func TestScheduler_KeepsRunningFailingTasks(t *testing.T) {
+	scheduler := NewScheduler(LocalElector{}, nil, zaptest.NewLogger(t).Sugar())
+
+	var runs int32
+	scheduler.Every("failing", 5*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Run(ctx)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, 5*time.Millisecond)
+}
+
+// memoryRuns keeps the last runs of the tasks in a map
+type memoryRuns struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func (m *memoryRuns) LastRuns(ctx context.Context) (map[string]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	runs := make(map[string]time.Time, len(m.runs))
+	for task, at := range m.runs {
+		runs[task] = at
+	}
+	return runs, nil
+}
+
+func (m *memoryRuns) SaveRun(ctx context.Context, task string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[task] = at
+	return nil
+}
+
+func TestScheduler_NewLeaderKeepsTheCadenceOfTheLastRuns(t *testing.T) {
+	runs := &memoryRuns{runs: map[string]time.Time{
+		"recent":  time.Now().Add(-10 * time.Minute),
+		"overdue": time.Now().Add(-2 * time.Hour),
+	}}
+	scheduler := NewScheduler(LocalElector{}, runs, zaptest.NewLogger(t).Sugar())
+
+	var recent, overdue, fresh int32
+	scheduler.Every("recent", time.Hour, func(ctx context.Context) error {
+		atomic.AddInt32(&recent, 1)
+		return nil
+	})
+	scheduler.Every("overdue", time.Hour, func(ctx context.Context) error {
+		atomic.AddInt32(&overdue, 1)
+		return nil
+	})
+	scheduler.Every("fresh", time.Hour, func(ctx context.Context) error {
+		atomic.AddInt32(&fresh, 1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	before := time.Now()
+	go scheduler.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&overdue) == 1 && atomic.LoadInt32(&fresh) == 1
+	}, time.Second, 5*time.Millisecond, "tasks whose interval passed, or that never ran, run at once")
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&recent), "a task that ran within its interval waits for the rest of it")
+
+	assert.Eventually(t, func() bool {
+		lastRuns, _ := runs.LastRuns(ctx)
+		return !lastRuns["overdue"].Before(before) && !lastRuns["fresh"].Before(before)
+	}, time.Second, 5*time.Millisecond, "the runs are recorded")
+}
+
+func TestLocalElector_FailsOnceCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LocalElector{}.Lead(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLockKey_IsStable(t *testing.T) {
+	assert.Equal(t, lockKey("usermanagement:scheduler"), lockKey("usermanagement:scheduler"))
+	assert.NotEqual(t, lockKey("usermanagement:scheduler"), lockKey("other"))
+}
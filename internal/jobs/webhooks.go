@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is the error of deliveries held back while an endpoint keeps failing
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitBreaker stops calls to an endpoint after threshold consecutive failures. Once cooldown has passed
+// one call is let through: success closes the circuit, failure opens it for another cooldown.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Allow reports whether a call may go ahead and, when it may not, until when the circuit stays open
+func (b *CircuitBreaker) Allow() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return time.Time{}, true
+	}
+	now := b.now()
+	if now.Before(b.openUntil) {
+		return b.openUntil, false
+	}
+	// Half open: this call probes the endpoint while the others keep waiting
+	b.openUntil = now.Add(b.cooldown)
+	return time.Time{}, true
+}
+
+// Record counts the outcome of a call that Allow let through
+func (b *CircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// WebhookDeliverer is the handler of KindDeliverEvent for a webhook. Every attempt is stored in
+// webhook_deliveries; while the endpoint's circuit is open, deliveries are postponed without using up attempts.
+type WebhookDeliverer struct {
+	webhook *events.WebhookPublisher
+	repo    repositories.WebhookDeliveryRepoInterface
+	breaker *CircuitBreaker
+	logger  *zap.SugaredLogger
+}
+
+func NewWebhookDeliverer(webhook *events.WebhookPublisher, repo repositories.WebhookDeliveryRepoInterface, breaker *CircuitBreaker, logger *zap.SugaredLogger) *WebhookDeliverer {
+	return &WebhookDeliverer{
+		webhook: webhook,
+		repo:    repo,
+		breaker: breaker,
+		logger:  logger,
+	}
+}
+
+func (d *WebhookDeliverer) Perform(ctx context.Context, payload []byte) error {
+	event := &events.Event{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return fmt.Errorf("%w: %v", ErrSkipRetry, err)
+	}
+
+	if until, ok := d.breaker.Allow(); !ok {
+		return Postpone(fmt.Errorf("%w for %s", ErrCircuitOpen, d.webhook.URL()), until)
+	}
+
+	started := time.Now()
+	status, err := d.webhook.Deliver(ctx, event)
+	d.breaker.Record(err == nil)
+
+	delivery := &models.WebhookDelivery{
+		TenantID:   tenancy.Current(ctx),
+		EventID:    event.ID,
+		URL:        d.webhook.URL(),
+		Attempt:    Attempt(ctx),
+		StatusCode: status,
+		Succeeded:  err == nil,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	// The attempt log is for inspection only; failing to write it must not redeliver a delivered event
+	if recordErr := d.repo.CreateWebhookDelivery(context.WithoutCancel(ctx), delivery); recordErr != nil {
+		d.logger.Errorw("Recording webhook delivery failed", "event_id", event.ID, "error", recordErr)
+	}
+	return err
+}
+
+// DeliveryLog is the history of webhook attempts served to admins
+type DeliveryLog interface {
+	ListWebhookDeliveries(ctx context.Context, eventID string, limit int) ([]models.WebhookDelivery, error)
+}
+
+// WebhookDeliveriesSweeper drops delivery attempts made more than retention ago
+type WebhookDeliveriesSweeper struct {
+	repo      repositories.WebhookDeliveryRepoInterface
+	retention time.Duration
+}
+
+func NewWebhookDeliveriesSweeper(repo repositories.WebhookDeliveryRepoInterface, retention time.Duration) *WebhookDeliveriesSweeper {
+	return &WebhookDeliveriesSweeper{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+func (sweeper *WebhookDeliveriesSweeper) Name() string {
+	return "webhook_deliveries"
+}
+
+func (sweeper *WebhookDeliveriesSweeper) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return sweeper.repo.DeleteWebhookDeliveries(ctx, now.Add(-sweeper.retention), limit)
+}
@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeDeliveryRepo struct {
+	deliveries []models.WebhookDelivery
+}
+
+func (repo *fakeDeliveryRepo) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	repo.deliveries = append(repo.deliveries, *delivery)
+	return nil
+}
+
+func (repo *fakeDeliveryRepo) ListWebhookDeliveries(ctx context.Context, eventID string, limit int) ([]models.WebhookDelivery, error) {
+	return repo.deliveries, nil
+}
+
+func (repo *fakeDeliveryRepo) DeleteWebhookDeliveries(ctx context.Context, createdBefore time.Time, limit int) (int, error) {
+	return 0, nil
+}
+
+func TestCircuitBreaker_OpensAfterThresholdAndProbesAfterCooldown(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Record(false)
+	_, ok := breaker.Allow()
+	assert.True(t, ok, "one failure keeps the circuit closed")
+
+	breaker.Record(false)
+	until, ok := breaker.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, now.Add(time.Minute), until)
+
+	// After the cooldown a single probe goes through
+	now = now.Add(time.Minute)
+	_, ok = breaker.Allow()
+	assert.True(t, ok)
+	_, ok = breaker.Allow()
+	assert.False(t, ok, "other calls wait for the probe")
+
+	breaker.Record(true)
+	_, ok = breaker.Allow()
+	assert.True(t, ok, "a successful probe closes the circuit")
+}
+
+func TestWebhookDeliverer_RecordsAttemptsAndPostponesWhileOpen(t *testing.T) {
+	var requests int32
+	consumer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer consumer.Close()
+
+	repo := &fakeJobRepo{}
+	queue := NewQueue(repo, 1, time.Millisecond, time.Second, 10, zaptest.NewLogger(t).Sugar())
+	deliveries := &fakeDeliveryRepo{}
+	deliverer := NewWebhookDeliverer(events.NewWebhookPublisher(consumer.URL), deliveries, NewCircuitBreaker(2, time.Hour), zaptest.NewLogger(t).Sugar())
+	queue.Register(KindDeliverEvent, deliverer.Perform)
+
+	event, err := events.New("urn:test", events.UserCreated, "users/1", nil)
+	require.NoError(t, err)
+	require.NoError(t, NewQueuedPublisher(queue).Publish(tenancy.WithTenant(context.Background(), 2), event))
+
+	for i := 0; i < 3; i++ {
+		_, err = queue.RunOnce(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "the open circuit holds the third attempt back")
+	require.Len(t, deliveries.deliveries, 2)
+	for i, delivery := range deliveries.deliveries {
+		assert.Equal(t, event.ID, delivery.EventID)
+		assert.Equal(t, uint(2), delivery.TenantID, "deliveries belong to the tenant that published the event")
+		assert.Equal(t, i+1, delivery.Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.StatusCode)
+		assert.False(t, delivery.Succeeded)
+	}
+
+	job := repo.jobs[0]
+	assert.Equal(t, models.JobPending, job.State)
+	assert.Equal(t, 2, job.Attempts, "postponing does not use up an attempt")
+	assert.Contains(t, job.LastError, "circuit open")
+	assert.True(t, job.RunAt.After(time.Now().Add(59*time.Minute)))
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New builds the logger of cfg's profile, zap's development or production configuration, adjusted by the
+// LOG_* settings: console or JSON encoding, output paths, sampling, caller and stack trace level. JSON entries
+// carry ISO 8601 times so log shippers (ELK, Loki) parse them without extra rules.
+// The returned level starts at the effective LOG_LEVEL and can be changed later, e.g. on config reload.
+func New(cfg *config.Config) (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevel()
+	err := level.UnmarshalText([]byte(cfg.EffectiveLogLevel()))
+	if err != nil {
+		return nil, level, err
+	}
+	var stacktraceLevel zapcore.Level
+	err = stacktraceLevel.UnmarshalText([]byte(cfg.EffectiveStacktraceLevel()))
+	if err != nil {
+		return nil, level, err
+	}
+
+	zapCfg := zap.NewProductionConfig()
+	if cfg.Profile().DevelopmentLogging {
+		zapCfg = zap.NewDevelopmentConfig()
+	}
+	zapCfg.Level = level
+	if len(cfg.LogOutputPaths) > 0 {
+		zapCfg.OutputPaths = cfg.LogOutputPaths
+	}
+	zapCfg.DisableCaller = !cfg.LogCaller
+	// zap would pick the stack trace level from Development; AddStacktrace below applies ours
+	zapCfg.DisableStacktrace = true
+
+	zapCfg.Encoding = cfg.EffectiveLogFormat()
+	if zapCfg.Encoding == "json" {
+		zapCfg.EncoderConfig = zap.NewProductionEncoderConfig()
+		zapCfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	}
+
+	zapCfg.Sampling = nil
+	if cfg.Profile().LogSampling && cfg.LogSamplingInitial > 0 && cfg.LogSamplingThereafter > 0 {
+		zapCfg.Sampling = &zap.SamplingConfig{Initial: cfg.LogSamplingInitial, Thereafter: cfg.LogSamplingThereafter}
+	}
+
+	logger, err := zapCfg.Build(zap.AddStacktrace(stacktraceLevel))
+	return logger, level, err
+}
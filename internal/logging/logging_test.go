@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+)
+
+func TestNew_JSONToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := &config.Config{
+		AppEnv:         config.EnvProduction,
+		LogOutputPaths: []string{path},
+		LogCaller:      true,
+	}
+
+	logger, _, err := New(cfg)
+	require.NoError(t, err)
+	logger.Sugar().Infow("User created", "user_id", 7)
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &entry))
+
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "User created", entry["msg"])
+	assert.Equal(t, float64(7), entry["user_id"])
+	assert.Contains(t, entry, "caller")
+	_, err = time.Parse("2006-01-02T15:04:05.000Z0700", entry["ts"].(string))
+	assert.NoError(t, err, "times are ISO 8601")
+}
+
+func TestNew_ConsoleOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := &config.Config{
+		AppEnv:         config.EnvProduction,
+		LogFormat:      "console",
+		LogOutputPaths: []string{path},
+	}
+
+	logger, _, err := New(cfg)
+	require.NoError(t, err)
+	logger.Info("plain text")
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "plain text")
+	assert.False(t, json.Valid(data))
+	assert.NotContains(t, string(data), ".go:", "LOG_CALLER=false leaves out the caller")
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+)
+
+// Message is one email; Text and HTML are alternative renderings of the same content, either may be empty
+type Message struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"text,omitempty"`
+	HTML    string   `json:"html,omitempty"`
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// AWSCaller signs and sends calls to AWS REST APIs, as secrets.AWSClient does
+type AWSCaller interface {
+	CallREST(ctx context.Context, host, service, method, path string, input, output interface{}) error
+}
+
+// NewMailer builds the mailer selected by MAIL_DRIVER; aws is only used by the SES driver
+func NewMailer(cfg *config.Config, aws AWSCaller, logger *zap.SugaredLogger) (Mailer, error) {
+	switch cfg.EffectiveMailDriver() {
+	case config.MailDriverLog:
+		return NewLogMailer(logger), nil
+	case config.MailDriverSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom, cfg.SMTPStartTLS), nil
+	case config.MailDriverSES:
+		return NewSESMailer(aws, cfg.MailFrom), nil
+	case config.MailDriverSendGrid:
+		return NewSendGridMailer(cfg.SendGridAPIKey, cfg.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", cfg.MailDriver)
+	}
+}
+
+// LogMailer only logs who would have received which subject, for development; bodies carry tokens and
+// personal data, so they are not logged
+type LogMailer struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogMailer(logger *zap.SugaredLogger) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+	}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	m.logger.Infow("email not sent (MAIL_DRIVER=log)", "to", msg.To, "subject", msg.Subject)
+	return nil
+}
@@ -0,0 +1,255 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	netmail "net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/text/language"
+)
+
+func TestBuild_MultipartAlternative(t *testing.T) {
+	msg := &Message{To: []string{"ann@example.com"}, Subject: "Привіт", Text: "plain body", HTML: "<p>html body</p>"}
+	raw, err := build("no-reply@example.com", msg, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Привіт", subject)
+	assert.Equal(t, "ann@example.com", parsed.Header.Get("To"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8: plain body", "text/html; charset=utf-8: <p>html body</p>"}, bodies)
+}
+
+func TestTemplates_RenderEscapesOnlyHTML(t *testing.T) {
+	templates, err := LoadTemplates("")
+	require.NoError(t, err)
+
+	msg, err := templates.Render(TemplateNotification, language.English, map[string]string{"Title": "New follower", "Body": "Tom & <Jerry> follow you", "SentAt": "May 1, 2024 15:00 EEST"}, "ann@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ann@example.com"}, msg.To)
+	assert.Equal(t, "New follower", msg.Subject)
+	assert.Equal(t, "Tom & <Jerry> follow you\n\nSent May 1, 2024 15:00 EEST\n", msg.Text)
+	assert.Equal(t, "<p>Tom &amp; &lt;Jerry&gt; follow you</p>\n<p>Sent May 1, 2024 15:00 EEST</p>\n", msg.HTML)
+
+	for _, name := range []string{TemplateVerifyEmail, TemplatePasswordReset} {
+		msg, err := templates.Render(name, language.English, map[string]string{"FirstName": "Ann", "URL": "https://example.com/t?x=1&y=2", "ExpiresIn": "1h"})
+		require.NoError(t, err, name)
+		assert.Contains(t, msg.Text, "https://example.com/t?x=1&y=2", name)
+		assert.Contains(t, msg.HTML, `href="https://example.com/t?x=1&amp;y=2"`, name)
+	}
+
+	// Every language has every template
+	for _, name := range []string{TemplateVerifyEmail, TemplatePasswordReset} {
+		msg, err := templates.Render(name, language.Ukrainian, map[string]string{"FirstName": "Ann", "URL": "https://example.com/t", "ExpiresIn": "1h"})
+		require.NoError(t, err, name)
+		assert.Contains(t, msg.Text, "Вітаємо, Ann!", name)
+	}
+	msg, err = templates.Render(TemplateNotification, language.Ukrainian, map[string]string{"Title": "Вітаємо!", "Body": "Ваш обліковий запис готовий.", "SentAt": "01.05.2024 15:00 EEST"})
+	require.NoError(t, err)
+	assert.Equal(t, "Вітаємо!", msg.Subject)
+	assert.Contains(t, msg.Text, "Надіслано 01.05.2024 15:00 EEST")
+
+	for _, lang := range []language.Tag{language.English, language.Ukrainian} {
+		msg, err := templates.Render(TemplateInvitation, lang, map[string]string{"Role": "moderator", "URL": "https://example.com/t?token=x", "ExpiresAt": "May 4, 2024 12:00 UTC"})
+		require.NoError(t, err, lang)
+		assert.Contains(t, msg.Text, "https://example.com/t?token=x", lang)
+		assert.Contains(t, msg.Text, "moderator", lang)
+	}
+
+	for _, lang := range []language.Tag{language.English, language.Ukrainian} {
+		msg, err := templates.Render(TemplateInactivityWarning, lang, map[string]string{"FirstName": "Ann", "AnonymizeAt": "May 4, 2024 12:00 UTC"})
+		require.NoError(t, err, lang)
+		assert.Contains(t, msg.Text, "Ann", lang)
+		assert.Contains(t, msg.HTML, "May 4, 2024 12:00 UTC", lang)
+	}
+
+	for _, lang := range []language.Tag{language.English, language.Ukrainian} {
+		msg, err := templates.Render(TemplateSecurityIncident, lang, map[string]interface{}{"ID": 7, "Signal": "ip", "Subject": "203.0.113.7", "Failures": 20})
+		require.NoError(t, err, lang)
+		assert.Contains(t, msg.Subject, "20", lang)
+		assert.Contains(t, msg.Text, "203.0.113.7", lang)
+	}
+
+	for _, lang := range []language.Tag{language.English, language.Ukrainian} {
+		msg, err := templates.Render(TemplateLoginAlert, lang, map[string]string{"FirstName": "Ann", "Time": "May 1, 2024 12:00 UTC", "Device": "curl/8.0", "IP": "203.0.113.7", "Country": "UA", "URL": "https://example.com/deny?token=x", "ExpiresAt": "May 8, 2024 12:00 UTC"})
+		require.NoError(t, err, lang)
+		assert.Contains(t, msg.Text, "https://example.com/deny?token=x", lang)
+		assert.Contains(t, msg.HTML, "UA", lang)
+	}
+
+	_, err = templates.Render("missing", language.English, nil)
+	assert.EqualError(t, err, `unknown email template "missing"`)
+}
+
+func TestTemplates_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(file, source string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(source), 0o644))
+	}
+	write("en/notification.tmpl", `{{define "subject"}}[Acme] {{.Title}}{{end}}{{define "text"}}{{.Body}}{{end}}{{define "html"}}<b>{{.Body}}</b>{{end}}`)
+	write("en-GB/notification.tmpl", `{{define "subject"}}[Acme UK] {{.Title}}{{end}}{{define "text"}}{{.Body}}{{end}}{{define "html"}}<b>{{.Body}}</b>{{end}}`)
+	write("de/notification.tmpl", `{{define "subject"}}[Acme] {{.Title}}{{end}}{{define "text"}}Hallo, {{.Body}}{{end}}{{define "html"}}<p>Hallo, {{.Body}}</p>{{end}}`)
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+	data := map[string]string{"Title": "Hi", "Body": "Tom & Jerry", "SentAt": "now"}
+
+	msg, err := templates.Render(TemplateNotification, language.English, data)
+	require.NoError(t, err)
+	assert.Equal(t, "[Acme] Hi", msg.Subject, "the override replaces the embedded template")
+	assert.Equal(t, "<b>Tom &amp; Jerry</b>\n", msg.HTML)
+	msg, err = templates.Render(TemplateNotification, language.BritishEnglish, data)
+	require.NoError(t, err)
+	assert.Equal(t, "[Acme UK] Hi", msg.Subject, "a locale with a region wins over its language")
+	msg, err = templates.Render(TemplateNotification, language.German, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Hallo, Tom & Jerry\n", msg.Text, "overrides add locales")
+	msg, err = templates.Render(TemplateVerifyEmail, language.German, map[string]string{"FirstName": "Ann", "URL": "https://example.com/t", "ExpiresIn": "1h"})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "Ann", "templates a locale lacks fall back to English")
+
+	write("en/notifcation.tmpl", `{{define "subject"}}{{end}}{{define "text"}}{{end}}{{define "html"}}{{end}}`)
+	_, err = LoadTemplates(dir)
+	assert.ErrorContains(t, err, `unknown email template "notifcation"`)
+	require.NoError(t, os.Remove(filepath.Join(dir, "en/notifcation.tmpl")))
+
+	write("en/invitation.tmpl", `{{define "subject"}}Join us{{end}}{{define "text"}}{{.URL}}{{end}}`)
+	_, err = LoadTemplates(dir)
+	assert.ErrorContains(t, err, `should define a "subject", a "text" and an "html" template`)
+}
+
+func TestSendGridMailer(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sg-key", r.Header.Get("Authorization"))
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	mailer := NewSendGridMailer("sg-key", "no-reply@example.com")
+	mailer.endpoint = server.URL
+	err := mailer.Send(context.Background(), &Message{To: []string{"ann@example.com"}, Subject: "Hi", Text: "plain", HTML: "<p>html</p>"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hi", received["subject"])
+	assert.Equal(t, map[string]interface{}{"email": "no-reply@example.com"}, received["from"])
+	content := received["content"].([]interface{})
+	require.Len(t, content, 2)
+	assert.Equal(t, "text/plain", content[0].(map[string]interface{})["type"])
+}
+
+type fakeAWS struct {
+	host, service, path string
+	input               interface{}
+}
+
+func (aws *fakeAWS) CallREST(ctx context.Context, host, service, method, path string, input, output interface{}) error {
+	aws.host, aws.service, aws.path, aws.input = host, service, path, input
+	return nil
+}
+
+func TestSESMailer(t *testing.T) {
+	aws := &fakeAWS{}
+	err := NewSESMailer(aws, "no-reply@example.com").Send(context.Background(), &Message{
+		To: []string{"ann@example.com"}, Subject: "Hi", Text: "plain",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "email", aws.host)
+	assert.Equal(t, "ses", aws.service)
+	assert.Equal(t, "/v2/email/outbound-emails", aws.path)
+	body, _ := json.Marshal(aws.input)
+	assert.JSONEq(t, `{
+		"FromEmailAddress": "no-reply@example.com",
+		"Destination": {"ToAddresses": ["ann@example.com"]},
+		"Content": {"Simple": {
+			"Subject": {"Data": "Hi", "Charset": "UTF-8"},
+			"Body": {"Text": {"Data": "plain", "Charset": "UTF-8"}}
+		}}
+	}`, string(body))
+}
+
+func TestSMTPMailer_RequiresStartTLS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		relay := textproto.NewConn(conn)
+		relay.PrintfLine("220 relay ready")
+		for {
+			line, err := relay.ReadLine()
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				relay.PrintfLine("250-relay\r\n250 8BITMIME")
+			case strings.HasPrefix(line, "QUIT"):
+				relay.PrintfLine("221 bye")
+				return
+			default:
+				relay.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	mailer := NewSMTPMailer(host, port, "", "", "no-reply@example.com", true)
+	err = mailer.Send(context.Background(), &Message{To: []string{"ann@example.com"}, Subject: "Hi", Text: "plain"})
+	assert.ErrorIs(t, err, ErrNoStartTLS)
+}
+
+func TestLogMailer_LogsNoBodies(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	err := NewLogMailer(zap.New(core).Sugar()).Send(context.Background(), &Message{To: []string{"ann@example.com"}, Subject: "Reset", Text: "token abc"})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "Reset", fields["subject"])
+	assert.NotContains(t, fields, "text")
+}
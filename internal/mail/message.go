@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// build renders msg as a MIME message: multipart/alternative when it has both a text and an HTML body
+func build(from string, msg *Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+
+	header("From", from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	id, err := messageID(from)
+	if err != nil {
+		return nil, err
+	}
+	header("Message-ID", id)
+	header("MIME-Version", "1.0")
+
+	if msg.Text == "" || msg.HTML == "" {
+		contentType, body := "text/plain; charset=utf-8", msg.Text
+		if msg.HTML != "" {
+			contentType, body = "text/html; charset=utf-8", msg.HTML
+		}
+		header("Content-Type", contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		return buf.Bytes(), writeQuotedPrintable(&buf, body)
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID is a random <id@domain> in the domain of the sender
+func messageID(from string) (string, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.TrimSuffix(from[at+1:], ">")
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">", nil
+}
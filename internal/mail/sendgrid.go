@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SendGridMailer sends through the SendGrid v3 Mail Send API
+type SendGridMailer struct {
+	apiKey string
+	from   string
+	// endpoint is overridden in tests
+	endpoint string
+	client   *http.Client
+}
+
+func NewSendGridMailer(apiKey, from string) *SendGridMailer {
+	return &SendGridMailer{
+		apiKey:   apiKey,
+		from:     from,
+		endpoint: "https://api.sendgrid.com/v3/mail/send",
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (m *SendGridMailer) Send(ctx context.Context, msg *Message) error {
+	to := make([]sendGridAddress, len(msg.To))
+	for i, address := range msg.To {
+		to[i] = sendGridAddress{Email: address}
+	}
+	// SendGrid wants the plain text first
+	var content []sendGridContent
+	if msg.Text != "" {
+		content = append(content, sendGridContent{Type: "text/plain", Value: msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": to}},
+		"from":             sendGridAddress{Email: m.from},
+		"subject":          msg.Subject,
+		"content":          content,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("sendgrid responded with status %d: %s", res.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"context"
+	"net/http"
+)
+
+// SESMailer sends through the Amazon SES v2 SendEmail API; the sender must be a verified identity
+type SESMailer struct {
+	aws  AWSCaller
+	from string
+}
+
+func NewSESMailer(aws AWSCaller, from string) *SESMailer {
+	return &SESMailer{
+		aws:  aws,
+		from: from,
+	}
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+func (m *SESMailer) Send(ctx context.Context, msg *Message) error {
+	body := map[string]sesContent{}
+	if msg.Text != "" {
+		body["Text"] = sesContent{Data: msg.Text, Charset: "UTF-8"}
+	}
+	if msg.HTML != "" {
+		body["Html"] = sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	}
+
+	input := map[string]interface{}{
+		"FromEmailAddress": m.from,
+		"Destination":      map[string][]string{"ToAddresses": msg.To},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": sesContent{Data: msg.Subject, Charset: "UTF-8"},
+				"Body":    body,
+			},
+		},
+	}
+	return m.aws.CallREST(ctx, "email", "ses", http.MethodPost, "/v2/email/outbound-emails", input, nil)
+}
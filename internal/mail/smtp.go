@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// ErrNoStartTLS is returned when requireTLS is set and the relay does not offer STARTTLS
+var ErrNoStartTLS = errors.New("smtp: the relay does not offer STARTTLS")
+
+// SMTPMailer submits messages to a relay, upgrading to TLS with STARTTLS. With requireTLS a relay that does
+// not offer it is refused; without it the message goes in the clear. Credentials are only sent over TLS
+// (or to localhost), as net/smtp enforces.
+type SMTPMailer struct {
+	host       string
+	port       string
+	username   string
+	password   string
+	from       string
+	requireTLS bool
+	timeout    time.Duration
+}
+
+func NewSMTPMailer(host, port, username, password, from string, requireTLS bool) *SMTPMailer {
+	return &SMTPMailer{
+		host:       host,
+		port:       port,
+		username:   username,
+		password:   password,
+		from:       from,
+		requireTLS: requireTLS,
+		timeout:    30 * time.Second,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	body, err := build(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, m.port))
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(m.timeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	} else if m.requireTLS {
+		return ErrNoStartTLS
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
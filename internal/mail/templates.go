@@ -0,0 +1,163 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"golang.org/x/text/language"
+)
+
+// Templates every flow can send; each file defines a "subject", a "text" and an "html" template
+const (
+	// TemplateVerifyEmail and TemplatePasswordReset take FirstName, URL and ExpiresIn
+	TemplateVerifyEmail   = "verify_email"
+	TemplatePasswordReset = "password_reset"
+	// TemplateNotification takes Title, Body and SentAt
+	TemplateNotification = "notification"
+	// TemplateInvitation takes Role, URL and ExpiresAt, and Organization when the invitee joins one
+	TemplateInvitation = "invitation"
+	// TemplateInactivityWarning takes FirstName and AnonymizeAt
+	TemplateInactivityWarning = "inactivity_warning"
+	// TemplateSecurityIncident takes ID, Signal, Subject and Failures
+	TemplateSecurityIncident = "security_incident"
+	// TemplateLoginAlert takes FirstName, Time, Device, IP, URL and ExpiresAt, and Country when it is known
+	TemplateLoginAlert = "login_alert"
+)
+
+//go:embed templates/*/*.tmpl
+var templatesFS embed.FS
+
+// template is one message in one language
+type template struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates renders messages from the embedded templates, in templates/<locale>/<name>.tmpl; the HTML part is
+// escaped by html/template. A locale is a language ("uk") or a language with a region ("pt-BR").
+type Templates struct {
+	byLocale map[string]map[string]template
+}
+
+// LoadTemplates parses every embedded template, so a broken one fails at startup rather than on first use. When
+// overrideDir is set, the templates in <overrideDir>/<locale>/<name>.tmpl replace the embedded ones of the same
+// locale and name, and add locales; a name no embedded template has is refused as a likely typo.
+func LoadTemplates(overrideDir string) (*Templates, error) {
+	templates := &Templates{byLocale: map[string]map[string]template{}}
+	if err := templates.parse(templatesFS, "templates", nil); err != nil {
+		return nil, err
+	}
+	if overrideDir == "" {
+		return templates, nil
+	}
+
+	known := make(map[string]bool, len(templates.byLocale["en"]))
+	for name := range templates.byLocale["en"] {
+		known[name] = true
+	}
+	if err := templates.parse(os.DirFS(overrideDir), ".", known); err != nil {
+		return nil, fmt.Errorf("email templates in %s: %w", overrideDir, err)
+	}
+	return templates, nil
+}
+
+// parse adds the templates in the locale directories under root of fsys, replacing those of the same locale and
+// name. Unless known is nil, only the names in it are allowed.
+func (t *Templates) parse(fsys fs.FS, root string, known map[string]bool) error {
+	locales, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return err
+	}
+
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+		dir := path.Join(root, locale.Name())
+		files, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return err
+		}
+
+		parsed := t.byLocale[locale.Name()]
+		if parsed == nil {
+			parsed = make(map[string]template, len(files))
+			t.byLocale[locale.Name()] = parsed
+		}
+		for _, file := range files {
+			if file.IsDir() || path.Ext(file.Name()) != ".tmpl" {
+				continue
+			}
+			name := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+			source := path.Join(dir, file.Name())
+			if known != nil && !known[name] {
+				return fmt.Errorf("unknown email template %q in %s", name, source)
+			}
+
+			text, err := texttemplate.ParseFS(fsys, source)
+			if err != nil {
+				return err
+			}
+			html, err := htmltemplate.ParseFS(fsys, source)
+			if err != nil {
+				return err
+			}
+			if text.Lookup("subject") == nil || text.Lookup("text") == nil || html.Lookup("html") == nil {
+				return fmt.Errorf("%s should define a \"subject\", a \"text\" and an \"html\" template", source)
+			}
+			parsed[name] = template{text: text, html: html}
+		}
+	}
+	return nil
+}
+
+// lookup returns the named template in the locale of lang, else in its language, else in the fallback language,
+// else in English
+func (t *Templates) lookup(name string, lang language.Tag) (template, bool) {
+	base, _ := lang.Base()
+	locales := []string{lang.String(), base.String()}
+	for _, tag := range []language.Tag{i18n.Fallback(), language.English} {
+		base, _ := tag.Base()
+		locales = append(locales, base.String())
+	}
+	for _, locale := range locales {
+		if found, ok := t.byLocale[locale][name]; ok {
+			return found, true
+		}
+	}
+	return template{}, false
+}
+
+// Render fills the named template in lang with data and addresses the message to the given recipients
+func (t *Templates) Render(name string, lang language.Tag, data interface{}, to ...string) (*Message, error) {
+	found, ok := t.lookup(name, lang)
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, plain, html bytes.Buffer
+	if err := found.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := found.text.ExecuteTemplate(&plain, "text", data); err != nil {
+		return nil, err
+	}
+	if err := found.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(plain.String()) + "\n",
+		HTML:    strings.TrimSpace(html.String()) + "\n",
+	}, nil
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+// Database metrics, kept apart from HTTP metrics by their db_ prefix
+var (
+	DBQueries = Default.NewCounterVec("db_queries_total",
+		"Database statements executed through GORM.", "table", "operation", "success")
+	DBQueryDuration = Default.NewHistogramVec("db_query_duration_seconds",
+		"Latency of database statements executed through GORM.", DurationBuckets, "table", "operation", "success")
+)
+
+// GormPlugin counts every GORM statement and times it, labelled by table, operation
+// (create, query, update, delete, row or raw) and success. A lookup that finds no record succeeds.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("metrics:start", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("metrics:observe", observe("create")); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("metrics:start", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:query").Register("metrics:observe", observe("query")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("metrics:start", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("metrics:observe", observe("update")); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("metrics:start", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("metrics:observe", observe("delete")); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("metrics:start", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("gorm:row").Register("metrics:observe", observe("row")); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("gorm:raw").Register("metrics:start", startTimer); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("metrics:observe", observe("raw"))
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			// Raw SQL and statements without a model
+			table = "unknown"
+		}
+		success := strconv.FormatBool(db.Error == nil || errors.Is(db.Error, gorm.ErrRecordNotFound))
+
+		DBQueries.Inc(table, operation, success)
+		DBQueryDuration.Observe(time.Since(start).Seconds(), table, operation, success)
+	}
+}
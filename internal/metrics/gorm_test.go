@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func counted(table, operation, success string) float64 {
+	DBQueries.mu.Lock()
+	defer DBQueries.mu.Unlock()
+	return DBQueries.values[strings.Join([]string{table, operation, success}, "\xff")]
+}
+
+func TestGormPlugin_CountsStatementsByTableOperationAndSuccess(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin{}))
+	require.NoError(t, db.AutoMigrate(&widget{}))
+
+	created := counted("widgets", "create", "true")
+	found := counted("widgets", "query", "true")
+	failed := counted("unknown", "raw", "false")
+
+	require.NoError(t, db.Create(&widget{Name: "gear"}).Error)
+	var w widget
+	require.NoError(t, db.First(&w).Error)
+	// Not finding a record is an answer, not a failure
+	assert.ErrorIs(t, db.First(&w, 42).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, db.Exec("SELECT * FROM missing_table").Error)
+
+	assert.Equal(t, created+1, counted("widgets", "create", "true"))
+	assert.Equal(t, found+2, counted("widgets", "query", "true"))
+	assert.Equal(t, failed+1, counted("unknown", "raw", "false"))
+
+	var out strings.Builder
+	require.NoError(t, Default.Write(&out))
+	assert.Contains(t, out.String(), `db_query_duration_seconds_count{table="widgets",operation="create",success="true"}`)
+}
@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds metrics and writes them in the Prometheus text exposition format
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+type collector interface {
+	write(w io.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes every metric of the registry, in registration order
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// Handler serves the registry to Prometheus scrapers
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	}
+}
+
+// vec keeps one series per combination of label values
+type vec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string][]string // key -> label values
+}
+
+func newVec(name, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels, series: make(map[string][]string)}
+}
+
+// key identifies the series of values, registering it on first use
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	if _, ok := v.series[key]; !ok {
+		v.series[key] = append([]string(nil), values...)
+	}
+	return key
+}
+
+// sortedKeys lists the series in a stable order, so scrapes are easy to diff
+func (v *vec) sortedKeys() []string {
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *vec) header(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, kind)
+}
+
+// labelPairs formats {name="value",...} with extra pairs appended, e.g. the le of a histogram bucket
+func (v *vec) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, value := range values {
+		pairs = append(pairs, v.labels[i]+"="+strconv.Quote(value))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+"="+strconv.Quote(extra[i+1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	vec
+	values map[string]float64
+}
+
+// NewCounterVec registers a counter; name should end in _total
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{vec: newVec(name, help, labels), values: make(map[string]float64)}
+	r.register(counter)
+	return counter
+}
+
+// Inc adds one to the series of the label values, given in the order of the labels
+func (c *CounterVec) Inc(values ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[c.key(values)]++
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w, "counter")
+	for _, key := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(c.series[key]), formatFloat(c.values[key]))
+	}
+}
+
+// HistogramVec counts observations into cumulative buckets, partitioned by labels
+type HistogramVec struct {
+	vec
+	buckets []float64
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// DurationBuckets suit latencies in seconds from a millisecond to ten seconds
+var DurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NewHistogramVec registers a histogram with the given upper bucket bounds, in increasing order
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{vec: newVec(name, help, labels), buckets: buckets, values: make(map[string]*histogram)}
+	r.register(h)
+	return h
+}
+
+// Observe records value in the series of the label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := h.key(values)
+	series, ok := h.values[key]
+	if !ok {
+		series = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = series
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+			break
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	for _, key := range h.sortedKeys() {
+		values, series := h.series[key], h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += series.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(values, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(values, "le", "+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(values), formatFloat(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(values), series.count)
+	}
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
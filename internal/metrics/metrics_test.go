@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WritesCountersAndHistograms(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("requests_total", "Requests served.", "method", "code")
+	latency := registry.NewHistogramVec("request_seconds", "Request latency.", []float64{0.1, 1}, "method")
+
+	requests.Inc("POST", "201")
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	latency.Observe(0.05, "GET")
+	latency.Observe(0.5, "GET")
+	latency.Observe(3, "GET")
+
+	var out strings.Builder
+	require.NoError(t, registry.Write(&out))
+	assert.Equal(t, `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{method="GET",code="200"} 2
+requests_total{method="POST",code="201"} 1
+# HELP request_seconds Request latency.
+# TYPE request_seconds histogram
+request_seconds_bucket{method="GET",le="0.1"} 1
+request_seconds_bucket{method="GET",le="1"} 2
+request_seconds_bucket{method="GET",le="+Inf"} 3
+request_seconds_sum{method="GET"} 3.55
+request_seconds_count{method="GET"} 3
+`, out.String())
+}
+
+func TestRegistry_EscapesLabelValues(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("errors_total", "Errors.", "message").Inc(`bad "quote"` + "\n")
+
+	var out strings.Builder
+	require.NoError(t, registry.Write(&out))
+	assert.Contains(t, out.String(), `errors_total{message="bad \"quote\"\n"} 1`)
+}
+
+func TestCounterVec_PanicsOnWrongLabelCount(t *testing.T) {
+	counter := NewRegistry().NewCounterVec("things_total", "Things.", "kind")
+	assert.Panics(t, func() { counter.Inc("a", "b") })
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("hits_total", "Hits.").Inc()
+
+	rec := httptest.NewRecorder()
+	registry.Handler()(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "hits_total 1\n")
+}
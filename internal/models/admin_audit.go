@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the admin audit
+const (
+	AdminAuditRoleChanged           = "user.role_changed"
+	AdminAuditUserUpdated           = "user.updated"
+	AdminAuditUserDeleted           = "user.deleted"
+	AdminAuditUserUnlocked          = "user.unlocked"
+	AdminAuditUserSuspended         = "user.suspended"
+	AdminAuditUserBanned            = "user.banned"
+	AdminAuditUserUnbanned          = "user.unbanned"
+	AdminAuditUserShadowBanned      = "user.shadow_banned"
+	AdminAuditUserShadowUnbanned    = "user.shadow_unbanned"
+	AdminAuditServiceAccountCreated = "service_account.created"
+)
+
+// Kinds of actors in the admin audit: people, and the service accounts programs act as
+const (
+	ActorUser           = "user"
+	ActorServiceAccount = "service_account"
+)
+
+// AdminAudit is one action an admin took on a user, kept apart from the user's own history and after the user is
+// gone. Details says what changed without personal data: the roles of a role change, the fields of an update.
+type AdminAudit struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	ActorID   uint      `json:"actor_id" gorm:"index:admin_audit_actor_id_idx"`
+	ActorType string    `json:"actor_type" gorm:"default:user"`
+	Action    string    `json:"action"`
+	TargetID  uint      `json:"target_id" gorm:"index:admin_audit_target_id_idx"`
+	Reason    string    `json:"reason"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:admin_audit_created_at_idx"`
+}
+
+func (AdminAudit) TableName() string {
+	return "admin_audit"
+}
+
+// BeforeCreate records whether the request was made by a service account, so every action gets its kind of actor
+func (a *AdminAudit) BeforeCreate(tx *gorm.DB) error {
+	if a.ActorType == "" {
+		a.ActorType = ActorType(tx.Statement.Context)
+	}
+	return nil
+}
+
+// ActorType is ActorServiceAccount for the requests of service accounts, ActorUser otherwise
+func ActorType(ctx context.Context) string {
+	if serviceAccount, _ := ctx.Value(ServiceAccountContextKey).(bool); serviceAccount {
+		return ActorServiceAccount
+	}
+	return ActorUser
+}
+
+// AdminAuditQuery selects audit entries, newest first; zero fields match every entry. Since is inclusive and Until
+// exclusive. BeforeID continues from an entry of the previous page.
+type AdminAuditQuery struct {
+	ActorID   uint
+	ActorType string
+	TargetID  uint
+	Action    string
+	Since     time.Time
+	Until     time.Time
+	BeforeID  uint64
+	Limit     int
+}
+
+// AdminAction is the admin taking an action and the reason they gave, recorded with it
+type AdminAction struct {
+	ActorID uint
+	Reason  string
+}
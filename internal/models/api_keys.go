@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey lets a program call the API as UserID, e.g. a service account, with the key in the X-API-Key header.
+// Only the SHA-256 of the key is kept; Prefix, its first characters, tells keys apart. Tier names the rate limit
+// the key's requests are held to, one of API_KEY_TIERS. Scopes, space separated, are the only permissions a key of a
+// service account has, whatever its role allows.
+type APIKey struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	KeyHash   string    `json:"-" gorm:"uniqueIndex"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Tier      string    `json:"tier"`
+	Scopes    string    `json:"scopes,omitempty"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// User is loaded when the key authenticates a request
+	User *User `json:"-"`
+}
+
+// Permissions are the scopes of the key, none rather than nil when it has none
+func (k *APIKey) Permissions() []string {
+	return append([]string{}, strings.Fields(k.Scopes)...)
+}
+
+// APIKeyUsage counts the requests made with a key on a UTC day, formatted as 2006-01-02. Limited are those
+// refused for the rate limit of the key's tier.
+type APIKeyUsage struct {
+	APIKeyID uint   `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Day      string `json:"day" gorm:"primaryKey;size:10"`
+	Requests int    `json:"requests"`
+	Limited  int    `json:"limited"`
+}
+
+// TableName keeps the table name singular, as it holds one counter per key and day
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
+
+// APIKeyUsageReport is the usage of a key over the last days, oldest first with days without requests left out,
+// and the limit it is held to: Limit requests per Window, or none when Limit is 0
+type APIKeyUsageReport struct {
+	APIKeyID uint          `json:"api_key_id"`
+	Tier     string        `json:"tier"`
+	Limit    int           `json:"limit"`
+	Window   string        `json:"window"`
+	Requests int           `json:"requests"`
+	Limited  int           `json:"limited"`
+	Days     []APIKeyUsage `json:"days"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Ban keeps a user from signing in until ExpiresAt and tells them Reason when they try. Unlike an AccountLock it
+// ends by itself; banning a banned user replaces their ban.
+type Ban struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index:bans_expires_at_idx"`
+	BannedBy  uint      `json:"banned_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// User is the banned user, loaded for the list of bans
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+type BanPage struct {
+	Data       []Ban `json:"data"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int   `json:"total"`
+	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+}
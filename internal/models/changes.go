@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Values of Change.Operation
+const (
+	ChangeOperationCreate = "create"
+	ChangeOperationUpdate = "update"
+	ChangeOperationDelete = "delete"
+)
+
+// ChangeFeedLock is the Postgres advisory lock that makes relays of the change feed take turns; the writers of
+// users never take it
+const ChangeFeedLock = 0x75736572636867
+
+// changedUsersKey holds the users a column update through Model(&User{}).Where(...) changes, from BeforeUpdate
+// to AfterUpdate
+const changedUsersKey = "models:changed_users"
+
+// Change records that a user was created, updated or deleted. Seq numbers the changes in the order the relay moved
+// them out of the outbox, one relay at a time, so a reader that has seen a Seq never misses a lower one committed
+// later.
+type Change struct {
+	Seq       uint64    `json:"seq" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	UserID    uint      `json:"user_id"`
+	Operation string    `json:"operation"`
+	ChangedAt time.Time `json:"changed_at"`
+	// User is the current version of the user, read with the feed; nil once the user is deleted
+	User *User `json:"user,omitempty" gorm:"-"`
+}
+
+func (Change) TableName() string {
+	return "user_changes"
+}
+
+// ChangeOutbox is a user mutation waiting to join the change feed. Writers append to the outbox in their own
+// transaction without waiting for each other; the relay numbers the committed entries into user_changes.
+type ChangeOutbox struct {
+	ID        uint64 `gorm:"primaryKey"`
+	TenantID  uint
+	UserID    uint
+	Operation string
+	ChangedAt time.Time
+}
+
+func (ChangeOutbox) TableName() string {
+	return "user_change_outbox"
+}
+
+// ChangesPage is one page of the change feed; NextSeq is the since_seq of the next page, or of the next poll
+// once HasMore is false
+type ChangesPage struct {
+	Data    []Change `json:"data"`
+	HasMore bool     `json:"has_more"`
+	NextSeq uint64   `json:"next_seq"`
+}
+
+// AfterCreate - a hook to append the new user to the change feed
+func (u *User) AfterCreate(tx *gorm.DB) (err error) {
+	return recordChange(tx, u, ChangeOperationCreate)
+}
+
+// AfterUpdate - a hook to append the update, or the soft delete, to the change feed. Column updates through
+// Model(&User{}).Where(...), like the rating refresh, record the users BeforeUpdate found.
+func (u *User) AfterUpdate(tx *gorm.DB) (err error) {
+	if u.ID == 0 {
+		changed, _ := tx.Statement.Settings.Load(changedUsersKey)
+		users, _ := changed.([]User)
+		for i := range users {
+			if err := recordChange(tx, &users[i], ChangeOperationUpdate); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	operation := ChangeOperationUpdate
+	if !u.DeletedAt.IsZero() {
+		operation = ChangeOperationDelete
+	}
+	return recordChange(tx, u, operation)
+}
+
+// findChangedUsers looks up the users a column update through Model(&User{}).Where(...) is about to change, whose
+// ids the hooks are not given, for AfterUpdate to record
+func findChangedUsers(tx *gorm.DB) error {
+	where, ok := tx.Statement.Clauses["WHERE"]
+	if !ok {
+		return nil
+	}
+	var users []User
+	err := tx.Session(&gorm.Session{NewDB: true}).Model(&User{}).Clauses(where.Expression).
+		Select("id", "tenant_id").Find(&users).Error
+	if err != nil {
+		return err
+	}
+	// On the statement itself: InstanceSet would start a new one
+	tx.Statement.Settings.Store(changedUsersKey, users)
+	return nil
+}
+
+func recordChange(tx *gorm.DB, user *User, operation string) error {
+	// A new session on the same connection, so the entry joins the running transaction
+	return tx.Session(&gorm.Session{NewDB: true}).Create(&ChangeOutbox{
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Operation: operation,
+		ChangedAt: time.Now(),
+	}).Error
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Event is a persisted CloudEvent; Seq gives the global emission order used for replays
+type Event struct {
+	Seq             uint64    `json:"seq" gorm:"primaryKey"`
+	EventID         string    `json:"id" gorm:"unique"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            string    `json:"data" gorm:"type:jsonb;default:null"`
+}
+
+// EventFilter selects a range of events; zero values leave that bound open
+type EventFilter struct {
+	FromSeq  uint64
+	ToSeq    uint64
+	FromTime time.Time
+	ToTime   time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Features that can be switched off at runtime
+const (
+	FeatureVoting       = "voting"
+	FeatureRegistration = "registration"
+)
+
+// DefaultFeatures is the state of every known feature when neither FEATURE_FLAGS nor an override sets it
+var DefaultFeatures = map[string]bool{
+	FeatureVoting:       true,
+	FeatureRegistration: true,
+}
+
+// FeatureFlag overrides a feature for every user (UserID 0) or for one user, taking precedence over the config
+type FeatureFlag struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex:feature_flags_name_user_id_key"`
+	UserID    uint      `json:"user_id,omitempty" gorm:"uniqueIndex:feature_flags_name_user_id_key"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FeatureState is the effective state of a feature and the overrides behind it
+type FeatureState struct {
+	Name      string        `json:"name"`
+	Enabled   bool          `json:"enabled"`
+	Overrides []FeatureFlag `json:"overrides,omitempty"`
+}
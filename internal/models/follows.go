@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Follow subscribes FollowerID to the profile of FollowedID: the follower is notified when the profile is updated
+type Follow struct {
+	FollowerID uint      `json:"follower_id" gorm:"primaryKey;autoIncrement:false"`
+	FollowedID uint      `json:"followed_id" gorm:"primaryKey;autoIncrement:false;index"`
+	TenantID   uint      `json:"-" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Follower is loaded in the followers of a user and Followed in the profiles a user follows
+	Follower *User `json:"follower,omitempty" gorm:"foreignKey:FollowerID"`
+	Followed *User `json:"followed,omitempty" gorm:"foreignKey:FollowedID"`
+}
+
+// FollowPage is one page of the followers or the followed profiles of a user, latest first
+type FollowPage struct {
+	Data       []Follow `json:"data"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	Total      int      `json:"total"`
+	TotalPages int      `json:"total_pages"`
+	HasNext    bool     `json:"has_next"`
+}
+
+// FollowCounts is how many users follow a user and how many the user follows, deleted users left out
+type FollowCounts struct {
+	UserID    uint `json:"user_id"`
+	Followers int  `json:"followers"`
+	Following int  `json:"following"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Identity providers a user can sign in with
+const (
+	ProviderPassword = "password"
+	ProviderGoogle   = "google"
+	ProviderGitHub   = "github"
+	ProviderSAML     = "saml"
+)
+
+// Providers lists every identity provider, in the order identities are listed
+var Providers = []string{ProviderPassword, ProviderGoogle, ProviderGitHub, ProviderSAML}
+
+// Identity links a user to an account at a provider; Subject is the provider's ID of that account. A user without
+// identities has only their password, and the password identity is stored once they link another.
+type Identity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"uniqueIndex:identities_tenant_provider_subject_key,priority:1"`
+	UserID    uint      `json:"-" gorm:"uniqueIndex:identities_user_id_provider_key,priority:1"`
+	Provider  string    `json:"provider" gorm:"uniqueIndex:identities_tenant_provider_subject_key,priority:2;uniqueIndex:identities_user_id_provider_key,priority:2"`
+	Subject   string    `json:"subject" gorm:"uniqueIndex:identities_tenant_provider_subject_key,priority:3"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserActivity is when a user last signed in, and when they were warned that their inactive account is about to
+// be anonymized; WarnedAt is the zero time once they sign in again
+type UserActivity struct {
+	UserID      uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	TenantID    uint      `json:"-" gorm:"index"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	WarnedAt    time.Time `json:"warned_at"`
+}
+
+func (UserActivity) TableName() string {
+	return "user_activity"
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gorm.io/gorm"
+)
+
+// Invitation lets the holder of its token sign up as Email with Role. Only the SHA-256 of the token is stored; the
+// token itself is only in the email. An invitation is pending until AcceptedAt is set.
+type Invitation struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	TenantID   uint   `json:"-" gorm:"index:invitations_tenant_email_idx,priority:1"`
+	Email      string `json:"email" gorm:"serializer:pii"`
+	EmailIndex string `json:"-" gorm:"index:invitations_tenant_email_idx,priority:2"` // blind index of Email, see pii.BlindIndex
+	Role       Role   `json:"role" gorm:"foreignKey:RoleID"`
+	RoleID     uint   `json:"-"`
+	InvitedBy  uint   `json:"invited_by"`
+	// OrganizationID is set when the invitee joins an organization on accepting, with OrganizationRole in it
+	OrganizationID   *uint      `json:"organization_id,omitempty"`
+	OrganizationRole string     `json:"organization_role,omitempty"`
+	TokenHash        string     `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	SentAt           time.Time  `json:"sent_at"`
+	AcceptedAt       *time.Time `json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Expired reports whether the invitation can no longer be accepted at now, until it is resent
+func (i *Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// BeforeSave keeps the email blind index in step with the email, as for users
+func (i *Invitation) BeforeSave(tx *gorm.DB) (err error) {
+	if i.Email != "" {
+		i.Email = NormalizeEmail(i.Email)
+		i.EmailIndex = pii.BlindIndex(i.Email)
+	}
+	return nil
+}
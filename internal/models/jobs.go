@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Job states: pending jobs wait for RunAt, running ones are claimed by a worker, failed attempts go back to
+// pending with a later RunAt until MaxAttempts is reached and the job is dead
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead"
+)
+
+// JobStates lists every state, in the order of a job's life
+var JobStates = []string{JobPending, JobRunning, JobSucceeded, JobDead}
+
+// Job is a unit of background work; Kind selects the registered handler and Payload is its JSON argument. It runs
+// in, and is only listed to, the tenant it was queued in.
+type Job struct {
+	ID          uint64     `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"-" gorm:"index"`
+	Kind        string     `json:"kind"`
+	Payload     string     `json:"payload" gorm:"type:jsonb"`
+	State       string     `json:"state" gorm:"index:jobs_state_run_at_idx,priority:1"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:jobs_state_run_at_idx,priority:2"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// JobStats counts the jobs in each state
+type JobStats map[string]int64
+
+// ScheduledRun is when the scheduler last started a periodic task, so a new leader keeps the task's cadence
+// instead of running it again at once
+type ScheduledRun struct {
+	Task      string    `gorm:"primaryKey"`
+	LastRunAt time.Time `gorm:"not null"`
+}
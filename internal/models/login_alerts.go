@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// KnownDevice is a device and country a user has signed in from. Fingerprint is the blind index of the user agent;
+// Country is empty without a GeoIP database or for addresses it does not locate.
+type KnownDevice struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	TenantID    uint      `json:"-" gorm:"index"`
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:known_devices_user_device_key,priority:1"`
+	Fingerprint string    `json:"-" gorm:"uniqueIndex:known_devices_user_device_key,priority:2"`
+	Country     string    `json:"country" gorm:"uniqueIndex:known_devices_user_device_key,priority:3;size:2"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// LoginAlert is the "this wasn't me" link mailed for a login from a new device or country; only the SHA-256 of
+// its token is kept
+type LoginAlert struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index:login_alerts_expires_at_idx"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountLock keeps a user from signing in with their password until an admin removes it
+type AccountLock struct {
+	UserID   uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	TenantID uint      `json:"-" gorm:"index"`
+	Reason   string    `json:"reason"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+// Reasons accounts are locked for
+const (
+	LockReasonLoginDenied = "login_denied"
+	LockReasonSuspended   = "suspended"
+)
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Notification channels; in-app notifications are the ones listed by GET /me/notifications
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// DefaultNotificationChannels is the state of every channel for a user who has not chosen
+var DefaultNotificationChannels = map[string]bool{
+	ChannelInApp: true,
+	ChannelEmail: true,
+	ChannelSMS:   false,
+}
+
+// Notification types
+const (
+	NotificationWelcome        = "welcome"
+	NotificationVoteReceived   = "vote.received"
+	NotificationProfileUpdated = "profile.updated"
+	NotificationNewFollower    = "follower.new"
+	// NotificationFollowedProfileUpdated goes to the followers of a user whose profile was updated
+	NotificationFollowedProfileUpdated = "followed_profile.updated"
+)
+
+// Notification tells a user about something that happened to their account; ReadAt is nil until it is read
+type Notification struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	TenantID  uint       `json:"-" gorm:"index"`
+	UserID    uint       `json:"user_id" gorm:"index:notifications_user_id_created_at_idx,priority:1"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index:notifications_user_id_created_at_idx,priority:2"`
+}
+
+// NotificationPreference switches one channel on or off for a user, overriding DefaultNotificationChannels
+type NotificationPreference struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Channel   string    `json:"channel" gorm:"primaryKey"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPage is one page of a user's notifications, newest first
+type NotificationPage struct {
+	Data       []Notification `json:"data"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	Total      int            `json:"total"`
+	TotalPages int            `json:"total_pages"`
+	HasNext    bool           `json:"has_next"`
+	Unread     int            `json:"unread"`
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Scopes third-party clients can be granted. A token issued to a client is only accepted by the routes of its
+// scopes.
+const (
+	// ScopeProfile reads the user's profile, GET /me
+	ScopeProfile = "profile"
+	// ScopeNotifications reads the user's notifications and marks them read
+	ScopeNotifications = "notifications"
+)
+
+// OAuthScopes lists every scope a client can be registered with
+var OAuthScopes = []string{ScopeProfile, ScopeNotifications}
+
+// Grant types a client can be registered with
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+)
+
+// OAuthClient is a third-party application registered by an admin. Confidential clients have a secret, of which
+// only the bcrypt hash is kept; public ones (e.g. mobile apps) have none and can only use the authorization code
+// grant, with PKCE.
+type OAuthClient struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"index"`
+	ClientID     string    `json:"client_id" gorm:"uniqueIndex"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris" gorm:"serializer:json"`
+	GrantTypes   []string  `json:"grant_types" gorm:"serializer:json"`
+	Scopes       []string  `json:"scopes" gorm:"serializer:json"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (c *OAuthClient) Confidential() bool {
+	return c.SecretHash != ""
+}
+
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return contains(c.GrantTypes, grantType)
+}
+
+// AllowsRedirect reports whether uri is one of the registered redirect URIs, compared exactly
+func (c *OAuthClient) AllowsRedirect(uri string) bool {
+	return contains(c.RedirectURIs, uri)
+}
+
+// AllowsScope reports whether every scope in the space separated scope was registered for the client
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	for _, name := range strings.Fields(scope) {
+		if !contains(c.Scopes, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// OAuthCode is an authorization code a user approved for a client, exchanged once for a token before ExpiresAt.
+// Only the SHA-256 of the code is stored, and CodeChallenge is the S256 PKCE challenge the verifier has to match.
+type OAuthCode struct {
+	ID            uint64 `gorm:"primaryKey"`
+	TenantID      uint   `gorm:"index"`
+	CodeHash      string `gorm:"uniqueIndex"`
+	ClientID      uint   `gorm:"index"`
+	UserID        uint
+	RedirectURI   string
+	Scope         string
+	CodeChallenge string
+	ExpiresAt     time.Time `gorm:"index"`
+	CreatedAt     time.Time
+}
+
+// Expired reports whether the code can no longer be exchanged at now
+func (c *OAuthCode) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// OAuthConsent is the scopes a user granted a client, space separated
+type OAuthConsent struct {
+	TenantID  uint         `json:"-" gorm:"index"`
+	UserID    uint         `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	ClientID  uint         `json:"-" gorm:"primaryKey;autoIncrement:false;index"`
+	Scope     string       `json:"scope"`
+	Client    *OAuthClient `json:"client,omitempty" gorm:"foreignKey:ClientID"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// Covers reports whether the consent includes every scope in the space separated scope
+func (c *OAuthConsent) Covers(scope string) bool {
+	granted := strings.Fields(c.Scope)
+	for _, name := range strings.Fields(scope) {
+		if !contains(granted, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthorizationRequest is what a client asks a user to approve, as the query of its authorization URL
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// OAuthToken is the answer of the token endpoint
+type OAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// JoinScopes returns the scopes in the space separated lists, each once and in order of appearance
+func JoinScopes(scopes ...string) string {
+	var joined []string
+	for _, scope := range scopes {
+		for _, name := range strings.Fields(scope) {
+			if !contains(joined, name) {
+				joined = append(joined, name)
+			}
+		}
+	}
+	return strings.Join(joined, " ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
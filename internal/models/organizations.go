@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Organization groups users of a tenant; a user can be a member of any number of them, with a role in each
+type Organization struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-"`
+	Name      string    `json:"name"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Membership is the role of a user in an organization. Roles are named like the global ones: an organization
+// admin manages the members, and a global admin counts as an admin of every organization. A membership belongs
+// to the tenant of its organization.
+type Membership struct {
+	OrganizationID uint          `json:"organization_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID         uint          `json:"user_id" gorm:"primaryKey;autoIncrement:false;index"`
+	TenantID       uint          `json:"-" gorm:"index"`
+	Role           string        `json:"role"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	User           *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// roleRanks orders the roles from the least to the most privileged
+var roleRanks = map[string]int{StrUser: 1, StrModerator: 2, StrAdmin: 3}
+
+// RoleAtLeast reports whether role grants everything minimum does
+func RoleAtLeast(role, minimum string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[minimum]
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Permission is a named right, e.g. "users.delete", that roles are granted at runtime
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"unique"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permissions the routes require, see requireScope in the server. Admins have every one of them.
+const (
+	PermissionStatsRead             = "stats.read"
+	PermissionSecurityIncidentsRead = "security.incidents.read"
+	PermissionSecurityEventsRead    = "security.events.read"
+	PermissionAuditRead             = "audit.read"
+	PermissionReportsModerate       = "reports.moderate"
+)
+
+// RolePermission grants a permission to every user with the role
+type RolePermission struct {
+	RoleID       uint        `json:"role_id" gorm:"primaryKey;autoIncrement:false"`
+	PermissionID uint        `json:"permission_id" gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt    time.Time   `json:"created_at"`
+	Permission   *Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
+}
+
+// GrantedPermission is a permission a role has, granted to the role itself or to one of its ancestors
+type GrantedPermission struct {
+	Permission
+	GrantedTo uint `json:"granted_to"`
+}
+
+// Actions recorded in the permission audit
+const (
+	PermissionAuditCreated = "permission.created"
+	PermissionAuditUpdated = "permission.updated"
+	PermissionAuditDeleted = "permission.deleted"
+	PermissionAuditGranted = "permission.granted"
+	PermissionAuditRevoked = "permission.revoked"
+)
+
+// PermissionAudit is one change to the permissions or their grants. The permission name is copied so the entry
+// still reads after the permission is deleted; RoleID is 0 for changes to the permission itself.
+type PermissionAudit struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ActorID        uint      `json:"actor_id"`
+	Action         string    `json:"action"`
+	PermissionID   uint      `json:"permission_id"`
+	PermissionName string    `json:"permission_name"`
+	RoleID         uint      `json:"role_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (PermissionAudit) TableName() string {
+	return "permission_audit"
+}
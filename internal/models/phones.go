@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gorm.io/gorm"
+)
+
+// Phone is the phone number of a user in E.164 form, e.g. +380501234567. It counts once VerifiedAt is set, after the
+// user entered a code texted to it; a number is verified by one user per tenant at most, who can then sign in with
+// it and a texted code. With SecondFactor the user's password logins also need a code texted to it.
+type Phone struct {
+	UserID       uint       `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	TenantID     uint       `json:"-" gorm:"uniqueIndex:phones_tenant_number_key,priority:1,where:verified_at IS NOT NULL"`
+	Number       string     `json:"number" gorm:"serializer:pii"`
+	NumberIndex  string     `json:"-" gorm:"uniqueIndex:phones_tenant_number_key,priority:2,where:verified_at IS NOT NULL"` // blind index of Number, see pii.BlindIndex
+	VerifiedAt   *time.Time `json:"verified_at"`
+	SecondFactor bool       `json:"second_factor"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PhoneNumberBlindIndex is the blind index phones are looked up by the number with
+func PhoneNumberBlindIndex(number string) string {
+	return pii.BlindIndex(number)
+}
+
+// BeforeSave keeps the blind index in step with the number, which is stored encrypted
+func (p *Phone) BeforeSave(tx *gorm.DB) error {
+	if p.Number != "" {
+		p.NumberIndex = PhoneNumberBlindIndex(p.Number)
+	}
+	return nil
+}
+
+// What phone codes are sent for
+const (
+	PhoneCodeVerify       = "verify"
+	PhoneCodeSecondFactor = "second_factor"
+	PhoneCodeLogin        = "login"
+)
+
+// PhoneCode is a one-time code texted to a user's phone for Purpose, one per user and purpose at a time. Only the
+// SHA-256 of the code is kept; a second factor code also has the SHA-256 of the challenge the login answered with,
+// which has to come back with the code. A code is used up by Attempts wrong guesses.
+type PhoneCode struct {
+	ID            uint64    `json:"-" gorm:"primaryKey"`
+	TenantID      uint      `json:"-" gorm:"index"`
+	UserID        uint      `json:"-" gorm:"uniqueIndex:phone_codes_user_purpose_key,priority:1"`
+	Purpose       string    `json:"-" gorm:"uniqueIndex:phone_codes_user_purpose_key,priority:2"`
+	CodeHash      string    `json:"-"`
+	ChallengeHash string    `json:"-" gorm:"index"`
+	Attempts      int       `json:"-"`
+	ExpiresAt     time.Time `json:"-" gorm:"index:phone_codes_expires_at_idx"`
+	CreatedAt     time.Time `json:"-"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Quotas limit what a tenant can use; 0 means unlimited
+const (
+	QuotaUsers               = "users"
+	QuotaVotesPerDay         = "votes_per_day"
+	QuotaRequestsPerDay      = "requests_per_day"
+	QuotaOrganizationMembers = "organization_members"
+)
+
+// Quotas lists every quota, in the order usage is reported
+var Quotas = []string{QuotaUsers, QuotaVotesPerDay, QuotaRequestsPerDay, QuotaOrganizationMembers}
+
+// TenantQuota replaces the configured limit of a quota for one tenant
+type TenantQuota struct {
+	TenantID  uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"primaryKey"`
+	Limit     int       `json:"limit" gorm:"column:quota_limit"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TenantUsage counts the API requests of a tenant on a UTC day, formatted as 2006-01-02
+type TenantUsage struct {
+	TenantID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Day      string `gorm:"primaryKey;size:10"`
+	Requests int
+}
+
+// TableName keeps the table name singular, as it holds one counter per tenant and day
+func (TenantUsage) TableName() string {
+	return "tenant_usage"
+}
+
+// QuotaUsage is the limit of a quota for a tenant and how much of it is used. Per-day quotas count since midnight
+// UTC and organization_members counts the largest organization.
+type QuotaUsage struct {
+	Name  string `json:"name"`
+	Limit int    `json:"limit"`
+	Used  int    `json:"used"`
+}
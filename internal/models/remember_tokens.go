@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RememberToken is one token of a remember-me series, which signs a device in again without a password. Using a
+// token uses it up and hands out the next one of its family, valid for another term. Only the SHA-256 of the token
+// is kept, with the blind index of the user agent of the device it was handed to. A used token that comes back, or a
+// token from another device, means the series was copied, and the whole family is revoked.
+type RememberToken struct {
+	ID          uint64     `json:"-" gorm:"primaryKey"`
+	TenantID    uint       `json:"-" gorm:"index"`
+	UserID      uint       `json:"-" gorm:"index:remember_tokens_user_id_idx"`
+	FamilyID    string     `json:"-" gorm:"index:remember_tokens_family_id_idx"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex"`
+	Fingerprint string     `json:"-"`
+	UsedAt      *time.Time `json:"-"`
+	ExpiresAt   time.Time  `json:"-" gorm:"index:remember_tokens_expires_at_idx"`
+	CreatedAt   time.Time  `json:"-"`
+}
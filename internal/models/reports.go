@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Reasons users report other users for
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonImpersonation = "impersonation"
+	ReportReasonInappropriate = "inappropriate_content"
+	ReportReasonOther         = "other"
+)
+
+// Statuses of a report: pending in the moderation queue until a moderator dismisses it or acts on the user
+const (
+	ReportStatusPending   = "pending"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// Actions a moderator resolves a report with
+const (
+	ReportActionDismiss = "dismiss"
+	ReportActionSuspend = "suspend"
+	ReportActionDelete  = "delete"
+)
+
+// Report is a user flagging the profile of UserID to the moderators. ReporterID is only kept so a user cannot
+// report the same profile again while their report is pending; it is never shown, to moderators included.
+// Details is the reporter's free text, stored encrypted like other personal data.
+type Report struct {
+	ID         uint64     `json:"id" gorm:"primaryKey"`
+	TenantID   uint       `json:"-" gorm:"index"`
+	ReporterID uint       `json:"-" gorm:"index:reports_reporter_id_idx"`
+	UserID     uint       `json:"user_id" gorm:"index:reports_user_id_idx"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details,omitempty" gorm:"serializer:pii"`
+	Status     string     `json:"status" gorm:"index:reports_status_idx"`
+	Action     string     `json:"action,omitempty"`
+	ResolvedBy uint       `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// User is the reported user, loaded for the moderation queue
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// ReportQuery selects reports, oldest first so the queue is worked in order; zero fields match every report
+type ReportQuery struct {
+	Status string
+	Reason string
+	UserID uint
+}
+
+// ReportResolution is how a moderator resolved reports
+type ReportResolution struct {
+	Status     string
+	Action     string
+	ResolvedBy uint
+	ResolvedAt time.Time
+}
+
+type ReportPage struct {
+	Data       []Report `json:"data"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	Total      int      `json:"total"`
+	TotalPages int      `json:"total_pages"`
+	HasNext    bool     `json:"has_next"`
+}
@@ -9,13 +9,31 @@ const (
 	StrUser      = "user"
 )
 
+// Ways users sign up, which DEFAULT_ROLE and SIGNUP_ROLES give a role to
+const (
+	SignupSelf  = "self"
+	SignupSSO   = "sso"
+	SignupOAuth = "oauth"
+	SignupPhone = "phone"
+)
+
+var SignupSources = []string{SignupSelf, SignupSSO, SignupOAuth, SignupPhone}
+
 const (
 	RoleContextKey  contextKey = "role"
 	EmailContextKey contextKey = "email"
 	IDContextKey    contextKey = "id"
+	// RequestIDContextKey holds the X-Request-ID of the request, given by the client or generated
+	RequestIDContextKey contextKey = "request_id"
+	// ClientContextKey holds the *Client of the request
+	ClientContextKey contextKey = "client"
+	// ServiceAccountContextKey is true for the requests of service accounts
+	ServiceAccountContextKey contextKey = "service_account"
 )
 
+// Role is granted the permissions of its parent on top of its own, and through it those of every ancestor
 type Role struct {
-	ID   uint   `json:"role_id" gorm:"primaryKey"`
-	Name string `json:"name" gorm:"unique"`
+	ID       uint   `json:"role_id" gorm:"primaryKey"`
+	Name     string `json:"name" gorm:"unique"`
+	ParentID *uint  `json:"parent_id,omitempty"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Signals failed logins are counted by: the client address, the account and the user agent
+const (
+	SignalIP        = "ip"
+	SignalAccount   = "account"
+	SignalUserAgent = "user_agent"
+)
+
+// FailedLogin is a login refused for a wrong email or password, with what it is counted by
+type FailedLogin struct {
+	IP        string
+	Email     string
+	UserAgent string
+}
+
+// SecurityIncident records one signal reaching its threshold of failed logins. Subject is the address, the
+// email or the user agent the failures had in common, stored encrypted like other personal data.
+type SecurityIncident struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	Signal    string    `json:"signal"`
+	Subject   string    `json:"subject" gorm:"serializer:pii"`
+	Failures  int       `json:"failures"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:security_incidents_created_at_idx"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Types of security events, recorded for the user whose account they concern
+const (
+	SecurityEventLogin            = "login"
+	SecurityEventPasswordChanged  = "password_changed"
+	SecurityEventIdentityUnlinked = "identity_unlinked"
+	SecurityEventTokenRevoked     = "token_revoked"
+	SecurityEventNewDevice        = "new_device"
+	SecurityEventAccountLocked    = "account_locked"
+	SecurityEventAccountUnlocked  = "account_unlocked"
+	SecurityEventAccountBanned    = "account_banned"
+	SecurityEventAccountUnbanned  = "account_unbanned"
+	SecurityEventPhoneVerified    = "phone_verified"
+	SecurityEventPhoneRemoved     = "phone_removed"
+	// The second factor events have the kind of second factor, sms, as details
+	SecurityEventSecondFactorEnabled  = "second_factor_enabled"
+	SecurityEventSecondFactorDisabled = "second_factor_disabled"
+	// A remember-me series revoked as stolen has why as details: reused or other_device
+	SecurityEventRememberMeRevoked = "remember_me_revoked"
+)
+
+// SecurityEvent is something that happened to the security of a user's account, shown to the user and to admins
+// investigating an incident. IP and UserAgent are those of the request, stored encrypted like other personal data.
+// Details names what was involved without secrets: the provider of an identity, the client of revoked tokens.
+type SecurityEvent struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	UserID    uint      `json:"user_id" gorm:"index:security_events_user_id_idx"`
+	Type      string    `json:"type"`
+	IP        string    `json:"ip" gorm:"serializer:pii"`
+	UserAgent string    `json:"user_agent" gorm:"serializer:pii"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:security_events_created_at_idx"`
+}
+
+// SecurityEventQuery selects events, newest first; zero fields match every event. BeforeID continues from an event
+// of the previous page.
+type SecurityEventQuery struct {
+	UserID   uint
+	Type     string
+	BeforeID uint64
+	Limit    int
+}
+
+// Client is the address and user agent a request came from, kept in the context under ClientContextKey
+type Client struct {
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ShadowBan hides a user from everyone else without telling them: what they do still succeeds for them, but their
+// votes do not count towards ratings and their profile is left out of other users' views
+type ShadowBan struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	TenantID  uint      `json:"-" gorm:"index"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// User is the shadow-banned user, loaded for the list of shadow bans
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// ShadowBannedUsers selects the IDs of the shadow-banned users, for queries to leave them out with NOT IN. User IDs
+// are unique across tenants, so it needs no tenant scope.
+const ShadowBannedUsers = "SELECT shadow_bans.user_id FROM shadow_bans"
+
+type ShadowBanPage struct {
+	Data       []ShadowBan `json:"data"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	Total      int         `json:"total"`
+	TotalPages int         `json:"total_pages"`
+	HasNext    bool        `json:"has_next"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Stats are the counters of the admin dashboard. Users are counted while not deleted, signups whether or not the
+// user was deleted since; the windows end at GeneratedAt.
+type Stats struct {
+	TotalUsers   int64       `json:"total_users"`
+	ActiveUsers  int64       `json:"active_users"`
+	DeletedUsers int64       `json:"deleted_users"`
+	Signups      SignupStats `json:"signups"`
+	VotesCast    int64       `json:"votes_cast"`
+	// ActiveSince is the earliest sign-in that makes a user active
+	ActiveSince time.Time `json:"active_since"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// SignupStats counts the users created in the last day, 7 days and 30 days
+type SignupStats struct {
+	Day   int64 `json:"day"`
+	Week  int64 `json:"week"`
+	Month int64 `json:"month"`
+}
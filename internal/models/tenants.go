@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Tenant is an isolated customer space; users and votes belong to exactly one tenant
+type Tenant struct {
+	ID        uint      `json:"tenant_id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug" gorm:"unique"`
+	Host      *string   `json:"host,omitempty" gorm:"unique"` // hostname the tenant is served on, if any
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TermsAcceptance records that a user accepted a version of the terms of service and privacy policy
+type TermsAcceptance struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	UserID     uint      `json:"-" gorm:"uniqueIndex:idx_terms_acceptances_user_version"`
+	Version    string    `json:"version" gorm:"uniqueIndex:idx_terms_acceptances_user_version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// TermsStatus is whether a user accepted the current version, with every version the user accepted, newest first
+type TermsStatus struct {
+	CurrentVersion string            `json:"current_version"`
+	Accepted       bool              `json:"accepted"`
+	Acceptances    []TermsAcceptance `json:"acceptances"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserArchive is a user moved out of the users table by the archival job some time after it was soft-deleted.
+// The password hash is not carried over, archived accounts cannot sign in.
+type UserArchive struct {
+	ID            uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	TenantID      uint      `json:"-"`
+	Email         string    `json:"email" gorm:"serializer:pii"`
+	FirstName     string    `json:"first_name" gorm:"serializer:pii"`
+	LastName      string    `json:"last_name" gorm:"serializer:pii"`
+	RoleID        uint      `json:"role_id"`
+	Rating        int       `json:"rating"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	VoteUpdatedAt time.Time `json:"vote_updated_at"`
+	DeletedAt     time.Time `json:"deleted_at"`
+	ArchivedAt    time.Time `json:"archived_at"`
+}
+
+func (UserArchive) TableName() string {
+	return "users_archive"
+}
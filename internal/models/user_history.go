@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Values of UserHistory.Operation
+const (
+	HistoryOperationUpdate = "update"
+	HistoryOperationDelete = "delete"
+)
+
+// UserHistory is the version of a user as it was right before an update or deletion.
+// Rows are only ever appended; the password hash is deliberately not kept.
+type UserHistory struct {
+	ID            uint      `json:"history_id" gorm:"primaryKey"`
+	TenantID      uint      `json:"-" gorm:"index"`
+	UserID        uint      `json:"user_id" gorm:"index"`
+	Operation     string    `json:"operation"`
+	Email         string    `json:"email" gorm:"serializer:pii"`
+	FirstName     string    `json:"first_name" gorm:"serializer:pii"`
+	LastName      string    `json:"last_name" gorm:"serializer:pii"`
+	RoleID        uint      `json:"role_id"`
+	Rating        int       `json:"rating"`
+	VoteUpdatedAt time.Time `json:"vote_updated_at"`
+	DeletedAt     time.Time `json:"deleted_at"`
+	ValidFrom     time.Time `json:"valid_from"` // UpdatedAt of the recorded version
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+func (UserHistory) TableName() string {
+	return "users_history"
+}
+
+// BeforeUpdate - a hook to save the current version of the user before it is overwritten.
+// Soft deletes go through Save as well and are recorded as deletions.
+func (u *User) BeforeUpdate(tx *gorm.DB) (err error) {
+	// Column updates through Model(&User{}).Where(...), like the rating refresh, carry no id; they are not
+	// versions worth keeping, but the change feed records them
+	if u.ID == 0 {
+		return findChangedUsers(tx)
+	}
+
+	operation := HistoryOperationUpdate
+	if !u.DeletedAt.IsZero() {
+		operation = HistoryOperationDelete
+	}
+	return recordUserHistory(tx, u.ID, operation)
+}
+
+// BeforeDelete - a hook to save the user before a hard delete
+func (u *User) BeforeDelete(tx *gorm.DB) (err error) {
+	return recordUserHistory(tx, u.ID, HistoryOperationDelete)
+}
+
+func recordUserHistory(tx *gorm.DB, userID uint, operation string) error {
+	// Column updates through Model(&User{}).Where(...), like the rating refresh, carry no id
+	if userID == 0 {
+		return nil
+	}
+
+	// A new session on the same connection, so the history row joins the running transaction
+	db := tx.Session(&gorm.Session{NewDB: true})
+
+	var prior User
+	err := db.Where("id = ?", userID).Take(&prior).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Re-saving an already deleted user is an ordinary update of a deleted row
+	if operation == HistoryOperationDelete && !prior.DeletedAt.IsZero() {
+		operation = HistoryOperationUpdate
+	}
+
+	return db.Create(&UserHistory{
+		TenantID:      prior.TenantID,
+		UserID:        prior.ID,
+		Operation:     operation,
+		Email:         prior.Email,
+		FirstName:     prior.FirstName,
+		LastName:      prior.LastName,
+		RoleID:        prior.RoleID,
+		Rating:        prior.Rating,
+		VoteUpdatedAt: prior.VoteUpdatedAt,
+		DeletedAt:     prior.DeletedAt,
+		ValidFrom:     prior.UpdatedAt,
+		ChangedAt:     time.Now(),
+	}).Error
+}
@@ -1,14 +1,21 @@
 package models
 
 import (
+	"strings"
 	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
 )
 
 type User struct {
 	ID            uint      `json:"user_id" gorm:"primaryKey"`
-	Email         string    `json:"email"`
-	FirstName     string    `json:"first_name"`
-	LastName      string    `json:"last_name"`
+	TenantID      uint      `json:"-" gorm:"uniqueIndex:users_tenant_email_key,priority:1"`
+	Email         string    `json:"email" gorm:"serializer:pii"`
+	EmailIndex    string    `json:"-" gorm:"uniqueIndex:users_tenant_email_key,priority:2,where:deleted_at IS NULL OR deleted_at = '0001-01-01 00:00:00+00:00'"` // blind index of Email, see pii.BlindIndex; unique among users not deleted
+	FirstName     string    `json:"first_name" gorm:"serializer:pii"`
+	LastName      string    `json:"last_name" gorm:"serializer:pii"`
 	Password      string    `json:"-"`
 	Role          Role      `json:"role" gorm:"foreignKey:RoleID"`
 	RoleID        uint      `json:"-"` // RoleID is needed for the foreign key relationship but is not exposed in JSON
@@ -17,4 +24,79 @@ type User struct {
 	VoteUpdatedAt time.Time `json:"vote_updated_at"`
 	DeletedAt     time.Time `json:"-" gorm:"index"`
 	Rating        int       `json:"rating"`
+	// Timezone is an IANA name such as Europe/Kyiv and Locale a BCP 47 tag such as uk-UA; either may be empty
+	Timezone string `json:"timezone"`
+	Locale   string `json:"locale"`
+	// ServiceAccount is set on accounts programs call the API as with API keys; they have no password and can not
+	// sign in
+	ServiceAccount bool `json:"service_account"`
+}
+
+// Location is the user's time zone, UTC when none is set
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Language is the user's locale, or fallback when none is set
+func (u *User) Language(fallback language.Tag) language.Tag {
+	tag, err := language.Parse(u.Locale)
+	if u.Locale == "" || err != nil {
+		return fallback
+	}
+	return tag
+}
+
+// NormalizeEmail is the form emails are stored and looked up in, so addresses that differ only in case are one
+// account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(email)
+}
+
+// EmailBlindIndex is the blind index users and invitations are looked up by the email with
+func EmailBlindIndex(email string) string {
+	return pii.BlindIndex(NormalizeEmail(email))
+}
+
+// BeforeSave - a hook to keep the email blind index in step with the email, since the email itself is stored encrypted
+func (u *User) BeforeSave(tx *gorm.DB) (err error) {
+	if u.Email != "" {
+		u.Email = NormalizeEmail(u.Email)
+		u.EmailIndex = pii.BlindIndex(u.Email)
+	}
+	return nil
+}
+
+// UserPage is one page of the user list with the metadata clients need to paginate
+type UserPage struct {
+	Data       []User `json:"data"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	HasNext    bool   `json:"has_next"`
+}
+
+// UserChange is a user created, updated or deleted after a sync cursor. A deleted user is a tombstone: Deleted
+// is set and User left out.
+type UserChange struct {
+	UserID    uint      `json:"user_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Deleted   bool      `json:"deleted"`
+	User      *User     `json:"user,omitempty"`
+}
+
+// UserChangesPage is one page of changes in the order they were made. UpdatedSince and AfterID are the cursor
+// of the next page, to be passed back as is; the last page leaves it at the latest change, for the next sync.
+type UserChangesPage struct {
+	Data         []UserChange `json:"data"`
+	HasMore      bool         `json:"has_more"`
+	UpdatedSince time.Time    `json:"updated_since"`
+	AfterID      uint         `json:"after_id"`
 }
@@ -8,6 +8,7 @@ import (
 
 type Vote struct {
 	ID        uint      `json:"vote_id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"index"`
 	UserID    uint      `json:"user_id"`    // Voting user ID
 	ProfileID uint      `json:"profile_id"` // ID of the profile being voted for
 	Value     int       `json:"value"`      // Voice value (+1 or -1)
@@ -16,27 +17,57 @@ type Vote struct {
 
 // AfterSave - a hook to automatically update the rating after saving a vote
 func (v *Vote) AfterSave(tx *gorm.DB) (err error) {
-	var rating int
-	// Calculate a new rating for the profile that was voted for
-	err = tx.Model(&Vote{}).
-		Where("profile_id = ?", v.ProfileID).
-		Select("COALESCE(SUM(value), 0)").
-		Scan(&rating).Error
+	err = updateRating(tx, v.ProfileID)
 	if err != nil {
 		return err
 	}
 
-	// Update the rating in the user table
-	err = tx.Model(&User{}).Where("id = ?", v.ProfileID).Update("rating", rating).Error
+	// Update the UpdatedAt field for the profile
+	err = tx.Model(&User{}).Where("id = ?", v.UserID).Update("vote_updated_at", time.Now()).Error
 	if err != nil {
 		return err
 	}
 
-	// Update the UpdatedAt field for the profile
-	err = tx.Model(&User{}).Where("id = ?", v.UserID).Update("vote_updated_at", time.Now()).Error
+	return nil
+}
+
+// AfterDelete - a hook to take a revoked vote out of the rating; revoking is not voting, so the cooldown is left alone
+func (v *Vote) AfterDelete(tx *gorm.DB) (err error) {
+	return updateRating(tx, v.ProfileID)
+}
+
+// RecalculateRatings recalculates the ratings of the profiles the voter voted for, after the voter was shadow-banned
+// or the shadow ban lifted
+func RecalculateRatings(tx *gorm.DB, voterID uint) error {
+	var profileIDs []uint
+	err := tx.Model(&Vote{}).Where("user_id = ?", voterID).Pluck("profile_id", &profileIDs).Error
 	if err != nil {
 		return err
 	}
+	return RecalculateProfileRatings(tx, profileIDs)
+}
 
+// RecalculateProfileRatings recalculates the ratings of the profiles from their votes, for when votes went in bulk
+func RecalculateProfileRatings(tx *gorm.DB, profileIDs []uint) error {
+	for _, profileID := range profileIDs {
+		if err := updateRating(tx, profileID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// updateRating recalculates the rating of a profile from its votes, leaving out those of shadow-banned voters
+func updateRating(tx *gorm.DB, profileID uint) error {
+	var rating int
+	err := tx.Model(&Vote{}).
+		Where("profile_id = ? AND user_id NOT IN ("+ShadowBannedUsers+")", profileID).
+		Select("COALESCE(SUM(value), 0)").
+		Scan(&rating).Error
+	if err != nil {
+		return err
+	}
+
+	// Update the rating in the user table
+	return tx.Model(&User{}).Where("id = ?", profileID).Update("rating", rating).Error
+}
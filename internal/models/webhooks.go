@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// WebhookDelivery records one attempt to POST an event to a webhook. StatusCode is 0 when no response
+// arrived, e.g. on a timeout, and Error says why a failed attempt failed. A delivery belongs to the tenant whose
+// event it carried and is only listed to that tenant's admins.
+type WebhookDelivery struct {
+	ID         uint64    `json:"id" gorm:"primaryKey"`
+	TenantID   uint      `json:"-" gorm:"index"`
+	EventID    string    `json:"event_id" gorm:"index:webhook_deliveries_event_id_idx"`
+	URL        string    `json:"url"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Succeeded  bool      `json:"succeeded"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index:webhook_deliveries_created_at_idx"`
+}
@@ -0,0 +1,300 @@
+// Package openapi embeds the OpenAPI document of the API, served on /openapi.yaml, and checks responses against it.
+// The checks cover what the document uses: types, formats of timestamps, required and unknown properties, enums,
+// nullable values, oneOf and local $refs.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var document []byte
+
+// Document returns the OpenAPI document as YAML
+func Document() []byte {
+	return document
+}
+
+// ErrUndocumented is returned for responses of operations the document does not describe
+var ErrUndocumented = errors.New("openapi: the operation is not documented")
+
+// Schema is the part of a schema object the checks understand
+type Schema struct {
+	Ref                  string             `yaml:"$ref"`
+	Type                 string             `yaml:"type"`
+	Format               string             `yaml:"format"`
+	Nullable             bool               `yaml:"nullable"`
+	Required             []string           `yaml:"required"`
+	Properties           map[string]*Schema `yaml:"properties"`
+	AdditionalProperties *bool              `yaml:"additionalProperties"`
+	Items                *Schema            `yaml:"items"`
+	Enum                 []interface{}      `yaml:"enum"`
+	OneOf                []*Schema          `yaml:"oneOf"`
+}
+
+type mediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+type response struct {
+	Ref     string               `yaml:"$ref"`
+	Content map[string]mediaType `yaml:"content"`
+}
+
+type operation struct {
+	Responses map[string]response `yaml:"responses"`
+}
+
+// Spec is a parsed OpenAPI document
+type Spec struct {
+	Paths      map[string]map[string]yaml.Node `yaml:"paths"`
+	Components struct {
+		Schemas   map[string]*Schema  `yaml:"schemas"`
+		Responses map[string]response `yaml:"responses"`
+	} `yaml:"components"`
+}
+
+// Load parses the embedded document
+func Load() (*Spec, error) {
+	return Parse(document)
+}
+
+// Parse parses an OpenAPI document in YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	spec := &Spec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return spec, nil
+}
+
+// ValidateResponse checks that status is documented for the operation of method on target, a request URI such as
+// "/users/12?expand=role", and that body matches its schema. Documented responses without content must have an
+// empty body; bodies of other media types than JSON, such as the text/plain token of a login, are not checked. It
+// returns ErrUndocumented when no path of the document matches target or it has no such method.
+func (spec *Spec) ValidateResponse(method, target string, status int, body []byte) error {
+	op, template, err := spec.operation(method, target)
+	if err != nil {
+		return err
+	}
+	where := method + " " + template
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("openapi: %s does not document the status %d", where, status)
+	}
+	if resp.Ref != "" {
+		name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+		if resp, ok = spec.Components.Responses[name]; !ok {
+			return fmt.Errorf("openapi: %s refers to the unknown response %s", where, name)
+		}
+	}
+
+	body = bytes.TrimSpace(body)
+	content, ok := resp.Content["application/json"]
+	if !ok && len(resp.Content) > 0 {
+		return nil
+	}
+	if !ok || content.Schema == nil {
+		if len(body) > 0 {
+			return fmt.Errorf("openapi: %s %d documents no body but got %s", where, status, body)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("openapi: %s %d: the body is not JSON: %w", where, status, err)
+	}
+	if err := spec.validate(content.Schema, value, "$"); err != nil {
+		return fmt.Errorf("openapi: %s %d: %w", where, status, err)
+	}
+	return nil
+}
+
+// operation finds the operation of method on the path of target. Literal segments beat parameters, so
+// /users/count is not taken for /users/{id}.
+func (spec *Spec) operation(method, target string) (*operation, string, error) {
+	path := target
+	if parsed, err := url.ParseRequestURI(target); err == nil {
+		path = parsed.Path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	templates := make([]string, 0, len(spec.Paths))
+	for template := range spec.Paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	best, bestLiterals := "", -1
+	for _, template := range templates {
+		literals, ok := match(strings.Split(strings.Trim(template, "/"), "/"), segments)
+		if ok && literals > bestLiterals {
+			best, bestLiterals = template, literals
+		}
+	}
+	if best == "" {
+		return nil, "", ErrUndocumented
+	}
+
+	node, ok := spec.Paths[best][strings.ToLower(method)]
+	if !ok {
+		return nil, "", ErrUndocumented
+	}
+	op := &operation{}
+	if err := node.Decode(op); err != nil {
+		return nil, "", fmt.Errorf("openapi: %s %s: %w", method, best, err)
+	}
+	return op, best, nil
+}
+
+// match reports whether segments fit the template's and how many of those are literal
+func match(template, segments []string) (int, bool) {
+	if len(template) != len(segments) {
+		return 0, false
+	}
+	literals := 0
+	for i, part := range template {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+func (spec *Spec) resolve(schema *Schema) (*Schema, error) {
+	for schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := spec.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %s", schema.Ref)
+		}
+		schema = resolved
+	}
+	return schema, nil
+}
+
+// validate checks value, decoded with json.Number, against schema; at is the JSON path of value for the errors
+func (spec *Spec) validate(schema *Schema, value interface{}, at string) error {
+	schema, err := spec.resolve(schema)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.OneOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s is null", at)
+	}
+
+	if len(schema.OneOf) > 0 {
+		matched := 0
+		for _, option := range schema.OneOf {
+			if spec.validate(option, value, at) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s matches %d of the oneOf schemas instead of one", at, matched)
+		}
+		return nil
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", at)
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s misses the required property %q", at, name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return fmt.Errorf("%s has the undocumented property %q", at, name)
+				}
+				continue
+			}
+			if err := spec.validate(property, object[name], at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an array", at)
+		}
+		if schema.Items != nil {
+			for i, item := range array {
+				if err := spec.validate(schema.Items, item, at+"["+strconv.Itoa(i)+"]"); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s is not a string", at)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s is not an RFC 3339 time: %q", at, s)
+			}
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s is not an integer", at)
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s is not an integer: %s", at, n)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s is not a number", at)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s is not a boolean", at)
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is %v, which is not one of %v", at, value, schema.Enum)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const user = `{"user_id": 12, "email": "john@example.com", "first_name": "John", "last_name": "Doe",
+	"role": {"role_id": 1, "name": "user"}, "created_at": "2024-03-01T12:00:00Z", "updated_at": "2024-03-01T12:00:00Z",
+	"vote_updated_at": "0001-01-01T00:00:00Z", "rating": 3, "timezone": "", "locale": ""}`
+
+func TestSpec_ValidateResponse(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "a user", method: http.MethodGet, target: "/users/12", status: http.StatusCreated, body: user},
+		{name: "an error", method: http.MethodGet, target: "/users/12", status: http.StatusNotFound, body: `{"code": "NO_RECORD_FOUND", "message": "No record found"}`},
+		{name: "literal segments beat parameters", method: http.MethodGet, target: "/users/count?filter[role]=admin", status: http.StatusOK, body: `{"count": 4}`},
+		{name: "no content", method: http.MethodDelete, target: "/organizations/7/members/5", status: http.StatusNoContent},
+		{name: "a body that is not JSON", method: http.MethodPost, target: "/login", status: http.StatusOK, body: "eyJhbGciOiJIUzI1NiJ9.e30.sig"},
+		{
+			name: "an undocumented status", method: http.MethodGet, target: "/users/12", status: http.StatusOK, body: user,
+			wantErr: "GET /users/{id} does not document the status 200",
+		},
+		{
+			name: "a leaked property", method: http.MethodGet, target: "/me", status: http.StatusOK,
+			body:    user[:len(user)-1] + `, "password": "$2a$10$hash"}`,
+			wantErr: `$ has the undocumented property "password"`,
+		},
+		{
+			name: "a missing property", method: http.MethodGet, target: "/users/count", status: http.StatusOK, body: `{}`,
+			wantErr: `$ misses the required property "count"`,
+		},
+		{
+			name: "a wrong type", method: http.MethodGet, target: "/users/count", status: http.StatusOK, body: `{"count": "4"}`,
+			wantErr: "$.count is not an integer",
+		},
+		{
+			name: "a value outside the enum", method: http.MethodPut, target: "/organizations/7/members/5", status: http.StatusOK,
+			body:    `{"organization_id": 7, "user_id": 5, "role": "owner", "created_at": "2024-03-01T12:00:00Z"}`,
+			wantErr: "$.role is owner, which is not one of [user moderator admin]",
+		},
+		{
+			name: "a malformed time", method: http.MethodPost, target: "/organizations", status: http.StatusCreated,
+			body:    `{"id": 7, "name": "Acme", "created_by": 1, "created_at": "yesterday"}`,
+			wantErr: `$.created_at is not an RFC 3339 time: "yesterday"`,
+		},
+		{
+			name: "a body where none is documented", method: http.MethodDelete, target: "/organizations/7/members/5", status: http.StatusNoContent,
+			body:    `{}`,
+			wantErr: "documents no body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.ValidateResponse(tt.method, tt.target, tt.status, []byte(tt.body))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpec_UndocumentedOperations(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+
+	err = spec.ValidateResponse(http.MethodGet, "/users/12/avatar", http.StatusOK, []byte(`{}`))
+	assert.True(t, errors.Is(err, ErrUndocumented), "got %v", err)
+	err = spec.ValidateResponse(http.MethodPatch, "/users/12", http.StatusOK, nil)
+	assert.True(t, errors.Is(err, ErrUndocumented), "got %v", err)
+}
+
+// TestDocument_ReferencesResolve keeps every $ref of the document pointing at a component
+func TestDocument_ReferencesResolve(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+
+	var check func(schema *Schema)
+	check = func(schema *Schema) {
+		if schema == nil {
+			return
+		}
+		_, err := spec.resolve(schema)
+		assert.NoError(t, err)
+		for _, property := range schema.Properties {
+			check(property)
+		}
+		for _, option := range schema.OneOf {
+			check(option)
+		}
+		check(schema.Items)
+	}
+	for _, schema := range spec.Components.Schemas {
+		check(schema)
+	}
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			if method == "parameters" {
+				continue
+			}
+			op, _, err := spec.operation(method, path)
+			require.NoError(t, err, "%s %s", method, path)
+			for status, resp := range op.Responses {
+				if resp.Ref != "" {
+					_, ok := spec.Components.Responses[resp.Ref[len("#/components/responses/"):]]
+					assert.True(t, ok, "%s %s %s refers to %s", method, path, status, resp.Ref)
+				}
+				for _, content := range resp.Content {
+					check(content.Schema)
+				}
+			}
+		}
+	}
+}
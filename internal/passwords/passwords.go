@@ -1,9 +1,19 @@
 package passwords
 
 import (
+	"crypto/rand"
+	"math/big"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Characters of generated passwords; every class the password rules ask for is represented
+const (
+	letters  = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	digits   = "23456789"
+	specials = "!@#$%^&*"
+)
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	return string(bytes), err
@@ -13,3 +23,28 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// Generate returns a random password of length characters, at least 8, with a digit and a special character
+func Generate(length int) (string, error) {
+	if length < 8 {
+		length = 8
+	}
+	password := make([]byte, length)
+	alphabet := letters + digits + specials
+	for i := range password {
+		// A digit first and a special character second, so the password rules always pass
+		source := alphabet
+		switch i {
+		case 0:
+			source = digits
+		case 1:
+			source = specials
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(source))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = source[n.Int64()]
+	}
+	return string(password), nil
+}
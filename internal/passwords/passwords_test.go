@@ -1,6 +1,7 @@
 package passwords
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,15 @@ func TestCheckPasswordHash(t *testing.T) {
 	invalid := CheckPasswordHash("wrongpassword", hash)
 	assert.False(t, invalid, "Password should not match the hash")
 }
+
+func TestGenerate(t *testing.T) {
+	password, err := Generate(20)
+	assert.NoError(t, err)
+	assert.Len(t, password, 20)
+	assert.True(t, strings.ContainsAny(password, digits))
+	assert.True(t, strings.ContainsAny(password, specials))
+
+	other, err := Generate(20)
+	assert.NoError(t, err)
+	assert.NotEqual(t, password, other)
+}
@@ -0,0 +1,220 @@
+// Package pii encrypts personal data at rest. Values are sealed with envelope encryption: every value gets
+// its own random data key, which is itself wrapped by a key encryption key (a local key from the config or
+// a KMS behind KeyWrapper). A keyed blind index makes encrypted columns searchable by equality.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CiphertextPrefix marks sealed values; anything without it is read back as plaintext,
+// which keeps rows written before encryption was enabled readable
+const CiphertextPrefix = "enc:v1:"
+
+const dataKeySize = 32
+
+// KeyWrapper protects data keys with a key encryption key that never leaves it
+type KeyWrapper interface {
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyWrapper wraps data keys with AES-256-GCM under a key held in memory
+type LocalKeyWrapper struct {
+	aead cipher.AEAD
+}
+
+func NewLocalKeyWrapper(key []byte) (*LocalKeyWrapper, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalKeyWrapper{aead: aead}, nil
+}
+
+func (wrapper *LocalKeyWrapper) WrapKey(dataKey []byte) ([]byte, error) {
+	return seal(wrapper.aead, dataKey)
+}
+
+func (wrapper *LocalKeyWrapper) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return open(wrapper.aead, wrapped)
+}
+
+// Keyring holds what Encrypt, Decrypt and BlindIndex need. A nil wrapper disables encryption.
+type Keyring struct {
+	wrapper  KeyWrapper
+	indexKey []byte
+}
+
+func NewKeyring(wrapper KeyWrapper, indexKey []byte) *Keyring {
+	return &Keyring{wrapper: wrapper, indexKey: indexKey}
+}
+
+// NewKeyringFromConfig builds a keyring from base64 encoded 32 byte keys; an empty encryption key
+// leaves values in plaintext. Without encryption an empty index key computes an unkeyed index, which
+// would make the index of a sealed email a plain hash of it, so encryption requires an index key.
+func NewKeyringFromConfig(encryptionKey, indexKey string) (*Keyring, error) {
+	var wrapper KeyWrapper
+	if encryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("PII_ENCRYPTION_KEY: %w", err)
+		}
+		wrapper, err = NewLocalKeyWrapper(key)
+		if err != nil {
+			return nil, fmt.Errorf("PII_ENCRYPTION_KEY: %w", err)
+		}
+	}
+
+	var index []byte
+	if indexKey != "" {
+		var err error
+		index, err = base64.StdEncoding.DecodeString(indexKey)
+		if err != nil {
+			return nil, fmt.Errorf("PII_INDEX_KEY: %w", err)
+		}
+	}
+	if wrapper != nil && len(index) == 0 {
+		return nil, errors.New("PII_INDEX_KEY: is required with PII_ENCRYPTION_KEY")
+	}
+
+	return NewKeyring(wrapper, index), nil
+}
+
+var (
+	activeMu sync.RWMutex
+	active   = NewKeyring(nil, nil)
+)
+
+// Use installs the keyring the GORM serializer and the package functions work with
+func Use(keyring *Keyring) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	active = keyring
+}
+
+func current() *Keyring {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	return active
+}
+
+// Enabled reports whether Encrypt seals values or leaves them in plaintext
+func Enabled() bool {
+	return current().wrapper != nil
+}
+
+// Encrypt seals plaintext with a fresh data key, or returns it unchanged when encryption is disabled
+func Encrypt(plaintext string) (string, error) {
+	keyring := current()
+	if keyring.wrapper == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrappedKey, err := keyring.wrapper.WrapKey(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	// Layout: length of the wrapped key, wrapped key, nonce and ciphertext
+	out := make([]byte, 2, 2+len(wrappedKey)+len(sealed))
+	binary.BigEndian.PutUint16(out, uint16(len(wrappedKey)))
+	out = append(out, wrappedKey...)
+	out = append(out, sealed...)
+	return CiphertextPrefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+// Decrypt opens a value produced by Encrypt; values without the ciphertext prefix are returned as they are
+func Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, CiphertextPrefix) {
+		return value, nil
+	}
+	keyring := current()
+	if keyring.wrapper == nil {
+		return "", errors.New("pii: encrypted value found but no PII_ENCRYPTION_KEY is configured")
+	}
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, CiphertextPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < 2 {
+		return "", errors.New("pii: truncated ciphertext")
+	}
+	keyLen := int(binary.BigEndian.Uint16(raw))
+	if len(raw) < 2+keyLen {
+		return "", errors.New("pii: truncated ciphertext")
+	}
+
+	dataKey, err := keyring.wrapper.UnwrapKey(raw[2 : 2+keyLen])
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, raw[2+keyLen:])
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex is a deterministic keyed hash of value used to look up encrypted columns by equality
+func BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, current().indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("pii: key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("pii: truncated ciphertext")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
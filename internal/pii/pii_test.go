@@ -0,0 +1,75 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useTestKeys(t *testing.T) {
+	wrapper, err := NewLocalKeyWrapper(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	previous := current()
+	Use(NewKeyring(wrapper, bytes.Repeat([]byte{2}, 32)))
+	t.Cleanup(func() { Use(previous) })
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	useTestKeys(t)
+
+	sealed, err := Encrypt("jane@example.com")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, CiphertextPrefix))
+	assert.NotContains(t, sealed, "jane")
+
+	again, err := Encrypt("jane@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+
+	plaintext, err := Decrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", plaintext)
+}
+
+func TestDecryptPassesPlaintextThrough(t *testing.T) {
+	useTestKeys(t)
+
+	plaintext, err := Decrypt("legacy@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy@example.com", plaintext)
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	useTestKeys(t)
+
+	sealed, err := Encrypt("jane@example.com")
+	require.NoError(t, err)
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	_, err = Decrypt(tampered)
+	assert.Error(t, err)
+}
+
+func TestBlindIndexIsDeterministicAndKeyed(t *testing.T) {
+	unkeyed := BlindIndex("jane@example.com")
+	useTestKeys(t)
+
+	assert.Equal(t, BlindIndex("jane@example.com"), BlindIndex("jane@example.com"))
+	assert.NotEqual(t, BlindIndex("jane@example.com"), BlindIndex("john@example.com"))
+	assert.NotEqual(t, unkeyed, BlindIndex("jane@example.com"))
+}
+
+func TestNewKeyringFromConfig_RequiresAnIndexKeyWithEncryption(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	_, err := NewKeyringFromConfig(key, "")
+	assert.EqualError(t, err, "PII_INDEX_KEY: is required with PII_ENCRYPTION_KEY")
+
+	_, err = NewKeyringFromConfig("", "")
+	assert.NoError(t, err, "plaintext storage needs no keys")
+}
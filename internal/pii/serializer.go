@@ -0,0 +1,43 @@
+package pii
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("pii", Serializer{})
+}
+
+// Serializer encrypts string fields tagged `gorm:"serializer:pii"` on write and decrypts them on read
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch value := dbValue.(type) {
+	case nil:
+	case string:
+		stored = value
+	case []byte:
+		stored = string(value)
+	default:
+		return fmt.Errorf("pii: unsupported column value %T", dbValue)
+	}
+
+	plaintext, err := Decrypt(stored)
+	if err != nil {
+		return err
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("pii: field %s must be a string", field.Name)
+	}
+	return Encrypt(plaintext)
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseTrustedProxies parses addresses and CIDR ranges, e.g. "10.0.0.0/8" or "192.168.1.10"
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %q", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ClientIP returns the address the request came from. X-Forwarded-For is only believed when the connection
+// comes from a trusted proxy, and then read from the right, where proxies append, up to the first address
+// that is not a trusted proxy; anything left of it could have been sent by the client.
+func ClientIP(r *http.Request, trusted []*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !isTrusted(remote, trusted) {
+		return remote
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, address := range strings.Split(header, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(address))
+		}
+	}
+	client := remote
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if net.ParseIP(forwarded[i]) == nil {
+			break
+		}
+		client = forwarded[i]
+		if !isTrusted(client, trusted) {
+			break
+		}
+	}
+	return client
+}
+
+func isTrusted(address string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{name: "direct", remote: "203.0.113.5:4000", want: "203.0.113.5"},
+		{name: "untrusted peer is not believed", remote: "203.0.113.5:4000", forwarded: []string{"198.51.100.1"}, want: "203.0.113.5"},
+		{name: "trusted proxy", remote: "10.1.2.3:4000", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed entries left of the client", remote: "10.1.2.3:4000", forwarded: []string{"1.1.1.1, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chain of trusted proxies", remote: "10.1.2.3:4000", forwarded: []string{"198.51.100.1, 192.168.1.10", "10.9.9.9"}, want: "198.51.100.1"},
+		{name: "garbage stops the walk", remote: "10.1.2.3:4000", forwarded: []string{"198.51.100.1, nonsense"}, want: "10.1.2.3"},
+		{name: "only proxies", remote: "10.1.2.3:4000", forwarded: []string{"10.4.4.4"}, want: "10.4.4.4"},
+		{name: "no header", remote: "10.1.2.3:4000", want: "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/users", nil)
+			r.RemoteAddr = tt.remote
+			for _, header := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+			assert.Equal(t, tt.want, ClientIP(r, trusted))
+		})
+	}
+}
+
+func TestParseTrustedProxies_RejectsInvalid(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.internal"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+// Package ratelimit counts requests per key in fixed windows kept in Redis, so every replica enforces the
+// same limit, throttles repeated failures per key in sliding windows, and finds the client address of a request
+// behind trusted proxies.
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Limiter allows limit requests per key in every window
+type Limiter interface {
+	// Allow counts a request for key and returns whether it is within the limit, and otherwise how long until
+	// the window ends
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+// RedisLimiter counts in a key that expires with its window, created by the first request of the window
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// count increments the counter and starts its window on the first request, in one step so a counter can not
+// be left without an expiry
+var count = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// NewRedisLimiter limits the keys under prefix, e.g. "ratelimit:signup:", to limit requests per window
+func NewRedisLimiter(client *redis.Client, prefix string, limit int, window time.Duration) *RedisLimiter {
+	return &RedisLimiter{client: client, prefix: prefix, limit: limit, window: window}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	result, err := count.Run(ctx, l.client, []string{l.prefix + key}, l.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if result[0] <= int64(l.limit) {
+		return true, 0, nil
+	}
+	return false, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Counter counts events per key in fixed windows, for callers that act on the count themselves
+type Counter interface {
+	// Count counts an event for key and returns how many the window has had, this one included
+	Count(ctx context.Context, key string) (int, error)
+}
+
+// RedisCounter counts like RedisLimiter, without a limit
+type RedisCounter struct {
+	client *redis.Client
+	prefix string
+	window time.Duration
+}
+
+// NewRedisCounter counts the keys under prefix, e.g. "bruteforce:", in windows of window
+func NewRedisCounter(client *redis.Client, prefix string, window time.Duration) *RedisCounter {
+	return &RedisCounter{client: client, prefix: prefix, window: window}
+}
+
+func (c *RedisCounter) Count(ctx context.Context, key string) (int, error) {
+	result, err := count.Run(ctx, c.client, []string{c.prefix + key}, c.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+	return int(result[0]), nil
+}
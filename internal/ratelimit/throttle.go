@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Throttle slows down repeated failures per key, e.g. failed logins to one account, whichever clients they
+// come from
+type Throttle interface {
+	// Wait returns how long key has to wait before its next attempt, zero when it may try now
+	Wait(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt of key
+	Fail(ctx context.Context, key string) error
+	// Reset forgets the failures of key, after an attempt that succeeded
+	Reset(ctx context.Context, key string) error
+}
+
+// RedisThrottle keeps the failures of a key in a sorted set scored by time, so they leave the window one by one.
+// Once a key has failed attempts times within the window, each further attempt has to wait after the latest
+// failure: delay at first, doubling with every failure up to maxDelay.
+type RedisThrottle struct {
+	client   *redis.Client
+	prefix   string
+	attempts int
+	window   time.Duration
+	delay    time.Duration
+	maxDelay time.Duration
+}
+
+// failures drops the failures that left the window and returns how many remain and when the latest was, in
+// milliseconds
+var failures = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1] - ARGV[2])
+local latest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+return {redis.call("ZCARD", KEYS[1]), latest[2] or 0}
+`)
+
+// fail records a failure at ARGV[1] and keeps the set for as long as the failure counts
+var fail = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1] - ARGV[2])
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
+// NewRedisThrottle throttles the keys under prefix, e.g. "throttle:login:", once they fail attempts times within
+// window
+func NewRedisThrottle(client *redis.Client, prefix string, attempts int, window, delay, maxDelay time.Duration) *RedisThrottle {
+	return &RedisThrottle{client: client, prefix: prefix, attempts: attempts, window: window, delay: delay, maxDelay: maxDelay}
+}
+
+func (t *RedisThrottle) Wait(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+	result, err := failures.Run(ctx, t.client, []string{t.prefix + key}, now.UnixMilli(), t.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+	backoff := t.backoff(int(result[0]))
+	if backoff == 0 {
+		return 0, nil
+	}
+	wait := time.UnixMilli(result[1]).Add(backoff).Sub(now)
+	if wait < 0 {
+		return 0, nil
+	}
+	return wait, nil
+}
+
+func (t *RedisThrottle) Fail(ctx context.Context, key string) error {
+	// Failures in the same millisecond need members of their own
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	return fail.Run(ctx, t.client, []string{t.prefix + key}, now, t.window.Milliseconds(), hex.EncodeToString(nonce)).Err()
+}
+
+func (t *RedisThrottle) Reset(ctx context.Context, key string) error {
+	return t.client.Del(ctx, t.prefix+key).Err()
+}
+
+// backoff is how long after the latest of failures the next attempt has to wait
+func (t *RedisThrottle) backoff(failures int) time.Duration {
+	if failures < t.attempts {
+		return 0
+	}
+	backoff := t.delay
+	for i := t.attempts; i < failures && backoff < t.maxDelay; i++ {
+		backoff *= 2
+	}
+	if backoff > t.maxDelay {
+		return t.maxDelay
+	}
+	return backoff
+}
@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisThrottle_Backoff(t *testing.T) {
+	throttle := NewRedisThrottle(nil, "throttle:test:", 3, time.Hour, time.Second, 10*time.Second)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 7, want: 10 * time.Second},
+		{failures: 1000, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, throttle.backoff(tt.failures), "after %d failures", tt.failures)
+	}
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AdminAuditRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type AdminAuditRepoInterface interface {
+	CreateAdminAudit(ctx context.Context, entry *models.AdminAudit) error
+	// ListAdminAudit returns up to query.Limit entries matching the query, newest first
+	ListAdminAudit(ctx context.Context, query *models.AdminAuditQuery) ([]models.AdminAudit, error)
+}
+
+func NewAdminAuditRepo(db *gorm.DB, logger *zap.SugaredLogger) *AdminAuditRepo {
+	return &AdminAuditRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *AdminAuditRepo) CreateAdminAudit(ctx context.Context, entry *models.AdminAudit) error {
+	result := writer(ctx, repo.db).Create(entry)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *AdminAuditRepo) ListAdminAudit(ctx context.Context, query *models.AdminAuditQuery) ([]models.AdminAudit, error) {
+	tx := reader(ctx, repo.db).Order("id DESC").Limit(query.Limit)
+	if query.ActorID > 0 {
+		tx = tx.Where("actor_id = ?", query.ActorID)
+	}
+	if query.ActorType != "" {
+		tx = tx.Where("actor_type = ?", query.ActorType)
+	}
+	if query.TargetID > 0 {
+		tx = tx.Where("target_id = ?", query.TargetID)
+	}
+	if query.Action != "" {
+		tx = tx.Where("action = ?", query.Action)
+	}
+	if !query.Since.IsZero() {
+		tx = tx.Where("created_at >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		tx = tx.Where("created_at < ?", query.Until)
+	}
+	if query.BeforeID > 0 {
+		tx = tx.Where("id < ?", query.BeforeID)
+	}
+
+	var entries []models.AdminAudit
+	result := tx.Find(&entries)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return entries, nil
+}
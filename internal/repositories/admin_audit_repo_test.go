@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAdminAuditRepo_ListFiltersAndPagesBack(t *testing.T) {
+	repo := NewAdminAuditRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	start := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, entry := range []*models.AdminAudit{
+		{ActorID: 1, Action: models.AdminAuditRoleChanged, TargetID: 5, Reason: "promoted", Details: "role_id 1 -> 2"},
+		{ActorID: 1, Action: models.AdminAuditUserUpdated, TargetID: 6, Details: "email"},
+		{ActorID: 2, Action: models.AdminAuditUserDeleted, TargetID: 5, Reason: "spam"},
+		{ActorID: 1, Action: models.AdminAuditUserDeleted, TargetID: 7},
+	} {
+		entry.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.CreateAdminAudit(ctx, entry))
+	}
+
+	entries, err := repo.ListAdminAudit(ctx, &models.AdminAuditQuery{TargetID: 5, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "spam", entries[0].Reason, "newest first")
+	assert.Equal(t, "promoted", entries[1].Reason)
+
+	entries, err = repo.ListAdminAudit(ctx, &models.AdminAuditQuery{ActorID: 1, Action: models.AdminAuditUserDeleted, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(7), entries[0].TargetID)
+
+	entries, err = repo.ListAdminAudit(ctx, &models.AdminAuditQuery{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint(5), entries[0].TargetID)
+	assert.Equal(t, uint(6), entries[1].TargetID)
+
+	page, err := repo.ListAdminAudit(ctx, &models.AdminAuditQuery{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	rest, err := repo.ListAdminAudit(ctx, &models.AdminAuditQuery{BeforeID: page[2].ID, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, "promoted", rest[0].Reason)
+}
+
+func TestAdminAuditRepo_RecordsServiceAccountsAsActors(t *testing.T) {
+	repo := NewAdminAuditRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	asServiceAccount := context.WithValue(ctx, models.ServiceAccountContextKey, true)
+
+	require.NoError(t, repo.CreateAdminAudit(ctx, &models.AdminAudit{ActorID: 1, Action: models.AdminAuditUserUnbanned, TargetID: 5}))
+	require.NoError(t, repo.CreateAdminAudit(asServiceAccount, &models.AdminAudit{ActorID: 9, Action: models.AdminAuditUserBanned, TargetID: 5}))
+
+	entries, err := repo.ListAdminAudit(ctx, &models.AdminAuditQuery{ActorType: models.ActorServiceAccount, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(9), entries[0].ActorID)
+
+	entries, err = repo.ListAdminAudit(ctx, &models.AdminAuditQuery{ActorType: models.ActorUser, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(1), entries[0].ActorID)
+}
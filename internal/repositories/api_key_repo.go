@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type APIKeyRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type APIKeyRepoInterface interface {
+	CreateKey(ctx context.Context, key *models.APIKey) error
+	ListKeys(ctx context.Context) ([]models.APIKey, error)
+	GetKey(ctx context.Context, id uint) (*models.APIKey, error)
+	// GetKeyByHash finds a key by the SHA-256 of its value, with its user and the user's role
+	GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	SetTier(ctx context.Context, id uint, tier string) error
+	// DeleteKey deletes the key with its usage
+	DeleteKey(ctx context.Context, id uint) error
+	// RecordUsage counts a request of the key on day, as limited when the rate limit refused it
+	RecordUsage(ctx context.Context, keyID uint, day string, limited bool) error
+	// ListUsage returns the counters of the key from day since on, oldest first
+	ListUsage(ctx context.Context, keyID uint, since string) ([]models.APIKeyUsage, error)
+	// DeleteUsageBefore deletes up to limit counters of days before day
+	DeleteUsageBefore(ctx context.Context, day string, limit int) (int, error)
+}
+
+func NewAPIKeyRepo(db *gorm.DB, logger *zap.SugaredLogger) *APIKeyRepo {
+	return &APIKeyRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *APIKeyRepo) CreateKey(ctx context.Context, key *models.APIKey) error {
+	result := writer(ctx, repo.db).Create(key)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *APIKeyRepo) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	result := reader(ctx, repo.db).Order("id").Find(&keys)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return keys, nil
+}
+
+func (repo *APIKeyRepo) GetKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	return repo.first(reader(ctx, repo.db).Where("id = ?", id))
+}
+
+func (repo *APIKeyRepo) GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return repo.first(reader(ctx, repo.db).Preload("User.Role").Where("key_hash = ?", keyHash))
+}
+
+func (repo *APIKeyRepo) SetTier(ctx context.Context, id uint, tier string) error {
+	result := writer(ctx, repo.db).Model(&models.APIKey{}).Where("id = ?", id).Update("tier", tier)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("API key not found.")
+	}
+	return nil
+}
+
+func (repo *APIKeyRepo) DeleteKey(ctx context.Context, id uint) error {
+	// The usage goes first; SQLite does not cascade without foreign keys switched on
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("api_key_id = ?", id).Delete(&models.APIKeyUsage{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.APIKey{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound.AppendMessage("API key not found.")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		repo.logger.Error(err)
+	}
+	return translateError(err, &apperrors.DeletionFailedErr)
+}
+
+func (repo *APIKeyRepo) RecordUsage(ctx context.Context, keyID uint, day string, limited bool) error {
+	usage := &models.APIKeyUsage{APIKeyID: keyID, Day: day, Requests: 1}
+	counter := "requests"
+	if limited {
+		usage.Requests, usage.Limited = 0, 1
+		counter = "limited"
+	}
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "api_key_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{counter: gorm.Expr("api_key_usage." + counter + " + 1")}),
+	}).Create(usage)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	return nil
+}
+
+func (repo *APIKeyRepo) ListUsage(ctx context.Context, keyID uint, since string) ([]models.APIKeyUsage, error) {
+	var usage []models.APIKeyUsage
+	result := reader(ctx, repo.db).Where("api_key_id = ? AND day >= ?", keyID, since).Order("day").Find(&usage)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return usage, nil
+}
+
+func (repo *APIKeyRepo) DeleteUsageBefore(ctx context.Context, day string, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.APIKeyUsage{}).Select("api_key_id, day").
+		Where("day < ?", day).
+		Order("day").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("(api_key_id, day) IN (?)", batch).Delete(&models.APIKeyUsage{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (repo *APIKeyRepo) first(query *gorm.DB) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	result := query.First(key)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("API key not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return key, nil
+}
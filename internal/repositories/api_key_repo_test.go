@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAPIKeyRepo_Usage(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewAPIKeyRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	user := createTestUser(t, NewUserRepo(db, zaptest.NewLogger(t).Sugar()), "alice@example.com")
+
+	key := &models.APIKey{Name: "Billing", Prefix: "uk_abcd", KeyHash: "hash", UserID: user.ID, Tier: "free"}
+	require.NoError(t, repo.CreateKey(ctx, key))
+	found, err := repo.GetKeyByHash(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	require.NotNil(t, found.User)
+	assert.Equal(t, "alice@example.com", found.User.Email)
+	assert.NotEmpty(t, found.User.Role.Name)
+	require.NoError(t, repo.SetTier(ctx, key.ID, "standard"))
+	found, err = repo.GetKey(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "standard", found.Tier)
+
+	require.NoError(t, repo.RecordUsage(ctx, key.ID, "2024-02-29", false))
+	require.NoError(t, repo.RecordUsage(ctx, key.ID, "2024-03-01", false))
+	require.NoError(t, repo.RecordUsage(ctx, key.ID, "2024-03-01", false))
+	require.NoError(t, repo.RecordUsage(ctx, key.ID, "2024-03-01", true))
+	usage, err := repo.ListUsage(ctx, key.ID, "2024-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, []models.APIKeyUsage{{APIKeyID: key.ID, Day: "2024-03-01", Requests: 2, Limited: 1}}, usage)
+
+	deleted, err := repo.DeleteUsageBefore(ctx, "2024-03-01", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	require.NoError(t, repo.DeleteKey(ctx, key.ID))
+	usage, err = repo.ListUsage(ctx, key.ID, "2024-01-01")
+	require.NoError(t, err)
+	assert.Empty(t, usage, "the usage goes with the key")
+	_, err = repo.GetKey(ctx, key.ID)
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BanRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type BanRepoInterface interface {
+	// SaveBan bans the user of ban, replacing their current ban
+	SaveBan(ctx context.Context, ban *models.Ban) error
+	// GetBan returns the ban of the user, expired or not
+	GetBan(ctx context.Context, userID uint) (*models.Ban, error)
+	DeleteBan(ctx context.Context, userID uint) error
+	// ListBans returns a page of the bans not expired at now with the banned users, soonest to expire first, and
+	// how many there are
+	ListBans(ctx context.Context, now time.Time, page, pageSize int) ([]models.Ban, int, error)
+	// DeleteExpiredBans deletes up to limit bans expired at now
+	DeleteExpiredBans(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+func NewBanRepo(db *gorm.DB, logger *zap.SugaredLogger) *BanRepo {
+	return &BanRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *BanRepo) SaveBan(ctx context.Context, ban *models.Ban) error {
+	result := writer(ctx, repo.db).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "expires_at", "banned_by", "created_at"}),
+	}).Create(ban)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *BanRepo) GetBan(ctx context.Context, userID uint) (*models.Ban, error) {
+	ban := &models.Ban{}
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).First(ban)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Ban not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return ban, nil
+}
+
+func (repo *BanRepo) DeleteBan(ctx context.Context, userID uint) error {
+	result := writer(ctx, repo.db).Where("user_id = ?", userID).Delete(&models.Ban{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Ban not found.")
+	}
+	return nil
+}
+
+func (repo *BanRepo) ListBans(ctx context.Context, now time.Time, page, pageSize int) ([]models.Ban, int, error) {
+	tx := reader(ctx, repo.db).Model(&models.Ban{}).Where("expires_at > ?", now).Session(&gorm.Session{})
+
+	var total int64
+	result := tx.Count(&total)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	var bans []models.Ban
+	result = tx.Preload("User.Role").
+		Order("expires_at, user_id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&bans)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return bans, int(total), nil
+}
+
+func (repo *BanRepo) DeleteExpiredBans(ctx context.Context, now time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.Ban{}).Select("user_id").
+		Where("expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("user_id IN (?)", batch).Delete(&models.Ban{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
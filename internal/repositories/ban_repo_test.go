@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBanRepo_BansExpire(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserRepo(db, zaptest.NewLogger(t).Sugar())
+	repo := NewBanRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	ann := createTestUser(t, users, "ann@example.com")
+	bob := createTestUser(t, users, "bob@example.com")
+	eve := createTestUser(t, users, "eve@example.com")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, repo.SaveBan(ctx, &models.Ban{UserID: ann.ID, Reason: "Spam", ExpiresAt: now.Add(time.Hour), BannedBy: eve.ID, CreatedAt: now}))
+	require.NoError(t, repo.SaveBan(ctx, &models.Ban{UserID: ann.ID, Reason: "Spam again", ExpiresAt: now.Add(48 * time.Hour), BannedBy: eve.ID, CreatedAt: now}))
+	require.NoError(t, repo.SaveBan(ctx, &models.Ban{UserID: bob.ID, Reason: "Abuse", ExpiresAt: now.Add(24 * time.Hour), BannedBy: eve.ID, CreatedAt: now}))
+	require.NoError(t, repo.SaveBan(ctx, &models.Ban{UserID: eve.ID, Reason: "Old", ExpiresAt: now.Add(-time.Hour), BannedBy: bob.ID, CreatedAt: now}))
+
+	ban, err := repo.GetBan(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Spam again", ban.Reason, "banning again replaces the ban")
+	assert.True(t, now.Add(48*time.Hour).Equal(ban.ExpiresAt))
+
+	bans, total, err := repo.ListBans(ctx, now, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total, "expired bans are left out")
+	require.Len(t, bans, 2)
+	assert.Equal(t, bob.ID, bans[0].UserID, "soonest to expire first")
+	require.NotNil(t, bans[0].User)
+	assert.Equal(t, "bob@example.com", bans[0].User.Email)
+
+	deleted, err := repo.DeleteExpiredBans(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = repo.GetBan(ctx, eve.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, repo.DeleteBan(ctx, ann.ID))
+	assert.ErrorIs(t, repo.DeleteBan(ctx, ann.ID), ErrNotFound)
+}
@@ -0,0 +1,152 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/integrationtest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// benchUsers is how many users the benchmarks that read users start with
+const benchUsers = 10_000
+
+// newBenchDB is a migrated Postgres database with a pool large enough for the parallel benchmarks
+func newBenchDB(b *testing.B) *gorm.DB {
+	cfg := integrationtest.Config(integrationtest.PostgresURI(b))
+	cfg.DBMaxOpenConns = 64
+	cfg.DBMaxIdleConns = 64
+	return integrationtest.Database(b, cfg)
+}
+
+// seedBenchUsers creates n users named user<i>@example.com and returns them in id order
+func seedBenchUsers(b *testing.B, repo *UserRepo, prefix string, n int) []*models.User {
+	b.Helper()
+	users := make([]*models.User, 0, n)
+	for start := 0; start < n; start += 1000 {
+		batch := make([]*models.User, 0, 1000)
+		for i := start; i < start+1000 && i < n; i++ {
+			batch = append(batch, &models.User{
+				Email:     fmt.Sprintf("%s%d@example.com", prefix, i),
+				FirstName: "Bench",
+				LastName:  "User",
+				Password:  "hash",
+				RoleID:    1,
+			})
+		}
+		rowErrs, err := repo.CreateUsers(context.Background(), batch)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, rowErr := range rowErrs {
+			if rowErr != nil {
+				b.Fatal(rowErr)
+			}
+		}
+		users = append(users, batch...)
+	}
+	return users
+}
+
+// BenchmarkUserRepo_ListUsersWithTotalPageDepth shows what OFFSET pagination costs as clients page deeper
+func BenchmarkUserRepo_ListUsersWithTotalPageDepth(b *testing.B) {
+	repo := NewUserRepo(newBenchDB(b), zap.NewNop().Sugar())
+	seedBenchUsers(b, repo, "user", benchUsers)
+	ctx := context.Background()
+
+	const pageSize = 20
+	for _, page := range []int{1, 50, 250, benchUsers / pageSize} {
+		b.Run(fmt.Sprintf("page=%d", page), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				users, _, err := repo.ListUsersWithTotal(ctx, page, pageSize, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(users) != pageSize {
+					b.Fatalf("got %d users, want %d", len(users), pageSize)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkUserRepo_GetUserByEmail looks users up by the blind index of their email, as login does
+func BenchmarkUserRepo_GetUserByEmail(b *testing.B) {
+	repo := NewUserRepo(newBenchDB(b), zap.NewNop().Sugar())
+	seedBenchUsers(b, repo, "user", benchUsers)
+	ctx := context.Background()
+
+	b.Run("found", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetUserByEmail(ctx, fmt.Sprintf("user%d@example.com", i%benchUsers)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("missing", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetUserByEmail(ctx, fmt.Sprintf("missing%d@example.com", i)); err == nil {
+				b.Fatal("found a user that does not exist")
+			}
+		}
+	})
+}
+
+// BenchmarkVoteRepo_VoteUnderContention changes votes from many goroutines at once in the transaction
+// UserService.Vote runs: lock the voter, read the vote, save it. Every save recalculates the profile's rating, so
+// the fewer the profiles the more the transactions wait on each other's rating updates.
+func BenchmarkVoteRepo_VoteUnderContention(b *testing.B) {
+	db := newBenchDB(b)
+	logger := zap.NewNop().Sugar()
+	userRepo := NewUserRepo(db, logger)
+	voteRepo := NewVoteRepo(db, logger)
+	txManager := NewTxManager(db, logger)
+	ctx := context.Background()
+
+	voters := seedBenchUsers(b, userRepo, "voter", 64)
+	for _, profileCount := range []int{1, 16} {
+		profiles := seedBenchUsers(b, userRepo, fmt.Sprintf("profile%d-", profileCount), profileCount)
+		for _, voter := range voters {
+			for _, profile := range profiles {
+				if _, err := voteRepo.CreateVote(ctx, &models.Vote{UserID: voter.ID, ProfileID: profile.ID, Value: 1, CreatedAt: time.Now()}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+
+		b.Run(fmt.Sprintf("profiles=%d", profileCount), func(b *testing.B) {
+			var next atomic.Int64
+			b.SetParallelism(4)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					n := next.Add(1)
+					voter := voters[n%int64(len(voters))]
+					profile := profiles[n%int64(len(profiles))]
+					err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+						if _, err := userRepo.LockUserByID(ctx, voter.ID); err != nil {
+							return err
+						}
+						vote, err := voteRepo.GetVote(ctx, voter.ID, profile.ID)
+						if err != nil {
+							return err
+						}
+						vote.Value = -vote.Value
+						_, err = voteRepo.UpdateVote(ctx, vote)
+						return err
+					})
+					if err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
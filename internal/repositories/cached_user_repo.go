@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"strconv"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/cache"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// userCache holds the key scheme shared by the caching decorators. Users are gob-encoded
+// because the JSON representation deliberately drops the password hash.
+type userCache struct {
+	cache  cache.CacheInterface
+	ttl    time.Duration
+	logger *zap.SugaredLogger
+}
+
+// userKeyByID and its siblings namespace keys by tenant, so one tenant is never answered from another's entries
+func userKeyByID(ctx context.Context, userID string) string {
+	return tenancy.KeyPrefix(ctx) + "repo:user:id:" + userID
+}
+
+func userKeyByUintID(ctx context.Context, userID uint) string {
+	return tenancy.KeyPrefix(ctx) + "repo:user:uid:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func userKeyByEmail(ctx context.Context, email string) string {
+	return tenancy.KeyPrefix(ctx) + "repo:user:email:" + email
+}
+
+func (c *userCache) get(ctx context.Context, key string) (*models.User, bool) {
+	cached, err := c.cache.Get(ctx, key, c.ttl)
+	if err != nil {
+		return nil, false
+	}
+
+	var user models.User
+	err = gob.NewDecoder(bytes.NewBufferString(cached)).Decode(&user)
+	if err != nil {
+		c.logger.Warnw("Dropping undecodable cache entry", "key", key, "error", err)
+		return nil, false
+	}
+	return &user, true
+}
+
+func (c *userCache) set(ctx context.Context, key string, user *models.User) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(user)
+	if err != nil {
+		c.logger.Warnw("Failed to encode user for cache", "key", key, "error", err)
+		return
+	}
+
+	err = c.cache.Set(ctx, key, buf.String(), c.ttl)
+	if err != nil {
+		c.logger.Warnw("Failed to cache user", "key", key, "error", err)
+	}
+}
+
+// invalidate drops every cached lookup of the user; emails are optional and may be empty
+func (c *userCache) invalidate(ctx context.Context, userID uint, emails ...string) {
+	id := strconv.FormatUint(uint64(userID), 10)
+	keys := []string{userKeyByID(ctx, id), userKeyByUintID(ctx, userID)}
+	for _, email := range emails {
+		if email != "" {
+			keys = append(keys, userKeyByEmail(ctx, email))
+		}
+	}
+
+	err := c.cache.Delete(ctx, keys...)
+	if err != nil {
+		c.logger.Warnw("Failed to invalidate cached user", "user_id", userID, "error", err)
+	}
+}
+
+// CachedUserRepo is a read-through cache in front of another UserRepoInterface.
+// Methods that are not overridden go straight to the wrapped repository.
+type CachedUserRepo struct {
+	UserRepoInterface
+	cache *userCache
+}
+
+func NewCachedUserRepo(repo UserRepoInterface, cache cache.CacheInterface, ttl time.Duration, logger *zap.SugaredLogger) *CachedUserRepo {
+	return &CachedUserRepo{
+		UserRepoInterface: repo,
+		cache:             &userCache{cache: cache, ttl: ttl, logger: logger},
+	}
+}
+
+func (repo *CachedUserRepo) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	key := userKeyByID(ctx, userID)
+	if user, ok := repo.cache.get(ctx, key); ok {
+		return user, nil
+	}
+
+	user, err := repo.UserRepoInterface.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.cache.set(ctx, key, user)
+	return user, nil
+}
+
+func (repo *CachedUserRepo) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	key := userKeyByUintID(ctx, userID)
+	if user, ok := repo.cache.get(ctx, key); ok {
+		return user, nil
+	}
+
+	user, err := repo.UserRepoInterface.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.cache.set(ctx, key, user)
+	return user, nil
+}
+
+func (repo *CachedUserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	key := userKeyByEmail(ctx, email)
+	if user, ok := repo.cache.get(ctx, key); ok {
+		return user, nil
+	}
+
+	user, err := repo.UserRepoInterface.GetUserByEmail(ctx, email)
+	if err != nil {
+		// Misses are not cached so a freshly registered email is visible immediately
+		return nil, err
+	}
+
+	repo.cache.set(ctx, key, user)
+	return user, nil
+}
+
+func (repo *CachedUserRepo) UpdateUser(ctx context.Context, userID string, updatedData *models.User) (*models.User, error) {
+	previousEmail := repo.currentEmail(ctx, userID)
+
+	user, err := repo.UserRepoInterface.UpdateUser(ctx, userID, updatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.cache.invalidate(ctx, user.ID, previousEmail, user.Email)
+	return user, nil
+}
+
+func (repo *CachedUserRepo) DeleteUser(ctx context.Context, userID string) (*models.User, error) {
+	previousEmail := repo.currentEmail(ctx, userID)
+
+	user, err := repo.UserRepoInterface.DeleteUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.cache.invalidate(ctx, user.ID, previousEmail, user.Email)
+	return user, nil
+}
+
+func (repo *CachedUserRepo) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	stored, created, err := repo.UserRepoInterface.CreateOrUpdateByEmail(ctx, user)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !created {
+		repo.cache.invalidate(ctx, stored.ID, stored.Email)
+	}
+	return stored, created, nil
+}
+
+// currentEmail reads the stored email bypassing the cache, so the email key can be dropped after it changes
+func (repo *CachedUserRepo) currentEmail(ctx context.Context, userID string) string {
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	user, err := repo.UserRepoInterface.GetUserByID(ctx, uint(id))
+	if err != nil {
+		return ""
+	}
+	return user.Email
+}
+
+// CachedVoteRepo invalidates cached users whose rating or vote cooldown a vote write changes
+type CachedVoteRepo struct {
+	VoteRepoInterface
+	cache *userCache
+}
+
+func NewCachedVoteRepo(repo VoteRepoInterface, cache cache.CacheInterface, ttl time.Duration, logger *zap.SugaredLogger) *CachedVoteRepo {
+	return &CachedVoteRepo{
+		VoteRepoInterface: repo,
+		cache:             &userCache{cache: cache, ttl: ttl, logger: logger},
+	}
+}
+
+func (repo *CachedVoteRepo) CreateVote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
+	created, err := repo.VoteRepoInterface.CreateVote(ctx, vote)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidateParticipants(ctx, vote.UserID, vote.ProfileID)
+	return created, nil
+}
+
+func (repo *CachedVoteRepo) UpdateVote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
+	updated, err := repo.VoteRepoInterface.UpdateVote(ctx, vote)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidateParticipants(ctx, vote.UserID, vote.ProfileID)
+	return updated, nil
+}
+
+func (repo *CachedVoteRepo) DeleteVote(ctx context.Context, userID uint, profileID uint) error {
+	err := repo.VoteRepoInterface.DeleteVote(ctx, userID, profileID)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidateParticipants(ctx, userID, profileID)
+	return nil
+}
+
+// invalidateParticipants drops the voter and the profile; email-keyed entries are left to expire
+// since votes never change emails and login only needs the password hash and role.
+func (repo *CachedVoteRepo) invalidateParticipants(ctx context.Context, userID uint, profileID uint) {
+	repo.cache.invalidate(ctx, userID)
+	repo.cache.invalidate(ctx, profileID)
+}
+
+// CachedInactivityRepo invalidates the cached lookups of the users it anonymizes, so their personal data is not
+// served from the cache until it expires
+type CachedInactivityRepo struct {
+	InactivityRepoInterface
+	users UserRepoInterface
+	cache *userCache
+}
+
+// NewCachedInactivityRepo reads the email to invalidate from users, which should not be cached itself
+func NewCachedInactivityRepo(repo InactivityRepoInterface, users UserRepoInterface, cache cache.CacheInterface, ttl time.Duration, logger *zap.SugaredLogger) *CachedInactivityRepo {
+	return &CachedInactivityRepo{
+		InactivityRepoInterface: repo,
+		users:                   users,
+		cache:                   &userCache{cache: cache, ttl: ttl, logger: logger},
+	}
+}
+
+func (repo *CachedInactivityRepo) Anonymize(ctx context.Context, userID uint, inactiveBefore time.Time) (bool, error) {
+	previousEmail := ""
+	if user, err := repo.users.GetUserByID(ctx, userID); err == nil {
+		previousEmail = user.Email
+	}
+
+	anonymized, err := repo.InactivityRepoInterface.Anonymize(ctx, userID, inactiveBefore)
+	if err != nil || !anonymized {
+		return anonymized, err
+	}
+
+	repo.cache.invalidate(ctx, userID, previousEmail)
+	return true, nil
+}
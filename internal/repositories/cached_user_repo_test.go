@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+type mapCache struct {
+	values map[string]string
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: map[string]string{}}
+}
+
+func (c *mapCache) Get(ctx context.Context, key string, cacheTTL time.Duration) (string, error) {
+	value, ok := c.values[key]
+	if !ok {
+		return "", errors.New("key does not exist")
+	}
+	return value, nil
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value string, cacheTTL time.Duration) error {
+	c.values[key] = value
+	return nil
+}
+
+func (c *mapCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func TestCachedUserRepo_GetUserReadsThrough(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
+	repo := NewCachedUserRepo(mockRepo, newMapCache(), time.Minute, zaptest.NewLogger(t).Sugar())
+
+	stored := &models.User{ID: 1, Email: "a@example.com", Password: "hash"}
+	mockRepo.EXPECT().GetUser(gomock.Any(), "1").Return(stored, nil).Times(1)
+
+	first, err := repo.GetUser(context.Background(), "1")
+	assert.NoError(t, err)
+	second, err := repo.GetUser(context.Background(), "1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, stored, first)
+	assert.Equal(t, "hash", second.Password)
+}
+
+func TestCachedUserRepo_GetUserByEmailDoesNotCacheMisses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
+	repo := NewCachedUserRepo(mockRepo, newMapCache(), time.Minute, zaptest.NewLogger(t).Sugar())
+
+	mockRepo.EXPECT().GetUserByEmail(gomock.Any(), "a@example.com").Return(nil, ErrNotFound).Times(2)
+
+	_, _ = repo.GetUserByEmail(context.Background(), "a@example.com")
+	user, err := repo.GetUserByEmail(context.Background(), "a@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, user)
+}
+
+func TestCachedUserRepo_UpdateUserInvalidates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
+	cache := newMapCache()
+	repo := NewCachedUserRepo(mockRepo, cache, time.Minute, zaptest.NewLogger(t).Sugar())
+
+	old := &models.User{ID: 1, Email: "old@example.com"}
+	updated := &models.User{ID: 1, Email: "new@example.com"}
+
+	mockRepo.EXPECT().GetUser(gomock.Any(), "1").Return(old, nil)
+	mockRepo.EXPECT().GetUserByEmail(gomock.Any(), "old@example.com").Return(old, nil)
+	_, _ = repo.GetUser(context.Background(), "1")
+	_, _ = repo.GetUserByEmail(context.Background(), "old@example.com")
+	assert.Len(t, cache.values, 2)
+
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint(1)).Return(old, nil)
+	mockRepo.EXPECT().UpdateUser(gomock.Any(), "1", updated).Return(updated, nil)
+
+	_, err := repo.UpdateUser(context.Background(), "1", updated)
+	assert.NoError(t, err)
+	assert.Empty(t, cache.values)
+}
+
+func TestCachedVoteRepo_CreateVoteInvalidatesParticipants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVotes := mocks.NewMockVoteRepoInterface(ctrl)
+	cache := newMapCache()
+	ctx := context.Background()
+	cache.values[userKeyByUintID(ctx, 1)] = "voter"
+	cache.values[userKeyByID(ctx, "2")] = "profile"
+	cache.values[userKeyByID(ctx, "3")] = "bystander"
+	repo := NewCachedVoteRepo(mockVotes, cache, time.Minute, zaptest.NewLogger(t).Sugar())
+
+	vote := &models.Vote{UserID: 1, ProfileID: 2, Value: 1}
+	mockVotes.EXPECT().CreateVote(gomock.Any(), vote).Return(vote, nil)
+
+	_, err := repo.CreateVote(ctx, vote)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{userKeyByID(ctx, "3"): "bystander"}, cache.values)
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ChangeRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type ChangeRepoInterface interface {
+	// ListChanges returns up to limit changes with a sequence number greater than sinceSeq, in order, each with
+	// the current version of its user unless the user is deleted
+	ListChanges(ctx context.Context, sinceSeq uint64, limit int) ([]models.Change, error)
+	// RelayChanges moves up to limit committed entries of the outbox into the change feed, numbering them in
+	// the order they were written, and returns how many it moved
+	RelayChanges(ctx context.Context, limit int) (int, error)
+}
+
+func NewChangeRepo(db *gorm.DB, logger *zap.SugaredLogger) *ChangeRepo {
+	return &ChangeRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *ChangeRepo) ListChanges(ctx context.Context, sinceSeq uint64, limit int) ([]models.Change, error) {
+	tx := reader(ctx, repo.db)
+
+	var changes []models.Change
+	result := tx.Where("seq > ?", sinceSeq).Order("seq").Limit(limit).Find(&changes)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	ids := make([]uint, 0, len(changes))
+	for _, change := range changes {
+		ids = append(ids, change.UserID)
+	}
+	var users []models.User
+	result = tx.Preload("Role").Where("id IN ?", ids).Where("deleted_at IS NULL OR deleted_at = ?", time.Time{}).Find(&users)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	byID := make(map[uint]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	for i := range changes {
+		changes[i].User = byID[changes[i].UserID]
+	}
+	return changes, nil
+}
+
+func (repo *ChangeRepo) RelayChanges(ctx context.Context, limit int) (int, error) {
+	relayed := 0
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		// Relays take turns, so sequence numbers follow the outbox; SQLite runs one write transaction at a time anyway
+		if tx.Dialector.Name() == "postgres" {
+			err := tx.Exec("SELECT pg_advisory_xact_lock(?)", models.ChangeFeedLock).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var entries []models.ChangeOutbox
+		err := tx.Order("id").Limit(limit).Find(&entries).Error
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+
+		changes := make([]models.Change, len(entries))
+		ids := make([]uint64, len(entries))
+		for i, entry := range entries {
+			changes[i] = models.Change{
+				TenantID:  entry.TenantID,
+				UserID:    entry.UserID,
+				Operation: entry.Operation,
+				ChangedAt: entry.ChangedAt,
+			}
+			ids[i] = entry.ID
+		}
+		if err := tx.Create(&changes).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&models.ChangeOutbox{}).Error; err != nil {
+			return err
+		}
+		relayed = len(entries)
+		return nil
+	})
+	if err != nil {
+		repo.logger.Error(err)
+		return 0, translateError(err, &apperrors.InsertionFailedErr)
+	}
+	return relayed, nil
+}
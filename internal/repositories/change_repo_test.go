@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChangeRepo_RecordsUserMutationsInOrder(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	userRepo := NewUserRepo(db, logger)
+	repo := NewChangeRepo(db, logger)
+	ctx := context.Background()
+
+	alice := createTestUser(t, userRepo, "alice@example.com")
+	bob := createTestUser(t, userRepo, "bob@example.com")
+	_, err := userRepo.UpdateUser(ctx, fmt.Sprint(alice.ID), &models.User{FirstName: "Alice"})
+	require.NoError(t, err)
+	_, err = userRepo.DeleteUser(ctx, fmt.Sprint(bob.ID))
+	require.NoError(t, err)
+	errs, err := userRepo.CreateUsers(ctx, []*models.User{{Email: "carol@example.com", RoleID: 1}})
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+
+	changes, err := repo.ListChanges(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "changes wait in the outbox until they are relayed")
+
+	relayed, err := repo.RelayChanges(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, relayed)
+	relayed, err = repo.RelayChanges(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, relayed)
+
+	changes, err = repo.ListChanges(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+	operations := make([]string, len(changes))
+	for i, change := range changes {
+		operations[i] = fmt.Sprintf("%s %d", change.Operation, change.UserID)
+		if i > 0 {
+			assert.Greater(t, change.Seq, changes[i-1].Seq)
+		}
+	}
+	assert.Equal(t, []string{"create 1", "create 2", "update 1", "delete 2", "create 3"}, operations)
+	if assert.NotNil(t, changes[0].User) {
+		assert.Equal(t, "Alice", changes[0].User.FirstName, "changes carry the current version")
+		assert.Equal(t, models.StrUser, changes[0].User.Role.Name)
+	}
+	assert.Nil(t, changes[1].User, "deleted users are left out")
+
+	page, err := repo.ListChanges(ctx, changes[2].Seq, 1)
+	require.NoError(t, err)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, changes[3].Seq, page[0].Seq)
+	}
+}
+
+func TestChangeRepo_RecordsColumnUpdates(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	userRepo := NewUserRepo(db, logger)
+	repo := NewChangeRepo(db, logger)
+	ctx := context.Background()
+
+	voter := createTestUser(t, userRepo, "voter@example.com")
+	profile := createTestUser(t, userRepo, "profile@example.com")
+	_, err := repo.RelayChanges(ctx, 10)
+	require.NoError(t, err)
+
+	_, err = NewVoteRepo(db, logger).CreateVote(ctx, &models.Vote{UserID: voter.ID, ProfileID: profile.ID, Value: 1})
+	require.NoError(t, err)
+	_, err = repo.RelayChanges(ctx, 10)
+	require.NoError(t, err)
+
+	changes, err := repo.ListChanges(ctx, 2, 10)
+	require.NoError(t, err)
+	operations := make([]string, len(changes))
+	for i, change := range changes {
+		operations[i] = fmt.Sprintf("%s %d", change.Operation, change.UserID)
+	}
+	assert.Equal(t, []string{fmt.Sprintf("update %d", profile.ID), fmt.Sprintf("update %d", voter.ID)}, operations,
+		"the rating of the profile and the vote time of the voter")
+	if assert.Len(t, changes, 2) && assert.NotNil(t, changes[0].User) {
+		assert.Equal(t, 1, changes[0].User.Rating)
+	}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the repositories; they match with errors.Is whatever message was appended,
+// and errors.As with *apperrors.AppError for the code and message
+var (
+	// ErrNotFound is returned when no (non-deleted) record matches the lookup
+	ErrNotFound = &apperrors.NoRecordFoundErr
+	// ErrDuplicateEmail is returned when a create or update would give two users of a tenant the same email
+	ErrDuplicateEmail = &apperrors.DuplicateEmailErr
+	// ErrConflict is returned when a transaction kept losing to concurrent updates
+	ErrConflict = &apperrors.TransactionConflictErr
+	// ErrDuplicate is returned when a write violates a unique constraint other than the user email
+	ErrDuplicate = &apperrors.DuplicateRecordErr
+	// ErrReference is returned when a write violates a foreign key
+	ErrReference = &apperrors.ReferenceViolationErr
+	// ErrCanceled and ErrTimeout are returned when the context ends before the database answers
+	ErrCanceled = &apperrors.RequestCanceledErr
+	ErrTimeout  = &apperrors.TimeoutErr
+)
+
+// translateError converts an error from GORM or a database driver into the AppError responses are built from.
+// Canceled and timed out contexts, missing records and unique and foreign key violations get their own errors
+// whatever the operation was; anything else becomes fallback, the error of the operation that failed. AppErrors
+// pass through unchanged and nil stays nil.
+func translateError(err error, fallback *apperrors.AppError) error {
+	var appError *apperrors.AppError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &appError):
+		return err
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled.AppendMessage(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout.AppendMessage(err)
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, pgx.ErrNoRows):
+		return ErrNotFound.AppendMessage(err)
+	case isDuplicateEmail(err):
+		return ErrDuplicateEmail.AppendMessage(err)
+	case isUniqueViolation(err):
+		return ErrDuplicate.AppendMessage(err)
+	case isForeignKeyViolation(err):
+		return ErrReference.AppendMessage(err)
+	}
+	return fallback.AppendMessage(err)
+}
+
+// isUniqueViolation recognizes unique constraint errors from Postgres (23505) and SQLite
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgerrcode.UniqueViolation
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// activeEmailIndexWhere is the predicate of users_tenant_email_key, the unique index on the emails of the users
+// that are not deleted; an ON CONFLICT on the index has to repeat it
+const activeEmailIndexWhere = "deleted_at IS NULL OR deleted_at = '0001-01-01 00:00:00+00:00'"
+
+// isDuplicateEmail recognizes a violation of users_tenant_email_key from Postgres and SQLite
+func isDuplicateEmail(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "users_tenant_email_key"
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed: users.tenant_id, users.email_index")
+}
+
+// isForeignKeyViolation recognizes foreign key errors from Postgres (23503) and SQLite
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgerrcode.ForeignKeyViolation
+	}
+	return strings.Contains(err.Error(), "FOREIGN KEY constraint failed")
+}
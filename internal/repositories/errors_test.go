@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   *apperrors.AppError
+		status int
+	}{
+		{"record not found", gorm.ErrRecordNotFound, ErrNotFound, http.StatusNotFound},
+		{"postgres unique violation", &pgconn.PgError{Code: pgerrcode.UniqueViolation}, ErrDuplicate, http.StatusConflict},
+		{"sqlite unique violation", errors.New("UNIQUE constraint failed: feature_flags.name"), ErrDuplicate, http.StatusConflict},
+		{"postgres foreign key violation", &pgconn.PgError{Code: pgerrcode.ForeignKeyViolation}, ErrReference, http.StatusConflict},
+		{"sqlite foreign key violation", errors.New("FOREIGN KEY constraint failed"), ErrReference, http.StatusConflict},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), ErrCanceled, apperrors.StatusClientClosedRequest},
+		{"deadline", context.DeadlineExceeded, ErrTimeout, http.StatusGatewayTimeout},
+		{"anything else", errors.New("connection reset"), &apperrors.QueryFailedErr, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err, &apperrors.QueryFailedErr)
+
+			var appError *apperrors.AppError
+			if assert.ErrorAs(t, err, &appError) {
+				assert.Equal(t, tt.want.Code, appError.Code)
+				assert.Equal(t, tt.status, appError.HTTPCode)
+			}
+			assert.ErrorIs(t, err, tt.err, "the driver error stays in the chain")
+		})
+	}
+}
+
+func TestTranslateError_KeepsAppErrors(t *testing.T) {
+	assert.Nil(t, translateError(nil, &apperrors.QueryFailedErr))
+
+	duplicate := ErrDuplicateEmail.AppendMessage("taken@example.com")
+	assert.Same(t, duplicate, translateError(duplicate, &apperrors.QueryFailedErr))
+}
+
+func TestFeatureFlagRepo_CanceledContext(t *testing.T) {
+	repo := NewFeatureFlagRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.ListFeatureFlags(ctx)
+	assert.ErrorIs(t, err, ErrCanceled)
+}
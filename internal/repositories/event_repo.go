@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type EventRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type EventRepoInterface interface {
+	CreateEvent(ctx context.Context, event *models.Event) (*models.Event, error)
+	ListEvents(ctx context.Context, filter models.EventFilter, afterSeq uint64, limit int) ([]models.Event, error)
+}
+
+func NewEventRepo(db *gorm.DB, logger *zap.SugaredLogger) *EventRepo {
+	return &EventRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *EventRepo) CreateEvent(ctx context.Context, event *models.Event) (*models.Event, error) {
+	if err := writer(ctx, repo.db).Create(event).Error; err != nil {
+		repo.logger.Error("Failed to store event", zap.Error(err))
+		return nil, translateError(err, &apperrors.InsertionFailedErr)
+	}
+	return event, nil
+}
+
+// ListEvents returns up to limit events matching the filter with a sequence number greater than afterSeq, in order
+func (repo *EventRepo) ListEvents(ctx context.Context, filter models.EventFilter, afterSeq uint64, limit int) ([]models.Event, error) {
+	var events []models.Event
+	tx := reader(ctx, repo.db).Where("seq > ?", afterSeq)
+
+	if filter.FromSeq > 0 {
+		tx = tx.Where("seq >= ?", filter.FromSeq)
+	}
+	if filter.ToSeq > 0 {
+		tx = tx.Where("seq <= ?", filter.ToSeq)
+	}
+	if !filter.FromTime.IsZero() {
+		tx = tx.Where("time >= ?", filter.FromTime)
+	}
+	if !filter.ToTime.IsZero() {
+		tx = tx.Where("time <= ?", filter.ToTime)
+	}
+
+	result := tx.Order("seq").Limit(limit).Find(&events)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return events, nil
+}
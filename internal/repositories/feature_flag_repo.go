@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FeatureFlagRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type FeatureFlagRepoInterface interface {
+	ListFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error)
+	// SetFeatureFlag creates the override for the flag's name and user or replaces its state
+	SetFeatureFlag(ctx context.Context, flag *models.FeatureFlag) error
+	DeleteFeatureFlag(ctx context.Context, name string, userID uint) error
+}
+
+func NewFeatureFlagRepo(db *gorm.DB, logger *zap.SugaredLogger) *FeatureFlagRepo {
+	return &FeatureFlagRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *FeatureFlagRepo) ListFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	var flags []models.FeatureFlag
+	result := reader(ctx, repo.db).Order("name, user_id").Find(&flags)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return flags, nil
+}
+
+func (repo *FeatureFlagRepo) SetFeatureFlag(ctx context.Context, flag *models.FeatureFlag) error {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(flag)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *FeatureFlagRepo) DeleteFeatureFlag(ctx context.Context, name string, userID uint) error {
+	result := writer(ctx, repo.db).Where("name = ? AND user_id = ?", name, userID).Delete(&models.FeatureFlag{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Feature flag override not found.")
+	}
+	return nil
+}
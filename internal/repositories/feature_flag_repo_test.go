@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFeatureFlagRepo_SetReplacesOverride(t *testing.T) {
+	repo := NewFeatureFlagRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	require.NoError(t, repo.SetFeatureFlag(ctx, &models.FeatureFlag{Name: models.FeatureVoting, Enabled: true}))
+	require.NoError(t, repo.SetFeatureFlag(ctx, &models.FeatureFlag{Name: models.FeatureVoting, UserID: 3, Enabled: true}))
+	require.NoError(t, repo.SetFeatureFlag(ctx, &models.FeatureFlag{Name: models.FeatureVoting, Enabled: false}))
+
+	flags, err := repo.ListFeatureFlags(ctx)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, uint(0), flags[0].UserID)
+	assert.False(t, flags[0].Enabled)
+	assert.Equal(t, uint(3), flags[1].UserID)
+
+	require.NoError(t, repo.DeleteFeatureFlag(ctx, models.FeatureVoting, 3))
+	err = repo.DeleteFeatureFlag(ctx, models.FeatureVoting, 3)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
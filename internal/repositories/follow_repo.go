@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type FollowRepoInterface interface {
+	// CreateFollow stores follow and returns whether it is new; following a profile twice keeps the first follow
+	CreateFollow(ctx context.Context, follow *models.Follow) (bool, error)
+	DeleteFollow(ctx context.Context, followerID, followedID uint) error
+	// ListFollowers returns a page of the follows of the user with their followers loaded, latest first, and how
+	// many there are. Deleted and shadow-banned followers are left out.
+	ListFollowers(ctx context.Context, userID uint, page, pageSize int) ([]models.Follow, int, error)
+	// ListFollowing is ListFollowers for the profiles the user follows, with the followed users loaded
+	ListFollowing(ctx context.Context, userID uint, page, pageSize int) ([]models.Follow, int, error)
+	CountFollows(ctx context.Context, userID uint) (*models.FollowCounts, error)
+	// ListFollowerIDs returns up to limit IDs of followers of the user above afterID, in order, so every follower
+	// can be walked in batches
+	ListFollowerIDs(ctx context.Context, userID, afterID uint, limit int) ([]uint, error)
+}
+
+func NewFollowRepo(db *gorm.DB, logger *zap.SugaredLogger) *FollowRepo {
+	return &FollowRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *FollowRepo) CreateFollow(ctx context.Context, follow *models.Follow) (bool, error) {
+	result := writer(ctx, repo.db).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *FollowRepo) DeleteFollow(ctx context.Context, followerID, followedID uint) error {
+	result := writer(ctx, repo.db).Where("follower_id = ? AND followed_id = ?", followerID, followedID).Delete(&models.Follow{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Follow not found.")
+	}
+	return nil
+}
+
+func (repo *FollowRepo) ListFollowers(ctx context.Context, userID uint, page, pageSize int) ([]models.Follow, int, error) {
+	return repo.list(ctx, "followed_id", "follower_id", "Follower", userID, page, pageSize)
+}
+
+func (repo *FollowRepo) ListFollowing(ctx context.Context, userID uint, page, pageSize int) ([]models.Follow, int, error) {
+	return repo.list(ctx, "follower_id", "followed_id", "Followed", userID, page, pageSize)
+}
+
+// list pages through the follows whose column is userID, joined to the users in other that are not deleted, and
+// loads those users as association
+func (repo *FollowRepo) list(ctx context.Context, column, other, association string, userID uint, page, pageSize int) ([]models.Follow, int, error) {
+	tx := repo.visible(reader(ctx, repo.db).Model(&models.Follow{}), other).
+		Where("follows."+column+" = ?", userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	result := tx.Count(&total)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	var follows []models.Follow
+	result = tx.Preload(association + ".Role").
+		Order("follows.created_at DESC, follows." + other + " DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&follows)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return follows, int(total), nil
+}
+
+func (repo *FollowRepo) CountFollows(ctx context.Context, userID uint) (*models.FollowCounts, error) {
+	var followers, following int64
+	result := repo.visible(reader(ctx, repo.db).Model(&models.Follow{}), "follower_id").
+		Where("follows.followed_id = ?", userID).
+		Count(&followers)
+	if result.Error == nil {
+		result = repo.visible(reader(ctx, repo.db).Model(&models.Follow{}), "followed_id").
+			Where("follows.follower_id = ?", userID).
+			Count(&following)
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return &models.FollowCounts{UserID: userID, Followers: int(followers), Following: int(following)}, nil
+}
+
+func (repo *FollowRepo) ListFollowerIDs(ctx context.Context, userID, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	result := repo.active(reader(ctx, repo.db).Model(&models.Follow{}), "follower_id").
+		Where("follows.followed_id = ? AND follows.follower_id > ?", userID, afterID).
+		Order("follows.follower_id").
+		Limit(limit).
+		Pluck("follows.follower_id", &ids)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return ids, nil
+}
+
+// active keeps the follows whose user in column is not deleted
+func (repo *FollowRepo) active(tx *gorm.DB, column string) *gorm.DB {
+	return tx.Joins("JOIN users ON users.id = follows."+column).
+		Where("(users.deleted_at IS NULL OR users.deleted_at = ?)", time.Time{})
+}
+
+// visible keeps the active follows whose user in column is not shadow-banned either. Shadow-banned followers are
+// still notified, so only the lists and counts others see leave them out.
+func (repo *FollowRepo) visible(tx *gorm.DB, column string) *gorm.DB {
+	return repo.active(tx, column).Where("users.id NOT IN (" + models.ShadowBannedUsers + ")")
+}
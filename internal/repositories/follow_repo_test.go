@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFollowRepo_FollowsAndCounts(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserRepo(db, zaptest.NewLogger(t).Sugar())
+	repo := NewFollowRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	ann := createTestUser(t, users, "ann@example.com")
+	bob := createTestUser(t, users, "bob@example.com")
+	eve := createTestUser(t, users, "eve@example.com")
+	now := time.Now()
+
+	created, err := repo.CreateFollow(ctx, &models.Follow{FollowerID: bob.ID, FollowedID: ann.ID, CreatedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = repo.CreateFollow(ctx, &models.Follow{FollowerID: bob.ID, FollowedID: ann.ID})
+	require.NoError(t, err)
+	assert.False(t, created, "following twice keeps the first follow")
+	_, err = repo.CreateFollow(ctx, &models.Follow{FollowerID: eve.ID, FollowedID: ann.ID, CreatedAt: now})
+	require.NoError(t, err)
+	_, err = repo.CreateFollow(ctx, &models.Follow{FollowerID: ann.ID, FollowedID: bob.ID, CreatedAt: now})
+	require.NoError(t, err)
+
+	followers, total, err := repo.ListFollowers(ctx, ann.ID, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, followers, 1)
+	assert.Equal(t, eve.ID, followers[0].FollowerID, "latest first")
+	require.NotNil(t, followers[0].Follower)
+	assert.Equal(t, "eve@example.com", followers[0].Follower.Email)
+	assert.Equal(t, models.StrUser, followers[0].Follower.Role.Name)
+
+	following, total, err := repo.ListFollowing(ctx, bob.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, following, 1)
+	assert.Equal(t, "ann@example.com", following[0].Followed.Email)
+
+	counts, err := repo.CountFollows(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.FollowCounts{UserID: ann.ID, Followers: 2, Following: 1}, counts)
+
+	ids, err := repo.ListFollowerIDs(ctx, ann.ID, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{bob.ID}, ids)
+	ids, err = repo.ListFollowerIDs(ctx, ann.ID, bob.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{eve.ID}, ids)
+
+	_, err = users.DeleteUser(ctx, fmt.Sprint(eve.ID))
+	require.NoError(t, err)
+	counts, err = repo.CountFollows(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, counts.Followers, "deleted followers are left out")
+
+	require.NoError(t, repo.DeleteFollow(ctx, bob.ID, ann.ID))
+	assert.ErrorIs(t, repo.DeleteFollow(ctx, bob.ID, ann.ID), ErrNotFound)
+	followers, total, err = repo.ListFollowers(ctx, ann.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, followers)
+}
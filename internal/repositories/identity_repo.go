@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IdentityRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type IdentityRepoInterface interface {
+	// ListIdentities returns the stored identities of the user, oldest first
+	ListIdentities(ctx context.Context, userID uint) ([]models.Identity, error)
+	// CreateIdentity returns ErrDuplicate when the user already has an identity at the provider, or the account
+	// at the provider is linked to another user
+	CreateIdentity(ctx context.Context, identity *models.Identity) error
+	// DeleteIdentity returns ErrNotFound when the user has no identity at the provider
+	DeleteIdentity(ctx context.Context, userID uint, provider string) error
+}
+
+func NewIdentityRepo(db *gorm.DB, logger *zap.SugaredLogger) *IdentityRepo {
+	return &IdentityRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *IdentityRepo) ListIdentities(ctx context.Context, userID uint) ([]models.Identity, error) {
+	var identities []models.Identity
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).Order("id").Find(&identities)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return identities, nil
+}
+
+func (repo *IdentityRepo) CreateIdentity(ctx context.Context, identity *models.Identity) error {
+	result := writer(ctx, repo.db).Create(identity)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *IdentityRepo) DeleteIdentity(ctx context.Context, userID uint, provider string) error {
+	result := writer(ctx, repo.db).Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.Identity{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Identity not found.")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIdentityRepo_LinkAndUnlink(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewIdentityRepo(db, logger)
+	ctx := context.Background()
+
+	owner := createTestUser(t, NewUserRepo(db, logger), "owner@example.com")
+	other := createTestUser(t, NewUserRepo(db, logger), "other@example.com")
+	require.NoError(t, repo.CreateIdentity(ctx, &models.Identity{UserID: owner.ID, Provider: models.ProviderPassword, Subject: "1"}))
+	require.NoError(t, repo.CreateIdentity(ctx, &models.Identity{UserID: owner.ID, Provider: models.ProviderGitHub, Subject: "octocat"}))
+
+	err := repo.CreateIdentity(ctx, &models.Identity{UserID: owner.ID, Provider: models.ProviderGitHub, Subject: "someone-else"})
+	assert.True(t, errors.Is(err, ErrDuplicate), "one identity per provider, got %v", err)
+	err = repo.CreateIdentity(ctx, &models.Identity{UserID: other.ID, Provider: models.ProviderGitHub, Subject: "octocat"})
+	assert.True(t, errors.Is(err, ErrDuplicate), "a provider account is linked to one user, got %v", err)
+
+	identities, err := repo.ListIdentities(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+	assert.Equal(t, models.ProviderPassword, identities[0].Provider, "oldest first")
+
+	require.NoError(t, repo.DeleteIdentity(ctx, owner.ID, models.ProviderGitHub))
+	assert.True(t, errors.Is(repo.DeleteIdentity(ctx, owner.ID, models.ProviderGitHub), ErrNotFound))
+	assert.True(t, errors.Is(repo.DeleteIdentity(ctx, other.ID, models.ProviderPassword), ErrNotFound))
+
+	identities, err = repo.ListIdentities(ctx, owner.ID)
+	require.NoError(t, err)
+	assert.Len(t, identities, 1)
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InactivityRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type InactivityRepoInterface interface {
+	// RecordLogin stores the user's latest login and withdraws a pending warning
+	RecordLogin(ctx context.Context, userID uint, at time.Time) error
+	// ListToWarn returns up to limit users, admins aside, who have not signed in since inactiveBefore and were
+	// not warned yet; users who never signed in count from their signup
+	ListToWarn(ctx context.Context, inactiveBefore time.Time, limit int) ([]models.User, error)
+	MarkWarned(ctx context.Context, userID uint, at time.Time) error
+	// ListToAnonymize returns the IDs of up to limit users inactive since inactiveBefore and warned before
+	// warnedBefore
+	ListToAnonymize(ctx context.Context, inactiveBefore, warnedBefore time.Time, limit int) ([]uint, error)
+	// Anonymize overwrites the user's personal data, deletes the account and drops its history, unless the user
+	// signed in since inactiveBefore in the meantime; it reports whether the user was anonymized
+	Anonymize(ctx context.Context, userID uint, inactiveBefore time.Time) (bool, error)
+}
+
+func NewInactivityRepo(db *gorm.DB, logger *zap.SugaredLogger) *InactivityRepo {
+	return &InactivityRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *InactivityRepo) RecordLogin(ctx context.Context, userID uint, at time.Time) error {
+	activity := &models.UserActivity{UserID: userID, LastLoginAt: at}
+	err := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_login_at": at, "warned_at": time.Time{}}),
+	}).Create(activity).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return translateError(err, &apperrors.QueryFailedErr)
+	}
+	return nil
+}
+
+// inactive selects the users not deleted and not admins whose last login, or signup, is before inactiveBefore
+func inactive(db *gorm.DB, inactiveBefore time.Time) *gorm.DB {
+	return db.Model(&models.User{}).
+		Joins("LEFT JOIN user_activity ON user_activity.user_id = users.id").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("(users.deleted_at IS NULL OR users.deleted_at = ?)", time.Time{}).
+		Where("roles.name <> ?", models.StrAdmin).
+		Where("COALESCE(user_activity.last_login_at, users.created_at) < ?", inactiveBefore)
+}
+
+func (repo *InactivityRepo) ListToWarn(ctx context.Context, inactiveBefore time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := inactive(reader(ctx, repo.db), inactiveBefore).
+		Where("(user_activity.warned_at IS NULL OR user_activity.warned_at = ?)", time.Time{}).
+		Order("users.id").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.QueryFailedErr)
+	}
+	return users, nil
+}
+
+func (repo *InactivityRepo) MarkWarned(ctx context.Context, userID uint, at time.Time) error {
+	// Users who never signed in have no row yet; their signup stands in for the last login
+	err := writer(ctx, repo.db).Exec(
+		"INSERT INTO user_activity (user_id, tenant_id, last_login_at, warned_at) "+
+			"SELECT id, tenant_id, created_at, ? FROM users WHERE id = ? "+
+			"ON CONFLICT (user_id) DO UPDATE SET warned_at = excluded.warned_at",
+		at, userID,
+	).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return translateError(err, &apperrors.QueryFailedErr)
+	}
+	return nil
+}
+
+func (repo *InactivityRepo) ListToAnonymize(ctx context.Context, inactiveBefore, warnedBefore time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := inactive(reader(ctx, repo.db), inactiveBefore).
+		Where("user_activity.warned_at > ? AND user_activity.warned_at <= ?", time.Time{}, warnedBefore).
+		Order("users.id").
+		Limit(limit).
+		Pluck("users.id", &ids).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.QueryFailedErr)
+	}
+	return ids, nil
+}
+
+func (repo *InactivityRepo) Anonymize(ctx context.Context, userID uint, inactiveBefore time.Time) (bool, error) {
+	anonymized := false
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		var activity models.UserActivity
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&activity, "user_id = ?", userID).Error
+		if err != nil {
+			return err
+		}
+		// Signed in after it was listed
+		if !activity.LastLoginAt.Before(inactiveBefore) || activity.WarnedAt.IsZero() {
+			return nil
+		}
+
+		var user models.User
+		err = tx.Take(&user, "id = ? AND (deleted_at IS NULL OR deleted_at = ?)", userID, time.Time{}).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// The placeholder keeps the email unique within the tenant and can never receive mail
+		user.Email = fmt.Sprintf("anonymized-%d@invalid", user.ID)
+		user.FirstName, user.LastName, user.Password = "", "", ""
+		user.Timezone, user.Locale = "", ""
+		user.DeletedAt = time.Now()
+		err = tx.Omit(clause.Associations).Save(&user).Error
+		if err != nil {
+			return err
+		}
+
+		// The hooks recorded the prior version in the history; nothing personal may be left behind
+		for _, model := range []interface{}{&models.UserHistory{}, &models.Identity{}, &models.UserActivity{}, &models.Phone{}, &models.PhoneCode{}, &models.RememberToken{}} {
+			err = tx.Where("user_id = ?", user.ID).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		anonymized = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		repo.logger.Error(err)
+		return false, translateError(err, &apperrors.DeletionFailedErr)
+	}
+	return anonymized, nil
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestInactivityRepo_WarnsThenAnonymizes(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	userRepo := NewUserRepo(db, logger)
+	repo := NewInactivityRepo(db, logger)
+	ctx := context.Background()
+
+	idle := createTestUser(t, userRepo, "idle@example.com")
+	active := createTestUser(t, userRepo, "active@example.com")
+	admin := createTestUser(t, userRepo, "admin@example.com")
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", admin.ID).Update("role_id", 3).Error)
+	require.NoError(t, repo.RecordLogin(ctx, idle.ID, time.Now().AddDate(-1, 0, 0)))
+	require.NoError(t, repo.RecordLogin(ctx, active.ID, time.Now()))
+	require.NoError(t, db.Create(&models.Identity{UserID: idle.ID, Provider: models.ProviderGoogle, Subject: "42"}).Error)
+
+	// Users who never signed in count from their signup, which is now for the admin
+	users, err := repo.ListToWarn(ctx, time.Now().AddDate(0, -1, 0), 10)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "idle@example.com", users[0].Email)
+
+	warnedAt := time.Now().AddDate(0, 0, -31)
+	require.NoError(t, repo.MarkWarned(ctx, idle.ID, warnedAt))
+	users, err = repo.ListToWarn(ctx, time.Now().AddDate(0, -1, 0), 10)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	ids, err := repo.ListToAnonymize(ctx, time.Now().AddDate(0, -6, 0), time.Now().AddDate(0, 0, -30), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{idle.ID}, ids)
+
+	anonymized, err := repo.Anonymize(ctx, idle.ID, time.Now().AddDate(0, -6, 0))
+	require.NoError(t, err)
+	assert.True(t, anonymized)
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, idle.ID).Error)
+	assert.Equal(t, fmt.Sprintf("anonymized-%d@invalid", idle.ID), stored.Email)
+	assert.Empty(t, stored.FirstName)
+	assert.Empty(t, stored.Password)
+	assert.False(t, stored.DeletedAt.IsZero())
+
+	var history, identities, activity int64
+	db.Model(&models.UserHistory{}).Where("user_id = ?", idle.ID).Count(&history)
+	db.Model(&models.Identity{}).Where("user_id = ?", idle.ID).Count(&identities)
+	db.Model(&models.UserActivity{}).Where("user_id = ?", idle.ID).Count(&activity)
+	assert.Zero(t, history)
+	assert.Zero(t, identities)
+	assert.Zero(t, activity)
+
+	ids, err = repo.ListToAnonymize(ctx, time.Now().AddDate(0, -6, 0), time.Now().AddDate(0, 0, -30), 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestInactivityRepo_LoginWithdrawsTheWarning(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewInactivityRepo(db, logger)
+	ctx := context.Background()
+
+	user := createTestUser(t, NewUserRepo(db, logger), "back@example.com")
+	require.NoError(t, repo.RecordLogin(ctx, user.ID, time.Now().AddDate(-1, 0, 0)))
+	require.NoError(t, repo.MarkWarned(ctx, user.ID, time.Now().AddDate(0, 0, -31)))
+
+	// The login lands between listing and anonymizing
+	require.NoError(t, repo.RecordLogin(ctx, user.ID, time.Now()))
+	anonymized, err := repo.Anonymize(ctx, user.ID, time.Now().AddDate(0, -6, 0))
+	require.NoError(t, err)
+	assert.False(t, anonymized)
+
+	var activity models.UserActivity
+	require.NoError(t, db.First(&activity, "user_id = ?", user.ID).Error)
+	assert.True(t, activity.WarnedAt.IsZero())
+}
+
+func TestCachedInactivityRepo_AnonymizeInvalidatesCachedUser(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	userRepo := NewUserRepo(db, logger)
+	cache := newMapCache()
+	cachedUsers := NewCachedUserRepo(userRepo, cache, time.Minute, logger)
+	repo := NewCachedInactivityRepo(NewInactivityRepo(db, logger), userRepo, cache, time.Minute, logger)
+	ctx := context.Background()
+
+	idle := createTestUser(t, userRepo, "idle@example.com")
+	require.NoError(t, repo.RecordLogin(ctx, idle.ID, time.Now().AddDate(-1, 0, 0)))
+	require.NoError(t, repo.MarkWarned(ctx, idle.ID, time.Now().AddDate(0, 0, -31)))
+
+	_, err := cachedUsers.GetUser(ctx, fmt.Sprint(idle.ID))
+	require.NoError(t, err)
+	_, err = cachedUsers.GetUserByID(ctx, idle.ID)
+	require.NoError(t, err)
+	_, err = cachedUsers.GetUserByEmail(ctx, "idle@example.com")
+	require.NoError(t, err)
+	require.Len(t, cache.values, 3)
+
+	anonymized, err := repo.Anonymize(ctx, idle.ID, time.Now().AddDate(0, -6, 0))
+	require.NoError(t, err)
+	require.True(t, anonymized)
+
+	assert.Empty(t, cache.values)
+	_, err = cachedUsers.GetUserByEmail(ctx, "idle@example.com")
+	assert.Error(t, err)
+	if user, err := cachedUsers.GetUserByID(ctx, idle.ID); err == nil {
+		assert.Equal(t, fmt.Sprintf("anonymized-%d@invalid", idle.ID), user.Email)
+		assert.Empty(t, user.Password)
+	}
+}
@@ -0,0 +1,239 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/database"
+	"gitlab.com/jkozhemiaka/web-layout/internal/integrationtest"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// newPostgresDB is newTestDB against a migrated Postgres database
+func newPostgresDB(t *testing.T) *gorm.DB {
+	return integrationtest.Database(t, integrationtest.Config(integrationtest.PostgresURI(t)))
+}
+
+func TestPostgres_UserRepo(t *testing.T) {
+	repo := NewUserRepo(newPostgresDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	first := createTestUser(t, repo, "a@example.com")
+	second := createTestUser(t, repo, "b@example.com")
+
+	byEmail, err := repo.GetUserByEmail(ctx, "a@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, byEmail.ID)
+	assert.Equal(t, models.StrUser, byEmail.Role.Name)
+
+	_, err = repo.CreateUser(ctx, &models.User{Email: "a@example.com", Password: "hash", RoleID: 1})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+	_, err = repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{Email: "b@example.com"})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+	_, err = repo.CreateUser(ctx, &models.User{Email: "c@example.com", Password: "hash", RoleID: 999})
+	assert.ErrorIs(t, err, ErrReference)
+
+	_, err = repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{FirstName: "Renamed"})
+	require.NoError(t, err)
+	_, err = repo.DeleteUser(ctx, fmt.Sprint(second.ID))
+	require.NoError(t, err)
+
+	users, total, err := repo.ListUsersWithTotal(ctx, 1, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "Renamed", users[0].FirstName)
+
+	history, err := repo.GetUserHistory(ctx, fmt.Sprint(second.ID))
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.HistoryOperationDelete, history[0].Operation)
+}
+
+func TestPostgres_ConcurrentEmailChangesConflict(t *testing.T) {
+	repo := NewUserRepo(newPostgresDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	users := make([]*models.User, 8)
+	for i := range users {
+		users[i] = createTestUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+	}
+	deleted := createTestUser(t, repo, "taken@example.com")
+	_, err := repo.DeleteUser(ctx, fmt.Sprint(deleted.ID))
+	require.NoError(t, err)
+
+	// Every user races for the email a deleted user had; users_tenant_email_key lets exactly one have it
+	errs := make([]error, len(users))
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(i int, user *models.User) {
+			defer wg.Done()
+			_, errs[i] = repo.UpdateUser(ctx, fmt.Sprint(user.ID), &models.User{Email: "taken@example.com"})
+		}(i, user)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrDuplicateEmail)
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestPostgres_VoteRepoKeepsRatingsInStep(t *testing.T) {
+	db := newPostgresDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewUserRepo(db, logger)
+	votes := NewVoteRepo(db, logger)
+	ctx := context.Background()
+
+	profile := createTestUser(t, users, "profile@example.com")
+	voters := []*models.User{createTestUser(t, users, "a@example.com"), createTestUser(t, users, "b@example.com")}
+
+	for _, voter := range voters {
+		_, err := votes.CreateVote(ctx, &models.Vote{UserID: voter.ID, ProfileID: profile.ID, Value: 1, CreatedAt: time.Now()})
+		require.NoError(t, err)
+	}
+	rating := func() int {
+		user, err := users.GetUserByID(ctx, profile.ID)
+		require.NoError(t, err)
+		return user.Rating
+	}
+	assert.Equal(t, 2, rating())
+
+	_, err := votes.CreateVote(ctx, &models.Vote{UserID: voters[0].ID, ProfileID: profile.ID, Value: -1, CreatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrDuplicate)
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: voters[0].ID, ProfileID: profile.ID + 100, Value: 1, CreatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrReference)
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: voters[0].ID, ProfileID: voters[1].ID, Value: 2, CreatedAt: time.Now()})
+	assert.Error(t, err, "the value is checked by the schema")
+
+	vote, err := votes.GetVote(ctx, voters[0].ID, profile.ID)
+	require.NoError(t, err)
+	vote.Value = -1
+	_, err = votes.UpdateVote(ctx, vote)
+	require.NoError(t, err)
+	assert.Equal(t, 0, rating())
+
+	require.NoError(t, votes.DeleteVote(ctx, voters[1].ID, profile.ID))
+	assert.Equal(t, -1, rating())
+}
+
+func TestPostgres_LockUserByIDSerializesTransactions(t *testing.T) {
+	db := newPostgresDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewUserRepo(db, logger)
+	txManager := NewTxManager(db, logger)
+	user := createTestUser(t, repo, "a@example.com")
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := txManager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			if _, err := repo.LockUserByID(ctx, user.ID); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+		assert.NoError(t, err)
+	}()
+	<-locked
+
+	// A second lock waits for the first transaction, so it runs out of time while that one holds the row
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := repo.LockUserByID(ctx, user.ID)
+		return err
+	})
+	cancel()
+	assert.True(t, errors.Is(err, ErrTimeout) || errors.Is(err, ErrCanceled), "got %v", err)
+
+	close(release)
+	wg.Wait()
+	err = txManager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		_, err := repo.LockUserByID(ctx, user.ID)
+		return err
+	})
+	assert.NoError(t, err)
+}
+
+func TestPostgres_PgxUserRepoMatchesGorm(t *testing.T) {
+	cfg := integrationtest.Config(integrationtest.PostgresURI(t))
+	db := integrationtest.Database(t, cfg)
+	logger := zaptest.NewLogger(t).Sugar()
+	pool, err := database.SetupPgxPool(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	gormRepo := NewUserRepo(db, logger)
+	pgxRepo := NewPgxUserRepo(pool, gormRepo, logger)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := pgxRepo.CreateUser(ctx, &models.User{Email: fmt.Sprintf("user%d@example.com", i), FirstName: "Pgx", LastName: "User", Password: "hash", RoleID: 1})
+		require.NoError(t, err)
+	}
+	_, err = pgxRepo.CreateUser(ctx, &models.User{Email: "user0@example.com", Password: "hash", RoleID: 1})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+
+	byPgx, pgxTotal, err := pgxRepo.ListUsersWithTotal(ctx, 2, 2, nil)
+	require.NoError(t, err)
+	byGorm, gormTotal, err := gormRepo.ListUsersWithTotal(ctx, 2, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, gormTotal, pgxTotal)
+	require.Len(t, byPgx, len(byGorm))
+	for i := range byGorm {
+		assert.Equal(t, byGorm[i].ID, byPgx[i].ID)
+		assert.Equal(t, byGorm[i].Email, byPgx[i].Email)
+	}
+
+	user, err := pgxRepo.GetUserByEmail(ctx, "user3@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, models.StrUser, user.Role.Name)
+}
+
+func TestPostgres_PgxUserRepoNotFound(t *testing.T) {
+	cfg := integrationtest.Config(integrationtest.PostgresURI(t))
+	db := integrationtest.Database(t, cfg)
+	logger := zaptest.NewLogger(t).Sugar()
+	pool, err := database.SetupPgxPool(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	pgxRepo := NewPgxUserRepo(pool, NewUserRepo(db, logger), logger)
+	ctx := context.Background()
+
+	_, err = pgxRepo.GetUserByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = pgxRepo.GetUserByID(ctx, 1<<30)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// A deleted user is not found by email, as with the GORM repository
+	user, err := pgxRepo.CreateUser(ctx, &models.User{Email: "deleted@example.com", Password: "hash", RoleID: 1})
+	require.NoError(t, err)
+	_, err = pgxRepo.DeleteUser(ctx, strconv.FormatUint(uint64(user.ID), 10))
+	require.NoError(t, err)
+	_, err = pgxRepo.GetUserByEmail(ctx, "deleted@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
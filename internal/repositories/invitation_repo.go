@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvitationRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type InvitationRepoInterface interface {
+	CreateInvitation(ctx context.Context, invitation *models.Invitation) error
+	// ListPendingInvitations returns the invitations not accepted yet, expired ones included, newest first
+	ListPendingInvitations(ctx context.Context) ([]models.Invitation, error)
+	// GetPendingInvitation returns ErrNotFound unless the invitation exists and is pending
+	GetPendingInvitation(ctx context.Context, id uint) (*models.Invitation, error)
+	// GetPendingInvitationByEmail returns ErrNotFound when the email has no pending invitation
+	GetPendingInvitationByEmail(ctx context.Context, email string) (*models.Invitation, error)
+	// GetPendingInvitationByToken finds the pending invitation by the SHA-256 of its token and locks it for the
+	// rest of the transaction
+	GetPendingInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	// RenewInvitation replaces the token of a pending invitation and moves its expiry, as resending does
+	RenewInvitation(ctx context.Context, id uint, tokenHash string, sentAt, expiresAt time.Time) error
+	// AcceptInvitation returns ErrNotFound when the invitation was accepted or revoked meanwhile
+	AcceptInvitation(ctx context.Context, id uint, acceptedAt time.Time) error
+	// DeletePendingInvitation revokes an invitation; accepted ones are kept
+	DeletePendingInvitation(ctx context.Context, id uint) error
+	// DeleteExpiredInvitations drops up to limit pending invitations that expired before expiredBefore and returns
+	// how many
+	DeleteExpiredInvitations(ctx context.Context, expiredBefore time.Time, limit int) (int, error)
+}
+
+func NewInvitationRepo(db *gorm.DB, logger *zap.SugaredLogger) *InvitationRepo {
+	return &InvitationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *InvitationRepo) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	result := writer(ctx, repo.db).Omit("Role").Create(invitation)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *InvitationRepo) ListPendingInvitations(ctx context.Context) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	result := reader(ctx, repo.db).Preload("Role").Where("accepted_at IS NULL").Order("id DESC").Find(&invitations)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return invitations, nil
+}
+
+func (repo *InvitationRepo) GetPendingInvitation(ctx context.Context, id uint) (*models.Invitation, error) {
+	return repo.first(reader(ctx, repo.db).Where("id = ? AND accepted_at IS NULL", id))
+}
+
+func (repo *InvitationRepo) GetPendingInvitationByEmail(ctx context.Context, email string) (*models.Invitation, error) {
+	return repo.first(reader(ctx, repo.db).Where("email_index = ? AND accepted_at IS NULL", models.EmailBlindIndex(email)))
+}
+
+func (repo *InvitationRepo) GetPendingInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	return repo.first(writer(ctx, repo.db).Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ? AND accepted_at IS NULL", tokenHash))
+}
+
+func (repo *InvitationRepo) first(query *gorm.DB) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	result := query.Preload("Role").First(invitation)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Invitation not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return invitation, nil
+}
+
+func (repo *InvitationRepo) RenewInvitation(ctx context.Context, id uint, tokenHash string, sentAt, expiresAt time.Time) error {
+	result := writer(ctx, repo.db).Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Updates(map[string]interface{}{"token_hash": tokenHash, "sent_at": sentAt, "expires_at": expiresAt})
+	return repo.affectedOne(result, &apperrors.UpdateFailedErr)
+}
+
+func (repo *InvitationRepo) AcceptInvitation(ctx context.Context, id uint, acceptedAt time.Time) error {
+	result := writer(ctx, repo.db).Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Update("accepted_at", acceptedAt)
+	return repo.affectedOne(result, &apperrors.UpdateFailedErr)
+}
+
+func (repo *InvitationRepo) DeletePendingInvitation(ctx context.Context, id uint) error {
+	result := writer(ctx, repo.db).Where("id = ? AND accepted_at IS NULL", id).Delete(&models.Invitation{})
+	return repo.affectedOne(result, &apperrors.DeletionFailedErr)
+}
+
+func (repo *InvitationRepo) DeleteExpiredInvitations(ctx context.Context, expiredBefore time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.Invitation{}).Select("id").
+		Where("accepted_at IS NULL AND expires_at < ?", expiredBefore).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.Invitation{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
+
+// affectedOne translates the error of a write to one pending invitation, and ErrNotFound when there was none
+func (repo *InvitationRepo) affectedOne(result *gorm.DB, fallback *apperrors.AppError) error {
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, fallback)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Invitation not found.")
+	}
+	return nil
+}
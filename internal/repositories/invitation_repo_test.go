@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestInvitationRepo_Lifecycle(t *testing.T) {
+	repo := NewInvitationRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := &models.Invitation{Email: "ann@example.com", RoleID: 2, InvitedBy: 1, TokenHash: "hash-1", SentAt: now, ExpiresAt: now.Add(time.Hour)}
+	second := &models.Invitation{Email: "bob@example.com", RoleID: 1, InvitedBy: 1, TokenHash: "hash-2", SentAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, repo.CreateInvitation(ctx, first))
+	require.NoError(t, repo.CreateInvitation(ctx, second))
+	err := repo.CreateInvitation(ctx, &models.Invitation{Email: "eve@example.com", RoleID: 1, TokenHash: "hash-1", SentAt: now, ExpiresAt: now})
+	assert.True(t, errors.Is(err, ErrDuplicate), "tokens are unique, got %v", err)
+
+	found, err := repo.GetPendingInvitationByEmail(ctx, "ann@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, found.ID)
+	assert.Equal(t, models.StrModerator, found.Role.Name)
+
+	require.NoError(t, repo.RenewInvitation(ctx, first.ID, "hash-3", now, now.Add(2*time.Hour)))
+	_, err = repo.GetPendingInvitationByToken(ctx, "hash-1")
+	assert.True(t, errors.Is(err, ErrNotFound), "the old token is replaced, got %v", err)
+	found, err = repo.GetPendingInvitationByToken(ctx, "hash-3")
+	require.NoError(t, err)
+	assert.True(t, found.ExpiresAt.Equal(now.Add(2*time.Hour)))
+
+	require.NoError(t, repo.AcceptInvitation(ctx, first.ID, now))
+	assert.True(t, errors.Is(repo.AcceptInvitation(ctx, first.ID, now), ErrNotFound), "accepted once")
+	assert.True(t, errors.Is(repo.DeletePendingInvitation(ctx, first.ID), ErrNotFound), "accepted invitations are not revoked")
+	_, err = repo.GetPendingInvitation(ctx, first.ID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	pending, err := repo.ListPendingInvitations(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "bob@example.com", pending[0].Email)
+
+	require.NoError(t, repo.DeletePendingInvitation(ctx, second.ID))
+	pending, err = repo.ListPendingInvitations(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestInvitationRepo_DeleteExpiredInvitations(t *testing.T) {
+	repo := NewInvitationRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	expired := &models.Invitation{Email: "ann@example.com", RoleID: 1, TokenHash: "hash-1", SentAt: now, ExpiresAt: now.Add(-2 * time.Hour)}
+	recent := &models.Invitation{Email: "bob@example.com", RoleID: 1, TokenHash: "hash-2", SentAt: now, ExpiresAt: now.Add(-time.Minute)}
+	accepted := &models.Invitation{Email: "eve@example.com", RoleID: 1, TokenHash: "hash-3", SentAt: now, ExpiresAt: now.Add(-2 * time.Hour)}
+	for _, invitation := range []*models.Invitation{expired, recent, accepted} {
+		require.NoError(t, repo.CreateInvitation(ctx, invitation))
+	}
+	require.NoError(t, repo.AcceptInvitation(ctx, accepted.ID, now))
+
+	deleted, err := repo.DeleteExpiredInvitations(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted, "accepted invitations and ones that can still be resent are kept")
+	pending, err := repo.ListPendingInvitations(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, recent.ID, pending[0].ID)
+}
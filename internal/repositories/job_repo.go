@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type JobRepoInterface interface {
+	// EnqueueJob joins the transaction in ctx, so a job can be committed together with the change it follows up
+	EnqueueJob(ctx context.Context, job *models.Job) error
+	// ClaimJobs marks up to limit due pending jobs of the given kinds as running and returns them. Jobs still
+	// running that were locked before staleBefore belonged to a worker that died and are claimed again.
+	ClaimJobs(ctx context.Context, kinds []string, limit int, now, staleBefore time.Time) ([]models.Job, error)
+	CompleteJob(ctx context.Context, id uint64) error
+	// FailJob records the error of the last attempt and schedules the job again at retryAt, or marks it dead when retryAt is nil
+	FailJob(ctx context.Context, id uint64, lastError string, retryAt *time.Time) error
+	// PostponeJob makes the job pending again at runAt and gives back the attempt it was claimed for
+	PostponeJob(ctx context.Context, id uint64, lastError string, runAt time.Time) error
+	CountJobs(ctx context.Context) (models.JobStats, error)
+	// ListJobs returns the most recently updated jobs, of one state or of all when state is empty
+	ListJobs(ctx context.Context, state string, limit int) ([]models.Job, error)
+	// RetryJob makes a dead job pending again with a fresh set of attempts
+	RetryJob(ctx context.Context, id uint64) error
+	// DeleteSucceededJobs drops up to limit jobs that succeeded before finishedBefore and returns how many
+	DeleteSucceededJobs(ctx context.Context, finishedBefore time.Time, limit int) (int, error)
+}
+
+func NewJobRepo(db *gorm.DB, logger *zap.SugaredLogger) *JobRepo {
+	return &JobRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *JobRepo) EnqueueJob(ctx context.Context, job *models.Job) error {
+	if job.State == "" {
+		job.State = models.JobPending
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	result := writer(ctx, repo.db).Create(job)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *JobRepo) ClaimJobs(ctx context.Context, kinds []string, limit int, now, staleBefore time.Time) ([]models.Job, error) {
+	var jobs []models.Job
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED lets several workers claim concurrently without waiting on each other's rows
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("kind IN ?", kinds).
+			Where("(state = ? AND run_at <= ?) OR (state = ? AND locked_at < ?)", models.JobPending, now, models.JobRunning, staleBefore).
+			Order("run_at, id").
+			Limit(limit).
+			Find(&jobs)
+		if result.Error != nil || len(jobs) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uint64, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].State = models.JobRunning
+			jobs[i].Attempts++
+			jobs[i].LockedAt = &now
+		}
+		return tx.Model(&models.Job{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"state":      models.JobRunning,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"locked_at":  now,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.UpdateFailedErr)
+	}
+	return jobs, nil
+}
+
+func (repo *JobRepo) CompleteJob(ctx context.Context, id uint64) error {
+	return repo.updateJob(ctx, id, map[string]interface{}{
+		"state":      models.JobSucceeded,
+		"locked_at":  nil,
+		"last_error": "",
+	})
+}
+
+func (repo *JobRepo) FailJob(ctx context.Context, id uint64, lastError string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"state":      models.JobDead,
+		"locked_at":  nil,
+		"last_error": lastError,
+	}
+	if retryAt != nil {
+		updates["state"] = models.JobPending
+		updates["run_at"] = *retryAt
+	}
+	return repo.updateJob(ctx, id, updates)
+}
+
+func (repo *JobRepo) PostponeJob(ctx context.Context, id uint64, lastError string, runAt time.Time) error {
+	return repo.updateJob(ctx, id, map[string]interface{}{
+		"state":      models.JobPending,
+		"locked_at":  nil,
+		"last_error": lastError,
+		"run_at":     runAt,
+		"attempts":   gorm.Expr("attempts - 1"),
+	})
+}
+
+func (repo *JobRepo) updateJob(ctx context.Context, id uint64, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	result := writer(ctx, repo.db).Model(&models.Job{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Job not found.")
+	}
+	return nil
+}
+
+func (repo *JobRepo) CountJobs(ctx context.Context) (models.JobStats, error) {
+	var rows []struct {
+		State string
+		Count int64
+	}
+	result := reader(ctx, repo.db).Model(&models.Job{}).Select("state, COUNT(*) AS count").Group("state").Scan(&rows)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	stats := make(models.JobStats, len(models.JobStates))
+	for _, state := range models.JobStates {
+		stats[state] = 0
+	}
+	for _, row := range rows {
+		stats[row.State] = row.Count
+	}
+	return stats, nil
+}
+
+func (repo *JobRepo) ListJobs(ctx context.Context, state string, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	tx := reader(ctx, repo.db).Order("updated_at DESC, id DESC").Limit(limit)
+	if state != "" {
+		tx = tx.Where("state = ?", state)
+	}
+	result := tx.Find(&jobs)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return jobs, nil
+}
+
+func (repo *JobRepo) RetryJob(ctx context.Context, id uint64) error {
+	result := writer(ctx, repo.db).Model(&models.Job{}).Where("id = ? AND state = ?", id, models.JobDead).Updates(map[string]interface{}{
+		"state":      models.JobPending,
+		"attempts":   0,
+		"run_at":     time.Now(),
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Dead job not found.")
+	}
+	return nil
+}
+
+func (repo *JobRepo) DeleteSucceededJobs(ctx context.Context, finishedBefore time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.Job{}).Select("id").
+		Where("state = ? AND updated_at < ?", models.JobSucceeded, finishedBefore).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.Job{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestJobRepo_ClaimsOnlyDueJobsOfRegisteredKinds(t *testing.T) {
+	repo := NewJobRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	due := &models.Job{Kind: "mail", Payload: "{}", MaxAttempts: 3, RunAt: now.Add(-time.Second)}
+	later := &models.Job{Kind: "mail", Payload: "{}", MaxAttempts: 3, RunAt: now.Add(time.Hour)}
+	other := &models.Job{Kind: "export", Payload: "{}", MaxAttempts: 3, RunAt: now.Add(-time.Second)}
+	for _, job := range []*models.Job{due, later, other} {
+		require.NoError(t, repo.EnqueueJob(ctx, job))
+	}
+
+	claimed, err := repo.ClaimJobs(ctx, []string{"mail"}, 10, now, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+	assert.Equal(t, models.JobRunning, claimed[0].State)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// Claimed jobs are not handed out twice
+	claimed, err = repo.ClaimJobs(ctx, []string{"mail"}, 10, now, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// ...unless their worker stopped reporting long ago
+	claimed, err = repo.ClaimJobs(ctx, []string{"mail"}, 10, now.Add(time.Minute), now.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].Attempts)
+}
+
+func TestJobRepo_FailRetryAndStats(t *testing.T) {
+	repo := NewJobRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	retried := &models.Job{Kind: "mail", Payload: "{}", MaxAttempts: 3}
+	dead := &models.Job{Kind: "mail", Payload: "{}", MaxAttempts: 3}
+	done := &models.Job{Kind: "mail", Payload: "{}", MaxAttempts: 3}
+	for _, job := range []*models.Job{retried, dead, done} {
+		require.NoError(t, repo.EnqueueJob(ctx, job))
+	}
+
+	retryAt := time.Now().Add(time.Minute)
+	require.NoError(t, repo.FailJob(ctx, retried.ID, "smtp timeout", &retryAt))
+	require.NoError(t, repo.FailJob(ctx, dead.ID, "bad address", nil))
+	require.NoError(t, repo.CompleteJob(ctx, done.ID))
+
+	stats, err := repo.CountJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStats{models.JobPending: 1, models.JobRunning: 0, models.JobSucceeded: 1, models.JobDead: 1}, stats)
+
+	deadJobs, err := repo.ListJobs(ctx, models.JobDead, 10)
+	require.NoError(t, err)
+	require.Len(t, deadJobs, 1)
+	assert.Equal(t, "bad address", deadJobs[0].LastError)
+
+	require.NoError(t, repo.RetryJob(ctx, dead.ID))
+	assert.True(t, errors.Is(repo.RetryJob(ctx, done.ID), ErrNotFound), "only dead jobs can be retried")
+
+	pending, err := repo.ListJobs(ctx, models.JobPending, 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
+
+func TestJobRepo_DeleteSucceededJobsKeepsRecentAndFailedOnes(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewJobRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	var jobs []*models.Job
+	for i := 0; i < 4; i++ {
+		job := &models.Job{Kind: "mail", Payload: "{}", MaxAttempts: 3}
+		require.NoError(t, repo.EnqueueJob(ctx, job))
+		jobs = append(jobs, job)
+	}
+	require.NoError(t, repo.CompleteJob(ctx, jobs[0].ID))
+	require.NoError(t, repo.CompleteJob(ctx, jobs[1].ID))
+	require.NoError(t, repo.CompleteJob(ctx, jobs[2].ID))
+	require.NoError(t, repo.FailJob(ctx, jobs[3].ID, "bad address", nil))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, db.Model(&models.Job{}).Where("id IN ?", []uint64{jobs[0].ID, jobs[1].ID, jobs[3].ID}).Update("updated_at", old).Error)
+
+	deleted, err := repo.DeleteSucceededJobs(ctx, time.Now().Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted, "one batch at a time")
+	deleted, err = repo.DeleteSucceededJobs(ctx, time.Now().Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	stats, err := repo.CountJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats[models.JobSucceeded])
+	assert.Equal(t, int64(1), stats[models.JobDead])
+}
+
+func TestJobRepo_PostponeGivesTheAttemptBack(t *testing.T) {
+	repo := NewJobRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	job := &models.Job{Kind: "events.deliver", Payload: "{}", MaxAttempts: 3, RunAt: now.Add(-time.Second)}
+	require.NoError(t, repo.EnqueueJob(ctx, job))
+	claimed, err := repo.ClaimJobs(ctx, []string{job.Kind}, 1, now, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	runAt := now.Add(time.Minute)
+	require.NoError(t, repo.PostponeJob(ctx, job.ID, "circuit open", runAt))
+
+	jobs, err := repo.ListJobs(ctx, models.JobPending, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 0, jobs[0].Attempts)
+	assert.Equal(t, "circuit open", jobs[0].LastError)
+	assert.WithinDuration(t, runAt, jobs[0].RunAt, time.Second)
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAlertRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type LoginAlertRepoInterface interface {
+	// CountDevices returns how many devices the user has signed in from
+	CountDevices(ctx context.Context, userID uint) (int64, error)
+	// SaveDevice records device for its user, or moves its LastSeenAt when it is known, and returns whether it was
+	// new
+	SaveDevice(ctx context.Context, device *models.KnownDevice) (bool, error)
+	CreateAlert(ctx context.Context, alert *models.LoginAlert) error
+	// GetAlertByHash finds an alert by the SHA-256 of its token
+	GetAlertByHash(ctx context.Context, tokenHash string) (*models.LoginAlert, error)
+	// DeleteAlerts deletes every alert of the user, so none of their links work any more
+	DeleteAlerts(ctx context.Context, userID uint) error
+	// DeleteExpiredAlerts deletes up to limit alerts expired before now and returns how many
+	DeleteExpiredAlerts(ctx context.Context, now time.Time, limit int) (int, error)
+	// CreateLock locks the user; locking a locked user keeps the first lock
+	CreateLock(ctx context.Context, lock *models.AccountLock) error
+	GetLock(ctx context.Context, userID uint) (*models.AccountLock, error)
+	DeleteLock(ctx context.Context, userID uint) error
+}
+
+func NewLoginAlertRepo(db *gorm.DB, logger *zap.SugaredLogger) *LoginAlertRepo {
+	return &LoginAlertRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *LoginAlertRepo) CountDevices(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	result := reader(ctx, repo.db).Model(&models.KnownDevice{}).Where("user_id = ?", userID).Count(&count)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return count, nil
+}
+
+func (repo *LoginAlertRepo) SaveDevice(ctx context.Context, device *models.KnownDevice) (bool, error) {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(device)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	result = writer(ctx, repo.db).Model(&models.KnownDevice{}).
+		Where("user_id = ? AND fingerprint = ? AND country = ?", device.UserID, device.Fingerprint, device.Country).
+		Update("last_seen_at", device.LastSeenAt)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	return false, nil
+}
+
+func (repo *LoginAlertRepo) CreateAlert(ctx context.Context, alert *models.LoginAlert) error {
+	result := writer(ctx, repo.db).Create(alert)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *LoginAlertRepo) GetAlertByHash(ctx context.Context, tokenHash string) (*models.LoginAlert, error) {
+	alert := &models.LoginAlert{}
+	result := reader(ctx, repo.db).Where("token_hash = ?", tokenHash).First(alert)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Login alert not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return alert, nil
+}
+
+func (repo *LoginAlertRepo) DeleteAlerts(ctx context.Context, userID uint) error {
+	result := writer(ctx, repo.db).Where("user_id = ?", userID).Delete(&models.LoginAlert{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return nil
+}
+
+func (repo *LoginAlertRepo) DeleteExpiredAlerts(ctx context.Context, now time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.LoginAlert{}).Select("id").
+		Where("expires_at < ?", now).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.LoginAlert{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (repo *LoginAlertRepo) CreateLock(ctx context.Context, lock *models.AccountLock) error {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *LoginAlertRepo) GetLock(ctx context.Context, userID uint) (*models.AccountLock, error) {
+	lock := &models.AccountLock{}
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).First(lock)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Account lock not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return lock, nil
+}
+
+func (repo *LoginAlertRepo) DeleteLock(ctx context.Context, userID uint) error {
+	result := writer(ctx, repo.db).Where("user_id = ?", userID).Delete(&models.AccountLock{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Account lock not found.")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLoginAlertRepo_SavesDevicesOnce(t *testing.T) {
+	repo := NewLoginAlertRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	created, err := repo.SaveDevice(ctx, &models.KnownDevice{UserID: 1, Fingerprint: "laptop", Country: "UA", LastSeenAt: now})
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = repo.SaveDevice(ctx, &models.KnownDevice{UserID: 1, Fingerprint: "laptop", Country: "UA", LastSeenAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.False(t, created, "the same device from the same country is known")
+	created, err = repo.SaveDevice(ctx, &models.KnownDevice{UserID: 1, Fingerprint: "laptop", Country: "PL", LastSeenAt: now})
+	require.NoError(t, err)
+	assert.True(t, created, "a known device from another country is new")
+	created, err = repo.SaveDevice(ctx, &models.KnownDevice{UserID: 2, Fingerprint: "laptop", Country: "UA", LastSeenAt: now})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	count, err := repo.CountDevices(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	var device models.KnownDevice
+	require.NoError(t, repo.db.Where("user_id = 1 AND country = 'UA'").First(&device).Error)
+	assert.True(t, device.LastSeenAt.Equal(now.Add(time.Hour)), device.LastSeenAt)
+}
+
+func TestLoginAlertRepo_AlertsAndLocks(t *testing.T) {
+	repo := NewLoginAlertRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, repo.CreateAlert(ctx, &models.LoginAlert{UserID: 1, TokenHash: "old", ExpiresAt: now.Add(-time.Hour)}))
+	require.NoError(t, repo.CreateAlert(ctx, &models.LoginAlert{UserID: 1, TokenHash: "new", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.CreateAlert(ctx, &models.LoginAlert{UserID: 2, TokenHash: "other", ExpiresAt: now.Add(time.Hour)}))
+
+	alert, err := repo.GetAlertByHash(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), alert.UserID)
+
+	deleted, err := repo.DeleteExpiredAlerts(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = repo.GetAlertByHash(ctx, "old")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, repo.DeleteAlerts(ctx, 1))
+	_, err = repo.GetAlertByHash(ctx, "new")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.GetAlertByHash(ctx, "other")
+	assert.NoError(t, err, "the alerts of other users stay")
+
+	_, err = repo.GetLock(ctx, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, repo.CreateLock(ctx, &models.AccountLock{UserID: 1, Reason: models.LockReasonLoginDenied, LockedAt: now}))
+	require.NoError(t, repo.CreateLock(ctx, &models.AccountLock{UserID: 1, Reason: "again", LockedAt: now}))
+	lock, err := repo.GetLock(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.LockReasonLoginDenied, lock.Reason, "the first lock is kept")
+
+	require.NoError(t, repo.DeleteLock(ctx, 1))
+	assert.ErrorIs(t, repo.DeleteLock(ctx, 1), ErrNotFound)
+}
@@ -0,0 +1,416 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// memoryRoles are the roles the migrations create, in their order
+var memoryRoles = map[uint]models.Role{
+	1: {ID: 1, Name: models.StrUser},
+	2: {ID: 2, Name: models.StrModerator},
+	3: {ID: 3, Name: models.StrAdmin},
+}
+
+// MemoryUserRepo keeps users and their history in the process, for tests and USER_REPO_DRIVER=memory. It behaves
+// like UserRepo, including tenant scoping and typed errors, but transactions do not apply to it: LockUserByID
+// locks nothing and a rolled back transaction keeps the changes made here. Safe for concurrent use.
+type MemoryUserRepo struct {
+	logger *zap.SugaredLogger
+
+	mu        sync.RWMutex
+	users     map[uint]*models.User
+	history   []models.UserHistory
+	lastID    uint
+	historyID uint
+}
+
+func NewMemoryUserRepo(logger *zap.SugaredLogger) *MemoryUserRepo {
+	return &MemoryUserRepo{
+		logger: logger,
+		users:  make(map[uint]*models.User),
+	}
+}
+
+// visible reports whether the tenant in ctx, if any, may see a row of tenantID
+func visible(ctx context.Context, tenantID uint) bool {
+	current, ok := tenancy.FromContext(ctx)
+	return !ok || current == tenantID
+}
+
+// rowTenant is the tenant new rows get: the one in ctx, or the default tenant
+func rowTenant(ctx context.Context) uint {
+	if tenantID, ok := tenancy.FromContext(ctx); ok {
+		return tenantID
+	}
+	return tenancy.DefaultTenantID
+}
+
+// snapshot returns a copy of user with its role filled in, so callers never share the stored row
+func (repo *MemoryUserRepo) snapshot(user *models.User) *models.User {
+	copied := *user
+	copied.Role = memoryRoles[user.RoleID]
+	return &copied
+}
+
+// findByEmail returns the user of tenantID with email, in any case, that is not deleted, like users_tenant_email_key
+func (repo *MemoryUserRepo) findByEmail(tenantID uint, email string) *models.User {
+	email = models.NormalizeEmail(email)
+	for _, user := range repo.users {
+		if user.TenantID == tenantID && user.DeletedAt.IsZero() && user.Email == email {
+			return user
+		}
+	}
+	return nil
+}
+
+// active returns the visible, non-deleted user with the id, or nil
+func (repo *MemoryUserRepo) active(ctx context.Context, userID string) *models.User {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return nil
+	}
+	user, ok := repo.users[uint(id)]
+	if !ok || !visible(ctx, user.TenantID) || !user.DeletedAt.IsZero() {
+		return nil
+	}
+	return user
+}
+
+// insert stores user as a new row; the caller holds the write lock
+func (repo *MemoryUserRepo) insert(ctx context.Context, user *models.User) error {
+	if user.TenantID == 0 {
+		user.TenantID = rowTenant(ctx)
+	}
+	user.Email = models.NormalizeEmail(user.Email)
+	if repo.findByEmail(user.TenantID, user.Email) != nil {
+		return ErrDuplicateEmail.AppendMessage(user.Email)
+	}
+	if _, ok := memoryRoles[user.RoleID]; !ok {
+		return ErrReference.AppendMessage("unknown role")
+	}
+
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	repo.lastID++
+	user.ID = repo.lastID
+	user.Role = memoryRoles[user.RoleID]
+
+	stored := *user
+	repo.users[stored.ID] = &stored
+	return nil
+}
+
+// recordHistory appends the current version of user before it changes; the caller holds the write lock
+func (repo *MemoryUserRepo) recordHistory(user *models.User, operation string) {
+	repo.historyID++
+	repo.history = append(repo.history, models.UserHistory{
+		ID:            repo.historyID,
+		TenantID:      user.TenantID,
+		UserID:        user.ID,
+		Operation:     operation,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		RoleID:        user.RoleID,
+		Rating:        user.Rating,
+		VoteUpdatedAt: user.VoteUpdatedAt,
+		DeletedAt:     user.DeletedAt,
+		ValidFrom:     user.UpdatedAt,
+		ChangedAt:     time.Now(),
+	})
+}
+
+func (repo *MemoryUserRepo) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.insert(ctx, user); err != nil {
+		repo.logger.Warn(err)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (repo *MemoryUserRepo) CreateUsers(ctx context.Context, users []*models.User) ([]error, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	rowErrs := make([]error, len(users))
+	for i, user := range users {
+		rowErrs[i] = repo.insert(ctx, user)
+	}
+	return rowErrs, nil
+}
+
+func (repo *MemoryUserRepo) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tenantID := user.TenantID
+	if tenantID == 0 {
+		tenantID = rowTenant(ctx)
+	}
+	if existing := repo.findByEmail(tenantID, user.Email); existing != nil {
+		existing.FirstName = user.FirstName
+		existing.LastName = user.LastName
+		existing.UpdatedAt = time.Now()
+		return repo.snapshot(existing), false, nil
+	}
+
+	if err := repo.insert(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return repo.snapshot(repo.users[user.ID]), true, nil
+}
+
+func (repo *MemoryUserRepo) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user := repo.active(ctx, userID)
+	if user == nil {
+		return nil, ErrNotFound.AppendMessage("No user found with the given ID.")
+	}
+	return repo.snapshot(user), nil
+}
+
+func (repo *MemoryUserRepo) DeleteUser(ctx context.Context, userID string) (*models.User, error) {
+	return repo.UpdateUser(ctx, userID, &models.User{DeletedAt: time.Now()})
+}
+
+// UpdateUser applies the non-zero fields of updatedData, like UserRepo
+func (repo *MemoryUserRepo) UpdateUser(ctx context.Context, userID string, updatedData *models.User) (*models.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user := repo.active(ctx, userID)
+	if user == nil {
+		return nil, ErrNotFound.AppendMessage("No user found with the given ID.")
+	}
+	email := models.NormalizeEmail(updatedData.Email)
+	if email != "" && email != user.Email && repo.findByEmail(user.TenantID, email) != nil {
+		return nil, ErrDuplicateEmail.AppendMessage("The email is already occupied by another user.")
+	}
+
+	operation := models.HistoryOperationUpdate
+	if !updatedData.DeletedAt.IsZero() {
+		operation = models.HistoryOperationDelete
+	}
+	repo.recordHistory(user, operation)
+
+	if email != "" {
+		user.Email = email
+	}
+	if updatedData.FirstName != "" {
+		user.FirstName = updatedData.FirstName
+	}
+	if updatedData.LastName != "" {
+		user.LastName = updatedData.LastName
+	}
+	if updatedData.Password != "" {
+		user.Password = updatedData.Password
+	}
+	if !updatedData.DeletedAt.IsZero() {
+		user.DeletedAt = updatedData.DeletedAt
+	}
+	if updatedData.RoleID > 0 {
+		user.RoleID = updatedData.RoleID
+	}
+	if updatedData.Timezone != "" {
+		user.Timezone = updatedData.Timezone
+	}
+	if updatedData.Locale != "" {
+		user.Locale = updatedData.Locale
+	}
+	user.UpdatedAt = time.Now()
+	return repo.snapshot(user), nil
+}
+
+// activeUsers returns the visible, non-deleted users in id order
+func (repo *MemoryUserRepo) activeUsers(ctx context.Context) []models.User {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	users := make([]models.User, 0, len(repo.users))
+	for _, user := range repo.users {
+		if visible(ctx, user.TenantID) && user.DeletedAt.IsZero() {
+			users = append(users, *repo.snapshot(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+// pageOf cuts one page out of users in the way LIMIT and OFFSET do
+func pageOf(users []models.User, page int, pageSize int) []models.User {
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(users) {
+		return []models.User{}
+	}
+	end := len(users)
+	if pageSize >= 0 && offset+pageSize < end {
+		end = offset + pageSize
+	}
+	return users[offset:end]
+}
+
+func (repo *MemoryUserRepo) ListUsers(ctx context.Context, page int, pageSize int) ([]models.User, error) {
+	return pageOf(repo.activeUsers(ctx), page, pageSize), nil
+}
+
+func (repo *MemoryUserRepo) ListUsersWithTotal(ctx context.Context, page int, pageSize int, filters []filter.Condition) ([]models.User, int, error) {
+	conditions, err := compileUserFilters(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	users := []models.User{}
+	for _, user := range repo.activeUsers(ctx) {
+		if matchesUserFilters(&user, conditions) {
+			users = append(users, user)
+		}
+	}
+	return pageOf(users, page, pageSize), len(users), nil
+}
+
+func (repo *MemoryUserRepo) ListUsersUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range repo.users {
+		if visible(ctx, user.TenantID) && (user.UpdatedAt.After(since) || user.UpdatedAt.Equal(since) && user.ID > afterID) {
+			users = append(users, *repo.snapshot(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].UpdatedAt.Equal(users[j].UpdatedAt) {
+			return users[i].UpdatedAt.Before(users[j].UpdatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// IterateUsers hands fn batches of a snapshot taken when it starts, so fn may call the repository
+func (repo *MemoryUserRepo) IterateUsers(ctx context.Context, batchSize int, fn func(batch []models.User) error) error {
+	users := repo.activeUsers(ctx)
+	for start := 0; start < len(users); start += batchSize {
+		end := start + batchSize
+		if end > len(users) {
+			end = len(users)
+		}
+		if err := fn(users[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryUserRepo) CountUsers(ctx context.Context, filters []filter.Condition) (int, error) {
+	conditions, err := compileUserFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, user := range repo.activeUsers(ctx) {
+		if matchesUserFilters(&user, conditions) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repo *MemoryUserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	email = models.NormalizeEmail(email)
+	for _, user := range repo.users {
+		if visible(ctx, user.TenantID) && user.DeletedAt.IsZero() && user.Email == email {
+			return repo.snapshot(user), nil
+		}
+	}
+	return nil, ErrNotFound.AppendMessage("User not found.")
+}
+
+// GetUserByID also finds deleted users, like UserRepo
+func (repo *MemoryUserRepo) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.users[userID]
+	if !ok || !visible(ctx, user.TenantID) {
+		return nil, ErrNotFound.AppendMessage("User not found.")
+	}
+	return repo.snapshot(user), nil
+}
+
+// LockUserByID is GetUserByID: there is no row lock to take
+func (repo *MemoryUserRepo) LockUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	return repo.GetUserByID(ctx, userID)
+}
+
+func (repo *MemoryUserRepo) GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error) {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return nil, apperrors.QueryFailedErr.AppendMessage(err)
+	}
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	history := []models.UserHistory{}
+	for i := len(repo.history) - 1; i >= 0; i-- {
+		if entry := repo.history[i]; entry.UserID == uint(id) && visible(ctx, entry.TenantID) {
+			history = append(history, entry)
+		}
+	}
+	return history, nil
+}
+
+// setRating stores the rating of a profile and marks when the voter last voted, as the vote hooks do for UserRepo.
+// A zero voterID leaves the vote times alone.
+func (repo *MemoryUserRepo) setRating(profileID uint, rating int, voterID uint) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	if profile, ok := repo.users[profileID]; ok {
+		profile.Rating = rating
+		profile.UpdatedAt = now
+	}
+	if voter, ok := repo.users[voterID]; ok {
+		voter.VoteUpdatedAt = now
+		voter.UpdatedAt = now
+	}
+}
+
+// exists reports whether a user with the id exists, deleted or not, for the votes' foreign keys
+func (repo *MemoryUserRepo) exists(userID uint) bool {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	_, ok := repo.users[userID]
+	return ok
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func createMemoryUser(t *testing.T, repo *MemoryUserRepo, email string) *models.User {
+	user, err := repo.CreateUser(context.Background(), &models.User{
+		Email:     email,
+		FirstName: "Test",
+		LastName:  "User",
+		Password:  "hash",
+		RoleID:    1,
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func TestMemoryUserRepo_CreateGetUpdateDelete(t *testing.T) {
+	repo := NewMemoryUserRepo(zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	first := createMemoryUser(t, repo, "a@example.com")
+	second := createMemoryUser(t, repo, "b@example.com")
+	assert.Equal(t, uint(1), first.ID)
+	assert.Equal(t, tenancy.DefaultTenantID, first.TenantID)
+
+	byEmail, err := repo.GetUserByEmail(ctx, "a@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, byEmail.ID)
+	assert.Equal(t, models.StrUser, byEmail.Role.Name)
+	byEmail, err = repo.GetUserByEmail(ctx, "A@Example.com")
+	require.NoError(t, err, "emails are looked up in any case")
+	assert.Equal(t, first.ID, byEmail.ID)
+
+	_, err = repo.CreateUser(ctx, &models.User{Email: "a@example.com", Password: "hash", RoleID: 1})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+	_, err = repo.CreateUser(ctx, &models.User{Email: "c@example.com", Password: "hash", RoleID: 999})
+	assert.ErrorIs(t, err, ErrReference)
+	_, err = repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{Email: "b@example.com"})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+
+	updated, err := repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{FirstName: "Renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.FirstName)
+	assert.Equal(t, "User", updated.LastName)
+
+	// Returned users are copies, so changing one does not change the store
+	updated.LastName = "Changed"
+	stored, err := repo.GetUser(ctx, fmt.Sprint(first.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "User", stored.LastName)
+
+	_, err = repo.DeleteUser(ctx, fmt.Sprint(second.ID))
+	require.NoError(t, err)
+	_, err = repo.GetUser(ctx, fmt.Sprint(second.ID))
+	assert.ErrorIs(t, err, ErrNotFound)
+	deleted, err := repo.GetUserByID(ctx, second.ID)
+	require.NoError(t, err, "GetUserByID finds deleted users")
+	assert.False(t, deleted.DeletedAt.IsZero())
+
+	history, err := repo.GetUserHistory(ctx, fmt.Sprint(second.ID))
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.HistoryOperationDelete, history[0].Operation)
+}
+
+func TestMemoryUserRepo_ListAndIterate(t *testing.T) {
+	repo := NewMemoryUserRepo(zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		createMemoryUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+	}
+
+	users, total, err := repo.ListUsersWithTotal(ctx, 2, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, users, 2)
+	assert.Equal(t, []uint{3, 4}, []uint{users[0].ID, users[1].ID})
+
+	users, err = repo.ListUsers(ctx, 4, 2)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	var batches []int
+	err = repo.IterateUsers(ctx, 2, func(batch []models.User) error {
+		batches = append(batches, len(batch))
+		// fn may use the repository while iterating
+		_, err := repo.UpdateUser(ctx, fmt.Sprint(batch[0].ID), &models.User{FirstName: "Seen"})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, batches)
+}
+
+func TestMemoryUserRepo_CreateOrUpdateByEmailIsIdempotent(t *testing.T) {
+	repo := NewMemoryUserRepo(zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	user, created, err := repo.CreateOrUpdateByEmail(ctx, &models.User{Email: "a@example.com", FirstName: "First", Password: "hash", RoleID: 1})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	again, created, err := repo.CreateOrUpdateByEmail(ctx, &models.User{Email: "a@example.com", FirstName: "Second", Password: "other", RoleID: 1})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Equal(t, "Second", again.FirstName)
+	assert.Equal(t, "hash", again.Password, "only the names are updated")
+}
+
+func TestMemoryUserRepo_ScopesQueriesToTenant(t *testing.T) {
+	repo := NewMemoryUserRepo(zaptest.NewLogger(t).Sugar())
+	tenantA := tenancy.WithTenant(context.Background(), 1)
+	tenantB := tenancy.WithTenant(context.Background(), 2)
+
+	newUser := func() *models.User {
+		return &models.User{Email: "same@example.com", FirstName: "Test", LastName: "User", Password: "hash", RoleID: 1}
+	}
+	_, err := repo.CreateUser(tenantA, newUser())
+	require.NoError(t, err)
+	userB, err := repo.CreateUser(tenantB, newUser())
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), userB.TenantID)
+
+	_, err = repo.GetUser(tenantA, fmt.Sprint(userB.ID))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.UpdateUser(tenantA, fmt.Sprint(userB.ID), &models.User{FirstName: "Hijacked"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	count, err := repo.CountUsers(tenantB, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = repo.CountUsers(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMemoryUserRepo_ConcurrentCreates(t *testing.T) {
+	repo := NewMemoryUserRepo(zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every email is registered twice, so exactly one of each pair fails
+			_, errs[i] = repo.CreateUser(ctx, &models.User{Email: fmt.Sprintf("user%d@example.com", i/2), Password: "hash", RoleID: 1})
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			assert.ErrorIs(t, err, ErrDuplicateEmail)
+			failed++
+		}
+	}
+	assert.Equal(t, 10, failed)
+	count, err := repo.CountUsers(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 10, count)
+}
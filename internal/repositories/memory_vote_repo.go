@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+)
+
+// voteKey is the (user_id, profile_id) pair the votes table keeps unique
+type voteKey struct {
+	userID    uint
+	profileID uint
+}
+
+// MemoryVoteRepo keeps votes in the process next to a MemoryUserRepo, whose ratings it keeps up to date as the
+// vote hooks do. Safe for concurrent use.
+type MemoryVoteRepo struct {
+	users  *MemoryUserRepo
+	logger *zap.SugaredLogger
+
+	// mu is taken before the user repository's lock, never after it
+	mu     sync.Mutex
+	votes  map[voteKey]*models.Vote
+	lastID uint
+}
+
+func NewMemoryVoteRepo(users *MemoryUserRepo, logger *zap.SugaredLogger) *MemoryVoteRepo {
+	return &MemoryVoteRepo{
+		users:  users,
+		logger: logger,
+		votes:  make(map[voteKey]*models.Vote),
+	}
+}
+
+// updateRating recalculates the rating of a profile from its votes; the caller holds the lock
+func (repo *MemoryVoteRepo) updateRating(profileID uint, voterID uint) {
+	rating := 0
+	for key, vote := range repo.votes {
+		if key.profileID == profileID {
+			rating += vote.Value
+		}
+	}
+	repo.users.setRating(profileID, rating, voterID)
+}
+
+// insert checks vote against the table's constraints and stores it; the caller holds the lock
+func (repo *MemoryVoteRepo) insert(ctx context.Context, vote *models.Vote) error {
+	key := voteKey{userID: vote.UserID, profileID: vote.ProfileID}
+	if _, ok := repo.votes[key]; ok {
+		return ErrDuplicate.AppendMessage("The user has already voted for this profile.")
+	}
+	if !repo.users.exists(vote.UserID) || !repo.users.exists(vote.ProfileID) {
+		return ErrReference.AppendMessage("No user found with the given ID.")
+	}
+	if vote.Value != 1 && vote.Value != -1 {
+		return apperrors.InsertionFailedErr.AppendMessage("the vote value must be 1 or -1")
+	}
+
+	if vote.TenantID == 0 {
+		vote.TenantID = rowTenant(ctx)
+	}
+	if vote.CreatedAt.IsZero() {
+		vote.CreatedAt = time.Now()
+	}
+	repo.lastID++
+	vote.ID = repo.lastID
+
+	stored := *vote
+	repo.votes[key] = &stored
+	repo.updateRating(vote.ProfileID, vote.UserID)
+	return nil
+}
+
+func (repo *MemoryVoteRepo) GetVote(ctx context.Context, userID uint, profileID uint) (*models.Vote, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	vote, ok := repo.votes[voteKey{userID: userID, profileID: profileID}]
+	if !ok || !visible(ctx, vote.TenantID) {
+		return nil, ErrNotFound.AppendMessage("Vote not found.")
+	}
+	copied := *vote
+	return &copied, nil
+}
+
+func (repo *MemoryVoteRepo) CreateVote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.insert(ctx, vote); err != nil {
+		repo.logger.Error("Failed to create vote", zap.Error(err))
+		return nil, err
+	}
+	return vote, nil
+}
+
+// UpdateVote stores vote over the user's vote for the profile, or creates it when there is none, as Save does
+func (repo *MemoryVoteRepo) UpdateVote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	existing, ok := repo.votes[voteKey{userID: vote.UserID, profileID: vote.ProfileID}]
+	if !ok || !visible(ctx, existing.TenantID) {
+		if err := repo.insert(ctx, vote); err != nil {
+			repo.logger.Error("Failed to update vote", zap.Error(err))
+			return nil, err
+		}
+		return vote, nil
+	}
+	if vote.Value != 1 && vote.Value != -1 {
+		err := apperrors.UpdateFailedErr.AppendMessage("the vote value must be 1 or -1")
+		repo.logger.Error("Failed to update vote", zap.Error(err))
+		return nil, err
+	}
+
+	vote.ID = existing.ID
+	vote.TenantID = existing.TenantID
+	*existing = *vote
+	repo.updateRating(vote.ProfileID, vote.UserID)
+	return vote, nil
+}
+
+func (repo *MemoryVoteRepo) DeleteVote(ctx context.Context, userID uint, profileID uint) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := voteKey{userID: userID, profileID: profileID}
+	vote, ok := repo.votes[key]
+	if !ok || !visible(ctx, vote.TenantID) {
+		return ErrNotFound.AppendMessage("Vote not found.")
+	}
+	delete(repo.votes, key)
+	repo.updateRating(profileID, 0)
+	return nil
+}
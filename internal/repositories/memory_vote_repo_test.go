@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMemoryVoteRepo_KeepsRatingsInStep(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewMemoryUserRepo(logger)
+	votes := NewMemoryVoteRepo(users, logger)
+	ctx := context.Background()
+
+	profile := createMemoryUser(t, users, "profile@example.com")
+	voters := []*models.User{createMemoryUser(t, users, "a@example.com"), createMemoryUser(t, users, "b@example.com")}
+	rating := func() int {
+		user, err := users.GetUserByID(ctx, profile.ID)
+		require.NoError(t, err)
+		return user.Rating
+	}
+
+	for _, voter := range voters {
+		_, err := votes.CreateVote(ctx, &models.Vote{UserID: voter.ID, ProfileID: profile.ID, Value: 1})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, rating())
+	voter, err := users.GetUserByID(ctx, voters[0].ID)
+	require.NoError(t, err)
+	assert.False(t, voter.VoteUpdatedAt.IsZero(), "voting starts the voter's cooldown")
+
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: voters[0].ID, ProfileID: profile.ID, Value: -1})
+	assert.ErrorIs(t, err, ErrDuplicate)
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: voters[0].ID, ProfileID: profile.ID + 100, Value: 1})
+	assert.ErrorIs(t, err, ErrReference)
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: voters[0].ID, ProfileID: voters[1].ID, Value: 2})
+	assert.Error(t, err)
+
+	vote, err := votes.GetVote(ctx, voters[0].ID, profile.ID)
+	require.NoError(t, err)
+	vote.Value = -1
+	_, err = votes.UpdateVote(ctx, vote)
+	require.NoError(t, err)
+	assert.Equal(t, 0, rating())
+
+	require.NoError(t, votes.DeleteVote(ctx, voters[1].ID, profile.ID))
+	assert.Equal(t, -1, rating(), "a revoked vote no longer counts")
+	assert.ErrorIs(t, votes.DeleteVote(ctx, voters[1].ID, profile.ID), ErrNotFound)
+	_, err = votes.GetVote(ctx, voters[1].ID, profile.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryVoteRepo_ConcurrentVotes(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewMemoryUserRepo(logger)
+	votes := NewMemoryVoteRepo(users, logger)
+	ctx := context.Background()
+
+	profile := createMemoryUser(t, users, "profile@example.com")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		voter := createMemoryUser(t, users, fmt.Sprintf("voter%d@example.com", i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := votes.CreateVote(ctx, &models.Vote{UserID: voter.ID, ProfileID: profile.ID, Value: 1})
+			assert.NoError(t, err)
+			_, err = users.GetUserByID(ctx, profile.ID)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	user, err := users.GetUserByID(ctx, profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20, user.Rating)
+}
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	filter "gitlab.com/jkozhemiaka/web-layout/internal/filter"
 	models "gitlab.com/jkozhemiaka/web-layout/internal/models"
 )
 
@@ -36,18 +38,34 @@ func (m *MockUserRepoInterface) EXPECT() *MockUserRepoInterfaceMockRecorder {
 }
 
 // CountUsers mocks base method.
-func (m *MockUserRepoInterface) CountUsers(ctx context.Context) (int, error) {
+func (m *MockUserRepoInterface) CountUsers(ctx context.Context, filters []filter.Condition) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CountUsers", ctx)
+	ret := m.ctrl.Call(m, "CountUsers", ctx, filters)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CountUsers indicates an expected call of CountUsers.
-func (mr *MockUserRepoInterfaceMockRecorder) CountUsers(ctx interface{}) *gomock.Call {
+func (mr *MockUserRepoInterfaceMockRecorder) CountUsers(ctx, filters interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockUserRepoInterface)(nil).CountUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockUserRepoInterface)(nil).CountUsers), ctx, filters)
+}
+
+// CreateOrUpdateByEmail mocks base method.
+func (m *MockUserRepoInterface) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateByEmail", ctx, user)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateOrUpdateByEmail indicates an expected call of CreateOrUpdateByEmail.
+func (mr *MockUserRepoInterfaceMockRecorder) CreateOrUpdateByEmail(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateByEmail", reflect.TypeOf((*MockUserRepoInterface)(nil).CreateOrUpdateByEmail), ctx, user)
 }
 
 // CreateUser mocks base method.
@@ -65,6 +83,21 @@ func (mr *MockUserRepoInterfaceMockRecorder) CreateUser(ctx, user interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepoInterface)(nil).CreateUser), ctx, user)
 }
 
+// CreateUsers mocks base method.
+func (m *MockUserRepoInterface) CreateUsers(ctx context.Context, users []*models.User) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUsers", ctx, users)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUsers indicates an expected call of CreateUsers.
+func (mr *MockUserRepoInterfaceMockRecorder) CreateUsers(ctx, users interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUsers", reflect.TypeOf((*MockUserRepoInterface)(nil).CreateUsers), ctx, users)
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserRepoInterface) DeleteUser(ctx context.Context, userID string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +158,35 @@ func (mr *MockUserRepoInterfaceMockRecorder) GetUserByID(ctx, userID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepoInterface)(nil).GetUserByID), ctx, userID)
 }
 
+// GetUserHistory mocks base method.
+func (m *MockUserRepoInterface) GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHistory", ctx, userID)
+	ret0, _ := ret[0].([]models.UserHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHistory indicates an expected call of GetUserHistory.
+func (mr *MockUserRepoInterfaceMockRecorder) GetUserHistory(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHistory", reflect.TypeOf((*MockUserRepoInterface)(nil).GetUserHistory), ctx, userID)
+}
+
+// IterateUsers mocks base method.
+func (m *MockUserRepoInterface) IterateUsers(ctx context.Context, batchSize int, fn func(batch []models.User) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateUsers", ctx, batchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateUsers indicates an expected call of IterateUsers.
+func (mr *MockUserRepoInterfaceMockRecorder) IterateUsers(ctx, batchSize, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateUsers", reflect.TypeOf((*MockUserRepoInterface)(nil).IterateUsers), ctx, batchSize, fn)
+}
+
 // ListUsers mocks base method.
 func (m *MockUserRepoInterface) ListUsers(ctx context.Context, page, pageSize int) ([]models.User, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +202,52 @@ func (mr *MockUserRepoInterfaceMockRecorder) ListUsers(ctx, page, pageSize inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRepoInterface)(nil).ListUsers), ctx, page, pageSize)
 }
 
+// ListUsersUpdatedSince mocks base method.
+func (m *MockUserRepoInterface) ListUsersUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersUpdatedSince", ctx, since, afterID, limit)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersUpdatedSince indicates an expected call of ListUsersUpdatedSince.
+func (mr *MockUserRepoInterfaceMockRecorder) ListUsersUpdatedSince(ctx, since, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersUpdatedSince", reflect.TypeOf((*MockUserRepoInterface)(nil).ListUsersUpdatedSince), ctx, since, afterID, limit)
+}
+
+// ListUsersWithTotal mocks base method.
+func (m *MockUserRepoInterface) ListUsersWithTotal(ctx context.Context, page, pageSize int, filters []filter.Condition) ([]models.User, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersWithTotal", ctx, page, pageSize, filters)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListUsersWithTotal indicates an expected call of ListUsersWithTotal.
+func (mr *MockUserRepoInterfaceMockRecorder) ListUsersWithTotal(ctx, page, pageSize, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersWithTotal", reflect.TypeOf((*MockUserRepoInterface)(nil).ListUsersWithTotal), ctx, page, pageSize, filters)
+}
+
+// LockUserByID mocks base method.
+func (m *MockUserRepoInterface) LockUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserByID", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockUserByID indicates an expected call of LockUserByID.
+func (mr *MockUserRepoInterfaceMockRecorder) LockUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserByID", reflect.TypeOf((*MockUserRepoInterface)(nil).LockUserByID), ctx, userID)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserRepoInterface) UpdateUser(ctx context.Context, userID string, updatedData *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
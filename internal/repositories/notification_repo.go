@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type NotificationRepoInterface interface {
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+	// ListNotifications returns one page of the user's notifications, newest first, and the total across pages
+	ListNotifications(ctx context.Context, userID uint, unreadOnly bool, page int, pageSize int) ([]models.Notification, int, error)
+	CountUnreadNotifications(ctx context.Context, userID uint) (int, error)
+	// MarkNotificationsRead marks the given notifications of the user, or all of them when ids is empty, and returns how many were unread
+	MarkNotificationsRead(ctx context.Context, userID uint, ids []uint64) (int, error)
+	ListNotificationPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error)
+	SetNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error
+}
+
+func NewNotificationRepo(db *gorm.DB, logger *zap.SugaredLogger) *NotificationRepo {
+	return &NotificationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *NotificationRepo) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	result := writer(ctx, repo.db).Create(notification)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *NotificationRepo) ListNotifications(ctx context.Context, userID uint, unreadOnly bool, page int, pageSize int) ([]models.Notification, int, error) {
+	tx := reader(ctx, repo.db).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		tx = tx.Where("read_at IS NULL")
+	}
+	// Reused for the count and the page
+	tx = tx.Session(&gorm.Session{})
+
+	var total int64
+	result := tx.Count(&total)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	var notifications []models.Notification
+	result = tx.Order("created_at DESC, id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&notifications)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return notifications, int(total), nil
+}
+
+func (repo *NotificationRepo) CountUnreadNotifications(ctx context.Context, userID uint) (int, error) {
+	var unread int64
+	result := reader(ctx, repo.db).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return int(unread), nil
+}
+
+func (repo *NotificationRepo) MarkNotificationsRead(ctx context.Context, userID uint, ids []uint64) (int, error) {
+	tx := writer(ctx, repo.db).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		tx = tx.Where("id IN ?", ids)
+	}
+	result := tx.Update("read_at", time.Now())
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (repo *NotificationRepo) ListNotificationPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).Order("channel").Find(&preferences)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return preferences, nil
+}
+
+func (repo *NotificationRepo) SetNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(preference)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
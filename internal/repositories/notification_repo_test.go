@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNotificationRepo_ListAndMarkRead(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewNotificationRepo(db, logger)
+	ctx := context.Background()
+
+	owner := createTestUser(t, NewUserRepo(db, logger), "owner@example.com")
+	other := createTestUser(t, NewUserRepo(db, logger), "other@example.com")
+	var created []*models.Notification
+	for _, title := range []string{"first", "second", "third"} {
+		notification := &models.Notification{UserID: owner.ID, Type: models.NotificationVoteReceived, Title: title}
+		require.NoError(t, repo.CreateNotification(ctx, notification))
+		created = append(created, notification)
+	}
+	require.NoError(t, repo.CreateNotification(ctx, &models.Notification{UserID: other.ID, Type: models.NotificationWelcome, Title: "theirs"}))
+
+	page, total, err := repo.ListNotifications(ctx, owner.ID, false, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "third", page[0].Title, "newest first")
+
+	marked, err := repo.MarkNotificationsRead(ctx, owner.ID, []uint64{created[0].ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+	marked, err = repo.MarkNotificationsRead(ctx, other.ID, []uint64{created[1].ID})
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked, "other users' notifications are left alone")
+
+	unread, err := repo.CountUnreadNotifications(ctx, owner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, unread)
+
+	page, total, err = repo.ListNotifications(ctx, owner.ID, true, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, page, 2)
+
+	marked, err = repo.MarkNotificationsRead(ctx, owner.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, marked)
+}
+
+func TestNotificationRepo_SetPreferenceReplacesState(t *testing.T) {
+	repo := NewNotificationRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	require.NoError(t, repo.SetNotificationPreference(ctx, &models.NotificationPreference{UserID: 7, Channel: models.ChannelEmail, Enabled: false}))
+	require.NoError(t, repo.SetNotificationPreference(ctx, &models.NotificationPreference{UserID: 7, Channel: models.ChannelEmail, Enabled: true}))
+
+	preferences, err := repo.ListNotificationPreferences(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, preferences, 1)
+	assert.True(t, preferences[0].Enabled)
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OAuthRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type OAuthRepoInterface interface {
+	CreateClient(ctx context.Context, client *models.OAuthClient) error
+	ListClients(ctx context.Context) ([]models.OAuthClient, error)
+	// GetClient finds a client by its public client ID
+	GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	// DeleteClient deletes the client with its codes and consents
+	DeleteClient(ctx context.Context, id uint) error
+	CreateCode(ctx context.Context, code *models.OAuthCode) error
+	// TakeCode deletes the code with the SHA-256 codeHash and returns it, so it is exchanged only once; ErrNotFound
+	// when there is none
+	TakeCode(ctx context.Context, codeHash string) (*models.OAuthCode, error)
+	// DeleteExpiredCodes deletes up to limit codes that expired before
+	DeleteExpiredCodes(ctx context.Context, before time.Time, limit int) (int, error)
+	// GetConsent returns ErrNotFound when the user never approved the client
+	GetConsent(ctx context.Context, userID, clientID uint) (*models.OAuthConsent, error)
+	// SaveConsent creates the consent or replaces its scope
+	SaveConsent(ctx context.Context, consent *models.OAuthConsent) error
+	// ListConsents returns the consents of the user with their clients, latest first
+	ListConsents(ctx context.Context, userID uint) ([]models.OAuthConsent, error)
+	DeleteConsent(ctx context.Context, userID, clientID uint) error
+}
+
+func NewOAuthRepo(db *gorm.DB, logger *zap.SugaredLogger) *OAuthRepo {
+	return &OAuthRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *OAuthRepo) CreateClient(ctx context.Context, client *models.OAuthClient) error {
+	result := writer(ctx, repo.db).Create(client)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *OAuthRepo) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	result := reader(ctx, repo.db).Order("id").Find(&clients)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return clients, nil
+}
+
+func (repo *OAuthRepo) GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{}
+	result := reader(ctx, repo.db).Where("client_id = ?", clientID).First(client)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("OAuth client not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return client, nil
+}
+
+func (repo *OAuthRepo) DeleteClient(ctx context.Context, id uint) error {
+	// The codes and consents go first; SQLite does not cascade without foreign keys switched on
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("client_id = ?", id).Delete(&models.OAuthCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("client_id = ?", id).Delete(&models.OAuthConsent{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.OAuthClient{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound.AppendMessage("OAuth client not found.")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		repo.logger.Error(err)
+	}
+	return translateError(err, &apperrors.DeletionFailedErr)
+}
+
+func (repo *OAuthRepo) CreateCode(ctx context.Context, code *models.OAuthCode) error {
+	result := writer(ctx, repo.db).Create(code)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *OAuthRepo) TakeCode(ctx context.Context, codeHash string) (*models.OAuthCode, error) {
+	code := &models.OAuthCode{}
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code_hash = ?", codeHash).First(code)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrNotFound.AppendMessage("Authorization code not found.")
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		// Of two exchanges at once only one deletes the row
+		result = tx.Delete(&models.OAuthCode{}, code.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound.AppendMessage("Authorization code not found.")
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			repo.logger.Error(err)
+		}
+		return nil, translateError(err, &apperrors.DeletionFailedErr)
+	}
+	return code, nil
+}
+
+func (repo *OAuthRepo) DeleteExpiredCodes(ctx context.Context, before time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.OAuthCode{}).Select("id").
+		Where("expires_at < ?", before).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.OAuthCode{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (repo *OAuthRepo) GetConsent(ctx context.Context, userID, clientID uint) (*models.OAuthConsent, error) {
+	consent := &models.OAuthConsent{}
+	result := reader(ctx, repo.db).Where("user_id = ? AND client_id = ?", userID, clientID).First(consent)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Consent not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return consent, nil
+}
+
+func (repo *OAuthRepo) SaveConsent(ctx context.Context, consent *models.OAuthConsent) error {
+	result := writer(ctx, repo.db).Omit("Client").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scope", "updated_at"}),
+	}).Create(consent)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *OAuthRepo) ListConsents(ctx context.Context, userID uint) ([]models.OAuthConsent, error) {
+	var consents []models.OAuthConsent
+	result := reader(ctx, repo.db).Preload("Client").Where("user_id = ?", userID).Order("updated_at DESC").Find(&consents)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return consents, nil
+}
+
+func (repo *OAuthRepo) DeleteConsent(ctx context.Context, userID, clientID uint) error {
+	result := writer(ctx, repo.db).Where("user_id = ? AND client_id = ?", userID, clientID).Delete(&models.OAuthConsent{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Consent not found.")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestOAuthRepo_CodesAreTakenOnce(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewOAuthRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	user := createTestUser(t, NewUserRepo(db, zaptest.NewLogger(t).Sugar()), "alice@example.com")
+
+	client := &models.OAuthClient{ClientID: "app", Name: "App", RedirectURIs: []string{"https://app.example.com/cb"}, Scopes: []string{models.ScopeProfile}}
+	require.NoError(t, repo.CreateClient(ctx, client))
+	found, err := repo.GetClient(ctx, "app")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com/cb"}, found.RedirectURIs)
+
+	now := time.Now()
+	require.NoError(t, repo.CreateCode(ctx, &models.OAuthCode{CodeHash: "fresh", ClientID: client.ID, UserID: user.ID, Scope: "profile", ExpiresAt: now.Add(time.Minute)}))
+	require.NoError(t, repo.CreateCode(ctx, &models.OAuthCode{CodeHash: "stale", ClientID: client.ID, UserID: user.ID, ExpiresAt: now.Add(-time.Minute)}))
+
+	code, err := repo.TakeCode(ctx, "fresh")
+	require.NoError(t, err)
+	assert.Equal(t, "profile", code.Scope)
+	_, err = repo.TakeCode(ctx, "fresh")
+	assert.True(t, errors.Is(err, ErrNotFound), "a code is exchanged once, got %v", err)
+
+	deleted, err := repo.DeleteExpiredCodes(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestOAuthRepo_Consents(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewOAuthRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	user := createTestUser(t, NewUserRepo(db, zaptest.NewLogger(t).Sugar()), "alice@example.com")
+	client := &models.OAuthClient{ClientID: "app", Name: "App"}
+	require.NoError(t, repo.CreateClient(ctx, client))
+
+	require.NoError(t, repo.SaveConsent(ctx, &models.OAuthConsent{UserID: user.ID, ClientID: client.ID, Scope: "profile"}))
+	require.NoError(t, repo.SaveConsent(ctx, &models.OAuthConsent{UserID: user.ID, ClientID: client.ID, Scope: "profile notifications"}))
+	consent, err := repo.GetConsent(ctx, user.ID, client.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "profile notifications", consent.Scope, "saving again replaces the scope")
+
+	consents, err := repo.ListConsents(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, consents, 1)
+	assert.Equal(t, "App", consents[0].Client.Name)
+
+	require.NoError(t, repo.DeleteClient(ctx, client.ID))
+	_, err = repo.GetConsent(ctx, user.ID, client.ID)
+	assert.True(t, errors.Is(err, ErrNotFound), "deleting the client drops its consents, got %v", err)
+	assert.True(t, errors.Is(repo.DeleteConsent(ctx, user.ID, client.ID), ErrNotFound))
+}
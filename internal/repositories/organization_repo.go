@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type OrganizationRepoInterface interface {
+	CreateOrganization(ctx context.Context, organization *models.Organization) error
+	// GetOrganization returns ErrNotFound when there is no such organization in the tenant
+	GetOrganization(ctx context.Context, id uint) (*models.Organization, error)
+	// LockOrganization is GetOrganization locking the row for the rest of the transaction, so changes to its
+	// members are serialized
+	LockOrganization(ctx context.Context, id uint) (*models.Organization, error)
+	// ListMemberships returns the organizations of the user with the user's role in each, oldest first
+	ListMemberships(ctx context.Context, userID uint) ([]models.Membership, error)
+	// ListMembers returns the members of the organization with their profiles, oldest first
+	ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error)
+	// GetMembership returns ErrNotFound when the user is not a member of the organization
+	GetMembership(ctx context.Context, organizationID, userID uint) (*models.Membership, error)
+	// AddMember returns ErrDuplicate when the user already is a member
+	AddMember(ctx context.Context, membership *models.Membership) error
+	// SetMemberRole and RemoveMember return ErrNotFound when the user is not a member
+	SetMemberRole(ctx context.Context, organizationID, userID uint, role string) error
+	RemoveMember(ctx context.Context, organizationID, userID uint) error
+	// CountMembersWithRole counts the members of the organization that have role
+	CountMembersWithRole(ctx context.Context, organizationID uint, role string) (int, error)
+}
+
+func NewOrganizationRepo(db *gorm.DB, logger *zap.SugaredLogger) *OrganizationRepo {
+	return &OrganizationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *OrganizationRepo) CreateOrganization(ctx context.Context, organization *models.Organization) error {
+	result := writer(ctx, repo.db).Create(organization)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *OrganizationRepo) GetOrganization(ctx context.Context, id uint) (*models.Organization, error) {
+	return repo.first(reader(ctx, repo.db), id)
+}
+
+func (repo *OrganizationRepo) LockOrganization(ctx context.Context, id uint) (*models.Organization, error) {
+	return repo.first(writer(ctx, repo.db).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (repo *OrganizationRepo) first(query *gorm.DB, id uint) (*models.Organization, error) {
+	organization := &models.Organization{}
+	result := query.First(organization, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Organization not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return organization, nil
+}
+
+func (repo *OrganizationRepo) ListMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	var memberships []models.Membership
+	result := reader(ctx, repo.db).Joins("Organization").
+		Where("memberships.user_id = ?", userID).
+		Order("memberships.created_at, memberships.organization_id").
+		Find(&memberships)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return memberships, nil
+}
+
+func (repo *OrganizationRepo) ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error) {
+	var memberships []models.Membership
+	result := reader(ctx, repo.db).Preload("User.Role").
+		Where("organization_id = ?", organizationID).
+		Order("created_at, user_id").
+		Find(&memberships)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return memberships, nil
+}
+
+func (repo *OrganizationRepo) GetMembership(ctx context.Context, organizationID, userID uint) (*models.Membership, error) {
+	membership := &models.Membership{}
+	result := reader(ctx, repo.db).Where("organization_id = ? AND user_id = ?", organizationID, userID).First(membership)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Membership not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return membership, nil
+}
+
+func (repo *OrganizationRepo) AddMember(ctx context.Context, membership *models.Membership) error {
+	result := writer(ctx, repo.db).Omit(clause.Associations).Create(membership)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *OrganizationRepo) SetMemberRole(ctx context.Context, organizationID, userID uint, role string) error {
+	result := writer(ctx, repo.db).Model(&models.Membership{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Membership not found.")
+	}
+	return nil
+}
+
+func (repo *OrganizationRepo) RemoveMember(ctx context.Context, organizationID, userID uint) error {
+	result := writer(ctx, repo.db).Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&models.Membership{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Membership not found.")
+	}
+	return nil
+}
+
+func (repo *OrganizationRepo) CountMembersWithRole(ctx context.Context, organizationID uint, role string) (int, error) {
+	var count int64
+	result := reader(ctx, repo.db).Model(&models.Membership{}).
+		Where("organization_id = ? AND role = ?", organizationID, role).
+		Count(&count)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return int(count), nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestOrganizationRepo_Members(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewOrganizationRepo(db, logger)
+	ctx := context.Background()
+	owner := createTestUser(t, NewUserRepo(db, logger), "owner@example.com")
+	member := createTestUser(t, NewUserRepo(db, logger), "member@example.com")
+
+	first := &models.Organization{Name: "Acme", CreatedBy: owner.ID}
+	second := &models.Organization{Name: "Globex", CreatedBy: member.ID}
+	require.NoError(t, repo.CreateOrganization(ctx, first))
+	require.NoError(t, repo.CreateOrganization(ctx, second))
+	require.NoError(t, repo.AddMember(ctx, &models.Membership{OrganizationID: first.ID, UserID: owner.ID, Role: models.StrAdmin}))
+	require.NoError(t, repo.AddMember(ctx, &models.Membership{OrganizationID: first.ID, UserID: member.ID, Role: models.StrUser}))
+	require.NoError(t, repo.AddMember(ctx, &models.Membership{OrganizationID: second.ID, UserID: member.ID, Role: models.StrAdmin}))
+	err := repo.AddMember(ctx, &models.Membership{OrganizationID: first.ID, UserID: member.ID, Role: models.StrAdmin})
+	assert.True(t, errors.Is(err, ErrDuplicate), "a user is a member once, got %v", err)
+
+	memberships, err := repo.ListMemberships(ctx, member.ID)
+	require.NoError(t, err)
+	require.Len(t, memberships, 2, "a user belongs to several organizations")
+	assert.Equal(t, "Acme", memberships[0].Organization.Name)
+	assert.Equal(t, models.StrUser, memberships[0].Role)
+	assert.Equal(t, "Globex", memberships[1].Organization.Name)
+	assert.Equal(t, models.StrAdmin, memberships[1].Role)
+
+	members, err := repo.ListMembers(ctx, first.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "owner@example.com", members[0].User.Email)
+
+	require.NoError(t, repo.SetMemberRole(ctx, first.ID, member.ID, models.StrAdmin))
+	admins, err := repo.CountMembersWithRole(ctx, first.ID, models.StrAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, 2, admins)
+
+	require.NoError(t, repo.RemoveMember(ctx, first.ID, member.ID))
+	_, err = repo.GetMembership(ctx, first.ID, member.ID)
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+	assert.True(t, errors.Is(repo.RemoveMember(ctx, first.ID, member.ID), ErrNotFound))
+	assert.True(t, errors.Is(repo.SetMemberRole(ctx, first.ID, member.ID, models.StrUser), ErrNotFound))
+
+	_, err = repo.GetOrganization(ctx, 999)
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+}
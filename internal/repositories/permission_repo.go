@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PermissionRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type PermissionRepoInterface interface {
+	// ListPermissions returns every permission ordered by name
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	// GetPermission returns ErrNotFound when there is no permission with the ID
+	GetPermission(ctx context.Context, id uint) (*models.Permission, error)
+	// CreatePermission returns ErrDuplicate when the name is taken
+	CreatePermission(ctx context.Context, permission *models.Permission) error
+	// UpdatePermission renames and redescribes the permission, returning ErrNotFound or ErrDuplicate
+	UpdatePermission(ctx context.Context, permission *models.Permission) error
+	// DeletePermission removes the permission and its grants, returning ErrNotFound when there is none
+	DeletePermission(ctx context.Context, id uint) error
+	// ListRolePermissions returns the grants of every role with their permissions
+	ListRolePermissions(ctx context.Context) ([]models.RolePermission, error)
+	// GrantPermission grants the permission to the role and reports false when the role already had it
+	GrantPermission(ctx context.Context, roleID, permissionID uint) (bool, error)
+	// RevokePermission returns ErrNotFound when the role does not have the permission
+	RevokePermission(ctx context.Context, roleID, permissionID uint) error
+	CreateAudit(ctx context.Context, entry *models.PermissionAudit) error
+	// ListAudit returns up to limit entries older than beforeID (0 for the newest), newest first
+	ListAudit(ctx context.Context, beforeID uint, limit int) ([]models.PermissionAudit, error)
+}
+
+func NewPermissionRepo(db *gorm.DB, logger *zap.SugaredLogger) *PermissionRepo {
+	return &PermissionRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *PermissionRepo) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	var permissions []models.Permission
+	result := reader(ctx, repo.db).Order("name").Find(&permissions)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return permissions, nil
+}
+
+func (repo *PermissionRepo) GetPermission(ctx context.Context, id uint) (*models.Permission, error) {
+	permission := &models.Permission{}
+	result := reader(ctx, repo.db).First(permission, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Permission not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return permission, nil
+}
+
+func (repo *PermissionRepo) CreatePermission(ctx context.Context, permission *models.Permission) error {
+	result := writer(ctx, repo.db).Create(permission)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *PermissionRepo) UpdatePermission(ctx context.Context, permission *models.Permission) error {
+	result := writer(ctx, repo.db).Model(permission).Select("name", "description", "updated_at").Updates(permission)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Permission not found.")
+	}
+	return nil
+}
+
+func (repo *PermissionRepo) DeletePermission(ctx context.Context, id uint) error {
+	// The grants go first; SQLite does not cascade without foreign keys switched on
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Permission{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound.AppendMessage("Permission not found.")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		repo.logger.Error(err)
+	}
+	return translateError(err, &apperrors.DeletionFailedErr)
+}
+
+func (repo *PermissionRepo) ListRolePermissions(ctx context.Context) ([]models.RolePermission, error) {
+	var grants []models.RolePermission
+	result := reader(ctx, repo.db).Preload("Permission").Order("role_id, permission_id").Find(&grants)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return grants, nil
+}
+
+func (repo *PermissionRepo) GrantPermission(ctx context.Context, roleID, permissionID uint) (bool, error) {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RolePermission{RoleID: roleID, PermissionID: permissionID})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *PermissionRepo) RevokePermission(ctx context.Context, roleID, permissionID uint) error {
+	result := writer(ctx, repo.db).Where("role_id = ? AND permission_id = ?", roleID, permissionID).Delete(&models.RolePermission{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("The role does not have the permission.")
+	}
+	return nil
+}
+
+func (repo *PermissionRepo) CreateAudit(ctx context.Context, entry *models.PermissionAudit) error {
+	result := writer(ctx, repo.db).Create(entry)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *PermissionRepo) ListAudit(ctx context.Context, beforeID uint, limit int) ([]models.PermissionAudit, error) {
+	query := reader(ctx, repo.db).Order("id DESC").Limit(limit)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	var entries []models.PermissionAudit
+	result := query.Find(&entries)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return entries, nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPermissionRepo_Grants(t *testing.T) {
+	repo := NewPermissionRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	deleteUsers := &models.Permission{Name: "users.delete"}
+	readUsers := &models.Permission{Name: "users.read"}
+	require.NoError(t, repo.CreatePermission(ctx, deleteUsers))
+	require.NoError(t, repo.CreatePermission(ctx, readUsers))
+	err := repo.CreatePermission(ctx, &models.Permission{Name: "users.read"})
+	assert.True(t, errors.Is(err, ErrDuplicate), "got %v", err)
+
+	granted, err := repo.GrantPermission(ctx, 3, deleteUsers.ID)
+	require.NoError(t, err)
+	assert.True(t, granted)
+	granted, err = repo.GrantPermission(ctx, 3, deleteUsers.ID)
+	require.NoError(t, err)
+	assert.False(t, granted, "the role already has it")
+	_, err = repo.GrantPermission(ctx, 1, readUsers.ID)
+	require.NoError(t, err)
+
+	grants, err := repo.ListRolePermissions(ctx)
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, uint(1), grants[0].RoleID)
+	assert.Equal(t, "users.read", grants[0].Permission.Name)
+
+	require.NoError(t, repo.RevokePermission(ctx, 1, readUsers.ID))
+	err = repo.RevokePermission(ctx, 1, readUsers.ID)
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+
+	// Deleting a permission takes it from the roles
+	require.NoError(t, repo.DeletePermission(ctx, deleteUsers.ID))
+	grants, err = repo.ListRolePermissions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+	err = repo.DeletePermission(ctx, deleteUsers.ID)
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+}
+
+func TestPermissionRepo_UpdatePermission(t *testing.T) {
+	repo := NewPermissionRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	permission := &models.Permission{Name: "users.read"}
+	require.NoError(t, repo.CreatePermission(ctx, permission))
+	require.NoError(t, repo.CreatePermission(ctx, &models.Permission{Name: "users.write"}))
+
+	require.NoError(t, repo.UpdatePermission(ctx, &models.Permission{ID: permission.ID, Name: "users.view", Description: "See profiles"}))
+	updated, err := repo.GetPermission(ctx, permission.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "users.view", updated.Name)
+	assert.Equal(t, "See profiles", updated.Description)
+
+	err = repo.UpdatePermission(ctx, &models.Permission{ID: permission.ID, Name: "users.write"})
+	assert.True(t, errors.Is(err, ErrDuplicate), "got %v", err)
+	err = repo.UpdatePermission(ctx, &models.Permission{ID: 99, Name: "users.edit"})
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+}
+
+func TestPermissionRepo_ListAuditPages(t *testing.T) {
+	repo := NewPermissionRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for _, action := range []string{models.PermissionAuditCreated, models.PermissionAuditGranted, models.PermissionAuditRevoked} {
+		require.NoError(t, repo.CreateAudit(ctx, &models.PermissionAudit{ActorID: 1, Action: action, PermissionID: 1, PermissionName: "users.read"}))
+	}
+
+	page, err := repo.ListAudit(ctx, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, models.PermissionAuditRevoked, page[0].Action, "newest first")
+
+	page, err = repo.ListAudit(ctx, page[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, models.PermissionAuditCreated, page[0].Action)
+}
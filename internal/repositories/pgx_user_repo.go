@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+const pgxUserColumns = `u.id, u.tenant_id, u.email, COALESCE(u.email_index, ''), u.first_name, u.last_name, u.password, u.role_id,
+	u.created_at, u.updated_at, u.vote_updated_at, u.deleted_at, u.rating, u.timezone, u.locale, u.service_account, r.id, r.name`
+
+const pgxUserFrom = ` FROM users u LEFT JOIN roles r ON r.id = u.role_id`
+
+// GORM stores the zero time for users that are not deleted, SQL migrations leave NULL
+const pgxNotDeleted = ` (u.deleted_at IS NULL OR u.deleted_at = $1)`
+
+// Lists and counts leave out shadow-banned users, as UserRepo does
+const pgxNotShadowBanned = ` AND u.id NOT IN (` + models.ShadowBannedUsers + `)`
+
+// PgxUserRepo serves the hot paths (lookups, listing, counting and registration) with hand-written SQL
+// over a pgx pool. Everything else, and every call made inside a TxManager transaction, goes to the
+// embedded GORM repository: a pgx connection cannot join the GORM transaction carried by the context.
+type PgxUserRepo struct {
+	*UserRepo
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewPgxUserRepo(pool *pgxpool.Pool, gormRepo *UserRepo, logger *zap.SugaredLogger) *PgxUserRepo {
+	return &PgxUserRepo{
+		UserRepo: gormRepo,
+		pool:     pool,
+		logger:   logger,
+	}
+}
+
+func inTransaction(ctx context.Context) bool {
+	_, ok := txFromContext(ctx)
+	return ok
+}
+
+func (repo *PgxUserRepo) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	if inTransaction(ctx) {
+		return repo.UserRepo.CreateUser(ctx, user)
+	}
+
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	if user.TenantID == 0 {
+		user.TenantID = tenancy.DefaultTenantID
+		if tenantID, ok := tenancy.FromContext(ctx); ok {
+			user.TenantID = tenantID
+		}
+	}
+	user.Email = models.NormalizeEmail(user.Email)
+	user.EmailIndex = pii.BlindIndex(user.Email)
+
+	// Same encryption the GORM serializer applies to these columns
+	encrypted := make([]string, 3)
+	for i, plaintext := range []string{user.Email, user.FirstName, user.LastName} {
+		var err error
+		encrypted[i], err = pii.Encrypt(plaintext)
+		if err != nil {
+			repo.logger.Error(err)
+			return nil, translateError(err, &apperrors.InsertionFailedErr)
+		}
+	}
+
+	// The change feed outbox entry the GORM hooks would write
+	err := repo.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `INSERT INTO users
+			(tenant_id, email, email_index, first_name, last_name, password, role_id, created_at, updated_at, vote_updated_at, deleted_at, rating, timezone, locale, service_account)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`,
+			user.TenantID, encrypted[0], user.EmailIndex, encrypted[1], encrypted[2], user.Password, user.RoleID,
+			user.CreatedAt, user.UpdatedAt, user.VoteUpdatedAt, user.DeletedAt, user.Rating, user.Timezone, user.Locale,
+			user.ServiceAccount,
+		).Scan(&user.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO user_change_outbox (tenant_id, user_id, operation, changed_at) VALUES ($1, $2, $3, $4)`,
+			user.TenantID, user.ID, models.ChangeOperationCreate, now)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateEmail.AppendMessage(user.Email)
+		}
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.InsertionFailedErr)
+	}
+
+	return user, nil
+}
+
+func (repo *PgxUserRepo) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if inTransaction(ctx) {
+		return repo.UserRepo.GetUser(ctx, userID)
+	}
+
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return nil, ErrNotFound.AppendMessage("User not found.")
+	}
+
+	tenantFilter, args := pgxTenantFilter(ctx, time.Time{}, id)
+	user, err := scanPgxUser(repo.pool.QueryRow(ctx,
+		`SELECT `+pgxUserColumns+pgxUserFrom+` WHERE`+pgxNotDeleted+` AND u.id = $2`+tenantFilter, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.AppendMessage("User not found.")
+		}
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.QueryFailedErr)
+	}
+	return user, nil
+}
+
+func (repo *PgxUserRepo) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	if inTransaction(ctx) {
+		return repo.UserRepo.GetUserByID(ctx, userID)
+	}
+
+	// Like the GORM repository, lookups by numeric id also see deleted users
+	tenantFilter, args := pgxTenantFilter(ctx, userID)
+	user, err := scanPgxUser(repo.pool.QueryRow(ctx,
+		`SELECT `+pgxUserColumns+pgxUserFrom+` WHERE u.id = $1`+tenantFilter, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.AppendMessage("User not found.")
+		}
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.QueryFailedErr)
+	}
+	return user, nil
+}
+
+func (repo *PgxUserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if inTransaction(ctx) {
+		return repo.UserRepo.GetUserByEmail(ctx, email)
+	}
+
+	tenantFilter, args := pgxTenantFilter(ctx, time.Time{}, models.EmailBlindIndex(email))
+	user, err := scanPgxUser(repo.pool.QueryRow(ctx,
+		`SELECT `+pgxUserColumns+pgxUserFrom+` WHERE`+pgxNotDeleted+` AND u.email_index = $2`+tenantFilter, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.AppendMessage("User not found.")
+		}
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.QueryFailedErr)
+	}
+	return user, nil
+}
+
+func (repo *PgxUserRepo) ListUsers(ctx context.Context, page int, pageSize int) ([]models.User, error) {
+	users, _, err := repo.ListUsersWithTotal(ctx, page, pageSize, nil)
+	return users, err
+}
+
+func (repo *PgxUserRepo) ListUsersWithTotal(ctx context.Context, page int, pageSize int, filters []filter.Condition) ([]models.User, int, error) {
+	// Filtered lists are rarer than the plain one and go through the query builder of UserRepo
+	if inTransaction(ctx) || len(filters) > 0 {
+		return repo.UserRepo.ListUsersWithTotal(ctx, page, pageSize, filters)
+	}
+
+	tenantFilter, args := pgxTenantFilter(ctx, time.Time{})
+	args = append(args, pageSize, (page-1)*pageSize)
+	rows, err := repo.pool.Query(ctx,
+		`SELECT `+pgxUserColumns+`, COUNT(*) OVER()`+pgxUserFrom+` WHERE`+pgxNotDeleted+pgxNotShadowBanned+tenantFilter+
+			fmt.Sprintf(` ORDER BY u.id LIMIT $%d OFFSET $%d`, len(args)-1, len(args)),
+		args...)
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, 0, translateError(err, &apperrors.QueryFailedErr)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	total := 0
+	for rows.Next() {
+		user, err := scanPgxUser(rows, &total)
+		if err != nil {
+			repo.logger.Error(err)
+			return nil, 0, translateError(err, &apperrors.QueryFailedErr)
+		}
+		users = append(users, *user)
+	}
+	if err = rows.Err(); err != nil {
+		repo.logger.Error(err)
+		return nil, 0, translateError(err, &apperrors.QueryFailedErr)
+	}
+
+	// A page past the end has no rows to carry the total, so count separately
+	if len(users) == 0 {
+		total, err = repo.CountUsers(ctx, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return users, total, nil
+}
+
+func (repo *PgxUserRepo) CountUsers(ctx context.Context, filters []filter.Condition) (int, error) {
+	if inTransaction(ctx) || len(filters) > 0 {
+		return repo.UserRepo.CountUsers(ctx, filters)
+	}
+
+	var count int
+	tenantFilter, args := pgxTenantFilter(ctx, time.Time{})
+	err := repo.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users u WHERE`+pgxNotDeleted+pgxNotShadowBanned+tenantFilter, args...).Scan(&count)
+	if err != nil {
+		repo.logger.Error(err)
+		return 0, translateError(err, &apperrors.QueryFailedErr)
+	}
+	return count, nil
+}
+
+// pgxTenantFilter appends the tenant of ctx to args and returns the matching condition, mirroring
+// the GORM tenancy plugin; both are empty additions when ctx carries no tenant
+func pgxTenantFilter(ctx context.Context, args ...interface{}) (string, []interface{}) {
+	tenantID, ok := tenancy.FromContext(ctx)
+	if !ok {
+		return "", args
+	}
+	args = append(args, tenantID)
+	return fmt.Sprintf(" AND u.tenant_id = $%d", len(args)), args
+}
+
+// scanPgxUser reads one row selected with pgxUserColumns; extra destinations follow the user columns
+func scanPgxUser(row pgx.Row, extra ...interface{}) (*models.User, error) {
+	var (
+		user      models.User
+		roleFK    *uint
+		deletedAt *time.Time
+		roleID    *uint
+		roleName  *string
+	)
+
+	dest := []interface{}{
+		&user.ID, &user.TenantID, &user.Email, &user.EmailIndex, &user.FirstName, &user.LastName, &user.Password, &roleFK,
+		&user.CreatedAt, &user.UpdatedAt, &user.VoteUpdatedAt, &deletedAt, &user.Rating, &user.Timezone, &user.Locale,
+		&user.ServiceAccount, &roleID, &roleName,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []*string{&user.Email, &user.FirstName, &user.LastName} {
+		*field, err = pii.Decrypt(*field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if roleFK != nil {
+		user.RoleID = *roleFK
+	}
+	if deletedAt != nil {
+		user.DeletedAt = *deletedAt
+	}
+	if roleID != nil && roleName != nil {
+		user.Role = models.Role{ID: *roleID, Name: *roleName}
+	}
+	return &user, nil
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PhoneRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type PhoneRepoInterface interface {
+	// SavePhone replaces the phone of phone.UserID, unverified and without second factor, and deletes the codes
+	// sent to the old one
+	SavePhone(ctx context.Context, phone *models.Phone) error
+	GetPhone(ctx context.Context, userID uint) (*models.Phone, error)
+	// GetVerifiedPhone finds the phone verified with number
+	GetVerifiedPhone(ctx context.Context, number string) (*models.Phone, error)
+	// DeletePhone deletes the phone of the user with its codes
+	DeletePhone(ctx context.Context, userID uint) error
+	// VerifyPhone marks the phone of the user verified at at; ErrDuplicate when another user verified the number
+	VerifyPhone(ctx context.Context, userID uint, at time.Time) error
+	SetSecondFactor(ctx context.Context, userID uint, enabled bool) error
+	// SaveCode stores code, replacing the code the user had for its purpose
+	SaveCode(ctx context.Context, code *models.PhoneCode) error
+	GetCode(ctx context.Context, userID uint, purpose string) (*models.PhoneCode, error)
+	// GetCodeByChallenge finds a second factor code by the SHA-256 of its challenge
+	GetCodeByChallenge(ctx context.Context, challengeHash string) (*models.PhoneCode, error)
+	// CountAttempt counts a guess of the code unless max guesses were counted already, and returns whether it did
+	CountAttempt(ctx context.Context, id uint64, max int) (bool, error)
+	DeleteCode(ctx context.Context, id uint64) error
+	// DeleteExpiredCodes deletes up to limit codes expired at now
+	DeleteExpiredCodes(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+func NewPhoneRepo(db *gorm.DB, logger *zap.SugaredLogger) *PhoneRepo {
+	return &PhoneRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *PhoneRepo) SavePhone(ctx context.Context, phone *models.Phone) error {
+	phone.VerifiedAt, phone.SecondFactor = nil, false
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"number", "number_index", "verified_at", "second_factor", "updated_at"}),
+		}).Create(phone).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", phone.UserID).Delete(&models.PhoneCode{}).Error
+	})
+	if err != nil {
+		repo.logger.Error(err)
+		return translateError(err, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *PhoneRepo) GetPhone(ctx context.Context, userID uint) (*models.Phone, error) {
+	phone := &models.Phone{}
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).First(phone)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Phone not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return phone, nil
+}
+
+func (repo *PhoneRepo) GetVerifiedPhone(ctx context.Context, number string) (*models.Phone, error) {
+	phone := &models.Phone{}
+	result := reader(ctx, repo.db).Where("number_index = ? AND verified_at IS NOT NULL", models.PhoneNumberBlindIndex(number)).First(phone)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Phone not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return phone, nil
+}
+
+func (repo *PhoneRepo) DeletePhone(ctx context.Context, userID uint) error {
+	// The codes go first; SQLite does not cascade without foreign keys switched on
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.PhoneCode{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id = ?", userID).Delete(&models.Phone{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound.AppendMessage("Phone not found.")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		repo.logger.Error(err)
+	}
+	return translateError(err, &apperrors.DeletionFailedErr)
+}
+
+func (repo *PhoneRepo) VerifyPhone(ctx context.Context, userID uint, at time.Time) error {
+	return repo.update(ctx, userID, map[string]interface{}{"verified_at": at, "updated_at": at})
+}
+
+func (repo *PhoneRepo) SetSecondFactor(ctx context.Context, userID uint, enabled bool) error {
+	return repo.update(ctx, userID, map[string]interface{}{"second_factor": enabled, "updated_at": time.Now()})
+}
+
+func (repo *PhoneRepo) update(ctx context.Context, userID uint, columns map[string]interface{}) error {
+	result := writer(ctx, repo.db).Model(&models.Phone{}).Where("user_id = ?", userID).Updates(columns)
+	if result.Error != nil {
+		if !isUniqueViolation(result.Error) {
+			repo.logger.Error(result.Error)
+		}
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound.AppendMessage("Phone not found.")
+	}
+	return nil
+}
+
+func (repo *PhoneRepo) SaveCode(ctx context.Context, code *models.PhoneCode) error {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "purpose"}},
+		DoUpdates: clause.AssignmentColumns([]string{"code_hash", "challenge_hash", "attempts", "expires_at", "created_at"}),
+	}).Create(code)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *PhoneRepo) GetCode(ctx context.Context, userID uint, purpose string) (*models.PhoneCode, error) {
+	return repo.firstCode(reader(ctx, repo.db).Where("user_id = ? AND purpose = ?", userID, purpose))
+}
+
+func (repo *PhoneRepo) GetCodeByChallenge(ctx context.Context, challengeHash string) (*models.PhoneCode, error) {
+	return repo.firstCode(reader(ctx, repo.db).Where("challenge_hash = ? AND purpose = ?", challengeHash, models.PhoneCodeSecondFactor))
+}
+
+func (repo *PhoneRepo) CountAttempt(ctx context.Context, id uint64, max int) (bool, error) {
+	// One statement, so guesses made at once cannot all read the same count and get past max
+	result := writer(ctx, repo.db).Model(&models.PhoneCode{}).Where("id = ? AND attempts < ?", id, max).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *PhoneRepo) DeleteCode(ctx context.Context, id uint64) error {
+	result := writer(ctx, repo.db).Delete(&models.PhoneCode{}, id)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return nil
+}
+
+func (repo *PhoneRepo) DeleteExpiredCodes(ctx context.Context, now time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.PhoneCode{}).Select("id").
+		Where("expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.PhoneCode{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (repo *PhoneRepo) firstCode(query *gorm.DB) (*models.PhoneCode, error) {
+	code := &models.PhoneCode{}
+	result := query.First(code)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Phone code not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return code, nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPhoneRepo_Verify(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewUserRepo(db, logger)
+	repo := NewPhoneRepo(db, logger)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	ann := createTestUser(t, users, "ann@example.com")
+	bob := createTestUser(t, users, "bob@example.com")
+
+	_, err := repo.GetPhone(ctx, ann.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.VerifyPhone(ctx, ann.ID, now), ErrNotFound)
+
+	require.NoError(t, repo.SavePhone(ctx, &models.Phone{UserID: ann.ID, Number: "+380501234567", UpdatedAt: now}))
+	require.NoError(t, repo.VerifyPhone(ctx, ann.ID, now))
+	require.NoError(t, repo.SetSecondFactor(ctx, ann.ID, true))
+	phone, err := repo.GetPhone(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "+380501234567", phone.Number)
+	require.NotNil(t, phone.VerifiedAt)
+	assert.True(t, phone.SecondFactor)
+
+	found, err := repo.GetVerifiedPhone(ctx, "+380501234567")
+	require.NoError(t, err)
+	assert.Equal(t, ann.ID, found.UserID)
+
+	// Another user may enter the number, but not verify it
+	require.NoError(t, repo.SavePhone(ctx, &models.Phone{UserID: bob.ID, Number: "+380501234567", UpdatedAt: now}))
+	assert.ErrorIs(t, repo.VerifyPhone(ctx, bob.ID, now), ErrDuplicate)
+
+	// Changing the number starts over
+	require.NoError(t, repo.SavePhone(ctx, &models.Phone{UserID: ann.ID, Number: "+380671234567", UpdatedAt: now}))
+	phone, err = repo.GetPhone(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Nil(t, phone.VerifiedAt)
+	assert.False(t, phone.SecondFactor)
+	_, err = repo.GetVerifiedPhone(ctx, "+380501234567")
+	assert.ErrorIs(t, err, ErrNotFound, "an unverified number is not found")
+	require.NoError(t, repo.VerifyPhone(ctx, bob.ID, now))
+	found, err = repo.GetVerifiedPhone(ctx, "+380501234567")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, found.UserID)
+
+	require.NoError(t, repo.DeletePhone(ctx, ann.ID))
+	assert.ErrorIs(t, repo.DeletePhone(ctx, ann.ID), ErrNotFound)
+}
+
+func TestPhoneRepo_Codes(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewUserRepo(db, logger)
+	repo := NewPhoneRepo(db, logger)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	ann := createTestUser(t, users, "ann@example.com")
+	require.NoError(t, repo.SavePhone(ctx, &models.Phone{UserID: ann.ID, Number: "+380501234567", UpdatedAt: now}))
+
+	require.NoError(t, repo.SaveCode(ctx, &models.PhoneCode{UserID: ann.ID, Purpose: models.PhoneCodeVerify, CodeHash: "old", ExpiresAt: now.Add(-time.Minute), CreatedAt: now}))
+	code, err := repo.GetCode(ctx, ann.ID, models.PhoneCodeVerify)
+	require.NoError(t, err)
+	counted, err := repo.CountAttempt(ctx, code.ID, 1)
+	require.NoError(t, err)
+	assert.True(t, counted)
+	counted, err = repo.CountAttempt(ctx, code.ID, 1)
+	require.NoError(t, err)
+	assert.False(t, counted, "no more than max guesses are counted")
+
+	// A new code replaces the old one and its attempts
+	require.NoError(t, repo.SaveCode(ctx, &models.PhoneCode{UserID: ann.ID, Purpose: models.PhoneCodeVerify, CodeHash: "new", ExpiresAt: now.Add(time.Minute), CreatedAt: now}))
+	code, err = repo.GetCode(ctx, ann.ID, models.PhoneCodeVerify)
+	require.NoError(t, err)
+	assert.Equal(t, "new", code.CodeHash)
+	assert.Equal(t, 0, code.Attempts)
+
+	require.NoError(t, repo.SaveCode(ctx, &models.PhoneCode{UserID: ann.ID, Purpose: models.PhoneCodeSecondFactor, CodeHash: "2fa", ChallengeHash: "challenge", ExpiresAt: now.Add(-time.Minute), CreatedAt: now}))
+	code, err = repo.GetCodeByChallenge(ctx, "challenge")
+	require.NoError(t, err)
+	assert.Equal(t, "2fa", code.CodeHash)
+	_, err = repo.GetCodeByChallenge(ctx, "other")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	deleted, err := repo.DeleteExpiredCodes(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = repo.GetCode(ctx, ann.ID, models.PhoneCodeVerify)
+	require.NoError(t, err)
+
+	// Saving the phone again drops the codes sent to it
+	require.NoError(t, repo.SavePhone(ctx, &models.Phone{UserID: ann.ID, Number: "+380501234567", UpdatedAt: now}))
+	_, err = repo.GetCode(ctx, ann.ID, models.PhoneCodeVerify)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
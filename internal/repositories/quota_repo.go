@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuotaRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+// QuotaRepoInterface reads and counts for the tenant in the context, like every tenant-scoped repository
+type QuotaRepoInterface interface {
+	// ListQuotas returns the limits set for the tenant
+	ListQuotas(ctx context.Context) ([]models.TenantQuota, error)
+	CountUsers(ctx context.Context) (int, error)
+	CountVotesSince(ctx context.Context, since time.Time) (int, error)
+	CountMembers(ctx context.Context, organizationID uint) (int, error)
+	// LargestOrganization returns the number of members of the tenant's largest organization
+	LargestOrganization(ctx context.Context) (int, error)
+	// IncrementRequests counts a request on day and returns the requests of the day so far
+	IncrementRequests(ctx context.Context, day string) (int, error)
+	CountRequests(ctx context.Context, day string) (int, error)
+}
+
+func NewQuotaRepo(db *gorm.DB, logger *zap.SugaredLogger) *QuotaRepo {
+	return &QuotaRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *QuotaRepo) ListQuotas(ctx context.Context) ([]models.TenantQuota, error) {
+	var quotas []models.TenantQuota
+	result := reader(ctx, repo.db).Order("name").Find(&quotas)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return quotas, nil
+}
+
+func (repo *QuotaRepo) CountUsers(ctx context.Context) (int, error) {
+	return repo.count(reader(ctx, repo.db).Model(&models.User{}).Where("deleted_at IS NULL OR deleted_at = ?", time.Time{}))
+}
+
+func (repo *QuotaRepo) CountVotesSince(ctx context.Context, since time.Time) (int, error) {
+	return repo.count(reader(ctx, repo.db).Model(&models.Vote{}).Where("created_at >= ?", since))
+}
+
+func (repo *QuotaRepo) CountMembers(ctx context.Context, organizationID uint) (int, error) {
+	return repo.count(reader(ctx, repo.db).Model(&models.Membership{}).Where("organization_id = ?", organizationID))
+}
+
+func (repo *QuotaRepo) LargestOrganization(ctx context.Context) (int, error) {
+	var largest int64
+	// Querying organizations scopes the count to the tenant
+	result := reader(ctx, repo.db).Model(&models.Organization{}).
+		Select("COUNT(memberships.user_id)").
+		Joins("JOIN memberships ON memberships.organization_id = organizations.id").
+		Group("organizations.id").
+		Order("1 DESC").
+		Limit(1).
+		Scan(&largest)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return int(largest), nil
+}
+
+func (repo *QuotaRepo) IncrementRequests(ctx context.Context, day string) (int, error) {
+	usage := &models.TenantUsage{Day: day, Requests: 1}
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"requests": gorm.Expr("tenant_usage.requests + 1")}),
+	}).Create(usage)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	return repo.CountRequests(ctx, day)
+}
+
+func (repo *QuotaRepo) CountRequests(ctx context.Context, day string) (int, error) {
+	var requests int64
+	// The primary has the count IncrementRequests just wrote
+	result := writer(ctx, repo.db).Model(&models.TenantUsage{}).Select("requests").Where("day = ?", day).Scan(&requests)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return int(requests), nil
+}
+
+func (repo *QuotaRepo) count(query *gorm.DB) (int, error) {
+	var count int64
+	result := query.Count(&count)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return int(count), nil
+}
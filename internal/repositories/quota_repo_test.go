@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQuotaRepo_Counts(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewQuotaRepo(db, logger)
+	ctx := tenancy.WithTenant(context.Background(), tenancy.DefaultTenantID)
+	users := NewUserRepo(db, logger)
+	alice := createTestUser(t, users, "alice@example.com")
+	bob := createTestUser(t, users, "bob@example.com")
+
+	count, err := repo.CountUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	require.NoError(t, db.Create(&models.Vote{UserID: alice.ID, ProfileID: bob.ID, Value: 1}).Error)
+	count, err = repo.CountVotesSince(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = repo.CountVotesSince(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	organizations := NewOrganizationRepo(db, logger)
+	small, large := &models.Organization{Name: "Small"}, &models.Organization{Name: "Large"}
+	require.NoError(t, organizations.CreateOrganization(ctx, small))
+	require.NoError(t, organizations.CreateOrganization(ctx, large))
+	require.NoError(t, organizations.AddMember(ctx, &models.Membership{OrganizationID: small.ID, UserID: alice.ID, Role: models.StrAdmin}))
+	require.NoError(t, organizations.AddMember(ctx, &models.Membership{OrganizationID: large.ID, UserID: alice.ID, Role: models.StrAdmin}))
+	require.NoError(t, organizations.AddMember(ctx, &models.Membership{OrganizationID: large.ID, UserID: bob.ID, Role: models.StrUser}))
+	count, err = repo.CountMembers(ctx, small.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = repo.LargestOrganization(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestQuotaRepo_RequestsAndLimitsArePerTenant(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewQuotaRepo(db, zaptest.NewLogger(t).Sugar())
+	require.NoError(t, db.Create(&models.Tenant{ID: 2, Name: "Other", Slug: "other"}).Error)
+	ctx := tenancy.WithTenant(context.Background(), tenancy.DefaultTenantID)
+	other := tenancy.WithTenant(context.Background(), 2)
+
+	for want := 1; want <= 3; want++ {
+		requests, err := repo.IncrementRequests(ctx, "2024-05-01")
+		require.NoError(t, err)
+		assert.Equal(t, want, requests)
+	}
+	requests, err := repo.IncrementRequests(other, "2024-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests, "tenants count apart")
+	requests, err = repo.CountRequests(ctx, "2024-05-02")
+	require.NoError(t, err)
+	assert.Zero(t, requests, "every day starts at zero")
+
+	require.NoError(t, db.WithContext(other).Create(&models.TenantQuota{Name: models.QuotaUsers, Limit: 5}).Error)
+	quotas, err := repo.ListQuotas(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, quotas)
+	quotas, err = repo.ListQuotas(other)
+	require.NoError(t, err)
+	require.Len(t, quotas, 1)
+	assert.Equal(t, 5, quotas[0].Limit)
+}
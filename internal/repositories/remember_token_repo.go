@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RememberTokenRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type RememberTokenRepoInterface interface {
+	CreateToken(ctx context.Context, token *models.RememberToken) error
+	// GetTokenByHash finds a token by its SHA-256, used or not
+	GetTokenByHash(ctx context.Context, tokenHash string) (*models.RememberToken, error)
+	// RotateToken marks used as used at at and creates next in its place. It returns false, creating nothing, when
+	// used was used already, e.g. by a concurrent request.
+	RotateToken(ctx context.Context, used *models.RememberToken, next *models.RememberToken, at time.Time) (bool, error)
+	// DeleteFamily deletes every token of the family
+	DeleteFamily(ctx context.Context, familyID string) error
+	// DeleteUserTokens deletes every token of the user, so no device of theirs is remembered any more
+	DeleteUserTokens(ctx context.Context, userID uint) error
+	// DeleteExpiredTokens deletes up to limit tokens expired at now and returns how many
+	DeleteExpiredTokens(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+func NewRememberTokenRepo(db *gorm.DB, logger *zap.SugaredLogger) *RememberTokenRepo {
+	return &RememberTokenRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *RememberTokenRepo) CreateToken(ctx context.Context, token *models.RememberToken) error {
+	result := writer(ctx, repo.db).Create(token)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *RememberTokenRepo) GetTokenByHash(ctx context.Context, tokenHash string) (*models.RememberToken, error) {
+	token := &models.RememberToken{}
+	result := reader(ctx, repo.db).Where("token_hash = ?", tokenHash).First(token)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Remember-me token not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return token, nil
+}
+
+func (repo *RememberTokenRepo) RotateToken(ctx context.Context, used *models.RememberToken, next *models.RememberToken, at time.Time) (bool, error) {
+	rotated := false
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		// Only one of two requests with the same token gets to mark it
+		result := tx.Model(&models.RememberToken{}).Where("id = ? AND used_at IS NULL", used.ID).Update("used_at", at)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		repo.logger.Error(err)
+		return false, translateError(err, &apperrors.UpdateFailedErr)
+	}
+	return rotated, nil
+}
+
+func (repo *RememberTokenRepo) DeleteFamily(ctx context.Context, familyID string) error {
+	result := writer(ctx, repo.db).Where("family_id = ?", familyID).Delete(&models.RememberToken{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return nil
+}
+
+func (repo *RememberTokenRepo) DeleteUserTokens(ctx context.Context, userID uint) error {
+	result := writer(ctx, repo.db).Where("user_id = ?", userID).Delete(&models.RememberToken{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return nil
+}
+
+func (repo *RememberTokenRepo) DeleteExpiredTokens(ctx context.Context, now time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.RememberToken{}).Select("id").
+		Where("expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.RememberToken{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
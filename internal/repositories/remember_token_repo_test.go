@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRememberTokenRepo_Rotate(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewUserRepo(db, logger)
+	repo := NewRememberTokenRepo(db, logger)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	ann := createTestUser(t, users, "ann@example.com")
+	first := &models.RememberToken{UserID: ann.ID, FamilyID: "family", TokenHash: "first", Fingerprint: "device", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, repo.CreateToken(ctx, first))
+
+	second := &models.RememberToken{UserID: ann.ID, FamilyID: "family", TokenHash: "second", Fingerprint: "device", ExpiresAt: now.Add(2 * time.Hour), CreatedAt: now}
+	rotated, err := repo.RotateToken(ctx, first, second, now)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	rotated, err = repo.RotateToken(ctx, first, &models.RememberToken{UserID: ann.ID, FamilyID: "family", TokenHash: "third", ExpiresAt: now.Add(2 * time.Hour)}, now)
+	require.NoError(t, err)
+	assert.False(t, rotated, "a used token is rotated once")
+	_, err = repo.GetTokenByHash(ctx, "third")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	used, err := repo.GetTokenByHash(ctx, "first")
+	require.NoError(t, err)
+	require.NotNil(t, used.UsedAt)
+
+	require.NoError(t, repo.CreateToken(ctx, &models.RememberToken{UserID: ann.ID, FamilyID: "other", TokenHash: "other", ExpiresAt: now.Add(-time.Minute), CreatedAt: now}))
+	deleted, err := repo.DeleteExpiredTokens(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	require.NoError(t, repo.DeleteFamily(ctx, "family"))
+	_, err = repo.GetTokenByHash(ctx, "second")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReportRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type ReportRepoInterface interface {
+	CreateReport(ctx context.Context, report *models.Report) error
+	// HasPendingReport returns whether the reporter has a pending report on the user
+	HasPendingReport(ctx context.Context, reporterID, userID uint) (bool, error)
+	// GetReport returns the report with the reported user
+	GetReport(ctx context.Context, id uint64) (*models.Report, error)
+	// ListReports returns a page of the reports matching the query with the reported users, oldest first, and how
+	// many there are
+	ListReports(ctx context.Context, query *models.ReportQuery, page, pageSize int) ([]models.Report, int, error)
+	// ResolveReport resolves the report if it is pending and returns whether it was
+	ResolveReport(ctx context.Context, id uint64, resolution *models.ReportResolution) (bool, error)
+	// ResolvePendingReports resolves every pending report on the user and returns how many there were
+	ResolvePendingReports(ctx context.Context, userID uint, resolution *models.ReportResolution) (int, error)
+}
+
+func NewReportRepo(db *gorm.DB, logger *zap.SugaredLogger) *ReportRepo {
+	return &ReportRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *ReportRepo) CreateReport(ctx context.Context, report *models.Report) error {
+	result := writer(ctx, repo.db).Omit("User").Create(report)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *ReportRepo) HasPendingReport(ctx context.Context, reporterID, userID uint) (bool, error) {
+	var count int64
+	result := reader(ctx, repo.db).Model(&models.Report{}).
+		Where("reporter_id = ? AND user_id = ? AND status = ?", reporterID, userID, models.ReportStatusPending).
+		Count(&count)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return count > 0, nil
+}
+
+func (repo *ReportRepo) GetReport(ctx context.Context, id uint64) (*models.Report, error) {
+	report := &models.Report{}
+	result := reader(ctx, repo.db).Preload("User.Role").Where("id = ?", id).First(report)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Report not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return report, nil
+}
+
+func (repo *ReportRepo) ListReports(ctx context.Context, query *models.ReportQuery, page, pageSize int) ([]models.Report, int, error) {
+	tx := reader(ctx, repo.db).Model(&models.Report{})
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	if query.Reason != "" {
+		tx = tx.Where("reason = ?", query.Reason)
+	}
+	if query.UserID > 0 {
+		tx = tx.Where("user_id = ?", query.UserID)
+	}
+	tx = tx.Session(&gorm.Session{})
+
+	var total int64
+	result := tx.Count(&total)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	var reports []models.Report
+	result = tx.Preload("User.Role").
+		Order("created_at, id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&reports)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return reports, int(total), nil
+}
+
+func (repo *ReportRepo) ResolveReport(ctx context.Context, id uint64, resolution *models.ReportResolution) (bool, error) {
+	affected, err := repo.resolve(writer(ctx, repo.db).Where("id = ?", id), resolution)
+	return affected > 0, err
+}
+
+func (repo *ReportRepo) ResolvePendingReports(ctx context.Context, userID uint, resolution *models.ReportResolution) (int, error) {
+	return repo.resolve(writer(ctx, repo.db).Where("user_id = ?", userID), resolution)
+}
+
+// resolve resolves the pending reports the query selects
+func (repo *ReportRepo) resolve(tx *gorm.DB, resolution *models.ReportResolution) (int, error) {
+	result := tx.Model(&models.Report{}).
+		Where("status = ?", models.ReportStatusPending).
+		Updates(map[string]interface{}{
+			"status":      resolution.Status,
+			"action":      resolution.Action,
+			"resolved_by": resolution.ResolvedBy,
+			"resolved_at": resolution.ResolvedAt,
+		})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.UpdateFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestReportRepo_QueueAndResolve(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserRepo(db, zaptest.NewLogger(t).Sugar())
+	repo := NewReportRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	ann := createTestUser(t, users, "ann@example.com")
+	bob := createTestUser(t, users, "bob@example.com")
+	eve := createTestUser(t, users, "eve@example.com")
+	now := time.Now()
+
+	first := &models.Report{ReporterID: ann.ID, UserID: eve.ID, Reason: models.ReportReasonSpam, Details: "Sells followers", Status: models.ReportStatusPending, CreatedAt: now.Add(-time.Hour)}
+	require.NoError(t, repo.CreateReport(ctx, first))
+	second := &models.Report{ReporterID: bob.ID, UserID: eve.ID, Reason: models.ReportReasonHarassment, Status: models.ReportStatusPending, CreatedAt: now}
+	require.NoError(t, repo.CreateReport(ctx, second))
+	require.NoError(t, repo.CreateReport(ctx, &models.Report{ReporterID: eve.ID, UserID: bob.ID, Reason: models.ReportReasonOther, Status: models.ReportStatusPending, CreatedAt: now}))
+
+	pending, err := repo.HasPendingReport(ctx, ann.ID, eve.ID)
+	require.NoError(t, err)
+	assert.True(t, pending)
+	pending, err = repo.HasPendingReport(ctx, ann.ID, bob.ID)
+	require.NoError(t, err)
+	assert.False(t, pending)
+
+	reports, total, err := repo.ListReports(ctx, &models.ReportQuery{Status: models.ReportStatusPending, UserID: eve.ID}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, reports, 1)
+	assert.Equal(t, first.ID, reports[0].ID, "oldest first")
+	assert.Equal(t, "Sells followers", reports[0].Details)
+	require.NotNil(t, reports[0].User)
+	assert.Equal(t, "eve@example.com", reports[0].User.Email)
+
+	resolution := &models.ReportResolution{Status: models.ReportStatusDismissed, Action: models.ReportActionDismiss, ResolvedBy: bob.ID, ResolvedAt: now}
+	resolved, err := repo.ResolveReport(ctx, first.ID, resolution)
+	require.NoError(t, err)
+	assert.True(t, resolved)
+	resolved, err = repo.ResolveReport(ctx, first.ID, resolution)
+	require.NoError(t, err)
+	assert.False(t, resolved, "a resolved report stays as it was resolved")
+
+	count, err := repo.ResolvePendingReports(ctx, eve.ID, &models.ReportResolution{Status: models.ReportStatusActioned, Action: models.ReportActionSuspend, ResolvedBy: bob.ID, ResolvedAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	report, err := repo.GetReport(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportStatusDismissed, report.Status)
+	report, err = repo.GetReport(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportStatusActioned, report.Status)
+	assert.Equal(t, models.ReportActionSuspend, report.Action)
+	assert.Equal(t, bob.ID, report.ResolvedBy)
+	require.NotNil(t, report.ResolvedAt)
+
+	reports, total, err = repo.ListReports(ctx, &models.ReportQuery{Status: models.ReportStatusPending}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, bob.ID, reports[0].UserID)
+
+	_, err = repo.GetReport(ctx, 999)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
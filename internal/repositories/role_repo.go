@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RoleRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type RoleRepoInterface interface {
+	GetRoleByName(ctx context.Context, name string) (*models.Role, error)
+	// GetRole returns ErrNotFound when there is no role with the ID
+	GetRole(ctx context.Context, id uint) (*models.Role, error)
+	// ListRoles returns every role with its parent, ordered by ID
+	ListRoles(ctx context.Context) ([]models.Role, error)
+}
+
+func NewRoleRepo(db *gorm.DB, logger *zap.SugaredLogger) *RoleRepo {
+	return &RoleRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *RoleRepo) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	return repo.first(reader(ctx, repo.db).Where("name = ?", name))
+}
+
+func (repo *RoleRepo) GetRole(ctx context.Context, id uint) (*models.Role, error) {
+	return repo.first(reader(ctx, repo.db).Where("id = ?", id))
+}
+
+func (repo *RoleRepo) ListRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	result := reader(ctx, repo.db).Order("id").Find(&roles)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return roles, nil
+}
+
+func (repo *RoleRepo) first(query *gorm.DB) (*models.Role, error) {
+	role := &models.Role{}
+	result := query.First(role)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Role not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return role, nil
+}
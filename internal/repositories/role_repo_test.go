@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRoleRepo_ListRolesWithParents(t *testing.T) {
+	repo := NewRoleRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+
+	roles, err := repo.ListRoles(context.Background())
+	require.NoError(t, err)
+	require.Len(t, roles, 3)
+	assert.Equal(t, models.StrUser, roles[0].Name)
+	assert.Nil(t, roles[0].ParentID)
+	require.NotNil(t, roles[1].ParentID)
+	assert.Equal(t, roles[0].ID, *roles[1].ParentID, "moderators inherit from users")
+	require.NotNil(t, roles[2].ParentID)
+	assert.Equal(t, roles[1].ID, *roles[2].ParentID, "admins inherit from moderators")
+}
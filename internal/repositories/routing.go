@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary marks the context so repository reads go to the primary instead of a replica.
+// Use it when a read must observe a write made moments before (read your writes).
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return primary
+}
+
+// reader returns a session for read-only queries, which may be served by a replica
+// unless the context carries a transaction or asks for the primary
+func reader(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	if usePrimary(ctx) {
+		return writer(ctx, db)
+	}
+	return db.WithContext(ctx)
+}
+
+// writer returns a session pinned to the primary, including the reads of a read-modify-write,
+// joining the transaction carried by the context if there is one
+func writer(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	// The extra Session keeps the returned handle reusable for several queries, like WithContext alone
+	return db.WithContext(ctx).Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledRunRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type ScheduledRunRepoInterface interface {
+	// LastRuns returns when each task that ever ran was last started, by task name
+	LastRuns(ctx context.Context) (map[string]time.Time, error)
+	// SaveRun records that task was started at
+	SaveRun(ctx context.Context, task string, at time.Time) error
+}
+
+func NewScheduledRunRepo(db *gorm.DB, logger *zap.SugaredLogger) *ScheduledRunRepo {
+	return &ScheduledRunRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *ScheduledRunRepo) LastRuns(ctx context.Context) (map[string]time.Time, error) {
+	var runs []models.ScheduledRun
+	result := reader(ctx, repo.db).Find(&runs)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	lastRuns := make(map[string]time.Time, len(runs))
+	for _, run := range runs {
+		lastRuns[run.Task] = run.LastRunAt
+	}
+	return lastRuns, nil
+}
+
+func (repo *ScheduledRunRepo) SaveRun(ctx context.Context, task string, at time.Time) error {
+	result := writer(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_run_at"}),
+	}).Create(&models.ScheduledRun{Task: task, LastRunAt: at})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestScheduledRunRepo_KeepsTheLastRunOfEachTask(t *testing.T) {
+	repo := NewScheduledRunRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.SaveRun(ctx, "users.archive", first))
+	require.NoError(t, repo.SaveRun(ctx, "cleanup", first))
+	require.NoError(t, repo.SaveRun(ctx, "users.archive", first.Add(time.Hour)))
+
+	runs, err := repo.LastRuns(ctx)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.True(t, runs["users.archive"].Equal(first.Add(time.Hour)), runs["users.archive"])
+	assert.True(t, runs["cleanup"].Equal(first), runs["cleanup"])
+}
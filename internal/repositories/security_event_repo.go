@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SecurityEventRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type SecurityEventRepoInterface interface {
+	CreateSecurityEvent(ctx context.Context, event *models.SecurityEvent) error
+	// ListSecurityEvents returns up to query.Limit events matching the query, newest first
+	ListSecurityEvents(ctx context.Context, query *models.SecurityEventQuery) ([]models.SecurityEvent, error)
+	// DeleteSecurityEvents drops up to limit events recorded before createdBefore and returns how many
+	DeleteSecurityEvents(ctx context.Context, createdBefore time.Time, limit int) (int, error)
+}
+
+func NewSecurityEventRepo(db *gorm.DB, logger *zap.SugaredLogger) *SecurityEventRepo {
+	return &SecurityEventRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *SecurityEventRepo) CreateSecurityEvent(ctx context.Context, event *models.SecurityEvent) error {
+	result := writer(ctx, repo.db).Create(event)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *SecurityEventRepo) ListSecurityEvents(ctx context.Context, query *models.SecurityEventQuery) ([]models.SecurityEvent, error) {
+	tx := reader(ctx, repo.db).Order("id DESC").Limit(query.Limit)
+	if query.UserID > 0 {
+		tx = tx.Where("user_id = ?", query.UserID)
+	}
+	if query.Type != "" {
+		tx = tx.Where("type = ?", query.Type)
+	}
+	if query.BeforeID > 0 {
+		tx = tx.Where("id < ?", query.BeforeID)
+	}
+
+	var events []models.SecurityEvent
+	result := tx.Find(&events)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return events, nil
+}
+
+func (repo *SecurityEventRepo) DeleteSecurityEvents(ctx context.Context, createdBefore time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.SecurityEvent{}).Select("id").
+		Where("created_at < ?", createdBefore).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.SecurityEvent{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSecurityEventRepo_ListFiltersAndPagesBack(t *testing.T) {
+	repo := NewSecurityEventRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for _, event := range []*models.SecurityEvent{
+		{UserID: 1, Type: models.SecurityEventLogin, IP: "203.0.113.7", UserAgent: "curl/8.0"},
+		{UserID: 2, Type: models.SecurityEventLogin, IP: "198.51.100.4"},
+		{UserID: 1, Type: models.SecurityEventPasswordChanged, IP: "203.0.113.7"},
+		{UserID: 1, Type: models.SecurityEventTokenRevoked, Details: "dashboard"},
+	} {
+		require.NoError(t, repo.CreateSecurityEvent(ctx, event))
+	}
+
+	events, err := repo.ListSecurityEvents(ctx, &models.SecurityEventQuery{UserID: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.SecurityEventTokenRevoked, events[0].Type, "newest first")
+	assert.Equal(t, models.SecurityEventPasswordChanged, events[1].Type)
+
+	rest, err := repo.ListSecurityEvents(ctx, &models.SecurityEventQuery{UserID: 1, BeforeID: events[1].ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, "203.0.113.7", rest[0].IP)
+	assert.Equal(t, "curl/8.0", rest[0].UserAgent)
+
+	events, err = repo.ListSecurityEvents(ctx, &models.SecurityEventQuery{Type: models.SecurityEventLogin, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint(2), events[0].UserID)
+}
+
+func TestSecurityEventRepo_DeletesOldEventsInBatches(t *testing.T) {
+	repo := NewSecurityEventRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, createdAt := range []time.Time{now.Add(-48 * time.Hour), now.Add(-47 * time.Hour), now} {
+		require.NoError(t, repo.CreateSecurityEvent(ctx, &models.SecurityEvent{UserID: 1, Type: models.SecurityEventLogin, CreatedAt: createdAt}))
+	}
+
+	deleted, err := repo.DeleteSecurityEvents(ctx, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	deleted, err = repo.DeleteSecurityEvents(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	events, err := repo.ListSecurityEvents(ctx, &models.SecurityEventQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
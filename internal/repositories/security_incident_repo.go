@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SecurityIncidentRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type SecurityIncidentRepoInterface interface {
+	CreateSecurityIncident(ctx context.Context, incident *models.SecurityIncident) error
+	// ListSecurityIncidents returns the latest incidents, of one signal or of all when signal is empty
+	ListSecurityIncidents(ctx context.Context, signal string, limit int) ([]models.SecurityIncident, error)
+	// DeleteSecurityIncidents drops up to limit incidents raised before createdBefore and returns how many
+	DeleteSecurityIncidents(ctx context.Context, createdBefore time.Time, limit int) (int, error)
+}
+
+func NewSecurityIncidentRepo(db *gorm.DB, logger *zap.SugaredLogger) *SecurityIncidentRepo {
+	return &SecurityIncidentRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *SecurityIncidentRepo) CreateSecurityIncident(ctx context.Context, incident *models.SecurityIncident) error {
+	result := writer(ctx, repo.db).Create(incident)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *SecurityIncidentRepo) ListSecurityIncidents(ctx context.Context, signal string, limit int) ([]models.SecurityIncident, error) {
+	var incidents []models.SecurityIncident
+	tx := reader(ctx, repo.db).Order("created_at DESC, id DESC").Limit(limit)
+	if signal != "" {
+		tx = tx.Where("signal = ?", signal)
+	}
+	result := tx.Find(&incidents)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return incidents, nil
+}
+
+func (repo *SecurityIncidentRepo) DeleteSecurityIncidents(ctx context.Context, createdBefore time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.SecurityIncident{}).Select("id").
+		Where("created_at < ?", createdBefore).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.SecurityIncident{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSecurityIncidentRepo_ListsLatestIncidentsOfTheTenant(t *testing.T) {
+	repo := NewSecurityIncidentRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, incident := range []*models.SecurityIncident{
+		{Signal: models.SignalIP, Subject: "203.0.113.7", Failures: 20},
+		{Signal: models.SignalAccount, Subject: "ann@example.com", Failures: 10},
+		{Signal: models.SignalIP, Subject: "198.51.100.4", Failures: 20},
+	} {
+		incident.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.CreateSecurityIncident(ctx, incident))
+	}
+	require.NoError(t, repo.CreateSecurityIncident(tenancy.WithTenant(ctx, 2), &models.SecurityIncident{Signal: models.SignalIP, Subject: "192.0.2.1", Failures: 20}))
+
+	incidents, err := repo.ListSecurityIncidents(tenancy.WithTenant(ctx, tenancy.DefaultTenantID), models.SignalIP, 10)
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, "198.51.100.4", incidents[0].Subject)
+	assert.Equal(t, "203.0.113.7", incidents[1].Subject)
+
+	incidents, err = repo.ListSecurityIncidents(tenancy.WithTenant(ctx, tenancy.DefaultTenantID), "", 2)
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, models.SignalAccount, incidents[1].Signal)
+	assert.Equal(t, "ann@example.com", incidents[1].Subject)
+}
+
+func TestSecurityIncidentRepo_DeletesOldIncidentsInBatches(t *testing.T) {
+	repo := NewSecurityIncidentRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, createdAt := range []time.Time{now.Add(-48 * time.Hour), now.Add(-47 * time.Hour), now} {
+		require.NoError(t, repo.CreateSecurityIncident(ctx, &models.SecurityIncident{Signal: models.SignalIP, Subject: "203.0.113.7", Failures: 20, CreatedAt: createdAt}))
+	}
+
+	deleted, err := repo.DeleteSecurityIncidents(ctx, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	deleted, err = repo.DeleteSecurityIncidents(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	incidents, err := repo.ListSecurityIncidents(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, incidents, 1)
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShadowBanRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type ShadowBanRepoInterface interface {
+	// CreateShadowBan shadow-bans the user of ban and recalculates the ratings they voted on, and returns false
+	// when they already were
+	CreateShadowBan(ctx context.Context, ban *models.ShadowBan) (bool, error)
+	// DeleteShadowBan lifts the shadow ban of the user and recalculates the ratings they voted on
+	DeleteShadowBan(ctx context.Context, userID uint) error
+	IsShadowBanned(ctx context.Context, userID uint) (bool, error)
+	// ListShadowBans returns a page of the shadow bans with the users, latest first, and how many there are
+	ListShadowBans(ctx context.Context, page, pageSize int) ([]models.ShadowBan, int, error)
+}
+
+func NewShadowBanRepo(db *gorm.DB, logger *zap.SugaredLogger) *ShadowBanRepo {
+	return &ShadowBanRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *ShadowBanRepo) CreateShadowBan(ctx context.Context, ban *models.ShadowBan) (bool, error) {
+	created := false
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(ban)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		created = true
+		return models.RecalculateRatings(tx, ban.UserID)
+	})
+	if err != nil {
+		repo.logger.Error(err)
+		return false, translateError(err, &apperrors.InsertionFailedErr)
+	}
+	return created, nil
+}
+
+func (repo *ShadowBanRepo) DeleteShadowBan(ctx context.Context, userID uint) error {
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ?", userID).Delete(&models.ShadowBan{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound.AppendMessage("Shadow ban not found.")
+		}
+		return models.RecalculateRatings(tx, userID)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		repo.logger.Error(err)
+	}
+	return translateError(err, &apperrors.DeletionFailedErr)
+}
+
+func (repo *ShadowBanRepo) IsShadowBanned(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	result := reader(ctx, repo.db).Model(&models.ShadowBan{}).Where("user_id = ?", userID).Count(&count)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return false, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return count > 0, nil
+}
+
+func (repo *ShadowBanRepo) ListShadowBans(ctx context.Context, page, pageSize int) ([]models.ShadowBan, int, error) {
+	tx := reader(ctx, repo.db).Model(&models.ShadowBan{}).Session(&gorm.Session{})
+
+	var total int64
+	result := tx.Count(&total)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	var bans []models.ShadowBan
+	result = tx.Preload("User.Role").
+		Order("created_at DESC, user_id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&bans)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return bans, int(total), nil
+}
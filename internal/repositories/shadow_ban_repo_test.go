@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestShadowBanRepo_HidesUserFromOthers(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewUserRepo(db, logger)
+	votes := NewVoteRepo(db, logger)
+	follows := NewFollowRepo(db, logger)
+	repo := NewShadowBanRepo(db, logger)
+	ctx := context.Background()
+
+	ann := createTestUser(t, users, "ann@example.com")
+	bob := createTestUser(t, users, "bob@example.com")
+	eve := createTestUser(t, users, "eve@example.com")
+	rating := func() int {
+		user, err := users.GetUserByID(ctx, ann.ID)
+		require.NoError(t, err)
+		return user.Rating
+	}
+	for _, voter := range []uint{bob.ID, eve.ID} {
+		_, err := votes.CreateVote(ctx, &models.Vote{UserID: voter, ProfileID: ann.ID, Value: 1, CreatedAt: time.Now()})
+		require.NoError(t, err)
+	}
+	_, err := follows.CreateFollow(ctx, &models.Follow{FollowerID: eve.ID, FollowedID: ann.ID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, 2, rating())
+
+	created, err := repo.CreateShadowBan(ctx, &models.ShadowBan{UserID: eve.ID, CreatedBy: bob.ID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = repo.CreateShadowBan(ctx, &models.ShadowBan{UserID: eve.ID, CreatedBy: bob.ID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	assert.False(t, created, "shadow-banning twice keeps the first shadow ban")
+
+	banned, err := repo.IsShadowBanned(ctx, eve.ID)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	assert.Equal(t, 1, rating(), "the votes of the shadow-banned user no longer count")
+
+	listed, total, err := users.ListUsersWithTotal(ctx, 1, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	for _, user := range listed {
+		assert.NotEqual(t, eve.ID, user.ID)
+	}
+	counts, err := follows.CountFollows(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, counts.Followers)
+	followerIDs, err := follows.ListFollowerIDs(ctx, ann.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{eve.ID}, followerIDs, "the shadow-banned follower is still notified")
+
+	bans, total, err := repo.ListShadowBans(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, bans, 1)
+	require.NotNil(t, bans[0].User)
+	assert.Equal(t, "eve@example.com", bans[0].User.Email)
+
+	require.NoError(t, repo.DeleteShadowBan(ctx, eve.ID))
+	assert.Equal(t, 2, rating(), "lifting the shadow ban counts the votes again")
+	assert.ErrorIs(t, repo.DeleteShadowBan(ctx, eve.ID), ErrNotFound)
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type StatsRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type StatsRepoInterface interface {
+	// GetStats counts the users, signups and votes of the tenant; users are active when they signed in at
+	// activeSince or later
+	GetStats(ctx context.Context, now, activeSince time.Time) (*models.Stats, error)
+}
+
+func NewStatsRepo(db *gorm.DB, logger *zap.SugaredLogger) *StatsRepo {
+	return &StatsRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// statsRow is the row of the stats query
+type statsRow struct {
+	TotalUsers   int64
+	ActiveUsers  int64
+	DeletedUsers int64
+	SignupsDay   int64
+	SignupsWeek  int64
+	SignupsMonth int64
+	VotesCast    int64
+}
+
+func (repo *StatsRepo) GetStats(ctx context.Context, now, activeSince time.Time) (*models.Stats, error) {
+	const live = "(users.deleted_at IS NULL OR users.deleted_at = ?)"
+	notDeleted := time.Time{}
+
+	// The tenancy plugin scopes the users; the votes subquery is raw SQL and is scoped here. Votes of shadow-banned
+	// users do not count, as they do not count towards ratings.
+	votes, votesArgs := "SELECT COUNT(*) FROM votes WHERE votes.user_id NOT IN ("+models.ShadowBannedUsers+")", []interface{}{}
+	if tenantID, ok := tenancy.FromContext(ctx); ok {
+		votes, votesArgs = votes+" AND votes.tenant_id = ?", []interface{}{tenantID}
+	}
+
+	args := []interface{}{
+		notDeleted,
+		notDeleted, activeSince,
+		notDeleted,
+		now.AddDate(0, 0, -1), now.AddDate(0, 0, -7), now.AddDate(0, 0, -30),
+	}
+	row := statsRow{}
+	err := reader(ctx, repo.db).Model(&models.User{}).
+		Joins("LEFT JOIN user_activity ON user_activity.user_id = users.id").
+		Select("COALESCE(SUM(CASE WHEN "+live+" THEN 1 ELSE 0 END), 0) AS total_users, "+
+			"COALESCE(SUM(CASE WHEN "+live+" AND user_activity.last_login_at >= ? THEN 1 ELSE 0 END), 0) AS active_users, "+
+			"COALESCE(SUM(CASE WHEN NOT "+live+" THEN 1 ELSE 0 END), 0) AS deleted_users, "+
+			"COALESCE(SUM(CASE WHEN users.created_at >= ? THEN 1 ELSE 0 END), 0) AS signups_day, "+
+			"COALESCE(SUM(CASE WHEN users.created_at >= ? THEN 1 ELSE 0 END), 0) AS signups_week, "+
+			"COALESCE(SUM(CASE WHEN users.created_at >= ? THEN 1 ELSE 0 END), 0) AS signups_month, "+
+			"("+votes+") AS votes_cast", append(args, votesArgs...)...).
+		Scan(&row).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.QueryFailedErr)
+	}
+
+	return &models.Stats{
+		TotalUsers:   row.TotalUsers,
+		ActiveUsers:  row.ActiveUsers,
+		DeletedUsers: row.DeletedUsers,
+		Signups:      models.SignupStats{Day: row.SignupsDay, Week: row.SignupsWeek, Month: row.SignupsMonth},
+		VotesCast:    row.VotesCast,
+		ActiveSince:  activeSince,
+		GeneratedAt:  now,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStatsRepo_GetStats(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	userRepo := NewUserRepo(db, logger)
+	ctx := context.Background()
+	now := time.Now()
+
+	active := createTestUser(t, userRepo, "active@example.com")
+	idle := createTestUser(t, userRepo, "idle@example.com")
+	old := createTestUser(t, userRepo, "old@example.com")
+	deleted := createTestUser(t, userRepo, "deleted@example.com")
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", old.ID).Update("created_at", now.AddDate(0, 0, -10)).Error)
+	_, err := userRepo.DeleteUser(ctx, fmt.Sprint(deleted.ID))
+	require.NoError(t, err)
+
+	activity := NewInactivityRepo(db, logger)
+	require.NoError(t, activity.RecordLogin(ctx, active.ID, now.Add(-time.Hour)))
+	require.NoError(t, activity.RecordLogin(ctx, idle.ID, now.AddDate(0, 0, -60)))
+
+	votes := NewVoteRepo(db, logger)
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: active.ID, ProfileID: idle.ID, Value: 1})
+	require.NoError(t, err)
+	_, err = votes.CreateVote(ctx, &models.Vote{UserID: idle.ID, ProfileID: active.ID, Value: -1})
+	require.NoError(t, err)
+
+	repo := NewStatsRepo(db, logger)
+	stats, err := repo.GetStats(ctx, now, now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalUsers)
+	assert.Equal(t, int64(1), stats.ActiveUsers)
+	assert.Equal(t, int64(1), stats.DeletedUsers)
+	assert.Equal(t, models.SignupStats{Day: 3, Week: 3, Month: 4}, stats.Signups, "deleted users still signed up")
+	assert.Equal(t, int64(2), stats.VotesCast)
+
+	// Another tenant sees none of it
+	stats, err = repo.GetStats(tenancy.WithTenant(ctx, 2), now, now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, models.Stats{ActiveSince: stats.ActiveSince, GeneratedAt: now}, *stats)
+}
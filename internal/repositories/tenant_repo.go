@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TenantRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type TenantRepoInterface interface {
+	GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	GetTenantByHost(ctx context.Context, host string) (*models.Tenant, error)
+}
+
+func NewTenantRepo(db *gorm.DB, logger *zap.SugaredLogger) *TenantRepo {
+	return &TenantRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *TenantRepo) GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return repo.getTenant(ctx, "slug = ?", slug)
+}
+
+func (repo *TenantRepo) GetTenantByHost(ctx context.Context, host string) (*models.Tenant, error) {
+	return repo.getTenant(ctx, "host = ?", host)
+}
+
+func (repo *TenantRepo) getTenant(ctx context.Context, query string, value string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	result := reader(ctx, repo.db).Where(query, value).First(&tenant)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound.AppendMessage("Tenant not found.")
+		}
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return &tenant, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TermsRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type TermsRepoInterface interface {
+	// CreateAcceptance returns ErrDuplicate when the user already accepted the version
+	CreateAcceptance(ctx context.Context, acceptance *models.TermsAcceptance) error
+	// GetAcceptance returns ErrNotFound when the user has not accepted the version
+	GetAcceptance(ctx context.Context, userID uint, version string) (*models.TermsAcceptance, error)
+	// ListAcceptances returns the versions the user accepted, newest first
+	ListAcceptances(ctx context.Context, userID uint) ([]models.TermsAcceptance, error)
+}
+
+func NewTermsRepo(db *gorm.DB, logger *zap.SugaredLogger) *TermsRepo {
+	return &TermsRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *TermsRepo) CreateAcceptance(ctx context.Context, acceptance *models.TermsAcceptance) error {
+	result := writer(ctx, repo.db).Create(acceptance)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *TermsRepo) GetAcceptance(ctx context.Context, userID uint, version string) (*models.TermsAcceptance, error) {
+	acceptance := &models.TermsAcceptance{}
+	result := reader(ctx, repo.db).Where("user_id = ? AND version = ?", userID, version).First(acceptance)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound.AppendMessage("Terms acceptance not found.")
+	}
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return acceptance, nil
+}
+
+func (repo *TermsRepo) ListAcceptances(ctx context.Context, userID uint) ([]models.TermsAcceptance, error) {
+	var acceptances []models.TermsAcceptance
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).Order("accepted_at DESC, id DESC").Find(&acceptances)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return acceptances, nil
+}
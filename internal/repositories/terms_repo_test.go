@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTermsRepo_Acceptances(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewTermsRepo(db, logger)
+	ctx := context.Background()
+	user := createTestUser(t, NewUserRepo(db, logger), "user@example.com")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, repo.CreateAcceptance(ctx, &models.TermsAcceptance{UserID: user.ID, Version: "2024-01", AcceptedAt: now.Add(-time.Hour)}))
+	require.NoError(t, repo.CreateAcceptance(ctx, &models.TermsAcceptance{UserID: user.ID, Version: "2024-05", AcceptedAt: now}))
+	err := repo.CreateAcceptance(ctx, &models.TermsAcceptance{UserID: user.ID, Version: "2024-05", AcceptedAt: now})
+	assert.True(t, errors.Is(err, ErrDuplicate), "a version is accepted once, got %v", err)
+
+	found, err := repo.GetAcceptance(ctx, user.ID, "2024-01")
+	require.NoError(t, err)
+	assert.True(t, found.AcceptedAt.Equal(now.Add(-time.Hour)))
+	_, err = repo.GetAcceptance(ctx, user.ID, "2025-01")
+	assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+
+	acceptances, err := repo.ListAcceptances(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, acceptances, 2)
+	assert.Equal(t, "2024-05", acceptances[0].Version, "newest first")
+}
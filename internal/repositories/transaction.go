@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxTxAttempts bounds how often a transaction is replayed after a serialization failure or deadlock
+	maxTxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+type txContextKey struct{}
+
+type TxManager struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type TxManagerInterface interface {
+	// WithinTransaction runs fn in a transaction carried by the context passed to it. Repositories
+	// called with that context join the transaction; it commits when fn returns nil and rolls back otherwise.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func NewTxManager(db *gorm.DB, logger *zap.SugaredLogger) *TxManager {
+	return &TxManager{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (manager *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// A nested call runs inside the outer transaction using a savepoint; only the outermost call
+	// can retry, since Postgres aborts the whole transaction on a serialization failure
+	if tx, ok := txFromContext(ctx); ok {
+		return manager.run(ctx, tx, fn)
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = manager.run(ctx, manager.db, fn)
+		if !isRetryableTxError(err) {
+			return err
+		}
+
+		manager.logger.Warnw("Retrying transaction", "attempt", attempt, "error", err)
+		if attempt == maxTxAttempts {
+			break
+		}
+
+		// Full jitter keeps the conflicting transactions from retrying in lockstep
+		delay := time.Duration(rand.Int63n(int64(txRetryDelay) << attempt))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return ErrConflict.AppendMessage(err.Error())
+}
+
+func (manager *TxManager) run(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+	if err != nil {
+		manager.logger.Debugw("Transaction rolled back", "error", err)
+	}
+	return err
+}
+
+// isRetryableTxError reports whether the transaction failed with a Postgres serialization failure or
+// deadlock. Services often flatten driver errors into AppError messages, so the SQLSTATE is also
+// looked for in the text.
+func isRetryableTxError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected
+	}
+
+	message := err.Error()
+	return strings.Contains(message, "(SQLSTATE "+pgerrcode.SerializationFailure+")") ||
+		strings.Contains(message, "(SQLSTATE "+pgerrcode.DeadlockDetected+")")
+}
+
+func txFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok
+}
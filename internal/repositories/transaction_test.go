@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTxManager_RetriesSerializationFailure(t *testing.T) {
+	manager := NewTxManager(newTestDB(t), zaptest.NewLogger(t).Sugar())
+
+	attempts := 0
+	err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: pgerrcode.SerializationFailure, Message: "could not serialize access"}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestTxManager_GivesUpAfterMaxAttempts(t *testing.T) {
+	manager := NewTxManager(newTestDB(t), zaptest.NewLogger(t).Sugar())
+
+	attempts := 0
+	err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		// Wrapped the way services flatten driver errors
+		deadlock := &pgconn.PgError{Severity: "ERROR", Code: pgerrcode.DeadlockDetected, Message: "deadlock detected"}
+		return apperrors.UpdateFailedErr.AppendMessage(deadlock.Error())
+	})
+
+	assert.True(t, apperrors.Is(err, &apperrors.TransactionConflictErr))
+	assert.Equal(t, maxTxAttempts, attempts)
+}
+
+func TestTxManager_DoesNotRetryOtherErrors(t *testing.T) {
+	manager := NewTxManager(newTestDB(t), zaptest.NewLogger(t).Sugar())
+
+	boom := errors.New("boom")
+	attempts := 0
+	err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return boom
+	})
+
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserArchiveRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type UserArchiveRepoInterface interface {
+	// ArchiveDeletedUsers moves up to limit users soft-deleted before deletedBefore into users_archive,
+	// or drops them for good when purge is set, and returns how many were processed
+	ArchiveDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int, purge bool) (int, error)
+}
+
+func NewUserArchiveRepo(db *gorm.DB, logger *zap.SugaredLogger) *UserArchiveRepo {
+	return &UserArchiveRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *UserArchiveRepo) ArchiveDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int, purge bool) (int, error) {
+	var archived int
+	err := writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		err := tx.Where("deleted_at IS NOT NULL AND deleted_at > ? AND deleted_at < ?", time.Time{}, deletedBefore).
+			Order("id").
+			Limit(limit).
+			Find(&users).Error
+		if err != nil || len(users) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+
+		if !purge {
+			now := time.Now()
+			archive := make([]models.UserArchive, len(users))
+			for i, user := range users {
+				archive[i] = models.UserArchive{
+					ID:            user.ID,
+					TenantID:      user.TenantID,
+					Email:         user.Email,
+					FirstName:     user.FirstName,
+					LastName:      user.LastName,
+					RoleID:        user.RoleID,
+					Rating:        user.Rating,
+					CreatedAt:     user.CreatedAt,
+					UpdatedAt:     user.UpdatedAt,
+					VoteUpdatedAt: user.VoteUpdatedAt,
+					DeletedAt:     user.DeletedAt,
+					ArchivedAt:    now,
+				}
+			}
+			err = tx.Create(&archive).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Votes reference the users on both sides. The profiles the users voted for, and that stay, lose those
+		// votes from their ratings in this transaction, as when the vote is revoked.
+		var profileIDs []uint
+		err = tx.Model(&models.Vote{}).Distinct().Where("user_id IN ? AND profile_id NOT IN ?", ids, ids).
+			Order("profile_id").Pluck("profile_id", &profileIDs).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id IN ? OR profile_id IN ?", ids, ids).Delete(&models.Vote{}).Error
+		if err != nil {
+			return err
+		}
+		err = models.RecalculateProfileRatings(tx, profileIDs)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("id IN ?", ids).Delete(&models.User{}).Error
+		if err != nil {
+			return err
+		}
+
+		archived = len(users)
+		return nil
+	})
+	if err != nil {
+		repo.logger.Error(err)
+		return 0, translateError(err, &apperrors.DeletionFailedErr)
+	}
+
+	return archived, nil
+}
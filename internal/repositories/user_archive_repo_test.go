@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestUserArchiveRepo_ArchivesOnlyOldDeletions(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	userRepo := NewUserRepo(db, logger)
+	archiveRepo := NewUserArchiveRepo(db, logger)
+	ctx := context.Background()
+
+	old := createTestUser(t, userRepo, "old@example.com")
+	recent := createTestUser(t, userRepo, "recent@example.com")
+	createTestUser(t, userRepo, "active@example.com")
+
+	_, err := userRepo.UpdateUser(ctx, fmt.Sprint(old.ID), &models.User{DeletedAt: time.Now().AddDate(0, 0, -100)})
+	require.NoError(t, err)
+	_, err = userRepo.DeleteUser(ctx, fmt.Sprint(recent.ID))
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.Vote{UserID: old.ID, ProfileID: recent.ID, Value: 1}).Error)
+	require.NoError(t, db.First(recent, recent.ID).Error)
+	require.Equal(t, 1, recent.Rating)
+
+	archived, err := archiveRepo.ArchiveDeletedUsers(ctx, time.Now().AddDate(0, 0, -30), 10, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+
+	var stored models.UserArchive
+	require.NoError(t, db.First(&stored, old.ID).Error)
+	assert.Equal(t, "old@example.com", stored.Email)
+	assert.False(t, stored.ArchivedAt.IsZero())
+
+	var remaining, votes int64
+	db.Model(&models.User{}).Count(&remaining)
+	db.Model(&models.Vote{}).Count(&votes)
+	assert.Equal(t, int64(2), remaining)
+	assert.Zero(t, votes)
+	var profile models.User
+	require.NoError(t, db.First(&profile, recent.ID).Error)
+	assert.Zero(t, profile.Rating, "the archived voter's vote is out of the rating")
+
+	archived, err = archiveRepo.ArchiveDeletedUsers(ctx, time.Now().AddDate(0, 0, -30), 10, false)
+	require.NoError(t, err)
+	assert.Zero(t, archived)
+}
@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gorm.io/gorm"
+)
+
+type filterKind int
+
+const (
+	filterString filterKind = iota
+	filterInt
+	filterTime
+	filterBool
+)
+
+// userFilterField is one field users can be filtered on. Column is only ever taken from userFilterFields, values
+// are always bound as parameters.
+type userFilterField struct {
+	column    string
+	kind      filterKind
+	operators []filter.Operator
+	// encrypted fields are sealed when personal data is encrypted; they can then only be compared by equality
+	// through indexColumn, or not at all without one
+	encrypted   bool
+	indexColumn string
+	// normalize puts values in the form the field is stored in
+	normalize func(value string) string
+	// value reads the field of a user held in memory
+	value func(user *models.User) interface{}
+}
+
+var (
+	equality   = []filter.Operator{filter.Eq, filter.Ne, filter.In}
+	ordering   = []filter.Operator{filter.Eq, filter.Ne, filter.Gt, filter.Gte, filter.Lt, filter.Lte, filter.In}
+	timeRanges = []filter.Operator{filter.Gt, filter.Gte, filter.Lt, filter.Lte}
+	text       = []filter.Operator{filter.Eq, filter.Ne, filter.In, filter.Contains}
+	flag       = []filter.Operator{filter.Eq, filter.Ne}
+)
+
+// userFilterFields is the allowlist of GET /users?filter[...]
+var userFilterFields = map[string]userFilterField{
+	"id": {column: "users.id", kind: filterInt, operators: ordering,
+		value: func(user *models.User) interface{} { return int64(user.ID) }},
+	"role": {column: "(SELECT roles.name FROM roles WHERE roles.id = users.role_id)", kind: filterString, operators: equality,
+		value: func(user *models.User) interface{} { return user.Role.Name }},
+	"email": {column: "users.email", kind: filterString, operators: text, encrypted: true, indexColumn: "users.email_index",
+		normalize: models.NormalizeEmail, value: func(user *models.User) interface{} { return user.Email }},
+	"first_name": {column: "users.first_name", kind: filterString, operators: text, encrypted: true,
+		value: func(user *models.User) interface{} { return user.FirstName }},
+	"last_name": {column: "users.last_name", kind: filterString, operators: text, encrypted: true,
+		value: func(user *models.User) interface{} { return user.LastName }},
+	"rating": {column: "users.rating", kind: filterInt, operators: ordering,
+		value: func(user *models.User) interface{} { return int64(user.Rating) }},
+	"timezone": {column: "users.timezone", kind: filterString, operators: equality,
+		value: func(user *models.User) interface{} { return user.Timezone }},
+	"locale": {column: "users.locale", kind: filterString, operators: equality,
+		value: func(user *models.User) interface{} { return user.Locale }},
+	"created_at": {column: "users.created_at", kind: filterTime, operators: timeRanges,
+		value: func(user *models.User) interface{} { return user.CreatedAt }},
+	"updated_at": {column: "users.updated_at", kind: filterTime, operators: timeRanges,
+		value: func(user *models.User) interface{} { return user.UpdatedAt }},
+	"service_account": {column: "users.service_account", kind: filterBool, operators: flag,
+		value: func(user *models.User) interface{} { return user.ServiceAccount }},
+}
+
+// userCondition is a condition checked against the allowlist, with its value parsed
+type userCondition struct {
+	filter.Condition
+	field  userFilterField
+	values []interface{}
+}
+
+// compileUserFilters checks every condition against userFilterFields; an unknown field, an operator the field
+// does not take or a value of the wrong type is a BadRequestErr
+func compileUserFilters(conditions []filter.Condition) ([]userCondition, error) {
+	compiled := make([]userCondition, 0, len(conditions))
+	for _, condition := range conditions {
+		field, ok := userFilterFields[condition.Field]
+		if !ok {
+			return nil, apperrors.BadRequestErr.AppendMessage("users can not be filtered by " + condition.Field)
+		}
+		if !hasOperator(field.operators, condition.Operator) {
+			return nil, apperrors.BadRequestErr.AppendMessage(condition.String() + " is not supported")
+		}
+		if field.encrypted && pii.Enabled() && (condition.Operator == filter.Contains || field.indexColumn == "") {
+			return nil, apperrors.BadRequestErr.AppendMessage(condition.String() + " is not supported while personal data is encrypted")
+		}
+
+		raw := []string{condition.Value}
+		if condition.Operator == filter.In {
+			raw = condition.Values()
+		}
+		values := make([]interface{}, len(raw))
+		for i, value := range raw {
+			if field.normalize != nil {
+				value = field.normalize(value)
+			}
+			parsed, err := parseFilterValue(field.kind, value)
+			if err != nil {
+				return nil, apperrors.BadRequestErr.AppendMessage(condition.String() + ": " + err.Error())
+			}
+			values[i] = parsed
+		}
+		compiled = append(compiled, userCondition{Condition: condition, field: field, values: values})
+	}
+	return compiled, nil
+}
+
+func hasOperator(operators []filter.Operator, operator filter.Operator) bool {
+	for _, allowed := range operators {
+		if allowed == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilterValue reads an integer, a time as RFC 3339 or a date, which stands for its midnight in UTC, or a boolean
+func parseFilterValue(kind filterKind, value string) (interface{}, error) {
+	switch kind {
+	case filterInt:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", value)
+		}
+		return number, nil
+	case filterTime:
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a date or an RFC 3339 time", value)
+		}
+		return t, nil
+	case filterBool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", value)
+		}
+		return flag, nil
+	}
+	return value, nil
+}
+
+// applyUserFilters adds the conditions to a query on users
+func applyUserFilters(tx *gorm.DB, conditions []userCondition) *gorm.DB {
+	for _, condition := range conditions {
+		column, values := condition.field.column, condition.values
+		// Sealed values differ on every write, the blind index is what compares equal
+		if condition.field.encrypted && pii.Enabled() {
+			column, values = condition.field.indexColumn, make([]interface{}, len(condition.values))
+			for i, value := range condition.values {
+				values[i] = pii.BlindIndex(value.(string))
+			}
+		}
+
+		switch condition.Operator {
+		case filter.Eq:
+			tx = tx.Where(column+" = ?", values[0])
+		case filter.Ne:
+			tx = tx.Where(column+" <> ?", values[0])
+		case filter.Gt:
+			tx = tx.Where(column+" > ?", values[0])
+		case filter.Gte:
+			tx = tx.Where(column+" >= ?", values[0])
+		case filter.Lt:
+			tx = tx.Where(column+" < ?", values[0])
+		case filter.Lte:
+			tx = tx.Where(column+" <= ?", values[0])
+		case filter.In:
+			tx = tx.Where(column+" IN ?", values)
+		case filter.Contains:
+			tx = tx.Where("LOWER("+column+`) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(values[0].(string)))+"%")
+		}
+	}
+	return tx
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// matchesUserFilters is applyUserFilters for users held in memory
+func matchesUserFilters(user *models.User, conditions []userCondition) bool {
+	for _, condition := range conditions {
+		actual := condition.field.value(user)
+		matched := false
+		switch condition.Operator {
+		case filter.Eq:
+			matched = compareFilterValues(actual, condition.values[0]) == 0
+		case filter.Ne:
+			matched = compareFilterValues(actual, condition.values[0]) != 0
+		case filter.Gt:
+			matched = compareFilterValues(actual, condition.values[0]) > 0
+		case filter.Gte:
+			matched = compareFilterValues(actual, condition.values[0]) >= 0
+		case filter.Lt:
+			matched = compareFilterValues(actual, condition.values[0]) < 0
+		case filter.Lte:
+			matched = compareFilterValues(actual, condition.values[0]) <= 0
+		case filter.In:
+			for _, value := range condition.values {
+				matched = matched || compareFilterValues(actual, value) == 0
+			}
+		case filter.Contains:
+			matched = strings.Contains(strings.ToLower(actual.(string)), strings.ToLower(condition.values[0].(string)))
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// compareFilterValues orders two values of the same filterKind
+func compareFilterValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(int64); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		switch b := b.(time.Time); {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+		return 0
+	case bool:
+		switch b := b.(bool); {
+		case a == b:
+			return 0
+		case b:
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.(string), b.(string))
+}
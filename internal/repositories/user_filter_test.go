@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"go.uber.org/zap/zaptest"
+)
+
+var userFilterTests = []struct {
+	name    string
+	filters []filter.Condition
+	want    []string
+}{
+	{name: "role", filters: []filter.Condition{{Field: "role", Operator: filter.Eq, Value: "admin"}}, want: []string{"boss@corp.com"}},
+	{name: "role in", filters: []filter.Condition{{Field: "role", Operator: filter.In, Value: "admin, moderator"}}, want: []string{"boss@corp.com", "mod@example.com"}},
+	{name: "email in any case", filters: []filter.Condition{{Field: "email", Operator: filter.Eq, Value: "Ann@Corp.com"}}, want: []string{"ann@corp.com"}},
+	{name: "email contains", filters: []filter.Condition{{Field: "email", Operator: filter.Contains, Value: "@CORP.com"}}, want: []string{"ann@corp.com", "boss@corp.com"}},
+	{name: "wildcards are literal", filters: []filter.Condition{{Field: "email", Operator: filter.Contains, Value: "%"}}, want: []string{}},
+	{name: "rating range", filters: []filter.Condition{
+		{Field: "rating", Operator: filter.Gte, Value: "1"},
+		{Field: "rating", Operator: filter.Lt, Value: "5"},
+	}, want: []string{"mod@example.com"}},
+	{name: "created since", filters: []filter.Condition{{Field: "created_at", Operator: filter.Gte, Value: "2000-01-01"}}, want: []string{"ann@corp.com", "boss@corp.com", "mod@example.com"}},
+	{name: "created before", filters: []filter.Condition{{Field: "created_at", Operator: filter.Lt, Value: "2000-01-01T00:00:00Z"}}, want: []string{}},
+	{name: "service accounts", filters: []filter.Condition{{Field: "service_account", Operator: filter.Eq, Value: "true"}}, want: []string{"mod@example.com"}},
+	{name: "people", filters: []filter.Condition{{Field: "service_account", Operator: filter.Ne, Value: "true"}}, want: []string{"ann@corp.com", "boss@corp.com"}},
+}
+
+func seedFilterUsers(t *testing.T, repo UserRepoInterface) {
+	ctx := context.Background()
+	for _, user := range []*models.User{
+		{Email: "ann@corp.com", FirstName: "Ann", RoleID: 1},
+		{Email: "boss@corp.com", FirstName: "Boss", RoleID: 3, Rating: 7},
+		{Email: "mod@example.com", FirstName: "Mod", RoleID: 2, Rating: 2, ServiceAccount: true},
+	} {
+		_, err := repo.CreateUser(ctx, user)
+		require.NoError(t, err)
+	}
+}
+
+func emailsOf(users []models.User) []string {
+	emails := []string{}
+	for _, user := range users {
+		emails = append(emails, user.Email)
+	}
+	return emails
+}
+
+func TestUserRepo_ListUsersWithTotalFilters(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	repos := map[string]UserRepoInterface{"gorm": NewUserRepo(newTestDB(t), logger), "memory": NewMemoryUserRepo(logger)}
+
+	for name, repo := range repos {
+		seedFilterUsers(t, repo)
+
+		for _, tt := range userFilterTests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				users, total, err := repo.ListUsersWithTotal(context.Background(), 1, 10, tt.filters)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, emailsOf(users))
+				assert.Equal(t, len(tt.want), total)
+			})
+		}
+	}
+}
+
+func TestUserRepo_CountUsersFilters(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	repos := map[string]UserRepoInterface{"gorm": NewUserRepo(newTestDB(t), logger), "memory": NewMemoryUserRepo(logger)}
+
+	for name, repo := range repos {
+		seedFilterUsers(t, repo)
+
+		for _, tt := range userFilterTests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				count, err := repo.CountUsers(context.Background(), tt.filters)
+				require.NoError(t, err)
+				assert.Equal(t, len(tt.want), count)
+			})
+		}
+
+		_, err := repo.CountUsers(context.Background(), []filter.Condition{{Field: "password", Operator: filter.Eq, Value: "x"}})
+		assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), name)
+	}
+}
+
+func TestUserRepo_ListUsersWithTotalRejectsFilters(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for _, filters := range [][]filter.Condition{
+		{{Field: "password", Operator: filter.Eq, Value: "x"}},
+		{{Field: "role", Operator: filter.Contains, Value: "adm"}},
+		{{Field: "rating", Operator: filter.Gt, Value: "high"}},
+		{{Field: "created_at", Operator: filter.Gte, Value: "yesterday"}},
+		{{Field: "service_account", Operator: filter.Eq, Value: "maybe"}},
+	} {
+		_, _, err := repo.ListUsersWithTotal(ctx, 1, 10, filters)
+		assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), filters)
+	}
+}
+
+func TestUserRepo_FiltersEncryptedEmailByEquality(t *testing.T) {
+	db := newTestDB(t)
+	keyring, err := pii.NewKeyringFromConfig(
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)),
+	)
+	require.NoError(t, err)
+	pii.Use(keyring)
+	t.Cleanup(func() { pii.Use(pii.NewKeyring(nil, nil)) })
+
+	repo := NewUserRepo(db, zaptest.NewLogger(t).Sugar())
+	seedFilterUsers(t, repo)
+	ctx := context.Background()
+
+	users, _, err := repo.ListUsersWithTotal(ctx, 1, 10, []filter.Condition{{Field: "email", Operator: filter.In, Value: "ann@corp.com,mod@example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ann@corp.com", "mod@example.com"}, emailsOf(users))
+
+	_, _, err = repo.ListUsersWithTotal(ctx, 1, 10, []filter.Condition{{Field: "email", Operator: filter.Contains, Value: "@corp.com"}})
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr))
+	_, _, err = repo.ListUsersWithTotal(ctx, 1, 10, []filter.Condition{{Field: "first_name", Operator: filter.Eq, Value: "Ann"}})
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr))
+}
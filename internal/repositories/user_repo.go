@@ -7,12 +7,17 @@ import (
 
 	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// bulkInsertBatchSize keeps each INSERT well under the Postgres limit of 65535 bind parameters
+const bulkInsertBatchSize = 500
+
 type UserRepo struct {
 	db     *gorm.DB
 	logger *zap.SugaredLogger
@@ -20,13 +25,25 @@ type UserRepo struct {
 
 type UserRepoInterface interface {
 	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	CreateUsers(ctx context.Context, users []*models.User) ([]error, error)
+	CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error)
 	GetUser(ctx context.Context, userID string) (*models.User, error)
 	DeleteUser(ctx context.Context, userID string) (*models.User, error)
 	UpdateUser(ctx context.Context, userID string, updatedData *models.User) (*models.User, error)
 	ListUsers(ctx context.Context, page int, pageSize int) ([]models.User, error)
-	CountUsers(ctx context.Context) (int, error)
+	// ListUsersWithTotal returns a page of the users matching filters, see userFilterFields, with the number of
+	// them across all pages. Shadow-banned users are left out.
+	ListUsersWithTotal(ctx context.Context, page int, pageSize int, filters []filter.Condition) ([]models.User, int, error)
+	// ListUsersUpdatedSince returns up to limit users, deleted ones included, updated after since or at since
+	// with an ID above afterID, in updated_at and ID order
+	ListUsersUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]models.User, error)
+	// CountUsers counts the users that match every filter, as ListUsersWithTotal does
+	CountUsers(ctx context.Context, filters []filter.Condition) (int, error)
+	IterateUsers(ctx context.Context, batchSize int, fn func(batch []models.User) error) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByID(ctx context.Context, userID uint) (*models.User, error)
+	LockUserByID(ctx context.Context, userID uint) (*models.User, error)
+	GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error)
 }
 
 func NewUserRepo(db *gorm.DB, logger *zap.SugaredLogger) *UserRepo {
@@ -37,29 +54,134 @@ func NewUserRepo(db *gorm.DB, logger *zap.SugaredLogger) *UserRepo {
 }
 
 func (repo *UserRepo) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
-	tx := repo.db.WithContext(ctx)
-	tx.Create(user)
-	if tx.Error != nil {
-		repo.logger.Error(tx.Error)
-		return nil, apperrors.InsertionFailedErr.AppendMessage(tx.Error)
+	result := writer(ctx, repo.db).Create(user)
+	if result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			repo.logger.Warn("The email is already occupied by another user.")
+			return nil, ErrDuplicateEmail.AppendMessage(user.Email)
+		}
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.InsertionFailedErr)
 	}
 
 	return user, nil
 }
 
+// CreateUsers inserts users in batches and reports the outcome of every row: the returned slice is
+// aligned with users and holds nil for rows that were inserted, ErrDuplicateEmail for emails that
+// already exist (in the table or earlier in the input) and InsertionFailedErr for anything else.
+// The error return is reserved for failures that prevent the import as a whole.
+func (repo *UserRepo) CreateUsers(ctx context.Context, users []*models.User) ([]error, error) {
+	rowErrs := make([]error, len(users))
+
+	// Emails are stored encrypted, so duplicates are found through their blind index
+	emailIndexes := make([]string, len(users))
+	for i, user := range users {
+		emailIndexes[i] = models.EmailBlindIndex(user.Email)
+	}
+
+	var existing []string
+	err := writer(ctx, repo.db).Model(&models.User{}).Where("email_index IN ?", emailIndexes).Where(activeEmailIndexWhere).
+		Pluck("email_index", &existing).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, translateError(err, &apperrors.InsertionFailedErr)
+	}
+
+	seen := make(map[string]bool, len(users)+len(existing))
+	for _, emailIndex := range existing {
+		seen[emailIndex] = true
+	}
+
+	pending := make([]*models.User, 0, len(users))
+	pendingIdx := make([]int, 0, len(users))
+	for i, user := range users {
+		if seen[emailIndexes[i]] {
+			rowErrs[i] = ErrDuplicateEmail.AppendMessage(user.Email)
+			continue
+		}
+		seen[emailIndexes[i]] = true
+		pending = append(pending, user)
+		pendingIdx = append(pendingIdx, i)
+	}
+	if len(pending) == 0 {
+		return rowErrs, nil
+	}
+
+	// Fast path: the whole remainder goes in at once. It is all-or-nothing, so if a row still fails
+	// (a concurrent insert, a constraint) fall back to row-by-row inserts to find out which one.
+	err = writer(ctx, repo.db).CreateInBatches(pending, bulkInsertBatchSize).Error
+	if err == nil {
+		return rowErrs, nil
+	}
+	repo.logger.Warnw("Bulk insert failed, retrying row by row", "error", err)
+
+	for i, user := range pending {
+		// Ids assigned to the rolled back batch are not valid any more
+		user.ID = 0
+		err = writer(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+			return tx.Create(user).Error
+		})
+		switch {
+		case err == nil:
+		case isUniqueViolation(err):
+			rowErrs[pendingIdx[i]] = ErrDuplicateEmail.AppendMessage(user.Email)
+		default:
+			rowErrs[pendingIdx[i]] = translateError(err, &apperrors.InsertionFailedErr)
+		}
+	}
+
+	return rowErrs, nil
+}
+
+// CreateOrUpdateByEmail inserts the user or, when a user that is not deleted has the email, overwrites its names in
+// a single INSERT ... ON CONFLICT (tenant_id, email_index) so repeated pushes of the same payload are idempotent.
+// The stored password and role of an existing user are left alone; the email of a deleted user gets a new user.
+// The bool reports whether a new user was created.
+func (repo *UserRepo) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	tx := writer(ctx, repo.db)
+
+	var existing int64
+	emailIndex := models.EmailBlindIndex(user.Email)
+	err := tx.Model(&models.User{}).Where("email_index = ?", emailIndex).Where(activeEmailIndexWhere).Count(&existing).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, false, translateError(err, &apperrors.InsertionFailedErr)
+	}
+
+	err = tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "tenant_id"}, {Name: "email_index"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: activeEmailIndexWhere}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"first_name", "last_name", "updated_at"}),
+	}).Create(user).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, false, translateError(err, &apperrors.InsertionFailedErr)
+	}
+
+	var stored models.User
+	err = tx.Preload("Role").Where("email_index = ?", emailIndex).Where(activeEmailIndexWhere).First(&stored).Error
+	if err != nil {
+		repo.logger.Error(err)
+		return nil, false, translateError(err, &apperrors.InsertionFailedErr)
+	}
+
+	return &stored, existing == 0, nil
+}
+
 func (repo *UserRepo) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	tx := repo.db.WithContext(ctx)
+	tx := reader(ctx, repo.db)
 	var user models.User
 
 	// Fetch the user to be updated
 	result := tx.First(&user, "id = ? AND (deleted_at IS NULL OR deleted_at = ?)", userID, time.Time{})
 	if result.Error != nil {
-		if result.RowsAffected == 0 {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			repo.logger.Warn("No user found with the given ID.")
-			return nil, apperrors.NoRecordFoundErr.AppendMessage("No user found with the given ID.")
+			return nil, ErrNotFound.AppendMessage("No user found with the given ID.")
 		}
 		repo.logger.Error(result.Error)
-		return nil, apperrors.DeletionFailedErr.AppendMessage(result.Error.Error())
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 
 	return &user, nil
@@ -70,7 +192,7 @@ func (repo *UserRepo) DeleteUser(ctx context.Context, userID string) (*models.Us
 }
 
 func (repo *UserRepo) UpdateUser(ctx context.Context, userID string, updatedData *models.User) (*models.User, error) {
-	tx := repo.db.WithContext(ctx)
+	tx := writer(ctx, repo.db)
 
 	// Step 1: Fetch the user to be updated
 	user, err := repo.fetchUser(tx, userID)
@@ -98,26 +220,20 @@ func (repo *UserRepo) fetchUser(tx *gorm.DB, userID string) (*models.User, error
 	var user models.User
 	result := tx.First(&user, "id = ? AND (deleted_at IS NULL OR deleted_at = ?)", userID, time.Time{})
 	if result.Error != nil {
-		if result.RowsAffected == 0 {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			repo.logger.Warn("No user found with the given ID.")
-			return nil, apperrors.NoRecordFoundErr.AppendMessage("No user found with the given ID.")
+			return nil, ErrNotFound.AppendMessage("No user found with the given ID.")
 		}
 		repo.logger.Error(result.Error)
-		return nil, apperrors.DeletionFailedErr.AppendMessage(result.Error.Error())
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 	return &user, nil
 }
 
 // Step 2: Apply updates to the user object
 func (repo *UserRepo) applyUserUpdates(tx *gorm.DB, user *models.User, updatedData *models.User) error {
-	// Check email uniqueness if it changes
-	if updatedData.Email != "" && updatedData.Email != user.Email {
-		var existingUser models.User
-		result := tx.First(&existingUser, "email = ?", updatedData.Email)
-		if result.RowsAffected > 0 {
-			repo.logger.Warn("The email is already occupied by another user.")
-			return apperrors.DeletionFailedErr.AppendMessage("The email is already occupied by another user.")
-		}
+	// A taken email is rejected by users_tenant_email_key when the user is saved, checking first would race
+	if updatedData.Email != "" {
 		user.Email = updatedData.Email
 	}
 
@@ -137,6 +253,12 @@ func (repo *UserRepo) applyUserUpdates(tx *gorm.DB, user *models.User, updatedDa
 	if updatedData.RoleID > 0 {
 		user.RoleID = updatedData.RoleID
 	}
+	if updatedData.Timezone != "" {
+		user.Timezone = updatedData.Timezone
+	}
+	if updatedData.Locale != "" {
+		user.Locale = updatedData.Locale
+	}
 
 	return nil
 }
@@ -145,15 +267,19 @@ func (repo *UserRepo) applyUserUpdates(tx *gorm.DB, user *models.User, updatedDa
 func (repo *UserRepo) saveUser(tx *gorm.DB, user *models.User) error {
 	result := tx.Save(&user)
 	if result.Error != nil {
+		if isDuplicateEmail(result.Error) {
+			repo.logger.Warn("The email is already occupied by another user.")
+			return ErrDuplicateEmail.AppendMessage("The email is already occupied by another user.")
+		}
 		repo.logger.Error(result.Error)
-		return apperrors.DeletionFailedErr.AppendMessage(result.Error.Error())
+		return translateError(result.Error, &apperrors.UpdateFailedErr)
 	}
 	return nil
 }
 
 func (repo *UserRepo) ListUsers(ctx context.Context, page int, pageSize int) ([]models.User, error) {
 	var users []models.User
-	tx := repo.db.WithContext(ctx)
+	tx := reader(ctx, repo.db)
 
 	// Calculate offset for pagination
 	offset := (page - 1) * pageSize
@@ -161,47 +287,167 @@ func (repo *UserRepo) ListUsers(ctx context.Context, page int, pageSize int) ([]
 	result := tx.Limit(pageSize).Offset(offset).Preload("Role").Find(&users, "deleted_at IS NULL OR deleted_at = ?", time.Time{})
 	if result.Error != nil {
 		repo.logger.Error(result.Error)
-		return nil, apperrors.DeletionFailedErr.AppendMessage(result.Error.Error())
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 
 	return users, nil
 }
 
-func (repo *UserRepo) CountUsers(ctx context.Context) (int, error) {
+// userWithTotal carries the COUNT(*) OVER() column alongside each user row
+type userWithTotal struct {
+	models.User
+	Total int `gorm:"column:total"`
+}
+
+// ListUsersWithTotal returns one page of users together with the number of users across all pages,
+// computed by a window function in the same query
+func (repo *UserRepo) ListUsersWithTotal(ctx context.Context, page int, pageSize int, filters []filter.Condition) ([]models.User, int, error) {
+	conditions, err := compileUserFilters(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rows []userWithTotal
+	tx := reader(ctx, repo.db)
+
+	offset := (page - 1) * pageSize
+
+	result := applyUserFilters(tx.Model(&models.User{}), conditions).
+		Select("users.*, COUNT(*) OVER() AS total").
+		Where("(users.deleted_at IS NULL OR users.deleted_at = ?)", time.Time{}).
+		Where("users.id NOT IN (" + models.ShadowBannedUsers + ")").
+		Order("id").
+		Limit(pageSize).
+		Offset(offset).
+		Preload("Role").
+		Find(&rows)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, 0, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+
+	// A page past the end has no rows to carry the total, so count separately
+	if len(rows) == 0 {
+		total, err := repo.countUsers(ctx, conditions)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []models.User{}, total, nil
+	}
+
+	users := make([]models.User, len(rows))
+	for i := range rows {
+		users[i] = rows[i].User
+	}
+	return users, rows[0].Total, nil
+}
+
+func (repo *UserRepo) ListUsersUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	result := reader(ctx, repo.db).
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", since, since, afterID).
+		Order("updated_at").
+		Order("id").
+		Limit(limit).
+		Preload("Role").
+		Find(&users)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return users, nil
+}
+
+// IterateUsers walks all users in primary key order, handing fn one batch at a time so exports and
+// background jobs never hold the whole table in memory. Returning an error from fn stops the scan.
+func (repo *UserRepo) IterateUsers(ctx context.Context, batchSize int, fn func(batch []models.User) error) error {
+	var batch []models.User
+	result := reader(ctx, repo.db).
+		Where("deleted_at IS NULL OR deleted_at = ?", time.Time{}).
+		Preload("Role").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+func (repo *UserRepo) CountUsers(ctx context.Context, filters []filter.Condition) (int, error) {
+	conditions, err := compileUserFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+	return repo.countUsers(ctx, conditions)
+}
+
+func (repo *UserRepo) countUsers(ctx context.Context, conditions []userCondition) (int, error) {
 	var count int64
-	tx := repo.db.WithContext(ctx)
-	result := tx.Model(&models.User{}).Where("deleted_at IS NULL OR deleted_at = ?", time.Time{}).Count(&count)
+	result := applyUserFilters(reader(ctx, repo.db).Model(&models.User{}), conditions).
+		Where("(users.deleted_at IS NULL OR users.deleted_at = ?)", time.Time{}).
+		Where("users.id NOT IN (" + models.ShadowBannedUsers + ")").
+		Count(&count)
 	if result.Error != nil {
 		repo.logger.Error(result.Error)
-		return 0, apperrors.DeletionFailedErr.AppendMessage(result.Error.Error())
+		return 0, translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 	return int(count), nil
 }
 
+// GetUserByEmail returns ErrNotFound when no active user has the email
 func (repo *UserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	tx := repo.db.WithContext(ctx).
-		Where("email = ? AND (deleted_at IS NULL OR deleted_at = ?)", email, time.Time{}).
+	tx := reader(ctx, repo.db).
+		Where("email_index = ? AND (deleted_at IS NULL OR deleted_at = ?)", models.EmailBlindIndex(email), time.Time{}).
 		Preload("Role").
 		First(&user)
 	if tx.Error != nil {
-		if tx.RowsAffected == 0 {
-			return nil, nil // No user found
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound.AppendMessage("User not found.")
 		}
 		repo.logger.Error(tx.Error)
-		return nil, tx.Error
+		return nil, translateError(tx.Error, &apperrors.QueryFailedErr)
 	}
 	return &user, nil
 }
 
 func (repo *UserRepo) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
 	var user models.User
-	result := repo.db.WithContext(ctx).First(&user, userID)
+	result := reader(ctx, repo.db).First(&user, userID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound.AppendMessage("User not found.")
+		}
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return &user, nil
+}
+
+// LockUserByID reads the user with SELECT ... FOR UPDATE so concurrent transactions touching the same
+// row wait for this one to finish. It only holds the lock when ctx carries a transaction (see TxManager).
+func (repo *UserRepo) LockUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	var user models.User
+	result := writer(ctx, repo.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, apperrors.NoRecordFoundErr.AppendMessage("User not found.")
+			return nil, ErrNotFound.AppendMessage("User not found.")
 		}
-		return nil, result.Error
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 	return &user, nil
 }
+
+// GetUserHistory returns the recorded prior versions of the user, newest first
+func (repo *UserRepo) GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error) {
+	history := []models.UserHistory{}
+	result := reader(ctx, repo.db).Where("user_id = ?", userID).Order("id DESC").Find(&history)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return history, nil
+}
@@ -0,0 +1,464 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/database"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a private in-memory SQLite database with the schema applied
+func newTestDB(t *testing.T) *gorm.DB {
+	cfg := &config.Config{
+		DBDriver:       config.DriverSQLite,
+		SqliteDSN:      fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()),
+		DBMaxOpenConns: 1,
+		// The in-memory database is dropped as soon as its last connection closes
+		DBMaxIdleConns: 1,
+		DBLogLevel:     "silent",
+	}
+
+	db, err := database.SetupDatabase(cfg, zaptest.NewLogger(t).Sugar())
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(db))
+
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
+
+func createTestUser(t *testing.T, repo UserRepoInterface, email string) *models.User {
+	user, err := repo.CreateUser(context.Background(), &models.User{
+		Email:     email,
+		FirstName: "Test",
+		LastName:  "User",
+		Password:  "hash",
+		RoleID:    1,
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func TestUserRepo_CreateAndGetUser(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	created := createTestUser(t, repo, "a@example.com")
+	assert.NotZero(t, created.ID)
+
+	user, err := repo.GetUser(ctx, fmt.Sprint(created.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", user.Email)
+
+	byEmail, err := repo.GetUserByEmail(ctx, "a@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, byEmail.ID)
+	assert.Equal(t, models.StrUser, byEmail.Role.Name)
+
+	_, err = repo.CreateUser(ctx, &models.User{Email: "a@example.com", RoleID: 1})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+}
+
+func TestUserRepo_ReturnsTypedErrors(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	_, err := repo.GetUserByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = repo.GetUser(ctx, "999")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.NoRecordFoundErr.Code, appErr.Code)
+
+	first := createTestUser(t, repo, "first@example.com")
+	createTestUser(t, repo, "second@example.com")
+	_, err = repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{Email: "second@example.com"})
+	assert.ErrorIs(t, err, ErrDuplicateEmail)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestUserRepo_UpdateUser(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	first := createTestUser(t, repo, "a@example.com")
+	createTestUser(t, repo, "b@example.com")
+
+	updated, err := repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{FirstName: "Renamed"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.FirstName)
+	assert.Equal(t, "a@example.com", updated.Email)
+
+	_, err = repo.UpdateUser(ctx, fmt.Sprint(first.ID), &models.User{Email: "b@example.com"})
+	assert.Error(t, err)
+}
+
+func TestUserRepo_DeleteHidesUser(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	user := createTestUser(t, repo, "a@example.com")
+	createTestUser(t, repo, "b@example.com")
+
+	deleted, err := repo.DeleteUser(ctx, fmt.Sprint(user.ID))
+	assert.NoError(t, err)
+	assert.False(t, deleted.DeletedAt.IsZero())
+
+	_, err = repo.GetUser(ctx, fmt.Sprint(user.ID))
+	assert.True(t, apperrors.Is(err, &apperrors.NoRecordFoundErr))
+
+	count, err := repo.CountUsers(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	users, err := repo.ListUsers(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestUserRepo_EmailsIgnoreCase(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	repos := map[string]UserRepoInterface{"gorm": NewUserRepo(newTestDB(t), logger), "memory": NewMemoryUserRepo(logger)}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			user := createTestUser(t, repo, "User@Example.com")
+			assert.Equal(t, "user@example.com", user.Email)
+
+			byEmail, err := repo.GetUserByEmail(ctx, "USER@example.COM")
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, byEmail.ID)
+			assert.Equal(t, "user@example.com", byEmail.Email)
+
+			_, err = repo.CreateUser(ctx, &models.User{Email: "user@EXAMPLE.com", Password: "hash", RoleID: 1})
+			assert.ErrorIs(t, err, ErrDuplicateEmail)
+			other := createTestUser(t, repo, "other@example.com")
+			_, err = repo.UpdateUser(ctx, fmt.Sprint(other.ID), &models.User{Email: "User@example.com"})
+			assert.ErrorIs(t, err, ErrDuplicateEmail)
+
+			pushed, created, err := repo.CreateOrUpdateByEmail(ctx, &models.User{Email: "USER@example.com", FirstName: "Pushed", Password: "hash", RoleID: 1})
+			require.NoError(t, err)
+			assert.False(t, created)
+			assert.Equal(t, user.ID, pushed.ID)
+		})
+	}
+}
+
+func TestUserRepo_DeletedUsersFreeTheirEmail(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	repos := map[string]UserRepoInterface{"gorm": NewUserRepo(newTestDB(t), logger), "memory": NewMemoryUserRepo(logger)}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			deleted := createTestUser(t, repo, "a@example.com")
+			other := createTestUser(t, repo, "b@example.com")
+			_, err := repo.DeleteUser(ctx, fmt.Sprint(deleted.ID))
+			require.NoError(t, err)
+
+			again := createTestUser(t, repo, "a@example.com")
+			assert.NotEqual(t, deleted.ID, again.ID)
+
+			// Taken again by a user that is not deleted
+			_, err = repo.UpdateUser(ctx, fmt.Sprint(other.ID), &models.User{Email: "a@example.com"})
+			assert.ErrorIs(t, err, ErrDuplicateEmail)
+			var appErr *apperrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, http.StatusConflict, appErr.HTTPCode)
+
+			_, err = repo.DeleteUser(ctx, fmt.Sprint(again.ID))
+			require.NoError(t, err)
+			pushed, created, err := repo.CreateOrUpdateByEmail(ctx, &models.User{Email: "a@example.com", FirstName: "Pushed", Password: "hash", RoleID: 1})
+			require.NoError(t, err)
+			assert.True(t, created)
+			assert.Equal(t, "Pushed", pushed.FirstName)
+		})
+	}
+}
+
+func TestUserRepo_ListUsersPaginates(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		createTestUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+	}
+
+	page, err := repo.ListUsers(ctx, 2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+
+	last, err := repo.ListUsers(ctx, 3, 2)
+	assert.NoError(t, err)
+	assert.Len(t, last, 1)
+}
+
+func TestUserRepo_ListUsersWithTotal(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		createTestUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+	}
+
+	users, total, err := repo.ListUsersWithTotal(ctx, 2, 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, "user2@example.com", users[0].Email)
+		assert.Equal(t, models.StrUser, users[0].Role.Name)
+	}
+
+	users, total, err = repo.ListUsersWithTotal(ctx, 4, 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Empty(t, users)
+}
+
+func TestUserRepo_ListUsersUpdatedSince(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepo(db, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	since := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for i, updatedAt := range []time.Time{since.Add(-time.Hour), since, since, since.Add(time.Hour)} {
+		user := createTestUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+		require.NoError(t, db.Model(user).UpdateColumn("updated_at", updatedAt).Error)
+	}
+	deleted, err := repo.DeleteUser(ctx, "4")
+	require.NoError(t, err)
+
+	users, err := repo.ListUsersUpdatedSince(ctx, since, 2, 10)
+	require.NoError(t, err)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, uint(3), users[0].ID, "ties with the cursor time continue after its ID")
+		assert.Equal(t, models.StrUser, users[0].Role.Name)
+		assert.Equal(t, deleted.ID, users[1].ID, "deleted users are included")
+		assert.False(t, users[1].DeletedAt.IsZero())
+	}
+
+	users, err = repo.ListUsersUpdatedSince(ctx, time.Time{}, 0, 2)
+	require.NoError(t, err)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, []uint{1, 2}, []uint{users[0].ID, users[1].ID})
+	}
+}
+
+func TestUserRepo_IterateUsers(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		createTestUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+	}
+
+	var batchSizes []int
+	var emails []string
+	err := repo.IterateUsers(ctx, 2, func(batch []models.User) error {
+		batchSizes = append(batchSizes, len(batch))
+		for _, user := range batch {
+			emails = append(emails, user.Email)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, batchSizes)
+	assert.Len(t, emails, 5)
+	assert.Equal(t, "user0@example.com", emails[0])
+}
+
+func TestUserRepo_IterateUsersStopsOnError(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		createTestUser(t, repo, fmt.Sprintf("user%d@example.com", i))
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := repo.IterateUsers(ctx, 2, func(batch []models.User) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestUserRepo_LockUserByIDInsideTransaction(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	repo := NewUserRepo(db, logger)
+	user := createTestUser(t, repo, "a@example.com")
+
+	err := NewTxManager(db, logger).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		locked, err := repo.LockUserByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, user.Email, locked.Email)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.LockUserByID(context.Background(), user.ID+100)
+	assert.True(t, apperrors.Is(err, &apperrors.NoRecordFoundErr))
+}
+
+func TestUserRepo_CreateUsersReportsDuplicates(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	createTestUser(t, repo, "taken@example.com")
+
+	newUser := func(email string) *models.User {
+		return &models.User{Email: email, FirstName: "Bulk", LastName: "User", Password: "hash", RoleID: 1}
+	}
+	users := []*models.User{
+		newUser("a@example.com"),
+		newUser("taken@example.com"),
+		newUser("b@example.com"),
+		newUser("a@example.com"),
+	}
+
+	rowErrs, err := repo.CreateUsers(ctx, users)
+	require.NoError(t, err)
+	require.Len(t, rowErrs, 4)
+	assert.NoError(t, rowErrs[0])
+	assert.True(t, apperrors.Is(rowErrs[1], &apperrors.DuplicateEmailErr))
+	assert.NoError(t, rowErrs[2])
+	assert.True(t, apperrors.Is(rowErrs[3], &apperrors.DuplicateEmailErr))
+	assert.NotZero(t, users[0].ID)
+
+	count, err := repo.CountUsers(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestUserRepo_CreateOrUpdateByEmailIsIdempotent(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	payload := func(firstName string) *models.User {
+		return &models.User{Email: "hr@example.com", FirstName: firstName, LastName: "Sync", Password: "hash", RoleID: 1}
+	}
+
+	first, created, err := repo.CreateOrUpdateByEmail(ctx, payload("Ann"))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	second, created, err := repo.CreateOrUpdateByEmail(ctx, payload("Anna"))
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, "Anna", second.FirstName)
+
+	count, err := repo.CountUsers(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestUserRepo_UpdateAndDeleteRecordHistory(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	user := createTestUser(t, repo, "a@example.com")
+	userID := fmt.Sprint(user.ID)
+
+	_, err := repo.UpdateUser(ctx, userID, &models.User{FirstName: "Renamed"})
+	require.NoError(t, err)
+	_, err = repo.DeleteUser(ctx, userID)
+	require.NoError(t, err)
+
+	history, err := repo.GetUserHistory(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.Equal(t, models.HistoryOperationDelete, history[0].Operation)
+	assert.Equal(t, "Renamed", history[0].FirstName)
+	assert.True(t, history[0].DeletedAt.IsZero())
+
+	assert.Equal(t, models.HistoryOperationUpdate, history[1].Operation)
+	assert.Equal(t, "Test", history[1].FirstName)
+}
+
+func TestUserRepo_ScopesQueriesToTenant(t *testing.T) {
+	repo := NewUserRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	tenantA := tenancy.WithTenant(context.Background(), 1)
+	tenantB := tenancy.WithTenant(context.Background(), 2)
+
+	newUser := func() *models.User {
+		return &models.User{Email: "same@example.com", FirstName: "Test", LastName: "User", Password: "hash", RoleID: 1}
+	}
+	userA, err := repo.CreateUser(tenantA, newUser())
+	require.NoError(t, err)
+	// The same email may exist once per tenant
+	userB, err := repo.CreateUser(tenantB, newUser())
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), userB.TenantID)
+
+	found, err := repo.GetUserByEmail(tenantA, "same@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, userA.ID, found.ID)
+
+	_, err = repo.GetUser(tenantA, fmt.Sprint(userB.ID))
+	assert.True(t, apperrors.Is(err, &apperrors.NoRecordFoundErr))
+
+	_, err = repo.UpdateUser(tenantA, fmt.Sprint(userB.ID), &models.User{FirstName: "Hijacked"})
+	assert.Error(t, err)
+
+	count, err := repo.CountUsers(tenantB, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Without a tenant in the context every tenant is visible
+	count, err = repo.CountUsers(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestUserRepo_EncryptsPersonalData(t *testing.T) {
+	db := newTestDB(t)
+	keyring, err := pii.NewKeyringFromConfig(
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)),
+	)
+	require.NoError(t, err)
+	pii.Use(keyring)
+	t.Cleanup(func() { pii.Use(pii.NewKeyring(nil, nil)) })
+
+	repo := NewUserRepo(db, zaptest.NewLogger(t).Sugar())
+	user := createTestUser(t, repo, "secret@example.com")
+
+	var stored struct{ Email, FirstName string }
+	require.NoError(t, db.Raw("SELECT email, first_name FROM users WHERE id = ?", user.ID).Scan(&stored).Error)
+	assert.True(t, strings.HasPrefix(stored.Email, pii.CiphertextPrefix))
+	assert.NotContains(t, stored.Email, "secret")
+	assert.True(t, strings.HasPrefix(stored.FirstName, pii.CiphertextPrefix))
+
+	found, err := repo.GetUserByEmail(context.Background(), "secret@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "secret@example.com", found.Email)
+	assert.Equal(t, "Test", found.FirstName)
+}
@@ -31,45 +31,45 @@ func NewVoteRepo(db *gorm.DB, logger *zap.SugaredLogger) *VoteRepo {
 
 func (repo *VoteRepo) GetVote(ctx context.Context, userID uint, profileID uint) (*models.Vote, error) {
 	var vote models.Vote
-	result := repo.db.WithContext(ctx).Where("user_id = ? AND profile_id = ?", userID, profileID).First(&vote)
+	result := reader(ctx, repo.db).Where("user_id = ? AND profile_id = ?", userID, profileID).First(&vote)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 	return &vote, nil
 }
 
 func (repo *VoteRepo) CreateVote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
-	if err := repo.db.WithContext(ctx).Create(vote).Error; err != nil {
+	if err := writer(ctx, repo.db).Create(vote).Error; err != nil {
 		repo.logger.Error("Failed to create vote", zap.Error(err))
-		return nil, err
+		return nil, translateError(err, &apperrors.InsertionFailedErr)
 	}
 	return vote, nil
 }
 
 func (repo *VoteRepo) UpdateVote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
-	if err := repo.db.WithContext(ctx).Save(vote).Error; err != nil {
+	if err := writer(ctx, repo.db).Save(vote).Error; err != nil {
 		repo.logger.Error("Failed to update vote", zap.Error(err))
-		return nil, err
+		return nil, translateError(err, &apperrors.UpdateFailedErr)
 	}
 	return vote, nil
 }
 
 func (repo *VoteRepo) DeleteVote(ctx context.Context, userID uint, profileID uint) error {
-	tx := repo.db.WithContext(ctx)
+	tx := writer(ctx, repo.db)
 
 	// Find the vote
 	var vote models.Vote
 	result := tx.Where("user_id = ? AND profile_id = ?", userID, profileID).First(&vote)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return apperrors.NoRecordFoundErr.AppendMessage("Vote not found.")
+			return ErrNotFound.AppendMessage("Vote not found.")
 		}
-		return result.Error
+		return translateError(result.Error, &apperrors.QueryFailedErr)
 	}
 
 	// Delete the vote
 	if err := tx.Delete(&vote).Error; err != nil {
-		return apperrors.DeletionFailedErr.AppendMessage(err.Error())
+		return translateError(err, &apperrors.DeletionFailedErr)
 	}
 
 	return nil
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestVoteRepo_KeepsRatingInStep(t *testing.T) {
+	db := newTestDB(t)
+	logger := zaptest.NewLogger(t).Sugar()
+	users := NewUserRepo(db, logger)
+	votes := NewVoteRepo(db, logger)
+	ctx := context.Background()
+
+	profile := createTestUser(t, users, "profile@example.com")
+	voter := createTestUser(t, users, "voter@example.com")
+	rating := func() int {
+		user, err := users.GetUserByID(ctx, profile.ID)
+		require.NoError(t, err)
+		return user.Rating
+	}
+
+	_, err := votes.CreateVote(ctx, &models.Vote{UserID: voter.ID, ProfileID: profile.ID, Value: 1, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rating())
+
+	require.NoError(t, votes.DeleteVote(ctx, voter.ID, profile.ID))
+	assert.Equal(t, 0, rating(), "a revoked vote no longer counts")
+
+	err = votes.DeleteVote(ctx, voter.ID, profile.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
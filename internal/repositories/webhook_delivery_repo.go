@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type WebhookDeliveryRepo struct {
+	db     *gorm.DB
+	logger *zap.SugaredLogger
+}
+
+type WebhookDeliveryRepoInterface interface {
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// ListWebhookDeliveries returns the latest attempts, of one event or of all when eventID is empty
+	ListWebhookDeliveries(ctx context.Context, eventID string, limit int) ([]models.WebhookDelivery, error)
+	// DeleteWebhookDeliveries drops up to limit attempts made before createdBefore and returns how many
+	DeleteWebhookDeliveries(ctx context.Context, createdBefore time.Time, limit int) (int, error)
+}
+
+func NewWebhookDeliveryRepo(db *gorm.DB, logger *zap.SugaredLogger) *WebhookDeliveryRepo {
+	return &WebhookDeliveryRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (repo *WebhookDeliveryRepo) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	result := writer(ctx, repo.db).Create(delivery)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return translateError(result.Error, &apperrors.InsertionFailedErr)
+	}
+	return nil
+}
+
+func (repo *WebhookDeliveryRepo) ListWebhookDeliveries(ctx context.Context, eventID string, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	tx := reader(ctx, repo.db).Order("created_at DESC, id DESC").Limit(limit)
+	if eventID != "" {
+		tx = tx.Where("event_id = ?", eventID)
+	}
+	result := tx.Find(&deliveries)
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return nil, translateError(result.Error, &apperrors.QueryFailedErr)
+	}
+	return deliveries, nil
+}
+
+func (repo *WebhookDeliveryRepo) DeleteWebhookDeliveries(ctx context.Context, createdBefore time.Time, limit int) (int, error) {
+	batch := writer(ctx, repo.db).Model(&models.WebhookDelivery{}).Select("id").
+		Where("created_at < ?", createdBefore).
+		Order("id").
+		Limit(limit)
+	result := writer(ctx, repo.db).Where("id IN (?)", batch).Delete(&models.WebhookDelivery{})
+	if result.Error != nil {
+		repo.logger.Error(result.Error)
+		return 0, translateError(result.Error, &apperrors.DeletionFailedErr)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWebhookDeliveryRepo_ListsLatestAttemptsFirst(t *testing.T) {
+	repo := NewWebhookDeliveryRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, delivery := range []*models.WebhookDelivery{
+		{EventID: "a", URL: "https://consumer.example.com", Attempt: 1, StatusCode: 503, Error: "status 503"},
+		{EventID: "a", URL: "https://consumer.example.com", Attempt: 2, StatusCode: 200, Succeeded: true},
+		{EventID: "b", URL: "https://consumer.example.com", Attempt: 1, StatusCode: 204, Succeeded: true},
+	} {
+		delivery.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.CreateWebhookDelivery(ctx, delivery))
+	}
+
+	deliveries, err := repo.ListWebhookDeliveries(ctx, "a", 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.True(t, deliveries[0].Succeeded)
+	assert.Equal(t, 503, deliveries[1].StatusCode)
+
+	deliveries, err = repo.ListWebhookDeliveries(ctx, "", 1)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "b", deliveries[0].EventID)
+}
+
+func TestWebhookDeliveryRepo_DeletesOldAttemptsInBatches(t *testing.T) {
+	repo := NewWebhookDeliveryRepo(newTestDB(t), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, createdAt := range []time.Time{now.Add(-48 * time.Hour), now.Add(-47 * time.Hour), now} {
+		require.NoError(t, repo.CreateWebhookDelivery(ctx, &models.WebhookDelivery{EventID: "a", URL: "https://consumer.example.com", Attempt: 1, CreatedAt: createdAt}))
+	}
+
+	deleted, err := repo.DeleteWebhookDeliveries(ctx, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	deleted, err = repo.DeleteWebhookDeliveries(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	deliveries, err := repo.ListWebhookDeliveries(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
@@ -0,0 +1,251 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AWSCredentials are static (or STS session) credentials used to sign requests
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is when temporary credentials stop working; zero for static ones
+	Expires time.Time
+}
+
+// AWSClient calls the JSON APIs of Secrets Manager and SSM Parameter Store, signed with Signature Version 4
+type AWSClient struct {
+	region      string
+	credentials AWSCredentialsProvider
+	httpClient  *http.Client
+	// endpoint returns the base URL of a service in a region; overridden in tests
+	endpoint func(service, region string) string
+	now      func() time.Time
+}
+
+func NewAWSClient(region string, credentials AWSCredentialsProvider) *AWSClient {
+	return &AWSClient{
+		region:      region,
+		credentials: credentials,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		endpoint: func(service, region string) string {
+			return fmt.Sprintf("https://%s.%s.amazonaws.com", service, region)
+		},
+		now: time.Now,
+	}
+}
+
+// SecretsManager returns a Provider for "aws-sm:<secret id or ARN>[#json field]" references.
+// JSON secrets, such as the ones RDS rotation maintains, need the field, e.g. "#password".
+func (c *AWSClient) SecretsManager() Provider {
+	return awsProvider(func(ctx context.Context, ref string) (string, error) {
+		secretID, field, _ := strings.Cut(ref, "#")
+
+		var out struct {
+			SecretString string
+		}
+		err := c.call(ctx, "secretsmanager", regionOf(secretID, c.region), "secretsmanager.GetSecretValue",
+			map[string]string{"SecretId": secretID}, &out)
+		if err != nil {
+			return "", err
+		}
+		if field == "" {
+			return out.SecretString, nil
+		}
+
+		var fields map[string]interface{}
+		err = json.Unmarshal([]byte(out.SecretString), &fields)
+		if err != nil {
+			return "", errors.Wrapf(err, "secret %s is not a JSON object", secretID)
+		}
+		value, ok := fields[field]
+		if !ok {
+			return "", errors.Errorf("secret %s has no field %q", secretID, field)
+		}
+		return fmt.Sprint(value), nil
+	})
+}
+
+// ParameterStore returns a Provider for "aws-ssm:<parameter name or ARN>" references, decrypting SecureStrings
+func (c *AWSClient) ParameterStore() Provider {
+	return awsProvider(func(ctx context.Context, ref string) (string, error) {
+		var out struct {
+			Parameter struct {
+				Value string
+			}
+		}
+		err := c.call(ctx, "ssm", regionOf(ref, c.region), "AmazonSSM.GetParameter",
+			map[string]interface{}{"Name": ref, "WithDecryption": true}, &out)
+		if err != nil {
+			return "", err
+		}
+		return out.Parameter.Value, nil
+	})
+}
+
+type awsProvider func(ctx context.Context, ref string) (string, error)
+
+func (p awsProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	return p(ctx, ref)
+}
+
+// regionOf takes the region from an ARN ("arn:aws:service:region:account:..."), falling back to the default
+func regionOf(ref, fallback string) string {
+	parts := strings.SplitN(ref, ":", 5)
+	if len(parts) == 5 && parts[0] == "arn" && parts[3] != "" {
+		return parts[3]
+	}
+	return fallback
+}
+
+func (c *AWSClient) call(ctx context.Context, service, region, target string, input, output interface{}) error {
+	if region == "" {
+		return errors.New("aws: AWS_REGION is not set and the reference is not an ARN")
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(service, region)+"/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	return c.do(req, payload, service, region, target, output)
+}
+
+// CallREST calls a REST JSON API such as SES v2 in the configured region; host is the endpoint prefix
+// ("email" for SES) and service the signing name ("ses")
+func (c *AWSClient) CallREST(ctx context.Context, host, service, method, path string, input, output interface{}) error {
+	if c.region == "" {
+		return errors.New("aws: AWS_REGION is not set")
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint(host, c.region)+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, payload, service, c.region, method+" "+path, output)
+}
+
+// do signs and sends req, decoding a successful answer into output; operation names the call in errors
+func (c *AWSClient) do(req *http.Request, payload []byte, service, region, operation string, output interface{}) error {
+	credentials, err := c.credentials.Retrieve(req.Context())
+	if err != nil {
+		return err
+	}
+	c.sign(req, payload, service, region, credentials)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "aws")
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "aws")
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(body, &apiErr)
+		if apiErr.Type == "" {
+			// REST APIs name the error in a header instead
+			apiErr.Type = res.Header.Get("X-Amzn-Errortype")
+		}
+		return errors.Errorf("aws: %s: %d %s %s", operation, res.StatusCode, apiErr.Type, apiErr.Message)
+	}
+	if output == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, output)
+}
+
+// sign adds the Signature Version 4 headers for a request whose only signed headers are host, the
+// content type, the target (when set) and the x-amz ones
+func (c *AWSClient) sign(req *http.Request, payload []byte, service, region string, credentials AWSCredentials) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if credentials.SessionToken != "" {
+		headers["x-amz-security-token"] = credentials.SessionToken
+	}
+	names := []string{"content-type", "host", "x-amz-date"}
+	if credentials.SessionToken != "" {
+		names = append(names, "x-amz-security-token")
+	}
+	if headers["x-amz-target"] != "" {
+		names = append(names, "x-amz-target")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, hexSHA256(payload),
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), day)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
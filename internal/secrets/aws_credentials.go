@@ -0,0 +1,364 @@
+package secrets
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AWSCredentialsProvider retrieves the credentials requests are signed with
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+// Retrieve makes static credentials a provider of themselves
+func (c AWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return c, nil
+}
+
+// credentialsExpiryWindow renews temporary credentials that long before they expire, so a request signed with them
+// never arrives late
+const credentialsExpiryWindow = 5 * time.Minute
+
+// The link-local endpoints of the ECS/EKS container credentials and of the EC2 instance metadata service
+const (
+	containerCredentialsHost = "http://169.254.170.2"
+	instanceMetadataHost     = "http://169.254.169.254"
+)
+
+// AWSCredentialChain finds credentials the way the AWS SDKs' default chain does: the static credentials of the
+// configuration (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN), the shared credentials file, a web
+// identity token (EKS IRSA), the container credentials endpoint (ECS task roles, EKS pod identity) and last the
+// instance profile from the EC2 instance metadata service (IMDSv2). The first source that answers is kept, and
+// its temporary credentials are renewed before they expire.
+type AWSCredentialChain struct {
+	static     AWSCredentials
+	region     string
+	httpClient *http.Client
+	getenv     func(string) string
+	// stsEndpoint, containerHost and metadataHost are overridden in tests
+	stsEndpoint   func(region string) string
+	containerHost string
+	metadataHost  string
+	now           func() time.Time
+
+	mu      sync.Mutex
+	source  func(ctx context.Context) (AWSCredentials, error)
+	current AWSCredentials
+}
+
+// NewAWSCredentialChain looks up credentials for region, starting with static when it has an access key
+func NewAWSCredentialChain(region string, static AWSCredentials) *AWSCredentialChain {
+	return &AWSCredentialChain{
+		static:     static,
+		region:     region,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		getenv:     os.Getenv,
+		stsEndpoint: func(region string) string {
+			if region == "" {
+				return "https://sts.amazonaws.com"
+			}
+			return fmt.Sprintf("https://sts.%s.amazonaws.com", region)
+		},
+		containerHost: containerCredentialsHost,
+		metadataHost:  instanceMetadataHost,
+		now:           time.Now,
+	}
+}
+
+// Retrieve returns the cached credentials until they are about to expire, and otherwise asks the source again
+func (chain *AWSCredentialChain) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	if chain.current.AccessKeyID != "" &&
+		(chain.current.Expires.IsZero() || chain.now().Add(credentialsExpiryWindow).Before(chain.current.Expires)) {
+		return chain.current, nil
+	}
+
+	if chain.source != nil {
+		creds, err := chain.source(ctx)
+		if err != nil {
+			return AWSCredentials{}, err
+		}
+		chain.current = creds
+		return creds, nil
+	}
+
+	var failures []string
+	for _, source := range []struct {
+		name  string
+		fetch func(ctx context.Context) (AWSCredentials, error)
+	}{
+		{"static", chain.fromStatic},
+		{"shared credentials file", chain.fromSharedFile},
+		{"web identity", chain.fromWebIdentity},
+		{"container", chain.fromContainer},
+		{"instance metadata", chain.fromInstanceMetadata},
+	} {
+		creds, err := source.fetch(ctx)
+		if errors.Is(err, errNoCredentials) {
+			continue
+		}
+		if err != nil {
+			failures = append(failures, source.name+": "+err.Error())
+			continue
+		}
+		chain.source = source.fetch
+		chain.current = creds
+		return creds, nil
+	}
+	if len(failures) > 0 {
+		return AWSCredentials{}, errors.Errorf("aws: no credentials: %s", strings.Join(failures, "; "))
+	}
+	return AWSCredentials{}, errors.New("aws: no credentials in the configuration, the shared credentials file, a web identity, the container or the instance metadata")
+}
+
+// errNoCredentials tells that a source is not set up, as opposed to failing
+var errNoCredentials = errors.New("aws: the source has no credentials")
+
+func (chain *AWSCredentialChain) fromStatic(ctx context.Context) (AWSCredentials, error) {
+	if chain.static.AccessKeyID == "" {
+		return AWSCredentials{}, errNoCredentials
+	}
+	return chain.static, nil
+}
+
+// fromSharedFile reads the profile AWS_PROFILE (default "default") of AWS_SHARED_CREDENTIALS_FILE or
+// ~/.aws/credentials
+func (chain *AWSCredentialChain) fromSharedFile(ctx context.Context) (AWSCredentials, error) {
+	path := chain.getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, errNoCredentials
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := chain.getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return AWSCredentials{}, errNoCredentials
+	}
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	defer file.Close()
+
+	var creds AWSCredentials
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return AWSCredentials{}, err
+	}
+	if creds.AccessKeyID == "" {
+		return AWSCredentials{}, errNoCredentials
+	}
+	return creds, nil
+}
+
+// fromWebIdentity assumes AWS_ROLE_ARN with the token in AWS_WEB_IDENTITY_TOKEN_FILE, as EKS service accounts do
+func (chain *AWSCredentialChain) fromWebIdentity(ctx context.Context) (AWSCredentials, error) {
+	tokenFile, roleARN := chain.getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), chain.getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || roleARN == "" {
+		return AWSCredentials{}, errNoCredentials
+	}
+	// The token is rotated by the kubelet, so it is read again for every call
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	sessionName := chain.getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("usermanagement-%d", chain.now().Unix())
+	}
+
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chain.stsEndpoint(chain.region)+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := chain.fetch(req)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+
+	var out struct {
+		Credentials struct {
+			AccessKeyId     string
+			SecretAccessKey string
+			SessionToken    string
+			Expiration      time.Time
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &out); err != nil {
+		return AWSCredentials{}, errors.Wrap(err, "aws: AssumeRoleWithWebIdentity")
+	}
+	return AWSCredentials{
+		AccessKeyID:     out.Credentials.AccessKeyId,
+		SecretAccessKey: out.Credentials.SecretAccessKey,
+		SessionToken:    out.Credentials.SessionToken,
+		Expires:         out.Credentials.Expiration,
+	}, nil
+}
+
+// fromContainer asks the endpoint of AWS_CONTAINER_CREDENTIALS_RELATIVE_URI (ECS) or
+// AWS_CONTAINER_CREDENTIALS_FULL_URI, with AWS_CONTAINER_AUTHORIZATION_TOKEN(_FILE) when set (EKS pod identity)
+func (chain *AWSCredentialChain) fromContainer(ctx context.Context) (AWSCredentials, error) {
+	endpoint := chain.getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := chain.getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = chain.containerHost + relative
+	}
+	if endpoint == "" {
+		return AWSCredentials{}, errNoCredentials
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	token := chain.getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := chain.getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return AWSCredentials{}, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return chain.fetchJSONCredentials(req)
+}
+
+// fromInstanceMetadata takes the credentials of the instance profile from IMDSv2, unless
+// AWS_EC2_METADATA_DISABLED is true
+func (chain *AWSCredentialChain) fromInstanceMetadata(ctx context.Context) (AWSCredentials, error) {
+	if strings.EqualFold(chain.getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return AWSCredentials{}, errNoCredentials
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, chain.metadataHost+"/latest/api/token", nil)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+	token, err := chain.fetch(req)
+	if err != nil {
+		// Off EC2 nothing answers on the link-local address
+		return AWSCredentials{}, errNoCredentials
+	}
+
+	get := func(path string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, chain.metadataHost+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+		return req, nil
+	}
+	req, err = get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	roles, err := chain.fetch(req)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return AWSCredentials{}, errNoCredentials
+	}
+	req, err = get("/latest/meta-data/iam/security-credentials/" + role)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	return chain.fetchJSONCredentials(req)
+}
+
+// fetchJSONCredentials decodes the credentials format the container endpoint and the instance metadata share
+func (chain *AWSCredentialChain) fetchJSONCredentials(req *http.Request) (AWSCredentials, error) {
+	body, err := chain.fetch(req)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	var out struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return AWSCredentials{}, errors.Wrap(err, "aws: credentials")
+	}
+	if out.AccessKeyId == "" {
+		return AWSCredentials{}, errors.Errorf("aws: %s returned no credentials", req.URL.Path)
+	}
+	return AWSCredentials{
+		AccessKeyID:     out.AccessKeyId,
+		SecretAccessKey: out.SecretAccessKey,
+		SessionToken:    out.Token,
+		Expires:         out.Expiration,
+	}, nil
+}
+
+func (chain *AWSCredentialChain) fetch(req *http.Request) ([]byte, error) {
+	res, err := chain.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, errors.Errorf("%s %s: %d %s", req.Method, req.URL.Path, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCredentialChain(t *testing.T, env map[string]string, handler http.HandlerFunc) *AWSCredentialChain {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	chain := NewAWSCredentialChain("eu-west-1", AWSCredentials{})
+	chain.getenv = func(key string) string { return env[key] }
+	chain.stsEndpoint = func(string) string { return server.URL }
+	chain.containerHost = server.URL
+	chain.metadataHost = server.URL
+	return chain
+}
+
+func TestAWSCredentialChain_SharedCredentialsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, []byte(`
+[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+[prod]
+aws_access_key_id = AKIDPROD
+aws_secret_access_key = prod-secret
+aws_session_token = prod-session
+`), 0o600))
+
+	chain := newTestCredentialChain(t, map[string]string{"AWS_SHARED_CREDENTIALS_FILE": path, "AWS_PROFILE": "prod"}, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected call to %s", r.URL.Path)
+	})
+	creds, err := chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "AKIDPROD", SecretAccessKey: "prod-secret", SessionToken: "prod-session"}, creds)
+}
+
+func TestAWSCredentialChain_WebIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("eyJ.token\n"), 0o600))
+
+	chain := newTestCredentialChain(t, map[string]string{
+		"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile,
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/app",
+		"AWS_ROLE_SESSION_NAME":       "app",
+		"AWS_EC2_METADATA_DISABLED":   "true",
+	}, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "AssumeRoleWithWebIdentity", r.PostForm.Get("Action"))
+		assert.Equal(t, "arn:aws:iam::123456789012:role/app", r.PostForm.Get("RoleArn"))
+		assert.Equal(t, "eyJ.token", r.PostForm.Get("WebIdentityToken"))
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIAWEB</AccessKeyId>
+      <SecretAccessKey>web-secret</SecretAccessKey>
+      <SessionToken>web-session</SessionToken>
+      <Expiration>2024-05-01T13:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	})
+	creds, err := chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, AWSCredentials{
+		AccessKeyID: "ASIAWEB", SecretAccessKey: "web-secret", SessionToken: "web-session",
+		Expires: time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC),
+	}, creds)
+}
+
+func TestAWSCredentialChain_ContainerRenewsBeforeExpiry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	chain := newTestCredentialChain(t, map[string]string{
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI": "/v2/credentials/task",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN":      "pod-token",
+	}, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/v2/credentials/task", r.URL.Path)
+		assert.Equal(t, "pod-token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"AccessKeyId": "ASIATASK", "SecretAccessKey": "task-secret", "Token": "task-session",
+			"Expiration": "` + now.Add(time.Hour).Format(time.RFC3339) + `"}`))
+	})
+	chain.now = func() time.Time { return now }
+
+	creds, err := chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ASIATASK", creds.AccessKeyID)
+	assert.Equal(t, "task-session", creds.SessionToken)
+
+	now = now.Add(50 * time.Minute)
+	_, err = chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "credentials are cached until they near their expiry")
+
+	now = now.Add(6 * time.Minute)
+	_, err = chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "credentials are renewed within five minutes of their expiry")
+}
+
+func TestAWSCredentialChain_InstanceMetadata(t *testing.T) {
+	chain := newTestCredentialChain(t, map[string]string{"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "none")},
+		func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+				assert.Equal(t, "21600", r.Header.Get("X-Aws-Ec2-Metadata-Token-Ttl-Seconds"))
+				w.Write([]byte("imds-token"))
+			case r.Header.Get("X-Aws-Ec2-Metadata-Token") != "imds-token":
+				w.WriteHeader(http.StatusUnauthorized)
+			case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+				w.Write([]byte("app-role"))
+			case r.URL.Path == "/latest/meta-data/iam/security-credentials/app-role":
+				w.Write([]byte(`{"Code": "Success", "AccessKeyId": "ASIAEC2", "SecretAccessKey": "ec2-secret", "Token": "ec2-session",
+					"Expiration": "2024-05-01T18:00:00Z"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+	creds, err := chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ASIAEC2", creds.AccessKeyID)
+	assert.Equal(t, "ec2-session", creds.SessionToken)
+}
+
+func TestAWSCredentialChain_StaticComesFirst(t *testing.T) {
+	chain := newTestCredentialChain(t, map[string]string{"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI": "/v2/credentials/task"},
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		})
+	chain.static = AWSCredentials{AccessKeyID: "AKIDSTATIC", SecretAccessKey: "static-secret"}
+	creds, err := chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDSTATIC", creds.AccessKeyID)
+}
+
+func TestAWSCredentialChain_NoCredentials(t *testing.T) {
+	chain := newTestCredentialChain(t, map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "none"),
+		"AWS_EC2_METADATA_DISABLED":   "true",
+	}, func(w http.ResponseWriter, r *http.Request) {})
+	_, err := chain.Retrieve(context.Background())
+	assert.ErrorContains(t, err, "aws: no credentials")
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAWSClient(t *testing.T, handler http.HandlerFunc) (*AWSClient, *[]string) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	var calls []string
+	client := NewAWSClient("eu-west-1", AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"})
+	client.endpoint = func(service, region string) string {
+		calls = append(calls, service+"/"+region)
+		return server.URL
+	}
+	client.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return client, &calls
+}
+
+func TestAWSClient_SecretsManager(t *testing.T) {
+	client, calls := newTestAWSClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "20240501T120000Z", r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240501/"), auth)
+		assert.Contains(t, auth, "/secretsmanager/aws4_request, ")
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=")
+
+		var in map[string]string
+		json.NewDecoder(r.Body).Decode(&in)
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"username":"app","password":"rotated-pw"}`, "SecretId": in["SecretId"]})
+	})
+
+	arn := "arn:aws:secretsmanager:us-east-2:123456789012:secret:prod/db-AbCdEf"
+	value, err := client.SecretsManager().Fetch(context.Background(), arn+"#password")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-pw", value)
+
+	_, err = client.SecretsManager().Fetch(context.Background(), "prod/db#missing")
+	assert.ErrorContains(t, err, `no field "missing"`)
+	assert.Equal(t, []string{"secretsmanager/us-east-2", "secretsmanager/eu-west-1"}, *calls, "the region comes from the ARN when there is one")
+}
+
+func TestAWSClient_ParameterStore(t *testing.T) {
+	client, calls := newTestAWSClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&in)
+		if in["Name"] != "/prod/jwt-key" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ParameterNotFound","message":"not found"}`))
+			return
+		}
+		assert.Equal(t, true, in["WithDecryption"])
+		json.NewEncoder(w).Encode(map[string]interface{}{"Parameter": map[string]string{"Value": "jwt-from-ssm"}})
+	})
+
+	value, err := client.ParameterStore().Fetch(context.Background(), "/prod/jwt-key")
+	require.NoError(t, err)
+	assert.Equal(t, "jwt-from-ssm", value)
+	assert.Equal(t, "ssm/eu-west-1", (*calls)[0])
+
+	_, err = client.ParameterStore().Fetch(context.Background(), "/prod/other")
+	assert.ErrorContains(t, err, "ParameterNotFound")
+}
+
+func TestAWSClient_CallREST(t *testing.T) {
+	client, calls := newTestAWSClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.Empty(t, r.Header.Get("X-Amz-Target"))
+		auth := r.Header.Get("Authorization")
+		assert.Contains(t, auth, "/eu-west-1/ses/aws4_request, ")
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature=")
+
+		w.Header().Set("X-Amzn-ErrorType", "MessageRejected")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Email address is not verified."}`))
+	})
+
+	err := client.CallREST(context.Background(), "email", "ses", http.MethodPost, "/v2/email/outbound-emails", map[string]string{}, nil)
+	assert.EqualError(t, err, "aws: POST /v2/email/outbound-emails: 400 MessageRejected Email address is not verified.")
+	assert.Equal(t, []string{"email/eu-west-1"}, *calls)
+}
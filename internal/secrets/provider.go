@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+)
+
+// Provider fetches the secret a reference points to; ref is the part after the scheme, e.g. the ARN of
+// "aws-sm:arn:aws:secretsmanager:..." or the path and field of "vault:secret/data/app#jwt_key"
+type Provider interface {
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// Resolver replaces secret references in the configuration with their values, caching each for ttl.
+// A failed refresh falls back to the cached value so a provider outage does not break running pools.
+type Resolver struct {
+	providers map[string]Provider
+	ttl       time.Duration
+	logger    *zap.SugaredLogger
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+
+	dbUser        string
+	dbPasswordRef string
+}
+
+func NewResolver(ttl time.Duration, logger *zap.SugaredLogger) *Resolver {
+	return &Resolver{
+		providers: make(map[string]Provider),
+		ttl:       ttl,
+		logger:    logger,
+		cache:     make(map[string]cachedSecret),
+	}
+}
+
+// Register makes references starting with "scheme:" resolve through provider
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.providers[scheme] = provider
+}
+
+// lookup splits value into a registered provider and its reference
+func (r *Resolver) lookup(value string) (Provider, string, bool) {
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found {
+		return nil, "", false
+	}
+	provider, ok := r.providers[scheme]
+	return provider, ref, ok
+}
+
+// Resolve returns the secret value references, or value itself when it is not a reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	provider, ref, ok := r.lookup(value)
+	if !ok {
+		return value, nil
+	}
+
+	r.mu.Lock()
+	cached, hit := r.cache[value]
+	r.mu.Unlock()
+	if hit && time.Since(cached.fetchedAt) < r.ttl {
+		return cached.value, nil
+	}
+
+	secret, err := provider.Fetch(ctx, ref)
+	if err != nil {
+		if hit {
+			r.logger.Warnf("Refreshing secret %s failed, using the cached value: %v", value, err)
+			return cached.value, nil
+		}
+		return "", errors.Wrapf(err, "resolving %s", value)
+	}
+
+	r.mu.Lock()
+	r.cache[value] = cachedSecret{value: secret, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return secret, nil
+}
+
+// ResolveConfig replaces every string setting that holds a reference with the secret, then applies
+// DB_PASSWORD to the Postgres URIs unless Vault issues the database credentials
+func (r *Resolver) ResolveConfig(ctx context.Context, cfg *config.Config) error {
+	if cfg.VaultDBRole != "" {
+		cfg.DBPassword = ""
+	}
+	if _, _, ok := r.lookup(cfg.DBPassword); ok {
+		r.dbPasswordRef = cfg.DBPassword
+	}
+
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			resolved, err := r.Resolve(ctx, field.String())
+			if err != nil {
+				return errors.Wrap(err, value.Type().Field(i).Name)
+			}
+			field.SetString(resolved)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			resolved := make([]string, field.Len())
+			for j := range resolved {
+				var err error
+				resolved[j], err = r.Resolve(ctx, field.Index(j).String())
+				if err != nil {
+					return errors.Wrap(err, value.Type().Field(i).Name)
+				}
+			}
+			field.Set(reflect.ValueOf(resolved))
+		}
+	}
+
+	if cfg.DBPassword != "" {
+		if parsed, err := url.Parse(cfg.PostgresURI); err == nil {
+			r.dbUser = parsed.User.Username()
+		}
+		cfg.PostgresURI = withPassword(cfg.PostgresURI, cfg.DBPassword)
+		for i, uri := range cfg.PostgresReplicaURIs {
+			cfg.PostgresReplicaURIs[i] = withPassword(uri, cfg.DBPassword)
+		}
+	}
+	return nil
+}
+
+// RotatesDBPassword reports whether DB_PASSWORD was a reference, so DBCredentials should back new connections
+func (r *Resolver) RotatesDBPassword() bool {
+	return r.dbPasswordRef != ""
+}
+
+// DBCredentials returns the database user and the current value of the DB_PASSWORD reference, re-fetched once
+// the cached value is older than the TTL so a rotated password reaches new connections
+func (r *Resolver) DBCredentials() (string, string) {
+	password, err := r.Resolve(context.Background(), r.dbPasswordRef)
+	if err != nil {
+		r.logger.Error(err)
+	}
+	return r.dbUser, password
+}
+
+// withPassword replaces the password of a postgres:// URI, keeping its user
+func withPassword(uri, password string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	return withCredentials(uri, Credentials{Username: parsed.User.Username(), Password: password})
+}
@@ -0,0 +1,301 @@
+package secrets
+
+import (
+	"context"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+)
+
+// Secrets holds the backends the configuration was resolved from
+type Secrets struct {
+	Vault    *Vault // nil without VAULT_ADDR
+	Resolver *Resolver
+	// AWS signs calls with the configured AWS credentials, e.g. for SES
+	AWS *AWSClient
+}
+
+// Setup loads the Vault secrets, then resolves the secret references left in cfg
+func Setup(ctx context.Context, cfg *config.Config, logger *zap.SugaredLogger) (*Secrets, error) {
+	vault, err := LoadVault(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := NewResolver(cfg.SecretsCacheTTL, logger)
+	aws := NewAWSClient(cfg.AWSRegion, NewAWSCredentialChain(cfg.AWSRegion, AWSCredentials{
+		AccessKeyID:     cfg.AWSAccessKeyID,
+		SecretAccessKey: cfg.AWSSecretAccessKey,
+		SessionToken:    cfg.AWSSessionToken,
+	}))
+	resolver.Register("aws-sm", aws.SecretsManager())
+	resolver.Register("aws-ssm", aws.ParameterStore())
+	if vault != nil {
+		resolver.Register("vault", vault.KV())
+	}
+
+	err = resolver.ResolveConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Secrets{Vault: vault, Resolver: resolver, AWS: aws}, nil
+}
+
+// CredentialSource returns the function new database connections should take their credentials from when
+// they rotate (Vault database role or a DB_PASSWORD reference), or nil when the URI credentials are static
+func (s *Secrets) CredentialSource() func() (string, string) {
+	if s.Vault != nil && s.Vault.dbRole != "" {
+		return s.Vault.DBCredentials
+	}
+	if s.Resolver.RotatesDBPassword() {
+		return s.Resolver.DBCredentials
+	}
+	return nil
+}
+
+// Run keeps the Vault leases alive until ctx is done
+func (s *Secrets) Run(ctx context.Context) {
+	if s.Vault != nil {
+		s.Vault.Run(ctx)
+	}
+}
+
+// minRenewDelay keeps a lease that Vault reports as already expiring from being renewed in a tight loop
+const minRenewDelay = 5 * time.Second
+
+// Vault resolves the JWT signing key and the database credentials from Vault, keeps their leases alive and reads
+// the JWT key again for rotations
+type Vault struct {
+	client  *VaultClient
+	dbRole  string
+	dbMount string
+	logger  *zap.SugaredLogger
+
+	jwtKeyPath   string
+	jwtKeyField  string
+	jwtKey       string
+	jwtRefresh   time.Duration
+	jwtRefreshAt time.Time
+	onJWTKey     atomic.Pointer[func(key string)]
+
+	credentials  atomic.Pointer[Credentials]
+	dbLease      Lease
+	dbRenewAt    time.Time
+	tokenLease   Lease
+	tokenRenewAt time.Time
+}
+
+// LoadVault logs in to Vault and replaces cfg.JwtKey and the credentials in the Postgres URIs with the secrets
+// configured by VAULT_JWT_KEY_PATH and VAULT_DB_ROLE. It returns nil when VAULT_ADDR is not set.
+func LoadVault(ctx context.Context, cfg *config.Config, logger *zap.SugaredLogger) (*Vault, error) {
+	if cfg.VaultAddr == "" {
+		return nil, nil
+	}
+
+	vault := &Vault{
+		client:  NewVaultClient(cfg.VaultAddr, cfg.VaultToken, cfg.VaultRoleID, cfg.VaultSecretID, cfg.VaultAppRoleMount),
+		dbRole:  cfg.VaultDBRole,
+		dbMount: cfg.VaultDBMount,
+		logger:  logger,
+
+		jwtKeyPath:  cfg.VaultJWTKeyPath,
+		jwtKeyField: cfg.VaultJWTKeyField,
+		jwtRefresh:  cfg.VaultJWTKeyRefresh,
+	}
+
+	lease, err := vault.client.Login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vault.setTokenLease(lease)
+
+	if cfg.VaultJWTKeyPath != "" {
+		cfg.JwtKey, err = vault.client.ReadKV(ctx, cfg.VaultJWTKeyPath, cfg.VaultJWTKeyField)
+		if err != nil {
+			return nil, err
+		}
+		vault.jwtKey = cfg.JwtKey
+		vault.jwtRefreshAt = refreshAt(vault.jwtRefresh)
+	}
+
+	if vault.dbRole != "" {
+		err = vault.issueCredentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Migrations and startup connections read the URIs; open pools ask DBCredentials for every new connection
+		creds := vault.credentials.Load()
+		cfg.PostgresURI = withCredentials(cfg.PostgresURI, *creds)
+		replicas := make([]string, len(cfg.PostgresReplicaURIs))
+		for i, uri := range cfg.PostgresReplicaURIs {
+			replicas[i] = withCredentials(uri, *creds)
+		}
+		cfg.PostgresReplicaURIs = replicas
+	}
+
+	return vault, nil
+}
+
+// KV returns a Provider for "vault:<path>#<field>" references, authenticated with the same token
+func (v *Vault) KV() Provider {
+	return kvProvider{client: v.client}
+}
+
+// OnJWTKey sets the function Run calls with the JWT key whenever it reads a new one
+func (v *Vault) OnJWTKey(fn func(key string)) {
+	v.onJWTKey.Store(&fn)
+}
+
+// DBCredentials returns the current database username and password
+func (v *Vault) DBCredentials() (string, string) {
+	creds := v.credentials.Load()
+	return creds.Username, creds.Password
+}
+
+// Run renews the token and database leases when two thirds of their duration has passed, logging in again
+// or issuing new credentials when a lease can no longer be renewed, and reads the JWT key again every
+// VAULT_JWT_KEY_REFRESH_INTERVAL, until ctx is done
+func (v *Vault) Run(ctx context.Context) {
+	for {
+		next := earliest(earliest(v.tokenRenewAt, v.dbRenewAt), v.jwtRefreshAt)
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		if !v.tokenRenewAt.IsZero() && !now.Before(v.tokenRenewAt) {
+			v.renewToken(ctx)
+		}
+		if !v.dbRenewAt.IsZero() && !now.Before(v.dbRenewAt) {
+			v.renewCredentials(ctx)
+		}
+		if !v.jwtRefreshAt.IsZero() && !now.Before(v.jwtRefreshAt) {
+			v.refreshJWTKey(ctx)
+		}
+	}
+}
+
+// refreshJWTKey reads the JWT key and hands a new one to the OnJWTKey function. Until there is one the key is
+// left as new, so the next read hands it over.
+func (v *Vault) refreshJWTKey(ctx context.Context) {
+	v.jwtRefreshAt = refreshAt(v.jwtRefresh)
+	key, err := v.client.ReadKV(ctx, v.jwtKeyPath, v.jwtKeyField)
+	if err != nil {
+		v.logger.Warnf("Reading the JWT key failed, signing with the current one: %v", err)
+		return
+	}
+	fn := v.onJWTKey.Load()
+	if key == v.jwtKey || fn == nil {
+		return
+	}
+	(*fn)(key)
+	v.jwtKey = key
+}
+
+func (v *Vault) renewToken(ctx context.Context) {
+	if v.tokenLease.Renewable {
+		lease, err := v.client.RenewToken(ctx)
+		if err == nil {
+			v.setTokenLease(lease)
+			return
+		}
+		v.logger.Warnf("Vault token renewal failed, logging in again: %v", err)
+	}
+
+	lease, err := v.client.Login(ctx)
+	if err != nil {
+		v.logger.Errorf("Vault login failed: %v", err)
+		v.tokenRenewAt = time.Now().Add(minRenewDelay)
+		return
+	}
+	v.setTokenLease(lease)
+}
+
+func (v *Vault) renewCredentials(ctx context.Context) {
+	if v.dbLease.Renewable {
+		lease, err := v.client.RenewLease(ctx, v.dbLease.ID, v.dbLease.Duration)
+		// Near the max TTL Vault grants less than asked for; switch to a fresh user before the old one expires
+		if err == nil && lease.Duration >= v.dbLease.Duration/3 {
+			v.dbLease.Duration = lease.Duration
+			v.dbRenewAt = renewAt(lease.Duration)
+			return
+		}
+		if err != nil {
+			v.logger.Warnf("Database lease renewal failed: %v", err)
+		}
+	}
+
+	err := v.issueCredentials(ctx)
+	if err != nil {
+		v.logger.Errorf("Issuing database credentials failed: %v", err)
+		v.dbRenewAt = time.Now().Add(minRenewDelay)
+		return
+	}
+	v.logger.Infof("Rotated database credentials, new connections use %s", v.credentials.Load().Username)
+}
+
+func (v *Vault) issueCredentials(ctx context.Context) error {
+	creds, lease, err := v.client.DatabaseCredentials(ctx, v.dbMount, v.dbRole)
+	if err != nil {
+		return err
+	}
+	v.credentials.Store(&creds)
+	v.dbLease = lease
+	v.dbRenewAt = renewAt(lease.Duration)
+	return nil
+}
+
+func (v *Vault) setTokenLease(lease Lease) {
+	v.tokenLease = lease
+	// Tokens without a TTL (root or periodic tokens looked up without one) never need renewing
+	v.tokenRenewAt = renewAt(lease.Duration)
+}
+
+// renewAt returns when a lease of duration obtained now should be renewed, or zero for leases that do not expire
+func renewAt(duration time.Duration) time.Time {
+	if duration <= 0 {
+		return time.Time{}
+	}
+	delay := duration * 2 / 3
+	if delay < minRenewDelay {
+		delay = minRenewDelay
+	}
+	return time.Now().Add(delay)
+}
+
+// refreshAt returns when to read again a secret read now every interval, or zero for one read once
+func refreshAt(interval time.Duration) time.Time {
+	if interval <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(interval)
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// withCredentials replaces the user info of a postgres:// URI
+func withCredentials(uri string, creds Credentials) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	parsed.User = url.UserPassword(creds.Username, creds.Password)
+	return parsed.String()
+}
@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// VaultClient talks to the Vault HTTP API with a token, either given directly or obtained by AppRole login
+type VaultClient struct {
+	addr       string
+	mu         sync.Mutex // guards token, replaced by logins while references are being resolved
+	token      string
+	roleID     string
+	secretID   string
+	appRole    string
+	httpClient *http.Client
+}
+
+// Lease is a secret Vault hands out for a limited time
+type Lease struct {
+	ID        string
+	Duration  time.Duration
+	Renewable bool
+}
+
+// Credentials is a database username and password
+type Credentials struct {
+	Username string
+	Password string
+}
+
+func NewVaultClient(addr, token, roleID, secretID, appRoleMount string) *VaultClient {
+	return &VaultClient{
+		addr:       strings.TrimSuffix(addr, "/"),
+		token:      token,
+		roleID:     roleID,
+		secretID:   secretID,
+		appRole:    appRoleMount,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultResponse covers the parts of Vault responses used here
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// Login exchanges the AppRole role and secret IDs for a token. With a static token it only looks up the
+// token's own lease. The returned lease describes the token.
+func (c *VaultClient) Login(ctx context.Context) (Lease, error) {
+	if c.roleID == "" {
+		resp, err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", nil)
+		if err != nil {
+			return Lease{}, err
+		}
+		ttl, _ := resp.Data["ttl"].(float64)
+		renewable, _ := resp.Data["renewable"].(bool)
+		return Lease{Duration: time.Duration(ttl) * time.Second, Renewable: renewable}, nil
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "auth/"+c.appRole+"/login", map[string]string{
+		"role_id":   c.roleID,
+		"secret_id": c.secretID,
+	})
+	if err != nil {
+		return Lease{}, err
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return Lease{}, errors.New("vault: AppRole login returned no token")
+	}
+	c.mu.Lock()
+	c.token = resp.Auth.ClientToken
+	c.mu.Unlock()
+	return Lease{Duration: time.Duration(resp.Auth.LeaseDuration) * time.Second, Renewable: resp.Auth.Renewable}, nil
+}
+
+// RenewToken extends the client's own token and returns its new lease
+func (c *VaultClient) RenewToken(ctx context.Context) (Lease, error) {
+	resp, err := c.do(ctx, http.MethodPost, "auth/token/renew-self", map[string]string{})
+	if err != nil {
+		return Lease{}, err
+	}
+	if resp.Auth == nil {
+		return Lease{}, errors.New("vault: token renewal returned no auth")
+	}
+	return Lease{Duration: time.Duration(resp.Auth.LeaseDuration) * time.Second, Renewable: resp.Auth.Renewable}, nil
+}
+
+// ReadKV returns one field of a KV secret. path is the API path, e.g. "secret/data/app" for KV v2.
+func (c *VaultClient) ReadKV(ctx context.Context, path, field string) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	data := resp.Data
+	// KV v2 nests the secret under data.data next to data.metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field].(string)
+	if !ok || value == "" {
+		return "", errors.Errorf("vault: secret %s has no field %q", path, field)
+	}
+	return value, nil
+}
+
+// kvProvider resolves "vault:<path>#<field>" references
+type kvProvider struct {
+	client *VaultClient
+}
+
+func (p kvProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	path, field, found := strings.Cut(ref, "#")
+	if !found {
+		return "", errors.Errorf("vault reference %q needs a #field", ref)
+	}
+	return p.client.ReadKV(ctx, path, field)
+}
+
+// DatabaseCredentials issues a new database user from the database secrets engine role at mount/creds/role
+func (c *VaultClient) DatabaseCredentials(ctx context.Context, mount, role string) (Credentials, Lease, error) {
+	resp, err := c.do(ctx, http.MethodGet, mount+"/creds/"+role, nil)
+	if err != nil {
+		return Credentials{}, Lease{}, err
+	}
+
+	username, _ := resp.Data["username"].(string)
+	password, _ := resp.Data["password"].(string)
+	if username == "" || password == "" {
+		return Credentials{}, Lease{}, errors.Errorf("vault: %s/creds/%s returned no credentials", mount, role)
+	}
+	lease := Lease{ID: resp.LeaseID, Duration: time.Duration(resp.LeaseDuration) * time.Second, Renewable: resp.Renewable}
+	return Credentials{Username: username, Password: password}, lease, nil
+}
+
+// RenewLease extends a secret lease by increment and returns the duration Vault granted, which is shorter
+// than requested once the lease nears its max TTL
+func (c *VaultClient) RenewLease(ctx context.Context, leaseID string, increment time.Duration) (Lease, error) {
+	resp, err := c.do(ctx, http.MethodPut, "sys/leases/renew", map[string]interface{}{
+		"lease_id":  leaseID,
+		"increment": int(increment.Seconds()),
+	})
+	if err != nil {
+		return Lease{}, err
+	}
+	return Lease{ID: resp.LeaseID, Duration: time.Duration(resp.LeaseDuration) * time.Second, Renewable: resp.Renewable}, nil
+}
+
+func (c *VaultClient) do(ctx context.Context, method, path string, body interface{}) (*vaultResponse, error) {
+	var payload bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&payload).Encode(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+"/v1/"+strings.TrimPrefix(path, "/"), &payload)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "vault")
+	}
+	defer res.Body.Close()
+
+	var resp vaultResponse
+	decodeErr := json.NewDecoder(res.Body).Decode(&resp)
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("vault: %s %s: %d %s", method, path, res.StatusCode, strings.Join(resp.Errors, "; "))
+	}
+	if decodeErr != nil {
+		return nil, errors.Wrapf(decodeErr, "vault: %s %s", method, path)
+	}
+	return &resp, nil
+}
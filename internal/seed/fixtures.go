@@ -0,0 +1,182 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// fixtureBatchSize rows are written per transaction
+const fixtureBatchSize = 500
+
+type FixtureOptions struct {
+	Users int
+	Votes int
+	// LikeRatio is the share of votes that are +1
+	LikeRatio float64
+	// Span spreads sign-ups and votes over that long before now
+	Span time.Duration
+	// RandSeed makes a run reproducible; every run with the same seed draws the same data
+	RandSeed int64
+}
+
+// FixtureGenerator fills a database with load-test data through the repositories: users whose sign-ups are spread
+// over the span, and votes where a few profiles draw most of the votes and a few users cast most of them, as on a
+// real leaderboard
+type FixtureGenerator struct {
+	userRepo  repositories.UserRepoInterface
+	voteRepo  repositories.VoteRepoInterface
+	roleRepo  repositories.RoleRepoInterface
+	txManager repositories.TxManagerInterface
+	logger    *zap.SugaredLogger
+}
+
+func NewFixtureGenerator(userRepo repositories.UserRepoInterface, voteRepo repositories.VoteRepoInterface, roleRepo repositories.RoleRepoInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) *FixtureGenerator {
+	return &FixtureGenerator{
+		userRepo:  userRepo,
+		voteRepo:  voteRepo,
+		roleRepo:  roleRepo,
+		txManager: txManager,
+		logger:    logger,
+	}
+}
+
+// FixtureReport counts what a run created
+type FixtureReport struct {
+	Users int
+	Votes int
+}
+
+// Run creates opts.Users users sharing one password and then up to opts.Votes votes among them. Fewer votes are
+// created when the users cannot cast that many, since everybody votes at most once per profile.
+func (g *FixtureGenerator) Run(ctx context.Context, opts FixtureOptions) (*FixtureReport, error) {
+	random := rand.New(rand.NewSource(opts.RandSeed))
+	faker := gofakeit.New(opts.RandSeed)
+	now := time.Now()
+
+	ids, err := g.createUsers(ctx, opts, faker, random, now)
+	if err != nil {
+		return nil, err
+	}
+	report := &FixtureReport{Users: len(ids)}
+	g.logger.Infof("Created %d fixture users (password %q)", len(ids), fakeUserPassword)
+
+	votes := drawVotes(random, ids, opts, now)
+	for start := 0; start < len(votes); start += fixtureBatchSize {
+		batch := votes[start:min(start+fixtureBatchSize, len(votes))]
+		err = g.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			for i := range batch {
+				if _, err := g.voteRepo.CreateVote(ctx, &batch[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+		report.Votes += len(batch)
+	}
+	g.logger.Infof("Created %d fixture votes", report.Votes)
+	return report, nil
+}
+
+func (g *FixtureGenerator) createUsers(ctx context.Context, opts FixtureOptions, faker *gofakeit.Faker, random *rand.Rand, now time.Time) ([]uint, error) {
+	role, err := g.roleRepo.GetRoleByName(ctx, models.StrUser)
+	if err != nil {
+		return nil, err
+	}
+	// bcrypt is deliberately slow, so every fixture account shares one hash
+	hash, err := passwords.HashPassword(fakeUserPassword)
+	if err != nil {
+		return nil, err
+	}
+	// The run's suffix keeps addresses unique across runs with the same seed
+	run := strings.ToLower(gofakeit.LetterN(6))
+
+	ids := make([]uint, 0, opts.Users)
+	for start := 0; start < opts.Users; start += fixtureBatchSize {
+		end := min(start+fixtureBatchSize, opts.Users)
+		err = g.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			for i := start; i < end; i++ {
+				firstName, lastName := faker.FirstName(), faker.LastName()
+				createdAt := spreadBefore(random, now, opts.Span)
+				user, err := g.userRepo.CreateUser(ctx, &models.User{
+					Email:     strings.ToLower(fmt.Sprintf("%s.%s.%d.%s@example.com", firstName, lastName, i, run)),
+					FirstName: firstName,
+					LastName:  lastName,
+					Password:  hash,
+					RoleID:    role.ID,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				})
+				if err != nil {
+					return err
+				}
+				ids = append(ids, user.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// drawVotes picks voters and profiles from Zipf distributions, so activity and popularity both have a long
+// tail. Profiles are ranked in a different order than voters, so the busiest voters are not the most popular.
+func drawVotes(random *rand.Rand, ids []uint, opts FixtureOptions, now time.Time) []models.Vote {
+	n := len(ids)
+	if n < 2 || opts.Votes <= 0 {
+		return nil
+	}
+	wanted := opts.Votes
+	if possible := n * (n - 1); wanted > possible {
+		wanted = possible
+	}
+
+	voters := rand.NewZipf(random, 1.1, 1, uint64(n-1))
+	profiles := rand.NewZipf(random, 1.3, 1, uint64(n-1))
+	popularity := random.Perm(n)
+
+	seen := make(map[[2]uint]bool, wanted)
+	votes := make([]models.Vote, 0, wanted)
+	// The tail of a Zipf distribution is rarely drawn, so a dense request falls back to uniform picks
+	for attempts := 0; len(votes) < wanted && attempts < 20*wanted; attempts++ {
+		var voter, profile uint
+		if attempts < 10*wanted {
+			voter, profile = ids[voters.Uint64()], ids[popularity[profiles.Uint64()]]
+		} else {
+			voter, profile = ids[random.Intn(n)], ids[random.Intn(n)]
+		}
+		pair := [2]uint{voter, profile}
+		if voter == profile || seen[pair] {
+			continue
+		}
+		seen[pair] = true
+
+		value := -1
+		if random.Float64() < opts.LikeRatio {
+			value = 1
+		}
+		votes = append(votes, models.Vote{UserID: voter, ProfileID: profile, Value: value, CreatedAt: spreadBefore(random, now, opts.Span)})
+	}
+	return votes
+}
+
+// spreadBefore is a random time within span before now
+func spreadBefore(random *rand.Rand, now time.Time, span time.Duration) time.Time {
+	if span <= 0 {
+		return now
+	}
+	return now.Add(-time.Duration(random.Int63n(int64(span))))
+}
@@ -0,0 +1,46 @@
+package seed
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawVotes_AreUniqueAndNeverForOneself(t *testing.T) {
+	ids := []uint{3, 7, 11, 19, 23, 42, 57, 88, 91, 100}
+	now := time.Now()
+	votes := drawVotes(rand.New(rand.NewSource(1)), ids, FixtureOptions{Votes: 60, LikeRatio: 0.7, Span: time.Hour}, now)
+
+	assert.Len(t, votes, 60)
+	seen := map[[2]uint]bool{}
+	likes := 0
+	for _, vote := range votes {
+		assert.NotEqual(t, vote.UserID, vote.ProfileID)
+		assert.False(t, seen[[2]uint{vote.UserID, vote.ProfileID}], "duplicate vote %d -> %d", vote.UserID, vote.ProfileID)
+		seen[[2]uint{vote.UserID, vote.ProfileID}] = true
+		assert.Contains(t, []int{-1, 1}, vote.Value)
+		assert.False(t, vote.CreatedAt.After(now))
+		assert.True(t, vote.CreatedAt.After(now.Add(-time.Hour)))
+		if vote.Value == 1 {
+			likes++
+		}
+	}
+	assert.Greater(t, likes, len(votes)/2)
+}
+
+func TestDrawVotes_IsCappedByPossiblePairs(t *testing.T) {
+	ids := []uint{1, 2, 3, 4}
+	votes := drawVotes(rand.New(rand.NewSource(1)), ids, FixtureOptions{Votes: 100}, time.Now())
+	assert.Len(t, votes, len(ids)*(len(ids)-1))
+
+	assert.Empty(t, drawVotes(rand.New(rand.NewSource(1)), ids[:1], FixtureOptions{Votes: 10}, time.Now()))
+}
+
+func TestDrawVotes_IsReproducible(t *testing.T) {
+	ids := []uint{1, 2, 3, 4, 5, 6, 7, 8}
+	now := time.Now()
+	opts := FixtureOptions{Votes: 20, LikeRatio: 0.5, Span: time.Hour}
+	assert.Equal(t, drawVotes(rand.New(rand.NewSource(5)), ids, opts, now), drawVotes(rand.New(rand.NewSource(5)), ids, opts, now))
+}
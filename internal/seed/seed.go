@@ -0,0 +1,141 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakeUserPassword is shared by all generated demo accounts so they can be logged into
+const fakeUserPassword = "demo-password1!"
+
+type Options struct {
+	AdminEmail    string
+	AdminPassword string
+	FakeUsers     int
+}
+
+type Seeder struct {
+	db       *gorm.DB
+	userRepo repositories.UserRepoInterface
+	logger   *zap.SugaredLogger
+}
+
+func NewSeeder(db *gorm.DB, userRepo repositories.UserRepoInterface, logger *zap.SugaredLogger) *Seeder {
+	return &Seeder{
+		db:       db,
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// Run is idempotent: existing roles and the admin account are left untouched
+func (s *Seeder) Run(ctx context.Context, opts Options) error {
+	roles, err := s.seedRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	if opts.AdminEmail != "" {
+		err = s.seedAdmin(ctx, opts.AdminEmail, opts.AdminPassword, roles[models.StrAdmin])
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.FakeUsers > 0 {
+		err = s.seedFakeUsers(ctx, opts.FakeUsers, roles[models.StrUser])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Seeder) seedRoles(ctx context.Context) (map[string]uint, error) {
+	roles := map[string]uint{}
+	for _, name := range []string{models.StrUser, models.StrModerator, models.StrAdmin} {
+		role := models.Role{Name: name}
+		err := s.db.WithContext(ctx).Where(models.Role{Name: name}).FirstOrCreate(&role).Error
+		if err != nil {
+			return nil, err
+		}
+		roles[name] = role.ID
+	}
+
+	s.logger.Infof("Seeded %d roles", len(roles))
+	return roles, nil
+}
+
+func (s *Seeder) seedAdmin(ctx context.Context, email, password string, roleID uint) error {
+	_, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err == nil {
+		s.logger.Infof("Admin %s already exists, skipping", email)
+		return nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+
+	if password == "" {
+		return errors.New("SEED_ADMIN_PASSWORD is required to create the admin account")
+	}
+
+	hash, err := passwords.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.userRepo.CreateUser(ctx, &models.User{
+		Email:     email,
+		FirstName: "Admin",
+		LastName:  "Seeded",
+		Password:  hash,
+		RoleID:    roleID,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Created admin %s", email)
+	return nil
+}
+
+func (s *Seeder) seedFakeUsers(ctx context.Context, count int, roleID uint) error {
+	// bcrypt is deliberately slow, so every demo account shares one hash
+	hash, err := passwords.HashPassword(fakeUserPassword)
+	if err != nil {
+		return err
+	}
+
+	faker := gofakeit.New(0)
+	for i := 0; i < count; i++ {
+		firstName := faker.FirstName()
+		lastName := faker.LastName()
+		user := &models.User{
+			// The index keeps addresses unique within a run, the random suffix across runs
+			Email:     strings.ToLower(fmt.Sprintf("%s.%s.%d.%s@example.com", firstName, lastName, i, faker.LetterN(6))),
+			FirstName: firstName,
+			LastName:  lastName,
+			Password:  hash,
+			RoleID:    roleID,
+		}
+
+		_, err = s.userRepo.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.logger.Infof("Created %d fake users (password %q)", count, fakeUserPassword)
+	return nil
+}
@@ -0,0 +1,108 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+)
+
+// appModule prefixes the functions Sentry shows as application code rather than libraries
+const appModule = "gitlab.com/jkozhemiaka/web-layout/"
+
+type contextKey struct{}
+
+// WithClient returns a copy of ctx whose requests report to client
+func WithClient(ctx context.Context, client *Client) context.Context {
+	return context.WithValue(ctx, contextKey{}, client)
+}
+
+// FromContext returns the client stored by WithClient, or nil, which reports nothing
+func FromContext(ctx context.Context) *Client {
+	client, _ := ctx.Value(contextKey{}).(*Client)
+	return client
+}
+
+// CaptureRequest reports err raised while serving r, tagged with the request ID, route and user. pcs is the
+// stack to attach, innermost call first; nil takes the stack recorded in err, if any.
+func (c *Client) CaptureRequest(r *http.Request, level string, err error, pcs []uintptr) {
+	if c == nil {
+		return
+	}
+	if pcs == nil {
+		pcs = apperrors.Callers(err)
+	}
+
+	route := r.URL.Path
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, templateErr := current.GetPathTemplate(); templateErr == nil {
+			route = template
+		}
+	}
+	tags := map[string]string{"route": route}
+	if requestID, ok := r.Context().Value(models.RequestIDContextKey).(string); ok {
+		tags["request_id"] = requestID
+	}
+
+	event := &Event{
+		Level:       level,
+		Transaction: r.Method + " " + route,
+		Tags:        tags,
+		Request:     &Request{Method: r.Method, URL: requestURL(r)},
+		Exception: &Exceptions{Values: []Exception{{
+			Type:       errorType(err),
+			Value:      err.Error(),
+			Stacktrace: stacktrace(pcs),
+		}}},
+	}
+	if userID, ok := r.Context().Value(models.IDContextKey).(string); ok && userID != "" {
+		tags["user_id"] = userID
+		event.User = &User{ID: userID}
+	}
+	c.Capture(event)
+}
+
+// requestURL is the URL of r without its query, which can carry tokens and codes that must not leave the service
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// errorType groups events by AppError code, or else by Go type
+func errorType(err error) string {
+	var appError *apperrors.AppError
+	if errors.As(err, &appError) {
+		return appError.Code
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// stacktrace converts program counters to Sentry frames, which are listed outermost call first
+func stacktrace(pcs []uintptr) *Stacktrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var frames []Frame
+	callers := runtime.CallersFrames(pcs)
+	for {
+		frame, more := callers.Next()
+		frames = append([]Frame{{
+			Function: frame.Function,
+			Filename: frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, appModule),
+		}}, frames...)
+		if !more {
+			return &Stacktrace{Frames: frames}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// queueSize bounds the events waiting to be sent; more are dropped so a Sentry outage cannot hold up requests
+const queueSize = 100
+
+// Levels of the events reported
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// defaultRetryAfter is how long sending pauses after a 429 that does not say for how long
+const defaultRetryAfter = time.Minute
+
+// Client sends events to the envelope endpoint of the Sentry project named by a DSN, in the background. Like the
+// official SDK it backs off while Sentry rate limits the project, and Flush sends what is queued at shutdown.
+type Client struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	httpClient  *http.Client
+	logger      *zap.SugaredLogger
+	queue       chan *Event
+	// pending counts the events queued or being sent, for Flush
+	pending sync.WaitGroup
+	// disabledUntil is when Sentry takes events again after rate limiting; only Run touches it
+	disabledUntil time.Time
+	now           func() time.Time
+}
+
+// NewClient parses dsn ("https://<public key>[:<secret key>]@<host>[/<path>]/<project id>"); environment and
+// release tag every event
+func NewClient(dsn, environment, release string, logger *zap.SugaredLogger) (*Client, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid SENTRY_DSN")
+	}
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	if parsed.User == nil || parsed.User.Username() == "" || slash < 0 || path[slash+1:] == "" ||
+		(parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, errors.New("invalid SENTRY_DSN: expected https://<public key>@<host>/<project id>")
+	}
+	prefix, projectID := path[:slash], path[slash+1:]
+
+	auth := "Sentry sentry_version=7, sentry_client=web-layout/1.0, sentry_key=" + parsed.User.Username()
+	if secret, ok := parsed.User.Password(); ok && secret != "" {
+		auth += ", sentry_secret=" + secret
+	}
+	return &Client{
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", parsed.Scheme, parsed.Host, prefix, projectID),
+		auth:        auth,
+		environment: environment,
+		release:     release,
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+		logger:      logger,
+		queue:       make(chan *Event, queueSize),
+		now:         time.Now,
+	}, nil
+}
+
+// Event is the part of the Sentry event payload filled in here
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *User             `json:"user,omitempty"`
+	Request     *Request          `json:"request,omitempty"`
+	Exception   *Exceptions       `json:"exception,omitempty"`
+}
+
+type User struct {
+	ID string `json:"id"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Capture queues event to be sent by Run, filling in the ID, time, environment and release. It never blocks:
+// events are dropped while the queue is full. A nil Client captures nothing.
+func (c *Client) Capture(event *Event) {
+	if c == nil {
+		return
+	}
+	event.EventID = newEventID()
+	event.Timestamp = time.Now().UTC()
+	event.Platform = "go"
+	event.Environment = c.environment
+	event.Release = c.release
+
+	c.pending.Add(1)
+	select {
+	case c.queue <- event:
+	default:
+		c.pending.Done()
+		c.logger.Warnf("Sentry queue is full, dropping event %s", event.EventID)
+	}
+}
+
+// Run sends the queued events until ctx is done. Events are dropped while Sentry rate limits the project.
+func (c *Client) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-c.queue:
+			if c.now().Before(c.disabledUntil) {
+				c.logger.Debugf("Sentry is rate limiting until %s, dropping event %s", c.disabledUntil.Format(time.RFC3339), event.EventID)
+			} else if err := c.send(ctx, event); err != nil {
+				c.logger.Warnf("Sending event %s to Sentry failed: %v", event.EventID, err)
+			}
+			c.pending.Done()
+		}
+	}
+}
+
+// Flush waits up to timeout for the queued events to be sent and reports whether they were. It is called before
+// the process exits, so the events of a crash are not lost.
+func (c *Client) Flush(timeout time.Duration) bool {
+	if c == nil {
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		c.logger.Warnf("Sentry did not take the queued events within %s", timeout)
+		return false
+	}
+}
+
+// send posts event in an envelope, the format Sentry ingests events in
+func (c *Client) send(ctx context.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var envelope bytes.Buffer
+	fmt.Fprintf(&envelope, "{\"event_id\":%q,\"sent_at\":%q}\n", event.EventID, time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&envelope, "{\"type\":\"event\",\"length\":%d}\n", len(payload))
+	envelope.Write(payload)
+	envelope.WriteString("\n")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, &envelope)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sentry")
+	}
+	defer res.Body.Close()
+
+	if until, limited := c.rateLimit(res); limited {
+		c.disabledUntil = until
+		if res.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("sentry: rate limited until %s", until.Format(time.RFC3339))
+		}
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sentry: responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// rateLimit reads until when Sentry refuses events from X-Sentry-Rate-Limits ("<seconds>:<categories>:<scope>",
+// comma separated, where no categories means all of them) or else the Retry-After of a 429
+func (c *Client) rateLimit(res *http.Response) (time.Time, bool) {
+	if header := res.Header.Get("X-Sentry-Rate-Limits"); header != "" {
+		var until time.Time
+		for _, limit := range strings.Split(header, ",") {
+			parts := strings.Split(strings.TrimSpace(limit), ":")
+			seconds, err := strconv.ParseFloat(parts[0], 64)
+			if err != nil {
+				continue
+			}
+			if len(parts) > 1 && parts[1] != "" && !containsCategory(parts[1], "error") {
+				continue
+			}
+			if at := c.now().Add(time.Duration(seconds * float64(time.Second))); at.After(until) {
+				until = at
+			}
+		}
+		return until, !until.IsZero()
+	}
+	if res.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		return c.now().Add(time.Duration(seconds) * time.Second), true
+	}
+	return c.now().Add(defaultRetryAfter), true
+}
+
+func containsCategory(categories, category string) bool {
+	for _, c := range strings.Split(categories, ";") {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// newEventID returns 32 hex characters, the UUID format Sentry expects without dashes
+func newEventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,360 @@
+//go:build integration
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/integrationtest"
+)
+
+// newIntegrationServer serves the API as `weblayout serve` wires it, against a fresh Postgres database and the
+// shared Redis
+func newIntegrationServer(t *testing.T, env map[string]string) *httptest.Server {
+	postgresURI := integrationtest.PostgresURI(t)
+	redisURL := integrationtest.RedisURL(t)
+
+	configPath := filepath.Join(t.TempDir(), "config.env")
+	require.NoError(t, os.WriteFile(configPath, nil, 0o600))
+	settings := map[string]string{
+		"CONFIG_PATH":  configPath,
+		"APP_ENV":      "development",
+		"APP_PORT":     "50052",
+		"JWT_KEY":      "integration-secret",
+		"DB_DRIVER":    "postgres",
+		"POSTGRES_URI": postgresURI,
+		"REDIS_URL":    redisURL,
+		"DB_LOG_LEVEL": "silent",
+	}
+	for key, value := range env {
+		settings[key] = value
+	}
+	for key, value := range settings {
+		t.Setenv(key, value)
+	}
+
+	api := newServer(app.New(context.Background()))
+	t.Cleanup(api.close)
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type client struct {
+	t     *testing.T
+	base  string
+	token string
+}
+
+func (c *client) do(method, path string, body io.Reader, contentType string) (int, []byte) {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.base+path, body)
+	require.NoError(c.t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	return resp.StatusCode, data
+}
+
+func (c *client) json(method, path string, payload interface{}) (int, []byte) {
+	c.t.Helper()
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		require.NoError(c.t, err)
+		body = bytes.NewReader(encoded)
+	}
+	return c.do(method, path, body, "application/json")
+}
+
+// register creates a user and returns its ID and a client logged in as it
+func register(t *testing.T, srv *httptest.Server, email string) (string, *client) {
+	anonymous := &client{t: t, base: srv.URL}
+	status, body := anonymous.json(http.MethodPost, "/users", map[string]string{
+		"email":      email,
+		"password":   "Integration-1!",
+		"first_name": "Integration",
+		"last_name":  "Test",
+	})
+	require.Equal(t, http.StatusCreated, status, string(body))
+	var created struct {
+		UserID string `json:"user_id"`
+	}
+	require.NoError(t, json.Unmarshal(body, &created))
+
+	form := url.Values{"email": {email}, "password": {"Integration-1!"}}
+	status, token := anonymous.do(http.MethodPost, "/login", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+	require.Equal(t, http.StatusOK, status, string(token))
+	return created.UserID, &client{t: t, base: srv.URL, token: string(token)}
+}
+
+func TestIntegration_RegisterLoginAndVote(t *testing.T) {
+	srv := newIntegrationServer(t, nil)
+	aliceID, alice := register(t, srv, "alice@example.com")
+	bobID, bob := register(t, srv, "bob@example.com")
+
+	status, body := alice.json(http.MethodPost, "/users", map[string]string{
+		"email": "alice@example.com", "password": "Integration-1!", "first_name": "Again", "last_name": "Again",
+	})
+	assert.Equal(t, http.StatusConflict, status, string(body))
+
+	status, body = alice.json(http.MethodPost, "/like/"+bobID, nil)
+	require.Equal(t, http.StatusCreated, status, string(body))
+	status, body = bob.json(http.MethodPost, "/dislike/"+aliceID, nil)
+	require.Equal(t, http.StatusCreated, status, string(body))
+
+	// A second vote within VOTE_COOLDOWN is refused
+	status, body = alice.json(http.MethodPost, "/dislike/"+bobID, nil)
+	assert.Equal(t, http.StatusTooManyRequests, status, string(body))
+	status, body = alice.json(http.MethodPost, "/like/"+aliceID, nil)
+	assert.Equal(t, http.StatusForbidden, status, string(body))
+
+	rating := func(id string) int {
+		status, body := alice.json(http.MethodGet, "/users/"+id, nil)
+		require.Equal(t, http.StatusOK, status, string(body))
+		var user struct {
+			Rating int `json:"rating"`
+		}
+		require.NoError(t, json.Unmarshal(body, &user))
+		return user.Rating
+	}
+	assert.Equal(t, 1, rating(bobID))
+	assert.Equal(t, -1, rating(aliceID))
+
+	status, body = alice.json(http.MethodGet, "/users?page=1&page_size=1", nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	var page struct {
+		Data    []json.RawMessage `json:"data"`
+		Total   int               `json:"total"`
+		HasNext bool              `json:"has_next"`
+	}
+	require.NoError(t, json.Unmarshal(body, &page))
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, 2, page.Total)
+	assert.True(t, page.HasNext)
+}
+
+func TestIntegration_LoginRejectsWrongPasswordAndUnknownEmail(t *testing.T) {
+	srv := newIntegrationServer(t, nil)
+	register(t, srv, "alice@example.com")
+
+	anonymous := &client{t: t, base: srv.URL}
+	for _, form := range []url.Values{
+		{"email": {"alice@example.com"}, "password": {"wrong-password-1!"}},
+		{"email": {"nobody@example.com"}, "password": {"Integration-1!"}},
+	} {
+		status, body := anonymous.do(http.MethodPost, "/login", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+		assert.Equal(t, http.StatusUnauthorized, status, string(body))
+	}
+}
+
+func TestIntegration_PgxUserRepoServesTheSameAPI(t *testing.T) {
+	srv := newIntegrationServer(t, map[string]string{"USER_REPO_DRIVER": "pgx"})
+	aliceID, alice := register(t, srv, "alice@example.com")
+	register(t, srv, "bob@example.com")
+
+	status, body := alice.json(http.MethodGet, "/users/"+aliceID, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Contains(t, string(body), "alice@example.com")
+
+	status, body = alice.json(http.MethodGet, "/users/count", nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Contains(t, string(body), "2")
+
+	status, body = alice.json(http.MethodGet, fmt.Sprintf("/users/%d", 1_000_000), nil)
+	assert.Equal(t, http.StatusNotFound, status, string(body))
+}
+
+func TestIntegration_Readyz(t *testing.T) {
+	srv := newIntegrationServer(t, nil)
+	status, body := (&client{t: t, base: srv.URL}).json(http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusOK, status, string(body))
+	assert.JSONEq(t, `{"status": "ok", "checks": {"database": "ok", "redis": "ok"}}`, string(body))
+}
+
+func TestIntegration_TermsVersionHasToBeAccepted(t *testing.T) {
+	srv := newIntegrationServer(t, map[string]string{"TERMS_VERSION": "2024-05"})
+	aliceID, alice := register(t, srv, "alice@example.com")
+
+	status, body := alice.json(http.MethodGet, "/users/"+aliceID+"/history", nil)
+	assert.Equal(t, http.StatusForbidden, status, string(body))
+	assert.Contains(t, string(body), "TERMS_NOT_ACCEPTED_ERR")
+
+	status, body = alice.json(http.MethodPost, "/me/terms", map[string]string{"version": "2024-05"})
+	require.Equal(t, http.StatusOK, status, string(body))
+	status, body = alice.json(http.MethodGet, "/users/"+aliceID+"/history", nil)
+	assert.Equal(t, http.StatusOK, status, string(body))
+}
+
+func TestIntegration_SignupsAreRateLimitedPerAddress(t *testing.T) {
+	srv := newIntegrationServer(t, map[string]string{"SIGNUP_RATE_LIMIT": "2", "TRUSTED_PROXIES": "127.0.0.1"})
+	register(t, srv, "alice@example.com")
+	register(t, srv, "bob@example.com")
+
+	signup := func(email, forwardedFor string) *http.Response {
+		payload := fmt.Sprintf(`{"email": %q, "password": "Integration-1!", "first_name": "Integration", "last_name": "Test"}`, email)
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/users", strings.NewReader(payload))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := signup("carol@example.com", "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	assert.Equal(t, http.StatusCreated, signup("carol@example.com", "198.51.100.7").StatusCode, "the proxy forwards another client")
+}
+
+func TestIntegration_LoginsAreThrottledPerAccount(t *testing.T) {
+	srv := newIntegrationServer(t, map[string]string{"LOGIN_THROTTLE_ATTEMPTS": "2", "LOGIN_THROTTLE_DELAY": "1m", "TRUSTED_PROXIES": "127.0.0.1"})
+	register(t, srv, "dave@example.com")
+
+	login := func(password, forwardedFor string) *http.Response {
+		form := url.Values{"email": {"dave@example.com"}, "password": {password}}
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/login", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// Guesses from different addresses count towards the same account
+	assert.Equal(t, http.StatusUnauthorized, login("Wrong-password-1!", "198.51.100.1").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, login("Wrong-password-2!", "198.51.100.2").StatusCode)
+
+	resp := login("Integration-1!", "198.51.100.3")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+}
+
+func TestIntegration_IntrospectTokens(t *testing.T) {
+	srv := newIntegrationServer(t, map[string]string{"INTROSPECTION_CLIENTS": "billing:s3cret"})
+	aliceID, alice := register(t, srv, "alice@example.com")
+
+	introspect := func(secret, token string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/auth/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("billing", secret)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+
+	status, _ := introspect("wrong", alice.token)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, body := introspect("s3cret", alice.token)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, body["active"])
+	assert.Equal(t, aliceID, body["sub"])
+	assert.Equal(t, "alice@example.com", body["username"])
+
+	status, body = introspect("s3cret", alice.token+"x")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"active": false}, body)
+}
+
+func TestIntegration_OAuthAuthorizationCodeWithPKCE(t *testing.T) {
+	// Everyone signs up as admin, so the test user can register the client
+	srv := newIntegrationServer(t, map[string]string{"DEFAULT_ROLE": "admin", "INTROSPECTION_CLIENTS": "billing:s3cret"})
+	_, alice := register(t, srv, "alice@example.com")
+
+	status, body := alice.json(http.MethodPost, "/admin/oauth/clients", map[string]interface{}{
+		"name":          "Calendar",
+		"redirect_uris": []string{"https://calendar.example.com/callback"},
+		"grant_types":   []string{"authorization_code"},
+		"scopes":        []string{"profile"},
+	})
+	require.Equal(t, http.StatusCreated, status, string(body))
+	var registered struct {
+		ClientID string `json:"client_id"`
+	}
+	require.NoError(t, json.Unmarshal(body, &registered))
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	authorization := url.Values{
+		"response_type": {"code"}, "client_id": {registered.ClientID}, "redirect_uri": {"https://calendar.example.com/callback"},
+		"scope": {"profile"}, "state": {"xyz"}, "code_challenge": {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"}, "code_challenge_method": {"S256"},
+	}
+	status, body = alice.json(http.MethodPost, "/oauth/authorize?"+authorization.Encode(), map[string]bool{"approve": true})
+	require.Equal(t, http.StatusOK, status, string(body))
+	var approved struct {
+		RedirectTo string `json:"redirect_to"`
+	}
+	require.NoError(t, json.Unmarshal(body, &approved))
+	redirect, err := url.Parse(approved.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", redirect.Query().Get("state"))
+
+	anonymous := &client{t: t, base: srv.URL}
+	exchange := url.Values{
+		"grant_type": {"authorization_code"}, "client_id": {registered.ClientID}, "code": {redirect.Query().Get("code")},
+		"redirect_uri": {"https://calendar.example.com/callback"}, "code_verifier": {verifier},
+	}
+	status, body = anonymous.do(http.MethodPost, "/oauth/token", strings.NewReader(exchange.Encode()), "application/x-www-form-urlencoded")
+	require.Equal(t, http.StatusOK, status, string(body))
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(body, &token))
+
+	status, body = anonymous.do(http.MethodPost, "/oauth/token", strings.NewReader(exchange.Encode()), "application/x-www-form-urlencoded")
+	assert.Equal(t, http.StatusBadRequest, status, "a code is exchanged once")
+	assert.Contains(t, string(body), "invalid_grant")
+
+	calendar := &client{t: t, base: srv.URL, token: token.AccessToken}
+	status, _ = calendar.json(http.MethodGet, "/me", nil)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = calendar.json(http.MethodGet, "/me/notifications", nil)
+	assert.Equal(t, http.StatusForbidden, status, "the notifications scope was not granted")
+	status, _ = calendar.json(http.MethodGet, "/admin/oauth/clients", nil)
+	assert.Equal(t, http.StatusForbidden, status, "admin routes have no scope")
+
+	introspect := func() map[string]interface{} {
+		form := url.Values{"client_id": {"billing"}, "client_secret": {"s3cret"}, "token": {token.AccessToken}}
+		status, body := anonymous.do(http.MethodPost, "/auth/introspect", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+		require.Equal(t, http.StatusOK, status, string(body))
+		var introspected map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &introspected))
+		return introspected
+	}
+	assert.Equal(t, registered.ClientID, introspect()["client_id"])
+	status, _ = alice.json(http.MethodDelete, "/me/oauth/consents/"+registered.ClientID, nil)
+	require.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, false, introspect()["active"], "the consent was revoked")
+}
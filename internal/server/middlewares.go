@@ -3,32 +3,50 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"runtime"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
 	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/handlers"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
+	"gitlab.com/jkozhemiaka/web-layout/internal/sentry"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
 )
 
 type CacheKeyGenerator func(r *http.Request) string
 
+// contextExpire serves GET responses from the cache for cacheTTL. Authenticated callers, let in by optionalAuth,
+// bypass the cache both ways: what they are shown may depend on who they are.
 func (srv *server) contextExpire(h http.HandlerFunc, keyGen CacheKeyGenerator, cacheTTL time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
 		defer cancel()
-		if r.Method != http.MethodGet {
+		if userID, _ := ctx.Value(models.IDContextKey).(string); r.Method != http.MethodGet || userID != "" {
 			r = r.WithContext(ctx)
 			h(w, r)
 			return
 		}
 
 		// Generate a cacheKey based on a custom function
-		cacheKey := keyGen(r)
+		cacheKey := tenancy.KeyPrefix(ctx) + keyGen(r)
 
 		cachedData, err := srv.cache.Get(ctx, cacheKey, cacheTTL)
 		if err == nil {
@@ -57,33 +75,315 @@ func (srv *server) contextExpire(h http.HandlerFunc, keyGen CacheKeyGenerator, c
 }
 
 func (srv *server) jwtMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return srv.authenticate("", h)
+}
+
+// optionalAuth is jwtMiddleware for public routes: requests without credentials are served anonymously, and ones
+// with credentials are authenticated, and refused when those are invalid, so h can tell who is asking
+func (srv *server) optionalAuth(h http.HandlerFunc) http.HandlerFunc {
+	authenticated := srv.authenticate("", h)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if (r.TLS != nil && len(r.TLS.VerifiedChains) > 0) || r.Header.Get("Authorization") != "" ||
+			r.Header.Get(auth.PartnerHeader) != "" || r.Header.Get(apiKeyHeader) != "" {
+			authenticated(w, r)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// invalidatesUser drops the cached GET /users/{id} response of the {id} of the route once h succeeds, for changes
+// such as a shadow ban that decide who may see the profile
+func (srv *server) invalidatesUser(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &bufferedResponseWriter{ResponseWriter: w, buffer: new(bytes.Buffer)}
+		h(recorder, r)
+		if recorder.statusCode != 0 && recorder.statusCode >= http.StatusMultipleChoices {
+			return
+		}
+		err := srv.cache.Delete(r.Context(), tenancy.KeyPrefix(r.Context())+generateUserCacheKey(r))
+		if err != nil {
+			srv.logger.Warnw("Failed to invalidate the cached user", "user_id", mux.Vars(r)["id"], "error", err)
+		}
+	}
+}
+
+// scopedMiddleware is jwtMiddleware for routes that OAuth clients may also call, with a token granted scope
+func (srv *server) scopedMiddleware(scope string, h http.HandlerFunc) http.HandlerFunc {
+	return srv.authenticate(scope, h)
+}
+
+// authenticate serves h to the user named by the bearer token or the X-API-Key header, to the service account of
+// a partner signing the request, or on the mutual TLS listener to the one of the client certificate. Tokens of OAuth clients are refused unless they were granted scope, and on
+// routes without one.
+func (srv *server) authenticate(scope string, h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			srv.authenticateCertificate(w, r, h)
+			return
+		}
+		if r.Header.Get(auth.PartnerHeader) != "" {
+			srv.authenticateSignature(w, r, h)
+			return
+		}
+		if r.Header.Get(apiKeyHeader) != "" {
+			srv.authenticateAPIKey(w, r, h)
+			return
+		}
+
 		tokenStr := r.Header.Get("Authorization")
 		if tokenStr == "" {
-			http.Error(w, "Missing token", http.StatusUnauthorized)
+			writeError(w, r, errors.New("Missing token"), http.StatusUnauthorized)
 			return
 		}
 
 		tokenStr = strings.TrimPrefix(tokenStr, "Bearer ")
 
-		claims := &auth.Claims{}
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(srv.cfg.JwtKey), nil
-		})
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		claims, err := srv.keys.Parse(tokenStr)
+		if err != nil {
+			writeError(w, r, errors.New("Invalid token"), http.StatusUnauthorized)
 			return
 		}
 		ID := strconv.FormatUint(uint64(claims.ID), 10)
 		if claims.Role == "" || claims.Email == "" || ID == "" {
-			http.Error(w, "token haven't info about Role,Email,ID", http.StatusUnauthorized)
+			writeError(w, r, errors.New("token haven't info about Role,Email,ID"), http.StatusUnauthorized)
+			return
+		}
+		if claims.ClientID != "" && (scope == "" || !claims.HasScope(scope)) {
+			writeError(w, r, apperrors.ForbiddenErr.AppendMessage("the token's scope does not allow this request"), http.StatusForbidden)
+			return
+		}
+		// A token without a tenant, or from another one, is refused; one tenant's users are nobody in another
+		if claims.TenantID == 0 || claims.TenantID != tenancy.Current(r.Context()) {
+			writeError(w, r, errors.New("Invalid token"), http.StatusUnauthorized)
+			return
+		}
+		if !srv.inGoodStanding(w, r, claims.ID) {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), models.RoleContextKey, claims.Role)
-		ctx = context.WithValue(ctx, models.EmailContextKey, claims.Email)
-		ctx = context.WithValue(ctx, models.IDContextKey, ID)
+		ctx := withClaims(r.Context(), claims)
 		r = r.WithContext(ctx)
+		if !termsExemptPaths[r.URL.Path] {
+			err := srv.terms.CheckAccepted(ctx, claims.ID)
+			if errors.Is(err, &apperrors.TermsNotAcceptedErr) {
+				writeError(w, r, err, http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				writeError(w, r, err, http.StatusInternalServerError)
+				return
+			}
+		}
+		h(w, r)
+	}
+}
+
+// authenticateCertificate serves h to the service account the verified client certificate maps to
+func (srv *server) authenticateCertificate(w http.ResponseWriter, r *http.Request, h http.HandlerFunc) {
+	identity, ok := certificateIdentity(r.TLS.VerifiedChains[0][0], srv.cfg.MTLSIdentities)
+	if !ok {
+		writeError(w, r, errors.New("Invalid certificate"), http.StatusUnauthorized)
+		return
+	}
+	srv.serveAccount(w, r, srv.cfg.MTLSIdentities[identity], srv.cfg.MTLSScopes[identity], errors.New("Invalid certificate"), h)
+}
+
+// authenticateSignature serves h to the service account of the partner whose secret signed the request, see
+// auth.SignRequest. The body is read to check it and handed to h unchanged.
+func (srv *server) authenticateSignature(w http.ResponseWriter, r *http.Request, h http.HandlerFunc) {
+	partner := r.Header.Get(auth.PartnerHeader)
+	secret, ok := srv.cfg.PartnerSecrets[partner]
+	if !ok {
+		writeError(w, r, errors.New("Invalid signature"), http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = auth.VerifySignature([]byte(secret), r.Header.Get(auth.TimestampHeader), r.Method, r.URL.RequestURI(), body,
+		r.Header.Get(auth.SignatureHeader), time.Now(), srv.cfg.PartnerSignatureSkew)
+	if err != nil {
+		writeError(w, r, err, http.StatusUnauthorized)
+		return
+	}
+	srv.serveAccount(w, r, srv.cfg.PartnerAccounts[partner], srv.cfg.PartnerScopes[partner], errors.New("Invalid signature"), h)
+}
+
+// serveAccount serves h to the service account with email, which has the space-separated permissions in scopes,
+// answering with unknown when there is none
+func (srv *server) serveAccount(w http.ResponseWriter, r *http.Request, email, scopes string, unknown error, h http.HandlerFunc) {
+	user, err := srv.userService.GetUserByEmail(r.Context(), email)
+	if errors.Is(err, &apperrors.NoRecordFoundErr) {
+		writeError(w, r, unknown, http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	claims := auth.NewClaims(user.Email, user.Role.Name, user.ID, 0)
+	if user.ServiceAccount {
+		claims.Permissions = strings.Fields(scopes)
+	}
+	srv.serveClaims(w, r, user, claims, h)
+}
+
+// apiKeyHeader carries the API keys admins create at /admin/api-keys
+const apiKeyHeader = "X-API-Key"
+
+// authenticateAPIKey serves h to the user of the API key, within the rate limit of the key's tier. A service account
+// has the permissions of its key's scopes only, not those of its role.
+func (srv *server) authenticateAPIKey(w http.ResponseWriter, r *http.Request, h http.HandlerFunc) {
+	key, err := srv.apiKeys.Authenticate(r.Context(), r.Header.Get(apiKeyHeader))
+	if errors.Is(err, &apperrors.UnauthorizedErr) {
+		writeError(w, r, err, http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	allowed, retryAfter := srv.apiKeys.Allow(r.Context(), key)
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		writeError(w, r, apperrors.TooManyRequestsErr.AppendMessage("the API key is over the rate limit of its tier"), http.StatusTooManyRequests)
+		return
+	}
+	claims := auth.NewClaims(key.User.Email, key.User.Role.Name, key.User.ID, 0)
+	if key.User.ServiceAccount {
+		claims.Permissions = key.Permissions()
+	}
+	srv.serveClaims(w, r, key.User, claims, h)
+}
+
+// serveClaims serves h to user, authorized by claims, without checking the terms, which service accounts and
+// programs do not accept. A service account has the permissions its credential grants and never those of its
+// role, whichever credential it used; the admin audit tells its actions from those of people.
+func (srv *server) serveClaims(w http.ResponseWriter, r *http.Request, user *models.User, claims *auth.Claims, h http.HandlerFunc) {
+	if !srv.inGoodStanding(w, r, user.ID) {
+		return
+	}
+	// The user was looked up in the tenant of the request, so that is the tenant of their credential
+	claims.TenantID = tenancy.Current(r.Context())
+	if user.ServiceAccount && claims.Permissions == nil {
+		claims.Permissions = []string{}
+	}
+	ctx := withClaims(r.Context(), claims)
+	if user.ServiceAccount {
+		ctx = context.WithValue(ctx, models.ServiceAccountContextKey, true)
+	}
+	h(w, r.WithContext(ctx))
+}
+
+// inGoodStanding answers 403 for a locked or banned user, whose tokens and keys stop working with the lock or ban
+// rather than when they expire
+func (srv *server) inGoodStanding(w http.ResponseWriter, r *http.Request, userID uint) bool {
+	err := services.CheckStanding(r.Context(), srv.loginAlerts, srv.bans, userID)
+	var appError *apperrors.AppError
+	if errors.As(err, &appError) && appError.HTTPCode != 0 {
+		writeError(w, r, err, appError.HTTPCode)
+		return false
+	}
+	if err != nil {
+		writeError(w, r, err, http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// withClaims authenticates ctx as the user of claims
+func withClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	ctx = auth.WithClaims(ctx, claims)
+	ctx = context.WithValue(ctx, models.RoleContextKey, claims.Role)
+	ctx = context.WithValue(ctx, models.EmailContextKey, claims.Email)
+	return context.WithValue(ctx, models.IDContextKey, strconv.FormatUint(uint64(claims.ID), 10))
+}
+
+// requireScope is jwtMiddleware for routes that need the permission scope, e.g. "stats.read". Admins have every
+// permission. Tokens that carry the user's permissions (JWT_CLAIMS) are checked as they are; for others the
+// permissions of the user's role are looked up.
+func (srv *server) requireScope(scope string, h http.HandlerFunc) http.HandlerFunc {
+	return srv.jwtMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		claims := auth.ClaimsFromContext(r.Context())
+		allowed := claims.Role == models.StrAdmin || claims.HasPermission(scope)
+		if !allowed && !claims.CarriesPermissions() {
+			user, err := srv.userService.GetUser(r.Context(), strconv.FormatUint(uint64(claims.ID), 10))
+			if err != nil {
+				writeError(w, r, err, http.StatusUnauthorized)
+				return
+			}
+			allowed = srv.permissions.HasPermission(r.Context(), user.RoleID, scope)
+		}
+		if !allowed {
+			writeError(w, r, apperrors.ForbiddenErr.AppendMessage("the "+scope+" permission is required"), http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	})
+}
+
+// termsExemptPaths are served to users who have not accepted the current terms version, so they can read and
+// accept it
+var termsExemptPaths = map[string]bool{"/me/terms": true}
+
+// tenantMiddleware resolves the tenant of the request from the tenant header or the hostname
+// and scopes the request context to it
+func (srv *server) tenantMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+
+		tenant, err := srv.tenantService.ResolveTenant(r.Context(), r.Header.Get(srv.cfg.TenantHeader), host)
+		if err != nil {
+			writeError(w, r, errors.New("Unknown tenant"), http.StatusNotFound)
+			return
+		}
+
+		r = r.WithContext(tenancy.WithTenant(r.Context(), tenant.ID))
+		h(w, r)
+	}
+}
+
+// quotaMiddleware counts the request against the daily request quota of its tenant and refuses it past the
+// limit. A failure to count is logged and the request served, so the quota store cannot take the API down.
+func (srv *server) quotaMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := srv.quotas.CountRequest(r.Context())
+		if errors.Is(err, &apperrors.QuotaExceededErr) {
+			writeError(w, r, err, http.StatusTooManyRequests)
+			return
+		}
+		if err != nil {
+			srv.logger.Error(err)
+		}
+		h(w, r)
+	}
+}
+
+// signupRateLimit refuses account creation from a client address past SIGNUP_RATE_LIMIT per window, a policy
+// of its own because signups cost far more than other requests. A failing limiter is logged and the request
+// served, so Redis cannot take registration down.
+func (srv *server) signupRateLimit(h http.HandlerFunc) http.HandlerFunc {
+	if srv.signupLimiter == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter, err := srv.signupLimiter.Allow(r.Context(), ratelimit.ClientIP(r, srv.trustedProxies))
+		if err != nil {
+			srv.logger.Error(err)
+		}
+		if err == nil && !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeError(w, r, apperrors.TooManyRequestsErr.AppendMessage("too many signups from this address"), http.StatusTooManyRequests)
+			return
+		}
 		h(w, r)
 	}
 }
@@ -109,3 +409,75 @@ func CacheGenId(r *http.Request) string {
 	vars := mux.Vars(r)
 	return "user:" + vars["id"]
 }
+
+// maxRequestIDLength bounds the X-Request-ID accepted from clients, longer ones are replaced
+const maxRequestIDLength = 64
+
+// requestIDMiddleware keeps the client's X-Request-ID or generates one, and echoes it in the response
+func requestIDMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			id := make([]byte, 16)
+			rand.Read(id)
+			requestID = hex.EncodeToString(id)
+		}
+		w.Header().Set("X-Request-ID", requestID)
+		h(w, r.WithContext(context.WithValue(r.Context(), models.RequestIDContextKey, requestID)))
+	}
+}
+
+// recoveryMiddleware answers a panicking request with 500 instead of dropping the connection, logging and
+// reporting the panic with its stack. It also makes the request report errors to Sentry when configured.
+func (srv *server) recoveryMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(sentry.WithClient(r.Context(), srv.sentry))
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http aborts the response quietly for this one
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+
+			pcs := make([]uintptr, 64)
+			// Skip runtime.Callers, this function and runtime.gopanic
+			pcs = pcs[:runtime.Callers(3, pcs)]
+			srv.logger.Errorw("Recovered from panic", "error", err, "path", r.URL.Path, "stack", string(debug.Stack()))
+			srv.sentry.CaptureRequest(r, sentry.LevelFatal, err, pcs)
+			writeError(w, r, &apperrors.InternalErr, http.StatusInternalServerError)
+		}()
+		h(w, r)
+	}
+}
+
+// languageMiddleware negotiates the language of error messages from Accept-Language
+func languageMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", lang.String())
+		w.Header().Add("Vary", "Accept-Language")
+		h(w, r.WithContext(i18n.WithLanguage(r.Context(), lang)))
+	}
+}
+
+// clientMiddleware keeps the address and user agent of the client in the context for the security events
+func (srv *server) clientMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := &models.Client{IP: ratelimit.ClientIP(r, srv.trustedProxies), UserAgent: r.UserAgent()}
+		h(w, r.WithContext(context.WithValue(r.Context(), models.ClientContextKey, client)))
+	}
+}
+
+// writeError answers with the same JSON error body as the handlers
+func writeError(w http.ResponseWriter, r *http.Request, err error, httpStatus int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(handlers.NewErrorResponse(err, httpStatus, i18n.FromContext(r.Context())))
+}
@@ -0,0 +1,333 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// goodStanding returns login alerts and bans that lock and ban no one
+func goodStanding(ctrl *gomock.Controller) (services.LoginAlertServiceInterface, services.BanServiceInterface) {
+	loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+	loginAlerts.EXPECT().Locked(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+	bans := services.NewMockBanServiceInterface(ctrl)
+	bans.EXPECT().ActiveBan(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	return loginAlerts, bans
+}
+
+func TestRequireScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	terms := services.NewMockTermsServiceInterface(ctrl)
+	terms.EXPECT().CheckAccepted(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	userService := services.NewMockUserServiceInterface(ctrl)
+	permissions := services.NewMockPermissionServiceInterface(ctrl)
+	srv := &server{
+		cfg:         &config.Config{},
+		keys:        auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:      zap.NewNop().Sugar(),
+		terms:       terms,
+		userService: userService,
+		permissions: permissions,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	handler := srv.requireScope(models.PermissionStatsRead, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(claims *auth.Claims, tenantID uint) int {
+		token, err := srv.keys.Sign(claims)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if tenantID != 0 {
+			req = req.WithContext(tenancy.WithTenant(req.Context(), tenantID))
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder.Code
+	}
+	user := func(permissions ...string) *auth.Claims {
+		claims := auth.NewClaims("ann@example.com", models.StrUser, 12, time.Hour)
+		claims.TenantID = tenancy.DefaultTenantID
+		if permissions != nil {
+			claims.Embed([]string{auth.ClaimTenant, auth.ClaimPermissions}, 1, permissions)
+		}
+		return claims
+	}
+
+	admin := auth.NewClaims("ops@example.com", models.StrAdmin, 3, time.Hour)
+	admin.TenantID = tenancy.DefaultTenantID
+	assert.Equal(t, http.StatusOK, serve(admin, 0), "admins have every permission")
+	assert.Equal(t, http.StatusUnauthorized, serve(auth.NewClaims("ops@example.com", models.StrAdmin, 3, time.Hour), 0), "every token is bound to a tenant")
+	assert.Equal(t, http.StatusOK, serve(user(models.PermissionStatsRead), 1), "embedded permissions need no lookup")
+	assert.Equal(t, http.StatusForbidden, serve(user(models.PermissionAuditRead), 1))
+	assert.Equal(t, http.StatusUnauthorized, serve(user(models.PermissionStatsRead), 2), "the token was issued in another tenant")
+
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(&models.User{ID: 12, RoleID: 1}, nil).Times(2)
+	permissions.EXPECT().HasPermission(gomock.Any(), uint(1), models.PermissionStatsRead).Return(true)
+	assert.Equal(t, http.StatusOK, serve(user(), 0), "without embedded permissions the role's are looked up")
+	permissions.EXPECT().HasPermission(gomock.Any(), uint(1), models.PermissionStatsRead).Return(false)
+	assert.Equal(t, http.StatusForbidden, serve(user(), 0))
+
+	client := user()
+	client.ClientID, client.Scope = "app", models.PermissionStatsRead
+	assert.Equal(t, http.StatusForbidden, serve(client, 0), "OAuth tokens are limited to the routes of their scopes")
+}
+
+func TestAuthenticateCertificate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	srv := &server{
+		cfg:         &config.Config{MTLSIdentities: map[string]string{"billing.internal": "billing@svc.example.com"}},
+		keys:        auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:      zap.NewNop().Sugar(),
+		userService: userService,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	var authenticated *auth.Claims
+	handler := srv.jwtMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = auth.ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(cert *x509.Certificate) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder.Code
+	}
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "billing@svc.example.com").
+		Return(&models.User{ID: 40, Email: "billing@svc.example.com", Role: models.Role{Name: models.StrUser}}, nil).Times(2)
+	assert.Equal(t, http.StatusOK, serve(&x509.Certificate{Subject: pkix.Name{CommonName: "billing.internal"}}), "no token is needed")
+	assert.Equal(t, uint(40), authenticated.ID)
+	assert.Equal(t, models.StrUser, authenticated.Role)
+	assert.Equal(t, http.StatusOK, serve(&x509.Certificate{Subject: pkix.Name{CommonName: "billing"}, DNSNames: []string{"billing.internal"}}))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(&x509.Certificate{Subject: pkix.Name{CommonName: "search.internal"}}))
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "billing@svc.example.com").Return(nil, apperrors.NoRecordFoundErr.AppendMessage("User not found."))
+	assert.Equal(t, http.StatusUnauthorized, serve(&x509.Certificate{Subject: pkix.Name{CommonName: "billing.internal"}}), "the service account was deleted")
+}
+
+func TestAuthenticateCertificate_ServiceAccountScopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	srv := &server{
+		cfg: &config.Config{
+			MTLSIdentities: map[string]string{"billing.internal": "billing@svc.example.com", "search.internal": "search@svc.example.com"},
+			MTLSScopes:     map[string]string{"billing.internal": models.PermissionStatsRead},
+		},
+		keys:        auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:      zap.NewNop().Sugar(),
+		userService: userService,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	handler := srv.requireScope(models.PermissionStatsRead, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(name string) int {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+		req := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder.Code
+	}
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "billing@svc.example.com").
+		Return(&models.User{ID: 40, Email: "billing@svc.example.com", Role: models.Role{Name: models.StrModerator}, ServiceAccount: true}, nil)
+	assert.Equal(t, http.StatusOK, serve("billing.internal"))
+
+	// The moderator role is not looked up: the certificate grants no permissions
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "search@svc.example.com").
+		Return(&models.User{ID: 42, Email: "search@svc.example.com", Role: models.Role{Name: models.StrModerator}, ServiceAccount: true}, nil)
+	assert.Equal(t, http.StatusForbidden, serve("search.internal"))
+}
+
+func TestAuthenticateSignature(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	srv := &server{
+		cfg: &config.Config{
+			PartnerSecrets:       map[string]string{"acme": "partner-secret"},
+			PartnerAccounts:      map[string]string{"acme": "acme@partners.example.com"},
+			PartnerSignatureSkew: 5 * time.Minute,
+		},
+		keys:        auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:      zap.NewNop().Sugar(),
+		userService: userService,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	var received string
+	handler := srv.jwtMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	const body = `{"first_name":"Ann"}`
+	serve := func(partner, secret string, at time.Time, sentBody string) int {
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPut, "/users/40?notify=1", strings.NewReader(sentBody))
+		req.Header.Set(auth.PartnerHeader, partner)
+		req.Header.Set(auth.TimestampHeader, timestamp)
+		req.Header.Set(auth.SignatureHeader, auth.SignRequest([]byte(secret), timestamp, http.MethodPut, "/users/40?notify=1", []byte(body)))
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder.Code
+	}
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "acme@partners.example.com").
+		Return(&models.User{ID: 41, Email: "acme@partners.example.com", Role: models.Role{Name: models.StrUser}}, nil)
+	assert.Equal(t, http.StatusOK, serve("acme", "partner-secret", time.Now(), body))
+	assert.Equal(t, body, received, "the handler reads the body that was signed")
+
+	assert.Equal(t, http.StatusUnauthorized, serve("acme", "partner-secret", time.Now(), `{"role":"admin"}`), "the body was changed")
+	assert.Equal(t, http.StatusUnauthorized, serve("acme", "partner-secret", time.Now().Add(-10*time.Minute), body), "replayed later than the skew")
+	assert.Equal(t, http.StatusUnauthorized, serve("acme", "wrong-secret", time.Now(), body))
+	assert.Equal(t, http.StatusUnauthorized, serve("globex", "partner-secret", time.Now(), body))
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	apiKeys := services.NewMockAPIKeyServiceInterface(ctrl)
+	srv := &server{
+		cfg:     &config.Config{},
+		keys:    auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:  zap.NewNop().Sugar(),
+		apiKeys: apiKeys,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	var authenticated *auth.Claims
+	handler := srv.jwtMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = auth.ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("X-API-Key", value)
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder
+	}
+	key := &models.APIKey{ID: 7, UserID: 40, Tier: "free", User: &models.User{ID: 40, Email: "billing@svc.example.com", Role: models.Role{Name: models.StrUser}}}
+
+	apiKeys.EXPECT().Authenticate(gomock.Any(), "uk_valid").Return(key, nil).Times(2)
+	apiKeys.EXPECT().Allow(gomock.Any(), key).Return(true, time.Duration(0))
+	assert.Equal(t, http.StatusOK, serve("uk_valid").Code)
+	assert.Equal(t, uint(40), authenticated.ID)
+
+	apiKeys.EXPECT().Allow(gomock.Any(), key).Return(false, 1500*time.Millisecond)
+	limited := serve("uk_valid")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "2", limited.Header().Get("Retry-After"))
+
+	apiKeys.EXPECT().Authenticate(gomock.Any(), "uk_unknown").Return(nil, apperrors.UnauthorizedErr.AppendMessage("unknown API key"))
+	assert.Equal(t, http.StatusUnauthorized, serve("uk_unknown").Code)
+}
+
+func TestAuthenticateAPIKey_ServiceAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	apiKeys := services.NewMockAPIKeyServiceInterface(ctrl)
+	srv := &server{
+		cfg:     &config.Config{},
+		keys:    auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:  zap.NewNop().Sugar(),
+		apiKeys: apiKeys,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	var actorType string
+	handler := srv.requireScope(models.PermissionStatsRead, func(w http.ResponseWriter, r *http.Request) {
+		actorType = models.ActorType(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(value string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+		req.Header.Set("X-API-Key", value)
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder.Code
+	}
+	account := &models.User{ID: 40, Email: "reports@svc.example.com", Role: models.Role{Name: models.StrModerator}, ServiceAccount: true}
+	scoped := &models.APIKey{ID: 7, UserID: 40, Tier: "free", Scopes: models.PermissionStatsRead, User: account}
+	unscoped := &models.APIKey{ID: 8, UserID: 40, Tier: "free", User: account}
+	apiKeys.EXPECT().Allow(gomock.Any(), gomock.Any()).Return(true, time.Duration(0)).AnyTimes()
+
+	apiKeys.EXPECT().Authenticate(gomock.Any(), "uk_scoped").Return(scoped, nil)
+	assert.Equal(t, http.StatusOK, serve("uk_scoped"))
+	assert.Equal(t, models.ActorServiceAccount, actorType)
+
+	// The role of the account is not looked up, the key has no scopes so it has no permissions
+	apiKeys.EXPECT().Authenticate(gomock.Any(), "uk_unscoped").Return(unscoped, nil)
+	assert.Equal(t, http.StatusForbidden, serve("uk_unscoped"))
+}
+
+func TestAuthenticate_LockedOrBannedUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	terms := services.NewMockTermsServiceInterface(ctrl)
+	terms.EXPECT().CheckAccepted(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	loginAlerts := services.NewMockLoginAlertServiceInterface(ctrl)
+	bans := services.NewMockBanServiceInterface(ctrl)
+	apiKeys := services.NewMockAPIKeyServiceInterface(ctrl)
+	srv := &server{
+		cfg:         &config.Config{},
+		keys:        auth.NewHMACKeys([]byte("middleware-secret")),
+		logger:      zap.NewNop().Sugar(),
+		terms:       terms,
+		loginAlerts: loginAlerts,
+		bans:        bans,
+		apiKeys:     apiKeys,
+	}
+	handler := srv.jwtMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(header, value)
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder
+	}
+	claims := auth.NewClaims("ann@example.com", models.StrUser, 12, time.Hour)
+	claims.TenantID = tenancy.DefaultTenantID
+	token, err := srv.keys.Sign(claims)
+	require.NoError(t, err)
+
+	// The token was issued before the lock, it stops working with it
+	loginAlerts.EXPECT().Locked(gomock.Any(), uint(12)).Return(true, nil)
+	locked := serve("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusForbidden, locked.Code)
+	assert.Contains(t, locked.Body.String(), "ACCOUNT_LOCKED_ERR")
+
+	loginAlerts.EXPECT().Locked(gomock.Any(), uint(12)).Return(false, nil)
+	bans.EXPECT().ActiveBan(gomock.Any(), uint(12)).Return(&models.Ban{UserID: 12, Reason: "Spam", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	banned := serve("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusForbidden, banned.Code)
+	assert.Contains(t, banned.Body.String(), "USER_BANNED_ERR")
+
+	key := &models.APIKey{ID: 7, UserID: 12, Tier: "free", User: &models.User{ID: 12, Email: "ann@example.com", Role: models.Role{Name: models.StrUser}}}
+	apiKeys.EXPECT().Authenticate(gomock.Any(), "uk_valid").Return(key, nil)
+	apiKeys.EXPECT().Allow(gomock.Any(), key).Return(true, time.Duration(0))
+	loginAlerts.EXPECT().Locked(gomock.Any(), uint(12)).Return(true, nil)
+	assert.Equal(t, http.StatusForbidden, serve("X-API-Key", "uk_valid").Code, "API keys stop working too")
+
+	loginAlerts.EXPECT().Locked(gomock.Any(), uint(12)).Return(false, nil)
+	bans.EXPECT().ActiveBan(gomock.Any(), uint(12)).Return(nil, nil)
+	assert.Equal(t, http.StatusOK, serve("Authorization", "Bearer "+token).Code)
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"gitlab.com/jkozhemiaka/web-layout/internal/jobs"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/openapi"
+	"gitlab.com/jkozhemiaka/web-layout/internal/services"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// memoryCache is a cache.CacheInterface in a map, whose entries never expire
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, cacheTTL time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.entries[key]
+	if !ok {
+		return "", errors.New("cache miss")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value string, cacheTTL time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = value
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// newRoutedServer wires every route of initializeRoutes in front of the given services; requests go through
+// ServeHTTP as in production
+func newRoutedServer(t *testing.T, ctrl *gomock.Controller, userService services.UserServiceInterface, shadowBans services.ShadowBanServiceInterface) (*server, *memoryCache) {
+	t.Helper()
+	terms := services.NewMockTermsServiceInterface(ctrl)
+	terms.EXPECT().CheckAccepted(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	responses := &memoryCache{entries: map[string]string{}}
+	srv := &server{
+		cache:       responses,
+		router:      &router{mux: mux.NewRouter()},
+		cfg:         &config.Config{},
+		keys:        auth.NewHMACKeys([]byte("routes-secret")),
+		logger:      zap.NewNop().Sugar(),
+		terms:       terms,
+		userService: userService,
+		shadowBans:  shadowBans,
+	}
+	srv.loginAlerts, srv.bans = goodStanding(ctrl)
+	srv.initializeRoutes()
+	return srv, responses
+}
+
+func TestRoutes_ShadowBannedUsersSeeTheirOwnProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := services.NewMockUserServiceInterface(ctrl)
+	shadowBans := services.NewMockShadowBanServiceInterface(ctrl)
+	srv, responses := newRoutedServer(t, ctrl, userService, shadowBans)
+
+	ann := &models.User{ID: 12, Email: "ann@example.com", Role: models.Role{Name: models.StrUser}}
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(ann, nil).AnyTimes()
+	token := func(userID uint, role string) string {
+		claims := auth.NewClaims("someone@example.com", role, userID, time.Hour)
+		claims.TenantID = tenancy.DefaultTenantID
+		signed, err := srv.keys.Sign(claims)
+		require.NoError(t, err)
+		return "Bearer " + signed
+	}
+	get := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/users/12", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		srv.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Cached for anonymous callers before the shadow ban
+	shadowBans.EXPECT().IsShadowBanned(gomock.Any(), uint(12)).Return(false, nil)
+	assert.Equal(t, http.StatusCreated, get(""))
+	assert.Len(t, responses.entries, 1)
+
+	shadowBans.EXPECT().ShadowBan(gomock.Any(), gomock.Any(), uint(12)).Return(nil)
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/12/shadow-ban", nil)
+	req.Header.Set("Authorization", token(3, models.StrAdmin))
+	recorder := httptest.NewRecorder()
+	srv.ServeHTTP(recorder, req)
+	require.Less(t, recorder.Code, http.StatusMultipleChoices, recorder.Body.String())
+	assert.Empty(t, responses.entries, "the shadow ban drops the cached profile")
+
+	shadowBans.EXPECT().IsShadowBanned(gomock.Any(), uint(12)).Return(true, nil).Times(2)
+	assert.Equal(t, http.StatusNotFound, get(""), "others no longer find the user")
+	assert.Equal(t, http.StatusNotFound, get(token(5, models.StrUser)))
+	assert.Equal(t, http.StatusCreated, get(token(12, models.StrUser)), "the user still sees themselves")
+	assert.Equal(t, http.StatusCreated, get(token(12, models.StrUser)))
+	assert.Empty(t, responses.entries, "responses to authenticated callers are not cached")
+	assert.Equal(t, http.StatusUnauthorized, get("Bearer invalid"), "credentials that are sent are checked")
+}
+
+// noDeliveries is a jobs.DeliveryLog without any delivery, enough to register the deliveries route
+type noDeliveries struct{}
+
+func (noDeliveries) ListWebhookDeliveries(ctx context.Context, eventID string, limit int) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+// routePattern is a mux variable with its pattern, such as {id:[0-9]+}
+var routePattern = regexp.MustCompile(`\{([^:}]+):[^}]*\}`)
+
+// TestRoutes_Documented keeps internal/openapi/openapi.yaml and the router in step: every route, the optional ones
+// included, has an operation in the document and every operation of the document has a route
+func TestRoutes_Documented(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srv, _ := newRoutedServer(t, ctrl, services.NewMockUserServiceInterface(ctrl), services.NewMockShadowBanServiceInterface(ctrl))
+	srv.router = &router{mux: mux.NewRouter()}
+	srv.cfg = &config.Config{PprofEnabled: true}
+	srv.introspection = services.NewMockIntrospectionServiceInterface(ctrl)
+	srv.quotas = services.NewMockQuotaServiceInterface(ctrl)
+	srv.jobQueue = &jobs.Queue{}
+	srv.webhookDeliveries = noDeliveries{}
+	srv.initializeRoutes()
+
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	routed := map[string]bool{}
+	err = srv.router.(*router).mux.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		template = routePattern.ReplaceAllString(template, "{$1}")
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			operation := strings.ToLower(method) + " " + template
+			routed[operation] = true
+			_, ok := spec.Paths[template][strings.ToLower(method)]
+			assert.True(t, ok, "%s %s is not documented in internal/openapi/openapi.yaml", method, template)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var documented []string
+	for template, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			documented = append(documented, method+" "+template)
+		}
+	}
+	sort.Strings(documented)
+	for _, operation := range documented {
+		assert.True(t, routed[operation], "%s is documented but not routed", operation)
+	}
+}
@@ -1,15 +1,26 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"time"
 
-	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/app"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/buildinfo"
 	"gitlab.com/jkozhemiaka/web-layout/internal/cache"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
+	"gitlab.com/jkozhemiaka/web-layout/internal/geoip"
 	"gitlab.com/jkozhemiaka/web-layout/internal/handlers"
+	"gitlab.com/jkozhemiaka/web-layout/internal/jobs"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
 	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/sentry"
 	"gitlab.com/jkozhemiaka/web-layout/internal/services"
 	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
 
@@ -23,83 +34,693 @@ import (
 )
 
 type server struct {
-	db          *gorm.DB
-	cache       cache.CacheInterface
-	router      Router
-	logger      *zap.SugaredLogger
-	validator   *validator.Validate
-	cfg         *config.Config
-	userService services.UserServiceInterface
+	db             *gorm.DB
+	cache          cache.CacheInterface
+	router         Router
+	logger         *zap.SugaredLogger
+	validator      *validator.Validate
+	cfg            *config.Config
+	userService    services.UserServiceInterface
+	eventService   services.EventServiceInterface
+	tenantService  services.TenantServiceInterface
+	notifications  services.NotificationServiceInterface
+	identities     services.IdentityServiceInterface
+	invitations    services.InvitationServiceInterface
+	organizations  services.OrganizationServiceInterface
+	terms          services.TermsServiceInterface
+	changes        services.ChangeServiceInterface
+	activity       services.ActivityServiceInterface
+	featureFlags   services.FeatureFlagServiceInterface
+	permissions    services.PermissionServiceInterface
+	signupRoles    services.SignupRoles
+	stats          services.StatsServiceInterface
+	security       services.SecurityServiceInterface
+	securityEvents services.SecurityEventServiceInterface
+	loginAlerts    services.LoginAlertServiceInterface
+	follows        services.FollowServiceInterface
+	reports        services.ReportServiceInterface
+	bans           services.BanServiceInterface
+	shadowBans     services.ShadowBanServiceInterface
+	phones         services.PhoneServiceInterface
+	remember       services.RememberServiceInterface
+	adminAudit     services.AdminAuditServiceInterface
+	oauth          services.OAuthServiceInterface
+	tokens         services.TokenServiceInterface
+	apiKeys        services.APIKeyServiceInterface
+	keys           *auth.Keys
+	// effectiveConfig is cfg with the settings changed by reloads applied
+	effectiveConfig func() *config.Config
+	// sentry is nil without SENTRY_DSN
+	sentry *sentry.Client
+	// jobQueue is nil with JOBS_ENABLED=false
+	jobQueue *jobs.Queue
+	// introspection is nil without INTROSPECTION_CLIENTS
+	introspection services.IntrospectionServiceInterface
+	// quotas is nil with QUOTAS_ENABLED=false
+	quotas services.QuotaServiceInterface
+	// signupLimiter is nil with SIGNUP_RATE_LIMIT=0
+	signupLimiter ratelimit.Limiter
+	// loginThrottle is nil with LOGIN_THROTTLE_ATTEMPTS=0
+	loginThrottle ratelimit.Throttle
+	// trustedProxies are the peers whose X-Forwarded-For names the client
+	trustedProxies []*net.IPNet
+	// webhookDeliveries is nil unless webhooks are delivered by the queue
+	webhookDeliveries jobs.DeliveryLog
+	// healthChecks are the dependencies /readyz reports on
+	healthChecks map[string]handlers.HealthCheck
+	// closers release what newServer opened besides the shared app resources
+	closers []func()
 }
 
+// tenantlessPaths are served without resolving a tenant, so orchestrators, operators and resource servers can reach
+// any replica by address
+var tenantlessPaths = map[string]bool{"/livez": true, "/readyz": true, "/version": true, "/.well-known/jwks.json": true}
+
 func (srv *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	srv.router.ServeHttp(w, r)
+	handler := srv.router.ServeHttp
+	if srv.quotas != nil && !tenantlessPaths[r.URL.Path] {
+		handler = srv.quotaMiddleware(handler)
+	}
+	if srv.cfg.TenancyEnabled && !tenantlessPaths[r.URL.Path] {
+		handler = srv.tenantMiddleware(handler)
+	}
+	requestIDMiddleware(srv.recoveryMiddleware(languageMiddleware(srv.clientMiddleware(handler))))(w, r)
 }
 
 func (srv *server) initializeRoutes() {
-	userHandler := handlers.NewUserHandler(srv.userService, srv.logger, srv.validator, srv.cfg)
-	loginHandler := handlers.NewLoginHandler(srv.userService, srv.logger, srv.cfg)
-	votesHandler := handlers.NewVotesHandler(srv.userService, srv.logger, srv.cfg)
+	userHandler := handlers.NewUserHandler(srv.userService, srv.featureFlags, srv.adminAudit, srv.securityEvents, srv.shadowBans, srv.signupRoles, srv.logger, srv.validator, srv.cfg)
+	loginHandler := handlers.NewLoginHandler(srv.userService, srv.identities, srv.activity, srv.security, srv.securityEvents, srv.loginAlerts, srv.bans, srv.phones, srv.remember, srv.tokens, srv.loginThrottle, srv.trustedProxies, srv.logger, srv.cfg)
+	votesHandler := handlers.NewVotesHandler(srv.userService, srv.featureFlags, srv.logger, srv.cfg)
+	eventsHandler := handlers.NewEventsHandler(srv.eventService, srv.logger, srv.cfg)
+	featureFlagsHandler := handlers.NewFeatureFlagsHandler(srv.featureFlags, srv.logger, srv.cfg)
+	permissionsHandler := handlers.NewPermissionsHandler(srv.permissions, srv.logger, srv.validator)
+	statsHandler := handlers.NewStatsHandler(srv.stats, srv.logger)
+	securityHandler := handlers.NewSecurityHandler(srv.security, srv.securityEvents, srv.logger)
+	adminAuditHandler := handlers.NewAdminAuditHandler(srv.adminAudit, srv.logger)
+	oauthHandler := handlers.NewOAuthHandler(srv.oauth, srv.securityEvents, srv.logger, srv.validator)
+	apiKeysHandler := handlers.NewAPIKeysHandler(srv.apiKeys, srv.logger, srv.validator)
+	serviceAccountsHandler := handlers.NewServiceAccountsHandler(srv.adminAudit, srv.logger, srv.validator)
+	loginAlertsHandler := handlers.NewLoginAlertsHandler(srv.loginAlerts, srv.logger, srv.validator)
+	followsHandler := handlers.NewFollowsHandler(srv.follows, srv.logger)
+	reportsHandler := handlers.NewReportsHandler(srv.reports, srv.logger, srv.validator)
+	bansHandler := handlers.NewBansHandler(srv.bans, srv.logger, srv.validator)
+	shadowBansHandler := handlers.NewShadowBansHandler(srv.shadowBans, srv.logger)
+	debugHandler := handlers.NewDebugHandler(srv.effectiveConfig, srv.logger)
+	errorsHandler := handlers.NewErrorsHandler(srv.logger)
+	openAPIHandler := handlers.NewOpenAPIHandler(srv.logger)
+	notificationsHandler := handlers.NewNotificationsHandler(srv.notifications, srv.logger, srv.cfg)
+	identitiesHandler := handlers.NewIdentitiesHandler(srv.identities, srv.securityEvents, srv.logger)
+	invitationsHandler := handlers.NewInvitationsHandler(srv.invitations, srv.logger, srv.validator)
+	organizationsHandler := handlers.NewOrganizationsHandler(srv.organizations, srv.logger, srv.validator)
+	termsHandler := handlers.NewTermsHandler(srv.terms, srv.logger, srv.validator)
+	phoneHandler := handlers.NewPhoneHandler(srv.phones, srv.logger, srv.validator)
+	changesHandler := handlers.NewChangesHandler(srv.changes, srv.logger)
+	healthHandler := handlers.NewHealthHandler(srv.healthChecks, srv.logger)
+	versionHandler := handlers.NewVersionHandler(srv.logger)
+	jwksHandler := handlers.NewJWKSHandler(srv.keys, srv.logger)
 
-	srv.router.Post("/users", srv.contextExpire(userHandler.CreateUserHandler, nil, time.Minute))
+	srv.router.Get("/livez", healthHandler.Livez)
+	srv.router.Get("/readyz", healthHandler.Readyz)
+	srv.router.Get("/version", versionHandler.Version)
+	srv.router.Get("/.well-known/jwks.json", jwksHandler.JWKS)
+
+	srv.router.Post("/users", srv.signupRateLimit(srv.contextExpire(userHandler.CreateUserHandler, nil, time.Minute)))
 	srv.router.Delete("/users/{id:[0-9]+}", srv.jwtMiddleware(userHandler.DeleteUser))
 	srv.router.Update("/users/{id:[0-9]+}", srv.jwtMiddleware(userHandler.UpdateUser))
 
 	srv.router.Get("/users", srv.contextExpire(userHandler.ListUsers, generateUsersListCacheKey, time.Minute))
-	srv.router.Get("/users/{id:[0-9]+}", srv.contextExpire(userHandler.GetUser, generateUserCacheKey, time.Minute))
+	srv.router.Get("/users/{id:[0-9]+}", srv.optionalAuth(srv.contextExpire(userHandler.GetUser, generateUserCacheKey, time.Minute)))
+	srv.router.Get("/users/{id:[0-9]+}/history", srv.jwtMiddleware(userHandler.GetUserHistory))
+	srv.router.Post("/users/{id:[0-9]+}/follow", srv.jwtMiddleware(followsHandler.Follow))
+	srv.router.Delete("/users/{id:[0-9]+}/follow", srv.jwtMiddleware(followsHandler.Unfollow))
+	srv.router.Get("/users/{id:[0-9]+}/followers", srv.jwtMiddleware(followsHandler.ListFollowers))
+	srv.router.Get("/users/{id:[0-9]+}/following", srv.jwtMiddleware(followsHandler.ListFollowing))
+	srv.router.Get("/users/{id:[0-9]+}/follow-counts", srv.jwtMiddleware(followsHandler.Counts))
+	srv.router.Post("/users/{id:[0-9]+}/report", srv.jwtMiddleware(reportsHandler.Report))
 	srv.router.Get("/users/count", srv.contextExpire(userHandler.CountUsers, generateCountUsersCacheKey, time.Minute))
+	srv.router.Get("/changes", srv.jwtMiddleware(changesHandler.ListChanges))
 
 	srv.router.Post("/login", srv.contextExpire(loginHandler.Login, nil, time.Minute))
+	srv.router.Post("/login/second-factor", srv.contextExpire(loginHandler.SecondFactor, nil, time.Minute))
+	srv.router.Post("/login/phone/code", srv.contextExpire(loginHandler.RequestPhoneCode, nil, time.Minute))
+	srv.router.Post("/login/phone", srv.contextExpire(loginHandler.PhoneLogin, nil, time.Minute))
+	srv.router.Post("/login/remember", srv.contextExpire(loginHandler.RememberLogin, nil, time.Minute))
+	srv.router.Delete("/login/remember", srv.contextExpire(loginHandler.Forget, nil, time.Minute))
+	if srv.introspection != nil {
+		introspectionHandler := handlers.NewIntrospectionHandler(srv.introspection, srv.logger)
+		srv.router.Post("/auth/introspect", srv.contextExpire(introspectionHandler.Introspect, nil, time.Minute))
+	}
+	srv.router.Get("/oauth/authorize", srv.jwtMiddleware(oauthHandler.Authorization))
+	srv.router.Post("/oauth/authorize", srv.jwtMiddleware(oauthHandler.Authorize))
+	srv.router.Post("/oauth/token", srv.contextExpire(oauthHandler.Token, nil, time.Minute))
+	srv.router.Post("/invitations/accept", srv.contextExpire(invitationsHandler.AcceptInvitation, nil, time.Minute))
+	srv.router.Post("/login-alerts/deny", srv.contextExpire(loginAlertsHandler.Deny, nil, time.Minute))
 
 	srv.router.Post("/like/{id:[0-9]+}", srv.jwtMiddleware(votesHandler.Like))
 	srv.router.Post("/dislike/{id:[0-9]+}", srv.jwtMiddleware(votesHandler.Dislike))
 	srv.router.Delete("/revoke/{id:[0-9]+}", srv.jwtMiddleware(votesHandler.RevokeVote))
+
+	srv.router.Get("/me", srv.scopedMiddleware(models.ScopeProfile, userHandler.Me))
+	srv.router.Get("/me/notifications", srv.scopedMiddleware(models.ScopeNotifications, notificationsHandler.ListNotifications))
+	srv.router.Post("/me/notifications/read", srv.scopedMiddleware(models.ScopeNotifications, notificationsHandler.MarkRead))
+	srv.router.Get("/me/notification-preferences", srv.jwtMiddleware(notificationsHandler.GetPreferences))
+	srv.router.Update("/me/notification-preferences", srv.jwtMiddleware(notificationsHandler.SetPreferences))
+	srv.router.Get("/me/identities", srv.jwtMiddleware(identitiesHandler.ListIdentities))
+	srv.router.Delete("/me/identities/{provider}", srv.jwtMiddleware(identitiesHandler.UnlinkIdentity))
+	srv.router.Get("/me/oauth/consents", srv.jwtMiddleware(oauthHandler.ListConsents))
+	srv.router.Delete("/me/oauth/consents/{client_id}", srv.jwtMiddleware(oauthHandler.RevokeConsent))
+	srv.router.Get("/me/security-events", srv.jwtMiddleware(securityHandler.ListMyEvents))
+	srv.router.Get("/me/terms", srv.jwtMiddleware(termsHandler.GetTerms))
+	srv.router.Post("/me/terms", srv.jwtMiddleware(termsHandler.AcceptTerms))
+	srv.router.Get("/me/phone", srv.jwtMiddleware(phoneHandler.GetPhone))
+	srv.router.Update("/me/phone", srv.jwtMiddleware(phoneHandler.SetPhone))
+	srv.router.Delete("/me/phone", srv.jwtMiddleware(phoneHandler.RemovePhone))
+	srv.router.Post("/me/phone/code", srv.jwtMiddleware(phoneHandler.ResendCode))
+	srv.router.Post("/me/phone/verify", srv.jwtMiddleware(phoneHandler.Verify))
+	srv.router.Update("/me/phone/second-factor", srv.jwtMiddleware(phoneHandler.SetSecondFactor))
+	srv.router.Delete("/me/remember-tokens", srv.jwtMiddleware(loginHandler.ForgetDevices))
+
+	srv.router.Post("/organizations", srv.jwtMiddleware(organizationsHandler.CreateOrganization))
+	srv.router.Get("/organizations", srv.jwtMiddleware(organizationsHandler.ListOrganizations))
+	srv.router.Get("/organizations/{id:[0-9]+}", srv.jwtMiddleware(organizationsHandler.GetOrganization))
+	srv.router.Get("/organizations/{id:[0-9]+}/members", srv.jwtMiddleware(organizationsHandler.ListMembers))
+	srv.router.Post("/organizations/{id:[0-9]+}/members", srv.jwtMiddleware(organizationsHandler.AddMember))
+	srv.router.Update("/organizations/{id:[0-9]+}/members/{user_id:[0-9]+}", srv.jwtMiddleware(organizationsHandler.SetMemberRole))
+	srv.router.Delete("/organizations/{id:[0-9]+}/members/{user_id:[0-9]+}", srv.jwtMiddleware(organizationsHandler.RemoveMember))
+
+	srv.router.Delete("/admin/users/{id:[0-9]+}/lock", srv.jwtMiddleware(loginAlertsHandler.Unlock))
+	srv.router.Post("/admin/users/{id:[0-9]+}/ban", srv.jwtMiddleware(bansHandler.Ban))
+	srv.router.Delete("/admin/users/{id:[0-9]+}/ban", srv.jwtMiddleware(bansHandler.Lift))
+	srv.router.Get("/admin/bans", srv.jwtMiddleware(bansHandler.ListBans))
+	srv.router.Update("/admin/users/{id:[0-9]+}/shadow-ban", srv.jwtMiddleware(srv.invalidatesUser(shadowBansHandler.ShadowBan)))
+	srv.router.Delete("/admin/users/{id:[0-9]+}/shadow-ban", srv.jwtMiddleware(srv.invalidatesUser(shadowBansHandler.Lift)))
+	srv.router.Get("/admin/shadow-bans", srv.jwtMiddleware(shadowBansHandler.ListShadowBans))
+
+	srv.router.Post("/admin/events/replay", srv.jwtMiddleware(eventsHandler.Replay))
+
+	srv.router.Post("/admin/invitations", srv.jwtMiddleware(invitationsHandler.Invite))
+	srv.router.Get("/admin/invitations", srv.jwtMiddleware(invitationsHandler.ListInvitations))
+	srv.router.Post("/admin/invitations/{id:[0-9]+}/resend", srv.jwtMiddleware(invitationsHandler.ResendInvitation))
+	srv.router.Delete("/admin/invitations/{id:[0-9]+}", srv.jwtMiddleware(invitationsHandler.RevokeInvitation))
+
+	if srv.quotas != nil {
+		quotasHandler := handlers.NewQuotasHandler(srv.quotas, srv.logger)
+		srv.router.Get("/admin/quotas", srv.jwtMiddleware(quotasHandler.Usage))
+	}
+
+	srv.router.Get("/admin/stats", srv.requireScope(models.PermissionStatsRead, statsHandler.Stats))
+	srv.router.Get("/admin/security/incidents", srv.requireScope(models.PermissionSecurityIncidentsRead, securityHandler.ListIncidents))
+	srv.router.Get("/admin/security/events", srv.requireScope(models.PermissionSecurityEventsRead, securityHandler.ListEvents))
+	srv.router.Get("/admin/audit", srv.requireScope(models.PermissionAuditRead, adminAuditHandler.ListAudit))
+	srv.router.Get("/admin/audit/export", srv.requireScope(models.PermissionAuditRead, adminAuditHandler.ExportAudit))
+	srv.router.Get("/admin/reports", srv.requireScope(models.PermissionReportsModerate, reportsHandler.ListReports))
+	srv.router.Post("/admin/reports/{id:[0-9]+}/resolve", srv.requireScope(models.PermissionReportsModerate, reportsHandler.Resolve))
+	srv.router.Post("/admin/oauth/clients", srv.jwtMiddleware(oauthHandler.RegisterClient))
+	srv.router.Get("/admin/oauth/clients", srv.jwtMiddleware(oauthHandler.ListClients))
+	srv.router.Delete("/admin/oauth/clients/{id:[0-9]+}", srv.jwtMiddleware(oauthHandler.DeleteClient))
+	srv.router.Post("/admin/service-accounts", srv.jwtMiddleware(serviceAccountsHandler.Create))
+	srv.router.Post("/admin/api-keys", srv.jwtMiddleware(apiKeysHandler.Create))
+	srv.router.Get("/admin/api-keys", srv.jwtMiddleware(apiKeysHandler.List))
+	srv.router.Update("/admin/api-keys/{id:[0-9]+}/tier", srv.jwtMiddleware(apiKeysHandler.SetTier))
+	srv.router.Delete("/admin/api-keys/{id:[0-9]+}", srv.jwtMiddleware(apiKeysHandler.Delete))
+	srv.router.Get("/admin/api-keys/{id:[0-9]+}/usage", srv.jwtMiddleware(apiKeysHandler.Usage))
+
+	srv.router.Get("/admin/flags", srv.jwtMiddleware(featureFlagsHandler.ListFeatureFlags))
+	srv.router.Update("/admin/flags/{name}", srv.jwtMiddleware(featureFlagsHandler.SetFeatureFlag))
+	srv.router.Delete("/admin/flags/{name}", srv.jwtMiddleware(featureFlagsHandler.DeleteFeatureFlag))
+
+	srv.router.Get("/permissions", srv.jwtMiddleware(permissionsHandler.ListPermissions))
+	srv.router.Post("/permissions", srv.jwtMiddleware(permissionsHandler.CreatePermission))
+	srv.router.Update("/permissions/{id:[0-9]+}", srv.jwtMiddleware(permissionsHandler.UpdatePermission))
+	srv.router.Delete("/permissions/{id:[0-9]+}", srv.jwtMiddleware(permissionsHandler.DeletePermission))
+	srv.router.Get("/roles", srv.jwtMiddleware(permissionsHandler.ListRoles))
+	srv.router.Get("/roles/{id:[0-9]+}/permissions", srv.jwtMiddleware(permissionsHandler.ListRolePermissions))
+	srv.router.Update("/roles/{id:[0-9]+}/permissions/{permission_id:[0-9]+}", srv.jwtMiddleware(permissionsHandler.GrantPermission))
+	srv.router.Delete("/roles/{id:[0-9]+}/permissions/{permission_id:[0-9]+}", srv.jwtMiddleware(permissionsHandler.RevokePermission))
+	srv.router.Get("/admin/permissions/audit", srv.jwtMiddleware(permissionsHandler.ListAudit))
+
+	if srv.jobQueue != nil {
+		jobsHandler := handlers.NewJobsHandler(srv.jobQueue, srv.logger, srv.cfg)
+		srv.router.Get("/admin/jobs", srv.jwtMiddleware(jobsHandler.ListJobs))
+		srv.router.Post("/admin/jobs/{id:[0-9]+}/retry", srv.jwtMiddleware(jobsHandler.RetryJob))
+	}
+	if srv.webhookDeliveries != nil {
+		webhooksHandler := handlers.NewWebhooksHandler(srv.webhookDeliveries, srv.logger)
+		srv.router.Get("/admin/webhooks/deliveries", srv.jwtMiddleware(webhooksHandler.ListDeliveries))
+	}
+
+	srv.router.Get("/debug/config", srv.jwtMiddleware(debugHandler.Config))
+	if srv.cfg.PprofEnabled {
+		srv.router.Get("/debug/pprof/", srv.jwtMiddleware(debugHandler.Pprof))
+		srv.router.Get("/debug/pprof/{profile}", srv.jwtMiddleware(debugHandler.Pprof))
+	}
+
+	srv.router.Get("/errors", errorsHandler.ListErrors)
+	srv.router.Get("/openapi.yaml", openAPIHandler.Document)
 }
 
-func Run() {
-	logger, err := zap.NewDevelopment()
+// Run serves the API until it fails
+func Run(a *app.App) {
+	srv := newServer(a)
+	defer srv.close()
+	if a.Config.MTLSPort != "" {
+		go func() {
+			err := srv.listenMTLS()
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+		}()
+	}
+	if a.Config.MetricsEnabled {
+		go func() {
+			err := srv.listenMetrics()
+			if err != nil {
+				a.Logger.Fatal(err)
+			}
+		}()
+	}
+	err := srv.listen()
 	if err != nil {
-		log.Fatal(apperrors.LoggerInitError.AppendMessage(err))
+		a.Logger.Fatal(err)
 	}
-	defer logger.Sync()
+}
 
-	cfg, err := config.NewConfig()
-	if err != nil {
-		logger.Sugar().Fatal(err)
+// newServer wires the API for a and starts its background work: config reloads, the scheduler and the job queue
+func newServer(a *app.App) *server {
+	cfg, logger := a.Config, a.Logger
+	go a.Secrets.Run(context.Background())
+
+	db := a.Database()
+
+	if cfg.AutoMigrate {
+		err := migrateDatabase(cfg, db)
+		if err != nil {
+			logger.Fatal(err)
+		}
 	}
 
-	db, err := database.SetupDatabase(cfg)
+	encrypted, conflicts, err := database.EncryptPII(db)
 	if err != nil {
-		logger.Sugar().Fatal(err)
+		logger.Fatal(err)
+	}
+	if encrypted > 0 {
+		logger.Infof("Encrypted personal data of %d existing rows", encrypted)
+	}
+	if len(conflicts) > 0 {
+		logger.Warnf("Users %v have the email of an older user but for case; they can not sign in until their email is changed", conflicts)
 	}
 
 	cache := cache.NewRedisClient(cfg.RedisURL)
 
-	userRepo := repositories.NewUserRepo(db, logger.Sugar())
-	voteRepo := repositories.NewVoteRepo(db, logger.Sugar())
-	userService := services.NewUserService(userRepo, voteRepo, logger.Sugar())
+	var closers []func()
+	var userRepo repositories.UserRepoInterface = repositories.NewUserRepo(db, logger)
+	var voteRepo repositories.VoteRepoInterface = repositories.NewVoteRepo(db, logger)
+	switch cfg.UserRepoDriver {
+	case config.RepoDriverPgx:
+		pool, err := database.SetupPgxPool(context.Background(), cfg)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		closers = append(closers, pool.Close)
+
+		userRepo = repositories.NewPgxUserRepo(pool, repositories.NewUserRepo(db, logger), logger)
+	case config.RepoDriverMemory:
+		logger.Warn("Users and votes are kept in memory and are lost when the server stops")
+		memoryUsers := repositories.NewMemoryUserRepo(logger)
+		userRepo = memoryUsers
+		voteRepo = repositories.NewMemoryVoteRepo(memoryUsers, logger)
+	}
+	uncachedUserRepo := userRepo
+	if cfg.UserCacheEnabled {
+		userRepo = repositories.NewCachedUserRepo(userRepo, cache, cfg.UserCacheTTL, logger)
+		voteRepo = repositories.NewCachedVoteRepo(voteRepo, cache, cfg.UserCacheTTL, logger)
+	}
+	eventRepo := repositories.NewEventRepo(db, logger)
+	eventService := services.NewEventService(eventRepo, logger)
+
+	// Webhooks are delivered by the job queue, with retries, instead of inside the request
+	var jobQueue *jobs.Queue
+	var webhookDeliveries jobs.DeliveryLog
+	jobRepo := repositories.NewJobRepo(db, logger)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepo(db, logger)
+	sink := events.NewPublisher(cfg.WebhookURL, logger)
+	if cfg.JobsEnabled {
+		jobQueue = jobs.NewQueue(jobRepo, cfg.JobWorkers, cfg.JobPollInterval, cfg.JobTimeout, cfg.JobMaxAttempts, logger)
+		if cfg.WebhookURL != "" {
+			breaker := jobs.NewCircuitBreaker(cfg.WebhookBreakerThreshold, cfg.WebhookBreakerCooldown)
+			deliverer := jobs.NewWebhookDeliverer(events.NewWebhookPublisher(cfg.WebhookURL), webhookDeliveryRepo, breaker, logger)
+			jobQueue.Register(jobs.KindDeliverEvent, deliverer.Perform)
+			sink = jobs.NewQueuedPublisher(jobQueue, jobs.MaxAttempts(cfg.WebhookMaxAttempts))
+			webhookDeliveries = webhookDeliveryRepo
+		}
+	}
+
+	mailer := a.Mailer()
+	if jobQueue != nil {
+		jobQueue.Register(jobs.KindSendMail, jobs.SendMail(mailer))
+		mailer = jobs.NewQueuedMailer(jobQueue)
+	}
+	mailTemplates, err := mail.LoadTemplates(cfg.MailTemplatesDir)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	// Notifications subscribe to the domain events next to the webhook sink
+	followRepo := repositories.NewFollowRepo(db, logger)
+	shadowBanRepo := repositories.NewShadowBanRepo(db, logger)
+	phoneRepo := repositories.NewPhoneRepo(db, logger)
+	smsSender := a.SMSSender()
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepo(db, logger), userRepo, followRepo, shadowBanRepo, map[string]services.NotificationChannel{
+		models.ChannelEmail: services.NewEmailChannel(mailer, mailTemplates),
+		models.ChannelSMS:   services.NewSMSChannel(phoneRepo, smsSender),
+	}, logger)
+	publisher := events.NewPersistingPublisher(eventService, events.NewFanoutPublisher(sink, notificationService))
+
+	emitter := events.NewEmitter(cfg.EventSource, publisher, logger)
+	txManager := repositories.NewTxManager(db, logger)
+	rememberTokenRepo := repositories.NewRememberTokenRepo(db, logger)
+	userService := services.NewUserService(userRepo, voteRepo, rememberTokenRepo, txManager, emitter, logger)
+	userService.SetVoteCooldown(cfg.VoteCooldown)
+	var quotaService services.QuotaServiceInterface
+	if cfg.QuotasEnabled {
+		quotaService = services.NewQuotaService(repositories.NewQuotaRepo(db, logger), map[string]int{
+			models.QuotaUsers:               cfg.QuotaMaxUsers,
+			models.QuotaVotesPerDay:         cfg.QuotaMaxVotesPerDay,
+			models.QuotaRequestsPerDay:      cfg.QuotaMaxRequestsPerDay,
+			models.QuotaOrganizationMembers: cfg.QuotaMaxOrganizationMembers,
+		}, logger)
+		userService = services.NewQuotaUserService(userService, quotaService)
+	}
+	identityService := services.NewIdentityService(repositories.NewIdentityRepo(db, logger), userRepo, txManager, logger)
+	organizationRepo := repositories.NewOrganizationRepo(db, logger)
+	invitationRepo := repositories.NewInvitationRepo(db, logger)
+	invitationService := services.NewInvitationService(invitationRepo, repositories.NewRoleRepo(db, logger), organizationRepo, userService, txManager, mailer, mailTemplates, cfg.InvitationURL, cfg.InvitationTTL, logger)
+	followService := services.NewFollowService(followRepo, userService, emitter, logger)
+	organizationService := services.NewOrganizationService(organizationRepo, userService, invitationService, quotaService, txManager, logger)
+	termsService := services.NewTermsService(repositories.NewTermsRepo(db, logger), cfg.TermsVersion, logger)
+	changeRepo := repositories.NewChangeRepo(db, logger)
+	changeService := services.NewChangeService(changeRepo, logger)
+	statsService := services.NewStatsService(repositories.NewStatsRepo(db, logger), logger)
+	keys, err := auth.ParseKeys(cfg.JwtAlgorithm, cfg.JwtKey)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if a.Secrets.Vault != nil {
+		// A new version of the key signs from now on; tokens of the one it replaces stay valid until they expire
+		a.Secrets.Vault.OnJWTKey(func(key string) {
+			next, err := auth.ParseKeys(cfg.JwtAlgorithm, key)
+			if err == nil {
+				err = keys.Rotate(next)
+			}
+			if err != nil {
+				logger.Errorw("Rotating to the JWT key from Vault failed", "error", err)
+				return
+			}
+			logger.Info("Rotated to the JWT key from Vault")
+		})
+	}
+	apiKeyRepo := repositories.NewAPIKeyRepo(db, logger)
+	apiKeyLimiters := make(map[string]ratelimit.Limiter)
+	for tier, limit := range cfg.APIKeyTiers {
+		if limit > 0 {
+			apiKeyLimiters[tier] = ratelimit.NewRedisLimiter(cache.Client, "ratelimit:apikey:"+tier+":", limit, cfg.APIKeyRateWindow)
+		}
+	}
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userService, cfg.APIKeyTiers, cfg.APIKeyDefaultTier, cfg.APIKeyRateWindow, apiKeyLimiters, logger)
+	adminAuditService := services.NewAdminAuditService(repositories.NewAdminAuditRepo(db, logger), userService, txManager, logger)
+
+	trustedProxies, err := ratelimit.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	var signupLimiter ratelimit.Limiter
+	if cfg.SignupRateLimit > 0 {
+		signupLimiter = ratelimit.NewRedisLimiter(cache.Client, "ratelimit:signup:", cfg.SignupRateLimit, cfg.SignupRateWindow)
+	}
+	var loginThrottle ratelimit.Throttle
+	if cfg.LoginThrottleAttempts > 0 {
+		loginThrottle = ratelimit.NewRedisThrottle(cache.Client, "throttle:login:", cfg.LoginThrottleAttempts,
+			cfg.LoginThrottleWindow, cfg.LoginThrottleDelay, cfg.LoginThrottleMaxDelay)
+	}
+	securityIncidentRepo := repositories.NewSecurityIncidentRepo(db, logger)
+	securityService := services.NewSecurityService(securityIncidentRepo, ratelimit.NewRedisCounter(cache.Client, "bruteforce:", cfg.BruteForceWindow), map[string]int{
+		models.SignalIP:        cfg.BruteForceIPThreshold,
+		models.SignalAccount:   cfg.BruteForceAccountThreshold,
+		models.SignalUserAgent: cfg.BruteForceUserAgentThreshold,
+	}, emitter, txManager, mailer, mailTemplates, cfg.SecurityAlertEmails, logger)
+	securityEventRepo := repositories.NewSecurityEventRepo(db, logger)
+	securityEventService := services.NewSecurityEventService(securityEventRepo, logger)
+	var geo *geoip.Database
+	if cfg.GeoIPDatabase != "" {
+		geo, err = geoip.Open(cfg.GeoIPDatabase)
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+	loginAlertRepo := repositories.NewLoginAlertRepo(db, logger)
+	loginAlertService := services.NewLoginAlertService(loginAlertRepo, repositories.NewAdminAuditRepo(db, logger), rememberTokenRepo, securityEventService, txManager, geo, mailer, mailTemplates, cfg.LoginAlertURL, cfg.LoginAlertTTL, logger)
+	banRepo := repositories.NewBanRepo(db, logger)
+	banService := services.NewBanService(banRepo, repositories.NewAdminAuditRepo(db, logger), rememberTokenRepo, userService, securityEventService, txManager, logger)
+	oauthRepo := repositories.NewOAuthRepo(db, logger)
+	oauthService := services.NewOAuthService(oauthRepo, repositories.NewRoleRepo(db, logger), userService, loginAlertService, banService, keys, cfg.OAuthCodeTTL, cfg.OAuthTokenTTL, logger)
+	var introspectionService services.IntrospectionServiceInterface
+	if len(cfg.IntrospectionClients) > 0 {
+		introspectionService = services.NewIntrospectionService(userService, oauthService, cfg.IntrospectionClients, keys, logger)
+	}
+	shadowBanService := services.NewShadowBanService(shadowBanRepo, repositories.NewAdminAuditRepo(db, logger), userService, txManager, logger)
+	var phoneCodeLimiter ratelimit.Limiter
+	if cfg.PhoneCodeSendLimit > 0 {
+		phoneCodeLimiter = ratelimit.NewRedisLimiter(cache.Client, "ratelimit:phonecode:", cfg.PhoneCodeSendLimit, cfg.PhoneCodeSendWindow)
+	}
+	phoneService := services.NewPhoneService(phoneRepo, userService, securityEventService, smsSender, phoneCodeLimiter, cfg.PhoneCodeTTL, cfg.PhoneCodeAttempts, logger)
+	rememberService := services.NewRememberService(rememberTokenRepo, securityEventService, cfg.RememberMeTTL, logger)
+	reportService := services.NewReportService(repositories.NewReportRepo(db, logger), loginAlertRepo, repositories.NewAdminAuditRepo(db, logger), rememberTokenRepo, adminAuditService, userService, securityEventService, txManager, logger)
+
+	featureFlagRepo := repositories.NewFeatureFlagRepo(db, logger)
+	featureFlags := services.NewFeatureFlagService(featureFlagRepo, cfg.FeatureFlags, cfg.FeatureFlagRefreshInterval, logger)
+	signupRoles, err := services.ResolveSignupRoles(context.Background(), repositories.NewRoleRepo(db, logger), cfg.DefaultRole, cfg.SignupRoles)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	permissionService := services.NewPermissionService(repositories.NewPermissionRepo(db, logger), repositories.NewRoleRepo(db, logger), txManager, cfg.PermissionRefreshInterval, logger)
+	tokenService := services.NewTokenService(permissionService, cfg.JwtClaims, keys, logger)
+
+	// The watcher compares reloads with the configured values, not with the resolved secrets
+	watcher := config.NewWatcher(&a.Configured, logger)
+	watcher.OnReload(func(reloaded *config.Config) {
+		err := a.LogLevel.UnmarshalText([]byte(reloaded.EffectiveLogLevel()))
+		if err != nil {
+			logger.Errorf("Invalid LOG_LEVEL %q: %v", reloaded.LogLevel, err)
+		}
+		userService.SetVoteCooldown(reloaded.VoteCooldown)
+		featureFlags.SetStatic(reloaded.FeatureFlags)
+		termsService.SetCurrentVersion(reloaded.TermsVersion)
+	})
+	go watcher.Run(context.Background(), cfg.ConfigWatchInterval)
+
+	tenantService := services.NewTenantService(repositories.NewTenantRepo(db, logger), logger)
+
+	// Only one replica runs the periodic tasks: the one holding the scheduler lock
+	scheduler := jobs.NewScheduler(newLeaderElector(cfg, db, cache, logger), repositories.NewScheduledRunRepo(db, logger), logger)
+	if cfg.ArchiveEnabled {
+		archiveRepo := repositories.NewUserArchiveRepo(db, logger)
+		retention := time.Duration(cfg.ArchiveAfterDays) * 24 * time.Hour
+		archiver := jobs.NewUserArchiver(archiveRepo, retention, cfg.ArchiveBatchSize, cfg.ArchivePurge, logger)
+		if jobQueue != nil {
+			jobQueue.RegisterSystem(jobs.KindArchiveUsers, archiver.Perform)
+			scheduler.Every(jobs.KindArchiveUsers, cfg.ArchiveInterval, jobs.Enqueue(jobQueue, jobs.KindArchiveUsers, nil))
+		} else {
+			scheduler.Every(jobs.KindArchiveUsers, cfg.ArchiveInterval, func(ctx context.Context) error {
+				_, err := archiver.RunOnce(ctx)
+				return err
+			})
+		}
+	}
+	// Logins are recorded even with the job off, so turning it on does not count everyone from their signup
+	var inactivityRepo repositories.InactivityRepoInterface = repositories.NewInactivityRepo(db, logger)
+	if cfg.UserCacheEnabled {
+		inactivityRepo = repositories.NewCachedInactivityRepo(inactivityRepo, uncachedUserRepo, cache, cfg.UserCacheTTL, logger)
+	}
+	activityService := services.NewActivityService(inactivityRepo, logger)
+	if cfg.InactivityEnabled {
+		period := time.Duration(cfg.InactivityAfterDays) * 24 * time.Hour
+		warning := time.Duration(cfg.InactivityWarningDays) * 24 * time.Hour
+		anonymizer := jobs.NewInactivityAnonymizer(inactivityRepo, mailer, mailTemplates, period, warning, cfg.InactivityBatchSize, logger)
+		if jobQueue != nil {
+			jobQueue.RegisterSystem(jobs.KindAnonymizeInactive, anonymizer.Perform)
+			scheduler.Every(jobs.KindAnonymizeInactive, cfg.InactivityInterval, jobs.Enqueue(jobQueue, jobs.KindAnonymizeInactive, nil))
+		} else {
+			scheduler.Every(jobs.KindAnonymizeInactive, cfg.InactivityInterval, func(ctx context.Context) error {
+				_, _, err := anonymizer.RunOnce(ctx)
+				return err
+			})
+		}
+	}
+	// Runs on the scheduler rather than the queue: every second would flood the jobs table
+	changeRelay := jobs.NewChangeRelay(changeRepo, cfg.ChangeFeedRelayBatchSize, logger)
+	scheduler.Every(jobs.KindRelayChanges, cfg.ChangeFeedRelayInterval, func(ctx context.Context) error {
+		_, err := changeRelay.RunOnce(ctx)
+		return err
+	})
+	if cfg.CleanupEnabled {
+		// Token and session stores add their sweepers here
+		sweepers := []jobs.Sweeper{
+			jobs.NewSucceededJobsSweeper(jobRepo, cfg.JobRetention),
+			jobs.NewWebhookDeliveriesSweeper(webhookDeliveryRepo, cfg.WebhookDeliveryRetention),
+			jobs.NewSecurityIncidentsSweeper(securityIncidentRepo, cfg.SecurityIncidentRetention),
+			jobs.NewSecurityEventsSweeper(securityEventRepo, cfg.SecurityEventRetention),
+			jobs.NewOAuthCodesSweeper(oauthRepo),
+			jobs.NewLoginAlertsSweeper(loginAlertRepo),
+			jobs.NewBansSweeper(banRepo),
+			jobs.NewPhoneCodesSweeper(phoneRepo),
+			jobs.NewRememberTokensSweeper(rememberTokenRepo),
+			jobs.NewInvitationsSweeper(invitationRepo, cfg.InvitationRetention),
+			jobs.NewAPIKeyUsageSweeper(apiKeyRepo, cfg.APIKeyUsageRetention),
+		}
+		cleaner := jobs.NewCleaner(cfg.CleanupBatchSize, logger, sweepers...)
+		if jobQueue != nil {
+			jobQueue.RegisterSystem(jobs.KindCleanup, cleaner.Perform)
+			scheduler.Every(jobs.KindCleanup, cfg.CleanupInterval, jobs.Enqueue(jobQueue, jobs.KindCleanup, nil))
+		} else {
+			scheduler.Every(jobs.KindCleanup, cfg.CleanupInterval, func(ctx context.Context) error {
+				_, err := cleaner.RunOnce(ctx)
+				return err
+			})
+		}
+	}
+	go scheduler.Run(context.Background())
+	if jobQueue != nil {
+		go jobQueue.Run(context.Background())
+	}
+
+	var reporter *sentry.Client
+	if cfg.SentryDSN != "" {
+		environment := cfg.SentryEnvironment
+		if environment == "" {
+			environment = cfg.AppEnv
+		}
+		release := cfg.SentryRelease
+		if release == "" {
+			release = buildinfo.Read().Release()
+		}
+		reporter, err = sentry.NewClient(cfg.SentryDSN, environment, release, logger)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		go reporter.Run(context.Background())
+		closers = append(closers, func() { reporter.Flush(2 * time.Second) })
+	}
 
 	// Initialize validator
 	validate := validator.New()
 	validate.RegisterValidation("password", myValidate.Password)
+	validate.RegisterValidation("timezone", myValidate.Timezone)
+	validate.RegisterValidation("locale", myValidate.Locale)
 
 	srvRouter := &router{mux: mux.NewRouter()}
 	srv := &server{
-		db:          db,
-		cache:       cache,
-		router:      srvRouter,
-		logger:      logger.Sugar(),
-		validator:   validate,
-		cfg:         cfg,
-		userService: userService,
+		db:             db,
+		cache:          cache,
+		router:         srvRouter,
+		logger:         logger,
+		validator:      validate,
+		cfg:            cfg,
+		userService:    userService,
+		eventService:   eventService,
+		tenantService:  tenantService,
+		notifications:  notificationService,
+		identities:     identityService,
+		invitations:    invitationService,
+		organizations:  organizationService,
+		terms:          termsService,
+		changes:        changeService,
+		activity:       activityService,
+		featureFlags:   featureFlags,
+		permissions:    permissionService,
+		signupRoles:    signupRoles,
+		stats:          statsService,
+		security:       securityService,
+		securityEvents: securityEventService,
+		loginAlerts:    loginAlertService,
+		follows:        followService,
+		reports:        reportService,
+		bans:           banService,
+		shadowBans:     shadowBanService,
+		phones:         phoneService,
+		remember:       rememberService,
+		adminAudit:     adminAuditService,
+		oauth:          oauthService,
+		tokens:         tokenService,
+		apiKeys:        apiKeyService,
+		keys:           keys,
+		effectiveConfig: func() *config.Config {
+			return config.WithReloaded(cfg, watcher.Current())
+		},
+		sentry:            reporter,
+		jobQueue:          jobQueue,
+		introspection:     introspectionService,
+		quotas:            quotaService,
+		signupLimiter:     signupLimiter,
+		loginThrottle:     loginThrottle,
+		trustedProxies:    trustedProxies,
+		webhookDeliveries: webhookDeliveries,
+		healthChecks:      healthChecks(db, cache),
+		closers:           closers,
 	}
 	srv.initializeRoutes()
+	return srv
+}
+
+// close releases the connections newServer opened, in reverse order
+func (srv *server) close() {
+	for i := len(srv.closers) - 1; i >= 0; i-- {
+		srv.closers[i]()
+	}
+}
+
+func newLeaderElector(cfg *config.Config, db *gorm.DB, cache *cache.RedisClient, logger *zap.SugaredLogger) jobs.LeaderElector {
+	switch cfg.EffectiveSchedulerLock() {
+	case config.SchedulerLockPostgres:
+		sqlDB, err := db.DB()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		return jobs.NewPostgresElector(sqlDB, cfg.SchedulerLockName, cfg.SchedulerLockInterval, logger)
+	case config.SchedulerLockRedis:
+		return jobs.NewRedisElector(cache.Client, cfg.SchedulerLockName, 3*cfg.SchedulerLockInterval, logger)
+	default:
+		return jobs.LocalElector{}
+	}
+}
+
+// healthChecks are the dependencies /readyz checks: the primary database and Redis
+func healthChecks(db *gorm.DB, cache *cache.RedisClient) map[string]handlers.HealthCheck {
+	return map[string]handlers.HealthCheck{
+		"database": func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+		"redis": func(ctx context.Context) error {
+			return cache.Client.Ping(ctx).Err()
+		},
+	}
+}
+
+func migrateDatabase(cfg *config.Config, db *gorm.DB) error {
+	if cfg.DBDriver == config.DriverSQLite {
+		return database.AutoMigrate(db)
+	}
 
-	logger.Sugar().Infof("Listening HTTP service on %s port", cfg.AppPort)
-	err = http.ListenAndServe(fmt.Sprintf(":%s", cfg.AppPort), srv)
+	migrator, err := database.NewMigrator(cfg)
 	if err != nil {
-		logger.Sugar().Fatal(err)
+		return err
 	}
+	defer migrator.Close()
+
+	return migrator.Up()
 }
 
 // Функція для генерації ключа кешу для отримання користувача
@@ -113,10 +734,22 @@ func generateUsersListCacheKey(r *http.Request) string {
 	queryParams := r.URL.Query()
 	page := queryParams.Get("page")
 	pageSize := queryParams.Get("page_size")
-	return fmt.Sprintf("users_list_page_%s_size_%s", page, pageSize)
+	if queryParams.Has("updated_since") {
+		return fmt.Sprintf("users_changes_since_%s_after_%s_size_%s", queryParams.Get("updated_since"), queryParams.Get("after_id"), pageSize)
+	}
+	key := fmt.Sprintf("users_list_page_%s_size_%s", page, pageSize)
+	// Malformed filters are rejected by the handler, which runs on a cache miss
+	if filters, err := filter.Parse(queryParams); err == nil && len(filters) > 0 {
+		key += "_filter_" + filter.Encode(filters)
+	}
+	return key
 }
 
 // Функція для генерації ключа кешу для підрахунку користувачів
 func generateCountUsersCacheKey(r *http.Request) string {
+	// Malformed filters are rejected by the handler, which runs on a cache miss
+	if filters, err := filter.Parse(r.URL.Query()); err == nil && len(filters) > 0 {
+		return "count_users_filter_" + filter.Encode(filters)
+	}
 	return "count_users"
 }
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/metrics"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// listen serves plain HTTP on APP_PORT, or HTTPS with ACME certificates when the profile enables autocert and
+// AUTOCERT_DOMAINS is set; AUTOCERT_HTTP_PORT then answers the HTTP-01 challenges and redirects to HTTPS
+func (srv *server) listen() error {
+	profile := srv.cfg.Profile()
+	if !profile.Autocert || len(srv.cfg.AutocertDomains) == 0 {
+		srv.logger.Infof("Listening HTTP service on %s port", srv.cfg.AppPort)
+		return http.ListenAndServe(fmt.Sprintf(":%s", srv.cfg.AppPort), srv)
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(srv.cfg.AutocertDomains...),
+		Cache:      autocert.DirCache(srv.cfg.AutocertCacheDir),
+		Email:      srv.cfg.AutocertEmail,
+		Client:     &acme.Client{DirectoryURL: profile.ACMEDirectoryURL},
+	}
+
+	go func() {
+		srv.logger.Infof("Answering ACME challenges on %s port", srv.cfg.AutocertHTTPPort)
+		err := http.ListenAndServe(fmt.Sprintf(":%s", srv.cfg.AutocertHTTPPort), manager.HTTPHandler(nil))
+		if err != nil {
+			srv.logger.Fatal(err)
+		}
+	}()
+
+	httpsServer := &http.Server{
+		Addr:      fmt.Sprintf(":%s", srv.cfg.AppPort),
+		Handler:   srv,
+		TLSConfig: manager.TLSConfig(),
+	}
+	srv.logger.Infof("Listening HTTPS service on %s port for %v", srv.cfg.AppPort, srv.cfg.AutocertDomains)
+	return httpsServer.ListenAndServeTLS("", "")
+}
+
+// listenMetrics serves /metrics, and nothing else, in plain HTTP on METRICS_PORT, so the counters stay off the API's
+// port and its ingress
+func (srv *server) listenMetrics() error {
+	router := http.NewServeMux()
+	router.Handle("/metrics", metrics.Default.Handler())
+	srv.logger.Infof("Serving metrics on %s port", srv.cfg.MetricsPort)
+	return http.ListenAndServe(fmt.Sprintf(":%s", srv.cfg.MetricsPort), router)
+}
+
+// listenMTLS serves HTTPS on MTLS_PORT to callers presenting a client certificate signed by MTLS_CLIENT_CA whose
+// identity is in MTLS_IDENTITIES; the handshake fails for any other
+func (srv *server) listenMTLS() error {
+	tlsConfig, err := mtlsConfig(srv.cfg.MTLSClientCA, srv.cfg.MTLSIdentities)
+	if err != nil {
+		return err
+	}
+	mtlsServer := &http.Server{
+		Addr:      fmt.Sprintf(":%s", srv.cfg.MTLSPort),
+		Handler:   srv,
+		TLSConfig: tlsConfig,
+	}
+	srv.logger.Infof("Listening mutual TLS service on %s port", srv.cfg.MTLSPort)
+	return mtlsServer.ListenAndServeTLS(srv.cfg.MTLSCertFile, srv.cfg.MTLSKeyFile)
+}
+
+func mtlsConfig(caFile string, identities map[string]string) (*tls.Config, error) {
+	bundle, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no PEM certificates in %s", caFile)
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		// Runs after the chain is verified, so only the identity is left to check
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if _, ok := certificateIdentity(state.PeerCertificates[0], identities); !ok {
+				return errors.New("the client certificate names no service account")
+			}
+			return nil
+		},
+	}, nil
+}
+
+// certificateIdentity returns the subject common name or the DNS name of cert that identities maps to a service
+// account
+func certificateIdentity(cert *x509.Certificate, identities map[string]string) (string, bool) {
+	if _, ok := identities[cert.Subject.CommonName]; ok && cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName, true
+	}
+	for _, name := range cert.DNSNames {
+		if _, ok := identities[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
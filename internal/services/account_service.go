@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	myValidate "gitlab.com/jkozhemiaka/web-layout/internal/validate"
+	"go.uber.org/zap"
+)
+
+// AccountServiceInterface covers the operator tasks on accounts, such as bootstrapping the first admin.
+// Changes go through the user service, so they are recorded and emitted like those made over the API.
+type AccountServiceInterface interface {
+	// CreateAccount creates a user with the named role and returns its ID
+	CreateAccount(ctx context.Context, account *NewAccount) (uint, error)
+	// ResetPassword replaces the password of the user with email
+	ResetPassword(ctx context.Context, email, password string) error
+}
+
+// NewAccount is what CreateAccount needs; the password is given in plain text
+type NewAccount struct {
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,password"`
+	Role      string `json:"role" validate:"required,oneof=user moderator admin"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type passwordReset struct {
+	Password string `json:"password" validate:"required,password"`
+}
+
+type AccountService struct {
+	userService UserServiceInterface
+	roleRepo    repositories.RoleRepoInterface
+	validate    *validator.Validate
+	logger      *zap.SugaredLogger
+}
+
+func NewAccountService(userService UserServiceInterface, roleRepo repositories.RoleRepoInterface, logger *zap.SugaredLogger) AccountServiceInterface {
+	validate := validator.New()
+	validate.RegisterValidation("password", myValidate.Password)
+	return &AccountService{
+		userService: userService,
+		roleRepo:    roleRepo,
+		validate:    validate,
+		logger:      logger,
+	}
+}
+
+func (service *AccountService) CreateAccount(ctx context.Context, account *NewAccount) (uint, error) {
+	if err := myValidate.ValidationError(service.validate, account); err != nil {
+		return 0, err
+	}
+
+	role, err := service.roleRepo.GetRoleByName(ctx, account.Role)
+	if err != nil {
+		return 0, err
+	}
+	hash, err := passwords.HashPassword(account.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	return service.userService.CreateUser(ctx, &models.User{
+		Email:     account.Email,
+		FirstName: account.FirstName,
+		LastName:  account.LastName,
+		Password:  hash,
+		RoleID:    role.ID,
+	})
+}
+
+func (service *AccountService) ResetPassword(ctx context.Context, email, password string) error {
+	if err := myValidate.ValidationError(service.validate, &passwordReset{Password: password}); err != nil {
+		return err
+	}
+
+	user, err := service.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	hash, err := passwords.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.userService.UpdateUser(ctx, strconv.FormatUint(uint64(user.ID), 10), &models.User{Password: hash})
+	return err
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAccountService_CreateAccountWithRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userService := NewMockUserServiceInterface(ctrl)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	roleRepo.EXPECT().GetRoleByName(gomock.Any(), models.StrAdmin).Return(&models.Role{ID: 3, Name: models.StrAdmin}, nil)
+	userService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (uint, error) {
+		assert.Equal(t, "ops@example.com", user.Email)
+		assert.Equal(t, uint(3), user.RoleID)
+		assert.True(t, passwords.CheckPasswordHash("s3cret-pass!", user.Password), "the password is stored hashed")
+		return 42, nil
+	})
+
+	service := NewAccountService(userService, roleRepo, zaptest.NewLogger(t).Sugar())
+	id, err := service.CreateAccount(context.Background(), &NewAccount{Email: "ops@example.com", Password: "s3cret-pass!", Role: models.StrAdmin})
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), id)
+}
+
+func TestAccountService_CreateAccountRejectsInvalidInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := NewAccountService(NewMockUserServiceInterface(ctrl), mocks.NewMockRoleRepoInterface(ctrl), zaptest.NewLogger(t).Sugar())
+	_, err := service.CreateAccount(context.Background(), &NewAccount{Email: "not-an-email", Password: "short", Role: "root"})
+
+	var appErr *apperrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	fields := map[string]string{}
+	for _, violation := range appErr.Fields {
+		fields[violation.Field] = violation.Rule
+	}
+	assert.Equal(t, map[string]string{"email": "email", "password": "password", "role": "oneof"}, fields)
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userService := NewMockUserServiceInterface(ctrl)
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "ops@example.com").Return(&models.User{ID: 42, Email: "ops@example.com"}, nil)
+	userService.EXPECT().UpdateUser(gomock.Any(), "42", gomock.Any()).DoAndReturn(func(ctx context.Context, userID string, user *models.User) (*models.User, error) {
+		assert.True(t, passwords.CheckPasswordHash("n3w-pass!word", user.Password))
+		assert.Empty(t, user.Email, "only the password changes")
+		return user, nil
+	})
+
+	service := NewAccountService(userService, mocks.NewMockRoleRepoInterface(ctrl), zaptest.NewLogger(t).Sugar())
+	require.NoError(t, service.ResetPassword(context.Background(), "ops@example.com", "n3w-pass!word"))
+}
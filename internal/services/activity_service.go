@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type ActivityService struct {
+	inactivityRepo repositories.InactivityRepoInterface
+	logger         *zap.SugaredLogger
+	now            func() time.Time
+}
+
+type ActivityServiceInterface interface {
+	// RecordLogin notes that the user signed in now, which also withdraws an inactivity warning
+	RecordLogin(ctx context.Context, userID uint) error
+}
+
+func NewActivityService(inactivityRepo repositories.InactivityRepoInterface, logger *zap.SugaredLogger) ActivityServiceInterface {
+	return &ActivityService{
+		inactivityRepo: inactivityRepo,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+func (service *ActivityService) RecordLogin(ctx context.Context, userID uint) error {
+	return service.inactivityRepo.RecordLogin(ctx, userID, service.now())
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type AdminAuditService struct {
+	auditRepo   repositories.AdminAuditRepoInterface
+	userService UserServiceInterface
+	txManager   repositories.TxManagerInterface
+	logger      *zap.SugaredLogger
+}
+
+type AdminAuditServiceInterface interface {
+	// UpdateUser and DeleteUser change the user like UserServiceInterface, on behalf of an admin, and record the
+	// change in the admin audit in the same transaction
+	UpdateUser(ctx context.Context, by *models.AdminAction, userID string, updatedData *models.User) (*models.User, error)
+	DeleteUser(ctx context.Context, by *models.AdminAction, userID string) (*models.User, error)
+	// CreateServiceAccount creates account as a service account, without a password, and records it in the admin
+	// audit in the same transaction
+	CreateServiceAccount(ctx context.Context, by *models.AdminAction, account *models.User) (*models.User, error)
+	ListAudit(ctx context.Context, query *models.AdminAuditQuery) ([]models.AdminAudit, error)
+	// ExportAudit hands every entry matching the query to fn, newest first, in pages of query.Limit
+	ExportAudit(ctx context.Context, query *models.AdminAuditQuery, fn func(entries []models.AdminAudit) error) error
+}
+
+func NewAdminAuditService(auditRepo repositories.AdminAuditRepoInterface, userService UserServiceInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) AdminAuditServiceInterface {
+	return &AdminAuditService{
+		auditRepo:   auditRepo,
+		userService: userService,
+		txManager:   txManager,
+		logger:      logger,
+	}
+}
+
+func (service *AdminAuditService) UpdateUser(ctx context.Context, by *models.AdminAction, userID string, updatedData *models.User) (*models.User, error) {
+	var user *models.User
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		prior, err := service.userService.GetUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		user, err = service.userService.UpdateUser(ctx, userID, updatedData)
+		if err != nil {
+			return err
+		}
+
+		if updatedData.RoleID > 0 && updatedData.RoleID != prior.RoleID {
+			details := fmt.Sprintf("role_id %d -> %d", prior.RoleID, updatedData.RoleID)
+			if err := service.record(ctx, by, models.AdminAuditRoleChanged, prior.ID, details); err != nil {
+				return err
+			}
+		}
+		if fields := updatedFields(updatedData); len(fields) > 0 {
+			return service.record(ctx, by, models.AdminAuditUserUpdated, prior.ID, strings.Join(fields, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (service *AdminAuditService) DeleteUser(ctx context.Context, by *models.AdminAction, userID string) (*models.User, error) {
+	var user *models.User
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = service.userService.DeleteUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return service.record(ctx, by, models.AdminAuditUserDeleted, user.ID, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (service *AdminAuditService) CreateServiceAccount(ctx context.Context, by *models.AdminAction, account *models.User) (*models.User, error) {
+	account.ServiceAccount = true
+	account.Password = ""
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		id, err := service.userService.CreateUser(ctx, account)
+		if err != nil {
+			return err
+		}
+		account.ID = id
+		return service.record(ctx, by, models.AdminAuditServiceAccountCreated, id, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (service *AdminAuditService) record(ctx context.Context, by *models.AdminAction, action string, targetID uint, details string) error {
+	return service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+		ActorID:  by.ActorID,
+		Action:   action,
+		TargetID: targetID,
+		Reason:   by.Reason,
+		Details:  details,
+	})
+}
+
+// updatedFields names the profile fields an update sets, leaving the role to its own entry
+func updatedFields(updatedData *models.User) []string {
+	var fields []string
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"email", updatedData.Email},
+		{"first_name", updatedData.FirstName},
+		{"last_name", updatedData.LastName},
+		{"password", updatedData.Password},
+		{"timezone", updatedData.Timezone},
+		{"locale", updatedData.Locale},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+func (service *AdminAuditService) ListAudit(ctx context.Context, query *models.AdminAuditQuery) ([]models.AdminAudit, error) {
+	return service.auditRepo.ListAdminAudit(ctx, query)
+}
+
+func (service *AdminAuditService) ExportAudit(ctx context.Context, query *models.AdminAuditQuery, fn func(entries []models.AdminAudit) error) error {
+	page := *query
+	for {
+		entries, err := service.auditRepo.ListAdminAudit(ctx, &page)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			if err := fn(entries); err != nil {
+				return err
+			}
+		}
+		if len(entries) < page.Limit {
+			return nil
+		}
+		page.BeforeID = entries[len(entries)-1].ID
+	}
+}
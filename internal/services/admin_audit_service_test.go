@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAdminAuditService_UpdateUserRecordsRoleAndFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := NewAdminAuditService(auditRepo, userService, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	by := &models.AdminAction{ActorID: 1, Reason: "ticket 42"}
+	ctx := context.Background()
+
+	userService.EXPECT().GetUser(gomock.Any(), "5").Return(&models.User{ID: 5, RoleID: 1}, nil).Times(2)
+	update := &models.User{RoleID: 3, Email: "new@example.com", Password: "hash"}
+	userService.EXPECT().UpdateUser(gomock.Any(), "5", update).Return(&models.User{ID: 5, RoleID: 3}, nil)
+	var recorded []*models.AdminAudit
+	auditRepo.EXPECT().CreateAdminAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entry *models.AdminAudit) error {
+		recorded = append(recorded, entry)
+		return nil
+	}).Times(2)
+
+	_, err := service.UpdateUser(ctx, by, "5", update)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, &models.AdminAudit{ActorID: 1, Action: models.AdminAuditRoleChanged, TargetID: 5, Reason: "ticket 42", Details: "role_id 1 -> 3"}, recorded[0])
+	assert.Equal(t, &models.AdminAudit{ActorID: 1, Action: models.AdminAuditUserUpdated, TargetID: 5, Reason: "ticket 42", Details: "email, password"}, recorded[1])
+
+	// Setting the role the user already has is no role change, and a failing audit fails the update
+	same := &models.User{RoleID: 1, FirstName: "Ann"}
+	userService.EXPECT().UpdateUser(gomock.Any(), "5", same).Return(&models.User{ID: 5, RoleID: 1}, nil)
+	auditRepo.EXPECT().CreateAdminAudit(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+	_, err = service.UpdateUser(ctx, by, "5", same)
+	assert.EqualError(t, err, "db down")
+}
+
+func TestAdminAuditService_CreateServiceAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := NewAdminAuditService(auditRepo, userService, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	by := &models.AdminAction{ActorID: 1, Reason: "billing export"}
+
+	userService.EXPECT().CreateUser(gomock.Any(), &models.User{Email: "billing@example.com", FirstName: "Billing", RoleID: 1, ServiceAccount: true}).Return(uint(40), nil)
+	auditRepo.EXPECT().CreateAdminAudit(gomock.Any(), &models.AdminAudit{ActorID: 1, Action: models.AdminAuditServiceAccountCreated, TargetID: 40, Reason: "billing export"}).Return(nil)
+
+	account, err := service.CreateServiceAccount(context.Background(), by, &models.User{Email: "billing@example.com", FirstName: "Billing", Password: "hash", RoleID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint(40), account.ID)
+	assert.True(t, account.ServiceAccount)
+	assert.Empty(t, account.Password, "service accounts have no password")
+}
+
+func TestAdminAuditService_ExportAuditPagesThroughEveryEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	service := NewAdminAuditService(auditRepo, NewMockUserServiceInterface(ctrl), newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+
+	gomock.InOrder(
+		auditRepo.EXPECT().ListAdminAudit(gomock.Any(), &models.AdminAuditQuery{ActorID: 1, Limit: 2}).Return([]models.AdminAudit{{ID: 9}, {ID: 7}}, nil),
+		auditRepo.EXPECT().ListAdminAudit(gomock.Any(), &models.AdminAuditQuery{ActorID: 1, BeforeID: 7, Limit: 2}).Return([]models.AdminAudit{{ID: 4}, {ID: 2}}, nil),
+		auditRepo.EXPECT().ListAdminAudit(gomock.Any(), &models.AdminAuditQuery{ActorID: 1, BeforeID: 2, Limit: 2}).Return(nil, nil),
+	)
+
+	var exported []uint64
+	err := service.ExportAudit(context.Background(), &models.AdminAuditQuery{ActorID: 1, Limit: 2}, func(entries []models.AdminAudit) error {
+		for _, entry := range entries {
+			exported = append(exported, entry.ID)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{9, 7, 4, 2}, exported)
+}
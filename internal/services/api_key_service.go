@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// apiKeyPrefix starts every API key, so leaked keys are easy to search for
+const apiKeyPrefix = "uk_"
+
+type APIKeyService struct {
+	apiKeyRepo  repositories.APIKeyRepoInterface
+	userService UserServiceInterface
+	// tiers maps each tier to the requests a key may make per window, 0 for no limit, and limiters holds the
+	// limiter of each tier with a limit. Keys created without a tier get defaultTier.
+	tiers       map[string]int
+	defaultTier string
+	window      time.Duration
+	limiters    map[string]ratelimit.Limiter
+	logger      *zap.SugaredLogger
+	now         func() time.Time
+}
+
+type APIKeyServiceInterface interface {
+	// Create creates the key for its user, in the default tier unless it has one, and returns it with its value,
+	// which is only kept hashed. Only keys of service accounts may have scopes.
+	Create(ctx context.Context, key *models.APIKey) (*models.APIKey, string, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	SetTier(ctx context.Context, id uint, tier string) error
+	Delete(ctx context.Context, id uint) error
+	// Authenticate returns the key with value and its user, UnauthorizedErr when there is none or the user was
+	// deleted
+	Authenticate(ctx context.Context, value string) (*models.APIKey, error)
+	// Allow counts a request of key against the limit of its tier and in its usage, and returns whether it is
+	// within the limit, and otherwise how long until the window ends. A failing limiter or counter is logged and
+	// the request allowed, so neither can take the API down.
+	Allow(ctx context.Context, key *models.APIKey) (bool, time.Duration)
+	// Usage returns the usage of the key over the last days, today included
+	Usage(ctx context.Context, id uint, days int) (*models.APIKeyUsageReport, error)
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepoInterface, userService UserServiceInterface, tiers map[string]int, defaultTier string, window time.Duration, limiters map[string]ratelimit.Limiter, logger *zap.SugaredLogger) APIKeyServiceInterface {
+	return &APIKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		userService: userService,
+		tiers:       tiers,
+		defaultTier: defaultTier,
+		window:      window,
+		limiters:    limiters,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (service *APIKeyService) Create(ctx context.Context, key *models.APIKey) (*models.APIKey, string, error) {
+	if key.Tier == "" {
+		key.Tier = service.defaultTier
+	}
+	if _, ok := service.tiers[key.Tier]; !ok {
+		return nil, "", apperrors.BadRequestErr.AppendMessage("unknown tier " + key.Tier)
+	}
+	user, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(key.UserID), 10))
+	if err != nil {
+		return nil, "", err
+	}
+	key.Scopes = strings.Join(strings.Fields(key.Scopes), " ")
+	if key.Scopes != "" && !user.ServiceAccount {
+		return nil, "", apperrors.BadRequestErr.AppendMessage("only keys of service accounts have scopes")
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	value := apiKeyPrefix + token
+	key.Prefix = value[:len(apiKeyPrefix)+8]
+	key.KeyHash = hashToken(value)
+	if err := service.apiKeyRepo.CreateKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, value, nil
+}
+
+func (service *APIKeyService) List(ctx context.Context) ([]models.APIKey, error) {
+	return service.apiKeyRepo.ListKeys(ctx)
+}
+
+func (service *APIKeyService) SetTier(ctx context.Context, id uint, tier string) error {
+	if _, ok := service.tiers[tier]; !ok {
+		return apperrors.BadRequestErr.AppendMessage("unknown tier " + tier)
+	}
+	return service.apiKeyRepo.SetTier(ctx, id, tier)
+}
+
+func (service *APIKeyService) Delete(ctx context.Context, id uint) error {
+	return service.apiKeyRepo.DeleteKey(ctx, id)
+}
+
+func (service *APIKeyService) Authenticate(ctx context.Context, value string) (*models.APIKey, error) {
+	key, err := service.apiKeyRepo.GetKeyByHash(ctx, hashToken(value))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil, apperrors.UnauthorizedErr.AppendMessage("unknown API key")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.User == nil || !key.User.DeletedAt.IsZero() {
+		return nil, apperrors.UnauthorizedErr.AppendMessage("the user of the API key was deleted")
+	}
+	return key, nil
+}
+
+func (service *APIKeyService) Allow(ctx context.Context, key *models.APIKey) (bool, time.Duration) {
+	allowed, retryAfter := true, time.Duration(0)
+	if limiter, ok := service.limiters[key.Tier]; ok {
+		var err error
+		allowed, retryAfter, err = limiter.Allow(ctx, strconv.FormatUint(uint64(key.ID), 10))
+		if err != nil {
+			service.logger.Error(err)
+			allowed = true
+		}
+	}
+	if err := service.apiKeyRepo.RecordUsage(ctx, key.ID, service.day(service.now()), !allowed); err != nil {
+		service.logger.Error(err)
+	}
+	return allowed, retryAfter
+}
+
+func (service *APIKeyService) Usage(ctx context.Context, id uint, days int) (*models.APIKeyUsageReport, error) {
+	if days <= 0 {
+		return nil, apperrors.BadRequestErr.AppendMessage("days should be positive")
+	}
+	// Usage is not scoped to tenants, the key is
+	key, err := service.apiKeyRepo.GetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := service.apiKeyRepo.ListUsage(ctx, id, service.day(service.now().AddDate(0, 0, 1-days)))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.APIKeyUsageReport{
+		APIKeyID: key.ID,
+		Tier:     key.Tier,
+		Limit:    service.tiers[key.Tier],
+		Window:   service.window.String(),
+		Days:     usage,
+	}
+	for _, counter := range usage {
+		report.Requests += counter.Requests
+		report.Limited += counter.Limited
+	}
+	return report, nil
+}
+
+// day formats t as the UTC day usage is counted by
+func (service *APIKeyService) day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
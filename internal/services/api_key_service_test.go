@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+// stubLimiter allows the first limit requests of each key
+type stubLimiter struct {
+	limit  int
+	counts map[string]int
+	err    error
+}
+
+func (l *stubLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	l.counts[key]++
+	return l.counts[key] <= l.limit, time.Minute, l.err
+}
+
+func newTestAPIKeyService(t *testing.T, limiter *stubLimiter) (*APIKeyService, *mocks.MockAPIKeyRepoInterface, *MockUserServiceInterface) {
+	ctrl := gomock.NewController(t)
+	apiKeyRepo := mocks.NewMockAPIKeyRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := NewAPIKeyService(apiKeyRepo, userService, map[string]int{"free": 2, "enterprise": 0}, "free", time.Minute,
+		map[string]ratelimit.Limiter{"free": limiter}, zaptest.NewLogger(t).Sugar()).(*APIKeyService)
+	service.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return service, apiKeyRepo, userService
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	service, apiKeyRepo, userService := newTestAPIKeyService(t, &stubLimiter{})
+	ctx := context.Background()
+
+	_, _, err := service.Create(ctx, &models.APIKey{Name: "Billing", UserID: 40, Tier: "gold"})
+	assert.True(t, errors.Is(err, &apperrors.BadRequestErr), "got %v", err)
+
+	var stored *models.APIKey
+	userService.EXPECT().GetUser(gomock.Any(), "40").Return(&models.User{ID: 40}, nil)
+	apiKeyRepo.EXPECT().CreateKey(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *models.APIKey) error {
+		stored = key
+		return nil
+	})
+	key, value, err := service.Create(ctx, &models.APIKey{Name: "Billing", UserID: 40})
+	require.NoError(t, err)
+	assert.Equal(t, "free", key.Tier, "the default tier")
+	assert.True(t, strings.HasPrefix(value, key.Prefix))
+	assert.Equal(t, hashToken(value), stored.KeyHash, "only the hash of the key is kept")
+
+	stored.User = &models.User{ID: 40}
+	apiKeyRepo.EXPECT().GetKeyByHash(gomock.Any(), stored.KeyHash).Return(stored, nil)
+	found, err := service.Authenticate(ctx, value)
+	require.NoError(t, err)
+	assert.Equal(t, uint(40), found.UserID)
+	apiKeyRepo.EXPECT().GetKeyByHash(gomock.Any(), stored.KeyHash).Return(&models.APIKey{UserID: 40, User: &models.User{ID: 40, DeletedAt: time.Now()}}, nil)
+	_, err = service.Authenticate(ctx, value)
+	assert.True(t, errors.Is(err, &apperrors.UnauthorizedErr), "the user was deleted, got %v", err)
+	apiKeyRepo.EXPECT().GetKeyByHash(gomock.Any(), gomock.Any()).Return(nil, apperrors.NoRecordFoundErr.AppendMessage("API key not found."))
+	_, err = service.Authenticate(ctx, "uk_unknown")
+	assert.True(t, errors.Is(err, &apperrors.UnauthorizedErr), "got %v", err)
+}
+
+func TestAPIKeyService_CreateScoped(t *testing.T) {
+	service, apiKeyRepo, userService := newTestAPIKeyService(t, &stubLimiter{})
+	ctx := context.Background()
+
+	userService.EXPECT().GetUser(gomock.Any(), "40").Return(&models.User{ID: 40}, nil)
+	_, _, err := service.Create(ctx, &models.APIKey{Name: "Billing", UserID: 40, Scopes: "stats.read"})
+	assert.True(t, errors.Is(err, &apperrors.BadRequestErr), "people's keys have no scopes, got %v", err)
+
+	userService.EXPECT().GetUser(gomock.Any(), "41").Return(&models.User{ID: 41, ServiceAccount: true}, nil)
+	apiKeyRepo.EXPECT().CreateKey(gomock.Any(), gomock.Any()).Return(nil)
+	key, _, err := service.Create(ctx, &models.APIKey{Name: "Reports", UserID: 41, Scopes: " stats.read  audit.read "})
+	require.NoError(t, err)
+	assert.Equal(t, "stats.read audit.read", key.Scopes)
+	assert.Equal(t, []string{"stats.read", "audit.read"}, key.Permissions())
+}
+
+func TestAPIKeyService_Allow(t *testing.T) {
+	limiter := &stubLimiter{limit: 2, counts: map[string]int{}}
+	service, apiKeyRepo, _ := newTestAPIKeyService(t, limiter)
+	ctx := context.Background()
+	free := &models.APIKey{ID: 7, Tier: "free"}
+
+	apiKeyRepo.EXPECT().RecordUsage(gomock.Any(), uint(7), "2024-03-01", false).Return(nil).Times(2)
+	apiKeyRepo.EXPECT().RecordUsage(gomock.Any(), uint(7), "2024-03-01", true).Return(nil)
+	for i := 0; i < 2; i++ {
+		allowed, _ := service.Allow(ctx, free)
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter := service.Allow(ctx, free)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+
+	apiKeyRepo.EXPECT().RecordUsage(gomock.Any(), uint(8), "2024-03-01", false).Return(errors.New("database is down"))
+	allowed, _ = service.Allow(ctx, &models.APIKey{ID: 8, Tier: "enterprise"})
+	assert.True(t, allowed, "enterprise keys have no limit, and a failing counter does not refuse requests")
+}
+
+func TestAPIKeyService_Usage(t *testing.T) {
+	service, apiKeyRepo, _ := newTestAPIKeyService(t, &stubLimiter{})
+	ctx := context.Background()
+
+	apiKeyRepo.EXPECT().GetKey(gomock.Any(), uint(7)).Return(&models.APIKey{ID: 7, Tier: "free"}, nil)
+	apiKeyRepo.EXPECT().ListUsage(gomock.Any(), uint(7), "2024-02-24").Return([]models.APIKeyUsage{
+		{APIKeyID: 7, Day: "2024-02-28", Requests: 10},
+		{APIKeyID: 7, Day: "2024-03-01", Requests: 5, Limited: 2},
+	}, nil)
+	report, err := service.Usage(ctx, 7, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 15, report.Requests)
+	assert.Equal(t, 2, report.Limited)
+	assert.Equal(t, 2, report.Limit)
+	assert.Equal(t, "1m0s", report.Window)
+}
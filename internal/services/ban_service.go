@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type BanService struct {
+	banRepo        repositories.BanRepoInterface
+	auditRepo      repositories.AdminAuditRepoInterface
+	rememberTokens repositories.RememberTokenRepoInterface
+	userService    UserServiceInterface
+	securityEvents SecurityEventServiceInterface
+	txManager      repositories.TxManagerInterface
+	logger         *zap.SugaredLogger
+	now            func() time.Time
+}
+
+type BanServiceInterface interface {
+	// Ban bans ban.UserID until ban.ExpiresAt on behalf of an admin, replacing a current ban, forgets their
+	// remembered devices and records it in the admin audit
+	Ban(ctx context.Context, by *models.AdminAction, ban *models.Ban) (*models.Ban, error)
+	// Lift ends the ban of the user before it expires and records it in the admin audit
+	Lift(ctx context.Context, by *models.AdminAction, userID uint) error
+	// ActiveBan returns the ban of the user, nil when they have none or it expired
+	ActiveBan(ctx context.Context, userID uint) (*models.Ban, error)
+	// ListBans returns a page of the bans in force, soonest to expire first
+	ListBans(ctx context.Context, page, pageSize int) (*models.BanPage, error)
+}
+
+func NewBanService(banRepo repositories.BanRepoInterface, auditRepo repositories.AdminAuditRepoInterface, rememberTokens repositories.RememberTokenRepoInterface, userService UserServiceInterface, securityEvents SecurityEventServiceInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) BanServiceInterface {
+	return &BanService{
+		banRepo:        banRepo,
+		auditRepo:      auditRepo,
+		rememberTokens: rememberTokens,
+		userService:    userService,
+		securityEvents: securityEvents,
+		txManager:      txManager,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+func (service *BanService) Ban(ctx context.Context, by *models.AdminAction, ban *models.Ban) (*models.Ban, error) {
+	now := service.now()
+	if !ban.ExpiresAt.After(now) {
+		return nil, apperrors.BadRequestErr.AppendMessage("expires_at should be in the future")
+	}
+	if ban.UserID == by.ActorID {
+		return nil, apperrors.BadRequestErr.AppendMessage("you cannot ban yourself")
+	}
+	if _, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(ban.UserID), 10)); err != nil {
+		return nil, err
+	}
+
+	ban.BannedBy = by.ActorID
+	ban.CreatedAt = now
+	expiresAt := ban.ExpiresAt.UTC().Format(time.RFC3339)
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.banRepo.SaveBan(ctx, ban); err != nil {
+			return err
+		}
+		if err := service.rememberTokens.DeleteUserTokens(ctx, ban.UserID); err != nil {
+			return err
+		}
+		return service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+			ActorID:  by.ActorID,
+			Action:   models.AdminAuditUserBanned,
+			TargetID: ban.UserID,
+			Reason:   by.Reason,
+			Details:  "until " + expiresAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	service.securityEvents.Record(ctx, ban.UserID, models.SecurityEventAccountBanned, "until "+expiresAt)
+	return ban, nil
+}
+
+func (service *BanService) Lift(ctx context.Context, by *models.AdminAction, userID uint) error {
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.banRepo.DeleteBan(ctx, userID); err != nil {
+			return err
+		}
+		return service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+			ActorID:  by.ActorID,
+			Action:   models.AdminAuditUserUnbanned,
+			TargetID: userID,
+			Reason:   by.Reason,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	service.securityEvents.Record(ctx, userID, models.SecurityEventAccountUnbanned, "")
+	return nil
+}
+
+func (service *BanService) ActiveBan(ctx context.Context, userID uint) (*models.Ban, error) {
+	ban, err := service.banRepo.GetBan(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// An expired ban is over even before the cleanup deletes it
+	if !ban.ExpiresAt.After(service.now()) {
+		return nil, nil
+	}
+	return ban, nil
+}
+
+func (service *BanService) ListBans(ctx context.Context, page, pageSize int) (*models.BanPage, error) {
+	bans, total, err := service.banRepo.ListBans(ctx, service.now(), page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if bans == nil {
+		bans = []models.Ban{}
+	}
+	return &models.BanPage{
+		Data:       bans,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
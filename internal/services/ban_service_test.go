@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBanService_Ban(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	banRepo := mocks.NewMockBanRepoInterface(ctrl)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	rememberTokens := mocks.NewMockRememberTokenRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	service := NewBanService(banRepo, auditRepo, rememberTokens, userService, securityEvents, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar()).(*BanService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	by := &models.AdminAction{ActorID: 3, Reason: "Ticket 42"}
+
+	_, err := service.Ban(ctx, by, &models.Ban{UserID: 2, Reason: "Spam", ExpiresAt: now})
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), "bans expire in the future")
+	_, err = service.Ban(ctx, by, &models.Ban{UserID: 3, Reason: "Spam", ExpiresAt: now.Add(time.Hour)})
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), "admins cannot ban themselves")
+
+	expiresAt := now.Add(72 * time.Hour)
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2}, nil)
+	banRepo.EXPECT().SaveBan(ctx, &models.Ban{UserID: 2, Reason: "Spam", ExpiresAt: expiresAt, BannedBy: 3, CreatedAt: now}).Return(nil)
+	rememberTokens.EXPECT().DeleteUserTokens(ctx, uint(2)).Return(nil)
+	auditRepo.EXPECT().CreateAdminAudit(ctx, &models.AdminAudit{
+		ActorID:  3,
+		Action:   models.AdminAuditUserBanned,
+		TargetID: 2,
+		Reason:   "Ticket 42",
+		Details:  "until 2024-05-04T12:00:00Z",
+	}).Return(nil)
+	securityEvents.EXPECT().Record(ctx, uint(2), models.SecurityEventAccountBanned, "until 2024-05-04T12:00:00Z")
+
+	ban, err := service.Ban(ctx, by, &models.Ban{UserID: 2, Reason: "Spam", ExpiresAt: expiresAt})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), ban.BannedBy)
+}
+
+func TestBanService_ActiveBan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	banRepo := mocks.NewMockBanRepoInterface(ctrl)
+	service := NewBanService(banRepo, nil, nil, nil, nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar()).(*BanService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	banRepo.EXPECT().GetBan(ctx, uint(1)).Return(nil, repositories.ErrNotFound.AppendMessage("Ban not found."))
+	ban, err := service.ActiveBan(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+
+	banRepo.EXPECT().GetBan(ctx, uint(2)).Return(&models.Ban{UserID: 2, ExpiresAt: now}, nil)
+	ban, err = service.ActiveBan(ctx, 2)
+	require.NoError(t, err)
+	assert.Nil(t, ban, "a ban is over when it expires, before the cleanup deletes it")
+
+	banRepo.EXPECT().GetBan(ctx, uint(3)).Return(&models.Ban{UserID: 3, ExpiresAt: now.Add(time.Minute)}, nil)
+	ban, err = service.ActiveBan(ctx, 3)
+	require.NoError(t, err)
+	assert.NotNil(t, ban)
+}
+
+func TestBanService_Lift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	banRepo := mocks.NewMockBanRepoInterface(ctrl)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	service := NewBanService(banRepo, auditRepo, nil, nil, securityEvents, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	by := &models.AdminAction{ActorID: 3}
+
+	banRepo.EXPECT().DeleteBan(ctx, uint(1)).Return(repositories.ErrNotFound.AppendMessage("Ban not found."))
+	assert.ErrorIs(t, service.Lift(ctx, by, 1), repositories.ErrNotFound)
+
+	banRepo.EXPECT().DeleteBan(ctx, uint(2)).Return(nil)
+	auditRepo.EXPECT().CreateAdminAudit(ctx, &models.AdminAudit{ActorID: 3, Action: models.AdminAuditUserUnbanned, TargetID: 2}).Return(nil)
+	securityEvents.EXPECT().Record(ctx, uint(2), models.SecurityEventAccountUnbanned, "")
+	require.NoError(t, service.Lift(ctx, by, 2))
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type ChangeService struct {
+	changeRepo repositories.ChangeRepoInterface
+	logger     *zap.SugaredLogger
+}
+
+type ChangeServiceInterface interface {
+	// ListChanges returns up to limit user changes after sinceSeq, for replication to systems that follow the feed
+	ListChanges(ctx context.Context, sinceSeq uint64, limit int) (*models.ChangesPage, error)
+}
+
+func NewChangeService(changeRepo repositories.ChangeRepoInterface, logger *zap.SugaredLogger) ChangeServiceInterface {
+	return &ChangeService{
+		changeRepo: changeRepo,
+		logger:     logger,
+	}
+}
+
+func (service *ChangeService) ListChanges(ctx context.Context, sinceSeq uint64, limit int) (*models.ChangesPage, error) {
+	// One change past the limit tells whether another page follows
+	changes, err := service.changeRepo.ListChanges(ctx, sinceSeq, limit+1)
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+
+	if changes == nil {
+		changes = []models.Change{}
+	}
+	page := &models.ChangesPage{Data: changes, NextSeq: sinceSeq}
+	if len(changes) > limit {
+		page.Data, page.HasMore = changes[:limit], true
+	}
+	if len(page.Data) > 0 {
+		page.NextSeq = page.Data[len(page.Data)-1].Seq
+	}
+	return page, nil
+}
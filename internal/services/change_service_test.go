@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChangeService_ListChangesPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	changeRepo := mocks.NewMockChangeRepoInterface(ctrl)
+	service := NewChangeService(changeRepo, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	changeRepo.EXPECT().ListChanges(gomock.Any(), uint64(10), 3).Return([]models.Change{{Seq: 11}, {Seq: 14}, {Seq: 15}}, nil)
+	page, err := service.ListChanges(ctx, 10, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &models.ChangesPage{Data: []models.Change{{Seq: 11}, {Seq: 14}}, HasMore: true, NextSeq: 14}, page)
+
+	changeRepo.EXPECT().ListChanges(gomock.Any(), uint64(15), 3).Return(nil, nil)
+	page, err = service.ListChanges(ctx, 15, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &models.ChangesPage{Data: []models.Change{}, NextSeq: 15}, page, "an empty page keeps the position")
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+const replayBatchSize = 500
+
+type EventService struct {
+	eventRepo repositories.EventRepoInterface
+	logger    *zap.SugaredLogger
+}
+
+type EventServiceInterface interface {
+	SaveEvent(ctx context.Context, event *events.Event) (uint64, error)
+	Replay(ctx context.Context, filter models.EventFilter, sink events.Publisher) (int, error)
+}
+
+func NewEventService(eventRepo repositories.EventRepoInterface, logger *zap.SugaredLogger) EventServiceInterface {
+	return &EventService{
+		eventRepo: eventRepo,
+		logger:    logger,
+	}
+}
+
+func (service *EventService) SaveEvent(ctx context.Context, event *events.Event) (uint64, error) {
+	stored, err := service.eventRepo.CreateEvent(ctx, &models.Event{
+		EventID:         event.ID,
+		Source:          event.Source,
+		Type:            event.Type,
+		Subject:         event.Subject,
+		Time:            event.Time,
+		DataContentType: event.DataContentType,
+		Data:            string(event.Data),
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return 0, err
+	}
+
+	return stored.Seq, nil
+}
+
+// Replay re-publishes every stored event matching the filter to the sink in sequence order
+// and returns how many were delivered before the first failure.
+func (service *EventService) Replay(ctx context.Context, filter models.EventFilter, sink events.Publisher) (int, error) {
+	replayed := 0
+	var afterSeq uint64
+
+	for {
+		batch, err := service.eventRepo.ListEvents(ctx, filter, afterSeq, replayBatchSize)
+		if err != nil {
+			service.logger.Error(err)
+			return replayed, err
+		}
+
+		for _, stored := range batch {
+			event := &events.Event{
+				SpecVersion:     events.SpecVersion,
+				ID:              stored.EventID,
+				Source:          stored.Source,
+				Type:            stored.Type,
+				Subject:         stored.Subject,
+				Time:            stored.Time,
+				DataContentType: stored.DataContentType,
+				Sequence:        strconv.FormatUint(stored.Seq, 10),
+			}
+			if stored.Data != "" {
+				event.Data = json.RawMessage(stored.Data)
+			}
+
+			err = sink.Publish(ctx, event)
+			if err != nil {
+				service.logger.Errorw("Replay stopped", "seq", stored.Seq, "error", err)
+				return replayed, err
+			}
+			replayed++
+			afterSeq = stored.Seq
+		}
+
+		if len(batch) < replayBatchSize {
+			return replayed, nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingSink struct {
+	published []*events.Event
+	failOn    int
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event *events.Event) error {
+	if s.failOn > 0 && len(s.published)+1 == s.failOn {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event)
+	return nil
+}
+
+func TestEventService_SaveEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockEventRepoInterface(ctrl)
+	eventService := NewEventService(mockRepo, zaptest.NewLogger(t).Sugar())
+
+	event, _ := events.New("urn:test", events.UserCreated, "1", map[string]int{"user_id": 1})
+	mockRepo.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, stored *models.Event) (*models.Event, error) {
+			assert.Equal(t, event.ID, stored.EventID)
+			assert.JSONEq(t, `{"user_id":1}`, stored.Data)
+			stored.Seq = 7
+			return stored, nil
+		})
+
+	seq, err := eventService.SaveEvent(context.Background(), event)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), seq)
+}
+
+func TestEventService_Replay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockEventRepoInterface(ctrl)
+	eventService := NewEventService(mockRepo, zaptest.NewLogger(t).Sugar())
+
+	filter := models.EventFilter{FromSeq: 3}
+	stored := []models.Event{
+		{Seq: 3, EventID: "a", Type: events.UserCreated, Data: `{"user_id":1}`},
+		{Seq: 4, EventID: "b", Type: events.UserDeleted},
+	}
+	mockRepo.EXPECT().ListEvents(gomock.Any(), filter, uint64(0), replayBatchSize).Return(stored, nil)
+
+	sink := &recordingSink{}
+	replayed, err := eventService.Replay(context.Background(), filter, sink)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	assert.Equal(t, "3", sink.published[0].Sequence)
+	assert.Equal(t, events.SpecVersion, sink.published[0].SpecVersion)
+	assert.Nil(t, sink.published[1].Data)
+}
+
+func TestEventService_Replay_SinkError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockEventRepoInterface(ctrl)
+	eventService := NewEventService(mockRepo, zaptest.NewLogger(t).Sugar())
+
+	stored := []models.Event{{Seq: 1, EventID: "a"}, {Seq: 2, EventID: "b"}}
+	mockRepo.EXPECT().ListEvents(gomock.Any(), models.EventFilter{}, uint64(0), replayBatchSize).Return(stored, nil)
+
+	replayed, err := eventService.Replay(context.Background(), models.EventFilter{}, &recordingSink{failOn: 2})
+	assert.Error(t, err)
+	assert.Equal(t, 1, replayed)
+}
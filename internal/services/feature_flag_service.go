@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type FeatureFlagService struct {
+	flagRepo        repositories.FeatureFlagRepoInterface
+	refreshInterval time.Duration
+	logger          *zap.SugaredLogger
+
+	static atomic.Pointer[map[string]bool]
+
+	mu        sync.Mutex
+	overrides []models.FeatureFlag
+	loadedAt  time.Time
+}
+
+type FeatureFlagServiceInterface interface {
+	// Enabled reports whether a feature is on for userID (0 for anonymous requests). An override for the user wins
+	// over one for everybody, which wins over FEATURE_FLAGS and then models.DefaultFeatures; unknown features are off.
+	Enabled(ctx context.Context, name string, userID uint) bool
+	ListFeatures(ctx context.Context) ([]models.FeatureState, error)
+	SetOverride(ctx context.Context, flag *models.FeatureFlag) error
+	DeleteOverride(ctx context.Context, name string, userID uint) error
+	// SetStatic replaces the FEATURE_FLAGS settings, e.g. on config reload
+	SetStatic(static map[string]bool)
+}
+
+// NewFeatureFlagService evaluates flags against static, with overrides read from flagRepo and cached for
+// refreshInterval so a change made on another instance applies within that time
+func NewFeatureFlagService(flagRepo repositories.FeatureFlagRepoInterface, static map[string]bool, refreshInterval time.Duration, logger *zap.SugaredLogger) FeatureFlagServiceInterface {
+	service := &FeatureFlagService{
+		flagRepo:        flagRepo,
+		refreshInterval: refreshInterval,
+		logger:          logger,
+	}
+	service.SetStatic(static)
+	return service
+}
+
+func (service *FeatureFlagService) SetStatic(static map[string]bool) {
+	for name := range static {
+		if _, known := models.DefaultFeatures[name]; !known {
+			service.logger.Warnf("FEATURE_FLAGS sets unknown feature %q", name)
+		}
+	}
+	service.static.Store(&static)
+}
+
+func (service *FeatureFlagService) Enabled(ctx context.Context, name string, userID uint) bool {
+	enabled, known := models.DefaultFeatures[name]
+	if !known {
+		return false
+	}
+	if value, ok := (*service.static.Load())[name]; ok {
+		enabled = value
+	}
+
+	userOverride := false
+	for _, flag := range service.cachedOverrides(ctx) {
+		if flag.Name != name {
+			continue
+		}
+		if flag.UserID == 0 && !userOverride {
+			enabled = flag.Enabled
+		}
+		if flag.UserID != 0 && flag.UserID == userID {
+			enabled, userOverride = flag.Enabled, true
+		}
+	}
+	return enabled
+}
+
+func (service *FeatureFlagService) ListFeatures(ctx context.Context) ([]models.FeatureState, error) {
+	overrides, err := service.flagRepo.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	service.storeOverrides(overrides)
+
+	names := make([]string, 0, len(models.DefaultFeatures))
+	for name := range models.DefaultFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	states := make([]models.FeatureState, 0, len(names))
+	for _, name := range names {
+		state := models.FeatureState{Name: name, Enabled: service.Enabled(ctx, name, 0)}
+		for _, flag := range overrides {
+			if flag.Name == name {
+				state.Overrides = append(state.Overrides, flag)
+			}
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (service *FeatureFlagService) SetOverride(ctx context.Context, flag *models.FeatureFlag) error {
+	if _, known := models.DefaultFeatures[flag.Name]; !known {
+		return apperrors.NoRecordFoundErr.AppendMessage("Unknown feature flag " + flag.Name + ".")
+	}
+	flag.UpdatedAt = time.Now()
+	err := service.flagRepo.SetFeatureFlag(ctx, flag)
+	if err != nil {
+		return err
+	}
+	service.invalidate()
+	return nil
+}
+
+func (service *FeatureFlagService) DeleteOverride(ctx context.Context, name string, userID uint) error {
+	err := service.flagRepo.DeleteFeatureFlag(ctx, name, userID)
+	if err != nil {
+		return err
+	}
+	service.invalidate()
+	return nil
+}
+
+// cachedOverrides returns the overrides, reloading them once they are older than the refresh interval.
+// A failed reload keeps the previous overrides so a database outage does not flip features.
+func (service *FeatureFlagService) cachedOverrides(ctx context.Context) []models.FeatureFlag {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if !service.loadedAt.IsZero() && time.Since(service.loadedAt) < service.refreshInterval {
+		return service.overrides
+	}
+
+	overrides, err := service.flagRepo.ListFeatureFlags(ctx)
+	if err != nil {
+		service.logger.Errorf("Loading feature flag overrides failed: %v", err)
+		// Retry on the next interval rather than on every check
+		service.loadedAt = time.Now()
+		return service.overrides
+	}
+	service.overrides, service.loadedAt = overrides, time.Now()
+	return service.overrides
+}
+
+func (service *FeatureFlagService) storeOverrides(overrides []models.FeatureFlag) {
+	service.mu.Lock()
+	service.overrides, service.loadedAt = overrides, time.Now()
+	service.mu.Unlock()
+}
+
+func (service *FeatureFlagService) invalidate() {
+	service.mu.Lock()
+	service.loadedAt = time.Time{}
+	service.mu.Unlock()
+}
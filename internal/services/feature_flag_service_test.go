@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFeatureFlagService_Enabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFeatureFlagRepoInterface(ctrl)
+	mockRepo.EXPECT().ListFeatureFlags(gomock.Any()).Return([]models.FeatureFlag{
+		{Name: models.FeatureVoting, Enabled: false},
+		{Name: models.FeatureVoting, UserID: 7, Enabled: true},
+	}, nil).Times(1)
+
+	service := NewFeatureFlagService(mockRepo, map[string]bool{models.FeatureRegistration: false}, time.Minute, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	assert.False(t, service.Enabled(ctx, models.FeatureVoting, 1), "global override beats the default")
+	assert.True(t, service.Enabled(ctx, models.FeatureVoting, 7), "user override beats the global one")
+	assert.False(t, service.Enabled(ctx, models.FeatureRegistration, 0), "config beats the default")
+	assert.False(t, service.Enabled(ctx, "unknown", 0), "unknown features are off")
+
+	service.SetStatic(nil)
+	assert.True(t, service.Enabled(ctx, models.FeatureRegistration, 0), "default once the config is removed")
+}
+
+func TestFeatureFlagService_KeepsOverridesWhenReloadFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFeatureFlagRepoInterface(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().ListFeatureFlags(gomock.Any()).Return([]models.FeatureFlag{{Name: models.FeatureVoting, Enabled: false}}, nil),
+		mockRepo.EXPECT().ListFeatureFlags(gomock.Any()).Return(nil, errors.New("database unavailable")),
+	)
+
+	service := NewFeatureFlagService(mockRepo, nil, time.Nanosecond, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	assert.False(t, service.Enabled(ctx, models.FeatureVoting, 0))
+	time.Sleep(time.Millisecond)
+	assert.False(t, service.Enabled(ctx, models.FeatureVoting, 0), "stale override is kept")
+}
+
+func TestFeatureFlagService_SetOverrideRejectsUnknownFeature(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := NewFeatureFlagService(mocks.NewMockFeatureFlagRepoInterface(ctrl), nil, time.Minute, zaptest.NewLogger(t).Sugar())
+
+	err := service.SetOverride(context.Background(), &models.FeatureFlag{Name: "unknown", Enabled: true})
+	assert.True(t, errors.Is(err, &apperrors.NoRecordFoundErr))
+}
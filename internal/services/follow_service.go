@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type FollowService struct {
+	followRepo  repositories.FollowRepoInterface
+	userService UserServiceInterface
+	emitter     *events.Emitter
+	logger      *zap.SugaredLogger
+}
+
+type FollowServiceInterface interface {
+	// Follow subscribes followerID to the profile of followedID; following a profile again changes nothing
+	Follow(ctx context.Context, followerID, followedID uint) error
+	Unfollow(ctx context.Context, followerID, followedID uint) error
+	// ListFollowers returns a page of the users following userID, latest first
+	ListFollowers(ctx context.Context, userID uint, page, pageSize int) (*models.FollowPage, error)
+	// ListFollowing returns a page of the profiles userID follows, latest first
+	ListFollowing(ctx context.Context, userID uint, page, pageSize int) (*models.FollowPage, error)
+	Counts(ctx context.Context, userID uint) (*models.FollowCounts, error)
+}
+
+func NewFollowService(followRepo repositories.FollowRepoInterface, userService UserServiceInterface, emitter *events.Emitter, logger *zap.SugaredLogger) FollowServiceInterface {
+	return &FollowService{
+		followRepo:  followRepo,
+		userService: userService,
+		emitter:     emitter,
+		logger:      logger,
+	}
+}
+
+func (service *FollowService) Follow(ctx context.Context, followerID, followedID uint) error {
+	if followerID == followedID {
+		return apperrors.BadRequestErr.AppendMessage("you cannot follow yourself")
+	}
+	if err := service.requireUser(ctx, followedID); err != nil {
+		return err
+	}
+
+	created, err := service.followRepo.CreateFollow(ctx, &models.Follow{FollowerID: followerID, FollowedID: followedID})
+	if err != nil {
+		return err
+	}
+	if created {
+		service.emitFollowEvent(ctx, events.UserFollowed, followerID, followedID)
+	}
+	return nil
+}
+
+func (service *FollowService) Unfollow(ctx context.Context, followerID, followedID uint) error {
+	if err := service.followRepo.DeleteFollow(ctx, followerID, followedID); err != nil {
+		return err
+	}
+	service.emitFollowEvent(ctx, events.UserUnfollowed, followerID, followedID)
+	return nil
+}
+
+func (service *FollowService) ListFollowers(ctx context.Context, userID uint, page, pageSize int) (*models.FollowPage, error) {
+	return service.list(ctx, service.followRepo.ListFollowers, userID, page, pageSize)
+}
+
+func (service *FollowService) ListFollowing(ctx context.Context, userID uint, page, pageSize int) (*models.FollowPage, error) {
+	return service.list(ctx, service.followRepo.ListFollowing, userID, page, pageSize)
+}
+
+func (service *FollowService) list(ctx context.Context, list func(ctx context.Context, userID uint, page, pageSize int) ([]models.Follow, int, error), userID uint, page, pageSize int) (*models.FollowPage, error) {
+	if err := service.requireUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	follows, total, err := list(ctx, userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if follows == nil {
+		follows = []models.Follow{}
+	}
+	return &models.FollowPage{
+		Data:       follows,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
+
+func (service *FollowService) Counts(ctx context.Context, userID uint) (*models.FollowCounts, error) {
+	if err := service.requireUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return service.followRepo.CountFollows(ctx, userID)
+}
+
+// requireUser returns NoRecordFoundErr unless the user exists and is not deleted
+func (service *FollowService) requireUser(ctx context.Context, userID uint) error {
+	_, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(userID), 10))
+	return err
+}
+
+func (service *FollowService) emitFollowEvent(ctx context.Context, eventType string, followerID, followedID uint) {
+	type followEventData struct {
+		UserID    uint `json:"user_id"`
+		ProfileID uint `json:"profile_id"`
+	}
+
+	subject := strconv.FormatUint(uint64(followedID), 10)
+	service.emitter.Emit(ctx, eventType, subject, &followEventData{UserID: followerID, ProfileID: followedID})
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+// recordingPublisher keeps the types of the events it is given
+type recordingPublisher []string
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *events.Event) error {
+	*p = append(*p, event.Type+" "+event.Subject)
+	return nil
+}
+
+func TestFollowService_FollowEmitsOnlyNewFollows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	followRepo := mocks.NewMockFollowRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	var published recordingPublisher
+	logger := zaptest.NewLogger(t).Sugar()
+	service := NewFollowService(followRepo, userService, events.NewEmitter("urn:test", &published, logger), logger)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUser(ctx, "7").Return(&models.User{ID: 7}, nil).Times(2)
+	followRepo.EXPECT().CreateFollow(ctx, &models.Follow{FollowerID: 3, FollowedID: 7}).Return(true, nil)
+	followRepo.EXPECT().CreateFollow(ctx, &models.Follow{FollowerID: 3, FollowedID: 7}).Return(false, nil)
+	require.NoError(t, service.Follow(ctx, 3, 7))
+	require.NoError(t, service.Follow(ctx, 3, 7))
+
+	followRepo.EXPECT().DeleteFollow(ctx, uint(3), uint(7)).Return(nil)
+	require.NoError(t, service.Unfollow(ctx, 3, 7))
+	assert.Equal(t, recordingPublisher{events.UserFollowed + " 7", events.UserUnfollowed + " 7"}, published)
+
+	err := service.Follow(ctx, 3, 3)
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr))
+
+	userService.EXPECT().GetUser(ctx, "9").Return(nil, repositories.ErrNotFound)
+	assert.ErrorIs(t, service.Follow(ctx, 3, 9), repositories.ErrNotFound)
+}
+
+func TestFollowService_ListFollowersPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	followRepo := mocks.NewMockFollowRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := NewFollowService(followRepo, userService, nil, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	userService.EXPECT().GetUser(ctx, "7").Return(&models.User{ID: 7}, nil).Times(2)
+	followRepo.EXPECT().ListFollowers(ctx, uint(7), 2, 2).Return([]models.Follow{{FollowerID: 3, FollowedID: 7}}, 3, nil)
+	page, err := service.ListFollowers(ctx, 7, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.TotalPages)
+	assert.False(t, page.HasNext)
+	assert.Len(t, page.Data, 1)
+
+	followRepo.EXPECT().ListFollowing(ctx, uint(7), 1, 10).Return(nil, 0, nil)
+	page, err = service.ListFollowing(ctx, 7, 1, 10)
+	require.NoError(t, err)
+	assert.NotNil(t, page.Data, "an empty page lists no follows rather than null")
+}
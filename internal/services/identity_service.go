@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type IdentityService struct {
+	identityRepo repositories.IdentityRepoInterface
+	userRepo     repositories.UserRepoInterface
+	txManager    repositories.TxManagerInterface
+	logger       *zap.SugaredLogger
+}
+
+type IdentityServiceInterface interface {
+	// ListIdentities returns the ways the user can sign in. A user who never linked a provider has only their
+	// password, which is listed without being stored.
+	ListIdentities(ctx context.Context, userID uint) ([]models.Identity, error)
+	// LinkIdentity links the account subject at provider to the user. It is for sign-in flows to call once the
+	// provider has verified the account; the password identity comes with the user and cannot be linked.
+	LinkIdentity(ctx context.Context, userID uint, provider, subject string) (*models.Identity, error)
+	// UnlinkIdentity removes the user's identity at provider, unless it is the last one
+	UnlinkIdentity(ctx context.Context, userID uint, provider string) error
+	// PasswordLogin reports whether user may still sign in with their password
+	PasswordLogin(ctx context.Context, user *models.User) (bool, error)
+}
+
+func NewIdentityService(identityRepo repositories.IdentityRepoInterface, userRepo repositories.UserRepoInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) IdentityServiceInterface {
+	return &IdentityService{
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		txManager:    txManager,
+		logger:       logger,
+	}
+}
+
+// passwordIdentity is the identity of a user who never linked a provider
+func passwordIdentity(user *models.User) models.Identity {
+	return models.Identity{
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Provider:  models.ProviderPassword,
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func (service *IdentityService) ListIdentities(ctx context.Context, userID uint) ([]models.Identity, error) {
+	identities, err := service.identityRepo.ListIdentities(ctx, userID)
+	if err != nil || len(identities) > 0 {
+		return identities, err
+	}
+
+	user, err := service.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Password == "" {
+		return []models.Identity{}, nil
+	}
+	return []models.Identity{passwordIdentity(user)}, nil
+}
+
+func (service *IdentityService) LinkIdentity(ctx context.Context, userID uint, provider, subject string) (*models.Identity, error) {
+	if provider == models.ProviderPassword || !knownProvider(provider) || subject == "" {
+		return nil, apperrors.BadRequestErr.AppendMessage("cannot link a " + strconv.Quote(provider) + " identity")
+	}
+
+	identity := &models.Identity{UserID: userID, Provider: provider, Subject: subject}
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Locking the user serializes links and unlinks, so two of them cannot both pass the checks
+		user, err := service.userRepo.LockUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		identities, err := service.identityRepo.ListIdentities(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if hasProvider(identities, provider) {
+			return apperrors.IdentityLinkedErr.AppendMessage(provider)
+		}
+
+		// The password stops being implicit once there is another way in, so it can be unlinked like any other
+		if len(identities) == 0 && user.Password != "" {
+			password := passwordIdentity(user)
+			if err := service.identityRepo.CreateIdentity(ctx, &password); err != nil {
+				return err
+			}
+		}
+
+		identity.TenantID = user.TenantID
+		err = service.identityRepo.CreateIdentity(ctx, identity)
+		if errors.Is(err, repositories.ErrDuplicate) {
+			return apperrors.IdentityLinkedErr.AppendMessage(provider)
+		}
+		return err
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (service *IdentityService) UnlinkIdentity(ctx context.Context, userID uint, provider string) error {
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := service.userRepo.LockUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		identities, err := service.identityRepo.ListIdentities(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) == 0 && user.Password != "" {
+			identities = []models.Identity{passwordIdentity(user)}
+		}
+
+		if !hasProvider(identities, provider) {
+			return repositories.ErrNotFound.AppendMessage("Identity not found.")
+		}
+		if len(identities) == 1 {
+			return apperrors.LastIdentityErr.AppendMessage(provider)
+		}
+		return service.identityRepo.DeleteIdentity(ctx, userID, provider)
+	})
+	if err != nil {
+		service.logger.Error(err)
+	}
+	return err
+}
+
+func (service *IdentityService) PasswordLogin(ctx context.Context, user *models.User) (bool, error) {
+	identities, err := service.identityRepo.ListIdentities(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return len(identities) == 0 || hasProvider(identities, models.ProviderPassword), nil
+}
+
+func knownProvider(provider string) bool {
+	for _, known := range models.Providers {
+		if provider == known {
+			return true
+		}
+	}
+	return false
+}
+
+func hasProvider(identities []models.Identity, provider string) bool {
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
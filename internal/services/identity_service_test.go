@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIdentityService_PasswordIsTheIdentityOfUnlinkedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	identityRepo := mocks.NewMockIdentityRepoInterface(ctrl)
+	userRepo := mocks.NewMockUserRepoInterface(ctrl)
+	user := &models.User{ID: 7, Password: "hash"}
+	identityRepo.EXPECT().ListIdentities(gomock.Any(), uint(7)).Return(nil, nil).AnyTimes()
+	userRepo.EXPECT().GetUserByID(gomock.Any(), uint(7)).Return(user, nil)
+	userRepo.EXPECT().LockUserByID(gomock.Any(), uint(7)).Return(user, nil).Times(2)
+	service := NewIdentityService(identityRepo, userRepo, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	identities, err := service.ListIdentities(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, models.ProviderPassword, identities[0].Provider)
+
+	err = service.UnlinkIdentity(ctx, 7, models.ProviderPassword)
+	assert.True(t, errors.Is(err, &apperrors.LastIdentityErr), "got %v", err)
+	err = service.UnlinkIdentity(ctx, 7, models.ProviderGitHub)
+	assert.True(t, errors.Is(err, repositories.ErrNotFound), "got %v", err)
+
+	allowed, err := service.PasswordLogin(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestIdentityService_LinkStoresThePasswordFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	identityRepo := mocks.NewMockIdentityRepoInterface(ctrl)
+	userRepo := mocks.NewMockUserRepoInterface(ctrl)
+	userRepo.EXPECT().LockUserByID(gomock.Any(), uint(7)).Return(&models.User{ID: 7, TenantID: 2, Password: "hash"}, nil)
+	identityRepo.EXPECT().ListIdentities(gomock.Any(), uint(7)).Return(nil, nil)
+	gomock.InOrder(
+		identityRepo.EXPECT().CreateIdentity(gomock.Any(), &models.Identity{TenantID: 2, UserID: 7, Provider: models.ProviderPassword, Subject: "7"}).Return(nil),
+		identityRepo.EXPECT().CreateIdentity(gomock.Any(), &models.Identity{TenantID: 2, UserID: 7, Provider: models.ProviderGitHub, Subject: "octocat"}).Return(nil),
+	)
+	service := NewIdentityService(identityRepo, userRepo, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+
+	identity, err := service.LinkIdentity(context.Background(), 7, models.ProviderGitHub, "octocat")
+	require.NoError(t, err)
+	assert.Equal(t, models.ProviderGitHub, identity.Provider)
+}
+
+func TestIdentityService_LinkRefusesTakenAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	identityRepo := mocks.NewMockIdentityRepoInterface(ctrl)
+	userRepo := mocks.NewMockUserRepoInterface(ctrl)
+	userRepo.EXPECT().LockUserByID(gomock.Any(), uint(7)).Return(&models.User{ID: 7}, nil).Times(2)
+	identityRepo.EXPECT().ListIdentities(gomock.Any(), uint(7)).Return([]models.Identity{{UserID: 7, Provider: models.ProviderGoogle}}, nil).Times(2)
+	identityRepo.EXPECT().CreateIdentity(gomock.Any(), gomock.Any()).Return(repositories.ErrDuplicate.AppendMessage("identities_tenant_provider_subject_key"))
+	service := NewIdentityService(identityRepo, userRepo, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	_, err := service.LinkIdentity(ctx, 7, models.ProviderGoogle, "another-account")
+	assert.True(t, errors.Is(err, &apperrors.IdentityLinkedErr), "one identity per provider, got %v", err)
+	_, err = service.LinkIdentity(ctx, 7, models.ProviderGitHub, "linked-to-someone-else")
+	assert.True(t, errors.Is(err, &apperrors.IdentityLinkedErr), "got %v", err)
+	_, err = service.LinkIdentity(ctx, 7, models.ProviderPassword, "7")
+	assert.True(t, errors.Is(err, &apperrors.BadRequestErr), "got %v", err)
+}
+
+func TestIdentityService_UnlinkKeepsTheLastIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	identityRepo := mocks.NewMockIdentityRepoInterface(ctrl)
+	userRepo := mocks.NewMockUserRepoInterface(ctrl)
+	user := &models.User{ID: 7, Password: "hash"}
+	userRepo.EXPECT().LockUserByID(gomock.Any(), uint(7)).Return(user, nil).Times(2)
+	gomock.InOrder(
+		identityRepo.EXPECT().ListIdentities(gomock.Any(), uint(7)).Return([]models.Identity{
+			{UserID: 7, Provider: models.ProviderPassword},
+			{UserID: 7, Provider: models.ProviderGitHub},
+		}, nil),
+		identityRepo.EXPECT().DeleteIdentity(gomock.Any(), uint(7), models.ProviderPassword).Return(nil),
+		identityRepo.EXPECT().ListIdentities(gomock.Any(), uint(7)).Return([]models.Identity{{UserID: 7, Provider: models.ProviderGitHub}}, nil).Times(2),
+	)
+	service := NewIdentityService(identityRepo, userRepo, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	require.NoError(t, service.UnlinkIdentity(ctx, 7, models.ProviderPassword))
+	err := service.UnlinkIdentity(ctx, 7, models.ProviderGitHub)
+	assert.True(t, errors.Is(err, &apperrors.LastIdentityErr), "got %v", err)
+
+	allowed, err := service.PasswordLogin(ctx, user)
+	require.NoError(t, err)
+	assert.False(t, allowed, "the password was unlinked")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIntrospectionService_Introspect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := NewMockUserServiceInterface(ctrl)
+	key := []byte("introspection-secret")
+	service := NewIntrospectionService(userService, nil, nil, auth.NewHMACKeys(key), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	sign := func(claims jwt.Claims, key []byte) string {
+		token, err := auth.Sign(claims, key)
+		require.NoError(t, err)
+		return token
+	}
+	claims := auth.NewClaims("alice@example.com", models.StrUser, 12, time.Hour)
+	claims.TenantID = tenancy.DefaultTenantID
+
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(&models.User{ID: 12}, nil)
+	active, err := service.Introspect(ctx, sign(jwt.MapClaims{
+		"email": claims.Email, "role": claims.Role, "user_id": claims.ID, "exp": claims.ExpiresAt, "tenant_id": claims.TenantID,
+		"team": "billing",
+	}, key))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", active["email"])
+	assert.Equal(t, "billing", active["team"], "claims beyond the ones the API reads are kept")
+
+	active, err = service.Introspect(tenancy.WithTenant(ctx, 2), sign(claims, key))
+	require.NoError(t, err)
+	assert.Nil(t, active, "the token was issued in another tenant")
+
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(nil, repositories.ErrNotFound)
+	active, err = service.Introspect(ctx, sign(claims, key))
+	require.NoError(t, err)
+	assert.Nil(t, active, "the user was deleted")
+
+	for name, token := range map[string]string{
+		"expired":        sign(auth.NewClaims("alice@example.com", models.StrUser, 12, -time.Minute), key),
+		"other key":      sign(claims, []byte("other-secret")),
+		"without a role": sign(auth.NewClaims("alice@example.com", "", 12, time.Hour), key),
+		"no tenant":      sign(auth.NewClaims("alice@example.com", models.StrUser, 12, time.Hour), key),
+		"not a JWT":      "opaque",
+	} {
+		active, err := service.Introspect(ctx, token)
+		assert.NoError(t, err, name)
+		assert.Nil(t, active, name)
+	}
+}
+
+func TestIntrospectionService_IntrospectOAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := NewMockUserServiceInterface(ctrl)
+	oauth := NewMockOAuthServiceInterface(ctrl)
+	key := []byte("introspection-secret")
+	service := NewIntrospectionService(userService, oauth, nil, auth.NewHMACKeys(key), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	sign := func(claims *auth.Claims) string {
+		token, err := auth.Sign(claims, key)
+		require.NoError(t, err)
+		return token
+	}
+	onBehalf := auth.NewClaims("alice@example.com", models.StrUser, 12, time.Hour)
+	onBehalf.ClientID, onBehalf.Scope, onBehalf.TenantID = "app", "profile", tenancy.DefaultTenantID
+	own := &auth.Claims{ClientID: "cron", Scope: "profile", TenantID: tenancy.DefaultTenantID, StandardClaims: jwt.StandardClaims{Subject: "cron", ExpiresAt: time.Now().Add(time.Hour).Unix()}}
+
+	oauth.EXPECT().Granted(gomock.Any(), "app", uint(12), "profile").Return(true, nil)
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(&models.User{ID: 12}, nil)
+	active, err := service.Introspect(ctx, sign(onBehalf))
+	require.NoError(t, err)
+	assert.Equal(t, "app", active["client_id"])
+
+	oauth.EXPECT().Granted(gomock.Any(), "app", uint(12), "profile").Return(false, nil)
+	active, err = service.Introspect(ctx, sign(onBehalf))
+	require.NoError(t, err)
+	assert.Nil(t, active, "the user revoked the consent")
+
+	oauth.EXPECT().Granted(gomock.Any(), "cron", uint(0), "profile").Return(true, nil)
+	active, err = service.Introspect(ctx, sign(own))
+	require.NoError(t, err)
+	assert.Equal(t, "cron", active["sub"], "a client credentials token names no user to look up")
+}
+
+func TestIntrospectionService_AuthenticateClient(t *testing.T) {
+	service := NewIntrospectionService(nil, nil, map[string]string{"billing": "s3cret", "empty": ""}, nil, zaptest.NewLogger(t).Sugar())
+
+	assert.True(t, service.AuthenticateClient("billing", "s3cret"))
+	assert.False(t, service.AuthenticateClient("billing", "S3cret"))
+	assert.False(t, service.AuthenticateClient("search", "s3cret"))
+	assert.False(t, service.AuthenticateClient("empty", ""), "a client without a secret never authenticates")
+}
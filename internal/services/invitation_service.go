@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type InvitationService struct {
+	invitationRepo repositories.InvitationRepoInterface
+	roleRepo       repositories.RoleRepoInterface
+	// organizationRepo adds the invitees of organizations as members when they accept
+	organizationRepo repositories.OrganizationRepoInterface
+	userService      UserServiceInterface
+	txManager        repositories.TxManagerInterface
+	mailer           mail.Mailer
+	templates        *mail.Templates
+	// acceptURL is the page the emails link to and ttl how long a sent token stays valid
+	acceptURL string
+	ttl       time.Duration
+	logger    *zap.SugaredLogger
+	now       func() time.Time
+}
+
+type InvitationServiceInterface interface {
+	// Invite records an invitation of email as role and mails its token. An email that belongs to a user or
+	// already has a pending invitation cannot be invited.
+	Invite(ctx context.Context, email, role string, invitedBy uint) (*models.Invitation, error)
+	// InviteToOrganization is Invite for a user who joins the organization with role in it on accepting; the
+	// account itself gets the user role
+	InviteToOrganization(ctx context.Context, email string, organizationID uint, role string, invitedBy uint) (*models.Invitation, error)
+	// ListPendingInvitations returns the invitations not accepted yet, newest first
+	ListPendingInvitations(ctx context.Context) ([]models.Invitation, error)
+	// ResendInvitation mails a new token, which replaces the previous one and is valid for another TTL
+	ResendInvitation(ctx context.Context, id uint) (*models.Invitation, error)
+	// RevokeInvitation deletes a pending invitation, so its token stops working
+	RevokeInvitation(ctx context.Context, id uint) error
+	// AcceptInvitation creates user with the email and role of the invitation token is for and returns its ID.
+	// user carries the rest of the profile and the password hash.
+	AcceptInvitation(ctx context.Context, token string, user *models.User) (uint, error)
+}
+
+func NewInvitationService(invitationRepo repositories.InvitationRepoInterface, roleRepo repositories.RoleRepoInterface, organizationRepo repositories.OrganizationRepoInterface, userService UserServiceInterface, txManager repositories.TxManagerInterface, mailer mail.Mailer, templates *mail.Templates, acceptURL string, ttl time.Duration, logger *zap.SugaredLogger) InvitationServiceInterface {
+	return &InvitationService{
+		invitationRepo:   invitationRepo,
+		roleRepo:         roleRepo,
+		organizationRepo: organizationRepo,
+		userService:      userService,
+		txManager:        txManager,
+		mailer:           mailer,
+		templates:        templates,
+		acceptURL:        acceptURL,
+		ttl:              ttl,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// invitationEmail is what the invitation template is filled with
+type invitationEmail struct {
+	Role string
+	// Organization is the name of the organization the invitee joins, if any
+	Organization string
+	URL          string
+	ExpiresAt    string
+}
+
+func (service *InvitationService) Invite(ctx context.Context, email, role string, invitedBy uint) (*models.Invitation, error) {
+	return service.invite(ctx, &models.Invitation{Email: email, InvitedBy: invitedBy}, role)
+}
+
+func (service *InvitationService) InviteToOrganization(ctx context.Context, email string, organizationID uint, role string, invitedBy uint) (*models.Invitation, error) {
+	return service.invite(ctx, &models.Invitation{
+		Email:            email,
+		InvitedBy:        invitedBy,
+		OrganizationID:   &organizationID,
+		OrganizationRole: role,
+	}, models.StrUser)
+}
+
+func (service *InvitationService) invite(ctx context.Context, invitation *models.Invitation, role string) (*models.Invitation, error) {
+	email := invitation.Email
+	_, err := service.userService.GetUserByEmail(ctx, email)
+	if err == nil {
+		return nil, repositories.ErrDuplicateEmail.AppendMessage(email)
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	_, err = service.invitationRepo.GetPendingInvitationByEmail(ctx, email)
+	if err == nil {
+		return nil, apperrors.InvitationPendingErr.AppendMessage(email)
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+
+	found, err := service.roleRepo.GetRoleByName(ctx, role)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperrors.BadRequestErr.AppendMessage("unknown role " + role)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation.Role = *found
+	invitation.RoleID = found.ID
+	invitation.TokenHash = tokenHash
+	invitation.SentAt = service.now()
+	invitation.ExpiresAt = invitation.SentAt.Add(service.ttl)
+
+	// Mail is queued in the same transaction, so an invitation is only kept once its email is on its way
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.invitationRepo.CreateInvitation(ctx, invitation); err != nil {
+			return err
+		}
+		return service.send(ctx, invitation, token)
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (service *InvitationService) ListPendingInvitations(ctx context.Context) ([]models.Invitation, error) {
+	return service.invitationRepo.ListPendingInvitations(ctx)
+}
+
+func (service *InvitationService) ResendInvitation(ctx context.Context, id uint) (*models.Invitation, error) {
+	var invitation *models.Invitation
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		invitation, err = service.invitationRepo.GetPendingInvitation(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		token, tokenHash, err := newInvitationToken()
+		if err != nil {
+			return err
+		}
+		invitation.TokenHash = tokenHash
+		invitation.SentAt = service.now()
+		invitation.ExpiresAt = invitation.SentAt.Add(service.ttl)
+		err = service.invitationRepo.RenewInvitation(ctx, id, tokenHash, invitation.SentAt, invitation.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		return service.send(ctx, invitation, token)
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (service *InvitationService) RevokeInvitation(ctx context.Context, id uint) error {
+	return service.invitationRepo.DeletePendingInvitation(ctx, id)
+}
+
+func (service *InvitationService) AcceptInvitation(ctx context.Context, token string, user *models.User) (uint, error) {
+	var userID uint
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The lock on the invitation keeps a token from being accepted twice at once
+		invitation, err := service.invitationRepo.GetPendingInvitationByToken(ctx, hashInvitationToken(token))
+		if errors.Is(err, repositories.ErrNotFound) {
+			return &apperrors.InvitationInvalidErr
+		}
+		if err != nil {
+			return err
+		}
+		now := service.now()
+		if invitation.Expired(now) {
+			return apperrors.InvitationInvalidErr.AppendMessage("expired at " + invitation.ExpiresAt.Format(time.RFC3339))
+		}
+
+		user.Email = invitation.Email
+		user.RoleID = invitation.RoleID
+		userID, err = service.userService.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		if invitation.OrganizationID != nil {
+			err = service.organizationRepo.AddMember(ctx, &models.Membership{
+				OrganizationID: *invitation.OrganizationID,
+				UserID:         userID,
+				Role:           invitation.OrganizationRole,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return service.invitationRepo.AcceptInvitation(ctx, invitation.ID, now)
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return 0, err
+	}
+	return userID, nil
+}
+
+// send mails the invitation with token in the default language, as the invitee has no locale yet
+func (service *InvitationService) send(ctx context.Context, invitation *models.Invitation, token string) error {
+	link, err := url.Parse(service.acceptURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	lang := i18n.Fallback()
+	email := &invitationEmail{
+		Role:      invitation.Role.Name,
+		URL:       link.String(),
+		ExpiresAt: i18n.FormatTime(invitation.ExpiresAt, time.UTC, lang),
+	}
+	if invitation.OrganizationID != nil {
+		organization, err := service.organizationRepo.GetOrganization(ctx, *invitation.OrganizationID)
+		if err != nil {
+			return err
+		}
+		email.Role, email.Organization = invitation.OrganizationRole, organization.Name
+	}
+	msg, err := service.templates.Render(mail.TemplateInvitation, lang, email, invitation.Email)
+	if err != nil {
+		return err
+	}
+	return service.mailer.Send(ctx, msg)
+}
+
+// newInvitationToken returns a random token for the email and the hash to store instead
+func newInvitationToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashInvitationToken(token), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
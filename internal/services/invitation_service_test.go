@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestInvitationService(t *testing.T, ctrl *gomock.Controller, invitationRepo repositories.InvitationRepoInterface, organizationRepo repositories.OrganizationRepoInterface, userService UserServiceInterface, sent *sentMail) *InvitationService {
+	templates, err := mail.LoadTemplates("")
+	require.NoError(t, err)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	roleRepo.EXPECT().GetRoleByName(gomock.Any(), models.StrModerator).Return(&models.Role{ID: 2, Name: models.StrModerator}, nil).AnyTimes()
+
+	service := NewInvitationService(invitationRepo, roleRepo, organizationRepo, userService, newInlineTxManager(ctrl), sent, templates, "https://app.example.com/invitations/accept?lang=en", 72*time.Hour, zaptest.NewLogger(t).Sugar()).(*InvitationService)
+	service.now = func() time.Time { return time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC) }
+	return service
+}
+
+// mailedToken is the token in the link of an invitation email
+func mailedToken(t *testing.T, msg *mail.Message) string {
+	link := regexp.MustCompile(`https://\S+`).FindString(msg.Text)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "en", parsed.Query().Get("lang"), "the query of INVITATION_URL is kept")
+	return parsed.Query().Get("token")
+}
+
+func TestInvitationService_InviteMailsTheToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	invitationRepo := mocks.NewMockInvitationRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	var sent sentMail
+	service := newTestInvitationService(t, ctrl, invitationRepo, nil, userService, &sent)
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "ann@example.com").Return(nil, repositories.ErrNotFound)
+	invitationRepo.EXPECT().GetPendingInvitationByEmail(gomock.Any(), "ann@example.com").Return(nil, repositories.ErrNotFound)
+	var stored *models.Invitation
+	invitationRepo.EXPECT().CreateInvitation(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, invitation *models.Invitation) error {
+		stored = invitation
+		return nil
+	})
+
+	invitation, err := service.Invite(context.Background(), "ann@example.com", models.StrModerator, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), invitation.RoleID)
+	assert.Equal(t, uint(3), invitation.InvitedBy)
+	assert.Equal(t, time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC), invitation.ExpiresAt)
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"ann@example.com"}, sent[0].To)
+	assert.Contains(t, sent[0].Text, "moderator")
+	token := mailedToken(t, sent[0])
+	assert.NotEmpty(t, token)
+	assert.Equal(t, hashInvitationToken(token), stored.TokenHash, "only the hash of the token is stored")
+}
+
+func TestInvitationService_InviteRefusesKnownEmails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	invitationRepo := mocks.NewMockInvitationRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := newTestInvitationService(t, ctrl, invitationRepo, nil, userService, &sentMail{})
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "user@example.com").Return(&models.User{ID: 1}, nil)
+	_, err := service.Invite(ctx, "user@example.com", models.StrModerator, 3)
+	assert.True(t, errors.Is(err, repositories.ErrDuplicateEmail), "got %v", err)
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "ann@example.com").Return(nil, repositories.ErrNotFound)
+	invitationRepo.EXPECT().GetPendingInvitationByEmail(gomock.Any(), "ann@example.com").Return(&models.Invitation{ID: 1}, nil)
+	_, err = service.Invite(ctx, "ann@example.com", models.StrModerator, 3)
+	assert.True(t, errors.Is(err, &apperrors.InvitationPendingErr), "got %v", err)
+}
+
+func TestInvitationService_ResendReplacesTheToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	invitationRepo := mocks.NewMockInvitationRepoInterface(ctrl)
+	var sent sentMail
+	service := newTestInvitationService(t, ctrl, invitationRepo, nil, NewMockUserServiceInterface(ctrl), &sent)
+
+	pending := &models.Invitation{ID: 4, Email: "ann@example.com", Role: models.Role{ID: 2, Name: models.StrModerator}, TokenHash: "old"}
+	invitationRepo.EXPECT().GetPendingInvitation(gomock.Any(), uint(4)).Return(pending, nil)
+	var renewedHash string
+	invitationRepo.EXPECT().RenewInvitation(gomock.Any(), uint(4), gomock.Any(), gomock.Any(), time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC)).
+		DoAndReturn(func(ctx context.Context, id uint, tokenHash string, sentAt, expiresAt time.Time) error {
+			renewedHash = tokenHash
+			return nil
+		})
+
+	_, err := service.ResendInvitation(context.Background(), 4)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.NotEqual(t, "old", renewedHash)
+	assert.Equal(t, hashInvitationToken(mailedToken(t, sent[0])), renewedHash)
+}
+
+func TestInvitationService_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	invitationRepo := mocks.NewMockInvitationRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := newTestInvitationService(t, ctrl, invitationRepo, nil, userService, &sentMail{})
+	ctx := context.Background()
+	now := service.now()
+
+	invitationRepo.EXPECT().GetPendingInvitationByToken(gomock.Any(), hashInvitationToken("unknown")).Return(nil, repositories.ErrNotFound)
+	_, err := service.AcceptInvitation(ctx, "unknown", &models.User{})
+	assert.True(t, errors.Is(err, &apperrors.InvitationInvalidErr), "got %v", err)
+
+	invitationRepo.EXPECT().GetPendingInvitationByToken(gomock.Any(), hashInvitationToken("expired")).Return(&models.Invitation{ID: 5, ExpiresAt: now}, nil)
+	_, err = service.AcceptInvitation(ctx, "expired", &models.User{})
+	assert.True(t, errors.Is(err, &apperrors.InvitationInvalidErr), "got %v", err)
+
+	invitationRepo.EXPECT().GetPendingInvitationByToken(gomock.Any(), hashInvitationToken("valid")).
+		Return(&models.Invitation{ID: 6, Email: "ann@example.com", RoleID: 2, ExpiresAt: now.Add(time.Hour)}, nil)
+	userService.EXPECT().CreateUser(gomock.Any(), &models.User{Email: "ann@example.com", RoleID: 2, FirstName: "Ann", Password: "hash"}).Return(uint(9), nil)
+	invitationRepo.EXPECT().AcceptInvitation(gomock.Any(), uint(6), now).Return(nil)
+
+	userID, err := service.AcceptInvitation(ctx, "valid", &models.User{FirstName: "Ann", Password: "hash"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(9), userID)
+}
+
+func TestInvitationService_AcceptJoinsTheOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	invitationRepo := mocks.NewMockInvitationRepoInterface(ctrl)
+	organizationRepo := mocks.NewMockOrganizationRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := newTestInvitationService(t, ctrl, invitationRepo, organizationRepo, userService, &sentMail{})
+	now := service.now()
+	organizationID := uint(7)
+
+	invitationRepo.EXPECT().GetPendingInvitationByToken(gomock.Any(), hashInvitationToken("valid")).Return(&models.Invitation{
+		ID: 6, Email: "ann@example.com", RoleID: 1, ExpiresAt: now.Add(time.Hour),
+		OrganizationID: &organizationID, OrganizationRole: models.StrModerator,
+	}, nil)
+	userService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(uint(9), nil)
+	organizationRepo.EXPECT().AddMember(gomock.Any(), &models.Membership{OrganizationID: 7, UserID: 9, Role: models.StrModerator}).Return(nil)
+	invitationRepo.EXPECT().AcceptInvitation(gomock.Any(), uint(6), now).Return(nil)
+
+	_, err := service.AcceptInvitation(context.Background(), "valid", &models.User{FirstName: "Ann", Password: "hash"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/geoip"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type LoginAlertService struct {
+	alertRepo      repositories.LoginAlertRepoInterface
+	auditRepo      repositories.AdminAuditRepoInterface
+	rememberTokens repositories.RememberTokenRepoInterface
+	securityEvents SecurityEventServiceInterface
+	txManager      repositories.TxManagerInterface
+	// geo locates the country of a login, or nothing when it is nil and devices are told apart by user agent only
+	geo       *geoip.Database
+	mailer    mail.Mailer
+	templates *mail.Templates
+	// denyURL is the "this wasn't me" page the alerts link to and ttl how long its link stays valid
+	denyURL string
+	ttl     time.Duration
+	logger  *zap.SugaredLogger
+	now     func() time.Time
+}
+
+type LoginAlertServiceInterface interface {
+	// CheckLogin records the device and country of the client in ctx as known for user. A login from a device or
+	// country the user has not signed in from before mails them an alert, unless it is their first one.
+	CheckLogin(ctx context.Context, user *models.User) error
+	// Deny locks the account the alert with token was mailed for, voids the other alerts of the user and forgets
+	// their remembered devices
+	Deny(ctx context.Context, token string) error
+	// Locked reports whether the user is locked out of signing in with their password
+	Locked(ctx context.Context, userID uint) (bool, error)
+	// Unlock removes the lock of the user and records it in the admin audit
+	Unlock(ctx context.Context, by *models.AdminAction, userID uint) error
+}
+
+func NewLoginAlertService(alertRepo repositories.LoginAlertRepoInterface, auditRepo repositories.AdminAuditRepoInterface, rememberTokens repositories.RememberTokenRepoInterface, securityEvents SecurityEventServiceInterface, txManager repositories.TxManagerInterface, geo *geoip.Database, mailer mail.Mailer, templates *mail.Templates, denyURL string, ttl time.Duration, logger *zap.SugaredLogger) LoginAlertServiceInterface {
+	return &LoginAlertService{
+		alertRepo:      alertRepo,
+		auditRepo:      auditRepo,
+		rememberTokens: rememberTokens,
+		securityEvents: securityEvents,
+		txManager:      txManager,
+		geo:            geo,
+		mailer:         mailer,
+		templates:      templates,
+		denyURL:        denyURL,
+		ttl:            ttl,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// loginAlertEmail is what the login alert template is filled with
+type loginAlertEmail struct {
+	FirstName string
+	Time      string
+	Device    string
+	IP        string
+	Country   string
+	URL       string
+	ExpiresAt string
+}
+
+func (service *LoginAlertService) CheckLogin(ctx context.Context, user *models.User) error {
+	client, ok := ctx.Value(models.ClientContextKey).(*models.Client)
+	if !ok {
+		return nil
+	}
+	known, err := service.alertRepo.CountDevices(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	// The user agent is kept as a blind index, the device only has to be recognised
+	now := service.now()
+	device := &models.KnownDevice{
+		UserID:      user.ID,
+		Fingerprint: pii.BlindIndex(client.UserAgent),
+		Country:     service.geo.Country(client.IP),
+		LastSeenAt:  now,
+	}
+	created, err := service.alertRepo.SaveDevice(ctx, device)
+	if err != nil || !created || known == 0 {
+		return err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	alert := &models.LoginAlert{UserID: user.ID, TokenHash: hashToken(token), ExpiresAt: now.Add(service.ttl)}
+	// Mail is queued in the same transaction, so only an alert the user is told about can lock the account
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.alertRepo.CreateAlert(ctx, alert); err != nil {
+			return err
+		}
+		return service.send(ctx, user, client, device, alert, token)
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return err
+	}
+	service.securityEvents.Record(ctx, user.ID, models.SecurityEventNewDevice, device.Country)
+	return nil
+}
+
+func (service *LoginAlertService) Deny(ctx context.Context, token string) error {
+	alert, err := service.alertRepo.GetAlertByHash(ctx, hashToken(token))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return &apperrors.LoginAlertInvalidErr
+	}
+	if err != nil {
+		return err
+	}
+	now := service.now()
+	if !now.Before(alert.ExpiresAt) {
+		return apperrors.LoginAlertInvalidErr.AppendMessage("expired at " + alert.ExpiresAt.Format(time.RFC3339))
+	}
+
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := service.alertRepo.CreateLock(ctx, &models.AccountLock{
+			UserID:   alert.UserID,
+			Reason:   models.LockReasonLoginDenied,
+			LockedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		if err := service.rememberTokens.DeleteUserTokens(ctx, alert.UserID); err != nil {
+			return err
+		}
+		return service.alertRepo.DeleteAlerts(ctx, alert.UserID)
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return err
+	}
+	service.securityEvents.Record(ctx, alert.UserID, models.SecurityEventAccountLocked, models.LockReasonLoginDenied)
+	return nil
+}
+
+func (service *LoginAlertService) Locked(ctx context.Context, userID uint) (bool, error) {
+	_, err := service.alertRepo.GetLock(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (service *LoginAlertService) Unlock(ctx context.Context, by *models.AdminAction, userID uint) error {
+	var reason string
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		lock, err := service.alertRepo.GetLock(ctx, userID)
+		if err != nil {
+			return err
+		}
+		reason = lock.Reason
+		if err := service.alertRepo.DeleteLock(ctx, userID); err != nil {
+			return err
+		}
+		return service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+			ActorID:  by.ActorID,
+			Action:   models.AdminAuditUserUnlocked,
+			TargetID: userID,
+			Reason:   by.Reason,
+			Details:  lock.Reason,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	service.securityEvents.Record(ctx, userID, models.SecurityEventAccountUnlocked, reason)
+	return nil
+}
+
+// send mails the alert with token about the login of user from device to them, in their language and time zone
+func (service *LoginAlertService) send(ctx context.Context, user *models.User, client *models.Client, device *models.KnownDevice, alert *models.LoginAlert, token string) error {
+	link, err := url.Parse(service.denyURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	lang := user.Language(i18n.Fallback())
+	email := &loginAlertEmail{
+		FirstName: user.FirstName,
+		Time:      i18n.FormatTime(device.LastSeenAt, user.Location(), lang),
+		Device:    client.UserAgent,
+		IP:        client.IP,
+		Country:   device.Country,
+		URL:       link.String(),
+		ExpiresAt: i18n.FormatTime(alert.ExpiresAt, user.Location(), lang),
+	}
+	msg, err := service.templates.Render(mail.TemplateLoginAlert, lang, email, user.Email)
+	if err != nil {
+		return err
+	}
+	return service.mailer.Send(ctx, msg)
+}
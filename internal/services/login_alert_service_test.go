@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/geoip"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestLoginAlertService(t *testing.T, ctrl *gomock.Controller, alertRepo repositories.LoginAlertRepoInterface, auditRepo repositories.AdminAuditRepoInterface, securityEvents SecurityEventServiceInterface, sent *sentMail) *LoginAlertService {
+	templates, err := mail.LoadTemplates("")
+	require.NoError(t, err)
+	geo, err := geoip.Load(strings.NewReader("203.0.113.0/24,UA\n198.51.100.0/24,PL\n"))
+	require.NoError(t, err)
+
+	service := NewLoginAlertService(alertRepo, auditRepo, mocks.NewMockRememberTokenRepoInterface(ctrl), securityEvents, newInlineTxManager(ctrl), geo, sent, templates, "https://app.example.com/login-alerts/deny", 168*time.Hour, zaptest.NewLogger(t).Sugar()).(*LoginAlertService)
+	service.now = func() time.Time { return time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func clientContext(ip, userAgent string) context.Context {
+	return context.WithValue(context.Background(), models.ClientContextKey, &models.Client{IP: ip, UserAgent: userAgent})
+}
+
+func TestLoginAlertService_CheckLoginAlertsOnANewDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	alertRepo := mocks.NewMockLoginAlertRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	var sent sentMail
+	service := newTestLoginAlertService(t, ctrl, alertRepo, nil, securityEvents, &sent)
+	user := &models.User{ID: 1, Email: "ann@example.com", FirstName: "Ann", Locale: "uk", Timezone: "Europe/Kyiv"}
+	ctx := clientContext("198.51.100.4", "curl/8.0")
+
+	var alert *models.LoginAlert
+	alertRepo.EXPECT().CountDevices(ctx, uint(1)).Return(int64(1), nil)
+	alertRepo.EXPECT().SaveDevice(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, device *models.KnownDevice) (bool, error) {
+		assert.Equal(t, "PL", device.Country)
+		assert.NotEqual(t, "curl/8.0", device.Fingerprint, "the user agent is not kept")
+		return true, nil
+	})
+	alertRepo.EXPECT().CreateAlert(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, created *models.LoginAlert) error {
+		alert = created
+		return nil
+	})
+	securityEvents.EXPECT().Record(ctx, uint(1), models.SecurityEventNewDevice, "PL")
+
+	require.NoError(t, service.CheckLogin(ctx, user))
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"ann@example.com"}, sent[0].To)
+	assert.Contains(t, sent[0].Text, "curl/8.0")
+	assert.Contains(t, sent[0].Text, "01.05.2024 15:00", "in the user's language and time zone")
+	link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(sent[0].Text))
+	require.NoError(t, err)
+	assert.Equal(t, "/login-alerts/deny", link.Path)
+	assert.Equal(t, hashToken(link.Query().Get("token")), alert.TokenHash)
+	assert.Equal(t, time.Date(2024, time.May, 8, 12, 0, 0, 0, time.UTC), alert.ExpiresAt)
+}
+
+func TestLoginAlertService_CheckLoginStaysQuiet(t *testing.T) {
+	for name, tc := range map[string]struct {
+		known   int64
+		created bool
+	}{
+		"the first device": {known: 0, created: true},
+		"a known device":   {known: 2, created: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			alertRepo := mocks.NewMockLoginAlertRepoInterface(ctrl)
+			var sent sentMail
+			service := newTestLoginAlertService(t, ctrl, alertRepo, nil, nil, &sent)
+			ctx := clientContext("203.0.113.7", "curl/8.0")
+
+			alertRepo.EXPECT().CountDevices(ctx, uint(1)).Return(tc.known, nil)
+			alertRepo.EXPECT().SaveDevice(ctx, gomock.Any()).Return(tc.created, nil)
+
+			require.NoError(t, service.CheckLogin(ctx, &models.User{ID: 1}))
+			assert.Empty(t, sent)
+		})
+	}
+}
+
+func TestLoginAlertService_DenyLocksTheAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	alertRepo := mocks.NewMockLoginAlertRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	rememberTokens := mocks.NewMockRememberTokenRepoInterface(ctrl)
+	service := newTestLoginAlertService(t, ctrl, alertRepo, nil, securityEvents, nil)
+	service.rememberTokens = rememberTokens
+	ctx := context.Background()
+
+	alertRepo.EXPECT().GetAlertByHash(ctx, hashToken("valid")).Return(&models.LoginAlert{UserID: 1, ExpiresAt: service.now().Add(time.Hour)}, nil)
+	alertRepo.EXPECT().CreateLock(ctx, &models.AccountLock{UserID: 1, Reason: models.LockReasonLoginDenied, LockedAt: service.now()})
+	rememberTokens.EXPECT().DeleteUserTokens(ctx, uint(1))
+	alertRepo.EXPECT().DeleteAlerts(ctx, uint(1))
+	securityEvents.EXPECT().Record(ctx, uint(1), models.SecurityEventAccountLocked, models.LockReasonLoginDenied)
+	require.NoError(t, service.Deny(ctx, "valid"))
+
+	alertRepo.EXPECT().GetAlertByHash(ctx, hashToken("expired")).Return(&models.LoginAlert{UserID: 1, ExpiresAt: service.now()}, nil)
+	assert.True(t, apperrors.Is(service.Deny(ctx, "expired"), &apperrors.LoginAlertInvalidErr))
+
+	alertRepo.EXPECT().GetAlertByHash(ctx, hashToken("unknown")).Return(nil, repositories.ErrNotFound)
+	assert.True(t, apperrors.Is(service.Deny(ctx, "unknown"), &apperrors.LoginAlertInvalidErr))
+}
+
+func TestLoginAlertService_UnlockIsAudited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	alertRepo := mocks.NewMockLoginAlertRepoInterface(ctrl)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	service := newTestLoginAlertService(t, ctrl, alertRepo, auditRepo, securityEvents, nil)
+	ctx := context.Background()
+
+	alertRepo.EXPECT().GetLock(ctx, uint(1)).Return(&models.AccountLock{UserID: 1, Reason: models.LockReasonLoginDenied}, nil)
+	alertRepo.EXPECT().DeleteLock(ctx, uint(1))
+	auditRepo.EXPECT().CreateAdminAudit(ctx, &models.AdminAudit{
+		ActorID:  3,
+		Action:   models.AdminAuditUserUnlocked,
+		TargetID: 1,
+		Reason:   "confirmed by phone",
+		Details:  models.LockReasonLoginDenied,
+	})
+	securityEvents.EXPECT().Record(ctx, uint(1), models.SecurityEventAccountUnlocked, models.LockReasonLoginDenied)
+	require.NoError(t, service.Unlock(ctx, &models.AdminAction{ActorID: 3, Reason: "confirmed by phone"}, 1))
+
+	alertRepo.EXPECT().GetLock(ctx, uint(2)).Return(nil, repositories.ErrNotFound)
+	assert.ErrorIs(t, service.Unlock(ctx, &models.AdminAction{ActorID: 3}, 2), repositories.ErrNotFound)
+}
@@ -7,8 +7,10 @@ package services
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	filter "gitlab.com/jkozhemiaka/web-layout/internal/filter"
 	models "gitlab.com/jkozhemiaka/web-layout/internal/models"
 )
 
@@ -36,18 +38,34 @@ func (m *MockUserServiceInterface) EXPECT() *MockUserServiceInterfaceMockRecorde
 }
 
 // CountUsers mocks base method.
-func (m *MockUserServiceInterface) CountUsers(ctx context.Context) (int, error) {
+func (m *MockUserServiceInterface) CountUsers(ctx context.Context, filters []filter.Condition) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CountUsers", ctx)
+	ret := m.ctrl.Call(m, "CountUsers", ctx, filters)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CountUsers indicates an expected call of CountUsers.
-func (mr *MockUserServiceInterfaceMockRecorder) CountUsers(ctx interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) CountUsers(ctx, filters interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockUserServiceInterface)(nil).CountUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockUserServiceInterface)(nil).CountUsers), ctx, filters)
+}
+
+// CreateOrUpdateByEmail mocks base method.
+func (m *MockUserServiceInterface) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateByEmail", ctx, user)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateOrUpdateByEmail indicates an expected call of CreateOrUpdateByEmail.
+func (mr *MockUserServiceInterfaceMockRecorder) CreateOrUpdateByEmail(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateByEmail", reflect.TypeOf((*MockUserServiceInterface)(nil).CreateOrUpdateByEmail), ctx, user)
 }
 
 // CreateUser mocks base method.
@@ -110,19 +128,49 @@ func (mr *MockUserServiceInterfaceMockRecorder) GetUserByEmail(ctx, email interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserServiceInterface)(nil).GetUserByEmail), ctx, email)
 }
 
+// GetUserHistory mocks base method.
+func (m *MockUserServiceInterface) GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHistory", ctx, userID)
+	ret0, _ := ret[0].([]models.UserHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHistory indicates an expected call of GetUserHistory.
+func (mr *MockUserServiceInterfaceMockRecorder) GetUserHistory(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHistory", reflect.TypeOf((*MockUserServiceInterface)(nil).GetUserHistory), ctx, userID)
+}
+
+// ListUserChanges mocks base method.
+func (m *MockUserServiceInterface) ListUserChanges(ctx context.Context, since time.Time, afterID uint, limit int) (*models.UserChangesPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserChanges", ctx, since, afterID, limit)
+	ret0, _ := ret[0].(*models.UserChangesPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserChanges indicates an expected call of ListUserChanges.
+func (mr *MockUserServiceInterfaceMockRecorder) ListUserChanges(ctx, since, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserChanges", reflect.TypeOf((*MockUserServiceInterface)(nil).ListUserChanges), ctx, since, afterID, limit)
+}
+
 // ListUsers mocks base method.
-func (m *MockUserServiceInterface) ListUsers(ctx context.Context, page, pageSize int) ([]models.User, error) {
+func (m *MockUserServiceInterface) ListUsers(ctx context.Context, page, pageSize int, filters []filter.Condition) (*models.UserPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", ctx, page, pageSize)
-	ret0, _ := ret[0].([]models.User)
+	ret := m.ctrl.Call(m, "ListUsers", ctx, page, pageSize, filters)
+	ret0, _ := ret[0].(*models.UserPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListUsers indicates an expected call of ListUsers.
-func (mr *MockUserServiceInterfaceMockRecorder) ListUsers(ctx, page, pageSize interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) ListUsers(ctx, page, pageSize, filters interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserServiceInterface)(nil).ListUsers), ctx, page, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserServiceInterface)(nil).ListUsers), ctx, page, pageSize, filters)
 }
 
 // RevokeVote mocks base method.
@@ -139,6 +187,18 @@ func (mr *MockUserServiceInterfaceMockRecorder) RevokeVote(ctx, userID, profileI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeVote", reflect.TypeOf((*MockUserServiceInterface)(nil).RevokeVote), ctx, userID, profileID)
 }
 
+// SetVoteCooldown mocks base method.
+func (m *MockUserServiceInterface) SetVoteCooldown(cooldown time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetVoteCooldown", cooldown)
+}
+
+// SetVoteCooldown indicates an expected call of SetVoteCooldown.
+func (mr *MockUserServiceInterfaceMockRecorder) SetVoteCooldown(cooldown interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVoteCooldown", reflect.TypeOf((*MockUserServiceInterface)(nil).SetVoteCooldown), cooldown)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserServiceInterface) UpdateUser(ctx context.Context, userID string, user *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/sms"
+	"go.uber.org/zap"
+	"golang.org/x/text/language"
+)
+
+// NotificationChannel delivers a notification outside the API, e.g. by email
+type NotificationChannel interface {
+	Deliver(ctx context.Context, user *models.User, notification *models.Notification) error
+}
+
+// followerBatchSize is how many followers are loaded at a time to be told about a profile update
+const followerBatchSize = 500
+
+type NotificationService struct {
+	notificationRepo repositories.NotificationRepoInterface
+	userRepo         repositories.UserRepoInterface
+	followRepo       repositories.FollowRepoInterface
+	shadowBanRepo    repositories.ShadowBanRepoInterface
+	channels         map[string]NotificationChannel
+	logger           *zap.SugaredLogger
+}
+
+type NotificationServiceInterface interface {
+	// Publish turns domain events into notifications, so the service can subscribe to the event stream
+	Publish(ctx context.Context, event *events.Event) error
+	// Notify stores the notification for the in-app channel and delivers it on every other channel the user has on
+	Notify(ctx context.Context, notification *models.Notification) error
+	ListNotifications(ctx context.Context, userID uint, unreadOnly bool, page int, pageSize int) (*models.NotificationPage, error)
+	MarkRead(ctx context.Context, userID uint, ids []uint64) (int, error)
+	// Preferences returns the state of every channel for the user, defaults included
+	Preferences(ctx context.Context, userID uint) (map[string]bool, error)
+	SetPreferences(ctx context.Context, userID uint, preferences map[string]bool) (map[string]bool, error)
+}
+
+// NewNotificationService delivers on the given channels, keyed by models.Channel*; the in-app channel is the repository
+func NewNotificationService(notificationRepo repositories.NotificationRepoInterface, userRepo repositories.UserRepoInterface, followRepo repositories.FollowRepoInterface, shadowBanRepo repositories.ShadowBanRepoInterface, channels map[string]NotificationChannel, logger *zap.SugaredLogger) NotificationServiceInterface {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		followRepo:       followRepo,
+		shadowBanRepo:    shadowBanRepo,
+		channels:         channels,
+		logger:           logger,
+	}
+}
+
+func (service *NotificationService) Publish(ctx context.Context, event *events.Event) error {
+	var data struct {
+		UserID    uint `json:"user_id"`
+		ProfileID uint `json:"profile_id"`
+		Value     int  `json:"value"`
+	}
+	if len(event.Data) > 0 {
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+	}
+
+	// What a shadow-banned user does is not told to anyone else; they are still told about their own profile
+	hidden := false
+	switch event.Type {
+	case events.UserUpdated, events.UserFollowed, events.VoteCast:
+		var err error
+		if hidden, err = service.shadowBanRepo.IsShadowBanned(ctx, data.UserID); err != nil {
+			return err
+		}
+	}
+
+	// The recipient's language is unknown, so notifications are in the default one
+	lang := i18n.Fallback()
+	switch event.Type {
+	case events.UserCreated:
+		return service.Notify(ctx, &models.Notification{
+			UserID: data.UserID,
+			Type:   models.NotificationWelcome,
+			Title:  i18n.Message(lang, "notification.welcome.title"),
+			Body:   i18n.Message(lang, "notification.welcome.body"),
+		})
+	case events.UserUpdated:
+		err := service.Notify(ctx, &models.Notification{
+			UserID: data.UserID,
+			Type:   models.NotificationProfileUpdated,
+			Title:  i18n.Message(lang, "notification.profile_updated.title"),
+			Body:   i18n.Message(lang, "notification.profile_updated.body"),
+		})
+		if err != nil || hidden {
+			return err
+		}
+		return service.notifyFollowers(ctx, data.UserID, models.Notification{
+			Type:  models.NotificationFollowedProfileUpdated,
+			Title: i18n.Message(lang, "notification.followed_profile_updated.title"),
+			Body:  i18n.Message(lang, "notification.followed_profile_updated.body", data.UserID),
+		})
+	case events.UserFollowed:
+		if hidden {
+			return nil
+		}
+		return service.Notify(ctx, &models.Notification{
+			UserID: data.ProfileID,
+			Type:   models.NotificationNewFollower,
+			Title:  i18n.Message(lang, "notification.follower.title"),
+			Body:   i18n.Message(lang, "notification.follower.body", data.UserID),
+		})
+	case events.VoteCast:
+		if hidden {
+			return nil
+		}
+		vote := "like"
+		if data.Value < 0 {
+			vote = "dislike"
+		}
+		title, body := i18n.Message(lang, "notification."+vote+".title"), i18n.Message(lang, "notification."+vote+".body")
+		return service.Notify(ctx, &models.Notification{
+			UserID: data.ProfileID,
+			Type:   models.NotificationVoteReceived,
+			Title:  title,
+			Body:   body,
+		})
+	}
+	return nil
+}
+
+func (service *NotificationService) Notify(ctx context.Context, notification *models.Notification) error {
+	preferences, err := service.Preferences(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	if preferences[models.ChannelInApp] {
+		err = service.notificationRepo.CreateNotification(ctx, notification)
+		if err != nil {
+			return err
+		}
+	}
+
+	var user *models.User
+	for _, name := range sortedChannels(service.channels) {
+		if !preferences[name] {
+			continue
+		}
+		if user == nil {
+			user, err = service.userRepo.GetUserByID(ctx, notification.UserID)
+			if err != nil {
+				return err
+			}
+		}
+		// One failing channel must not keep the others from delivering
+		err = service.channels[name].Deliver(ctx, user, notification)
+		if err != nil {
+			service.logger.Errorw("Notification delivery failed", "channel", name, "user_id", notification.UserID, "type", notification.Type, "error", err)
+		}
+	}
+	return nil
+}
+
+// notifyFollowers sends notification to every follower of userID, a batch of followers at a time. A follower who
+// cannot be notified is logged and skipped, so the others still are.
+func (service *NotificationService) notifyFollowers(ctx context.Context, userID uint, notification models.Notification) error {
+	var afterID uint
+	for {
+		followerIDs, err := service.followRepo.ListFollowerIDs(ctx, userID, afterID, followerBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, followerID := range followerIDs {
+			copied := notification
+			copied.UserID = followerID
+			err = service.Notify(ctx, &copied)
+			if err != nil {
+				service.logger.Errorw("Failed to notify a follower", "user_id", followerID, "type", notification.Type, "error", err)
+			}
+		}
+		if len(followerIDs) < followerBatchSize {
+			return nil
+		}
+		afterID = followerIDs[len(followerIDs)-1]
+	}
+}
+
+func sortedChannels(channels map[string]NotificationChannel) []string {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (service *NotificationService) ListNotifications(ctx context.Context, userID uint, unreadOnly bool, page int, pageSize int) (*models.NotificationPage, error) {
+	notifications, total, err := service.notificationRepo.ListNotifications(ctx, userID, unreadOnly, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := service.notificationRepo.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+	return &models.NotificationPage{
+		Data:       notifications,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		Unread:     unread,
+	}, nil
+}
+
+func (service *NotificationService) MarkRead(ctx context.Context, userID uint, ids []uint64) (int, error) {
+	return service.notificationRepo.MarkNotificationsRead(ctx, userID, ids)
+}
+
+func (service *NotificationService) Preferences(ctx context.Context, userID uint) (map[string]bool, error) {
+	stored, err := service.notificationRepo.ListNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]bool, len(models.DefaultNotificationChannels))
+	for channel, enabled := range models.DefaultNotificationChannels {
+		preferences[channel] = enabled
+	}
+	for _, preference := range stored {
+		preferences[preference.Channel] = preference.Enabled
+	}
+	return preferences, nil
+}
+
+func (service *NotificationService) SetPreferences(ctx context.Context, userID uint, preferences map[string]bool) (map[string]bool, error) {
+	var violations []apperrors.FieldViolation
+	for channel := range preferences {
+		if _, known := models.DefaultNotificationChannels[channel]; !known {
+			violations = append(violations, apperrors.FieldViolation{
+				Field:     channel,
+				Rule:      "oneof",
+				Message:   i18n.Message(language.English, "validation.notification_channel"),
+				MessageID: "validation.notification_channel",
+			})
+		}
+	}
+	if len(violations) > 0 {
+		sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+		return nil, apperrors.ValidationFailedErr.WithFields(violations...)
+	}
+
+	for channel, enabled := range preferences {
+		err := service.notificationRepo.SetNotificationPreference(ctx, &models.NotificationPreference{UserID: userID, Channel: channel, Enabled: enabled})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return service.Preferences(ctx, userID)
+}
+
+// EmailChannel mails notifications with the notification template
+type EmailChannel struct {
+	mailer    mail.Mailer
+	templates *mail.Templates
+}
+
+func NewEmailChannel(mailer mail.Mailer, templates *mail.Templates) *EmailChannel {
+	return &EmailChannel{
+		mailer:    mailer,
+		templates: templates,
+	}
+}
+
+// notificationEmail is what the notification template is filled with
+type notificationEmail struct {
+	*models.Notification
+	// SentAt is the time of the notification in the recipient's time zone and locale
+	SentAt string
+}
+
+// Deliver mails the notification in the user's locale, with its time in the user's time zone
+func (channel *EmailChannel) Deliver(ctx context.Context, user *models.User, notification *models.Notification) error {
+	lang := user.Language(i18n.Fallback())
+	sentAt := notification.CreatedAt
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+	email := &notificationEmail{Notification: notification, SentAt: i18n.FormatTime(sentAt, user.Location(), lang)}
+
+	msg, err := channel.templates.Render(mail.TemplateNotification, lang, email, user.Email)
+	if err != nil {
+		return err
+	}
+	return channel.mailer.Send(ctx, msg)
+}
+
+// SMSChannel texts the title of notifications to the user's verified phone; users without one are skipped
+type SMSChannel struct {
+	phoneRepo repositories.PhoneRepoInterface
+	sender    sms.Sender
+}
+
+func NewSMSChannel(phoneRepo repositories.PhoneRepoInterface, sender sms.Sender) *SMSChannel {
+	return &SMSChannel{
+		phoneRepo: phoneRepo,
+		sender:    sender,
+	}
+}
+
+func (channel *SMSChannel) Deliver(ctx context.Context, user *models.User, notification *models.Notification) error {
+	phone, err := channel.phoneRepo.GetPhone(ctx, user.ID)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if phone.VerifiedAt == nil {
+		return nil
+	}
+	return channel.sender.Send(ctx, phone.Number, notification.Title)
+}
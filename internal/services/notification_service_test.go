@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNotificationService_VoteNotifiesProfileOwnerOnEnabledChannels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mocks.NewMockNotificationRepoInterface(ctrl)
+	userRepo := mocks.NewMockUserRepoInterface(ctrl)
+	email := NewMockNotificationChannel(ctrl)
+	sms := NewMockNotificationChannel(ctrl)
+
+	owner := &models.User{ID: 7, Email: "owner@example.com"}
+	notificationRepo.EXPECT().ListNotificationPreferences(gomock.Any(), uint(7)).Return([]models.NotificationPreference{
+		{UserID: 7, Channel: models.ChannelSMS, Enabled: true},
+	}, nil)
+	notificationRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, notification *models.Notification) error {
+		assert.Equal(t, uint(7), notification.UserID)
+		assert.Equal(t, models.NotificationVoteReceived, notification.Type)
+		assert.Equal(t, "Your profile got a dislike", notification.Title)
+		return nil
+	})
+	userRepo.EXPECT().GetUserByID(gomock.Any(), uint(7)).Return(owner, nil).Times(1)
+	email.EXPECT().Deliver(gomock.Any(), owner, gomock.Any()).Return(errors.New("provider down"))
+	sms.EXPECT().Deliver(gomock.Any(), owner, gomock.Any()).Return(nil)
+
+	shadowBanRepo := mocks.NewMockShadowBanRepoInterface(ctrl)
+	shadowBanRepo.EXPECT().IsShadowBanned(gomock.Any(), uint(3)).Return(false, nil)
+
+	service := NewNotificationService(notificationRepo, userRepo, nil, shadowBanRepo, map[string]NotificationChannel{
+		models.ChannelEmail: email,
+		models.ChannelSMS:   sms,
+	}, zaptest.NewLogger(t).Sugar())
+
+	event, err := events.New("urn:test", events.VoteCast, "7", map[string]int{"user_id": 3, "profile_id": 7, "value": -1})
+	require.NoError(t, err)
+	assert.NoError(t, service.Publish(context.Background(), event), "a failing channel does not fail the others")
+}
+
+func TestNotificationService_ProfileUpdateNotifiesFollowers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mocks.NewMockNotificationRepoInterface(ctrl)
+	followRepo := mocks.NewMockFollowRepoInterface(ctrl)
+	notificationRepo.EXPECT().ListNotificationPreferences(gomock.Any(), gomock.Any()).Return(nil, nil).Times(3)
+	var notified []uint
+	notificationRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, notification *models.Notification) error {
+		notified = append(notified, notification.UserID)
+		if notification.UserID != 7 {
+			assert.Equal(t, models.NotificationFollowedProfileUpdated, notification.Type)
+			assert.Equal(t, "User 7 updated their profile.", notification.Body)
+		}
+		return nil
+	}).Times(3)
+	followRepo.EXPECT().ListFollowerIDs(gomock.Any(), uint(7), uint(0), followerBatchSize).Return([]uint{3, 5}, nil)
+	shadowBanRepo := mocks.NewMockShadowBanRepoInterface(ctrl)
+	shadowBanRepo.EXPECT().IsShadowBanned(gomock.Any(), uint(7)).Return(false, nil)
+
+	service := NewNotificationService(notificationRepo, mocks.NewMockUserRepoInterface(ctrl), followRepo, shadowBanRepo, nil, zaptest.NewLogger(t).Sugar())
+
+	event, err := events.New("urn:test", events.UserUpdated, "7", map[string]int{"user_id": 7})
+	require.NoError(t, err)
+	require.NoError(t, service.Publish(context.Background(), event))
+	assert.Equal(t, []uint{7, 3, 5}, notified, "the user is told first, then their followers")
+}
+
+func TestNotificationService_FollowNotifiesTheFollowedUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mocks.NewMockNotificationRepoInterface(ctrl)
+	notificationRepo.EXPECT().ListNotificationPreferences(gomock.Any(), uint(7)).Return(nil, nil)
+	notificationRepo.EXPECT().CreateNotification(gomock.Any(), &models.Notification{
+		UserID: 7,
+		Type:   models.NotificationNewFollower,
+		Title:  "You have a new follower",
+		Body:   "User 3 started following your profile.",
+	})
+	shadowBanRepo := mocks.NewMockShadowBanRepoInterface(ctrl)
+	shadowBanRepo.EXPECT().IsShadowBanned(gomock.Any(), uint(3)).Return(false, nil)
+
+	service := NewNotificationService(notificationRepo, mocks.NewMockUserRepoInterface(ctrl), nil, shadowBanRepo, nil, zaptest.NewLogger(t).Sugar())
+
+	event, err := events.New("urn:test", events.UserFollowed, "7", map[string]int{"user_id": 3, "profile_id": 7})
+	require.NoError(t, err)
+	require.NoError(t, service.Publish(context.Background(), event))
+}
+
+func TestNotificationService_HidesShadowBannedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mocks.NewMockNotificationRepoInterface(ctrl)
+	shadowBanRepo := mocks.NewMockShadowBanRepoInterface(ctrl)
+	shadowBanRepo.EXPECT().IsShadowBanned(gomock.Any(), uint(3)).Return(true, nil).Times(3)
+	notificationRepo.EXPECT().ListNotificationPreferences(gomock.Any(), uint(3)).Return(nil, nil)
+	notificationRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, notification *models.Notification) error {
+		assert.Equal(t, uint(3), notification.UserID, "only the shadow-banned user hears of their profile update")
+		return nil
+	})
+
+	service := NewNotificationService(notificationRepo, mocks.NewMockUserRepoInterface(ctrl), nil, shadowBanRepo, nil, zaptest.NewLogger(t).Sugar())
+
+	for _, eventType := range []string{events.VoteCast, events.UserFollowed, events.UserUpdated} {
+		event, err := events.New("urn:test", eventType, "7", map[string]int{"user_id": 3, "profile_id": 7, "value": 1})
+		require.NoError(t, err)
+		require.NoError(t, service.Publish(context.Background(), event))
+	}
+}
+
+func TestNotificationService_SkipsDisabledChannels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mocks.NewMockNotificationRepoInterface(ctrl)
+	email := NewMockNotificationChannel(ctrl)
+	notificationRepo.EXPECT().ListNotificationPreferences(gomock.Any(), uint(7)).Return([]models.NotificationPreference{
+		{UserID: 7, Channel: models.ChannelInApp, Enabled: false},
+		{UserID: 7, Channel: models.ChannelEmail, Enabled: false},
+	}, nil)
+
+	service := NewNotificationService(notificationRepo, mocks.NewMockUserRepoInterface(ctrl), nil, nil, map[string]NotificationChannel{
+		models.ChannelEmail: email,
+	}, zaptest.NewLogger(t).Sugar())
+
+	err := service.Notify(context.Background(), &models.Notification{UserID: 7, Type: models.NotificationProfileUpdated, Title: "Updated"})
+	assert.NoError(t, err)
+}
+
+func TestNotificationService_SetPreferencesRejectsUnknownChannels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := NewNotificationService(mocks.NewMockNotificationRepoInterface(ctrl), mocks.NewMockUserRepoInterface(ctrl), nil, nil, nil, zaptest.NewLogger(t).Sugar())
+
+	_, err := service.SetPreferences(context.Background(), 7, map[string]bool{"pigeon": true, models.ChannelEmail: false})
+	var appErr *apperrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, apperrors.ValidationFailedErr.Code, appErr.Code)
+	assert.Equal(t, []apperrors.FieldViolation{{Field: "pigeon", Rule: "oneof", Message: "is not a notification channel", MessageID: "validation.notification_channel"}}, appErr.Fields)
+}
+
+// sentMail keeps the messages a test sends
+type sentMail []*mail.Message
+
+func (sent *sentMail) Send(ctx context.Context, msg *mail.Message) error {
+	*sent = append(*sent, msg)
+	return nil
+}
+
+func TestEmailChannel_UsesTheRecipientsLocaleAndTimezone(t *testing.T) {
+	templates, err := mail.LoadTemplates("")
+	require.NoError(t, err)
+	var sent sentMail
+	channel := NewEmailChannel(&sent, templates)
+
+	notification := &models.Notification{Title: "Welcome!", Body: "Your account is ready.", CreatedAt: time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, channel.Deliver(context.Background(), &models.User{Email: "ann@example.com", Timezone: "Europe/Kyiv", Locale: "uk-UA"}, notification))
+	require.NoError(t, channel.Deliver(context.Background(), &models.User{Email: "bob@example.com"}, notification))
+
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[0].Text, "Надіслано 01.05.2024 15:00 EEST")
+	assert.Contains(t, sent[1].Text, "Sent May 1, 2024 12:00 UTC", "without a locale and timezone the default language and UTC are used")
+}
+
+func TestSMSChannel_TextsVerifiedPhonesOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	phoneRepo := mocks.NewMockPhoneRepoInterface(ctrl)
+	var sent sentSMS
+	channel := NewSMSChannel(phoneRepo, &sent)
+	ctx := context.Background()
+	notification := &models.Notification{Title: "Your profile got a vote"}
+	verifiedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	phoneRepo.EXPECT().GetPhone(ctx, uint(1)).Return(nil, apperrors.NoRecordFoundErr.AppendMessage("Phone not found."))
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(&models.Phone{UserID: 2, Number: "+380501234567"}, nil)
+	require.NoError(t, channel.Deliver(ctx, &models.User{ID: 1}, notification))
+	require.NoError(t, channel.Deliver(ctx, &models.User{ID: 2}, notification))
+	assert.Empty(t, sent.to)
+
+	phoneRepo.EXPECT().GetPhone(ctx, uint(3)).Return(&models.Phone{UserID: 3, Number: "+380671234567", VerifiedAt: &verifiedAt}, nil)
+	require.NoError(t, channel.Deliver(ctx, &models.User{ID: 3}, notification))
+	assert.Equal(t, "+380671234567", sent.to)
+	assert.Equal(t, "Your profile got a vote", sent.body)
+}
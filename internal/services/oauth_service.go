@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+type OAuthService struct {
+	oauthRepo   repositories.OAuthRepoInterface
+	roleRepo    repositories.RoleRepoInterface
+	userService UserServiceInterface
+	// loginAlerts and bans keep locked and banned users from exchanging the codes they approved before
+	loginAlerts LoginAlertServiceInterface
+	bans        BanServiceInterface
+	keys        *auth.Keys
+	// codeTTL is how long an authorization code can be exchanged and tokenTTL how long the token is valid
+	codeTTL  time.Duration
+	tokenTTL time.Duration
+	logger   *zap.SugaredLogger
+	now      func() time.Time
+}
+
+type OAuthServiceInterface interface {
+	// RegisterClient creates the client and returns it with its secret, which is only kept hashed; public clients
+	// get none
+	RegisterClient(ctx context.Context, client *models.OAuthClient, confidential bool) (*models.OAuthClient, string, error)
+	ListClients(ctx context.Context) ([]models.OAuthClient, error)
+	// DeleteClient deletes the client with its codes and consents; the tokens it holds stop working at introspection
+	DeleteClient(ctx context.Context, id uint) error
+	// Authorization checks what a client asks the user to approve and returns the client, with the consent the user
+	// already gave it or nil
+	Authorization(ctx context.Context, userID uint, request *models.AuthorizationRequest) (*models.OAuthClient, *models.OAuthConsent, error)
+	// Authorize records the user's consent to the request and returns the authorization code for the client
+	Authorize(ctx context.Context, userID uint, request *models.AuthorizationRequest) (string, error)
+	// ExchangeCode returns a token on behalf of the user who approved code. The client proves itself with the PKCE
+	// verifier and, when confidential, its secret.
+	ExchangeCode(ctx context.Context, clientID, secret, code, redirectURI, verifier string) (*models.OAuthToken, error)
+	// ClientCredentials returns a token of the confidential client itself, for scope or else every scope it has
+	ClientCredentials(ctx context.Context, clientID, secret, scope string) (*models.OAuthToken, error)
+	// Granted reports whether the client still exists and, for a token on behalf of userID, still has the user's
+	// consent to scope
+	Granted(ctx context.Context, clientID string, userID uint, scope string) (bool, error)
+	// ListConsents returns the clients the user approved, with the scopes
+	ListConsents(ctx context.Context, userID uint) ([]models.OAuthConsent, error)
+	RevokeConsent(ctx context.Context, userID uint, clientID string) error
+}
+
+func NewOAuthService(oauthRepo repositories.OAuthRepoInterface, roleRepo repositories.RoleRepoInterface, userService UserServiceInterface, loginAlerts LoginAlertServiceInterface, bans BanServiceInterface, keys *auth.Keys, codeTTL, tokenTTL time.Duration, logger *zap.SugaredLogger) OAuthServiceInterface {
+	return &OAuthService{
+		oauthRepo:   oauthRepo,
+		roleRepo:    roleRepo,
+		userService: userService,
+		loginAlerts: loginAlerts,
+		bans:        bans,
+		keys:        keys,
+		codeTTL:     codeTTL,
+		tokenTTL:    tokenTTL,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (service *OAuthService) RegisterClient(ctx context.Context, client *models.OAuthClient, confidential bool) (*models.OAuthClient, string, error) {
+	if client.AllowsGrant(models.GrantAuthorizationCode) && len(client.RedirectURIs) == 0 {
+		return nil, "", apperrors.BadRequestErr.AppendMessage("the authorization code grant needs a redirect URI")
+	}
+	if client.AllowsGrant(models.GrantClientCredentials) && !confidential {
+		return nil, "", apperrors.BadRequestErr.AppendMessage("the client credentials grant needs a confidential client")
+	}
+	for _, uri := range client.RedirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return nil, "", apperrors.BadRequestErr.AppendMessage("redirect URIs are absolute and have no fragment")
+		}
+	}
+
+	var err error
+	client.ClientID, err = randomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+	secret := ""
+	if confidential {
+		secret, err = randomToken(32)
+		if err != nil {
+			return nil, "", err
+		}
+		client.SecretHash, err = passwords.HashPassword(secret)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if err := service.oauthRepo.CreateClient(ctx, client); err != nil {
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+func (service *OAuthService) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	return service.oauthRepo.ListClients(ctx)
+}
+
+func (service *OAuthService) DeleteClient(ctx context.Context, id uint) error {
+	return service.oauthRepo.DeleteClient(ctx, id)
+}
+
+func (service *OAuthService) Authorization(ctx context.Context, userID uint, request *models.AuthorizationRequest) (*models.OAuthClient, *models.OAuthConsent, error) {
+	if request.ResponseType != "code" {
+		return nil, nil, apperrors.BadRequestErr.AppendMessage("response_type should be code")
+	}
+	client, err := service.oauthRepo.GetClient(ctx, request.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return nil, nil, &apperrors.OAuthUnauthorizedClientErr
+	}
+	if !client.AllowsRedirect(request.RedirectURI) {
+		return nil, nil, apperrors.BadRequestErr.AppendMessage("redirect_uri is not registered for the client")
+	}
+	if strings.TrimSpace(request.Scope) == "" {
+		return nil, nil, apperrors.BadRequestErr.AppendMessage("scope is required")
+	}
+	if !client.AllowsScope(request.Scope) {
+		return nil, nil, &apperrors.OAuthInvalidScopeErr
+	}
+	// Only S256: a plain challenge is the verifier itself
+	if request.CodeChallengeMethod != "S256" || request.CodeChallenge == "" {
+		return nil, nil, apperrors.BadRequestErr.AppendMessage("a code_challenge with code_challenge_method S256 is required")
+	}
+
+	consent, err := service.oauthRepo.GetConsent(ctx, userID, client.ID)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return client, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, consent, nil
+}
+
+func (service *OAuthService) Authorize(ctx context.Context, userID uint, request *models.AuthorizationRequest) (string, error) {
+	client, consent, err := service.Authorization(ctx, userID, request)
+	if err != nil {
+		return "", err
+	}
+
+	// A consent grows with every approval, so a client asking for less does not take scopes away
+	scope := models.JoinScopes(request.Scope)
+	if consent != nil {
+		scope = models.JoinScopes(consent.Scope, request.Scope)
+	}
+	err = service.oauthRepo.SaveConsent(ctx, &models.OAuthConsent{UserID: userID, ClientID: client.ID, Scope: scope})
+	if err != nil {
+		return "", err
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = service.oauthRepo.CreateCode(ctx, &models.OAuthCode{
+		CodeHash:      hashToken(code),
+		ClientID:      client.ID,
+		UserID:        userID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         models.JoinScopes(request.Scope),
+		CodeChallenge: request.CodeChallenge,
+		ExpiresAt:     service.now().Add(service.codeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func (service *OAuthService) ExchangeCode(ctx context.Context, clientID, secret, code, redirectURI, verifier string) (*models.OAuthToken, error) {
+	if code == "" || verifier == "" {
+		return nil, apperrors.BadRequestErr.AppendMessage("code and code_verifier are required")
+	}
+	client, err := service.authenticate(ctx, clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return nil, &apperrors.OAuthUnauthorizedClientErr
+	}
+
+	granted, err := service.oauthRepo.TakeCode(ctx, hashToken(code))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil, &apperrors.OAuthInvalidGrantErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	if granted.ClientID != client.ID || granted.Expired(service.now()) || granted.RedirectURI != redirectURI ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != granted.CodeChallenge {
+		return nil, &apperrors.OAuthInvalidGrantErr
+	}
+
+	user, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(granted.UserID), 10))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil, &apperrors.OAuthInvalidGrantErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = CheckStanding(ctx, service.loginAlerts, service.bans, user.ID)
+	if apperrors.Is(err, &apperrors.AccountLockedErr) || apperrors.Is(err, &apperrors.UserBannedErr) {
+		return nil, &apperrors.OAuthInvalidGrantErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	role, err := service.roleRepo.GetRole(ctx, user.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	claims := auth.NewClaims(user.Email, role.Name, user.ID, service.tokenTTL)
+	claims.ClientID, claims.Scope, claims.TenantID = client.ClientID, granted.Scope, tenancy.Current(ctx)
+	return service.issue(claims)
+}
+
+func (service *OAuthService) ClientCredentials(ctx context.Context, clientID, secret, scope string) (*models.OAuthToken, error) {
+	client, err := service.authenticate(ctx, clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.Confidential() || !client.AllowsGrant(models.GrantClientCredentials) {
+		return nil, &apperrors.OAuthUnauthorizedClientErr
+	}
+	if strings.TrimSpace(scope) == "" {
+		scope = strings.Join(client.Scopes, " ")
+	}
+	if !client.AllowsScope(scope) {
+		return nil, &apperrors.OAuthInvalidScopeErr
+	}
+
+	now := service.now()
+	return service.issue(&auth.Claims{
+		ClientID: client.ClientID,
+		Scope:    models.JoinScopes(scope),
+		TenantID: tenancy.Current(ctx),
+		StandardClaims: jwt.StandardClaims{
+			Subject:   client.ClientID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(service.tokenTTL).Unix(),
+		},
+	})
+}
+
+func (service *OAuthService) Granted(ctx context.Context, clientID string, userID uint, scope string) (bool, error) {
+	client, err := service.oauthRepo.GetClient(ctx, clientID)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if userID == 0 {
+		return true, nil
+	}
+	consent, err := service.oauthRepo.GetConsent(ctx, userID, client.ID)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return consent.Covers(scope), nil
+}
+
+func (service *OAuthService) ListConsents(ctx context.Context, userID uint) ([]models.OAuthConsent, error) {
+	return service.oauthRepo.ListConsents(ctx, userID)
+}
+
+func (service *OAuthService) RevokeConsent(ctx context.Context, userID uint, clientID string) error {
+	client, err := service.oauthRepo.GetClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	return service.oauthRepo.DeleteConsent(ctx, userID, client.ID)
+}
+
+// authenticate finds the client and checks the secret of a confidential one
+func (service *OAuthService) authenticate(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	client, err := service.oauthRepo.GetClient(ctx, clientID)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil, &apperrors.OAuthInvalidClientErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.Confidential() && !passwords.CheckPasswordHash(secret, client.SecretHash) {
+		return nil, &apperrors.OAuthInvalidClientErr
+	}
+	return client, nil
+}
+
+func (service *OAuthService) issue(claims *auth.Claims) (*models.OAuthToken, error) {
+	token, err := service.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OAuthToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(service.tokenTTL.Seconds()),
+		Scope:       claims.Scope,
+	}, nil
+}
+
+// randomToken returns size random bytes, URL-safe encoded
+func randomToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
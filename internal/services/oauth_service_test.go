@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/passwords"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+var oauthTestKey = []byte("oauth-secret")
+
+func newTestOAuthService(t *testing.T) (*OAuthService, *mocks.MockOAuthRepoInterface, *mocks.MockRoleRepoInterface, *MockUserServiceInterface) {
+	ctrl := gomock.NewController(t)
+	oauthRepo := mocks.NewMockOAuthRepoInterface(ctrl)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	loginAlerts := NewMockLoginAlertServiceInterface(ctrl)
+	loginAlerts.EXPECT().Locked(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+	bans := NewMockBanServiceInterface(ctrl)
+	bans.EXPECT().ActiveBan(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	service := NewOAuthService(oauthRepo, roleRepo, userService, loginAlerts, bans, auth.NewHMACKeys(oauthTestKey), time.Minute, time.Hour, zaptest.NewLogger(t).Sugar()).(*OAuthService)
+	return service, oauthRepo, roleRepo, userService
+}
+
+func TestOAuthService_RegisterClient(t *testing.T) {
+	service, oauthRepo, _, _ := newTestOAuthService(t)
+	ctx := context.Background()
+
+	_, _, err := service.RegisterClient(ctx, &models.OAuthClient{Name: "Cron", GrantTypes: []string{models.GrantClientCredentials}}, false)
+	assert.True(t, errors.Is(err, &apperrors.BadRequestErr), "client credentials need a secret, got %v", err)
+	_, _, err = service.RegisterClient(ctx, &models.OAuthClient{Name: "App", GrantTypes: []string{models.GrantAuthorizationCode}, RedirectURIs: []string{"/callback"}}, false)
+	assert.True(t, errors.Is(err, &apperrors.BadRequestErr), "redirect URIs are absolute, got %v", err)
+
+	oauthRepo.EXPECT().CreateClient(gomock.Any(), gomock.Any()).Return(nil)
+	client, secret, err := service.RegisterClient(ctx, &models.OAuthClient{Name: "Cron", GrantTypes: []string{models.GrantClientCredentials}}, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, client.ClientID)
+	assert.True(t, passwords.CheckPasswordHash(secret, client.SecretHash), "only the hash of the secret is kept")
+}
+
+func TestOAuthService_AuthorizationCodeWithPKCE(t *testing.T) {
+	service, oauthRepo, roleRepo, userService := newTestOAuthService(t)
+	ctx := context.Background()
+	client := &models.OAuthClient{
+		ID: 4, ClientID: "app", Name: "App",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		GrantTypes:   []string{models.GrantAuthorizationCode},
+		Scopes:       []string{models.ScopeProfile, models.ScopeNotifications},
+	}
+	oauthRepo.EXPECT().GetClient(gomock.Any(), "app").Return(client, nil).AnyTimes()
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	request := &models.AuthorizationRequest{
+		ResponseType: "code", ClientID: "app", RedirectURI: "https://app.example.com/callback", Scope: "profile",
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(sum[:]), CodeChallengeMethod: "S256",
+	}
+
+	for name, invalid := range map[string]func(r models.AuthorizationRequest) models.AuthorizationRequest{
+		"other redirect": func(r models.AuthorizationRequest) models.AuthorizationRequest {
+			r.RedirectURI = "https://evil.example.com"
+			return r
+		},
+		"plain challenge": func(r models.AuthorizationRequest) models.AuthorizationRequest {
+			r.CodeChallengeMethod = "plain"
+			return r
+		},
+		"token response": func(r models.AuthorizationRequest) models.AuthorizationRequest { r.ResponseType = "token"; return r },
+	} {
+		changed := invalid(*request)
+		_, _, err := service.Authorization(ctx, 12, &changed)
+		assert.True(t, errors.Is(err, &apperrors.BadRequestErr), "%s: got %v", name, err)
+	}
+	unregistered := *request
+	unregistered.Scope = "profile admin"
+	_, _, err := service.Authorization(ctx, 12, &unregistered)
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidScopeErr), "got %v", err)
+
+	var stored *models.OAuthCode
+	oauthRepo.EXPECT().GetConsent(gomock.Any(), uint(12), uint(4)).Return(&models.OAuthConsent{Scope: "notifications"}, nil)
+	oauthRepo.EXPECT().SaveConsent(gomock.Any(), &models.OAuthConsent{UserID: 12, ClientID: 4, Scope: "notifications profile"}).Return(nil)
+	oauthRepo.EXPECT().CreateCode(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, code *models.OAuthCode) error {
+		stored = code
+		return nil
+	})
+	code, err := service.Authorize(ctx, 12, request)
+	require.NoError(t, err)
+	assert.Equal(t, hashToken(code), stored.CodeHash, "only the hash of the code is kept")
+
+	oauthRepo.EXPECT().TakeCode(gomock.Any(), hashToken(code)).Return(stored, nil)
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(&models.User{ID: 12, Email: "alice@example.com", RoleID: 1}, nil)
+	roleRepo.EXPECT().GetRole(gomock.Any(), uint(1)).Return(&models.Role{ID: 1, Name: models.StrUser}, nil)
+	token, err := service.ExchangeCode(ctx, "app", "", code, request.RedirectURI, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, "profile", token.Scope)
+	claims, err := auth.Parse(token.AccessToken, oauthTestKey)
+	require.NoError(t, err)
+	assert.Equal(t, "app", claims.ClientID)
+	assert.Equal(t, uint(12), claims.ID)
+	assert.Equal(t, models.StrUser, claims.Role)
+	assert.Equal(t, tenancy.DefaultTenantID, claims.TenantID)
+
+	oauthRepo.EXPECT().TakeCode(gomock.Any(), hashToken(code)).Return(nil, repositories.ErrNotFound)
+	_, err = service.ExchangeCode(ctx, "app", "", code, request.RedirectURI, verifier)
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidGrantErr), "a code is exchanged once, got %v", err)
+
+	oauthRepo.EXPECT().TakeCode(gomock.Any(), hashToken("other")).Return(stored, nil)
+	_, err = service.ExchangeCode(ctx, "app", "", "other", request.RedirectURI, "wrong-verifier")
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidGrantErr), "got %v", err)
+
+	// The user was locked out after approving the client
+	locked := NewMockLoginAlertServiceInterface(gomock.NewController(t))
+	locked.EXPECT().Locked(gomock.Any(), uint(12)).Return(true, nil)
+	service.loginAlerts = locked
+	oauthRepo.EXPECT().TakeCode(gomock.Any(), hashToken(code)).Return(stored, nil)
+	userService.EXPECT().GetUser(gomock.Any(), "12").Return(&models.User{ID: 12, Email: "alice@example.com", RoleID: 1}, nil)
+	_, err = service.ExchangeCode(ctx, "app", "", code, request.RedirectURI, verifier)
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidGrantErr), "got %v", err)
+}
+
+func TestOAuthService_ClientCredentials(t *testing.T) {
+	service, oauthRepo, _, _ := newTestOAuthService(t)
+	ctx := context.Background()
+	hash, err := passwords.HashPassword("s3cret")
+	require.NoError(t, err)
+	oauthRepo.EXPECT().GetClient(gomock.Any(), "cron").Return(&models.OAuthClient{
+		ID: 5, ClientID: "cron", SecretHash: hash,
+		GrantTypes: []string{models.GrantClientCredentials}, Scopes: []string{models.ScopeProfile},
+	}, nil).AnyTimes()
+	oauthRepo.EXPECT().GetClient(gomock.Any(), "unknown").Return(nil, repositories.ErrNotFound)
+
+	_, err = service.ClientCredentials(ctx, "cron", "wrong", "")
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidClientErr), "got %v", err)
+	_, err = service.ClientCredentials(ctx, "unknown", "s3cret", "")
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidClientErr), "got %v", err)
+	_, err = service.ClientCredentials(ctx, "cron", "s3cret", "notifications")
+	assert.True(t, errors.Is(err, &apperrors.OAuthInvalidScopeErr), "got %v", err)
+
+	token, err := service.ClientCredentials(ctx, "cron", "s3cret", "")
+	require.NoError(t, err)
+	assert.Equal(t, "profile", token.Scope, "every registered scope without one asked for")
+	claims, err := auth.Parse(token.AccessToken, oauthTestKey)
+	require.NoError(t, err)
+	assert.Equal(t, "cron", claims.Subject)
+	assert.Zero(t, claims.ID, "the token names no user")
+
+	granted, err := service.Granted(ctx, "cron", 0, "profile")
+	require.NoError(t, err)
+	assert.True(t, granted)
+}
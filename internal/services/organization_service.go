@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type OrganizationService struct {
+	organizationRepo repositories.OrganizationRepoInterface
+	userService      UserServiceInterface
+	invitations      InvitationServiceInterface
+	// quotas limits the members of an organization; nil without quotas
+	quotas    QuotaServiceInterface
+	txManager repositories.TxManagerInterface
+	logger    *zap.SugaredLogger
+}
+
+type OrganizationServiceInterface interface {
+	// CreateOrganization creates an organization with its creator as its admin
+	CreateOrganization(ctx context.Context, name string, creatorID uint) (*models.Organization, error)
+	GetOrganization(ctx context.Context, id uint) (*models.Organization, error)
+	// ListMemberships returns the organizations of the user with the user's role in each
+	ListMemberships(ctx context.Context, userID uint) ([]models.Membership, error)
+	ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error)
+	// Authorize returns nil when the user has at least the role minimum in the organization. Global admins are
+	// admins of every organization; anybody else gets ForbiddenErr unless they are a member with that role.
+	Authorize(ctx context.Context, organizationID, userID uint, globalRole, minimum string) error
+	// AddMember makes the user with email a member with role. An email without a user is invited instead, and
+	// joins on accepting; exactly one of the results is set. Only global admins invite, like on /admin/invitations,
+	// so anybody else gets ForbiddenErr for an email without a user.
+	AddMember(ctx context.Context, organizationID uint, email, role string, addedBy uint, globalRole string) (*models.Membership, *models.Invitation, error)
+	// SetMemberRole and RemoveMember refuse to leave the organization without an admin
+	SetMemberRole(ctx context.Context, organizationID, userID uint, role string) (*models.Membership, error)
+	RemoveMember(ctx context.Context, organizationID, userID uint) error
+}
+
+func NewOrganizationService(organizationRepo repositories.OrganizationRepoInterface, userService UserServiceInterface, invitations InvitationServiceInterface, quotas QuotaServiceInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) OrganizationServiceInterface {
+	return &OrganizationService{
+		organizationRepo: organizationRepo,
+		userService:      userService,
+		invitations:      invitations,
+		quotas:           quotas,
+		txManager:        txManager,
+		logger:           logger,
+	}
+}
+
+func (service *OrganizationService) CreateOrganization(ctx context.Context, name string, creatorID uint) (*models.Organization, error) {
+	organization := &models.Organization{Name: name, CreatedBy: creatorID}
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.organizationRepo.CreateOrganization(ctx, organization); err != nil {
+			return err
+		}
+		return service.organizationRepo.AddMember(ctx, &models.Membership{OrganizationID: organization.ID, UserID: creatorID, Role: models.StrAdmin})
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return organization, nil
+}
+
+func (service *OrganizationService) GetOrganization(ctx context.Context, id uint) (*models.Organization, error) {
+	return service.organizationRepo.GetOrganization(ctx, id)
+}
+
+func (service *OrganizationService) ListMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	return service.organizationRepo.ListMemberships(ctx, userID)
+}
+
+func (service *OrganizationService) ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error) {
+	return service.organizationRepo.ListMembers(ctx, organizationID)
+}
+
+func (service *OrganizationService) Authorize(ctx context.Context, organizationID, userID uint, globalRole, minimum string) error {
+	// The organization is looked up first, so a missing one is a 404 for everybody
+	if _, err := service.organizationRepo.GetOrganization(ctx, organizationID); err != nil {
+		return err
+	}
+	if globalRole == models.StrAdmin {
+		return nil
+	}
+
+	membership, err := service.organizationRepo.GetMembership(ctx, organizationID, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return apperrors.ForbiddenErr.AppendMessage("not a member of the organization")
+	}
+	if err != nil {
+		return err
+	}
+	if !models.RoleAtLeast(membership.Role, minimum) {
+		return apperrors.ForbiddenErr.AppendMessage("needs the " + minimum + " role in the organization")
+	}
+	return nil
+}
+
+func (service *OrganizationService) AddMember(ctx context.Context, organizationID uint, email, role string, addedBy uint, globalRole string) (*models.Membership, *models.Invitation, error) {
+	// Pending invitations do not count, so accepting one may take an organization past the quota
+	if service.quotas != nil {
+		if err := service.quotas.Check(ctx, models.QuotaOrganizationMembers, organizationID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	user, err := service.userService.GetUserByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		if globalRole != models.StrAdmin {
+			return nil, nil, apperrors.ForbiddenErr.AppendMessage("only admins invite people without an account")
+		}
+		invitation, err := service.invitations.InviteToOrganization(ctx, email, organizationID, role, addedBy)
+		return nil, invitation, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	membership := &models.Membership{OrganizationID: organizationID, UserID: user.ID, Role: role}
+	err = service.organizationRepo.AddMember(ctx, membership)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return nil, nil, apperrors.AlreadyMemberErr.AppendMessage(email)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	membership.User = user
+	return membership, nil, nil
+}
+
+func (service *OrganizationService) SetMemberRole(ctx context.Context, organizationID, userID uint, role string) (*models.Membership, error) {
+	var membership *models.Membership
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		membership, err = service.lockedMembership(ctx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if role != models.StrAdmin {
+			if err := service.keepAnAdmin(ctx, membership); err != nil {
+				return err
+			}
+		}
+		membership.Role = role
+		return service.organizationRepo.SetMemberRole(ctx, organizationID, userID, role)
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return membership, nil
+}
+
+func (service *OrganizationService) RemoveMember(ctx context.Context, organizationID, userID uint) error {
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		membership, err := service.lockedMembership(ctx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if err := service.keepAnAdmin(ctx, membership); err != nil {
+			return err
+		}
+		return service.organizationRepo.RemoveMember(ctx, organizationID, userID)
+	})
+	if err != nil {
+		service.logger.Error(err)
+	}
+	return err
+}
+
+// lockedMembership locks the organization, so two changes to its admins cannot both pass keepAnAdmin
+func (service *OrganizationService) lockedMembership(ctx context.Context, organizationID, userID uint) (*models.Membership, error) {
+	if _, err := service.organizationRepo.LockOrganization(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	return service.organizationRepo.GetMembership(ctx, organizationID, userID)
+}
+
+// keepAnAdmin returns LastOrganizationAdminErr when membership is the only admin of its organization
+func (service *OrganizationService) keepAnAdmin(ctx context.Context, membership *models.Membership) error {
+	if membership.Role != models.StrAdmin {
+		return nil
+	}
+	admins, err := service.organizationRepo.CountMembersWithRole(ctx, membership.OrganizationID, models.StrAdmin)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return &apperrors.LastOrganizationAdminErr
+	}
+	return nil
+}
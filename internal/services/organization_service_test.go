@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestOrganizationService_CreateMakesTheCreatorAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	organizationRepo := mocks.NewMockOrganizationRepoInterface(ctrl)
+	service := NewOrganizationService(organizationRepo, NewMockUserServiceInterface(ctrl), NewMockInvitationServiceInterface(ctrl), nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+
+	organizationRepo.EXPECT().CreateOrganization(gomock.Any(), &models.Organization{Name: "Acme", CreatedBy: 3}).
+		DoAndReturn(func(ctx context.Context, organization *models.Organization) error {
+			organization.ID = 7
+			return nil
+		})
+	organizationRepo.EXPECT().AddMember(gomock.Any(), &models.Membership{OrganizationID: 7, UserID: 3, Role: models.StrAdmin}).Return(nil)
+
+	organization, err := service.CreateOrganization(context.Background(), "Acme", 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), organization.ID)
+}
+
+func TestOrganizationService_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	organizationRepo := mocks.NewMockOrganizationRepoInterface(ctrl)
+	service := NewOrganizationService(organizationRepo, NewMockUserServiceInterface(ctrl), NewMockInvitationServiceInterface(ctrl), nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	organizationRepo.EXPECT().GetOrganization(gomock.Any(), uint(7)).Return(&models.Organization{ID: 7}, nil).AnyTimes()
+	organizationRepo.EXPECT().GetOrganization(gomock.Any(), uint(8)).Return(nil, repositories.ErrNotFound).AnyTimes()
+	organizationRepo.EXPECT().GetMembership(gomock.Any(), uint(7), uint(3)).Return(&models.Membership{Role: models.StrModerator}, nil).AnyTimes()
+	organizationRepo.EXPECT().GetMembership(gomock.Any(), uint(7), uint(4)).Return(nil, repositories.ErrNotFound).AnyTimes()
+
+	assert.NoError(t, service.Authorize(ctx, 7, 3, models.StrUser, models.StrUser))
+	assert.NoError(t, service.Authorize(ctx, 7, 3, models.StrUser, models.StrModerator))
+	err := service.Authorize(ctx, 7, 3, models.StrUser, models.StrAdmin)
+	assert.True(t, errors.Is(err, &apperrors.ForbiddenErr), "a moderator is no admin, got %v", err)
+	err = service.Authorize(ctx, 7, 4, models.StrModerator, models.StrUser)
+	assert.True(t, errors.Is(err, &apperrors.ForbiddenErr), "global roles below admin do not count, got %v", err)
+	assert.NoError(t, service.Authorize(ctx, 7, 4, models.StrAdmin, models.StrAdmin), "global admins administer every organization")
+	err = service.Authorize(ctx, 8, 4, models.StrAdmin, models.StrUser)
+	assert.True(t, errors.Is(err, repositories.ErrNotFound), "got %v", err)
+}
+
+func TestOrganizationService_AddMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	organizationRepo := mocks.NewMockOrganizationRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	invitations := NewMockInvitationServiceInterface(ctrl)
+	service := NewOrganizationService(organizationRepo, userService, invitations, nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "bob@example.com").Return(&models.User{ID: 5}, nil).Times(2)
+	organizationRepo.EXPECT().AddMember(gomock.Any(), &models.Membership{OrganizationID: 7, UserID: 5, Role: models.StrModerator}).Return(nil)
+	membership, invitation, err := service.AddMember(ctx, 7, "bob@example.com", models.StrModerator, 3, models.StrUser)
+	require.NoError(t, err)
+	assert.Nil(t, invitation)
+	assert.Equal(t, uint(5), membership.UserID)
+
+	organizationRepo.EXPECT().AddMember(gomock.Any(), gomock.Any()).Return(repositories.ErrDuplicate)
+	_, _, err = service.AddMember(ctx, 7, "bob@example.com", models.StrModerator, 3, models.StrUser)
+	assert.True(t, errors.Is(err, &apperrors.AlreadyMemberErr), "got %v", err)
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "ann@example.com").Return(nil, repositories.ErrNotFound).Times(2)
+	_, _, err = service.AddMember(ctx, 7, "ann@example.com", models.StrUser, 3, models.StrUser)
+	assert.True(t, errors.Is(err, &apperrors.ForbiddenErr), "only admins invite, got %v", err)
+
+	invitations.EXPECT().InviteToOrganization(gomock.Any(), "ann@example.com", uint(7), models.StrUser, uint(3)).Return(&models.Invitation{ID: 4}, nil)
+	membership, invitation, err = service.AddMember(ctx, 7, "ann@example.com", models.StrUser, 3, models.StrAdmin)
+	require.NoError(t, err)
+	assert.Nil(t, membership)
+	assert.Equal(t, uint(4), invitation.ID, "unknown emails are invited")
+}
+
+func TestOrganizationService_KeepsAnAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	organizationRepo := mocks.NewMockOrganizationRepoInterface(ctrl)
+	service := NewOrganizationService(organizationRepo, NewMockUserServiceInterface(ctrl), NewMockInvitationServiceInterface(ctrl), nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	organizationRepo.EXPECT().LockOrganization(gomock.Any(), uint(7)).Return(&models.Organization{ID: 7}, nil).AnyTimes()
+	organizationRepo.EXPECT().GetMembership(gomock.Any(), uint(7), uint(3)).Return(&models.Membership{OrganizationID: 7, UserID: 3, Role: models.StrAdmin}, nil).AnyTimes()
+	organizationRepo.EXPECT().CountMembersWithRole(gomock.Any(), uint(7), models.StrAdmin).Return(1, nil).Times(2)
+
+	_, err := service.SetMemberRole(ctx, 7, 3, models.StrUser)
+	assert.True(t, errors.Is(err, &apperrors.LastOrganizationAdminErr), "got %v", err)
+	err = service.RemoveMember(ctx, 7, 3)
+	assert.True(t, errors.Is(err, &apperrors.LastOrganizationAdminErr), "got %v", err)
+
+	organizationRepo.EXPECT().CountMembersWithRole(gomock.Any(), uint(7), models.StrAdmin).Return(2, nil)
+	organizationRepo.EXPECT().RemoveMember(gomock.Any(), uint(7), uint(3)).Return(nil)
+	assert.NoError(t, service.RemoveMember(ctx, 7, 3), "another admin is left")
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type PermissionService struct {
+	permissionRepo  repositories.PermissionRepoInterface
+	roleRepo        repositories.RoleRepoInterface
+	txManager       repositories.TxManagerInterface
+	refreshInterval time.Duration
+	logger          *zap.SugaredLogger
+
+	mu       sync.Mutex
+	grants   map[uint][]models.GrantedPermission
+	loadedAt time.Time
+}
+
+type PermissionServiceInterface interface {
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	// CreatePermission, UpdatePermission and DeletePermission record actorID in the audit with the change
+	CreatePermission(ctx context.Context, permission *models.Permission, actorID uint) error
+	UpdatePermission(ctx context.Context, permission *models.Permission, actorID uint) error
+	DeletePermission(ctx context.Context, id, actorID uint) error
+	// ListRoles returns every role with its parent
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	// RolePermissions returns the permissions of the role and its ancestors ordered by name, from the cache
+	RolePermissions(ctx context.Context, roleID uint) ([]models.GrantedPermission, error)
+	// HasPermission reports whether the role or one of its ancestors has been granted the permission, from the cache
+	HasPermission(ctx context.Context, roleID uint, name string) bool
+	// GrantPermission and RevokePermission return ErrNotFound for an unknown role or permission
+	GrantPermission(ctx context.Context, roleID, permissionID, actorID uint) error
+	RevokePermission(ctx context.Context, roleID, permissionID, actorID uint) error
+	ListAudit(ctx context.Context, beforeID uint, limit int) ([]models.PermissionAudit, error)
+}
+
+// NewPermissionService caches the grants of every role, resolved through the role parents, for refreshInterval. Changes made through the service
+// apply at once on this instance and within refreshInterval on the others.
+func NewPermissionService(permissionRepo repositories.PermissionRepoInterface, roleRepo repositories.RoleRepoInterface, txManager repositories.TxManagerInterface, refreshInterval time.Duration, logger *zap.SugaredLogger) PermissionServiceInterface {
+	return &PermissionService{
+		permissionRepo:  permissionRepo,
+		roleRepo:        roleRepo,
+		txManager:       txManager,
+		refreshInterval: refreshInterval,
+		logger:          logger,
+	}
+}
+
+func (service *PermissionService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	return service.permissionRepo.ListPermissions(ctx)
+}
+
+func (service *PermissionService) CreatePermission(ctx context.Context, permission *models.Permission, actorID uint) error {
+	return service.change(ctx, func(ctx context.Context) (*models.PermissionAudit, error) {
+		if err := service.permissionRepo.CreatePermission(ctx, permission); err != nil {
+			return nil, err
+		}
+		return &models.PermissionAudit{ActorID: actorID, Action: models.PermissionAuditCreated, PermissionID: permission.ID, PermissionName: permission.Name}, nil
+	})
+}
+
+func (service *PermissionService) UpdatePermission(ctx context.Context, permission *models.Permission, actorID uint) error {
+	return service.change(ctx, func(ctx context.Context) (*models.PermissionAudit, error) {
+		permission.UpdatedAt = time.Now()
+		if err := service.permissionRepo.UpdatePermission(ctx, permission); err != nil {
+			return nil, err
+		}
+		updated, err := service.permissionRepo.GetPermission(ctx, permission.ID)
+		if err != nil {
+			return nil, err
+		}
+		*permission = *updated
+		return &models.PermissionAudit{ActorID: actorID, Action: models.PermissionAuditUpdated, PermissionID: permission.ID, PermissionName: permission.Name}, nil
+	})
+}
+
+func (service *PermissionService) DeletePermission(ctx context.Context, id, actorID uint) error {
+	return service.change(ctx, func(ctx context.Context) (*models.PermissionAudit, error) {
+		permission, err := service.permissionRepo.GetPermission(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := service.permissionRepo.DeletePermission(ctx, id); err != nil {
+			return nil, err
+		}
+		return &models.PermissionAudit{ActorID: actorID, Action: models.PermissionAuditDeleted, PermissionID: id, PermissionName: permission.Name}, nil
+	})
+}
+
+func (service *PermissionService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	return service.roleRepo.ListRoles(ctx)
+}
+
+func (service *PermissionService) RolePermissions(ctx context.Context, roleID uint) ([]models.GrantedPermission, error) {
+	if _, err := service.roleRepo.GetRole(ctx, roleID); err != nil {
+		return nil, err
+	}
+	permissions := service.cachedGrants(ctx)[roleID]
+	if permissions == nil {
+		permissions = []models.GrantedPermission{}
+	}
+	return permissions, nil
+}
+
+func (service *PermissionService) HasPermission(ctx context.Context, roleID uint, name string) bool {
+	for _, permission := range service.cachedGrants(ctx)[roleID] {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (service *PermissionService) GrantPermission(ctx context.Context, roleID, permissionID, actorID uint) error {
+	return service.change(ctx, func(ctx context.Context) (*models.PermissionAudit, error) {
+		permission, err := service.rolePermission(ctx, roleID, permissionID)
+		if err != nil {
+			return nil, err
+		}
+		granted, err := service.permissionRepo.GrantPermission(ctx, roleID, permissionID)
+		if err != nil || !granted {
+			// Granting a permission the role has is no change and goes unaudited
+			return nil, err
+		}
+		return &models.PermissionAudit{ActorID: actorID, Action: models.PermissionAuditGranted, PermissionID: permissionID, PermissionName: permission.Name, RoleID: roleID}, nil
+	})
+}
+
+func (service *PermissionService) RevokePermission(ctx context.Context, roleID, permissionID, actorID uint) error {
+	return service.change(ctx, func(ctx context.Context) (*models.PermissionAudit, error) {
+		permission, err := service.rolePermission(ctx, roleID, permissionID)
+		if err != nil {
+			return nil, err
+		}
+		if err := service.permissionRepo.RevokePermission(ctx, roleID, permissionID); err != nil {
+			return nil, err
+		}
+		return &models.PermissionAudit{ActorID: actorID, Action: models.PermissionAuditRevoked, PermissionID: permissionID, PermissionName: permission.Name, RoleID: roleID}, nil
+	})
+}
+
+func (service *PermissionService) ListAudit(ctx context.Context, beforeID uint, limit int) ([]models.PermissionAudit, error) {
+	return service.permissionRepo.ListAudit(ctx, beforeID, limit)
+}
+
+// rolePermission checks that the role exists and returns the permission
+func (service *PermissionService) rolePermission(ctx context.Context, roleID, permissionID uint) (*models.Permission, error) {
+	if _, err := service.roleRepo.GetRole(ctx, roleID); err != nil {
+		return nil, err
+	}
+	return service.permissionRepo.GetPermission(ctx, permissionID)
+}
+
+// change runs fn in a transaction with the audit entry it returns, so no change goes unaudited, and drops the
+// cached grants once it commits. A nil entry records nothing.
+func (service *PermissionService) change(ctx context.Context, fn func(ctx context.Context) (*models.PermissionAudit, error)) error {
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		entry, err := fn(ctx)
+		if err != nil || entry == nil {
+			return err
+		}
+		return service.permissionRepo.CreateAudit(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	service.invalidate()
+	return nil
+}
+
+// cachedGrants returns the permissions of every role, inherited ones included, reloading them once they are older
+// than the refresh interval. A failed reload keeps the previous grants so a database outage does not take
+// permissions away.
+func (service *PermissionService) cachedGrants(ctx context.Context) map[uint][]models.GrantedPermission {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if !service.loadedAt.IsZero() && time.Since(service.loadedAt) < service.refreshInterval {
+		return service.grants
+	}
+
+	roles, err := service.roleRepo.ListRoles(ctx)
+	var rolePermissions []models.RolePermission
+	if err == nil {
+		rolePermissions, err = service.permissionRepo.ListRolePermissions(ctx)
+	}
+	if err != nil {
+		service.logger.Errorf("Loading role permissions failed: %v", err)
+		// Retry on the next interval rather than on every check
+		service.loadedAt = time.Now()
+		return service.grants
+	}
+
+	service.grants, service.loadedAt = service.resolveGrants(roles, rolePermissions), time.Now()
+	return service.grants
+}
+
+// resolveGrants gives every role its own permissions and those of its ancestors, nearest first, so a permission
+// granted at several levels is reported as granted to the closest one. A cycle of parents is cut where it closes.
+func (service *PermissionService) resolveGrants(roles []models.Role, rolePermissions []models.RolePermission) map[uint][]models.GrantedPermission {
+	direct := make(map[uint][]models.Permission)
+	for _, grant := range rolePermissions {
+		if grant.Permission != nil {
+			direct[grant.RoleID] = append(direct[grant.RoleID], *grant.Permission)
+		}
+	}
+	parents := make(map[uint]*uint, len(roles))
+	for _, role := range roles {
+		parents[role.ID] = role.ParentID
+	}
+
+	grants := make(map[uint][]models.GrantedPermission, len(roles))
+	for _, role := range roles {
+		visited := make(map[uint]bool)
+		granted := make(map[uint]bool)
+		for id := &role.ID; id != nil; id = parents[*id] {
+			if visited[*id] {
+				service.logger.Warnf("The parents of role %d form a cycle at role %d", role.ID, *id)
+				break
+			}
+			visited[*id] = true
+			for _, permission := range direct[*id] {
+				if !granted[permission.ID] {
+					granted[permission.ID] = true
+					grants[role.ID] = append(grants[role.ID], models.GrantedPermission{Permission: permission, GrantedTo: *id})
+				}
+			}
+		}
+		permissions := grants[role.ID]
+		sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	}
+	return grants
+}
+
+func (service *PermissionService) invalidate() {
+	service.mu.Lock()
+	service.loadedAt = time.Time{}
+	service.mu.Unlock()
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPermissionService_GrantIsAuditedAndInvalidatesTheCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permissionRepo := mocks.NewMockPermissionRepoInterface(ctrl)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	service := NewPermissionService(permissionRepo, roleRepo, newInlineTxManager(ctrl), time.Hour, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	deleteUsers := &models.Permission{ID: 5, Name: "users.delete"}
+	roleRepo.EXPECT().GetRole(gomock.Any(), uint(2)).Return(&models.Role{ID: 2, Name: models.StrModerator}, nil).AnyTimes()
+	permissionRepo.EXPECT().GetPermission(gomock.Any(), uint(5)).Return(deleteUsers, nil).AnyTimes()
+
+	// The first check loads the grants, the second is served from the cache
+	roleRepo.EXPECT().ListRoles(gomock.Any()).Return([]models.Role{{ID: 2, Name: models.StrModerator}}, nil).Times(2)
+	permissionRepo.EXPECT().ListRolePermissions(gomock.Any()).Return(nil, nil)
+	assert.False(t, service.HasPermission(ctx, 2, "users.delete"))
+	assert.False(t, service.HasPermission(ctx, 2, "users.delete"))
+
+	permissionRepo.EXPECT().GrantPermission(gomock.Any(), uint(2), uint(5)).Return(true, nil)
+	permissionRepo.EXPECT().CreateAudit(gomock.Any(), &models.PermissionAudit{
+		ActorID: 9, Action: models.PermissionAuditGranted, PermissionID: 5, PermissionName: "users.delete", RoleID: 2,
+	}).Return(nil)
+	require.NoError(t, service.GrantPermission(ctx, 2, 5, 9))
+
+	permissionRepo.EXPECT().ListRolePermissions(gomock.Any()).
+		Return([]models.RolePermission{{RoleID: 2, PermissionID: 5, Permission: deleteUsers}}, nil)
+	assert.True(t, service.HasPermission(ctx, 2, "users.delete"), "the grant applies at once")
+	permissions, err := service.RolePermissions(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []models.GrantedPermission{{Permission: *deleteUsers, GrantedTo: 2}}, permissions)
+
+	// A repeated grant changes nothing and is not audited
+	permissionRepo.EXPECT().GrantPermission(gomock.Any(), uint(2), uint(5)).Return(false, nil)
+	require.NoError(t, service.GrantPermission(ctx, 2, 5, 9))
+}
+
+func TestPermissionService_FailedChangeIsNotAudited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permissionRepo := mocks.NewMockPermissionRepoInterface(ctrl)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	service := NewPermissionService(permissionRepo, roleRepo, newInlineTxManager(ctrl), time.Hour, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	roleRepo.EXPECT().GetRole(gomock.Any(), uint(8)).Return(nil, repositories.ErrNotFound)
+	err := service.RevokePermission(ctx, 8, 5, 9)
+	assert.True(t, errors.Is(err, repositories.ErrNotFound), "got %v", err)
+
+	permissionRepo.EXPECT().CreatePermission(gomock.Any(), gomock.Any()).Return(repositories.ErrDuplicate)
+	err = service.CreatePermission(ctx, &models.Permission{Name: "users.read"}, 9)
+	assert.True(t, errors.Is(err, repositories.ErrDuplicate), "got %v", err)
+}
+
+func TestPermissionService_DeleteAuditsTheName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permissionRepo := mocks.NewMockPermissionRepoInterface(ctrl)
+	service := NewPermissionService(permissionRepo, mocks.NewMockRoleRepoInterface(ctrl), newInlineTxManager(ctrl), time.Hour, zaptest.NewLogger(t).Sugar())
+
+	permissionRepo.EXPECT().GetPermission(gomock.Any(), uint(5)).Return(&models.Permission{ID: 5, Name: "users.delete"}, nil)
+	permissionRepo.EXPECT().DeletePermission(gomock.Any(), uint(5)).Return(nil)
+	permissionRepo.EXPECT().CreateAudit(gomock.Any(), &models.PermissionAudit{
+		ActorID: 9, Action: models.PermissionAuditDeleted, PermissionID: 5, PermissionName: "users.delete",
+	}).Return(nil)
+	require.NoError(t, service.DeletePermission(context.Background(), 5, 9))
+}
+
+func TestPermissionService_RolesInheritFromTheirParents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permissionRepo := mocks.NewMockPermissionRepoInterface(ctrl)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	service := NewPermissionService(permissionRepo, roleRepo, newInlineTxManager(ctrl), time.Hour, zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	userID, moderatorID := uint(1), uint(2)
+	roleRepo.EXPECT().ListRoles(gomock.Any()).Return([]models.Role{
+		{ID: 1, Name: models.StrUser},
+		{ID: 2, Name: models.StrModerator, ParentID: &userID},
+		{ID: 3, Name: models.StrAdmin, ParentID: &moderatorID},
+	}, nil)
+	readUsers := &models.Permission{ID: 1, Name: "users.read"}
+	banUsers := &models.Permission{ID: 2, Name: "users.ban"}
+	deleteUsers := &models.Permission{ID: 3, Name: "users.delete"}
+	permissionRepo.EXPECT().ListRolePermissions(gomock.Any()).Return([]models.RolePermission{
+		{RoleID: 1, PermissionID: 1, Permission: readUsers},
+		{RoleID: 2, PermissionID: 2, Permission: banUsers},
+		// Granted again further down, the nearest grant is the one reported
+		{RoleID: 3, PermissionID: 1, Permission: readUsers},
+		{RoleID: 3, PermissionID: 3, Permission: deleteUsers},
+	}, nil)
+
+	assert.True(t, service.HasPermission(ctx, 3, "users.read"))
+	assert.True(t, service.HasPermission(ctx, 3, "users.ban"), "admins inherit from moderators")
+	assert.True(t, service.HasPermission(ctx, 2, "users.read"), "moderators inherit from users")
+	assert.False(t, service.HasPermission(ctx, 2, "users.delete"), "nothing is inherited downwards")
+	assert.False(t, service.HasPermission(ctx, 1, "users.ban"))
+
+	roleRepo.EXPECT().GetRole(gomock.Any(), uint(3)).Return(&models.Role{ID: 3, Name: models.StrAdmin}, nil)
+	permissions, err := service.RolePermissions(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []models.GrantedPermission{
+		{Permission: *banUsers, GrantedTo: 2},
+		{Permission: *deleteUsers, GrantedTo: 3},
+		{Permission: *readUsers, GrantedTo: 3},
+	}, permissions)
+}
+
+func TestPermissionService_ParentCycleIsCut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permissionRepo := mocks.NewMockPermissionRepoInterface(ctrl)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	service := NewPermissionService(permissionRepo, roleRepo, newInlineTxManager(ctrl), time.Hour, zaptest.NewLogger(t).Sugar())
+
+	first, second := uint(1), uint(2)
+	roleRepo.EXPECT().ListRoles(gomock.Any()).Return([]models.Role{{ID: 1, ParentID: &second}, {ID: 2, ParentID: &first}}, nil)
+	permissionRepo.EXPECT().ListRolePermissions(gomock.Any()).Return([]models.RolePermission{
+		{RoleID: 2, PermissionID: 1, Permission: &models.Permission{ID: 1, Name: "users.read"}},
+	}, nil)
+
+	assert.True(t, service.HasPermission(context.Background(), 1, "users.read"))
+	assert.False(t, service.HasPermission(context.Background(), 1, "users.delete"))
+}
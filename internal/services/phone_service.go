@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/sms"
+	"go.uber.org/zap"
+)
+
+// secondFactorSMS is the kind of second factor phones are, in responses and security events
+const secondFactorSMS = "sms"
+
+type PhoneService struct {
+	phoneRepo      repositories.PhoneRepoInterface
+	userService    UserServiceInterface
+	securityEvents SecurityEventServiceInterface
+	sender         sms.Sender
+	// limiter caps the codes texted to a user; nil with PHONE_CODE_SEND_LIMIT=0
+	limiter ratelimit.Limiter
+	// A code is valid for codeTTL and attempts wrong guesses
+	codeTTL  time.Duration
+	attempts int
+	logger   *zap.SugaredLogger
+	now      func() time.Time
+}
+
+type PhoneServiceInterface interface {
+	GetPhone(ctx context.Context, userID uint) (*models.Phone, error)
+	// SetPhone replaces the phone of the user with number, unverified and without second factor, and texts it a
+	// code to verify it with
+	SetPhone(ctx context.Context, userID uint, number string) (*models.Phone, error)
+	// ResendCode texts the unverified phone of the user a new code to verify it with
+	ResendCode(ctx context.Context, userID uint) error
+	// Verify verifies the phone of the user with the code texted to it. PhoneTakenErr when another user of the
+	// tenant verified the number first.
+	Verify(ctx context.Context, userID uint, code string) (*models.Phone, error)
+	// SetSecondFactor turns the SMS second factor of the user's password logins on or off; only a verified phone
+	// can be one
+	SetSecondFactor(ctx context.Context, userID uint, enabled bool) (*models.Phone, error)
+	Remove(ctx context.Context, userID uint) error
+	// StartSecondFactor texts a code to the user when the user's logins need one, and returns the challenge the
+	// code has to come back with. It returns false when the user has no second factor.
+	StartSecondFactor(ctx context.Context, user *models.User) (string, bool, error)
+	// CompleteSecondFactor returns the user the challenge was given to once the code texted to them is right
+	CompleteSecondFactor(ctx context.Context, challenge, code string) (uint, error)
+	// StartLogin texts a login code to number when a user verified it, within the send limits of the number and
+	// of the user. Nothing is sent otherwise, and nothing tells the caller so, lest it reveals which numbers have
+	// accounts: failures are only logged.
+	StartLogin(ctx context.Context, number string)
+	// CompleteLogin returns the user who verified number once the login code texted to it is right
+	CompleteLogin(ctx context.Context, number, code string) (uint, error)
+}
+
+func NewPhoneService(phoneRepo repositories.PhoneRepoInterface, userService UserServiceInterface, securityEvents SecurityEventServiceInterface, sender sms.Sender, limiter ratelimit.Limiter, codeTTL time.Duration, attempts int, logger *zap.SugaredLogger) PhoneServiceInterface {
+	return &PhoneService{
+		phoneRepo:      phoneRepo,
+		userService:    userService,
+		securityEvents: securityEvents,
+		sender:         sender,
+		limiter:        limiter,
+		codeTTL:        codeTTL,
+		attempts:       attempts,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+func (service *PhoneService) GetPhone(ctx context.Context, userID uint) (*models.Phone, error) {
+	return service.phoneRepo.GetPhone(ctx, userID)
+}
+
+func (service *PhoneService) SetPhone(ctx context.Context, userID uint, number string) (*models.Phone, error) {
+	user, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return nil, err
+	}
+	phone := &models.Phone{UserID: userID, Number: number, UpdatedAt: service.now()}
+	if err := service.phoneRepo.SavePhone(ctx, phone); err != nil {
+		return nil, err
+	}
+	if err := service.sendCode(ctx, user, phone, models.PhoneCodeVerify, ""); err != nil {
+		return nil, err
+	}
+	return phone, nil
+}
+
+func (service *PhoneService) ResendCode(ctx context.Context, userID uint) error {
+	phone, err := service.phoneRepo.GetPhone(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if phone.VerifiedAt != nil {
+		return apperrors.BadRequestErr.AppendMessage("the phone number is already verified")
+	}
+	user, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return err
+	}
+	return service.sendCode(ctx, user, phone, models.PhoneCodeVerify, "")
+}
+
+func (service *PhoneService) Verify(ctx context.Context, userID uint, code string) (*models.Phone, error) {
+	phone, err := service.phoneRepo.GetPhone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if phone.VerifiedAt != nil {
+		return phone, nil
+	}
+	sent, err := service.phoneRepo.GetCode(ctx, userID, models.PhoneCodeVerify)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil, apperrors.PhoneCodeInvalidErr.AppendMessage("no code was sent, ask for a new one")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := service.checkCode(ctx, sent, code); err != nil {
+		return nil, err
+	}
+
+	now := service.now()
+	err = service.phoneRepo.VerifyPhone(ctx, userID, now)
+	if apperrors.Is(err, repositories.ErrDuplicate) {
+		return nil, &apperrors.PhoneTakenErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	phone.VerifiedAt, phone.UpdatedAt = &now, now
+	service.securityEvents.Record(ctx, userID, models.SecurityEventPhoneVerified, "")
+	return phone, nil
+}
+
+func (service *PhoneService) SetSecondFactor(ctx context.Context, userID uint, enabled bool) (*models.Phone, error) {
+	phone, err := service.phoneRepo.GetPhone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled && phone.VerifiedAt == nil {
+		return nil, &apperrors.PhoneNotVerifiedErr
+	}
+	if phone.SecondFactor == enabled {
+		return phone, nil
+	}
+	if err := service.phoneRepo.SetSecondFactor(ctx, userID, enabled); err != nil {
+		return nil, err
+	}
+	phone.SecondFactor = enabled
+	event := models.SecurityEventSecondFactorDisabled
+	if enabled {
+		event = models.SecurityEventSecondFactorEnabled
+	}
+	service.securityEvents.Record(ctx, userID, event, secondFactorSMS)
+	return phone, nil
+}
+
+func (service *PhoneService) Remove(ctx context.Context, userID uint) error {
+	phone, err := service.phoneRepo.GetPhone(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := service.phoneRepo.DeletePhone(ctx, userID); err != nil {
+		return err
+	}
+	// Removing the phone turns its second factor off, which the user should hear about like turning it off
+	if phone.SecondFactor {
+		service.securityEvents.Record(ctx, userID, models.SecurityEventSecondFactorDisabled, secondFactorSMS)
+	}
+	if phone.VerifiedAt != nil {
+		service.securityEvents.Record(ctx, userID, models.SecurityEventPhoneRemoved, "")
+	}
+	return nil
+}
+
+func (service *PhoneService) StartSecondFactor(ctx context.Context, user *models.User) (string, bool, error) {
+	phone, err := service.phoneRepo.GetPhone(ctx, user.ID)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if !phone.SecondFactor || phone.VerifiedAt == nil {
+		return "", false, nil
+	}
+
+	challenge, err := randomToken(32)
+	if err != nil {
+		return "", false, err
+	}
+	if err := service.sendCode(ctx, user, phone, models.PhoneCodeSecondFactor, hashToken(challenge)); err != nil {
+		return "", false, err
+	}
+	return challenge, true, nil
+}
+
+func (service *PhoneService) CompleteSecondFactor(ctx context.Context, challenge, code string) (uint, error) {
+	sent, err := service.phoneRepo.GetCodeByChallenge(ctx, hashToken(challenge))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return 0, apperrors.PhoneCodeInvalidErr.AppendMessage("unknown challenge, sign in again")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := service.checkCode(ctx, sent, code); err != nil {
+		return 0, err
+	}
+	return sent.UserID, nil
+}
+
+func (service *PhoneService) StartLogin(ctx context.Context, number string) {
+	// Limited by number before the lookup, so numbers with and without accounts run into it alike
+	if service.limiter != nil {
+		allowed, _, err := service.limiter.Allow(ctx, "number:"+number)
+		if err != nil {
+			service.logger.Errorw("Failed to check the phone code limit", "error", err)
+		} else if !allowed {
+			return
+		}
+	}
+
+	phone, err := service.phoneRepo.GetVerifiedPhone(ctx, number)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return
+	}
+	if err != nil {
+		service.logger.Errorw("Failed to find the phone to log in with", "error", err)
+		return
+	}
+	user, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(phone.UserID), 10))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return
+	}
+	if err != nil {
+		service.logger.Errorw("Failed to find the user to log in", "user_id", phone.UserID, "error", err)
+		return
+	}
+	err = service.sendCode(ctx, user, phone, models.PhoneCodeLogin, "")
+	if err != nil && !apperrors.Is(err, &apperrors.TooManyRequestsErr) {
+		service.logger.Errorw("Failed to text a login code", "user_id", user.ID, "error", err)
+	}
+}
+
+func (service *PhoneService) CompleteLogin(ctx context.Context, number, code string) (uint, error) {
+	phone, err := service.phoneRepo.GetVerifiedPhone(ctx, number)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return 0, &apperrors.PhoneCodeInvalidErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	sent, err := service.phoneRepo.GetCode(ctx, phone.UserID, models.PhoneCodeLogin)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return 0, &apperrors.PhoneCodeInvalidErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := service.checkCode(ctx, sent, code); err != nil {
+		return 0, err
+	}
+	return phone.UserID, nil
+}
+
+// sendCode texts a new code for purpose to the phone, in the user's language, within the user's send limit
+func (service *PhoneService) sendCode(ctx context.Context, user *models.User, phone *models.Phone, purpose, challengeHash string) error {
+	if service.limiter != nil {
+		allowed, retryAfter, err := service.limiter.Allow(ctx, strconv.FormatUint(uint64(user.ID), 10))
+		if err != nil {
+			service.logger.Errorw("Failed to check the phone code limit", "user_id", user.ID, "error", err)
+		} else if !allowed {
+			return apperrors.TooManyRequestsErr.AppendMessage(fmt.Sprintf("too many codes sent, try again in %d seconds", int(math.Ceil(retryAfter.Seconds()))))
+		}
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return err
+	}
+	now := service.now()
+	err = service.phoneRepo.SaveCode(ctx, &models.PhoneCode{
+		UserID:        user.ID,
+		Purpose:       purpose,
+		CodeHash:      hashToken(code),
+		ChallengeHash: challengeHash,
+		ExpiresAt:     now.Add(service.codeTTL),
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return err
+	}
+	body := i18n.Message(user.Language(i18n.Fallback()), "sms.phone_code", code, int(service.codeTTL.Minutes()))
+	return service.sender.Send(ctx, phone.Number, body)
+}
+
+// checkCode uses the code up when guess is right. Every guess counts against the code before it is compared, so
+// guesses made at once get no more than attempts between them, and the last allowed wrong guess uses it up.
+func (service *PhoneService) checkCode(ctx context.Context, sent *models.PhoneCode, guess string) error {
+	expired := apperrors.PhoneCodeInvalidErr.AppendMessage("the code expired, ask for a new one")
+	if !service.now().Before(sent.ExpiresAt) {
+		if err := service.phoneRepo.DeleteCode(ctx, sent.ID); err != nil {
+			return err
+		}
+		return expired
+	}
+	counted, err := service.phoneRepo.CountAttempt(ctx, sent.ID, service.attempts)
+	if err != nil {
+		return err
+	}
+	if !counted {
+		if err := service.phoneRepo.DeleteCode(ctx, sent.ID); err != nil {
+			return err
+		}
+		return expired
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(guess)), []byte(sent.CodeHash)) != 1 {
+		if sent.Attempts+1 >= service.attempts {
+			if err := service.phoneRepo.DeleteCode(ctx, sent.ID); err != nil {
+				return err
+			}
+		}
+		return &apperrors.PhoneCodeInvalidErr
+	}
+	return service.phoneRepo.DeleteCode(ctx, sent.ID)
+}
+
+// randomCode returns a code of six random digits
+func randomCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
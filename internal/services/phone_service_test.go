@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+// sentSMS keeps the last text sent
+type sentSMS struct {
+	to, body string
+}
+
+func (s *sentSMS) Send(_ context.Context, to, body string) error {
+	s.to, s.body = to, body
+	return nil
+}
+
+// code returns the code in the last text
+func (s *sentSMS) code(t *testing.T) string {
+	code := regexp.MustCompile(`\d{6}`).FindString(s.body)
+	require.NotEmpty(t, code, "no code in %q", s.body)
+	return code
+}
+
+// fakeLimiter allows the first n requests of every key in one window that never ends
+type fakeLimiter struct {
+	n     int
+	count map[string]int
+}
+
+func (l *fakeLimiter) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	l.count[key]++
+	return l.count[key] <= l.n, time.Minute, nil
+}
+
+func newTestPhoneService(t *testing.T, ctrl *gomock.Controller, limiter ratelimit.Limiter) (*PhoneService, *mocks.MockPhoneRepoInterface, *MockUserServiceInterface, *MockSecurityEventServiceInterface, *sentSMS) {
+	phoneRepo := mocks.NewMockPhoneRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	sender := &sentSMS{}
+	service := NewPhoneService(phoneRepo, userService, securityEvents, sender, limiter, 10*time.Minute, 3, zaptest.NewLogger(t).Sugar()).(*PhoneService)
+	return service, phoneRepo, userService, securityEvents, sender
+}
+
+func TestPhoneService_SetAndVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, phoneRepo, userService, securityEvents, sender := newTestPhoneService(t, ctrl, nil)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2, Locale: "uk"}, nil)
+	phoneRepo.EXPECT().SavePhone(ctx, &models.Phone{UserID: 2, Number: "+380501234567", UpdatedAt: now}).Return(nil)
+	var saved *models.PhoneCode
+	phoneRepo.EXPECT().SaveCode(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, code *models.PhoneCode) error {
+		saved = code
+		return nil
+	})
+	_, err := service.SetPhone(ctx, 2, "+380501234567")
+	require.NoError(t, err)
+	assert.Equal(t, "+380501234567", sender.to)
+	assert.Contains(t, sender.body, "10 хв", "the text is in the user's language")
+	assert.Equal(t, models.PhoneCodeVerify, saved.Purpose)
+	assert.Equal(t, hashToken(sender.code(t)), saved.CodeHash)
+	assert.Equal(t, now.Add(10*time.Minute), saved.ExpiresAt)
+	saved.ID = 7
+
+	// A wrong guess counts against the code
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(&models.Phone{UserID: 2, Number: "+380501234567"}, nil).Times(2)
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeVerify).Return(saved, nil).Times(2)
+	phoneRepo.EXPECT().CountAttempt(ctx, uint64(7), 3).Return(true, nil).Times(2)
+	_, err = service.Verify(ctx, 2, "wrong")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr))
+
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(7)).Return(nil)
+	phoneRepo.EXPECT().VerifyPhone(ctx, uint(2), now).Return(nil)
+	securityEvents.EXPECT().Record(ctx, uint(2), models.SecurityEventPhoneVerified, "")
+	phone, err := service.Verify(ctx, 2, sender.code(t))
+	require.NoError(t, err)
+	assert.Equal(t, &now, phone.VerifiedAt)
+}
+
+func TestPhoneService_VerifyCodeChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, phoneRepo, _, _, _ := newTestPhoneService(t, ctrl, nil)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(&models.Phone{UserID: 2}, nil).AnyTimes()
+
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeVerify).Return(nil, repositories.ErrNotFound.AppendMessage("Phone code not found."))
+	_, err := service.Verify(ctx, 2, "123456")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr), "no code was sent")
+
+	expired := &models.PhoneCode{ID: 7, CodeHash: hashToken("123456"), ExpiresAt: now}
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeVerify).Return(expired, nil)
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(7)).Return(nil)
+	_, err = service.Verify(ctx, 2, "123456")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr), "an expired code is used up")
+
+	// The last allowed wrong guess uses the code up
+	lastTry := &models.PhoneCode{ID: 8, CodeHash: hashToken("123456"), Attempts: 2, ExpiresAt: now.Add(time.Minute)}
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeVerify).Return(lastTry, nil)
+	phoneRepo.EXPECT().CountAttempt(ctx, uint64(8), 3).Return(true, nil)
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(8)).Return(nil)
+	_, err = service.Verify(ctx, 2, "654321")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr))
+
+	// Guesses made at once used the code up in between, so even the right one is turned away
+	usedUp := &models.PhoneCode{ID: 10, CodeHash: hashToken("123456"), Attempts: 1, ExpiresAt: now.Add(time.Minute)}
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeVerify).Return(usedUp, nil)
+	phoneRepo.EXPECT().CountAttempt(ctx, uint64(10), 3).Return(false, nil)
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(10)).Return(nil)
+	_, err = service.Verify(ctx, 2, "123456")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr))
+
+	taken := &models.PhoneCode{ID: 9, CodeHash: hashToken("123456"), ExpiresAt: now.Add(time.Minute)}
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeVerify).Return(taken, nil)
+	phoneRepo.EXPECT().CountAttempt(ctx, uint64(9), 3).Return(true, nil)
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(9)).Return(nil)
+	phoneRepo.EXPECT().VerifyPhone(ctx, uint(2), now).Return(&apperrors.DuplicateRecordErr)
+	_, err = service.Verify(ctx, 2, "123456")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneTakenErr))
+}
+
+func TestPhoneService_SendLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, phoneRepo, userService, _, sender := newTestPhoneService(t, ctrl, &fakeLimiter{n: 1, count: map[string]int{}})
+	ctx := context.Background()
+
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(&models.Phone{UserID: 2, Number: "+380501234567"}, nil).Times(2)
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2}, nil).Times(2)
+	phoneRepo.EXPECT().SaveCode(ctx, gomock.Any()).Return(nil)
+	require.NoError(t, service.ResendCode(ctx, 2))
+	sender.body = ""
+
+	err := service.ResendCode(ctx, 2)
+	assert.True(t, apperrors.Is(err, &apperrors.TooManyRequestsErr))
+	assert.Empty(t, sender.body, "nothing is sent over the limit")
+}
+
+func TestPhoneService_SecondFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, phoneRepo, _, securityEvents, sender := newTestPhoneService(t, ctrl, nil)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	user := &models.User{ID: 2}
+
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(&models.Phone{UserID: 2, Number: "+380501234567"}, nil)
+	_, err := service.SetSecondFactor(ctx, 2, true)
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneNotVerifiedErr), "only a verified phone can be a second factor")
+
+	verified := &models.Phone{UserID: 2, Number: "+380501234567", VerifiedAt: &now}
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(verified, nil)
+	_, required, err := service.StartSecondFactor(ctx, user)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(verified, nil)
+	phoneRepo.EXPECT().SetSecondFactor(ctx, uint(2), true).Return(nil)
+	securityEvents.EXPECT().Record(ctx, uint(2), models.SecurityEventSecondFactorEnabled, "sms")
+	phone, err := service.SetSecondFactor(ctx, 2, true)
+	require.NoError(t, err)
+	assert.True(t, phone.SecondFactor)
+
+	var saved *models.PhoneCode
+	phoneRepo.EXPECT().GetPhone(ctx, uint(2)).Return(phone, nil)
+	phoneRepo.EXPECT().SaveCode(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, code *models.PhoneCode) error {
+		saved = code
+		saved.ID = 7
+		return nil
+	})
+	challenge, required, err := service.StartSecondFactor(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, required)
+	assert.Equal(t, models.PhoneCodeSecondFactor, saved.Purpose)
+	assert.Equal(t, hashToken(challenge), saved.ChallengeHash)
+
+	phoneRepo.EXPECT().GetCodeByChallenge(ctx, hashToken("other")).Return(nil, repositories.ErrNotFound.AppendMessage("Phone code not found."))
+	_, err = service.CompleteSecondFactor(ctx, "other", sender.code(t))
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr))
+
+	phoneRepo.EXPECT().GetCodeByChallenge(ctx, hashToken(challenge)).Return(saved, nil)
+	phoneRepo.EXPECT().CountAttempt(ctx, uint64(7), 3).Return(true, nil)
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(7)).Return(nil)
+	userID, err := service.CompleteSecondFactor(ctx, challenge, sender.code(t))
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), userID)
+}
+
+func TestPhoneService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, phoneRepo, userService, _, sender := newTestPhoneService(t, ctrl, nil)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// An unknown number is texted nothing, and answered the same
+	phoneRepo.EXPECT().GetVerifiedPhone(ctx, "+380670000000").Return(nil, repositories.ErrNotFound.AppendMessage("Phone not found.")).Times(2)
+	service.StartLogin(ctx, "+380670000000")
+	assert.Empty(t, sender.to)
+	_, err := service.CompleteLogin(ctx, "+380670000000", "123456")
+	assert.True(t, apperrors.Is(err, &apperrors.PhoneCodeInvalidErr))
+
+	phone := &models.Phone{UserID: 2, Number: "+380501234567", VerifiedAt: &now}
+	phoneRepo.EXPECT().GetVerifiedPhone(ctx, "+380501234567").Return(phone, nil).Times(2)
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2}, nil)
+	var saved *models.PhoneCode
+	phoneRepo.EXPECT().SaveCode(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, code *models.PhoneCode) error {
+		saved = code
+		saved.ID = 7
+		return nil
+	})
+	service.StartLogin(ctx, "+380501234567")
+	assert.Equal(t, "+380501234567", sender.to)
+	assert.Equal(t, models.PhoneCodeLogin, saved.Purpose)
+
+	phoneRepo.EXPECT().GetCode(ctx, uint(2), models.PhoneCodeLogin).Return(saved, nil)
+	phoneRepo.EXPECT().CountAttempt(ctx, uint64(7), 3).Return(true, nil)
+	phoneRepo.EXPECT().DeleteCode(ctx, uint64(7)).Return(nil)
+	userID, err := service.CompleteLogin(ctx, "+380501234567", sender.code(t))
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), userID)
+}
+
+func TestPhoneService_LoginLimitsNumbersBeforeTheLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, phoneRepo, userService, _, sender := newTestPhoneService(t, ctrl, &fakeLimiter{n: 1, count: map[string]int{}})
+	ctx := context.Background()
+
+	// Over the limit of the number nothing is looked up, whether it has an account or not
+	phoneRepo.EXPECT().GetVerifiedPhone(ctx, "+380670000000").Return(nil, repositories.ErrNotFound.AppendMessage("Phone not found."))
+	service.StartLogin(ctx, "+380670000000")
+	service.StartLogin(ctx, "+380670000000")
+
+	// Over the limit of the user, texted before to another number, the caller is told nothing either
+	service.limiter.Allow(ctx, "2")
+	phoneRepo.EXPECT().GetVerifiedPhone(ctx, "+380501234567").Return(&models.Phone{UserID: 2, Number: "+380501234567"}, nil)
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2}, nil)
+	service.StartLogin(ctx, "+380501234567")
+	assert.Empty(t, sender.body)
+}
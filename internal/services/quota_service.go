@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+// quotaRefreshInterval is how long the limits of a tenant are cached, so a changed limit applies within that time
+const quotaRefreshInterval = time.Minute
+
+type QuotaService struct {
+	quotaRepo repositories.QuotaRepoInterface
+	// defaults are the configured limits, for tenants without one of their own
+	defaults map[string]int
+	logger   *zap.SugaredLogger
+	now      func() time.Time
+
+	// mu guards limits and loads only; the database is read outside of it
+	mu     sync.Mutex
+	limits map[uint]cachedLimits
+	// loads are the reloads in flight by tenant, which concurrent requests of the tenant share
+	loads map[uint]*limitsLoad
+}
+
+type cachedLimits struct {
+	limits   map[string]int
+	loadedAt time.Time
+}
+
+// limitsLoad is a reload of a tenant's limits; cached is set once done is closed
+type limitsLoad struct {
+	done   chan struct{}
+	cached cachedLimits
+}
+
+type QuotaServiceInterface interface {
+	// Check returns QuotaExceededErr when the tenant in ctx has used up quota, one of the models.Quota* names
+	// counted before an action. organizationID is the organization for organization_members and 0 otherwise.
+	Check(ctx context.Context, quota string, organizationID uint) error
+	// CountRequest counts an API request of the tenant in ctx and returns QuotaExceededErr past the daily limit
+	CountRequest(ctx context.Context) error
+	// Usage returns every quota of the tenant in ctx with its limit and use
+	Usage(ctx context.Context) ([]models.QuotaUsage, error)
+}
+
+// NewQuotaService limits every tenant to defaults, keyed by the models.Quota* names, unless the tenant has a
+// limit of its own in quotaRepo
+func NewQuotaService(quotaRepo repositories.QuotaRepoInterface, defaults map[string]int, logger *zap.SugaredLogger) QuotaServiceInterface {
+	return &QuotaService{
+		quotaRepo: quotaRepo,
+		defaults:  defaults,
+		logger:    logger,
+		now:       time.Now,
+		limits:    map[uint]cachedLimits{},
+		loads:     map[uint]*limitsLoad{},
+	}
+}
+
+func (service *QuotaService) Check(ctx context.Context, quota string, organizationID uint) error {
+	limit := service.limit(ctx, quota)
+	if limit == 0 {
+		return nil
+	}
+	used, err := service.used(ctx, quota, organizationID)
+	if err != nil {
+		return err
+	}
+	if used >= limit {
+		return apperrors.QuotaExceededErr.AppendMessage(quota + " is limited to " + strconv.Itoa(limit))
+	}
+	return nil
+}
+
+func (service *QuotaService) CountRequest(ctx context.Context) error {
+	requests, err := service.quotaRepo.IncrementRequests(ctx, service.today())
+	if err != nil {
+		return err
+	}
+	if limit := service.limit(ctx, models.QuotaRequestsPerDay); limit != 0 && requests > limit {
+		return apperrors.QuotaExceededErr.AppendMessage(models.QuotaRequestsPerDay + " is limited to " + strconv.Itoa(limit))
+	}
+	return nil
+}
+
+func (service *QuotaService) Usage(ctx context.Context) ([]models.QuotaUsage, error) {
+	usage := make([]models.QuotaUsage, 0, len(models.Quotas))
+	for _, quota := range models.Quotas {
+		used, err := service.used(ctx, quota, 0)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, models.QuotaUsage{Name: quota, Limit: service.limit(ctx, quota), Used: used})
+	}
+	return usage, nil
+}
+
+func (service *QuotaService) used(ctx context.Context, quota string, organizationID uint) (int, error) {
+	switch quota {
+	case models.QuotaUsers:
+		return service.quotaRepo.CountUsers(ctx)
+	case models.QuotaVotesPerDay:
+		now := service.now().UTC()
+		return service.quotaRepo.CountVotesSince(ctx, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	case models.QuotaRequestsPerDay:
+		return service.quotaRepo.CountRequests(ctx, service.today())
+	case models.QuotaOrganizationMembers:
+		if organizationID == 0 {
+			return service.quotaRepo.LargestOrganization(ctx)
+		}
+		return service.quotaRepo.CountMembers(ctx, organizationID)
+	}
+	return 0, errors.New("unknown quota " + quota)
+}
+
+func (service *QuotaService) today() string {
+	return service.now().UTC().Format("2006-01-02")
+}
+
+// limit returns the limit of quota for the tenant in ctx. A failed reload keeps the limits loaded before, or
+// the defaults, so a database outage does not lift or tighten quotas.
+func (service *QuotaService) limit(ctx context.Context, quota string) int {
+	cached := service.cachedLimits(ctx)
+	if limit, ok := cached.limits[quota]; ok {
+		return limit
+	}
+	return service.defaults[quota]
+}
+
+// cachedLimits returns the limits of the tenant in ctx, reloading them once they are older than
+// quotaRefreshInterval. One request of the tenant reloads; the others keep the stale limits meanwhile, or wait
+// for the reload when the tenant has none yet, so neither other tenants nor the tenant queue up on the database.
+func (service *QuotaService) cachedLimits(ctx context.Context) cachedLimits {
+	tenantID, ok := tenancy.FromContext(ctx)
+	if !ok {
+		tenantID = tenancy.DefaultTenantID
+	}
+
+	service.mu.Lock()
+	cached, found := service.limits[tenantID]
+	if found && service.now().Sub(cached.loadedAt) < quotaRefreshInterval {
+		service.mu.Unlock()
+		return cached
+	}
+	load, inFlight := service.loads[tenantID]
+	if !inFlight {
+		load = &limitsLoad{done: make(chan struct{})}
+		service.loads[tenantID] = load
+	}
+	service.mu.Unlock()
+
+	if inFlight {
+		if found {
+			return cached
+		}
+		select {
+		case <-load.done:
+			return load.cached
+		case <-ctx.Done():
+			return cachedLimits{}
+		}
+	}
+
+	overrides, err := service.quotaRepo.ListQuotas(ctx)
+	if err != nil {
+		service.logger.Error(err)
+	}
+	if err == nil || !found {
+		cached = cachedLimits{limits: map[string]int{}, loadedAt: service.now()}
+		for _, override := range overrides {
+			cached.limits[override.Name] = override.Limit
+		}
+	}
+
+	service.mu.Lock()
+	if err == nil || !found {
+		service.limits[tenantID] = cached
+	}
+	delete(service.loads, tenantID)
+	service.mu.Unlock()
+	load.cached = cached
+	close(load.done)
+	return cached
+}
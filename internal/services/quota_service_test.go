@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestQuotaService(t *testing.T, quotaRepo *mocks.MockQuotaRepoInterface, defaults map[string]int) *QuotaService {
+	service := NewQuotaService(quotaRepo, defaults, zaptest.NewLogger(t).Sugar()).(*QuotaService)
+	service.now = func() time.Time { return time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func TestQuotaService_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	quotaRepo := mocks.NewMockQuotaRepoInterface(ctrl)
+	service := newTestQuotaService(t, quotaRepo, map[string]int{models.QuotaUsers: 2, models.QuotaVotesPerDay: 10})
+	ctx := context.Background()
+
+	quotaRepo.EXPECT().ListQuotas(gomock.Any()).Return(nil, nil).Times(1)
+	quotaRepo.EXPECT().CountUsers(gomock.Any()).Return(1, nil)
+	assert.NoError(t, service.Check(ctx, models.QuotaUsers, 0))
+	quotaRepo.EXPECT().CountUsers(gomock.Any()).Return(2, nil)
+	err := service.Check(ctx, models.QuotaUsers, 0)
+	assert.True(t, errors.Is(err, &apperrors.QuotaExceededErr), "got %v", err)
+
+	quotaRepo.EXPECT().CountVotesSince(gomock.Any(), time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)).Return(9, nil)
+	assert.NoError(t, service.Check(ctx, models.QuotaVotesPerDay, 0), "votes count since midnight UTC")
+	assert.NoError(t, service.Check(ctx, models.QuotaOrganizationMembers, 7), "unlimited quotas count nothing")
+}
+
+func TestQuotaService_TenantLimitsReplaceTheDefaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	quotaRepo := mocks.NewMockQuotaRepoInterface(ctrl)
+	service := newTestQuotaService(t, quotaRepo, map[string]int{models.QuotaUsers: 2})
+	acme := tenancy.WithTenant(context.Background(), 2)
+
+	quotaRepo.EXPECT().ListQuotas(gomock.Any()).Return([]models.TenantQuota{{TenantID: 2, Name: models.QuotaUsers, Limit: 0}}, nil).Times(1)
+	assert.NoError(t, service.Check(acme, models.QuotaUsers, 0), "0 lifts the default")
+	assert.NoError(t, service.Check(acme, models.QuotaUsers, 0), "the limits are cached")
+
+	quotaRepo.EXPECT().ListQuotas(gomock.Any()).Return(nil, errors.New("down"))
+	quotaRepo.EXPECT().CountUsers(gomock.Any()).Return(2, nil)
+	err := service.Check(context.Background(), models.QuotaUsers, 0)
+	assert.True(t, errors.Is(err, &apperrors.QuotaExceededErr), "the defaults apply when the limits cannot be read, got %v", err)
+}
+
+func TestQuotaService_ReloadsOutsideTheLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	quotaRepo := mocks.NewMockQuotaRepoInterface(ctrl)
+	service := newTestQuotaService(t, quotaRepo, map[string]int{models.QuotaUsers: 2})
+	slow, fast := tenancy.WithTenant(context.Background(), 2), tenancy.WithTenant(context.Background(), 3)
+
+	release := make(chan struct{})
+	quotaRepo.EXPECT().ListQuotas(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]models.TenantQuota, error) {
+		if tenantID, _ := tenancy.FromContext(ctx); tenantID == 2 {
+			<-release
+			return []models.TenantQuota{{TenantID: 2, Name: models.QuotaUsers, Limit: 5}}, nil
+		}
+		return nil, nil
+	}).Times(2)
+
+	var wg sync.WaitGroup
+	limits := make([]int, 3)
+	for i := range limits {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			limits[i] = service.limit(slow, models.QuotaUsers)
+		}(i)
+	}
+
+	// Another tenant is served while the first one's limits load
+	done := make(chan int)
+	go func() { done <- service.limit(fast, models.QuotaUsers) }()
+	select {
+	case limit := <-done:
+		assert.Equal(t, 2, limit)
+	case <-time.After(time.Second):
+		t.Fatal("a reload held up another tenant")
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{5, 5, 5}, limits, "requests of the tenant share one reload")
+}
+
+func TestQuotaService_CountRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	quotaRepo := mocks.NewMockQuotaRepoInterface(ctrl)
+	service := newTestQuotaService(t, quotaRepo, map[string]int{models.QuotaRequestsPerDay: 2})
+	ctx := context.Background()
+
+	quotaRepo.EXPECT().ListQuotas(gomock.Any()).Return(nil, nil)
+	gomock.InOrder(
+		quotaRepo.EXPECT().IncrementRequests(gomock.Any(), "2024-05-01").Return(2, nil),
+		quotaRepo.EXPECT().IncrementRequests(gomock.Any(), "2024-05-01").Return(3, nil),
+	)
+	assert.NoError(t, service.CountRequest(ctx), "the request reaching the limit is served")
+	err := service.CountRequest(ctx)
+	assert.True(t, errors.Is(err, &apperrors.QuotaExceededErr), "got %v", err)
+}
+
+func TestQuotaService_Usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	quotaRepo := mocks.NewMockQuotaRepoInterface(ctrl)
+	service := newTestQuotaService(t, quotaRepo, map[string]int{models.QuotaUsers: 100})
+
+	quotaRepo.EXPECT().ListQuotas(gomock.Any()).Return(nil, nil)
+	quotaRepo.EXPECT().CountUsers(gomock.Any()).Return(12, nil)
+	quotaRepo.EXPECT().CountVotesSince(gomock.Any(), gomock.Any()).Return(3, nil)
+	quotaRepo.EXPECT().CountRequests(gomock.Any(), "2024-05-01").Return(40, nil)
+	quotaRepo.EXPECT().LargestOrganization(gomock.Any()).Return(5, nil)
+
+	usage, err := service.Usage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []models.QuotaUsage{
+		{Name: models.QuotaUsers, Limit: 100, Used: 12},
+		{Name: models.QuotaVotesPerDay, Used: 3},
+		{Name: models.QuotaRequestsPerDay, Used: 40},
+		{Name: models.QuotaOrganizationMembers, Used: 5},
+	}, usage)
+}
+
+func TestQuotaUserService_ChecksBeforeCreating(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := NewMockUserServiceInterface(ctrl)
+	quotas := NewMockQuotaServiceInterface(ctrl)
+	service := NewQuotaUserService(userService, quotas)
+	ctx := context.Background()
+
+	quotas.EXPECT().Check(gomock.Any(), models.QuotaUsers, uint(0)).Return(apperrors.QuotaExceededErr.AppendMessage("users"))
+	_, err := service.CreateUser(ctx, &models.User{Email: "ann@example.com"})
+	assert.True(t, errors.Is(err, &apperrors.QuotaExceededErr), "got %v", err)
+
+	// Known emails are updated past the quota
+	known := &models.User{Email: "bob@example.com"}
+	userService.EXPECT().GetUserByEmail(gomock.Any(), "bob@example.com").Return(&models.User{ID: 5}, nil)
+	userService.EXPECT().CreateOrUpdateByEmail(gomock.Any(), known).Return(known, false, nil)
+	_, _, err = service.CreateOrUpdateByEmail(ctx, known)
+	assert.NoError(t, err)
+
+	quotas.EXPECT().Check(gomock.Any(), models.QuotaVotesPerDay, uint(0)).Return(nil)
+	userService.EXPECT().Vote(gomock.Any(), gomock.Any()).Return(uint(1), nil)
+	_, err = service.Vote(ctx, &models.Vote{UserID: 1, ProfileID: 2, Value: 1})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+)
+
+// QuotaUserService checks the users and votes_per_day quotas of the tenant before the wrapped service creates users
+// or votes. Two concurrent requests may both pass the check, so a quota can be exceeded by the requests in flight.
+type QuotaUserService struct {
+	UserServiceInterface
+	quotas QuotaServiceInterface
+}
+
+func NewQuotaUserService(userService UserServiceInterface, quotas QuotaServiceInterface) UserServiceInterface {
+	return &QuotaUserService{
+		UserServiceInterface: userService,
+		quotas:               quotas,
+	}
+}
+
+func (service *QuotaUserService) CreateUser(ctx context.Context, user *models.User) (uint, error) {
+	if err := service.quotas.Check(ctx, models.QuotaUsers, 0); err != nil {
+		return 0, err
+	}
+	return service.UserServiceInterface.CreateUser(ctx, user)
+}
+
+// CreateOrUpdateByEmail only checks the users quota when the email is new, so known users can still be updated
+func (service *QuotaUserService) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	_, err := service.UserServiceInterface.GetUserByEmail(ctx, user.Email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		err = service.quotas.Check(ctx, models.QuotaUsers, 0)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return service.UserServiceInterface.CreateOrUpdateByEmail(ctx, user)
+}
+
+func (service *QuotaUserService) Vote(ctx context.Context, vote *models.Vote) (uint, error) {
+	if err := service.quotas.Check(ctx, models.QuotaVotesPerDay, 0); err != nil {
+		return 0, err
+	}
+	return service.UserServiceInterface.Vote(ctx, vote)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// Why a remember-me series was revoked, in the details of its security event
+const (
+	rememberMeReused      = "reused"
+	rememberMeOtherDevice = "other_device"
+)
+
+type RememberService struct {
+	tokenRepo      repositories.RememberTokenRepoInterface
+	securityEvents SecurityEventServiceInterface
+	ttl            time.Duration
+	logger         *zap.SugaredLogger
+	now            func() time.Time
+}
+
+type RememberServiceInterface interface {
+	// Issue starts a remember-me series for the user on the device of the client in ctx and returns its first token
+	Issue(ctx context.Context, userID uint) (string, error)
+	// Use uses the token up and returns its user with the next token of its series. A used token, or one from
+	// another device, revokes the series as stolen and records it as a security event of the user.
+	Use(ctx context.Context, token string) (uint, string, error)
+	// Forget revokes the series of the token; an unknown token is forgotten already
+	Forget(ctx context.Context, token string) error
+	// ForgetAll revokes every series of the user
+	ForgetAll(ctx context.Context, userID uint) error
+}
+
+func NewRememberService(tokenRepo repositories.RememberTokenRepoInterface, securityEvents SecurityEventServiceInterface, ttl time.Duration, logger *zap.SugaredLogger) RememberServiceInterface {
+	return &RememberService{
+		tokenRepo:      tokenRepo,
+		securityEvents: securityEvents,
+		ttl:            ttl,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+func (service *RememberService) Issue(ctx context.Context, userID uint) (string, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	value, token, err := service.newToken(userID, familyID, deviceFingerprint(ctx))
+	if err != nil {
+		return "", err
+	}
+	if err := service.tokenRepo.CreateToken(ctx, token); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func (service *RememberService) Use(ctx context.Context, value string) (uint, string, error) {
+	token, err := service.tokenRepo.GetTokenByHash(ctx, hashToken(value))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return 0, "", &apperrors.RememberTokenInvalidErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	now := service.now()
+	if !now.Before(token.ExpiresAt) {
+		return 0, "", &apperrors.RememberTokenInvalidErr
+	}
+	if token.UsedAt != nil {
+		return 0, "", service.revoke(ctx, token, rememberMeReused)
+	}
+	fingerprint := deviceFingerprint(ctx)
+	if token.Fingerprint != fingerprint {
+		return 0, "", service.revoke(ctx, token, rememberMeOtherDevice)
+	}
+
+	nextValue, next, err := service.newToken(token.UserID, token.FamilyID, fingerprint)
+	if err != nil {
+		return 0, "", err
+	}
+	rotated, err := service.tokenRepo.RotateToken(ctx, token, next, now)
+	if err != nil {
+		return 0, "", err
+	}
+	// Another request used the token in the meantime
+	if !rotated {
+		return 0, "", service.revoke(ctx, token, rememberMeReused)
+	}
+	return token.UserID, nextValue, nil
+}
+
+func (service *RememberService) Forget(ctx context.Context, value string) error {
+	token, err := service.tokenRepo.GetTokenByHash(ctx, hashToken(value))
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return service.tokenRepo.DeleteFamily(ctx, token.FamilyID)
+}
+
+func (service *RememberService) ForgetAll(ctx context.Context, userID uint) error {
+	return service.tokenRepo.DeleteUserTokens(ctx, userID)
+}
+
+// revoke deletes the family of a stolen token, tells its user why and returns the error the request gets
+func (service *RememberService) revoke(ctx context.Context, token *models.RememberToken, why string) error {
+	if err := service.tokenRepo.DeleteFamily(ctx, token.FamilyID); err != nil {
+		return err
+	}
+	service.logger.Warnw("Revoked a stolen remember-me series", "user_id", token.UserID, "reason", why)
+	service.securityEvents.Record(ctx, token.UserID, models.SecurityEventRememberMeRevoked, why)
+	return &apperrors.RememberTokenInvalidErr
+}
+
+// newToken returns a token of the family for the user's device and its value, which is only kept hashed
+func (service *RememberService) newToken(userID uint, familyID, fingerprint string) (string, *models.RememberToken, error) {
+	value, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	now := service.now()
+	return value, &models.RememberToken{
+		UserID:      userID,
+		FamilyID:    familyID,
+		TokenHash:   hashToken(value),
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(service.ttl),
+		CreatedAt:   now,
+	}, nil
+}
+
+// deviceFingerprint is the blind index of the user agent of the client in ctx, as devices are told apart for login
+// alerts
+func deviceFingerprint(ctx context.Context) string {
+	userAgent := ""
+	if client, ok := ctx.Value(models.ClientContextKey).(*models.Client); ok {
+		userAgent = client.UserAgent
+	}
+	return pii.BlindIndex(userAgent)
+}
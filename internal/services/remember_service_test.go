@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRememberService_Rotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tokenRepo := mocks.NewMockRememberTokenRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	service := NewRememberService(tokenRepo, securityEvents, 30*24*time.Hour, zaptest.NewLogger(t).Sugar()).(*RememberService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	laptop := context.WithValue(context.Background(), models.ClientContextKey, &models.Client{IP: "203.0.113.7", UserAgent: "Firefox"})
+	phone := context.WithValue(context.Background(), models.ClientContextKey, &models.Client{IP: "203.0.113.7", UserAgent: "Safari"})
+
+	var issued *models.RememberToken
+	tokenRepo.EXPECT().CreateToken(laptop, gomock.Any()).DoAndReturn(func(_ context.Context, token *models.RememberToken) error {
+		issued = token
+		return nil
+	})
+	first, err := service.Issue(laptop, 2)
+	require.NoError(t, err)
+	assert.Equal(t, hashToken(first), issued.TokenHash, "only the hash is stored")
+	assert.Equal(t, now.Add(30*24*time.Hour), issued.ExpiresAt)
+	issued.ID = 1
+
+	// Using the token on its device rotates it within the family
+	tokenRepo.EXPECT().GetTokenByHash(laptop, hashToken(first)).Return(issued, nil)
+	var next *models.RememberToken
+	tokenRepo.EXPECT().RotateToken(laptop, issued, gomock.Any(), now).DoAndReturn(func(_ context.Context, _, token *models.RememberToken, _ time.Time) (bool, error) {
+		next = token
+		return true, nil
+	})
+	userID, second, err := service.Use(laptop, first)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), userID)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, hashToken(second), next.TokenHash)
+	assert.Equal(t, issued.FamilyID, next.FamilyID)
+
+	// Reusing the old token revokes the whole family as stolen
+	used := *issued
+	used.UsedAt = &now
+	tokenRepo.EXPECT().GetTokenByHash(laptop, hashToken(first)).Return(&used, nil)
+	tokenRepo.EXPECT().DeleteFamily(laptop, issued.FamilyID).Return(nil)
+	securityEvents.EXPECT().Record(laptop, uint(2), models.SecurityEventRememberMeRevoked, "reused")
+	_, _, err = service.Use(laptop, first)
+	assert.True(t, apperrors.Is(err, &apperrors.RememberTokenInvalidErr))
+
+	// So does the token turning up on another device
+	tokenRepo.EXPECT().GetTokenByHash(phone, hashToken(second)).Return(next, nil)
+	tokenRepo.EXPECT().DeleteFamily(phone, issued.FamilyID).Return(nil)
+	securityEvents.EXPECT().Record(phone, uint(2), models.SecurityEventRememberMeRevoked, "other_device")
+	_, _, err = service.Use(phone, second)
+	assert.True(t, apperrors.Is(err, &apperrors.RememberTokenInvalidErr))
+}
+
+func TestRememberService_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tokenRepo := mocks.NewMockRememberTokenRepoInterface(ctrl)
+	service := NewRememberService(tokenRepo, NewMockSecurityEventServiceInterface(ctrl), time.Hour, zaptest.NewLogger(t).Sugar()).(*RememberService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	tokenRepo.EXPECT().GetTokenByHash(ctx, hashToken("unknown")).Return(nil, repositories.ErrNotFound.AppendMessage("Remember-me token not found."))
+	_, _, err := service.Use(ctx, "unknown")
+	assert.True(t, apperrors.Is(err, &apperrors.RememberTokenInvalidErr))
+
+	tokenRepo.EXPECT().GetTokenByHash(ctx, hashToken("expired")).Return(&models.RememberToken{UserID: 2, ExpiresAt: now}, nil)
+	_, _, err = service.Use(ctx, "expired")
+	assert.True(t, apperrors.Is(err, &apperrors.RememberTokenInvalidErr))
+
+	// Forgetting an unknown token is not an error
+	tokenRepo.EXPECT().GetTokenByHash(ctx, hashToken("unknown")).Return(nil, repositories.ErrNotFound.AppendMessage("Remember-me token not found."))
+	assert.NoError(t, service.Forget(ctx, "unknown"))
+}
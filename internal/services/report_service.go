@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type ReportService struct {
+	reportRepo     repositories.ReportRepoInterface
+	alertRepo      repositories.LoginAlertRepoInterface
+	auditRepo      repositories.AdminAuditRepoInterface
+	rememberTokens repositories.RememberTokenRepoInterface
+	adminAudit     AdminAuditServiceInterface
+	userService    UserServiceInterface
+	securityEvents SecurityEventServiceInterface
+	txManager      repositories.TxManagerInterface
+	logger         *zap.SugaredLogger
+	now            func() time.Time
+}
+
+type ReportServiceInterface interface {
+	// Report files the report in the moderation queue; a reporter has one pending report per user at most
+	Report(ctx context.Context, report *models.Report) (*models.Report, error)
+	// ListReports returns a page of the reports matching the query, oldest first
+	ListReports(ctx context.Context, query *models.ReportQuery, page, pageSize int) (*models.ReportPage, error)
+	// Resolve resolves the pending report with action on behalf of a moderator. Dismissing only resolves the
+	// report; suspending locks the reported user out like a denied login, deleting deletes them, and both record
+	// the action in the admin audit and resolve every pending report on the user.
+	Resolve(ctx context.Context, by *models.AdminAction, id uint64, action string) (*models.Report, error)
+}
+
+func NewReportService(reportRepo repositories.ReportRepoInterface, alertRepo repositories.LoginAlertRepoInterface, auditRepo repositories.AdminAuditRepoInterface, rememberTokens repositories.RememberTokenRepoInterface, adminAudit AdminAuditServiceInterface, userService UserServiceInterface, securityEvents SecurityEventServiceInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) ReportServiceInterface {
+	return &ReportService{
+		reportRepo:     reportRepo,
+		alertRepo:      alertRepo,
+		auditRepo:      auditRepo,
+		rememberTokens: rememberTokens,
+		adminAudit:     adminAudit,
+		userService:    userService,
+		securityEvents: securityEvents,
+		txManager:      txManager,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+func (service *ReportService) Report(ctx context.Context, report *models.Report) (*models.Report, error) {
+	if report.ReporterID == report.UserID {
+		return nil, apperrors.BadRequestErr.AppendMessage("you cannot report yourself")
+	}
+	if _, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(report.UserID), 10)); err != nil {
+		return nil, err
+	}
+	pending, err := service.reportRepo.HasPendingReport(ctx, report.ReporterID, report.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, &apperrors.ReportPendingErr
+	}
+
+	report.Status = models.ReportStatusPending
+	if err := service.reportRepo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (service *ReportService) ListReports(ctx context.Context, query *models.ReportQuery, page, pageSize int) (*models.ReportPage, error) {
+	reports, total, err := service.reportRepo.ListReports(ctx, query, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if reports == nil {
+		reports = []models.Report{}
+	}
+	return &models.ReportPage{
+		Data:       reports,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
+
+func (service *ReportService) Resolve(ctx context.Context, by *models.AdminAction, id uint64, action string) (*models.Report, error) {
+	report, err := service.reportRepo.GetReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusPending {
+		return nil, &apperrors.ReportResolvedErr
+	}
+
+	now := service.now()
+	resolution := &models.ReportResolution{
+		Status:     models.ReportStatusActioned,
+		Action:     action,
+		ResolvedBy: by.ActorID,
+		ResolvedAt: now,
+	}
+	switch action {
+	case models.ReportActionDismiss:
+		resolution.Status = models.ReportStatusDismissed
+		err = service.resolve(ctx, report.ID, resolution)
+	case models.ReportActionSuspend:
+		err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := service.alertRepo.CreateLock(ctx, &models.AccountLock{
+				UserID:   report.UserID,
+				Reason:   models.LockReasonSuspended,
+				LockedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			if err := service.rememberTokens.DeleteUserTokens(ctx, report.UserID); err != nil {
+				return err
+			}
+			err = service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+				ActorID:  by.ActorID,
+				Action:   models.AdminAuditUserSuspended,
+				TargetID: report.UserID,
+				Reason:   by.Reason,
+				Details:  reportDetails(report),
+			})
+			if err != nil {
+				return err
+			}
+			_, err = service.reportRepo.ResolvePendingReports(ctx, report.UserID, resolution)
+			return err
+		})
+	case models.ReportActionDelete:
+		err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := service.adminAudit.DeleteUser(ctx, by, strconv.FormatUint(uint64(report.UserID), 10)); err != nil {
+				return err
+			}
+			_, err := service.reportRepo.ResolvePendingReports(ctx, report.UserID, resolution)
+			return err
+		})
+	default:
+		return nil, apperrors.BadRequestErr.AppendMessage("unknown action " + action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if action == models.ReportActionSuspend {
+		service.securityEvents.Record(ctx, report.UserID, models.SecurityEventAccountLocked, models.LockReasonSuspended)
+	}
+
+	return service.reportRepo.GetReport(ctx, report.ID)
+}
+
+// resolve resolves the report, ReportResolvedErr when a moderator resolved it in the meantime
+func (service *ReportService) resolve(ctx context.Context, id uint64, resolution *models.ReportResolution) error {
+	resolved, err := service.reportRepo.ResolveReport(ctx, id, resolution)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return &apperrors.ReportResolvedErr
+	}
+	return nil
+}
+
+// reportDetails names the report an action was taken on in the admin audit, without its reporter or details
+func reportDetails(report *models.Report) string {
+	return fmt.Sprintf("report %d: %s", report.ID, report.Reason)
+}
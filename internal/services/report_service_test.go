@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestReportService_Report(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reportRepo := mocks.NewMockReportRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := NewReportService(reportRepo, nil, nil, nil, nil, userService, nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	_, err := service.Report(ctx, &models.Report{ReporterID: 1, UserID: 1, Reason: models.ReportReasonSpam})
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), "users cannot report themselves")
+
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2}, nil).Times(2)
+	reportRepo.EXPECT().HasPendingReport(ctx, uint(1), uint(2)).Return(true, nil)
+	_, err = service.Report(ctx, &models.Report{ReporterID: 1, UserID: 2, Reason: models.ReportReasonSpam})
+	assert.True(t, apperrors.Is(err, &apperrors.ReportPendingErr))
+
+	reportRepo.EXPECT().HasPendingReport(ctx, uint(1), uint(2)).Return(false, nil)
+	reportRepo.EXPECT().CreateReport(ctx, gomock.Any()).Return(nil)
+	report, err := service.Report(ctx, &models.Report{ReporterID: 1, UserID: 2, Reason: models.ReportReasonSpam})
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportStatusPending, report.Status)
+}
+
+func TestReportService_ResolveSuspendLocksTheUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reportRepo := mocks.NewMockReportRepoInterface(ctrl)
+	alertRepo := mocks.NewMockLoginAlertRepoInterface(ctrl)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	rememberTokens := mocks.NewMockRememberTokenRepoInterface(ctrl)
+	securityEvents := NewMockSecurityEventServiceInterface(ctrl)
+	service := NewReportService(reportRepo, alertRepo, auditRepo, rememberTokens, nil, nil, securityEvents, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar()).(*ReportService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	by := &models.AdminAction{ActorID: 3, Reason: "Repeated harassment"}
+
+	report := &models.Report{ID: 7, ReporterID: 1, UserID: 2, Reason: models.ReportReasonHarassment, Status: models.ReportStatusPending}
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(report, nil)
+	alertRepo.EXPECT().CreateLock(ctx, &models.AccountLock{UserID: 2, Reason: models.LockReasonSuspended, LockedAt: now}).Return(nil)
+	rememberTokens.EXPECT().DeleteUserTokens(ctx, uint(2)).Return(nil)
+	auditRepo.EXPECT().CreateAdminAudit(ctx, &models.AdminAudit{
+		ActorID:  3,
+		Action:   models.AdminAuditUserSuspended,
+		TargetID: 2,
+		Reason:   "Repeated harassment",
+		Details:  "report 7: harassment",
+	}).Return(nil)
+	reportRepo.EXPECT().ResolvePendingReports(ctx, uint(2), &models.ReportResolution{
+		Status:     models.ReportStatusActioned,
+		Action:     models.ReportActionSuspend,
+		ResolvedBy: 3,
+		ResolvedAt: now,
+	}).Return(2, nil)
+	securityEvents.EXPECT().Record(ctx, uint(2), models.SecurityEventAccountLocked, models.LockReasonSuspended)
+	resolved := &models.Report{ID: 7, UserID: 2, Status: models.ReportStatusActioned, Action: models.ReportActionSuspend}
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(resolved, nil)
+
+	got, err := service.Resolve(ctx, by, 7, models.ReportActionSuspend)
+	require.NoError(t, err)
+	assert.Equal(t, resolved, got)
+}
+
+func TestReportService_ResolveDeleteDeletesTheUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reportRepo := mocks.NewMockReportRepoInterface(ctrl)
+	adminAudit := NewMockAdminAuditServiceInterface(ctrl)
+	service := NewReportService(reportRepo, nil, nil, nil, adminAudit, nil, nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	by := &models.AdminAction{ActorID: 3}
+
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(&models.Report{ID: 7, UserID: 2, Status: models.ReportStatusPending}, nil)
+	adminAudit.EXPECT().DeleteUser(ctx, by, "2").Return(&models.User{ID: 2}, nil)
+	reportRepo.EXPECT().ResolvePendingReports(ctx, uint(2), gomock.Any()).DoAndReturn(func(ctx context.Context, userID uint, resolution *models.ReportResolution) (int, error) {
+		assert.Equal(t, models.ReportStatusActioned, resolution.Status)
+		assert.Equal(t, models.ReportActionDelete, resolution.Action)
+		return 1, nil
+	})
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(&models.Report{ID: 7, Status: models.ReportStatusActioned}, nil)
+
+	_, err := service.Resolve(ctx, by, 7, models.ReportActionDelete)
+	require.NoError(t, err)
+}
+
+func TestReportService_ResolveDismiss(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reportRepo := mocks.NewMockReportRepoInterface(ctrl)
+	service := NewReportService(reportRepo, nil, nil, nil, nil, nil, nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+	by := &models.AdminAction{ActorID: 3}
+
+	reportRepo.EXPECT().GetReport(ctx, uint64(8)).Return(&models.Report{ID: 8, Status: models.ReportStatusDismissed}, nil)
+	_, err := service.Resolve(ctx, by, 8, models.ReportActionDismiss)
+	assert.True(t, apperrors.Is(err, &apperrors.ReportResolvedErr))
+
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(&models.Report{ID: 7, Status: models.ReportStatusPending}, nil)
+	reportRepo.EXPECT().ResolveReport(ctx, uint64(7), gomock.Any()).Return(false, nil)
+	_, err = service.Resolve(ctx, by, 7, models.ReportActionDismiss)
+	assert.True(t, apperrors.Is(err, &apperrors.ReportResolvedErr), "another moderator resolved it first")
+
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(&models.Report{ID: 7, Status: models.ReportStatusPending}, nil)
+	reportRepo.EXPECT().ResolveReport(ctx, uint64(7), gomock.Any()).DoAndReturn(func(ctx context.Context, id uint64, resolution *models.ReportResolution) (bool, error) {
+		assert.Equal(t, models.ReportStatusDismissed, resolution.Status)
+		return true, nil
+	})
+	reportRepo.EXPECT().GetReport(ctx, uint64(7)).Return(&models.Report{ID: 7, Status: models.ReportStatusDismissed}, nil)
+	report, err := service.Resolve(ctx, by, 7, models.ReportActionDismiss)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportStatusDismissed, report.Status)
+}
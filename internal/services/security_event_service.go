@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type SecurityEventService struct {
+	eventRepo repositories.SecurityEventRepoInterface
+	logger    *zap.SugaredLogger
+}
+
+type SecurityEventServiceInterface interface {
+	// Record stores an event of the user with the client of the request in ctx. A failure is logged rather than
+	// returned: the action the event records has already happened.
+	Record(ctx context.Context, userID uint, eventType, details string)
+	ListEvents(ctx context.Context, query *models.SecurityEventQuery) ([]models.SecurityEvent, error)
+}
+
+func NewSecurityEventService(eventRepo repositories.SecurityEventRepoInterface, logger *zap.SugaredLogger) SecurityEventServiceInterface {
+	return &SecurityEventService{
+		eventRepo: eventRepo,
+		logger:    logger,
+	}
+}
+
+func (service *SecurityEventService) Record(ctx context.Context, userID uint, eventType, details string) {
+	event := &models.SecurityEvent{UserID: userID, Type: eventType, Details: details}
+	if client, ok := ctx.Value(models.ClientContextKey).(*models.Client); ok {
+		event.IP, event.UserAgent = client.IP, client.UserAgent
+	}
+	if err := service.eventRepo.CreateSecurityEvent(ctx, event); err != nil {
+		service.logger.Errorw("Failed to record a security event", "user_id", userID, "type", eventType, "error", err)
+	}
+}
+
+func (service *SecurityEventService) ListEvents(ctx context.Context, query *models.SecurityEventQuery) ([]models.SecurityEvent, error) {
+	return service.eventRepo.ListSecurityEvents(ctx, query)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSecurityEventService_RecordsTheClientOfTheRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eventRepo := mocks.NewMockSecurityEventRepoInterface(ctrl)
+	service := NewSecurityEventService(eventRepo, zaptest.NewLogger(t).Sugar())
+
+	ctx := context.WithValue(context.Background(), models.ClientContextKey, &models.Client{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+	eventRepo.EXPECT().CreateSecurityEvent(ctx, &models.SecurityEvent{
+		UserID:    1,
+		Type:      models.SecurityEventTokenRevoked,
+		IP:        "203.0.113.7",
+		UserAgent: "curl/8.0",
+		Details:   "dashboard",
+	}).Return(nil)
+	service.Record(ctx, 1, models.SecurityEventTokenRevoked, "dashboard")
+
+	var recorded *models.SecurityEvent
+	eventRepo.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *models.SecurityEvent) error {
+		recorded = event
+		return errors.New("database is down")
+	})
+	assert.NotPanics(t, func() { service.Record(context.Background(), 1, models.SecurityEventLogin, "") })
+	assert.Empty(t, recorded.IP, "requests from no client, e.g. jobs, are recorded without one")
+}
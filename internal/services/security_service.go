@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/pii"
+	"gitlab.com/jkozhemiaka/web-layout/internal/ratelimit"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+type SecurityService struct {
+	incidentRepo repositories.SecurityIncidentRepoInterface
+	counter      ratelimit.Counter
+	thresholds   map[string]int
+	emitter      *events.Emitter
+	txManager    repositories.TxManagerInterface
+	mailer       mail.Mailer
+	templates    *mail.Templates
+	alertEmails  []string
+	logger       *zap.SugaredLogger
+}
+
+type SecurityServiceInterface interface {
+	// RecordFailedLogin counts the login against its address, account and user agent, and raises an incident for
+	// each of them that reaches its threshold
+	RecordFailedLogin(ctx context.Context, login *models.FailedLogin) error
+	ListIncidents(ctx context.Context, signal string, limit int) ([]models.SecurityIncident, error)
+}
+
+// NewSecurityService raises an incident once a signal, keyed by models.Signal*, has thresholds failed logins in
+// one window of counter; a signal without a threshold is not counted. Incidents are logged, emitted and mailed to
+// alertEmails with mailer, which is expected to queue the mail (jobs.QueuedMailer) rather than send it.
+func NewSecurityService(incidentRepo repositories.SecurityIncidentRepoInterface, counter ratelimit.Counter, thresholds map[string]int, emitter *events.Emitter, txManager repositories.TxManagerInterface, mailer mail.Mailer, templates *mail.Templates, alertEmails []string, logger *zap.SugaredLogger) SecurityServiceInterface {
+	return &SecurityService{
+		incidentRepo: incidentRepo,
+		counter:      counter,
+		thresholds:   thresholds,
+		emitter:      emitter,
+		txManager:    txManager,
+		mailer:       mailer,
+		templates:    templates,
+		alertEmails:  alertEmails,
+		logger:       logger,
+	}
+}
+
+func (service *SecurityService) RecordFailedLogin(ctx context.Context, login *models.FailedLogin) error {
+	// Emails and user agents are counted by blind index, which keeps them out of Redis and bounds the key
+	signals := []struct {
+		name, subject, key string
+	}{
+		{models.SignalIP, login.IP, login.IP},
+		{models.SignalAccount, models.NormalizeEmail(login.Email), models.EmailBlindIndex(login.Email)},
+		{models.SignalUserAgent, login.UserAgent, pii.BlindIndex(login.UserAgent)},
+	}
+
+	var errs []error
+	for _, signal := range signals {
+		threshold := service.thresholds[signal.name]
+		if threshold <= 0 {
+			continue
+		}
+		failures, err := service.counter.Count(ctx, tenancy.KeyPrefix(ctx)+signal.name+":"+signal.key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// Only the failure that reaches the threshold raises the incident, so an attack is reported once a window
+		if failures != threshold {
+			continue
+		}
+		err = service.raise(ctx, &models.SecurityIncident{Signal: signal.name, Subject: signal.subject, Failures: failures})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// raise stores the incident together with the mail.send job alerting the alert addresses, so the failing login
+// never waits on the mail provider, then logs the incident and emits it for the webhook
+func (service *SecurityService) raise(ctx context.Context, incident *models.SecurityIncident) error {
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.incidentRepo.CreateSecurityIncident(ctx, incident); err != nil {
+			return err
+		}
+		if len(service.alertEmails) == 0 {
+			return nil
+		}
+		msg, err := service.templates.Render(mail.TemplateSecurityIncident, i18n.Fallback(), incident, service.alertEmails...)
+		if err != nil {
+			return err
+		}
+		return service.mailer.Send(ctx, msg)
+	})
+	if err != nil {
+		return err
+	}
+	service.logger.Warnw("Brute-force attempt detected", "incident_id", incident.ID, "signal", incident.Signal, "failures", incident.Failures)
+
+	// The event names the incident only; its subject is personal data, served to admins by the API
+	type incidentEventData struct {
+		IncidentID uint64 `json:"incident_id"`
+		Signal     string `json:"signal"`
+		Failures   int    `json:"failures"`
+	}
+	subject := strconv.FormatUint(incident.ID, 10)
+	service.emitter.Emit(ctx, events.SecurityIncidentRaised, subject, &incidentEventData{
+		IncidentID: incident.ID,
+		Signal:     incident.Signal,
+		Failures:   incident.Failures,
+	})
+	return nil
+}
+
+func (service *SecurityService) ListIncidents(ctx context.Context, signal string, limit int) ([]models.SecurityIncident, error) {
+	return service.incidentRepo.ListSecurityIncidents(ctx, signal, limit)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
+	"gitlab.com/jkozhemiaka/web-layout/internal/mail"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeCounter counts in one window that never ends
+type fakeCounter map[string]int
+
+func (c fakeCounter) Count(_ context.Context, key string) (int, error) {
+	c[key]++
+	return c[key], nil
+}
+
+func TestSecurityService_RaisesAnIncidentOncePerSignal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	incidentRepo := mocks.NewMockSecurityIncidentRepoInterface(ctrl)
+	templates, err := mail.LoadTemplates("")
+	require.NoError(t, err)
+	logger := zaptest.NewLogger(t).Sugar()
+	sink := &recordingSink{}
+	var sent sentMail
+	thresholds := map[string]int{models.SignalIP: 3, models.SignalAccount: 2, models.SignalUserAgent: 0}
+	service := NewSecurityService(incidentRepo, fakeCounter{}, thresholds, events.NewEmitter("urn:test", sink, logger), newInlineTxManager(ctrl), &sent, templates, []string{"security@example.com"}, logger)
+
+	var raised []*models.SecurityIncident
+	incidentRepo.EXPECT().CreateSecurityIncident(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, incident *models.SecurityIncident) error {
+		incident.ID = uint64(len(raised) + 1)
+		raised = append(raised, incident)
+		return nil
+	}).Times(2)
+
+	ctx := context.Background()
+	for _, email := range []string{"ann@example.com", "Ann@Example.com", "bob@example.com", "ann@example.com", "carl@example.com"} {
+		require.NoError(t, service.RecordFailedLogin(ctx, &models.FailedLogin{IP: "203.0.113.7", Email: email, UserAgent: "curl/8.0"}))
+	}
+
+	require.Len(t, raised, 2, "each signal is raised when it reaches its threshold, not after")
+	assert.Equal(t, models.SignalAccount, raised[0].Signal)
+	assert.Equal(t, "ann@example.com", raised[0].Subject)
+	assert.Equal(t, 2, raised[0].Failures)
+	assert.Equal(t, models.SignalIP, raised[1].Signal)
+	assert.Equal(t, "203.0.113.7", raised[1].Subject)
+
+	require.Len(t, sink.published, 2)
+	assert.Equal(t, events.SecurityIncidentRaised, sink.published[1].Type)
+	assert.Equal(t, "2", sink.published[1].Subject)
+	assert.NotContains(t, string(sink.published[0].Data), "ann@example.com", "events leave the subject out")
+
+	require.Len(t, sent, 2)
+	assert.Equal(t, []string{"security@example.com"}, sent[1].To)
+	assert.Contains(t, sent[1].Text, "203.0.113.7")
+}
+
+// queueingMailer fails to queue or records whether the mail was queued inside the incident's transaction
+type queueingMailer struct {
+	err         error
+	inTx        bool
+	queuedCount int
+}
+
+type txKey struct{}
+
+func (m *queueingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.queuedCount++
+	m.inTx, _ = ctx.Value(txKey{}).(bool)
+	return nil
+}
+
+func TestSecurityService_QueuesTheAlertWithTheIncident(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	incidentRepo := mocks.NewMockSecurityIncidentRepoInterface(ctrl)
+	txManager := mocks.NewMockTxManagerInterface(ctrl)
+	txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, true))
+		}).Times(2)
+	templates, err := mail.LoadTemplates("")
+	require.NoError(t, err)
+	logger := zaptest.NewLogger(t).Sugar()
+	sink := &recordingSink{}
+	mailer := &queueingMailer{}
+	service := NewSecurityService(incidentRepo, fakeCounter{}, map[string]int{models.SignalIP: 1}, events.NewEmitter("urn:test", sink, logger), txManager, mailer, templates, []string{"security@example.com"}, logger)
+
+	incidentRepo.EXPECT().CreateSecurityIncident(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	ctx := context.Background()
+	require.NoError(t, service.RecordFailedLogin(ctx, &models.FailedLogin{IP: "203.0.113.7"}))
+	assert.Equal(t, 1, mailer.queuedCount)
+	assert.True(t, mailer.inTx, "the alert is queued in the transaction that stores the incident")
+	assert.Len(t, sink.published, 1)
+
+	// An alert that can not be queued fails the incident, which is then not emitted either
+	mailer.err = errors.New("jobs table unavailable")
+	assert.ErrorContains(t, service.RecordFailedLogin(ctx, &models.FailedLogin{IP: "203.0.113.8"}), "jobs table unavailable")
+	assert.Len(t, sink.published, 1)
+}
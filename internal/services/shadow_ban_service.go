@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type ShadowBanService struct {
+	shadowBanRepo repositories.ShadowBanRepoInterface
+	auditRepo     repositories.AdminAuditRepoInterface
+	userService   UserServiceInterface
+	txManager     repositories.TxManagerInterface
+	logger        *zap.SugaredLogger
+	now           func() time.Time
+}
+
+type ShadowBanServiceInterface interface {
+	// ShadowBan shadow-bans the user on behalf of an admin and records it in the admin audit. Shadow-banning a
+	// shadow-banned user changes nothing. Unlike a ban, no security event tells the user.
+	ShadowBan(ctx context.Context, by *models.AdminAction, userID uint) error
+	// Lift lifts the shadow ban of the user and records it in the admin audit
+	Lift(ctx context.Context, by *models.AdminAction, userID uint) error
+	IsShadowBanned(ctx context.Context, userID uint) (bool, error)
+	// ListShadowBans returns a page of the shadow bans, latest first
+	ListShadowBans(ctx context.Context, page, pageSize int) (*models.ShadowBanPage, error)
+}
+
+func NewShadowBanService(shadowBanRepo repositories.ShadowBanRepoInterface, auditRepo repositories.AdminAuditRepoInterface, userService UserServiceInterface, txManager repositories.TxManagerInterface, logger *zap.SugaredLogger) ShadowBanServiceInterface {
+	return &ShadowBanService{
+		shadowBanRepo: shadowBanRepo,
+		auditRepo:     auditRepo,
+		userService:   userService,
+		txManager:     txManager,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+func (service *ShadowBanService) ShadowBan(ctx context.Context, by *models.AdminAction, userID uint) error {
+	if userID == by.ActorID {
+		return apperrors.BadRequestErr.AppendMessage("you cannot shadow-ban yourself")
+	}
+	if _, err := service.userService.GetUser(ctx, strconv.FormatUint(uint64(userID), 10)); err != nil {
+		return err
+	}
+
+	return service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err := service.shadowBanRepo.CreateShadowBan(ctx, &models.ShadowBan{
+			UserID:    userID,
+			CreatedBy: by.ActorID,
+			CreatedAt: service.now(),
+		})
+		if err != nil || !created {
+			return err
+		}
+		return service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+			ActorID:  by.ActorID,
+			Action:   models.AdminAuditUserShadowBanned,
+			TargetID: userID,
+			Reason:   by.Reason,
+		})
+	})
+}
+
+func (service *ShadowBanService) Lift(ctx context.Context, by *models.AdminAction, userID uint) error {
+	return service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := service.shadowBanRepo.DeleteShadowBan(ctx, userID); err != nil {
+			return err
+		}
+		return service.auditRepo.CreateAdminAudit(ctx, &models.AdminAudit{
+			ActorID:  by.ActorID,
+			Action:   models.AdminAuditUserShadowUnbanned,
+			TargetID: userID,
+			Reason:   by.Reason,
+		})
+	})
+}
+
+func (service *ShadowBanService) IsShadowBanned(ctx context.Context, userID uint) (bool, error) {
+	return service.shadowBanRepo.IsShadowBanned(ctx, userID)
+}
+
+func (service *ShadowBanService) ListShadowBans(ctx context.Context, page, pageSize int) (*models.ShadowBanPage, error) {
+	bans, total, err := service.shadowBanRepo.ListShadowBans(ctx, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if bans == nil {
+		bans = []models.ShadowBan{}
+	}
+	return &models.ShadowBanPage{
+		Data:       bans,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
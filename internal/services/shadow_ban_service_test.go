@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestShadowBanService_ShadowBan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	shadowBanRepo := mocks.NewMockShadowBanRepoInterface(ctrl)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	userService := NewMockUserServiceInterface(ctrl)
+	service := NewShadowBanService(shadowBanRepo, auditRepo, userService, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar()).(*ShadowBanService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	by := &models.AdminAction{ActorID: 3, Reason: "Vote ring"}
+
+	err := service.ShadowBan(ctx, by, 3)
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), "admins cannot shadow-ban themselves")
+
+	userService.EXPECT().GetUser(ctx, "2").Return(&models.User{ID: 2}, nil).Times(2)
+	shadowBanRepo.EXPECT().CreateShadowBan(ctx, &models.ShadowBan{UserID: 2, CreatedBy: 3, CreatedAt: now}).Return(true, nil)
+	auditRepo.EXPECT().CreateAdminAudit(ctx, &models.AdminAudit{
+		ActorID:  3,
+		Action:   models.AdminAuditUserShadowBanned,
+		TargetID: 2,
+		Reason:   "Vote ring",
+	}).Return(nil)
+	require.NoError(t, service.ShadowBan(ctx, by, 2))
+
+	// Shadow-banning again is not audited a second time
+	shadowBanRepo.EXPECT().CreateShadowBan(ctx, &models.ShadowBan{UserID: 2, CreatedBy: 3, CreatedAt: now}).Return(false, nil)
+	require.NoError(t, service.ShadowBan(ctx, by, 2))
+}
+
+func TestShadowBanService_Lift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	shadowBanRepo := mocks.NewMockShadowBanRepoInterface(ctrl)
+	auditRepo := mocks.NewMockAdminAuditRepoInterface(ctrl)
+	service := NewShadowBanService(shadowBanRepo, auditRepo, nil, newInlineTxManager(ctrl), zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	shadowBanRepo.EXPECT().DeleteShadowBan(ctx, uint(2)).Return(nil)
+	auditRepo.EXPECT().CreateAdminAudit(ctx, &models.AdminAudit{
+		ActorID:  3,
+		Action:   models.AdminAuditUserShadowUnbanned,
+		TargetID: 2,
+		Reason:   "Appeal",
+	}).Return(nil)
+	require.NoError(t, service.Lift(ctx, &models.AdminAction{ActorID: 3, Reason: "Appeal"}, 2))
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+)
+
+// SignupRoles is the ID of the role new users get, by signup source
+type SignupRoles map[string]uint
+
+// ResolveSignupRoles looks up defaultRole, and the role of every source in bySource, in the roles table. A role
+// that does not exist, an unknown source or the admin role is an error, so a typo fails the startup rather than
+// the first signup.
+func ResolveSignupRoles(ctx context.Context, roleRepo repositories.RoleRepoInterface, defaultRole string, bySource map[string]string) (SignupRoles, error) {
+	for source := range bySource {
+		if !isSignupSource(source) {
+			return nil, fmt.Errorf("SIGNUP_ROLES names unknown signup source %q, expected one of %v", source, models.SignupSources)
+		}
+	}
+
+	roles := make(SignupRoles, len(models.SignupSources))
+	for _, source := range models.SignupSources {
+		name := defaultRole
+		if bySource[source] != "" {
+			name = bySource[source]
+		}
+		if name == models.StrAdmin {
+			return nil, fmt.Errorf("signups from %s would get the %s role", source, models.StrAdmin)
+		}
+
+		role, err := roleRepo.GetRoleByName(ctx, name)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("the %s role for signups from %s does not exist", name, source)
+		}
+		if err != nil {
+			return nil, err
+		}
+		roles[source] = role.ID
+	}
+	return roles, nil
+}
+
+// RoleID returns the role for users signing up from source
+func (roles SignupRoles) RoleID(source string) uint {
+	return roles[source]
+}
+
+func isSignupSource(source string) bool {
+	for _, known := range models.SignupSources {
+		if source == known {
+			return true
+		}
+	}
+	return false
+}
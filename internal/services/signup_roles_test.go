@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+)
+
+func TestResolveSignupRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	roleRepo := mocks.NewMockRoleRepoInterface(ctrl)
+	roleRepo.EXPECT().GetRoleByName(gomock.Any(), models.StrUser).Return(&models.Role{ID: 1, Name: models.StrUser}, nil).AnyTimes()
+	roleRepo.EXPECT().GetRoleByName(gomock.Any(), models.StrModerator).Return(&models.Role{ID: 2, Name: models.StrModerator}, nil).AnyTimes()
+	roleRepo.EXPECT().GetRoleByName(gomock.Any(), "editor").Return(nil, repositories.ErrNotFound).AnyTimes()
+	ctx := context.Background()
+
+	roles, err := ResolveSignupRoles(ctx, roleRepo, models.StrUser, map[string]string{models.SignupSSO: models.StrModerator, models.SignupPhone: models.StrModerator})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), roles.RoleID(models.SignupSelf))
+	assert.Equal(t, uint(2), roles.RoleID(models.SignupSSO))
+	assert.Equal(t, uint(1), roles.RoleID(models.SignupOAuth))
+	assert.Equal(t, uint(2), roles.RoleID(models.SignupPhone))
+
+	_, err = ResolveSignupRoles(ctx, roleRepo, "editor", nil)
+	assert.EqualError(t, err, "the editor role for signups from self does not exist")
+	_, err = ResolveSignupRoles(ctx, roleRepo, models.StrUser, map[string]string{"ldap": models.StrUser})
+	assert.ErrorContains(t, err, `unknown signup source "ldap"`)
+	_, err = ResolveSignupRoles(ctx, roleRepo, models.StrUser, map[string]string{models.SignupSSO: models.StrAdmin})
+	assert.EqualError(t, err, "signups from sso would get the admin role")
+	_, err = ResolveSignupRoles(ctx, roleRepo, models.StrUser, map[string]string{models.SignupOAuth: "editor"})
+	assert.EqualError(t, err, "the editor role for signups from oauth does not exist")
+}
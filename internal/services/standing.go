@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+)
+
+// CheckStanding returns AccountLockedErr for a locked user, locked by a denied login alert or a suspension, and
+// UserBannedErr for a banned one. Signing in, tokens, keys and OAuth grants all check it, so no credential the user
+// held before outlasts the lock or ban.
+func CheckStanding(ctx context.Context, loginAlerts LoginAlertServiceInterface, bans BanServiceInterface, userID uint) error {
+	locked, err := loginAlerts.Locked(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return &apperrors.AccountLockedErr
+	}
+	ban, err := bans.ActiveBan(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if ban != nil {
+		return apperrors.UserBannedErr.AppendMessage(ban.Reason + ", until " + ban.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
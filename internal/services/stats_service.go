@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type StatsService struct {
+	statsRepo repositories.StatsRepoInterface
+	logger    *zap.SugaredLogger
+}
+
+type StatsServiceInterface interface {
+	// Stats counts users as active when they signed in within activeWindow
+	Stats(ctx context.Context, activeWindow time.Duration) (*models.Stats, error)
+}
+
+func NewStatsService(statsRepo repositories.StatsRepoInterface, logger *zap.SugaredLogger) StatsServiceInterface {
+	return &StatsService{
+		statsRepo: statsRepo,
+		logger:    logger,
+	}
+}
+
+func (service *StatsService) Stats(ctx context.Context, activeWindow time.Duration) (*models.Stats, error) {
+	now := time.Now().UTC()
+	return service.statsRepo.GetStats(ctx, now, now.Add(-activeWindow))
+}
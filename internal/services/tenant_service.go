@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+type TenantService struct {
+	tenantRepo repositories.TenantRepoInterface
+	logger     *zap.SugaredLogger
+}
+
+type TenantServiceInterface interface {
+	// ResolveTenant finds the tenant named by slug, or else the one served on host,
+	// falling back to the default tenant when the host is not assigned to any
+	ResolveTenant(ctx context.Context, slug string, host string) (*models.Tenant, error)
+}
+
+func NewTenantService(tenantRepo repositories.TenantRepoInterface, logger *zap.SugaredLogger) TenantServiceInterface {
+	return &TenantService{
+		tenantRepo: tenantRepo,
+		logger:     logger,
+	}
+}
+
+func (service *TenantService) ResolveTenant(ctx context.Context, slug string, host string) (*models.Tenant, error) {
+	if slug != "" {
+		return service.tenantRepo.GetTenantBySlug(ctx, slug)
+	}
+
+	tenant, err := service.tenantRepo.GetTenantByHost(ctx, host)
+	if apperrors.Is(err, &apperrors.NoRecordFoundErr) {
+		return &models.Tenant{ID: tenancy.DefaultTenantID}, nil
+	}
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return tenant, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	"go.uber.org/zap"
+)
+
+type TermsService struct {
+	termsRepo repositories.TermsRepoInterface
+	logger    *zap.SugaredLogger
+	// currentVersion is a string, swapped on config reload while requests are being checked
+	currentVersion atomic.Value
+	now            func() time.Time
+}
+
+type TermsServiceInterface interface {
+	// CurrentVersion is the version users have to accept, empty when none is required
+	CurrentVersion() string
+	SetCurrentVersion(version string)
+	// Status returns whether the user accepted the current version, with the versions the user accepted
+	Status(ctx context.Context, userID uint) (*models.TermsStatus, error)
+	// Accept records that the user accepted version, which has to be the current one; accepting it again
+	// returns the first acceptance
+	Accept(ctx context.Context, userID uint, version string) (*models.TermsAcceptance, error)
+	// CheckAccepted returns TermsNotAcceptedErr unless the user accepted the current version
+	CheckAccepted(ctx context.Context, userID uint) error
+}
+
+func NewTermsService(termsRepo repositories.TermsRepoInterface, currentVersion string, logger *zap.SugaredLogger) TermsServiceInterface {
+	service := &TermsService{
+		termsRepo: termsRepo,
+		logger:    logger,
+		now:       time.Now,
+	}
+	service.SetCurrentVersion(currentVersion)
+	return service
+}
+
+func (service *TermsService) CurrentVersion() string {
+	return service.currentVersion.Load().(string)
+}
+
+func (service *TermsService) SetCurrentVersion(version string) {
+	service.currentVersion.Store(version)
+}
+
+func (service *TermsService) Status(ctx context.Context, userID uint) (*models.TermsStatus, error) {
+	acceptances, err := service.termsRepo.ListAcceptances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.TermsStatus{CurrentVersion: service.CurrentVersion(), Acceptances: acceptances}
+	for _, acceptance := range acceptances {
+		if acceptance.Version == status.CurrentVersion {
+			status.Accepted = true
+		}
+	}
+	// Nothing to accept counts as accepted
+	status.Accepted = status.Accepted || status.CurrentVersion == ""
+	return status, nil
+}
+
+func (service *TermsService) Accept(ctx context.Context, userID uint, version string) (*models.TermsAcceptance, error) {
+	// A page opened before the version changed must not accept the new terms on the user's behalf
+	if current := service.CurrentVersion(); version != current {
+		return nil, apperrors.ConflictErr.AppendMessage("the current terms version is " + current)
+	}
+
+	acceptance := &models.TermsAcceptance{UserID: userID, Version: version, AcceptedAt: service.now()}
+	err := service.termsRepo.CreateAcceptance(ctx, acceptance)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return service.termsRepo.GetAcceptance(ctx, userID, version)
+	}
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+	return acceptance, nil
+}
+
+func (service *TermsService) CheckAccepted(ctx context.Context, userID uint) error {
+	current := service.CurrentVersion()
+	if current == "" {
+		return nil
+	}
+	_, err := service.termsRepo.GetAcceptance(ctx, userID, current)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return apperrors.TermsNotAcceptedErr.AppendMessage("version " + current)
+	}
+	return err
+}
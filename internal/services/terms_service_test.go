@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
+	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTermsService_CheckAcceptedFollowsTheVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	termsRepo := mocks.NewMockTermsRepoInterface(ctrl)
+	service := NewTermsService(termsRepo, "", zaptest.NewLogger(t).Sugar())
+	ctx := context.Background()
+
+	assert.NoError(t, service.CheckAccepted(ctx, 3), "without a version there is nothing to accept")
+
+	service.SetCurrentVersion("2024-01")
+	termsRepo.EXPECT().GetAcceptance(gomock.Any(), uint(3), "2024-01").Return(&models.TermsAcceptance{Version: "2024-01"}, nil)
+	assert.NoError(t, service.CheckAccepted(ctx, 3))
+
+	service.SetCurrentVersion("2024-05")
+	termsRepo.EXPECT().GetAcceptance(gomock.Any(), uint(3), "2024-05").Return(nil, repositories.ErrNotFound)
+	err := service.CheckAccepted(ctx, 3)
+	assert.True(t, errors.Is(err, &apperrors.TermsNotAcceptedErr), "a new version is accepted again, got %v", err)
+}
+
+func TestTermsService_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	termsRepo := mocks.NewMockTermsRepoInterface(ctrl)
+	service := NewTermsService(termsRepo, "2024-05", zaptest.NewLogger(t).Sugar()).(*TermsService)
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := service.Accept(ctx, 3, "2024-01")
+	assert.True(t, errors.Is(err, &apperrors.ConflictErr), "only the current version is accepted, got %v", err)
+
+	termsRepo.EXPECT().CreateAcceptance(gomock.Any(), &models.TermsAcceptance{UserID: 3, Version: "2024-05", AcceptedAt: now}).Return(nil)
+	acceptance, err := service.Accept(ctx, 3, "2024-05")
+	require.NoError(t, err)
+	assert.Equal(t, now, acceptance.AcceptedAt)
+
+	earlier := &models.TermsAcceptance{UserID: 3, Version: "2024-05", AcceptedAt: now.Add(-time.Hour)}
+	termsRepo.EXPECT().CreateAcceptance(gomock.Any(), gomock.Any()).Return(repositories.ErrDuplicate)
+	termsRepo.EXPECT().GetAcceptance(gomock.Any(), uint(3), "2024-05").Return(earlier, nil)
+	acceptance, err = service.Accept(ctx, 3, "2024-05")
+	require.NoError(t, err)
+	assert.Equal(t, earlier, acceptance, "accepting again keeps the first acceptance")
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap"
+)
+
+type TokenService struct {
+	permissions PermissionServiceInterface
+	// claims are the custom claims embedded in each token, see auth.Claims.Embed
+	claims []string
+	keys   *auth.Keys
+	logger *zap.SugaredLogger
+}
+
+type TokenServiceInterface interface {
+	// Issue returns the login token of user, valid for auth.LoginTokenTTL, with the configured custom claims
+	Issue(ctx context.Context, user *models.User) (string, error)
+}
+
+func NewTokenService(permissions PermissionServiceInterface, claims []string, keys *auth.Keys, logger *zap.SugaredLogger) TokenServiceInterface {
+	return &TokenService{
+		permissions: permissions,
+		claims:      claims,
+		keys:        keys,
+		logger:      logger,
+	}
+}
+
+func (service *TokenService) Issue(ctx context.Context, user *models.User) (string, error) {
+	claims := auth.NewClaims(user.Email, user.Role.Name, user.ID, auth.LoginTokenTTL)
+	// Every token is bound to the tenant it was issued in, whatever JWT_CLAIMS asks for
+	claims.TenantID = tenancy.Current(ctx)
+	if len(service.claims) == 0 {
+		return service.keys.Sign(claims)
+	}
+
+	var permissions []string
+	if contains(service.claims, auth.ClaimPermissions) || contains(service.claims, auth.ClaimScope) {
+		granted, err := service.permissions.RolePermissions(ctx, user.RoleID)
+		if err != nil {
+			return "", err
+		}
+		for _, permission := range granted {
+			permissions = append(permissions, permission.Name)
+		}
+	}
+	claims.Embed(service.claims, claims.TenantID, permissions)
+	return service.keys.Sign(claims)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/jkozhemiaka/web-layout/internal/auth"
+	"gitlab.com/jkozhemiaka/web-layout/internal/models"
+	"gitlab.com/jkozhemiaka/web-layout/internal/tenancy"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTokenService_Issue(t *testing.T) {
+	key := []byte("token-secret")
+	user := &models.User{ID: 12, Email: "ann@example.com", RoleID: 2, Role: models.Role{ID: 2, Name: models.StrModerator}}
+	ctx := tenancy.WithTenant(context.Background(), 4)
+
+	plain, err := NewTokenService(nil, nil, auth.NewHMACKeys(key), zaptest.NewLogger(t).Sugar()).Issue(ctx, user)
+	require.NoError(t, err)
+	claims, err := auth.Parse(plain, key)
+	require.NoError(t, err)
+	assert.Equal(t, models.StrModerator, claims.Role)
+	assert.Equal(t, uint(4), claims.TenantID, "every token is bound to its tenant")
+	assert.False(t, claims.CarriesPermissions(), "no permissions unless configured")
+
+	plain, err = NewTokenService(nil, nil, auth.NewHMACKeys(key), zaptest.NewLogger(t).Sugar()).Issue(context.Background(), user)
+	require.NoError(t, err)
+	claims, err = auth.Parse(plain, key)
+	require.NoError(t, err)
+	assert.Equal(t, tenancy.DefaultTenantID, claims.TenantID, "without multi-tenancy")
+
+	ctrl := gomock.NewController(t)
+	permissions := NewMockPermissionServiceInterface(ctrl)
+	permissions.EXPECT().RolePermissions(gomock.Any(), uint(2)).Return([]models.GrantedPermission{
+		{Permission: models.Permission{Name: models.PermissionAuditRead}},
+		{Permission: models.Permission{Name: models.PermissionStatsRead}},
+	}, nil)
+	service := NewTokenService(permissions, []string{auth.ClaimTenant, auth.ClaimPermissions, auth.ClaimScope}, auth.NewHMACKeys(key), zaptest.NewLogger(t).Sugar())
+	token, err := service.Issue(ctx, user)
+	require.NoError(t, err)
+	claims, err = auth.Parse(token, key)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), claims.TenantID)
+	assert.Equal(t, []string{"audit.read", "stats.read"}, claims.Permissions)
+	assert.Equal(t, "audit.read stats.read", claims.Scope)
+}
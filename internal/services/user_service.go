@@ -2,12 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
 	"gitlab.com/jkozhemiaka/web-layout/internal/repositories"
-	"gorm.io/gorm"
 
+	"gitlab.com/jkozhemiaka/web-layout/internal/filter"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
 	"go.uber.org/zap"
 )
@@ -15,27 +19,54 @@ import (
 type UserService struct {
 	userRepo repositories.UserRepoInterface
 	voteRepo repositories.VoteRepoInterface
-	logger   *zap.SugaredLogger
+	// rememberTokens are revoked when a password changes, so a stolen device cannot outlive the reset
+	rememberTokens repositories.RememberTokenRepoInterface
+	txManager      repositories.TxManagerInterface
+	emitter        *events.Emitter
+	logger         *zap.SugaredLogger
+	// voteCooldown is a time.Duration, swapped on config reload while votes are being served
+	voteCooldown atomic.Int64
 }
 
+// DefaultVoteCooldown is the time between two votes of a user until SetVoteCooldown is called
+const DefaultVoteCooldown = time.Hour
+
 type UserServiceInterface interface {
 	CreateUser(ctx context.Context, user *models.User) (uint, error)
 	DeleteUser(ctx context.Context, userID string) (*models.User, error)
 	GetUser(ctx context.Context, userID string) (*models.User, error)
 	UpdateUser(ctx context.Context, userID string, user *models.User) (*models.User, error)
-	ListUsers(ctx context.Context, page, pageSize int) ([]models.User, error)
-	CountUsers(ctx context.Context) (int, error)
+	// ListUsers returns a page of the users matching filters; unknown fields, operators and bad values are
+	// a BadRequestErr
+	ListUsers(ctx context.Context, page, pageSize int, filters []filter.Condition) (*models.UserPage, error)
+	// ListUserChanges returns up to limit users changed after the cursor of since and afterID, for clients that
+	// sync incrementally; deleted users are returned as tombstones
+	ListUserChanges(ctx context.Context, since time.Time, afterID uint, limit int) (*models.UserChangesPage, error)
+	// CountUsers counts the users matching the same filters as ListUsers
+	CountUsers(ctx context.Context, filters []filter.Condition) (int, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error)
+	GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error)
 	Vote(ctx context.Context, vote *models.Vote) (uint, error)
 	RevokeVote(ctx context.Context, userID uint, profileID uint) error
+	SetVoteCooldown(cooldown time.Duration)
 }
 
-func NewUserService(userRepo repositories.UserRepoInterface, voteRepo repositories.VoteRepoInterface, logger *zap.SugaredLogger) UserServiceInterface {
-	return &UserService{
-		userRepo: userRepo,
-		voteRepo: voteRepo,
-		logger:   logger,
+func NewUserService(userRepo repositories.UserRepoInterface, voteRepo repositories.VoteRepoInterface, rememberTokens repositories.RememberTokenRepoInterface, txManager repositories.TxManagerInterface, emitter *events.Emitter, logger *zap.SugaredLogger) UserServiceInterface {
+	service := &UserService{
+		userRepo:       userRepo,
+		voteRepo:       voteRepo,
+		rememberTokens: rememberTokens,
+		txManager:      txManager,
+		emitter:        emitter,
+		logger:         logger,
 	}
+	service.SetVoteCooldown(DefaultVoteCooldown)
+	return service
+}
+
+func (service *UserService) SetVoteCooldown(cooldown time.Duration) {
+	service.voteCooldown.Store(int64(cooldown))
 }
 
 func (service *UserService) CreateUser(ctx context.Context, user *models.User) (userId uint, err error) {
@@ -45,6 +76,7 @@ func (service *UserService) CreateUser(ctx context.Context, user *models.User) (
 		return 0, err
 	}
 
+	service.emitUserEvent(ctx, events.UserCreated, insertedUser.ID)
 	return insertedUser.ID, nil
 }
 
@@ -65,31 +97,85 @@ func (service *UserService) DeleteUser(ctx context.Context, userID string) (user
 		return nil, err
 	}
 
+	service.emitUserEvent(ctx, events.UserDeleted, user.ID)
+
 	return user, nil
 }
 
 func (service *UserService) UpdateUser(ctx context.Context, userID string, updatedData *models.User) (user *models.User, err error) {
-	user, err = service.userRepo.UpdateUser(ctx, userID, updatedData)
+	// Service accounts call the API with their keys, a password would let them sign in
+	if updatedData.Password != "" {
+		prior, err := service.userRepo.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if prior.ServiceAccount {
+			return nil, apperrors.BadRequestErr.AppendMessage("service accounts have no password")
+		}
+	}
+
+	// A new password forgets every remembered device in the same transaction
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err = service.userRepo.UpdateUser(ctx, userID, updatedData)
+		if err != nil || updatedData.Password == "" {
+			return err
+		}
+		return service.rememberTokens.DeleteUserTokens(ctx, user.ID)
+	})
 	if err != nil {
 		service.logger.Error(err)
 		return nil, err
 	}
 
+	service.emitUserEvent(ctx, events.UserUpdated, user.ID)
+
 	return user, nil
 }
 
-func (service *UserService) ListUsers(ctx context.Context, page, pageSize int) (user []models.User, err error) {
-	user, err = service.userRepo.ListUsers(ctx, page, pageSize)
+func (service *UserService) ListUsers(ctx context.Context, page, pageSize int, filters []filter.Condition) (*models.UserPage, error) {
+	users, total, err := service.userRepo.ListUsersWithTotal(ctx, page, pageSize, filters)
 	if err != nil {
 		service.logger.Error(err)
 		return nil, err
 	}
 
-	return user, nil
+	totalPages := (total + pageSize - 1) / pageSize
+	return &models.UserPage{
+		Data:       users,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
+
+func (service *UserService) ListUserChanges(ctx context.Context, since time.Time, afterID uint, limit int) (*models.UserChangesPage, error) {
+	// One row past the limit tells whether another page follows
+	users, err := service.userRepo.ListUsersUpdatedSince(ctx, since, afterID, limit+1)
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+
+	changes := &models.UserChangesPage{Data: []models.UserChange{}, UpdatedSince: since, AfterID: afterID}
+	if len(users) > limit {
+		users, changes.HasMore = users[:limit], true
+	}
+	for i := range users {
+		user := &users[i]
+		change := models.UserChange{UserID: user.ID, UpdatedAt: user.UpdatedAt, Deleted: !user.DeletedAt.IsZero()}
+		if !change.Deleted {
+			change.User = user
+		}
+		changes.Data = append(changes.Data, change)
+		changes.UpdatedSince, changes.AfterID = user.UpdatedAt, user.ID
+	}
+	return changes, nil
 }
 
-func (service *UserService) CountUsers(ctx context.Context) (int, error) {
-	count, err := service.userRepo.CountUsers(ctx)
+func (service *UserService) CountUsers(ctx context.Context, filters []filter.Condition) (int, error) {
+	count, err := service.userRepo.CountUsers(ctx, filters)
 	if err != nil {
 		service.logger.Error(err)
 		return 0, err
@@ -98,6 +184,32 @@ func (service *UserService) CountUsers(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// CreateOrUpdateByEmail upserts a user pushed by an external system keyed on the email
+func (service *UserService) CreateOrUpdateByEmail(ctx context.Context, user *models.User) (*models.User, bool, error) {
+	stored, created, err := service.userRepo.CreateOrUpdateByEmail(ctx, user)
+	if err != nil {
+		service.logger.Error(err)
+		return nil, false, err
+	}
+
+	if created {
+		service.emitUserEvent(ctx, events.UserCreated, stored.ID)
+	} else {
+		service.emitUserEvent(ctx, events.UserUpdated, stored.ID)
+	}
+	return stored, created, nil
+}
+
+func (service *UserService) GetUserHistory(ctx context.Context, userID string) ([]models.UserHistory, error) {
+	history, err := service.userRepo.GetUserHistory(ctx, userID)
+	if err != nil {
+		service.logger.Error(err)
+		return nil, err
+	}
+
+	return history, nil
+}
+
 func (service *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	user, err := service.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -109,24 +221,41 @@ func (service *UserService) GetUserByEmail(ctx context.Context, email string) (*
 }
 
 func (service *UserService) Vote(ctx context.Context, vote *models.Vote) (uint, error) {
-	// Get the user profile
+	var saved *models.Vote
+	err := service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		saved, err = service.vote(ctx, vote)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Emitted only after commit so consumers never see a vote that was rolled back
+	service.emitVoteEvent(ctx, events.VoteCast, saved)
+	return saved.ID, nil
+}
+
+func (service *UserService) vote(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
+	// Lock the voter's row for the rest of the transaction so concurrent votes
+	// by the same user serialize on the cooldown check instead of both passing it
 	var user *models.User
-	user, err := service.userRepo.GetUserByID(ctx, vote.UserID)
+	user, err := service.userRepo.LockUserByID(ctx, vote.UserID)
 	if err != nil {
 		service.logger.Error("Failed to get user", zap.Error(err))
-		return 0, apperrors.InsertionFailedErr.AppendMessage(err.Error())
+		return nil, apperrors.InsertionFailedErr.AppendMessage(err.Error())
 	}
 
-	// Check if the user has voted within the last hour
-	if time.Since(user.VoteUpdatedAt) < time.Hour {
-		return 0, &apperrors.VoteCooldownErr
+	// Check if the user has voted within the cooldown
+	if time.Since(user.VoteUpdatedAt) < time.Duration(service.voteCooldown.Load()) {
+		return nil, &apperrors.VoteCooldownErr
 	}
 
 	// Check if the user has already voted for this profile
 	existingVote, err := service.voteRepo.GetVote(ctx, vote.UserID, vote.ProfileID)
-	if err != nil && err != gorm.ErrRecordNotFound {
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
 		service.logger.Error("Failed to check existing vote", zap.Error(err))
-		return 0, apperrors.InsertionFailedErr.AppendMessage(err.Error())
+		return nil, apperrors.InsertionFailedErr.AppendMessage(err.Error())
 	}
 
 	if existingVote != nil {
@@ -135,19 +264,19 @@ func (service *UserService) Vote(ctx context.Context, vote *models.Vote) (uint,
 		_, err = service.voteRepo.UpdateVote(ctx, existingVote)
 		if err != nil {
 			service.logger.Error("Failed to update vote", zap.Error(err))
-			return 0, apperrors.UpdateFailedErr.AppendMessage(err.Error())
+			return nil, apperrors.UpdateFailedErr.AppendMessage(err.Error())
 		}
-		return existingVote.ID, nil
+		return existingVote, nil
 	}
 
 	// Create new vote
 	insertedVote, err := service.voteRepo.CreateVote(ctx, vote)
 	if err != nil {
 		service.logger.Error("Failed to create vote", zap.Error(err))
-		return 0, apperrors.InsertionFailedErr.AppendMessage(err.Error())
+		return nil, apperrors.InsertionFailedErr.AppendMessage(err.Error())
 	}
 
-	return insertedVote.ID, nil
+	return insertedVote, nil
 }
 
 func (service *UserService) RevokeVote(ctx context.Context, userID uint, profileID uint) error {
@@ -157,5 +286,32 @@ func (service *UserService) RevokeVote(ctx context.Context, userID uint, profile
 		return err
 	}
 
+	service.emitVoteEvent(ctx, events.VoteRevoked, &models.Vote{UserID: userID, ProfileID: profileID})
 	return nil
 }
+
+func (service *UserService) emitUserEvent(ctx context.Context, eventType string, userID uint) {
+	type userEventData struct {
+		UserID uint `json:"user_id"`
+	}
+
+	subject := strconv.FormatUint(uint64(userID), 10)
+	service.emitter.Emit(ctx, eventType, subject, &userEventData{UserID: userID})
+}
+
+func (service *UserService) emitVoteEvent(ctx context.Context, eventType string, vote *models.Vote) {
+	type voteEventData struct {
+		VoteID    uint `json:"vote_id,omitempty"`
+		UserID    uint `json:"user_id"`
+		ProfileID uint `json:"profile_id"`
+		Value     int  `json:"value,omitempty"`
+	}
+
+	subject := strconv.FormatUint(uint64(vote.ProfileID), 10)
+	service.emitter.Emit(ctx, eventType, subject, &voteEventData{
+		VoteID:    vote.ID,
+		UserID:    vote.UserID,
+		ProfileID: vote.ProfileID,
+		Value:     vote.Value,
+	})
+}
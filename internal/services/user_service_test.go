@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/events"
 	"gitlab.com/jkozhemiaka/web-layout/internal/models"
 	mocks "gitlab.com/jkozhemiaka/web-layout/internal/repositories/mocks"
 
@@ -15,6 +16,16 @@ import (
 	"go.uber.org/zap/zaptest"
 )
 
+// newInlineTxManager runs transactional callbacks directly, as if every transaction committed
+func newInlineTxManager(ctrl *gomock.Controller) *mocks.MockTxManagerInterface {
+	mockTx := mocks.NewMockTxManagerInterface(ctrl)
+	mockTx.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	return mockTx
+}
+
 func TestUserService_CreateUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -22,7 +33,7 @@ func TestUserService_CreateUser(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testUser := &models.User{Email: "test@example.com"}
 	mockRepo.EXPECT().CreateUser(gomock.Any(), testUser).Return(testUser, nil)
@@ -39,7 +50,7 @@ func TestUserService_GetUser(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testUserID := "1"
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
@@ -57,7 +68,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testUserID := "1"
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
@@ -74,8 +85,9 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
+	rememberTokens := mocks.NewMockRememberTokenRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, rememberTokens, newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testUserID := "1"
 	testUser := &models.User{ID: 1, Email: "updated@example.com"}
@@ -84,6 +96,18 @@ func TestUserService_UpdateUser(t *testing.T) {
 	user, err := userService.UpdateUser(context.Background(), testUserID, testUser)
 	assert.NoError(t, err)
 	assert.Equal(t, testUser, user)
+
+	// A new password forgets the remembered devices
+	newPassword := &models.User{Password: "hash"}
+	mockRepo.EXPECT().GetUser(gomock.Any(), testUserID).Return(testUser, nil)
+	mockRepo.EXPECT().UpdateUser(gomock.Any(), testUserID, newPassword).Return(testUser, nil)
+	rememberTokens.EXPECT().DeleteUserTokens(gomock.Any(), uint(1)).Return(nil)
+	_, err = userService.UpdateUser(context.Background(), testUserID, newPassword)
+	assert.NoError(t, err)
+
+	mockRepo.EXPECT().GetUser(gomock.Any(), "2").Return(&models.User{ID: 2, ServiceAccount: true}, nil)
+	_, err = userService.UpdateUser(context.Background(), "2", &models.User{Password: "hash"})
+	assert.True(t, apperrors.Is(err, &apperrors.BadRequestErr), "service accounts have no password, got %v", err)
 }
 
 func TestUserService_ListUsers(t *testing.T) {
@@ -93,17 +117,52 @@ func TestUserService_ListUsers(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testUsers := []models.User{
 		{ID: 1, Email: "user1@example.com"},
 		{ID: 2, Email: "user2@example.com"},
 	}
-	mockRepo.EXPECT().ListUsers(gomock.Any(), 1, 10).Return(testUsers, nil)
+	mockRepo.EXPECT().ListUsersWithTotal(gomock.Any(), 1, 2, gomock.Nil()).Return(testUsers, 5, nil)
 
-	users, err := userService.ListUsers(context.Background(), 1, 10)
+	page, err := userService.ListUsers(context.Background(), 1, 2, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, testUsers, users)
+	assert.Equal(t, &models.UserPage{
+		Data:       testUsers,
+		Page:       1,
+		PageSize:   2,
+		Total:      5,
+		TotalPages: 3,
+		HasNext:    true,
+	}, page)
+}
+
+func TestUserService_ListUserChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
+	mockLogger := zaptest.NewLogger(t).Sugar()
+	userService := NewUserService(mockRepo, mocks.NewMockVoteRepoInterface(ctrl), mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
+	since := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().ListUsersUpdatedSince(gomock.Any(), since, uint(0), 3).Return([]models.User{
+		{ID: 4, UpdatedAt: since.Add(time.Minute)},
+		{ID: 2, UpdatedAt: since.Add(2 * time.Minute), DeletedAt: since.Add(2 * time.Minute)},
+		{ID: 5, UpdatedAt: since.Add(3 * time.Minute)},
+	}, nil)
+	changes, err := userService.ListUserChanges(context.Background(), since, 0, 2)
+	assert.NoError(t, err)
+	assert.True(t, changes.HasMore)
+	if assert.Len(t, changes.Data, 2) {
+		assert.Equal(t, uint(4), changes.Data[0].User.ID)
+		assert.Equal(t, models.UserChange{UserID: 2, UpdatedAt: since.Add(2 * time.Minute), Deleted: true}, changes.Data[1], "deleted users are tombstones")
+	}
+	assert.Equal(t, since.Add(2*time.Minute), changes.UpdatedSince)
+	assert.Equal(t, uint(2), changes.AfterID)
+
+	mockRepo.EXPECT().ListUsersUpdatedSince(gomock.Any(), since, uint(7), 3).Return(nil, nil)
+	changes, err = userService.ListUserChanges(context.Background(), since, 7, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.UserChangesPage{Data: []models.UserChange{}, UpdatedSince: since, AfterID: 7}, changes, "without changes the cursor stays")
 }
 
 func TestUserService_CountUsers(t *testing.T) {
@@ -113,11 +172,11 @@ func TestUserService_CountUsers(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
-	mockRepo.EXPECT().CountUsers(gomock.Any()).Return(2, nil)
+	mockRepo.EXPECT().CountUsers(gomock.Any(), gomock.Nil()).Return(2, nil)
 
-	count, err := userService.CountUsers(context.Background())
+	count, err := userService.CountUsers(context.Background(), nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, count)
 }
@@ -129,7 +188,7 @@ func TestUserService_GetUserByEmail(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testEmail := "test@example.com"
 	testUser := &models.User{ID: 1, Email: testEmail}
@@ -147,13 +206,13 @@ func TestUserService_Vote(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testVote := &models.Vote{UserID: 1, ProfileID: 2, Value: 1}
 	testUser := &models.User{ID: 1, VoteUpdatedAt: time.Now().Add(-2 * time.Hour)}
 
 	// Return the user and nil for error
-	mockRepo.EXPECT().GetUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
+	mockRepo.EXPECT().LockUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
 	mockVote.EXPECT().GetVote(gomock.Any(), testVote.UserID, testVote.ProfileID).Return(nil, nil)
 	mockVote.EXPECT().CreateVote(gomock.Any(), testVote).Return(testVote, nil)
 
@@ -169,13 +228,13 @@ func TestUserService_Vote_CooldownError(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testVote := &models.Vote{UserID: 1, ProfileID: 2, Value: 1}
 	testUser := &models.User{ID: 1, VoteUpdatedAt: time.Now().Add(-30 * time.Minute)} // Time within cooldown period
 
 	// Set expectations
-	mockRepo.EXPECT().GetUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
+	mockRepo.EXPECT().LockUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
 
 	_, err := userService.Vote(context.Background(), testVote)
 	assert.Error(t, err)
@@ -185,6 +244,27 @@ func TestUserService_Vote_CooldownError(t *testing.T) {
 	assert.Equal(t, apperrors.VoteCooldownErr.Code, appErr.Code)
 }
 
+func TestUserService_Vote_ConfiguredCooldown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
+	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
+	mockLogger := zaptest.NewLogger(t).Sugar()
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
+	userService.SetVoteCooldown(10 * time.Minute)
+
+	testVote := &models.Vote{UserID: 1, ProfileID: 2, Value: 1}
+	testUser := &models.User{ID: 1, VoteUpdatedAt: time.Now().Add(-30 * time.Minute)} // Within the default hour, past the configured cooldown
+
+	mockRepo.EXPECT().LockUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
+	mockVote.EXPECT().GetVote(gomock.Any(), testVote.UserID, testVote.ProfileID).Return(nil, nil)
+	mockVote.EXPECT().CreateVote(gomock.Any(), testVote).Return(testVote, nil)
+
+	_, err := userService.Vote(context.Background(), testVote)
+	assert.NoError(t, err)
+}
+
 func TestUserService_Vote_UpdateSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -192,14 +272,14 @@ func TestUserService_Vote_UpdateSuccess(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testVote := &models.Vote{UserID: 1, ProfileID: 2, Value: 1}
 	existingVote := &models.Vote{ID: 10, UserID: 1, ProfileID: 2, Value: 0}
 	testUser := &models.User{ID: 1, VoteUpdatedAt: time.Now().Add(-2 * time.Hour)} // Time outside cooldown period
 
 	// Set expectations
-	mockRepo.EXPECT().GetUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
+	mockRepo.EXPECT().LockUserByID(gomock.Any(), testVote.UserID).Return(testUser, nil)
 	mockVote.EXPECT().GetVote(gomock.Any(), testVote.UserID, testVote.ProfileID).Return(existingVote, nil)
 	mockVote.EXPECT().UpdateVote(gomock.Any(), existingVote).Return(existingVote, nil)
 
@@ -215,12 +295,12 @@ func TestUserService_Vote_GetUserError(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	testVote := &models.Vote{UserID: 1, ProfileID: 2, Value: 1}
 
 	// Set expectations
-	mockRepo.EXPECT().GetUserByID(gomock.Any(), testVote.UserID).Return(nil, errors.New("db error"))
+	mockRepo.EXPECT().LockUserByID(gomock.Any(), testVote.UserID).Return(nil, errors.New("db error"))
 
 	voteID, err := userService.Vote(context.Background(), testVote)
 	assert.Error(t, err)
@@ -235,7 +315,7 @@ func TestUserService_RevokeVote_Success(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	userID := uint(1)
 	profileID := uint(2)
@@ -254,7 +334,7 @@ func TestUserService_RevokeVote_DeleteVoteError(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
 	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
 	mockLogger := zaptest.NewLogger(t).Sugar()
-	userService := NewUserService(mockRepo, mockVote, mockLogger)
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
 
 	userID := uint(1)
 	profileID := uint(2)
@@ -265,3 +345,22 @@ func TestUserService_RevokeVote_DeleteVoteError(t *testing.T) {
 	err := userService.RevokeVote(context.Background(), userID, profileID)
 	assert.Error(t, err)
 }
+
+func TestUserService_CreateOrUpdateByEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepoInterface(ctrl)
+	mockVote := mocks.NewMockVoteRepoInterface(ctrl)
+	mockLogger := zaptest.NewLogger(t).Sugar()
+	userService := NewUserService(mockRepo, mockVote, mocks.NewMockRememberTokenRepoInterface(ctrl), newInlineTxManager(ctrl), events.NewEmitter("urn:test", events.NewLogPublisher(mockLogger), mockLogger), mockLogger)
+
+	payload := &models.User{Email: "hr@example.com", FirstName: "Ann"}
+	stored := &models.User{ID: 7, Email: "hr@example.com", FirstName: "Ann"}
+	mockRepo.EXPECT().CreateOrUpdateByEmail(gomock.Any(), payload).Return(stored, false, nil)
+
+	user, created, err := userService.CreateOrUpdateByEmail(context.Background(), payload)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, stored, user)
+}
@@ -0,0 +1,44 @@
+// Package sms sends text messages, through Twilio or only to the log
+package sms
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/jkozhemiaka/web-layout/internal/config"
+	"go.uber.org/zap"
+)
+
+// Sender sends body to a phone number in E.164 form, e.g. +380501234567
+type Sender interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// NewSender builds the sender selected by SMS_DRIVER
+func NewSender(cfg *config.Config, logger *zap.SugaredLogger) (Sender, error) {
+	switch cfg.SMSDriver {
+	case config.SMSDriverLog, "":
+		return NewLogSender(logger), nil
+	case config.SMSDriverTwilio:
+		return NewTwilioSender(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFrom), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMS_DRIVER %q", cfg.SMSDriver)
+	}
+}
+
+// LogSender only logs who would have received a message, for development. The body is logged too, so codes can
+// be read from the log.
+type LogSender struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogSender(logger *zap.SugaredLogger) *LogSender {
+	return &LogSender{
+		logger: logger,
+	}
+}
+
+func (s *LogSender) Send(ctx context.Context, to, body string) error {
+	s.logger.Infow("SMS not sent (SMS_DRIVER=log)", "to", to, "body", body)
+	return nil
+}
@@ -0,0 +1,38 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwilioSender(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sid, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", sid)
+		assert.Equal(t, "tw-token", token)
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sender := NewTwilioSender("AC123", "tw-token", "+15005550006")
+	assert.Equal(t, "https://api.twilio.com/2010-04-01/Accounts/AC123/Messages.json", sender.endpoint)
+	sender.endpoint = server.URL
+	require.NoError(t, sender.Send(context.Background(), "+380501234567", "Your code is 123456"))
+	assert.Equal(t, map[string][]string{"To": {"+380501234567"}, "From": {"+15005550006"}, "Body": {"Your code is 123456"}}, form)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 21211, "message": "Invalid 'To' Phone Number"}`, http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	sender.endpoint = failing.URL
+	assert.ErrorContains(t, sender.Send(context.Background(), "+1", "Hi"), "twilio responded with status 400")
+}
@@ -0,0 +1,53 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TwilioSender sends through the Twilio Programmable Messaging API
+type TwilioSender struct {
+	accountSID string
+	authToken  string
+	from       string
+	// endpoint is overridden in tests
+	endpoint string
+	client   *http.Client
+}
+
+func NewTwilioSender(accountSID, authToken, from string) *TwilioSender {
+	return &TwilioSender{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		endpoint:   "https://api.twilio.com/2010-04-01/Accounts/" + url.PathEscape(accountSID) + "/Messages.json",
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TwilioSender) Send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {s.from}, "Body": {body}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("twilio responded with status %d: %s", res.StatusCode, body)
+	}
+	return nil
+}
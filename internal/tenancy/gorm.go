@@ -0,0 +1,84 @@
+package tenancy
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const tenantField = "TenantID"
+
+// Plugin scopes every statement on a model with a TenantID field to the tenant in the statement's context,
+// and stamps new rows with that tenant (or DefaultTenantID when there is none). Statements whose context
+// carries no tenant, such as background jobs, see all tenants.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenancy"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenancy:assign", assignTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenancy:scope", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenancy:scope", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenancy:scope", scopeToTenant); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenancy:scope", scopeToTenant)
+}
+
+func scopeToTenant(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return
+	}
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+func assignTenant(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return
+	}
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		tenantID = DefaultTenantID
+	}
+
+	ctx := db.Statement.Context
+	assign := func(row reflect.Value) {
+		if _, zero := field.ValueOf(ctx, row); zero {
+			db.AddError(field.Set(ctx, row, tenantID))
+		}
+	}
+
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			assign(reflect.Indirect(db.Statement.ReflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		assign(db.Statement.ReflectValue)
+	}
+}
@@ -0,0 +1,41 @@
+// Package tenancy carries the current tenant through the request context and scopes GORM queries to it.
+package tenancy
+
+import (
+	"context"
+	"strconv"
+)
+
+// DefaultTenantID is the tenant every row belongs to when multi-tenancy is off, created by the migrations
+const DefaultTenantID uint = 1
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose repository calls only see rows of the given tenant
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// FromContext returns the tenant set by WithTenant
+func FromContext(ctx context.Context) (uint, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(uint)
+	return tenantID, ok
+}
+
+// Current returns the tenant set by WithTenant, or DefaultTenantID, the tenant rows are stamped with, when there is
+// none
+func Current(ctx context.Context) uint {
+	if tenantID, ok := FromContext(ctx); ok {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+// KeyPrefix namespaces cache keys by tenant; it is empty when the context carries no tenant
+func KeyPrefix(ctx context.Context) string {
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return "tenant:" + strconv.FormatUint(uint64(tenantID), 10) + ":"
+}
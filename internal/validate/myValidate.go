@@ -2,25 +2,49 @@ package myValidate
 
 import (
 	"regexp"
+	"time"
+	"unicode"
 
 	"github.com/go-playground/validator"
+	"golang.org/x/text/language"
 )
 
-// Custom password validation
+var specialChar = regexp.MustCompile(`[!@#~$%^&*(),.?":{}|<>]`)
+
+// Password accepts 8 to 72 bytes, bcrypt's limit, with a letter, a number and a special character
 func Password(fl validator.FieldLevel) bool {
 	password := fl.Field().String()
-	if len(password) < 8 {
+	if len(password) < 8 || len(password) > 72 {
 		return false
 	}
+	hasLetter := false
 	hasNumber := false
 	hasSpecial := false
 	for _, char := range password {
 		switch {
 		case char >= '0' && char <= '9':
 			hasNumber = true
-		case regexp.MustCompile(`[!@#~$%^&*(),.?":{}|<>]`).MatchString(string(char)):
+		case unicode.IsLetter(char):
+			hasLetter = true
+		case specialChar.MatchString(string(char)):
 			hasSpecial = true
 		}
 	}
-	return hasNumber && hasSpecial
+	return hasLetter && hasNumber && hasSpecial
+}
+
+// Timezone accepts IANA time zone names such as Europe/Kyiv. "Local" is rejected: it is the server's zone, not a name.
+func Timezone(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Locale accepts well-formed BCP 47 language tags such as uk or en-GB
+func Locale(fl validator.FieldLevel) bool {
+	tag, err := language.Parse(fl.Field().String())
+	return err == nil && tag != language.Und
 }
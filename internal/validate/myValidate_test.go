@@ -1,6 +1,7 @@
 package myValidate
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator"
@@ -24,6 +25,8 @@ func TestPassword(t *testing.T) {
 		{"abcd1234", false},      // Letters and numbers, no special character
 		{"!@#$%^&*", false},      // Only special characters
 		{"Abcdef1!", true},       // Valid password with mixed characters
+		{"1234567!", false},      // No letter
+		{"Abcdef1!" + strings.Repeat("x", 65), false}, // Longer than bcrypt hashes
 	}
 
 	for _, test := range tests {
@@ -35,3 +38,35 @@ func TestPassword(t *testing.T) {
 		}
 	}
 }
+
+func TestTimezoneAndLocale(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterValidation("timezone", Timezone)
+	validate.RegisterValidation("locale", Locale)
+
+	tests := []struct {
+		rule  string
+		value string
+		valid bool
+	}{
+		{"timezone", "Europe/Kyiv", true},
+		{"timezone", "UTC", true},
+		{"timezone", "Mars/Olympus", false},
+		{"timezone", "Local", false}, // the server's zone
+		{"timezone", "", false},
+		{"locale", "uk", true},
+		{"locale", "en-GB", true},
+		{"locale", "not a tag", false},
+		{"locale", "und", false},
+		{"locale", "", false},
+	}
+
+	for _, test := range tests {
+		err := validate.Var(test.value, test.rule)
+		if test.valid {
+			assert.NoError(t, err, "%s %q should be valid", test.rule, test.value)
+		} else {
+			assert.Error(t, err, "%s %q should be invalid", test.rule, test.value)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package myValidate
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+	"gitlab.com/jkozhemiaka/web-layout/internal/i18n"
+	"golang.org/x/text/language"
+)
+
+// Violations turns the errors of validating request into one field violation per failed field, named as in
+// the JSON body. It returns nil when err is not a validation error.
+func Violations(request interface{}, err error) []apperrors.FieldViolation {
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil
+	}
+
+	requestType := reflect.TypeOf(request)
+	if requestType.Kind() == reflect.Ptr {
+		requestType = requestType.Elem()
+	}
+
+	violations := make([]apperrors.FieldViolation, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		id, args := describe(fieldErr.Tag(), fieldErr.Param())
+		violations = append(violations, apperrors.FieldViolation{
+			Field:       jsonName(requestType, fieldErr.StructField()),
+			Rule:        fieldErr.Tag(),
+			Message:     i18n.Message(language.English, id, args...),
+			MessageID:   id,
+			MessageArgs: args,
+		})
+	}
+	return violations
+}
+
+// ValidationError validates request and returns ValidationFailedErr listing every invalid field, or nil
+func ValidationError(validate *validator.Validate, request interface{}) error {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil
+	}
+	violations := Violations(request, err)
+	if violations == nil {
+		return err
+	}
+	return apperrors.ValidationFailedErr.WithFields(violations...)
+}
+
+// jsonName returns the name a struct field has in JSON, or the field name without a json tag
+func jsonName(structType reflect.Type, field string) string {
+	if structType.Kind() != reflect.Struct {
+		return field
+	}
+	structField, ok := structType.FieldByName(field)
+	if !ok {
+		return field
+	}
+	name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field
+	}
+	return name
+}
+
+// describe returns the catalog message for a failed validate rule and its parameter
+func describe(rule, param string) (string, []interface{}) {
+	switch rule {
+	case "min", "max":
+		return "validation." + rule, []interface{}{param}
+	case "oneof":
+		return "validation.oneof", []interface{}{strings.ReplaceAll(param, " ", ", ")}
+	}
+	if i18n.Has("validation." + rule) {
+		return "validation." + rule, nil
+	}
+	return "validation.default", []interface{}{rule}
+}
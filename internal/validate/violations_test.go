@@ -0,0 +1,41 @@
+package myValidate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/jkozhemiaka/web-layout/internal/apperrors"
+)
+
+type signupRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password,omitempty" validate:"required,min=8,password"`
+	Nickname string `validate:"required"`
+}
+
+func TestValidationError_ListsEveryField(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterValidation("password", Password)
+
+	err := ValidationError(validate, &signupRequest{Email: "not-an-email", Password: "short"})
+
+	var appError *apperrors.AppError
+	if assert.ErrorAs(t, err, &appError) {
+		assert.Equal(t, apperrors.ValidationFailedErr.Code, appError.Code)
+		assert.Equal(t, []apperrors.FieldViolation{
+			{Field: "email", Rule: "email", Message: "must be a valid email address", MessageID: "validation.email"},
+			{Field: "password", Rule: "min", Message: "must be at least 8 characters long", MessageID: "validation.min", MessageArgs: []interface{}{"8"}},
+			{Field: "Nickname", Rule: "required", Message: "is required", MessageID: "validation.required"},
+		}, appError.Fields)
+	}
+}
+
+func TestValidationError_Valid(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterValidation("password", Password)
+
+	assert.NoError(t, ValidationError(validate, &signupRequest{Email: "a@example.com", Password: "N0Special!", Nickname: "a"}))
+	assert.Nil(t, Violations(nil, errors.New("not a validation error")))
+}